@@ -0,0 +1,142 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import "fmt"
+
+// AdaptiveConfig defines configuration for adaptive execution mode. It
+// binary-searches the maximum rate which keeps P99 latency under the target.
+type AdaptiveConfig struct {
+	// TargetP99Seconds is the P99 latency target in seconds.
+	TargetP99Seconds float64 `json:"targetP99Seconds" yaml:"targetP99Seconds" mapstructure:"targetP99Seconds"`
+	// MinRate is the lower bound of searched rate in requests per second.
+	// Zero means DefaultAdaptiveMinRate.
+	MinRate float64 `json:"minRate,omitempty" yaml:"minRate,omitempty" mapstructure:"minRate"`
+	// MaxRate is the upper bound of searched rate in requests per second.
+	MaxRate float64 `json:"maxRate" yaml:"maxRate" mapstructure:"maxRate"`
+	// Steps is the number of rates to probe. Zero means DefaultAdaptiveSteps.
+	Steps int `json:"steps,omitempty" yaml:"steps,omitempty" mapstructure:"steps"`
+	// StepDuration is the running time in seconds of each probe. Zero means
+	// DefaultAdaptiveStepDuration.
+	StepDuration Seconds `json:"stepDuration,omitempty" yaml:"stepDuration,omitempty" mapstructure:"stepDuration"`
+	// Requests defines the different kinds of requests with weights.
+	Requests []*WeightedRequest `json:"requests" yaml:"requests" mapstructure:"requests"`
+	// MaxRetryPicks is the same as WeightedRandomConfig.MaxRetryPicks.
+	MaxRetryPicks int `json:"maxRetryPicks,omitempty" yaml:"maxRetryPicks,omitempty" mapstructure:"maxRetryPicks"`
+}
+
+const (
+	// DefaultAdaptiveMinRate is the default value of AdaptiveConfig.MinRate.
+	DefaultAdaptiveMinRate = 1
+	// DefaultAdaptiveSteps is the default value of AdaptiveConfig.Steps.
+	DefaultAdaptiveSteps = 8
+	// DefaultAdaptiveStepDuration is the default value of AdaptiveConfig.StepDuration.
+	DefaultAdaptiveStepDuration Seconds = 10
+)
+
+// Ensure AdaptiveConfig implements ModeConfig
+func (*AdaptiveConfig) isModeConfig() {}
+
+// GetOverridableFields implements ModeConfig for AdaptiveConfig
+func (c *AdaptiveConfig) GetOverridableFields() []OverridableField {
+	return nil
+}
+
+// ApplyOverrides implements ModeConfig for AdaptiveConfig
+func (c *AdaptiveConfig) ApplyOverrides(overrides map[string]interface{}) error {
+	for key := range overrides {
+		return fmt.Errorf("unknown override key for adaptive mode: %s", key)
+	}
+	return nil
+}
+
+// Validate implements ModeConfig for AdaptiveConfig
+func (c *AdaptiveConfig) Validate(_ map[string]interface{}) error {
+	if c.TargetP99Seconds <= 0 {
+		return fmt.Errorf("targetP99Seconds requires > 0: %v", c.TargetP99Seconds)
+	}
+
+	if c.MinRate < 0 || c.Steps < 0 || c.StepDuration < 0 || c.MaxRetryPicks < 0 {
+		return fmt.Errorf("minRate, steps, stepDuration and maxRetryPicks require >= 0")
+	}
+
+	// Apply defaults
+	if c.MinRate == 0 {
+		c.MinRate = DefaultAdaptiveMinRate
+	}
+	if c.Steps == 0 {
+		c.Steps = DefaultAdaptiveSteps
+	}
+	if c.StepDuration == 0 {
+		c.StepDuration = DefaultAdaptiveStepDuration
+	}
+
+	if c.MaxRate <= c.MinRate {
+		return fmt.Errorf("maxRate(%v) requires > minRate(%v)", c.MaxRate, c.MinRate)
+	}
+
+	if len(c.Requests) == 0 {
+		return fmt.Errorf("requests are required")
+	}
+	for idx, r := range c.Requests {
+		if r == nil {
+			return fmt.Errorf("idx: %v request: null", idx)
+		}
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("idx: %v request: %v", idx, err)
+		}
+	}
+	return nil
+}
+
+// ConfigureClientOptions implements ModeConfig for AdaptiveConfig
+func (c *AdaptiveConfig) ConfigureClientOptions() ClientOptions {
+	// NOTE: The rate is controlled by executor. The client's limit must
+	// not be lower than the searched rate.
+	return ClientOptions{
+		QPS: c.MaxRate,
+	}
+}
+
+// AdaptiveReport is the result of adaptive mode.
+type AdaptiveReport struct {
+	// TargetP99Seconds is the P99 latency target in seconds.
+	TargetP99Seconds float64 `json:"targetP99Seconds"`
+	// SustainableRate is the maximum probed rate whose P99 latency is under
+	// the target. Zero means none of probed rates meets the target.
+	SustainableRate float64 `json:"sustainableRate"`
+	// Steps are the probes in order.
+	Steps []AdaptiveStep `json:"steps"`
+}
+
+// AdaptiveStep is the result of probing one rate.
+type AdaptiveStep struct {
+	// Rate is the probed rate in requests per second.
+	Rate float64 `json:"rate"`
+	// Requests is the number of requests done during the probe.
+	Requests int `json:"requests"`
+	// Errors is the number of failed requests during the probe.
+	Errors int `json:"errors"`
+	// P99Seconds is the P99 latency in seconds. Failed requests are
+	// counted as infinite latency.
+	P99Seconds float64 `json:"p99Seconds"`
+	// Passed is true if P99 latency is under the target.
+	Passed bool `json:"passed"`
+}
+
+// ExecutorReport is the mode-specific report produced by executor.
+type ExecutorReport struct {
+	// WeightedRandom is the report of weighted-random mode.
+	WeightedRandom *WeightedRandomReport `json:"weightedRandom,omitempty"`
+	// Adaptive is the report of adaptive mode.
+	Adaptive *AdaptiveReport `json:"adaptive,omitempty"`
+	// Burst is the report of burst mode.
+	Burst *BurstReport `json:"burst,omitempty"`
+	// Trace is the report of trace mode.
+	Trace *TraceReport `json:"trace,omitempty"`
+	// TimeSeries is the report of time-series mode.
+	TimeSeries *TimeSeriesReport `json:"timeSeries,omitempty"`
+	// Children are the reports of composite mode's children by name.
+	Children map[string]*ExecutorReport `json:"children,omitempty"`
+}
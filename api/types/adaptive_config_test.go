@@ -0,0 +1,57 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestAdaptiveConfigUnmarshalAndValidate(t *testing.T) {
+	in := `
+conns: 1
+client: 1
+contentType: json
+mode: adaptive
+modeConfig:
+  targetP99Seconds: 0.5
+  maxRate: 1000
+  requests:
+  - shares: 1
+    staleList:
+      version: v1
+      resource: pods
+`
+	var spec LoadProfileSpec
+	require.NoError(t, yaml.Unmarshal([]byte(in), &spec))
+	require.Equal(t, ModeAdaptive, spec.Mode)
+
+	config, ok := spec.ModeConfig.(*AdaptiveConfig)
+	require.True(t, ok)
+	require.NoError(t, config.Validate(nil))
+	require.NoError(t, spec.Validate())
+
+	assert.Equal(t, 0.5, config.TargetP99Seconds)
+	assert.Equal(t, float64(DefaultAdaptiveMinRate), config.MinRate)
+	assert.Equal(t, DefaultAdaptiveSteps, config.Steps)
+	assert.Equal(t, DefaultAdaptiveStepDuration, config.StepDuration)
+	assert.Equal(t, float64(1000), config.ConfigureClientOptions().QPS)
+
+	for name, c := range map[string]AdaptiveConfig{
+		"no target":        {MaxRate: 10, Requests: config.Requests},
+		"max rate <= min":  {TargetP99Seconds: 1, MinRate: 10, MaxRate: 10, Requests: config.Requests},
+		"negative steps":   {TargetP99Seconds: 1, MaxRate: 10, Steps: -1, Requests: config.Requests},
+		"no requests":      {TargetP99Seconds: 1, MaxRate: 10},
+		"invalid requests": {TargetP99Seconds: 1, MaxRate: 10, Requests: []*WeightedRequest{{Shares: 1}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, c.Validate(nil))
+		})
+	}
+
+	assert.Error(t, config.ApplyOverrides(map[string]interface{}{"rate": float64(1)}))
+}
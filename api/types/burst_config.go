@@ -0,0 +1,103 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import "fmt"
+
+// BurstConfig defines configuration for burst execution mode. It alternates
+// full-rate bursts with idle periods, like controllers which resync
+// everything at once after doing nothing for minutes.
+type BurstConfig struct {
+	// BurstRate is the maximum requests per second during burst. Zero means
+	// no limit.
+	BurstRate float64 `json:"burstRate,omitempty" yaml:"burstRate,omitempty" mapstructure:"burstRate"`
+	// BurstDuration is the running time in seconds of each burst.
+	BurstDuration Seconds `json:"burstDuration" yaml:"burstDuration" mapstructure:"burstDuration"`
+	// IdleDuration is the time in seconds without requests after each burst.
+	IdleDuration Seconds `json:"idleDuration,omitempty" yaml:"idleDuration,omitempty" mapstructure:"idleDuration"`
+	// Cycles is the number of bursts.
+	Cycles int `json:"cycles" yaml:"cycles" mapstructure:"cycles"`
+	// Requests defines the different kinds of requests with weights.
+	Requests []*WeightedRequest `json:"requests" yaml:"requests" mapstructure:"requests"`
+	// MaxRetryPicks is the same as WeightedRandomConfig.MaxRetryPicks.
+	MaxRetryPicks int `json:"maxRetryPicks,omitempty" yaml:"maxRetryPicks,omitempty" mapstructure:"maxRetryPicks"`
+}
+
+// Ensure BurstConfig implements ModeConfig
+func (*BurstConfig) isModeConfig() {}
+
+// GetOverridableFields implements ModeConfig for BurstConfig
+func (c *BurstConfig) GetOverridableFields() []OverridableField {
+	return nil
+}
+
+// ApplyOverrides implements ModeConfig for BurstConfig
+func (c *BurstConfig) ApplyOverrides(overrides map[string]interface{}) error {
+	for key := range overrides {
+		return fmt.Errorf("unknown override key for burst mode: %s", key)
+	}
+	return nil
+}
+
+// Validate implements ModeConfig for BurstConfig
+func (c *BurstConfig) Validate(_ map[string]interface{}) error {
+	if c.BurstRate < 0 {
+		return fmt.Errorf("burstRate requires >= 0: %v", c.BurstRate)
+	}
+
+	if c.BurstDuration <= 0 {
+		return fmt.Errorf("burstDuration requires > 0: %v", c.BurstDuration)
+	}
+
+	if c.IdleDuration < 0 {
+		return fmt.Errorf("idleDuration requires >= 0: %v", c.IdleDuration)
+	}
+
+	if c.Cycles <= 0 {
+		return fmt.Errorf("cycles requires > 0: %v", c.Cycles)
+	}
+
+	if c.MaxRetryPicks < 0 {
+		return fmt.Errorf("maxRetryPicks requires >= 0: %v", c.MaxRetryPicks)
+	}
+
+	if len(c.Requests) == 0 {
+		return fmt.Errorf("requests are required")
+	}
+	for idx, r := range c.Requests {
+		if r == nil {
+			return fmt.Errorf("idx: %v request: null", idx)
+		}
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("idx: %v request: %v", idx, err)
+		}
+	}
+	return nil
+}
+
+// ConfigureClientOptions implements ModeConfig for BurstConfig
+func (c *BurstConfig) ConfigureClientOptions() ClientOptions {
+	return ClientOptions{
+		QPS: c.BurstRate,
+	}
+}
+
+// BurstReport is the result of burst mode.
+type BurstReport struct {
+	// Bursts are the statistics of each burst in order.
+	Bursts []BurstStats `json:"bursts"`
+}
+
+// BurstStats is the statistics of requests sent during one burst.
+type BurstStats struct {
+	// Index is the index of burst, starting from 0.
+	Index int `json:"index"`
+	// Requests is the number of requests done during the burst.
+	Requests int `json:"requests"`
+	// Errors is the number of failed requests during the burst.
+	Errors int `json:"errors"`
+	// PercentileLatencies represents the latency distribution in seconds
+	// of successful requests.
+	PercentileLatencies [][2]float64 `json:"percentileLatencies,omitempty"`
+}
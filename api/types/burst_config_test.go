@@ -0,0 +1,58 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestBurstConfigUnmarshalAndValidate(t *testing.T) {
+	in := `
+conns: 1
+client: 1
+contentType: json
+mode: burst
+modeConfig:
+  burstRate: 500
+  burstDuration: 10
+  idleDuration: 120
+  cycles: 5
+  requests:
+  - shares: 1
+    staleList:
+      version: v1
+      resource: pods
+`
+	var spec LoadProfileSpec
+	require.NoError(t, yaml.Unmarshal([]byte(in), &spec))
+	require.Equal(t, ModeBurst, spec.Mode)
+
+	config, ok := spec.ModeConfig.(*BurstConfig)
+	require.True(t, ok)
+	require.NoError(t, spec.Validate())
+
+	assert.Equal(t, float64(500), config.BurstRate)
+	assert.Equal(t, Seconds(10), config.BurstDuration)
+	assert.Equal(t, Seconds(120), config.IdleDuration)
+	assert.Equal(t, 5, config.Cycles)
+	assert.Equal(t, float64(500), config.ConfigureClientOptions().QPS)
+
+	for name, c := range map[string]BurstConfig{
+		"zero burst duration": {BurstDuration: 0, Cycles: 1, Requests: config.Requests},
+		"zero cycles":         {BurstDuration: 1, Cycles: 0, Requests: config.Requests},
+		"negative idle":       {BurstDuration: 1, IdleDuration: -1, Cycles: 1, Requests: config.Requests},
+		"negative rate":       {BurstRate: -1, BurstDuration: 1, Cycles: 1, Requests: config.Requests},
+		"no requests":         {BurstDuration: 1, Cycles: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, c.Validate(nil))
+		})
+	}
+
+	assert.Error(t, config.ApplyOverrides(map[string]interface{}{"rate": float64(1)}))
+}
@@ -0,0 +1,184 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CompositeConfig defines configuration for composite execution mode. It
+// runs child modes concurrently and their requests share the connections,
+// like weighted-random background traffic alongside time-series replay.
+type CompositeConfig struct {
+	// Children are the child modes with their own settings.
+	Children []CompositeChild `json:"children" yaml:"children" mapstructure:"children"`
+}
+
+// CompositeChild is one child mode of composite mode.
+type CompositeChild struct {
+	// Name identifies the child in metadata and report. Empty means
+	// child-<index>.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Mode defines the execution strategy of the child.
+	Mode ExecutionMode `json:"mode" yaml:"mode"`
+	// ModeConfig contains mode-specific configuration of the child.
+	ModeConfig ModeConfig `json:"modeConfig" yaml:"modeConfig"`
+}
+
+// UnmarshalYAML implements custom YAML unmarshaling for CompositeChild.
+// It deserializes ModeConfig to the correct concrete type based on Mode.
+func (c *CompositeChild) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type tempChild struct {
+		Name       string                 `yaml:"name"`
+		Mode       ExecutionMode          `yaml:"mode"`
+		ModeConfig map[string]interface{} `yaml:"modeConfig"`
+	}
+
+	temp := &tempChild{}
+	if err := unmarshal(temp); err != nil {
+		return err
+	}
+
+	c.Name = temp.Name
+	c.Mode = temp.Mode
+	c.ModeConfig = nil
+	if temp.ModeConfig == nil {
+		return nil
+	}
+
+	if err := checkModeConfigKeys(temp.Mode, temp.ModeConfig, "yaml"); err != nil {
+		return err
+	}
+
+	config, err := newModeConfig(temp.Mode)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(temp.ModeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to marshal modeConfig: %w", err)
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("failed to unmarshal modeConfig for mode %s: %w", temp.Mode, err)
+	}
+	c.ModeConfig = config
+	return nil
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for CompositeChild.
+// It deserializes ModeConfig to the correct concrete type based on Mode.
+func (c *CompositeChild) UnmarshalJSON(data []byte) error {
+	type tempChild struct {
+		Name       string          `json:"name"`
+		Mode       ExecutionMode   `json:"mode"`
+		ModeConfig json.RawMessage `json:"modeConfig"`
+	}
+
+	temp := &tempChild{}
+	if err := json.Unmarshal(data, temp); err != nil {
+		return err
+	}
+
+	c.Name = temp.Name
+	c.Mode = temp.Mode
+	c.ModeConfig = nil
+	if len(temp.ModeConfig) == 0 || string(temp.ModeConfig) == "null" {
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(temp.ModeConfig, &fields); err != nil {
+		return fmt.Errorf("failed to unmarshal modeConfig for mode %s: %w", temp.Mode, err)
+	}
+	if err := checkModeConfigKeys(temp.Mode, fields, "json"); err != nil {
+		return err
+	}
+
+	config, err := newModeConfig(temp.Mode)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(temp.ModeConfig, config); err != nil {
+		return fmt.Errorf("failed to unmarshal modeConfig for mode %s: %w", temp.Mode, err)
+	}
+	c.ModeConfig = config
+	return nil
+}
+
+// Ensure CompositeConfig implements ModeConfig
+func (*CompositeConfig) isModeConfig() {}
+
+// GetOverridableFields implements ModeConfig for CompositeConfig
+func (c *CompositeConfig) GetOverridableFields() []OverridableField {
+	return nil
+}
+
+// ApplyOverrides implements ModeConfig for CompositeConfig
+func (c *CompositeConfig) ApplyOverrides(overrides map[string]interface{}) error {
+	for key := range overrides {
+		return fmt.Errorf("unknown override key for composite mode: %s", key)
+	}
+	return nil
+}
+
+// Validate implements ModeConfig for CompositeConfig. It also validates
+// children with the same defaultOverrides.
+func (c *CompositeConfig) Validate(defaultOverrides map[string]interface{}) error {
+	if len(c.Children) == 0 {
+		return fmt.Errorf("children are required")
+	}
+
+	names := make(map[string]struct{}, len(c.Children))
+	for idx := range c.Children {
+		child := &c.Children[idx]
+		if child.Name == "" {
+			child.Name = fmt.Sprintf("child-%d", idx)
+		}
+		if _, ok := names[child.Name]; ok {
+			return fmt.Errorf("duplicate child name: %s", child.Name)
+		}
+		names[child.Name] = struct{}{}
+
+		if err := child.Mode.Validate(); err != nil {
+			return fmt.Errorf("child %s: %w", child.Name, err)
+		}
+
+		// NOTE: Adaptive and burst modes require response feedback which
+		// can't be attributed to the child.
+		switch child.Mode {
+		case ModeComposite, ModeAdaptive, ModeBurst:
+			return fmt.Errorf("child %s: mode %s is not supported in composite mode", child.Name, child.Mode)
+		}
+
+		if child.ModeConfig == nil {
+			return fmt.Errorf("child %s: modeConfig is required", child.Name)
+		}
+		if err := child.ModeConfig.Validate(defaultOverrides); err != nil {
+			return fmt.Errorf("child %s: %w", child.Name, err)
+		}
+	}
+	return nil
+}
+
+// ConfigureClientOptions implements ModeConfig for CompositeConfig. The QPS
+// is the sum of children's, and it's no limit if any child has no limit.
+func (c *CompositeConfig) ConfigureClientOptions() ClientOptions {
+	var qps float64
+	for _, child := range c.Children {
+		if child.ModeConfig == nil {
+			continue
+		}
+
+		childQPS := child.ModeConfig.ConfigureClientOptions().QPS
+		if childQPS <= 0 {
+			return ClientOptions{QPS: 0}
+		}
+		qps += childQPS
+	}
+	return ClientOptions{QPS: qps}
+}
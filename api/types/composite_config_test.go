@@ -0,0 +1,111 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestCompositeConfigUnmarshalAndValidate(t *testing.T) {
+	in := `
+conns: 1
+client: 1
+contentType: json
+mode: composite
+modeConfig:
+  children:
+  - name: background
+    mode: weighted-random
+    modeConfig:
+      rate: 10
+      requests:
+      - shares: 1
+        staleList:
+          version: v1
+          resource: pods
+  - mode: time-series
+    modeConfig:
+      interval: 1s
+      buckets:
+      - startTime: 0
+        requests:
+        - method: GET
+          version: v1
+          resource: pods
+          namespace: default
+          name: a
+`
+	var spec LoadProfileSpec
+	require.NoError(t, yaml.Unmarshal([]byte(in), &spec))
+	require.Equal(t, ModeComposite, spec.Mode)
+
+	config, ok := spec.ModeConfig.(*CompositeConfig)
+	require.True(t, ok)
+	require.NoError(t, config.Validate(map[string]interface{}{"total": 100}))
+	require.NoError(t, spec.Validate())
+
+	require.Len(t, config.Children, 2)
+	assert.Equal(t, "background", config.Children[0].Name)
+	assert.Equal(t, "child-1", config.Children[1].Name)
+
+	background, ok := config.Children[0].ModeConfig.(*WeightedRandomConfig)
+	require.True(t, ok)
+	assert.Equal(t, float64(10), background.Rate)
+	assert.Equal(t, 100, background.Total)
+
+	_, ok = config.Children[1].ModeConfig.(*TimeSeriesConfig)
+	require.True(t, ok)
+
+	// Time-series child has no limit.
+	assert.Equal(t, float64(0), config.ConfigureClientOptions().QPS)
+
+	// JSON round-trip keeps children's config types.
+	data, err := json.Marshal(spec)
+	require.NoError(t, err)
+	var again LoadProfileSpec
+	require.NoError(t, json.Unmarshal(data, &again))
+	assert.Equal(t, spec, again)
+
+	for name, c := range map[string]CompositeConfig{
+		"no children": {},
+		"duplicate name": {Children: []CompositeChild{
+			{Name: "a", Mode: ModeWeightedRandom, ModeConfig: &WeightedRandomConfig{}},
+			{Name: "a", Mode: ModeWeightedRandom, ModeConfig: &WeightedRandomConfig{}},
+		}},
+		"nested composite": {Children: []CompositeChild{
+			{Mode: ModeComposite, ModeConfig: &CompositeConfig{}},
+		}},
+		"adaptive child": {Children: []CompositeChild{
+			{Mode: ModeAdaptive, ModeConfig: &AdaptiveConfig{}},
+		}},
+		"no modeConfig": {Children: []CompositeChild{
+			{Mode: ModeWeightedRandom},
+		}},
+		"invalid child": {Children: []CompositeChild{
+			{Mode: ModeWeightedRandom, ModeConfig: &WeightedRandomConfig{MaxRetryPicks: -1}},
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, c.Validate(nil))
+		})
+	}
+
+	// Burst mode is valid by itself, but it requires response feedback
+	// which can't be attributed to the child.
+	burst := &BurstConfig{
+		BurstDuration: 1,
+		Cycles:        1,
+		Requests: []*WeightedRequest{
+			{Shares: 1, StaleList: &RequestList{KubeGroupVersionResource: KubeGroupVersionResource{Version: "v1", Resource: "pods"}}},
+		},
+	}
+	require.NoError(t, burst.Validate(nil))
+	c := CompositeConfig{Children: []CompositeChild{{Mode: ModeBurst, ModeConfig: burst}}}
+	assert.ErrorContains(t, c.Validate(nil), "mode burst is not supported in composite mode")
+}
@@ -0,0 +1,136 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Consistency defines how apiserver serves get and list requests.
+type Consistency string
+
+const (
+	// ConsistencyStale reads with zero resource version, which is served
+	// from kube-apiserver's watch cache.
+	ConsistencyStale Consistency = "stale"
+	// ConsistencyQuorum reads the most recent data without resource
+	// version. It's the default.
+	ConsistencyQuorum Consistency = "quorum"
+	// ConsistencyExact reads at the given resource version exactly. It's
+	// only supported by list.
+	ConsistencyExact Consistency = "exact"
+)
+
+// Validate returns error if Consistency is not supported.
+func (c Consistency) Validate() error {
+	switch c {
+	case "", ConsistencyStale, ConsistencyQuorum, ConsistencyExact:
+		return nil
+	default:
+		return fmt.Errorf("unsupported consistency: %s", c)
+	}
+}
+
+// typePrefix returns the prefix of request type for the consistency, like
+// stale in staleList, so that the types in report are the same as the
+// deprecated request types.
+func (c Consistency) typePrefix() string {
+	if c == "" {
+		return string(ConsistencyQuorum)
+	}
+	return string(c)
+}
+
+// ReadResourceVersion returns the resource version of get or list request
+// for the consistency. The resourceVersion is only used by exact.
+func (c Consistency) ReadResourceVersion(resourceVersion string) string {
+	switch c {
+	case ConsistencyStale:
+		return "0"
+	case ConsistencyExact:
+		return resourceVersion
+	default:
+		return ""
+	}
+}
+
+// WithoutDeprecated returns the request whose deprecated staleList,
+// quorumList, staleGet or quorumGet is converted into list or get with the
+// corresponding consistency. The request is returned as it is if there is
+// nothing to convert. Unmarshalers convert requests already, so it's only
+// required by requests created in Go.
+func (r *WeightedRequest) WithoutDeprecated() (*WeightedRequest, error) {
+	var (
+		get         *RequestGet
+		list        *RequestList
+		consistency Consistency
+		deprecated  string
+	)
+	switch {
+	case r.StaleList != nil:
+		list, consistency, deprecated = r.StaleList, ConsistencyStale, "staleList"
+	case r.QuorumList != nil:
+		list, consistency, deprecated = r.QuorumList, ConsistencyQuorum, "quorumList"
+	case r.StaleGet != nil:
+		get, consistency, deprecated = r.StaleGet, ConsistencyStale, "staleGet"
+	case r.QuorumGet != nil:
+		get, consistency, deprecated = r.QuorumGet, ConsistencyQuorum, "quorumGet"
+	default:
+		return r, nil
+	}
+
+	if r.Get != nil || r.List != nil {
+		return nil, fmt.Errorf("%s can't be used with get or list", deprecated)
+	}
+
+	copied := *r
+	copied.StaleList, copied.QuorumList, copied.StaleGet, copied.QuorumGet = nil, nil, nil, nil
+	if list != nil {
+		if list.Consistency != "" || list.ResourceVersion != "" {
+			return nil, fmt.Errorf("%s doesn't support consistency and resourceVersion, use list instead", deprecated)
+		}
+		converted := *list
+		converted.Consistency = consistency
+		copied.List = &converted
+	} else {
+		if get.Consistency != "" {
+			return nil, fmt.Errorf("%s doesn't support consistency, use get instead", deprecated)
+		}
+		converted := *get
+		converted.Consistency = consistency
+		copied.Get = &converted
+	}
+	return &copied, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. The deprecated request types
+// are converted into get or list with consistency.
+func (r *WeightedRequest) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain WeightedRequest
+	if err := unmarshal((*plain)(r)); err != nil {
+		return err
+	}
+	return r.convertDeprecated()
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The deprecated request types
+// are converted into get or list with consistency.
+func (r *WeightedRequest) UnmarshalJSON(data []byte) error {
+	type plain WeightedRequest
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	return r.convertDeprecated()
+}
+
+// convertDeprecated converts the deprecated request types in place.
+func (r *WeightedRequest) convertDeprecated() error {
+	converted, err := r.WithoutDeprecated()
+	if err != nil {
+		return err
+	}
+	*r = *converted
+	return nil
+}
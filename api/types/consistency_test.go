@@ -0,0 +1,179 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestWeightedRequestUnmarshalDeprecated(t *testing.T) {
+	for name, tc := range map[string]struct {
+		yaml         string
+		json         string
+		expectedType string
+		consistency  Consistency
+		err          bool
+	}{
+		"staleList": {
+			yaml:         "shares: 1\nstaleList:\n  version: v1\n  resource: pods\n",
+			json:         `{"shares":1,"staleList":{"version":"v1","resource":"pods"}}`,
+			expectedType: "staleList",
+			consistency:  ConsistencyStale,
+		},
+		"quorumList": {
+			yaml:         "shares: 1\nquorumList:\n  version: v1\n  resource: pods\n  limit: 10\n",
+			json:         `{"shares":1,"quorumList":{"version":"v1","resource":"pods","limit":10}}`,
+			expectedType: "quorumList",
+			consistency:  ConsistencyQuorum,
+		},
+		"staleGet": {
+			yaml:         "shares: 1\nstaleGet:\n  version: v1\n  resource: pods\n  name: a\n",
+			json:         `{"shares":1,"staleGet":{"version":"v1","resource":"pods","name":"a"}}`,
+			expectedType: "staleGet",
+			consistency:  ConsistencyStale,
+		},
+		"quorumGet": {
+			yaml:         "shares: 1\nquorumGet:\n  version: v1\n  resource: pods\n  name: a\n",
+			json:         `{"shares":1,"quorumGet":{"version":"v1","resource":"pods","name":"a"}}`,
+			expectedType: "quorumGet",
+			consistency:  ConsistencyQuorum,
+		},
+		"list": {
+			yaml:         "shares: 1\nlist:\n  version: v1\n  resource: pods\n  consistency: exact\n  resourceVersion: \"100\"\n",
+			json:         `{"shares":1,"list":{"version":"v1","resource":"pods","consistency":"exact","resourceVersion":"100"}}`,
+			expectedType: "exactList",
+			consistency:  ConsistencyExact,
+		},
+		"get without consistency": {
+			yaml:         "shares: 1\nget:\n  version: v1\n  resource: pods\n  name: a\n",
+			json:         `{"shares":1,"get":{"version":"v1","resource":"pods","name":"a"}}`,
+			expectedType: "quorumGet",
+		},
+		"deprecated with consistency": {
+			yaml: "shares: 1\nstaleList:\n  version: v1\n  resource: pods\n  consistency: quorum\n",
+			json: `{"shares":1,"staleList":{"version":"v1","resource":"pods","consistency":"quorum"}}`,
+			err:  true,
+		},
+		"deprecated with list": {
+			yaml: "shares: 1\nstaleGet:\n  version: v1\n  resource: pods\n  name: a\nlist:\n  version: v1\n  resource: pods\n",
+			json: `{"shares":1,"staleGet":{"version":"v1","resource":"pods","name":"a"},"list":{"version":"v1","resource":"pods"}}`,
+			err:  true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var fromYAML, fromJSON WeightedRequest
+
+			errYAML := yaml.Unmarshal([]byte(tc.yaml), &fromYAML)
+			errJSON := json.Unmarshal([]byte(tc.json), &fromJSON)
+			if tc.err {
+				assert.Error(t, errYAML)
+				assert.Error(t, errJSON)
+				return
+			}
+			require.NoError(t, errYAML)
+			require.NoError(t, errJSON)
+			assert.Equal(t, fromYAML, fromJSON)
+
+			for _, r := range []WeightedRequest{fromYAML, fromJSON} {
+				assert.Nil(t, r.StaleList)
+				assert.Nil(t, r.QuorumList)
+				assert.Nil(t, r.StaleGet)
+				assert.Nil(t, r.QuorumGet)
+				assert.Equal(t, tc.expectedType, r.Type())
+				assert.NoError(t, r.Validate())
+
+				if r.List != nil {
+					assert.Equal(t, tc.consistency, r.List.Consistency)
+				} else {
+					require.NotNil(t, r.Get)
+					assert.Equal(t, tc.consistency, r.Get.Consistency)
+				}
+			}
+		})
+	}
+}
+
+func TestWeightedRequestWithoutDeprecated(t *testing.T) {
+	gvr := KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+
+	staleList := &RequestList{KubeGroupVersionResource: gvr}
+	r := &WeightedRequest{Shares: 1, StaleList: staleList}
+
+	converted, err := r.WithoutDeprecated()
+	require.NoError(t, err)
+	assert.Nil(t, converted.StaleList)
+	require.NotNil(t, converted.List)
+	assert.Equal(t, ConsistencyStale, converted.List.Consistency)
+	assert.Equal(t, "staleList", converted.Type())
+
+	// The original request isn't changed.
+	assert.Same(t, staleList, r.StaleList)
+	assert.Empty(t, staleList.Consistency)
+
+	get := &WeightedRequest{Shares: 1, Get: &RequestGet{KubeGroupVersionResource: gvr, Name: "a"}}
+	converted, err = get.WithoutDeprecated()
+	require.NoError(t, err)
+	assert.Same(t, get, converted)
+}
+
+func TestRequestConsistencyValidate(t *testing.T) {
+	gvr := KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+
+	for name, tc := range map[string]struct {
+		req WeightedRequest
+		err bool
+	}{
+		"stale list": {
+			req: WeightedRequest{List: &RequestList{KubeGroupVersionResource: gvr, Consistency: ConsistencyStale}},
+		},
+		"stale list with limit": {
+			req: WeightedRequest{List: &RequestList{KubeGroupVersionResource: gvr, Consistency: ConsistencyStale, Limit: 10}},
+			err: true,
+		},
+		"exact list": {
+			req: WeightedRequest{List: &RequestList{KubeGroupVersionResource: gvr, Consistency: ConsistencyExact, ResourceVersion: "10", Limit: 10}},
+		},
+		"exact list without resourceVersion": {
+			req: WeightedRequest{List: &RequestList{KubeGroupVersionResource: gvr, Consistency: ConsistencyExact}},
+			err: true,
+		},
+		"quorum list with resourceVersion": {
+			req: WeightedRequest{List: &RequestList{KubeGroupVersionResource: gvr, Consistency: ConsistencyQuorum, ResourceVersion: "10"}},
+			err: true,
+		},
+		"unknown consistency": {
+			req: WeightedRequest{List: &RequestList{KubeGroupVersionResource: gvr, Consistency: "strong"}},
+			err: true,
+		},
+		"exact get": {
+			req: WeightedRequest{Get: &RequestGet{KubeGroupVersionResource: gvr, Name: "a", Consistency: ConsistencyExact}},
+			err: true,
+		},
+		"deprecated stale list with limit": {
+			req: WeightedRequest{StaleList: &RequestList{KubeGroupVersionResource: gvr, Limit: 10}},
+			err: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.req.Validate()
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestConsistencyReadResourceVersion(t *testing.T) {
+	assert.Equal(t, "0", ConsistencyStale.ReadResourceVersion("10"))
+	assert.Equal(t, "", ConsistencyQuorum.ReadResourceVersion("10"))
+	assert.Equal(t, "", Consistency("").ReadResourceVersion("10"))
+	assert.Equal(t, "10", ConsistencyExact.ReadResourceVersion("10"))
+}
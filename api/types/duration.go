@@ -0,0 +1,94 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Seconds is a duration in whole seconds. It can be unmarshaled from either
+// an integer of seconds, like 300, or a duration string, like "5m" or
+// "1h30m". It's always marshaled as an integer for compatibility.
+type Seconds int
+
+// ParseSeconds parses either an integer of seconds or a duration string.
+func ParseSeconds(s string) (Seconds, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return Seconds(n), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: requires seconds or duration string like 90s", s)
+	}
+	if d%time.Second != 0 {
+		return 0, fmt.Errorf("invalid duration %q: requires whole seconds", s)
+	}
+	return Seconds(d / time.Second), nil
+}
+
+// Duration returns time.Duration.
+func (s Seconds) Duration() time.Duration {
+	return time.Duration(s) * time.Second
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Seconds) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		v, err := ParseSeconds(str)
+		if err != nil {
+			return err
+		}
+		*s = v
+		return nil
+	}
+
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid duration %s: requires seconds or duration string like 90s", data)
+	}
+	*s = Seconds(n)
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *Seconds) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var n int
+	if err := unmarshal(&n); err == nil {
+		*s = Seconds(n)
+		return nil
+	}
+
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	v, err := ParseSeconds(str)
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// secondsFromOverride converts the override value, which is an integer of
+// seconds or a string parsed by ParseSeconds, to Seconds.
+func secondsFromOverride(value interface{}) (Seconds, error) {
+	switch v := value.(type) {
+	case int:
+		return Seconds(v), nil
+	case Seconds:
+		return v, nil
+	case string:
+		return ParseSeconds(v)
+	default:
+		return 0, fmt.Errorf("must be int or duration string, got %T", value)
+	}
+}
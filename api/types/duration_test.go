@@ -0,0 +1,101 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestParseSeconds(t *testing.T) {
+	for in, expected := range map[string]Seconds{
+		"300":   300,
+		"0":     0,
+		"90s":   90,
+		"5m":    300,
+		"1h30m": 5400,
+		" 10 ":  10,
+	} {
+		got, err := ParseSeconds(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, expected, got, in)
+	}
+
+	for _, in := range []string{"", "5x", "1.5", "500ms", "abc"} {
+		_, err := ParseSeconds(in)
+		assert.Error(t, err, in)
+	}
+	assert.Equal(t, 90*time.Second, Seconds(90).Duration())
+}
+
+func TestSecondsUnmarshal(t *testing.T) {
+	type target struct {
+		Duration Seconds `json:"duration" yaml:"duration"`
+	}
+
+	for in, expected := range map[string]Seconds{
+		`300`:     300,
+		`"300"`:   300,
+		`"5m"`:    300,
+		`"1h30m"`: 5400,
+	} {
+		var y target
+		require.NoError(t, yaml.Unmarshal([]byte("duration: "+in), &y), in)
+		assert.Equal(t, expected, y.Duration, in)
+
+		var j target
+		require.NoError(t, json.Unmarshal([]byte(`{"duration":`+in+`}`), &j), in)
+		assert.Equal(t, expected, j.Duration, in)
+	}
+
+	for _, in := range []string{`"5x"`, `"500ms"`, `true`, `[1]`} {
+		var y target
+		assert.Error(t, yaml.Unmarshal([]byte("duration: "+in), &y), in)
+
+		var j target
+		assert.Error(t, json.Unmarshal([]byte(`{"duration":`+in+`}`), &j), in)
+	}
+
+	// It's always marshaled as seconds.
+	data, err := json.Marshal(target{Duration: 300})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"duration":300}`, string(data))
+}
+
+func TestWeightedRandomConfigDurationString(t *testing.T) {
+	in := `
+version: 1
+spec:
+  conns: 1
+  client: 1
+  mode: weighted-random
+  contentType: json
+  connectTimeoutSeconds: 5s
+  modeConfig:
+    duration: 5m
+    requests:
+    - shares: 1
+      staleList:
+        version: v1
+        resource: pods
+`
+	var lp LoadProfile
+	require.NoError(t, yaml.Unmarshal([]byte(in), &lp))
+	require.NoError(t, lp.Validate())
+	assert.Equal(t, Seconds(5), lp.Spec.ConnectTimeoutSeconds)
+
+	config := lp.Spec.ModeConfig.(*WeightedRandomConfig)
+	assert.Equal(t, Seconds(300), config.Duration)
+
+	require.NoError(t, config.ApplyOverrides(map[string]interface{}{"duration": "90s"}))
+	assert.Equal(t, Seconds(90), config.Duration)
+	require.NoError(t, config.ApplyOverrides(map[string]interface{}{"duration": 120}))
+	assert.Equal(t, Seconds(120), config.Duration)
+	assert.Error(t, config.ApplyOverrides(map[string]interface{}{"duration": "5x"}))
+}
@@ -0,0 +1,123 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RunnerIndexEnv is the environment variable which holds the index of
+// runner instance. The runner uses it as KeySpaceShard.KeySpaceOffset if
+// the offset isn't set.
+const RunnerIndexEnv = "KPERF_RUNNER_INDEX"
+
+// KeySpaceShard splits the key space of mutating requests into disjoint
+// shards so that multiple runners sharing one load profile don't touch
+// the same objects.
+type KeySpaceShard struct {
+	// KeySpacePartitions is the number of shards. Zero means the whole
+	// key space is used.
+	KeySpacePartitions int `json:"keySpacePartitions,omitempty" yaml:"keySpacePartitions,omitempty"`
+	// KeySpaceOffset is the shard used by this runner, in the range of
+	// [0, KeySpacePartitions). If it's unset, the runner fills it from
+	// KPERF_RUNNER_INDEX environment variable.
+	KeySpaceOffset *int `json:"keySpaceOffset,omitempty" yaml:"keySpaceOffset,omitempty"`
+}
+
+// Validate verifies the shard. The keySpaceSize is the size of whole key space
+// and zero means the key space is unbounded.
+func (s *KeySpaceShard) Validate(keySpaceSize int) error {
+	if s.KeySpacePartitions < 0 {
+		return fmt.Errorf("keySpacePartitions requires >= 0: %v", s.KeySpacePartitions)
+	}
+
+	if s.KeySpacePartitions == 0 {
+		if s.KeySpaceOffset != nil {
+			return fmt.Errorf("keySpaceOffset requires keySpacePartitions")
+		}
+		return nil
+	}
+
+	if s.KeySpaceOffset == nil {
+		return fmt.Errorf("keySpaceOffset or %s is required if keySpacePartitions is set", RunnerIndexEnv)
+	}
+
+	offset := *s.KeySpaceOffset
+	if offset < 0 || offset >= s.KeySpacePartitions {
+		return fmt.Errorf("keySpaceOffset(%v) requires >= 0 and < keySpacePartitions(%v)",
+			offset, s.KeySpacePartitions)
+	}
+
+	if keySpaceSize > 0 && keySpaceSize < s.KeySpacePartitions {
+		return fmt.Errorf("keySpaceSize(%v) requires >= keySpacePartitions(%v), otherwise shard is empty",
+			keySpaceSize, s.KeySpacePartitions)
+	}
+	return nil
+}
+
+// KeySpaceRange returns the range [low, high) of shard in the key space
+// [0, keySpaceSize). The remainder is spread over the leading shards.
+func (s *KeySpaceShard) KeySpaceRange(keySpaceSize int) (low, high int64) {
+	if s.KeySpacePartitions == 0 || s.KeySpaceOffset == nil {
+		return 0, int64(keySpaceSize)
+	}
+
+	size, parts, offset := int64(keySpaceSize), int64(s.KeySpacePartitions), int64(*s.KeySpaceOffset)
+	return size * offset / parts, size * (offset + 1) / parts
+}
+
+// KeySpaceIndex maps the seq-th key, starting from 1, of unbounded key
+// space into the shard. It's seq if there is no partition.
+func (s *KeySpaceShard) KeySpaceIndex(seq int64) int64 {
+	if s.KeySpacePartitions == 0 || s.KeySpaceOffset == nil {
+		return seq
+	}
+	return (seq-1)*int64(s.KeySpacePartitions) + int64(*s.KeySpaceOffset) + 1
+}
+
+// Shard returns the shard in {offset}/{partitions} format. It's empty if
+// there is no partition.
+func (s *KeySpaceShard) Shard() string {
+	if s.KeySpacePartitions == 0 || s.KeySpaceOffset == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", *s.KeySpaceOffset, s.KeySpacePartitions)
+}
+
+// SetDefaultKeySpaceOffset sets offset as KeySpaceOffset of all the
+// partitioned shards in spec whose offset isn't set.
+func SetDefaultKeySpaceOffset(spec *LoadProfileSpec, offset int) {
+	setDefaultKeySpaceOffset(reflect.ValueOf(spec), offset)
+}
+
+var keySpaceShardType = reflect.TypeOf(KeySpaceShard{})
+
+// setDefaultKeySpaceOffset walks v and fills the unset offsets in place.
+func setDefaultKeySpaceOffset(v reflect.Value, offset int) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			setDefaultKeySpaceOffset(v.Elem(), offset)
+		}
+	case reflect.Struct:
+		if v.Type() == keySpaceShardType && v.CanSet() {
+			s := v.Addr().Interface().(*KeySpaceShard)
+			if s.KeySpacePartitions > 0 && s.KeySpaceOffset == nil {
+				o := offset
+				s.KeySpaceOffset = &o
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				setDefaultKeySpaceOffset(v.Field(i), offset)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			setDefaultKeySpaceOffset(v.Index(i), offset)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestKeySpaceShardValidate(t *testing.T) {
+	offset := func(v int) *int { return &v }
+
+	for name, tc := range map[string]struct {
+		shard KeySpaceShard
+		size  int
+		err   bool
+	}{
+		"no partition":              {shard: KeySpaceShard{}, size: 10},
+		"negative partitions":       {shard: KeySpaceShard{KeySpacePartitions: -1}, size: 10, err: true},
+		"offset without partitions": {shard: KeySpaceShard{KeySpaceOffset: offset(0)}, size: 10, err: true},
+		"offset":                    {shard: KeySpaceShard{KeySpacePartitions: 4, KeySpaceOffset: offset(3)}, size: 10},
+		"offset out of range":       {shard: KeySpaceShard{KeySpacePartitions: 4, KeySpaceOffset: offset(4)}, size: 10, err: true},
+		"negative offset":           {shard: KeySpaceShard{KeySpacePartitions: 4, KeySpaceOffset: offset(-1)}, size: 10, err: true},
+		"empty shard":               {shard: KeySpaceShard{KeySpacePartitions: 4, KeySpaceOffset: offset(0)}, size: 3, err: true},
+		"unbounded key space":       {shard: KeySpaceShard{KeySpacePartitions: 4, KeySpaceOffset: offset(1)}, size: 0},
+		"no offset":                 {shard: KeySpaceShard{KeySpacePartitions: 4}, size: 10, err: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.shard.Validate(tc.size)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	// NOTE: Validate doesn't read the offset from environment.
+	t.Setenv(RunnerIndexEnv, "2")
+	shard := KeySpaceShard{KeySpacePartitions: 4}
+	assert.Error(t, shard.Validate(10))
+	assert.Nil(t, shard.KeySpaceOffset)
+}
+
+func TestSetDefaultKeySpaceOffset(t *testing.T) {
+	offset := 1
+	spec := LoadProfileSpec{
+		Mode: ModeComposite,
+		ModeConfig: &CompositeConfig{
+			Children: []CompositeChild{
+				{
+					Mode: ModeWeightedRandom,
+					ModeConfig: &WeightedRandomConfig{
+						Requests: []*WeightedRequest{
+							{Shares: 1, Put: &RequestPut{KeySpaceShard: KeySpaceShard{KeySpacePartitions: 4}}},
+							{Shares: 1, Patch: &RequestPatch{KeySpaceShard: KeySpaceShard{KeySpacePartitions: 4, KeySpaceOffset: &offset}}},
+							{Shares: 1, PostDel: &RequestPostDel{}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	SetDefaultKeySpaceOffset(&spec, 2)
+
+	reqs := spec.ModeConfig.(*CompositeConfig).Children[0].ModeConfig.(*WeightedRandomConfig).Requests
+	assert.Equal(t, "2/4", reqs[0].Put.Shard())
+	assert.Equal(t, "1/4", reqs[1].Patch.Shard())
+	assert.Nil(t, reqs[2].PostDel.KeySpaceOffset)
+}
+
+func TestKeySpaceShardRange(t *testing.T) {
+	shard := KeySpaceShard{}
+	low, high := shard.KeySpaceRange(10)
+	assert.Equal(t, []int64{0, 10}, []int64{low, high})
+	assert.Equal(t, int64(7), shard.KeySpaceIndex(7))
+	assert.Empty(t, shard.Shard())
+
+	// NOTE: The shards are disjoint and cover the whole key space.
+	next := int64(0)
+	for i := 0; i < 4; i++ {
+		offset := i
+		shard := KeySpaceShard{KeySpacePartitions: 4, KeySpaceOffset: &offset}
+		require.NoError(t, shard.Validate(10))
+
+		low, high := shard.KeySpaceRange(10)
+		assert.Equal(t, next, low)
+		assert.Greater(t, high, low)
+		next = high
+
+		for seq := int64(1); seq <= 3; seq++ {
+			assert.Equal(t, int64(i), (shard.KeySpaceIndex(seq)-1)%4)
+		}
+	}
+	assert.Equal(t, int64(10), next)
+}
+
+func TestKeySpaceShardUnmarshalYAML(t *testing.T) {
+	in := `
+version: v1
+resource: configmaps
+namespace: default
+name: kperf
+keySpaceSize: 100
+keySpacePartitions: 4
+keySpaceOffset: 1
+patchType: merge
+body: "{}"
+`
+	var patch RequestPatch
+	require.NoError(t, yaml.Unmarshal([]byte(in), &patch))
+	require.NoError(t, patch.Validate())
+	assert.Equal(t, "1/4", patch.Shard())
+
+	low, high := patch.KeySpaceRange(patch.KeySpaceSize)
+	assert.Equal(t, []int64{25, 50}, []int64{low, high})
+}
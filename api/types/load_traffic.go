@@ -4,12 +4,20 @@
 package types
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net"
+	"reflect"
 	"strings"
+	"text/template"
+	"time"
 
-	apitypes "k8s.io/apimachinery/pkg/types"
 	"gopkg.in/yaml.v2"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // ContentType represents the format of response.
@@ -40,14 +48,52 @@ const (
 	ModeWeightedRandom ExecutionMode = "weighted-random"
 	// ModeTimeSeries replays requests from time-bucketed audit logs.
 	ModeTimeSeries ExecutionMode = "time-series"
+	// ModeAdaptive searches the maximum rate which keeps P99 latency under
+	// the target.
+	ModeAdaptive ExecutionMode = "adaptive"
+	// ModeBurst alternates full-rate bursts with idle periods.
+	ModeBurst ExecutionMode = "burst"
+	// ModeTrace replays requests with per-request timestamps from file.
+	ModeTrace ExecutionMode = "trace"
+	// ModeComposite runs multiple modes concurrently, like weighted-random
+	// background traffic alongside time-series replay.
+	ModeComposite ExecutionMode = "composite"
 )
 
-// Validate returns error if ExecutionMode is not supported.
+// newModeConfig returns the empty ModeConfig of the given mode, including
+// the custom modes registered by RegisterModeConfig.
+func newModeConfig(mode ExecutionMode) (ModeConfig, error) {
+	switch mode {
+	case ModeWeightedRandom:
+		return &WeightedRandomConfig{}, nil
+	case ModeTimeSeries:
+		return &TimeSeriesConfig{}, nil
+	case ModeAdaptive:
+		return &AdaptiveConfig{}, nil
+	case ModeBurst:
+		return &BurstConfig{}, nil
+	case ModeTrace:
+		return &TraceConfig{}, nil
+	case ModeComposite:
+		return &CompositeConfig{}, nil
+	default:
+		if newConfig, ok := registeredModeConfig(mode); ok {
+			return newConfig(), nil
+		}
+		return nil, fmt.Errorf("unknown mode: %s", mode)
+	}
+}
+
+// Validate returns error if ExecutionMode is neither built-in nor registered
+// by RegisterModeConfig.
 func (em ExecutionMode) Validate() error {
 	switch em {
-	case ModeWeightedRandom, ModeTimeSeries:
+	case ModeWeightedRandom, ModeTimeSeries, ModeAdaptive, ModeBurst, ModeTrace, ModeComposite:
 		return nil
 	default:
+		if _, ok := registeredModeConfig(em); ok {
+			return nil
+		}
 		return fmt.Errorf("unsupported execution mode: %s", em)
 	}
 }
@@ -58,10 +104,29 @@ type LoadProfile struct {
 	Version int `json:"version" yaml:"version"`
 	// Description is a string value to describe this object.
 	Description string `json:"description,omitempty" yaml:"description"`
+	// Annotations carry arbitrary data about the benchmark intent, like
+	// the target cluster's version. They're copied into report and not
+	// used to filter results. See Annotation* for standard keys.
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// Variables are substituted for ${VAR} in all the string fields of
+	// Spec. See ExpandVariables.
+	Variables map[string]string `json:"variables,omitempty" yaml:"variables,omitempty"`
 	// Spec defines behavior of load profile.
 	Spec LoadProfileSpec `json:"spec" yaml:"spec"`
 }
 
+// Standard keys of LoadProfile.Annotations.
+const (
+	// AnnotationClusterVersion is the Kubernetes version of target cluster.
+	AnnotationClusterVersion = "kperf.io/cluster-version"
+	// AnnotationNodeCount is the number of nodes in target cluster.
+	AnnotationNodeCount = "kperf.io/node-count"
+	// AnnotationOperator is who runs the benchmark.
+	AnnotationOperator = "kperf.io/operator"
+	// AnnotationGitSHA is the git commit of code under test.
+	AnnotationGitSHA = "kperf.io/git-sha"
+)
+
 // LoadProfileSpec defines the load traffic for target resource.
 type LoadProfileSpec struct {
 	// Conns defines total number of long connections used for traffic.
@@ -76,6 +141,32 @@ type LoadProfileSpec struct {
 	// retrying upon receiving "Retry-After" headers and 429 status-code
 	// in the response (<= 0 means no retry).
 	MaxRetries int `json:"maxRetries" yaml:"maxRetries"`
+	// HistogramBuckets defines the upper bounds in seconds of latency
+	// histogram in report. If empty, DefaultHistogramBuckets is used.
+	HistogramBuckets []float64 `json:"histogramBuckets,omitempty" yaml:"histogramBuckets,omitempty"`
+	// ConnectTimeoutSeconds defines the timeout in seconds to establish a new
+	// connection (zero means default).
+	ConnectTimeoutSeconds Seconds `json:"connectTimeoutSeconds,omitempty" yaml:"connectTimeoutSeconds,omitempty"`
+	// ReadTimeoutSeconds defines the timeout in seconds to wait for response's
+	// headers after the request is sent (zero means no limit).
+	ReadTimeoutSeconds Seconds `json:"readTimeoutSeconds,omitempty" yaml:"readTimeoutSeconds,omitempty"`
+	// Transport tunes the HTTP transport's connection behavior.
+	Transport *TransportSpec `json:"transport,omitempty" yaml:"transport,omitempty"`
+	// DNSCacheTTLSeconds defines how long the resolved addresses of
+	// apiserver's host are cached (zero means no cache).
+	DNSCacheTTLSeconds Seconds `json:"dnsCacheTTLSeconds,omitempty" yaml:"dnsCacheTTLSeconds,omitempty"`
+	// DNSServers defines the nameservers, in ip[:port] format, to resolve
+	// apiserver's host instead of system's. The port is 53 by default.
+	DNSServers []string `json:"dnsServers,omitempty" yaml:"dnsServers,omitempty"`
+	// ObjectMeta defines the labels and annotations merged into all the
+	// objects created by requests. The request's ObjectMeta takes
+	// precedence.
+	ObjectMeta *ObjectMeta `json:"objectMeta,omitempty" yaml:"objectMeta,omitempty"`
+	// DispatchBufferSize defines the number of requests buffered between
+	// executor and workers, which smooths dispatch if workers are busy
+	// briefly at the cost of memory. Zero means unbuffered. It's respected
+	// by weighted-random and time-series modes.
+	DispatchBufferSize int `json:"dispatchBufferSize,omitempty" yaml:"dispatchBufferSize,omitempty"`
 
 	// Mode defines the execution strategy (weighted-random, time-series, etc.).
 	Mode ExecutionMode `json:"mode" yaml:"mode"`
@@ -84,6 +175,23 @@ type LoadProfileSpec struct {
 	ModeConfig ModeConfig `json:"modeConfig" yaml:"modeConfig"`
 }
 
+// TransportSpec tunes the HTTP transport's connection behavior. Zero value
+// means the default of client-go.
+type TransportSpec struct {
+	// IdleConnTimeoutSeconds defines how long an idle connection is kept
+	// before closing itself.
+	IdleConnTimeoutSeconds Seconds `json:"idleConnTimeoutSeconds,omitempty" yaml:"idleConnTimeoutSeconds,omitempty"`
+	// DisableKeepAlives closes the connection after each request if it's
+	// true.
+	DisableKeepAlives bool `json:"disableKeepAlives,omitempty" yaml:"disableKeepAlives,omitempty"`
+	// MaxIdleConnsPerHost defines the maximum idle connections to keep per
+	// host.
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost,omitempty" yaml:"maxIdleConnsPerHost,omitempty"`
+	// TLSHandshakeTimeoutSeconds defines the timeout in seconds to wait for
+	// TLS handshake.
+	TLSHandshakeTimeoutSeconds Seconds `json:"tlsHandshakeTimeoutSeconds,omitempty" yaml:"tlsHandshakeTimeoutSeconds,omitempty"`
+}
+
 // KubeGroupVersionResource identifies the resource URI.
 type KubeGroupVersionResource struct {
 	// Group is the name about a collection of related functionality.
@@ -99,15 +207,33 @@ type KubeGroupVersionResource struct {
 type WeightedRequest struct {
 	// Shares defines weight in the same group.
 	Shares int `json:"shares" yaml:"shares"`
+	// Get means this is get request with the given consistency.
+	Get *RequestGet `json:"get,omitempty" yaml:"get,omitempty"`
+	// List means this is list request with the given consistency.
+	List *RequestList `json:"list,omitempty" yaml:"list,omitempty"`
 	// StaleList means this list request with zero resource version.
+	//
+	// Deprecated: Use List with stale consistency. It's converted by
+	// unmarshalers.
 	StaleList *RequestList `json:"staleList,omitempty" yaml:"staleList,omitempty"`
 	// QuorumList means this list request without kube-apiserver cache.
+	//
+	// Deprecated: Use List with quorum consistency. It's converted by
+	// unmarshalers.
 	QuorumList *RequestList `json:"quorumList,omitempty" yaml:"quorumList,omitempty"`
 	// WatchList lists objects with the watch list feature, a.k.a streaming list.
 	WatchList *RequestWatchList `json:"watchList,omitempty" yaml:"watchList,omitempty"`
+	// WatchChurn establishes a watch, holds it for a while and then closes it.
+	WatchChurn *RequestWatchChurn `json:"watchChurn,omitempty" yaml:"watchChurn,omitempty"`
 	// StaleGet means this get request with zero resource version.
+	//
+	// Deprecated: Use Get with stale consistency. It's converted by
+	// unmarshalers.
 	StaleGet *RequestGet `json:"staleGet,omitempty" yaml:"staleGet,omitempty"`
 	// QuorumGet means this get request without kube-apiserver cache.
+	//
+	// Deprecated: Use Get with quorum consistency. It's converted by
+	// unmarshalers.
 	QuorumGet *RequestGet `json:"quorumGet,omitempty" yaml:"quorumGet,omitempty"`
 	// Put means this is mutating request.
 	Put *RequestPut `json:"put,omitempty" yaml:"put,omitempty"`
@@ -117,6 +243,40 @@ type WeightedRequest struct {
 	GetPodLog *RequestGetPodLog `json:"getPodLog,omitempty" yaml:"getPodLog,omitempty"`
 	// PostDelete means this is a post-delete operation request.
 	PostDel *RequestPostDel `json:"postDel,omitempty" yaml:"postDel,omitempty"`
+	// Condition defines when this request can be picked. It's optional.
+	Condition *RequestCondition `json:"condition,omitempty" yaml:"condition,omitempty"`
+	// MaxConcurrency is the maximum number of in-flight requests of this
+	// type (zero is no limit). Another type is picked when the cap is hit,
+	// so that expensive requests can't monopolize all the workers.
+	MaxConcurrency int `json:"maxConcurrency,omitempty" yaml:"maxConcurrency,omitempty"`
+	// MaxRetries overrides spec's MaxRetries for this request if it's set,
+	// including zero which disables retry.
+	MaxRetries *int `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+}
+
+// RequestCondition defines runtime conditions for picking request. The
+// request is skipped and picked again if any condition is not met.
+type RequestCondition struct {
+	// IfCacheNonEmpty requires that postDel request's cache of created
+	// objects is not empty.
+	IfCacheNonEmpty bool `json:"ifCacheNonEmpty,omitempty" yaml:"ifCacheNonEmpty,omitempty"`
+	// IfCacheSize is the minimum size of postDel request's cache of created
+	// objects.
+	IfCacheSize *int `json:"ifCacheSize,omitempty" yaml:"ifCacheSize,omitempty"`
+	// MaxConcurrent is the maximum number of in-flight requests (zero is no
+	// limit).
+	MaxConcurrent int `json:"maxConcurrent,omitempty" yaml:"maxConcurrent,omitempty"`
+}
+
+// Validate verifies fields of RequestCondition.
+func (c *RequestCondition) Validate() error {
+	if c.IfCacheSize != nil && *c.IfCacheSize < 0 {
+		return fmt.Errorf("ifCacheSize requires >= 0: %v", *c.IfCacheSize)
+	}
+	if c.MaxConcurrent < 0 {
+		return fmt.Errorf("maxConcurrent requires >= 0: %v", c.MaxConcurrent)
+	}
+	return nil
 }
 
 // RequestGet defines GET request for target object.
@@ -127,6 +287,9 @@ type RequestGet struct {
 	Namespace string `json:"namespace" yaml:"namespace"`
 	// Name is object's name.
 	Name string `json:"name" yaml:"name"`
+	// Consistency defines how apiserver serves the request, stale or
+	// quorum. Empty means quorum.
+	Consistency Consistency `json:"consistency,omitempty" yaml:"consistency,omitempty"`
 }
 
 // RequestList defines LIST request for target objects.
@@ -137,10 +300,23 @@ type RequestList struct {
 	Namespace string `json:"namespace" yaml:"namespace"`
 	// Limit defines the page size.
 	Limit int `json:"limit" yaml:"limit"`
+	// Paginate defines whether to follow continue token to fetch the
+	// following pages. Without it, only the first page is fetched.
+	Paginate bool `json:"paginate,omitempty" yaml:"paginate,omitempty"`
+	// LimitByPageCount defines the maximum number of pages to fetch when
+	// Paginate is true. It stops even if continue token isn't exhausted.
+	// Zero means fetching until continue token is exhausted.
+	LimitByPageCount int `json:"limitByPageCount,omitempty" yaml:"limitByPageCount,omitempty"`
 	// Selector defines how to identify a set of objects.
 	Selector string `json:"selector" yaml:"selector"`
 	// FieldSelector defines how to identify a set of objects with field selector.
 	FieldSelector string `json:"fieldSelector" yaml:"fieldSelector"`
+	// Consistency defines how apiserver serves the request, stale, quorum
+	// or exact. Empty means quorum.
+	Consistency Consistency `json:"consistency,omitempty" yaml:"consistency,omitempty"`
+	// ResourceVersion is the resource version to read at. It's required
+	// by exact consistency and not allowed by the others.
+	ResourceVersion string `json:"resourceVersion,omitempty" yaml:"resourceVersion,omitempty"`
 }
 
 type RequestWatchList struct {
@@ -154,6 +330,23 @@ type RequestWatchList struct {
 	FieldSelector string `json:"fieldSelector" yaml:"fieldSelector"`
 }
 
+// RequestWatchChurn defines WATCH request which is closed after HoldTime.
+//
+// Combined with shares and rate, it produces a configurable watch
+// establishment rate to stress the watch registration path.
+type RequestWatchChurn struct {
+	// KubeGroupVersionResource identifies the resource URI.
+	KubeGroupVersionResource `yaml:",inline"`
+	// Namespace is object's namespace.
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// Selector defines how to identify a set of objects.
+	Selector string `json:"selector" yaml:"selector"`
+	// FieldSelector defines how to identify a set of objects with field selector.
+	FieldSelector string `json:"fieldSelector" yaml:"fieldSelector"`
+	// HoldTime defines how long the watch is kept open (e.g., "30s").
+	HoldTime string `json:"holdTime" yaml:"holdTime"`
+}
+
 // RequestPut defines PUT request for target resource type.
 type RequestPut struct {
 	// KubeGroupVersionResource identifies the resource URI.
@@ -170,8 +363,19 @@ type RequestPut struct {
 	Name string `json:"name" yaml:"name"`
 	// KeySpaceSize is used to generate random number as name's suffix.
 	KeySpaceSize int `json:"keySpaceSize" yaml:"keySpaceSize"`
+	// KeySpaceShard limits the suffix to one shard of KeySpaceSize.
+	KeySpaceShard `yaml:",inline"`
 	// ValueSize is the object's size in bytes.
 	ValueSize int `json:"valueSize" yaml:"valueSize"`
+	// UseExistingTemplate updates the existing object instead of creating
+	// one. Each request GETs the object and PUTs it back with ValueSize
+	// bytes of new data. The GET and PUT are measured as one request.
+	UseExistingTemplate bool `json:"useExistingTemplate,omitempty" yaml:"useExistingTemplate,omitempty"`
+	// OptimisticLock sends the resourceVersion fetched by GET with PUT so
+	// that apiserver rejects the update with conflict if the object has
+	// been changed in between. Otherwise, PUT is unconditional. It
+	// requires UseExistingTemplate.
+	OptimisticLock bool `json:"optimisticLock,omitempty" yaml:"optimisticLock,omitempty"`
 }
 
 // RequestPatch defines PATCH request for target resource type.
@@ -183,10 +387,47 @@ type RequestPatch struct {
 	Name string `json:"name" yaml:"name"`
 	// KeySpaceSize is used to generate random number as name's suffix.
 	KeySpaceSize int `json:"keySpaceSize" yaml:"keySpaceSize"`
+	// KeySpaceShard limits the suffix to one shard of KeySpaceSize.
+	KeySpaceShard `yaml:",inline"`
 	// PatchType is the type of patch, e.g. "json", "merge", "strategic-merge".
 	PatchType string `json:"patchType" yaml:"patchType"`
 	// Body is the request body, for fields to be changed.
 	Body string `json:"body" yaml:"body"`
+	// BodyTemplate is a text/template to generate the request body for
+	// each request, e.g. {"metadata":{"labels":{"version":"{{.Index}}"}}}.
+	// The template data is PatchBodyTemplateData. It's exclusive with Body.
+	BodyTemplate string `json:"bodyTemplate,omitempty" yaml:"bodyTemplate,omitempty"`
+}
+
+// PatchBodyTemplateData is the data to render RequestPatch.BodyTemplate.
+type PatchBodyTemplateData struct {
+	// Name is the name of patched object.
+	Name string
+	// Index is the suffix index of patched object's name.
+	Index int64
+	// Namespace is object's namespace.
+	Namespace string
+}
+
+// ParseBodyTemplate parses BodyTemplate. It returns nil if BodyTemplate is empty.
+func (r *RequestPatch) ParseBodyTemplate() (*template.Template, error) {
+	if r.BodyTemplate == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("bodyTemplate").Option("missingkey=error").Parse(r.BodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bodyTemplate %q: %w", r.BodyTemplate, err)
+	}
+	return tmpl, nil
+}
+
+// RenderPatchBody renders patch's body with parsed BodyTemplate.
+func RenderPatchBody(tmpl *template.Template, data PatchBodyTemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render bodyTemplate: %w", err)
+	}
+	return buf.String(), nil
 }
 
 // RequestGetPodLog defines GetLog request for target pod.
@@ -204,11 +445,59 @@ type RequestGetPodLog struct {
 	// LimitBytes is the number of bytes to read from the server before
 	// terminating the log output, if set.
 	LimitBytes *int64 `json:"limitBytes" yaml:"limitBytes"`
+	// ParseTimestamps requests logs with timestamps and records the time
+	// span between the first and last log lines.
+	ParseTimestamps bool `json:"parseTimestamps,omitempty" yaml:"parseTimestamps,omitempty"`
+	// CountLines records the number of received log lines.
+	CountLines bool `json:"countLines,omitempty" yaml:"countLines,omitempty"`
 }
 type RequestPostDel struct {
 	KubeGroupVersionResource `yaml:",inline"`
 	Namespace                string  `json:"namespace" yaml:"namespace"`
 	DeleteRatio              float64 `json:"deleteRatio" yaml:"deleteRatio"`
+	// NameTemplate is a text/template to generate the name of created
+	// object, e.g. kperf-{{.Resource}}-{{.Index}}. The template data is
+	// PostDelNameTemplateData. If empty, name is {timestamp}-{counter}.
+	NameTemplate string `json:"nameTemplate,omitempty" yaml:"nameTemplate,omitempty"`
+	// KeySpaceShard limits the index of created objects to one shard so
+	// that runners don't create the same names.
+	KeySpaceShard `yaml:",inline"`
+	// ObjectMeta defines the labels and annotations merged into created
+	// objects. It overrides spec's ObjectMeta.
+	ObjectMeta *ObjectMeta `json:"objectMeta,omitempty" yaml:"objectMeta,omitempty"`
+}
+
+// PostDelNameTemplateData is the data to render RequestPostDel.NameTemplate.
+type PostDelNameTemplateData struct {
+	// Index is the sequence number of created object, starting from 1.
+	Index int64
+	// Namespace is object's namespace.
+	Namespace string
+	// Resource is object's resource type.
+	Resource string
+	// Timestamp is the creation time in unix nanoseconds.
+	Timestamp int64
+}
+
+// ParseNameTemplate parses NameTemplate. It returns nil if NameTemplate is empty.
+func (r *RequestPostDel) ParseNameTemplate() (*template.Template, error) {
+	if r.NameTemplate == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("nameTemplate").Option("missingkey=error").Parse(r.NameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nameTemplate %q: %w", r.NameTemplate, err)
+	}
+	return tmpl, nil
+}
+
+// RenderPostDelName renders object's name with parsed NameTemplate.
+func RenderPostDelName(tmpl *template.Template, data PostDelNameTemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render nameTemplate: %w", err)
+	}
+	return buf.String(), nil
 }
 
 // WeightedRandomConfig defines configuration for weighted-random execution mode.
@@ -220,25 +509,49 @@ func (lp LoadProfile) Validate() error {
 	return lp.Spec.Validate()
 }
 
+// Checksum returns hex-encoded SHA-256 of the canonical JSON serialization
+// of LoadProfile, so that it doesn't depend on formatting, comments or the
+// order of map keys, which encoding/json sorts. Annotations are excluded
+// because they're notes about the benchmark rather than the traffic.
+func (lp LoadProfile) Checksum() (string, error) {
+	lp.Annotations = nil
+
+	data, err := json.Marshal(lp)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal load profile: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // UnmarshalYAML implements custom YAML unmarshaling for LoadProfileSpec.
 // It automatically deserializes ModeConfig to the correct concrete type based on Mode.
 // It also provides backward compatibility for legacy format (without mode field).
 func (spec *LoadProfileSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	// Create a temporary struct that has all fields explicitly (no embedding)
 	type tempSpec struct {
-		Conns        int                        `yaml:"conns"`
-		Client       int                        `yaml:"client"`
-		ContentType  ContentType                `yaml:"contentType"`
-		DisableHTTP2 bool                       `yaml:"disableHTTP2"`
-		MaxRetries   int                        `yaml:"maxRetries"`
-		Mode         ExecutionMode              `yaml:"mode"`
-		ModeConfig   map[string]interface{}     `yaml:"modeConfig"`
+		Conns                 int                    `yaml:"conns"`
+		Client                int                    `yaml:"client"`
+		ContentType           ContentType            `yaml:"contentType"`
+		DisableHTTP2          bool                   `yaml:"disableHTTP2"`
+		MaxRetries            int                    `yaml:"maxRetries"`
+		HistogramBuckets      []float64              `yaml:"histogramBuckets"`
+		ConnectTimeoutSeconds Seconds                `yaml:"connectTimeoutSeconds"`
+		ReadTimeoutSeconds    Seconds                `yaml:"readTimeoutSeconds"`
+		Transport             *TransportSpec         `yaml:"transport"`
+		DNSCacheTTLSeconds    Seconds                `yaml:"dnsCacheTTLSeconds"`
+		DNSServers            []string               `yaml:"dnsServers"`
+		ObjectMeta            *ObjectMeta            `yaml:"objectMeta"`
+		DispatchBufferSize    int                    `yaml:"dispatchBufferSize"`
+		Mode                  ExecutionMode          `yaml:"mode"`
+		ModeConfig            map[string]interface{} `yaml:"modeConfig"`
 
 		// Legacy fields (for backward compatibility)
-		Rate         float64                    `yaml:"rate"`
-		Total        int                        `yaml:"total"`
-		Duration     int                        `yaml:"duration"`
-		Requests     []*WeightedRequest         `yaml:"requests"`
+		Rate     float64            `yaml:"rate"`
+		Burst    int                `yaml:"burst"`
+		Total    int                `yaml:"total"`
+		Duration Seconds            `yaml:"duration"`
+		Requests []*WeightedRequest `yaml:"requests"`
 	}
 
 	temp := &tempSpec{}
@@ -252,6 +565,14 @@ func (spec *LoadProfileSpec) UnmarshalYAML(unmarshal func(interface{}) error) er
 	spec.ContentType = temp.ContentType
 	spec.DisableHTTP2 = temp.DisableHTTP2
 	spec.MaxRetries = temp.MaxRetries
+	spec.HistogramBuckets = temp.HistogramBuckets
+	spec.ConnectTimeoutSeconds = temp.ConnectTimeoutSeconds
+	spec.ReadTimeoutSeconds = temp.ReadTimeoutSeconds
+	spec.Transport = temp.Transport
+	spec.DNSCacheTTLSeconds = temp.DNSCacheTTLSeconds
+	spec.DNSServers = temp.DNSServers
+	spec.ObjectMeta = temp.ObjectMeta
+	spec.DispatchBufferSize = temp.DispatchBufferSize
 
 	// Check if this is legacy format (no mode specified but has requests)
 	if temp.Mode == "" && len(temp.Requests) > 0 {
@@ -259,6 +580,7 @@ func (spec *LoadProfileSpec) UnmarshalYAML(unmarshal func(interface{}) error) er
 		spec.Mode = ModeWeightedRandom
 		spec.ModeConfig = &WeightedRandomConfig{
 			Rate:     temp.Rate,
+			Burst:    temp.Burst,
 			Total:    temp.Total,
 			Duration: temp.Duration,
 			Requests: temp.Requests,
@@ -269,16 +591,19 @@ func (spec *LoadProfileSpec) UnmarshalYAML(unmarshal func(interface{}) error) er
 	// New format: mode is specified
 	spec.Mode = temp.Mode
 
+	if temp.Mode != "" && temp.ModeConfig == nil {
+		return fmt.Errorf("mode %s requires modeConfig", temp.Mode)
+	}
+
 	// Now unmarshal ModeConfig based on Mode
 	if temp.ModeConfig != nil {
-		var config ModeConfig
-		switch temp.Mode {
-		case ModeWeightedRandom:
-			config = &WeightedRandomConfig{}
-		case ModeTimeSeries:
-			config = &TimeSeriesConfig{}
-		default:
-			return fmt.Errorf("unknown mode: %s", temp.Mode)
+		if err := checkModeConfigKeys(temp.Mode, temp.ModeConfig, "yaml"); err != nil {
+			return err
+		}
+
+		config, err := newModeConfig(temp.Mode)
+		if err != nil {
+			return err
 		}
 
 		// Convert map to YAML bytes and unmarshal into typed struct
@@ -301,19 +626,28 @@ func (spec *LoadProfileSpec) UnmarshalYAML(unmarshal func(interface{}) error) er
 func (spec *LoadProfileSpec) UnmarshalJSON(data []byte) error {
 	// Create a temporary struct that has all fields explicitly (no embedding)
 	type tempSpec struct {
-		Conns        int                        `json:"conns"`
-		Client       int                        `json:"client"`
-		ContentType  ContentType                `json:"contentType"`
-		DisableHTTP2 bool                       `json:"disableHTTP2"`
-		MaxRetries   int                        `json:"maxRetries"`
-		Mode         ExecutionMode              `json:"mode"`
-		ModeConfig   map[string]interface{}     `json:"modeConfig"`
+		Conns                 int                    `json:"conns"`
+		Client                int                    `json:"client"`
+		ContentType           ContentType            `json:"contentType"`
+		DisableHTTP2          bool                   `json:"disableHTTP2"`
+		MaxRetries            int                    `json:"maxRetries"`
+		HistogramBuckets      []float64              `json:"histogramBuckets"`
+		ConnectTimeoutSeconds Seconds                `json:"connectTimeoutSeconds"`
+		ReadTimeoutSeconds    Seconds                `json:"readTimeoutSeconds"`
+		Transport             *TransportSpec         `json:"transport"`
+		DNSCacheTTLSeconds    Seconds                `json:"dnsCacheTTLSeconds"`
+		DNSServers            []string               `json:"dnsServers"`
+		ObjectMeta            *ObjectMeta            `json:"objectMeta"`
+		DispatchBufferSize    int                    `json:"dispatchBufferSize"`
+		Mode                  ExecutionMode          `json:"mode"`
+		ModeConfig            map[string]interface{} `json:"modeConfig"`
 
 		// Legacy fields (for backward compatibility)
-		Rate         float64                    `json:"rate"`
-		Total        int                        `json:"total"`
-		Duration     int                        `json:"duration"`
-		Requests     []*WeightedRequest         `json:"requests"`
+		Rate     float64            `json:"rate"`
+		Burst    int                `json:"burst"`
+		Total    int                `json:"total"`
+		Duration Seconds            `json:"duration"`
+		Requests []*WeightedRequest `json:"requests"`
 	}
 
 	temp := &tempSpec{}
@@ -327,6 +661,14 @@ func (spec *LoadProfileSpec) UnmarshalJSON(data []byte) error {
 	spec.ContentType = temp.ContentType
 	spec.DisableHTTP2 = temp.DisableHTTP2
 	spec.MaxRetries = temp.MaxRetries
+	spec.HistogramBuckets = temp.HistogramBuckets
+	spec.ConnectTimeoutSeconds = temp.ConnectTimeoutSeconds
+	spec.ReadTimeoutSeconds = temp.ReadTimeoutSeconds
+	spec.Transport = temp.Transport
+	spec.DNSCacheTTLSeconds = temp.DNSCacheTTLSeconds
+	spec.DNSServers = temp.DNSServers
+	spec.ObjectMeta = temp.ObjectMeta
+	spec.DispatchBufferSize = temp.DispatchBufferSize
 
 	// Check if this is legacy format (no mode specified but has requests)
 	if temp.Mode == "" && len(temp.Requests) > 0 {
@@ -334,6 +676,7 @@ func (spec *LoadProfileSpec) UnmarshalJSON(data []byte) error {
 		spec.Mode = ModeWeightedRandom
 		spec.ModeConfig = &WeightedRandomConfig{
 			Rate:     temp.Rate,
+			Burst:    temp.Burst,
 			Total:    temp.Total,
 			Duration: temp.Duration,
 			Requests: temp.Requests,
@@ -344,16 +687,19 @@ func (spec *LoadProfileSpec) UnmarshalJSON(data []byte) error {
 	// New format: mode is specified
 	spec.Mode = temp.Mode
 
+	if temp.Mode != "" && temp.ModeConfig == nil {
+		return fmt.Errorf("mode %s requires modeConfig", temp.Mode)
+	}
+
 	// Now unmarshal ModeConfig based on Mode
 	if temp.ModeConfig != nil {
-		var config ModeConfig
-		switch temp.Mode {
-		case ModeWeightedRandom:
-			config = &WeightedRandomConfig{}
-		case ModeTimeSeries:
-			config = &TimeSeriesConfig{}
-		default:
-			return fmt.Errorf("unknown mode: %s", temp.Mode)
+		if err := checkModeConfigKeys(temp.Mode, temp.ModeConfig, "json"); err != nil {
+			return err
+		}
+
+		config, err := newModeConfig(temp.Mode)
+		if err != nil {
+			return err
 		}
 
 		// Convert map to JSON bytes and unmarshal into typed struct
@@ -370,7 +716,6 @@ func (spec *LoadProfileSpec) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-
 // Validate verifies fields of LoadProfileSpec.
 func (spec *LoadProfileSpec) Validate() error {
 
@@ -395,6 +740,73 @@ func (spec *LoadProfileSpec) Validate() error {
 		return fmt.Errorf("modeConfig is required")
 	}
 
+	for i, b := range spec.HistogramBuckets {
+		if b <= 0 {
+			return fmt.Errorf("histogramBuckets requires > 0: %v", b)
+		}
+		if i > 0 && b <= spec.HistogramBuckets[i-1] {
+			return fmt.Errorf("histogramBuckets must be in increasing order: %v", spec.HistogramBuckets)
+		}
+	}
+
+	if spec.ConnectTimeoutSeconds < 0 {
+		return fmt.Errorf("connectTimeoutSeconds requires >= 0: %v", spec.ConnectTimeoutSeconds)
+	}
+
+	if spec.ReadTimeoutSeconds < 0 {
+		return fmt.Errorf("readTimeoutSeconds requires >= 0: %v", spec.ReadTimeoutSeconds)
+	}
+
+	if spec.DNSCacheTTLSeconds < 0 {
+		return fmt.Errorf("dnsCacheTTLSeconds requires >= 0: %v", spec.DNSCacheTTLSeconds)
+	}
+
+	for _, s := range spec.DNSServers {
+		host := s
+		if h, _, err := net.SplitHostPort(s); err == nil {
+			host = h
+		}
+		if net.ParseIP(host) == nil {
+			return fmt.Errorf("dnsServers requires ip[:port]: %q", s)
+		}
+	}
+
+	if spec.DispatchBufferSize < 0 {
+		return fmt.Errorf("dispatchBufferSize requires >= 0: %v", spec.DispatchBufferSize)
+	}
+
+	if spec.Transport != nil {
+		if err := spec.Transport.Validate(); err != nil {
+			return fmt.Errorf("transport: %w", err)
+		}
+	}
+
+	if spec.ObjectMeta != nil {
+		if err := spec.ObjectMeta.Validate(); err != nil {
+			return fmt.Errorf("objectMeta: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate verifies fields of TransportSpec.
+func (t *TransportSpec) Validate() error {
+	if t.IdleConnTimeoutSeconds < 0 {
+		return fmt.Errorf("idleConnTimeoutSeconds requires >= 0: %v", t.IdleConnTimeoutSeconds)
+	}
+
+	if t.MaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("maxIdleConnsPerHost requires >= 0: %v", t.MaxIdleConnsPerHost)
+	}
+
+	if t.TLSHandshakeTimeoutSeconds < 0 {
+		return fmt.Errorf("tlsHandshakeTimeoutSeconds requires >= 0: %v", t.TLSHandshakeTimeoutSeconds)
+	}
+
+	// There is no idle connection if keep-alives is disabled.
+	if t.DisableKeepAlives && (t.IdleConnTimeoutSeconds > 0 || t.MaxIdleConnsPerHost > 0) {
+		return fmt.Errorf("idleConnTimeoutSeconds and maxIdleConnsPerHost don't work with disableKeepAlives")
+	}
 	return nil
 }
 
@@ -404,17 +816,37 @@ func (r WeightedRequest) Validate() error {
 		return fmt.Errorf("shares(%v) requires >= 0", r.Shares)
 	}
 
+	if r.MaxConcurrency < 0 {
+		return fmt.Errorf("maxConcurrency(%v) requires >= 0", r.MaxConcurrency)
+	}
+
+	if r.MaxRetries != nil && *r.MaxRetries < 0 {
+		return fmt.Errorf("maxRetries(%v) requires >= 0", *r.MaxRetries)
+	}
+
+	if r.Condition != nil {
+		if err := r.Condition.Validate(); err != nil {
+			return fmt.Errorf("condition: %v", err)
+		}
+		if (r.Condition.IfCacheNonEmpty || r.Condition.IfCacheSize != nil) && r.PostDel == nil {
+			return fmt.Errorf("condition: cache condition requires postDel request")
+		}
+	}
+
+	req, err := r.WithoutDeprecated()
+	if err != nil {
+		return err
+	}
+
 	switch {
-	case r.StaleList != nil:
-		return r.StaleList.Validate(true)
-	case r.QuorumList != nil:
-		return r.QuorumList.Validate(false)
+	case req.Get != nil:
+		return req.Get.Validate()
+	case req.List != nil:
+		return req.List.Validate()
 	case r.WatchList != nil:
 		return r.WatchList.Validate()
-	case r.StaleGet != nil:
-		return r.StaleGet.Validate()
-	case r.QuorumGet != nil:
-		return r.QuorumGet.Validate()
+	case r.WatchChurn != nil:
+		return r.WatchChurn.Validate()
 	case r.Put != nil:
 		return r.Put.Validate()
 	case r.Patch != nil:
@@ -428,19 +860,117 @@ func (r WeightedRequest) Validate() error {
 	}
 }
 
+// Equal returns true if both send the same request with the same settings.
+// Shares is ignored, because such requests should be one with the sum of
+// shares. The deprecated staleList, quorumList, staleGet and quorumGet equal
+// their list and get with the corresponding consistency.
+func (r WeightedRequest) Equal(other WeightedRequest) bool {
+	normalize := func(req WeightedRequest) WeightedRequest {
+		if converted, err := req.WithoutDeprecated(); err == nil {
+			req = *converted
+		}
+		req.Shares = 0
+		return req
+	}
+	return reflect.DeepEqual(normalize(r), normalize(other))
+}
+
+// Type returns the name of the specified request type, like staleList.
+func (r WeightedRequest) Type() string {
+	switch {
+	case r.Get != nil:
+		return r.Get.Consistency.typePrefix() + "Get"
+	case r.List != nil:
+		return r.List.Consistency.typePrefix() + "List"
+	case r.StaleList != nil:
+		return "staleList"
+	case r.QuorumList != nil:
+		return "quorumList"
+	case r.WatchList != nil:
+		return "watchList"
+	case r.WatchChurn != nil:
+		return "watchChurn"
+	case r.StaleGet != nil:
+		return "staleGet"
+	case r.QuorumGet != nil:
+		return "quorumGet"
+	case r.Put != nil:
+		return "put"
+	case r.Patch != nil:
+		return "patch"
+	case r.GetPodLog != nil:
+		return "getPodLog"
+	case r.PostDel != nil:
+		return "postDel"
+	default:
+		return ""
+	}
+}
+
+// KeySpaceShard returns the key space shard, in {offset}/{partitions}
+// format, used by the request. It's empty if the request isn't sharded.
+func (r *WeightedRequest) KeySpaceShard() string {
+	switch {
+	case r.Put != nil:
+		return r.Put.Shard()
+	case r.Patch != nil:
+		return r.Patch.Shard()
+	case r.PostDel != nil:
+		return r.PostDel.Shard()
+	default:
+		return ""
+	}
+}
+
+// WithDefaultObjectMeta returns the request whose created objects carry
+// the given labels and annotations unless the request overrides them. The
+// request is returned as it is if it doesn't create objects.
+func (r *WeightedRequest) WithDefaultObjectMeta(meta *ObjectMeta) *WeightedRequest {
+	if meta == nil || r.PostDel == nil {
+		return r
+	}
+
+	postDel := *r.PostDel
+	postDel.ObjectMeta = meta.Merge(r.PostDel.ObjectMeta)
+
+	copied := *r
+	copied.PostDel = &postDel
+	return &copied
+}
+
 // RequestList validates RequestList type.
-func (r *RequestList) Validate(stale bool) error {
+func (r *RequestList) Validate() error {
 	if err := r.KubeGroupVersionResource.Validate(); err != nil {
 		return fmt.Errorf("kube metadata: %v", err)
 	}
 
+	if err := r.Consistency.Validate(); err != nil {
+		return err
+	}
+
+	if (r.Consistency == ConsistencyExact) != (r.ResourceVersion != "") {
+		return fmt.Errorf("resourceVersion is required by exact consistency only")
+	}
+
 	if r.Limit < 0 {
 		return fmt.Errorf("limit must >= 0")
 	}
 
-	if stale && r.Limit != 0 {
+	if r.Consistency == ConsistencyStale && r.Limit != 0 {
 		return fmt.Errorf("stale list doesn't support pagination option: https://github.com/kubernetes/kubernetes/issues/108003")
 	}
+
+	if r.Paginate && r.Limit == 0 {
+		return fmt.Errorf("paginate requires limit > 0")
+	}
+
+	if r.LimitByPageCount < 0 {
+		return fmt.Errorf("limitByPageCount must >= 0")
+	}
+
+	if r.LimitByPageCount > 0 && (!r.Paginate || r.Limit == 0) {
+		return fmt.Errorf("limitByPageCount requires paginate to be true and limit > 0")
+	}
 	return nil
 }
 
@@ -451,6 +981,22 @@ func (r *RequestWatchList) Validate() error {
 	return nil
 }
 
+// Validate validates RequestWatchChurn type.
+func (r *RequestWatchChurn) Validate() error {
+	if err := r.KubeGroupVersionResource.Validate(); err != nil {
+		return fmt.Errorf("kube metadata: %v", err)
+	}
+
+	holdTime, err := time.ParseDuration(r.HoldTime)
+	if err != nil {
+		return fmt.Errorf("invalid holdTime %q: %v", r.HoldTime, err)
+	}
+	if holdTime <= 0 {
+		return fmt.Errorf("holdTime must > 0")
+	}
+	return nil
+}
+
 // Validate validates RequestGet type.
 func (r *RequestGet) Validate() error {
 	if err := r.KubeGroupVersionResource.Validate(); err != nil {
@@ -460,6 +1006,15 @@ func (r *RequestGet) Validate() error {
 	if r.Name == "" {
 		return fmt.Errorf("name is required")
 	}
+
+	if err := r.Consistency.Validate(); err != nil {
+		return err
+	}
+
+	// NOTE: apiserver serves get with resourceVersion as not older than.
+	if r.Consistency == ConsistencyExact {
+		return fmt.Errorf("exact consistency is only supported by list")
+	}
 	return nil
 }
 
@@ -476,9 +1031,18 @@ func (r *RequestPut) Validate() error {
 	if r.KeySpaceSize <= 0 {
 		return fmt.Errorf("keySpaceSize must > 0")
 	}
+	if err := r.KeySpaceShard.Validate(r.KeySpaceSize); err != nil {
+		return err
+	}
 	if r.ValueSize <= 0 {
 		return fmt.Errorf("valueSize must > 0")
 	}
+	if r.OptimisticLock && !r.UseExistingTemplate {
+		return fmt.Errorf("optimisticLock requires useExistingTemplate")
+	}
+	if r.UseExistingTemplate && (r.Group != "" || (r.Resource != "configmaps" && r.Resource != "secrets")) {
+		return fmt.Errorf("useExistingTemplate only supports configmaps and secrets, but got %s", r.Resource)
+	}
 	return nil
 }
 
@@ -520,6 +1084,52 @@ func GetPatchType(patchType string) (apitypes.PatchType, bool) {
 	}
 }
 
+// jsonPatchOps are the operations defined by RFC 6902.
+var jsonPatchOps = map[string]bool{
+	"add":     true,
+	"remove":  true,
+	"replace": true,
+	"move":    true,
+	"copy":    true,
+	"test":    true,
+}
+
+// ValidatePatchBody verifies that body's structure matches the patch type.
+// JSON patch must be an array of operations with op and path, while merge
+// and strategic-merge patch must be an object.
+func ValidatePatchBody(patchType string, body string) error {
+	pt, ok := GetPatchType(patchType)
+	if !ok {
+		return fmt.Errorf("unknown patch type: %s (valid types: json, merge, strategic-merge)", patchType)
+	}
+	if !json.Valid([]byte(body)) {
+		return fmt.Errorf("invalid JSON in patch body: %q", body)
+	}
+
+	if pt != apitypes.JSONPatchType {
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(body), &obj); err != nil || obj == nil {
+			return fmt.Errorf("%s patch body must be a JSON object: %q", patchType, body)
+		}
+		return nil
+	}
+
+	var ops []map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &ops); err != nil {
+		return fmt.Errorf("json patch body must be an array of operations: %q", body)
+	}
+	for idx, op := range ops {
+		name, _ := op["op"].(string)
+		if !jsonPatchOps[name] {
+			return fmt.Errorf("json patch operation %d has unknown op %q", idx, name)
+		}
+		if _, ok := op["path"].(string); !ok {
+			return fmt.Errorf("json patch operation %d requires path", idx)
+		}
+	}
+	return nil
+}
+
 // Validate validates RequestPatch type.
 func (r *RequestPatch) Validate() error {
 	if err := r.KubeGroupVersionResource.Validate(); err != nil {
@@ -528,8 +1138,14 @@ func (r *RequestPatch) Validate() error {
 	if r.Name == "" {
 		return fmt.Errorf("name is required")
 	}
-	if r.Body == "" {
-		return fmt.Errorf("body is required")
+	if (r.Body == "") == (r.BodyTemplate == "") {
+		return fmt.Errorf("exactly one of body and bodyTemplate is required")
+	}
+	if r.KeySpaceShard.KeySpacePartitions > 0 && r.KeySpaceSize <= 0 {
+		return fmt.Errorf("keySpaceSize must > 0 if keySpacePartitions is set")
+	}
+	if err := r.KeySpaceShard.Validate(r.KeySpaceSize); err != nil {
+		return err
 	}
 
 	// Validate patch type
@@ -538,10 +1154,31 @@ func (r *RequestPatch) Validate() error {
 		return fmt.Errorf("unknown patch type: %s (valid types: json, merge, strategic-merge)", r.PatchType)
 	}
 
-	// Validate JSON body and trim it
+	if r.BodyTemplate != "" {
+		r.BodyTemplate = strings.TrimSpace(r.BodyTemplate)
+
+		tmpl, err := r.ParseBodyTemplate()
+		if err != nil {
+			return err
+		}
+		body, err := RenderPatchBody(tmpl, PatchBodyTemplateData{
+			Name:      r.Name + "-0",
+			Index:     0,
+			Namespace: r.Namespace,
+		})
+		if err != nil {
+			return err
+		}
+		if err := ValidatePatchBody(r.PatchType, body); err != nil {
+			return fmt.Errorf("bodyTemplate %q renders invalid body: %w", r.BodyTemplate, err)
+		}
+		return nil
+	}
+
+	// Validate body and trim it
 	trimmed := strings.TrimSpace(r.Body)
-	if !json.Valid([]byte(trimmed)) {
-		return fmt.Errorf("invalid JSON in patch body: %q", r.Body)
+	if err := ValidatePatchBody(r.PatchType, trimmed); err != nil {
+		return err
 	}
 
 	r.Body = trimmed // Store the trimmed body
@@ -558,5 +1195,35 @@ func (r *RequestPostDel) Validate() error {
 		return fmt.Errorf("delete ratio must be between 0 and 0.5: %v, create proportion should be greater than delete", r.DeleteRatio)
 	}
 
+	if err := r.KeySpaceShard.Validate(0); err != nil {
+		return err
+	}
+
+	if r.ObjectMeta != nil {
+		if err := r.ObjectMeta.Validate(); err != nil {
+			return fmt.Errorf("objectMeta: %w", err)
+		}
+	}
+
+	tmpl, err := r.ParseNameTemplate()
+	if err != nil {
+		return err
+	}
+	if tmpl != nil {
+		name, err := RenderPostDelName(tmpl, PostDelNameTemplateData{
+			Index:     1,
+			Namespace: r.Namespace,
+			Resource:  r.Resource,
+			Timestamp: time.Now().UnixNano(),
+		})
+		if err != nil {
+			return err
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return fmt.Errorf("nameTemplate %q renders invalid name %q: %s",
+				r.NameTemplate, name, strings.Join(errs, "; "))
+		}
+	}
+
 	return nil
 }
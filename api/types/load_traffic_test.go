@@ -4,9 +4,15 @@
 package types
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 )
 
 func TestWeightedRequest(t *testing.T) {
@@ -20,6 +26,14 @@ func TestWeightedRequest(t *testing.T) {
 			},
 			err: true,
 		},
+		"maxRetries < 0": {
+			req: WeightedRequest{
+				Shares:     100,
+				StaleGet:   &RequestGet{KubeGroupVersionResource: KubeGroupVersionResource{Version: "v1", Resource: "pods"}, Name: "a"},
+				MaxRetries: func() *int { v := -1; return &v }(),
+			},
+			err: true,
+		},
 		"no request setting": {
 			req: WeightedRequest{
 				Shares: 100,
@@ -61,6 +75,245 @@ func TestWeightedRequest(t *testing.T) {
 			},
 			err: true,
 		},
+		"paginate without limit": {
+			req: WeightedRequest{
+				Shares: 100,
+				QuorumList: &RequestList{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "pods",
+						Version:  "v1",
+					},
+					Paginate: true,
+				},
+			},
+			err: true,
+		},
+		"limitByPageCount without paginate": {
+			req: WeightedRequest{
+				Shares: 100,
+				QuorumList: &RequestList{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "pods",
+						Version:  "v1",
+					},
+					Limit:            100,
+					LimitByPageCount: 5,
+				},
+			},
+			err: true,
+		},
+		"limitByPageCount with paginate": {
+			req: WeightedRequest{
+				Shares: 100,
+				QuorumList: &RequestList{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "pods",
+						Version:  "v1",
+					},
+					Limit:            100,
+					Paginate:         true,
+					LimitByPageCount: 5,
+				},
+			},
+			err: false,
+		},
+		"cache condition without postDel": {
+			req: WeightedRequest{
+				Shares: 100,
+				StaleGet: &RequestGet{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "pods",
+						Version:  "v1",
+					},
+					Namespace: "default",
+					Name:      "x",
+				},
+				Condition: &RequestCondition{IfCacheNonEmpty: true},
+			},
+			err: true,
+		},
+		"negative maxConcurrent": {
+			req: WeightedRequest{
+				Shares: 100,
+				StaleGet: &RequestGet{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "pods",
+						Version:  "v1",
+					},
+					Namespace: "default",
+					Name:      "x",
+				},
+				Condition: &RequestCondition{MaxConcurrent: -1},
+			},
+			err: true,
+		},
+		"postDel with cache condition": {
+			req: WeightedRequest{
+				Shares: 100,
+				PostDel: &RequestPostDel{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "configmaps",
+						Version:  "v1",
+					},
+					Namespace:   "default",
+					DeleteRatio: 0.5,
+				},
+				Condition: &RequestCondition{IfCacheNonEmpty: true, MaxConcurrent: 2},
+			},
+			err: false,
+		},
+		"watch churn without holdTime": {
+			req: WeightedRequest{
+				Shares: 100,
+				WatchChurn: &RequestWatchChurn{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "pods",
+						Version:  "v1",
+					},
+				},
+			},
+			err: true,
+		},
+		"watch churn with zero holdTime": {
+			req: WeightedRequest{
+				Shares: 100,
+				WatchChurn: &RequestWatchChurn{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "pods",
+						Version:  "v1",
+					},
+					HoldTime: "0s",
+				},
+			},
+			err: true,
+		},
+		"watch churn": {
+			req: WeightedRequest{
+				Shares: 100,
+				WatchChurn: &RequestWatchChurn{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "pods",
+						Version:  "v1",
+					},
+					HoldTime: "30s",
+				},
+			},
+			err: false,
+		},
+		"postDel with invalid nameTemplate": {
+			req: WeightedRequest{
+				Shares: 100,
+				PostDel: &RequestPostDel{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "pods",
+						Version:  "v1",
+					},
+					NameTemplate: "kperf-{{.Resource",
+				},
+			},
+			err: true,
+		},
+		"postDel with nameTemplate rendering invalid name": {
+			req: WeightedRequest{
+				Shares: 100,
+				PostDel: &RequestPostDel{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "pods",
+						Version:  "v1",
+					},
+					NameTemplate: "Kperf_{{.Resource}}.{{.Index}}",
+				},
+			},
+			err: true,
+		},
+		"postDel with nameTemplate": {
+			req: WeightedRequest{
+				Shares: 100,
+				PostDel: &RequestPostDel{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "pods",
+						Version:  "v1",
+					},
+					NameTemplate: "kperf-{{.Resource}}-{{.Index}}",
+				},
+			},
+			err: false,
+		},
+		"patch with both body and bodyTemplate": {
+			req: WeightedRequest{
+				Shares: 100,
+				Patch: &RequestPatch{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "configmaps",
+						Version:  "v1",
+					},
+					Name:         "kperf",
+					PatchType:    "merge",
+					Body:         `{"data":{"k":"v"}}`,
+					BodyTemplate: `{"data":{"k":"{{.Index}}"}}`,
+				},
+			},
+			err: true,
+		},
+		"patch without body": {
+			req: WeightedRequest{
+				Shares: 100,
+				Patch: &RequestPatch{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "configmaps",
+						Version:  "v1",
+					},
+					Name:      "kperf",
+					PatchType: "merge",
+				},
+			},
+			err: true,
+		},
+		"patch with invalid bodyTemplate": {
+			req: WeightedRequest{
+				Shares: 100,
+				Patch: &RequestPatch{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "configmaps",
+						Version:  "v1",
+					},
+					Name:         "kperf",
+					PatchType:    "merge",
+					BodyTemplate: `{"data":{"k":"{{.Index"}}`,
+				},
+			},
+			err: true,
+		},
+		"patch with bodyTemplate rendering invalid JSON": {
+			req: WeightedRequest{
+				Shares: 100,
+				Patch: &RequestPatch{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "configmaps",
+						Version:  "v1",
+					},
+					Name:         "kperf",
+					PatchType:    "merge",
+					BodyTemplate: `{"data":{"k":{{.Name}}}}`,
+				},
+			},
+			err: true,
+		},
+		"patch with bodyTemplate": {
+			req: WeightedRequest{
+				Shares: 100,
+				Patch: &RequestPatch{
+					KubeGroupVersionResource: KubeGroupVersionResource{
+						Resource: "configmaps",
+						Version:  "v1",
+					},
+					Name:         "kperf",
+					PatchType:    "merge",
+					BodyTemplate: `{"metadata":{"labels":{"version":"{{.Index}}","name":"{{.Name}}"}}}`,
+				},
+			},
+			err: false,
+		},
 		"no error": {
 			req: WeightedRequest{
 				Shares: 100,
@@ -74,6 +327,48 @@ func TestWeightedRequest(t *testing.T) {
 			},
 			err: false,
 		},
+		"put optimisticLock without useExistingTemplate": {
+			req: WeightedRequest{
+				Shares: 100,
+				Put: &RequestPut{
+					KubeGroupVersionResource: KubeGroupVersionResource{Version: "v1", Resource: "configmaps"},
+					Name:                     "kperf",
+					KeySpaceSize:             10,
+					ValueSize:                1024,
+					UseExistingTemplate:      false,
+					OptimisticLock:           true,
+				},
+			},
+			err: true,
+		},
+		"put useExistingTemplate of pods": {
+			req: WeightedRequest{
+				Shares: 100,
+				Put: &RequestPut{
+					KubeGroupVersionResource: KubeGroupVersionResource{Version: "v1", Resource: "pods"},
+					Name:                     "kperf",
+					KeySpaceSize:             10,
+					ValueSize:                1024,
+					UseExistingTemplate:      true,
+					OptimisticLock:           true,
+				},
+			},
+			err: true,
+		},
+		"put useExistingTemplate with optimisticLock": {
+			req: WeightedRequest{
+				Shares: 100,
+				Put: &RequestPut{
+					KubeGroupVersionResource: KubeGroupVersionResource{Version: "v1", Resource: "configmaps"},
+					Name:                     "kperf",
+					KeySpaceSize:             10,
+					ValueSize:                1024,
+					UseExistingTemplate:      true,
+					OptimisticLock:           true,
+				},
+			},
+			err: false,
+		},
 	}
 
 	for name, tc := range tests {
@@ -87,3 +382,416 @@ func TestWeightedRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestWeightedRequestEqual(t *testing.T) {
+	gvr := KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+	list := &WeightedRequest{Shares: 1, List: &RequestList{KubeGroupVersionResource: gvr, Consistency: ConsistencyStale}}
+
+	for name, tc := range map[string]struct {
+		other WeightedRequest
+		equal bool
+	}{
+		"same": {
+			other: WeightedRequest{Shares: 1, List: &RequestList{KubeGroupVersionResource: gvr, Consistency: ConsistencyStale}},
+			equal: true,
+		},
+		"different shares": {
+			other: WeightedRequest{Shares: 5, List: &RequestList{KubeGroupVersionResource: gvr, Consistency: ConsistencyStale}},
+			equal: true,
+		},
+		"deprecated": {
+			other: WeightedRequest{Shares: 1, StaleList: &RequestList{KubeGroupVersionResource: gvr}},
+			equal: true,
+		},
+		"different consistency": {
+			other: WeightedRequest{Shares: 1, List: &RequestList{KubeGroupVersionResource: gvr, Consistency: ConsistencyQuorum}},
+		},
+		"different limit": {
+			other: WeightedRequest{Shares: 1, List: &RequestList{KubeGroupVersionResource: gvr, Consistency: ConsistencyStale, Limit: 10}},
+		},
+		"different type": {
+			other: WeightedRequest{Shares: 1, Get: &RequestGet{KubeGroupVersionResource: gvr, Consistency: ConsistencyStale}},
+		},
+		"different settings": {
+			other: WeightedRequest{Shares: 1, List: &RequestList{KubeGroupVersionResource: gvr, Consistency: ConsistencyStale}, MaxConcurrency: 1},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.equal, list.Equal(tc.other))
+			assert.Equal(t, tc.equal, tc.other.Equal(*list))
+		})
+	}
+}
+
+func TestValidatePatchBody(t *testing.T) {
+	tests := map[string]struct {
+		patchType string
+		body      string
+		err       bool
+	}{
+		"merge object":                   {patchType: "merge", body: `{"data":{"k":"v"}}`},
+		"strategic-merge object":         {patchType: "strategic-merge", body: `{"metadata":{"labels":{"k":"v"}}}`},
+		"merge array":                    {patchType: "merge", body: `[{"op":"add","path":"/a"}]`, err: true},
+		"strategic-merge array":          {patchType: "strategic-merge", body: `[]`, err: true},
+		"merge null":                     {patchType: "merge", body: `null`, err: true},
+		"json operations":                {patchType: "json", body: `[{"op":"add","path":"/a","value":1},{"op":"remove","path":"/b"}]`},
+		"json object":                    {patchType: "json", body: `{"data":{"k":"v"}}`, err: true},
+		"json operation with unknown op": {patchType: "json", body: `[{"op":"upsert","path":"/a"}]`, err: true},
+		"json operation without path":    {patchType: "json", body: `[{"op":"remove"}]`, err: true},
+		"invalid JSON":                   {patchType: "merge", body: `{`, err: true},
+		"unknown patch type":             {patchType: "apply", body: `{}`, err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidatePatchBody(tc.patchType, tc.body)
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoadProfileSpecValidateHistogramBuckets(t *testing.T) {
+	newSpec := func(buckets []float64) LoadProfileSpec {
+		return LoadProfileSpec{
+			Conns:            1,
+			Client:           1,
+			ContentType:      ContentTypeJSON,
+			Mode:             ModeWeightedRandom,
+			ModeConfig:       &WeightedRandomConfig{},
+			HistogramBuckets: buckets,
+		}
+	}
+
+	spec := newSpec(nil)
+	assert.NoError(t, spec.Validate())
+
+	spec = newSpec([]float64{0.1, 0.5, 1})
+	assert.NoError(t, spec.Validate())
+
+	spec = newSpec([]float64{0.1, 0.1})
+	assert.Error(t, spec.Validate())
+
+	spec = newSpec([]float64{0, 1})
+	assert.Error(t, spec.Validate())
+}
+
+func TestLoadProfileSpecTimeouts(t *testing.T) {
+	in := `
+conns: 1
+client: 1
+contentType: json
+connectTimeoutSeconds: 5
+readTimeoutSeconds: 30
+mode: weighted-random
+modeConfig:
+  rate: 10
+  total: 10
+  requests:
+  - shares: 1
+    staleGet:
+      version: v1
+      resource: pods
+      namespace: default
+      name: x
+`
+	var spec LoadProfileSpec
+	require.NoError(t, yaml.Unmarshal([]byte(in), &spec))
+	assert.Equal(t, Seconds(5), spec.ConnectTimeoutSeconds)
+	assert.Equal(t, Seconds(30), spec.ReadTimeoutSeconds)
+	assert.NoError(t, spec.Validate())
+
+	spec.ConnectTimeoutSeconds = -1
+	assert.Error(t, spec.Validate())
+
+	spec.ConnectTimeoutSeconds = 0
+	spec.ReadTimeoutSeconds = -1
+	assert.Error(t, spec.Validate())
+}
+
+func TestLoadProfileSpecTransport(t *testing.T) {
+	in := `
+conns: 1
+client: 1
+contentType: json
+transport:
+  idleConnTimeoutSeconds: 5
+  maxIdleConnsPerHost: 2
+  tlsHandshakeTimeoutSeconds: 3
+mode: weighted-random
+modeConfig:
+  rate: 10
+  total: 10
+  requests:
+  - shares: 1
+    staleGet:
+      version: v1
+      resource: pods
+      namespace: default
+      name: x
+`
+	var spec LoadProfileSpec
+	require.NoError(t, yaml.Unmarshal([]byte(in), &spec))
+	assert.Equal(t, &TransportSpec{
+		IdleConnTimeoutSeconds:     5,
+		MaxIdleConnsPerHost:        2,
+		TLSHandshakeTimeoutSeconds: 3,
+	}, spec.Transport)
+	assert.NoError(t, spec.Validate())
+
+	for name, transport := range map[string]TransportSpec{
+		"negative idleConnTimeoutSeconds":     {IdleConnTimeoutSeconds: -1},
+		"negative maxIdleConnsPerHost":        {MaxIdleConnsPerHost: -1},
+		"negative tlsHandshakeTimeoutSeconds": {TLSHandshakeTimeoutSeconds: -1},
+		"idle timeout without keep-alives":    {DisableKeepAlives: true, IdleConnTimeoutSeconds: 1},
+		"idle conns without keep-alives":      {DisableKeepAlives: true, MaxIdleConnsPerHost: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			spec.Transport = &transport
+			assert.Error(t, spec.Validate())
+		})
+	}
+
+	spec.Transport = &TransportSpec{DisableKeepAlives: true}
+	assert.NoError(t, spec.Validate())
+}
+
+func TestLoadProfileSpecDispatchBufferSize(t *testing.T) {
+	in := `
+conns: 1
+client: 1
+contentType: json
+dispatchBufferSize: 16
+mode: weighted-random
+modeConfig:
+  rate: 10
+  total: 10
+  requests:
+  - shares: 1
+    staleGet:
+      version: v1
+      resource: pods
+      namespace: default
+      name: x
+`
+	var spec LoadProfileSpec
+	require.NoError(t, yaml.Unmarshal([]byte(in), &spec))
+	assert.Equal(t, 16, spec.DispatchBufferSize)
+
+	data, err := json.Marshal(spec)
+	require.NoError(t, err)
+
+	var again LoadProfileSpec
+	require.NoError(t, json.Unmarshal(data, &again))
+	assert.Equal(t, 16, again.DispatchBufferSize)
+}
+
+func TestLoadProfileSpecDNS(t *testing.T) {
+	in := `
+conns: 1
+client: 1
+contentType: json
+dnsCacheTTLSeconds: 30
+dnsServers:
+- 10.0.0.10
+- 10.0.0.11:5353
+mode: weighted-random
+modeConfig:
+  rate: 10
+  total: 10
+  requests:
+  - shares: 1
+    staleGet:
+      version: v1
+      resource: pods
+      namespace: default
+      name: x
+`
+	var spec LoadProfileSpec
+	require.NoError(t, yaml.Unmarshal([]byte(in), &spec))
+	assert.Equal(t, Seconds(30), spec.DNSCacheTTLSeconds)
+	assert.Equal(t, []string{"10.0.0.10", "10.0.0.11:5353"}, spec.DNSServers)
+	assert.NoError(t, spec.Validate())
+
+	data, err := json.Marshal(spec)
+	require.NoError(t, err)
+
+	var again LoadProfileSpec
+	require.NoError(t, json.Unmarshal(data, &again))
+	assert.Equal(t, spec.DNSCacheTTLSeconds, again.DNSCacheTTLSeconds)
+	assert.Equal(t, spec.DNSServers, again.DNSServers)
+
+	spec.DNSServers = []string{"ns.example.com"}
+	assert.Error(t, spec.Validate())
+
+	spec.DNSServers = nil
+	spec.DNSCacheTTLSeconds = -1
+	assert.Error(t, spec.Validate())
+}
+
+func FuzzLoadProfileUnmarshalJSON(f *testing.F) {
+	// The runner group specs shipped by runkperf are the existing valid
+	// profiles.
+	files, err := filepath.Glob("../../contrib/internal/manifests/loadprofile/*.yaml")
+	require.NoError(f, err)
+	require.NotEmpty(f, files)
+
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		require.NoError(f, err)
+
+		var rg struct {
+			LoadProfile LoadProfile `yaml:"loadProfile"`
+		}
+		require.NoError(f, yaml.Unmarshal(raw, &rg), file)
+
+		data, err := json.Marshal(rg.LoadProfile)
+		require.NoError(f, err, file)
+		f.Add(data)
+	}
+
+	for _, seed := range []string{
+		`{"version":1,"spec":{"conns":1,"client":1,"rate":10,"total":10,"requests":[{"shares":1,"staleList":{"version":"v1","resource":"pods"}}]}}`,
+		`{"version":1,"spec":{"conns":1,"client":1,"mode":"adaptive","modeConfig":{"targetP99Seconds":1,"maxRate":10}}}`,
+		`{"version":1,"spec":{"conns":1,"client":1,"mode":"burst","modeConfig":{"burstDuration":1,"cycles":1}}}`,
+		`{"version":1,"spec":{"conns":1,"client":1,"mode":"adaptive","modeConfig":{"targetP99Seconds":1,"maxRate":10,"requests":[null]}}}`,
+		`{"version":1,"spec":{"conns":1,"client":1,"mode":"burst","modeConfig":{"burstDuration":1,"cycles":1,"requests":[null]}}}`,
+		`{"version":1,"spec":{"conns":1,"client":1,"mode":"composite","modeConfig":{"children":[{"mode":"trace","modeConfig":{"file":"a.csv"}},{"mode":"burst"}]}}}`,
+		`{"version":1,"spec":{"mode":"weighted-random"}}`,
+		`{"version":1,"spec":{"readTimeoutSeconds":"30s","mode":"weighted-random","modeConfig":{"duration":"1h30m"}}}`,
+		`{"version":1,"spec":{"mode":"unknown","modeConfig":{}}}`,
+		`{"spec":{"modeConfig":null}}`,
+		`{"spec":null}`,
+		`null`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var lp LoadProfile
+		if err := json.Unmarshal(data, &lp); err != nil {
+			return
+		}
+		_ = lp.Validate()
+		if lp.Spec.ModeConfig != nil {
+			_ = lp.Spec.ModeConfig.Validate(nil)
+		}
+
+		first, err := json.Marshal(lp)
+		require.NoError(t, err)
+
+		var again LoadProfile
+		require.NoError(t, json.Unmarshal(first, &again), string(first))
+
+		second, err := json.Marshal(again)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(first), string(second))
+	})
+}
+
+func TestLoadProfileChecksum(t *testing.T) {
+	in := `
+version: 1
+description: checksum
+annotations:
+  kperf.io/git-sha: abc
+spec:
+  conns: 2
+  client: 1
+  contentType: json
+  mode: weighted-random
+  modeConfig:
+    rate: 10
+    total: 100
+    requests:
+    - list:
+        version: v1
+        resource: pods
+        consistency: stale
+      shares: 1
+`
+	// The same profile with different formatting, key order, legacy
+	// request type and annotations.
+	equivalent := `
+version: 1
+description: "checksum"
+spec:
+  client: 1
+  conns: 2
+  contentType: json
+  mode: weighted-random
+  modeConfig:
+    total: 100
+    rate: 10
+    requests:
+    - shares: 1
+      staleList: {resource: pods, version: v1}
+`
+
+	checksumOf := func(in string) string {
+		var profile LoadProfile
+		require.NoError(t, yaml.Unmarshal([]byte(in), &profile))
+		checksum, err := profile.Checksum()
+		require.NoError(t, err)
+		return checksum
+	}
+
+	checksum := checksumOf(in)
+	assert.Len(t, checksum, 64)
+	assert.Equal(t, checksum, checksumOf(equivalent))
+	assert.NotEqual(t, checksum, checksumOf(strings.Replace(in, "rate: 10", "rate: 20", 1)))
+}
+
+// customModeConfig is the config of custom mode registered in test.
+type customModeConfig struct {
+	PluginModeConfig `json:"-" yaml:"-"`
+
+	Count int `json:"count" yaml:"count"`
+}
+
+func (c *customModeConfig) GetOverridableFields() []OverridableField    { return nil }
+func (c *customModeConfig) ApplyOverrides(map[string]interface{}) error { return nil }
+func (c *customModeConfig) ConfigureClientOptions() ClientOptions       { return ClientOptions{} }
+func (c *customModeConfig) Validate(map[string]interface{}) error       { return nil }
+
+func TestRegisterModeConfig(t *testing.T) {
+	mode := ExecutionMode("test-custom")
+	in := []byte(`
+conns: 1
+client: 1
+contentType: json
+mode: test-custom
+modeConfig:
+  count: 3
+`)
+
+	var spec LoadProfileSpec
+	require.Error(t, yaml.Unmarshal(in, &spec))
+	require.Error(t, mode.Validate())
+
+	RegisterModeConfig(mode, func() ModeConfig { return &customModeConfig{} })
+	require.NoError(t, mode.Validate())
+
+	require.NoError(t, yaml.Unmarshal(in, &spec))
+	require.NoError(t, spec.Validate())
+	assert.Equal(t, &customModeConfig{Count: 3}, spec.ModeConfig)
+
+	spec = LoadProfileSpec{}
+	require.NoError(t, json.Unmarshal([]byte(`{"conns":1,"client":1,"contentType":"json","mode":"test-custom","modeConfig":{"count":5}}`), &spec))
+	assert.Equal(t, &customModeConfig{Count: 5}, spec.ModeConfig)
+
+	err := yaml.Unmarshal([]byte(`
+mode: test-custom
+modeConfig:
+  rate: 3
+`), &spec)
+	assert.ErrorContains(t, err, "unknown keys [rate]")
+
+	assert.Panics(t, func() {
+		RegisterModeConfig(ModeWeightedRandom, func() ModeConfig { return &customModeConfig{} })
+	})
+	assert.Panics(t, func() { RegisterModeConfig(mode, nil) })
+}
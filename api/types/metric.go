@@ -3,7 +3,10 @@
 
 package types
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // ResponseErrorType is error type of response.
 type ResponseErrorType string
@@ -20,6 +23,16 @@ const (
 	ResponseErrorTypeConnection ResponseErrorType = "connection"
 )
 
+// Validate returns error if ResponseErrorType is not supported.
+func (t ResponseErrorType) Validate() error {
+	switch t {
+	case ResponseErrorTypeUnknown, ResponseErrorTypeHTTP, ResponseErrorTypeHTTP2Protocol, ResponseErrorTypeConnection:
+		return nil
+	default:
+		return fmt.Errorf("unsupported response error type %s", t)
+	}
+}
+
 // ResponseError is the record about that error.
 type ResponseError struct {
 	Method string `json:"method"`
@@ -39,6 +52,36 @@ type ResponseError struct {
 	Message string `json:"message"`
 }
 
+// ErrorRate is the ratio of failed requests to attempted requests.
+type ErrorRate struct {
+	// Attempts is the number of attempted requests.
+	Attempts int64 `json:"attempts"`
+	// Failures is the number of failed requests.
+	Failures int64 `json:"failures"`
+	// Rate is Failures / Attempts.
+	Rate float64 `json:"rate"`
+}
+
+// DefaultHistogramBuckets is the default upper bounds of latency histogram in
+// seconds. It follows kube-apiserver's apiserver_request_duration_seconds.
+var DefaultHistogramBuckets = []float64{
+	0.005, 0.025, 0.05, 0.1, 0.2, 0.4, 0.6, 0.8, 1.0, 1.25, 1.5,
+	2, 3, 4, 5, 6, 8, 10, 15, 20, 30, 45, 60,
+}
+
+// LatencyHistogram is the latency distribution in buckets.
+type LatencyHistogram struct {
+	// Buckets are the upper bounds (inclusive) in seconds.
+	Buckets []float64 `json:"buckets"`
+	// Counts is the number of observations in each bucket. It has one
+	// more item than Buckets for observations greater than the last bound.
+	Counts []int64 `json:"counts"`
+	// Count is the total number of observations.
+	Count int64 `json:"count"`
+	// Sum is the sum of all the observations in seconds.
+	Sum float64 `json:"sum"`
+}
+
 // ResponseStats is the report about benchmark result.
 type ResponseStats struct {
 	// Errors stores all the observed errors.
@@ -47,25 +90,354 @@ type ResponseStats struct {
 	LatenciesByURL map[string][]float64
 	// TotalReceivedBytes is total bytes read from apiserver.
 	TotalReceivedBytes int64
+	// ResponseSizesByURL stores all the observed response sizes in bytes
+	// for each request. It's only available if response size tracking is
+	// enabled.
+	ResponseSizesByURL map[string][]int64
+	// AttemptsByURL stores the number of attempted requests for each request.
+	AttemptsByURL map[string]int64
+	// FailuresByURL stores the number of failed requests for each request.
+	FailuresByURL map[string]int64
+	// AttemptsByMethod stores the number of attempted requests for each method.
+	AttemptsByMethod map[string]int64
+	// FailuresByMethod stores the number of failed requests for each method.
+	FailuresByMethod map[string]int64
+	// WatchSetupLatenciesByURL stores the time to first event or bookmark
+	// for each watch-churn request.
+	WatchSetupLatenciesByURL map[string][]float64
+	// WatchEventLagsByURL stores the time from object's creation to
+	// receiving its ADDED event for each watch-churn request.
+	WatchEventLagsByURL map[string][]float64
+	// TTFBByURL stores the time to first byte, which is until response
+	// headers are received, for each request. It's only available if
+	// tiered latency is enabled.
+	TTFBByURL map[string][]float64
+	// BodyReadLatenciesByURL stores the time to read the whole response
+	// body after headers for each request. It's only available if tiered
+	// latency is enabled.
+	BodyReadLatenciesByURL map[string][]float64
+	// TotalWatchEvents is total number of events received by watch-churn requests.
+	TotalWatchEvents int64
+	// TotalWatchBookmarks is total number of bookmarks received by watch-churn requests.
+	TotalWatchBookmarks int64
+	// LogLinesByURL stores the number of received log lines for each
+	// getPodLog request with countLines.
+	LogLinesByURL map[string]int64
+	// LogTimeSpanByURL stores the longest time span in seconds between the
+	// first and last log lines for each getPodLog request with
+	// parseTimestamps.
+	LogTimeSpanByURL map[string]float64
+	// LatenciesByConnection stores all the observed latencies for each
+	// connection index. It's only available if per-connection tracking is
+	// enabled.
+	LatenciesByConnection map[int][]float64
+	// RequestsByWorker stores the number of finished requests for each
+	// worker index.
+	RequestsByWorker []int64
+	// RequestsByConnection stores the number of finished requests for each
+	// connection index.
+	RequestsByConnection []int64
+	// FailuresByConnection stores the number of failed requests for each
+	// connection index.
+	FailuresByConnection []int64
+	// LatencySumByConnection stores the sum of successful requests'
+	// latencies in seconds for each connection index.
+	LatencySumByConnection []float64
 }
 
 type RunnerMetricReport struct {
-	// Total represents total number of requests.
+	// SchemaVersion is the version of report's schema.
+	SchemaVersion string `json:"schemaVersion,omitempty"`
+	// Metadata is the provenance of benchmark.
+	Metadata *RunMetadata `json:"metadata,omitempty"`
+	// Annotations are copied from load profile and --annotate flags.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// ProfileChecksum is LoadProfile.Checksum of the load profile before
+	// variable substitution and CLI overrides, which links the result to
+	// the profile file. The effective spec is in Metadata.
+	ProfileChecksum string `json:"profileChecksum,omitempty"`
+	// Total represents total number of attempted requests, which is
+	// successCount plus errorCount.
 	Total int `json:"total"`
+	// SuccessCount is the number of requests without error.
+	SuccessCount int `json:"successCount"`
+	// ErrorCount is the number of failed requests.
+	ErrorCount int `json:"errorCount"`
+	// ErrorRate is the ratio of failed requests to total.
+	ErrorRate float64 `json:"errorRate"`
+	// SuccessRate is the ratio of successful requests to total.
+	SuccessRate float64 `json:"successRate"`
 	// Duration means the time of benchmark.
 	Duration string `json:"duration"`
+	// TerminatedEarly is true if benchmark is terminated before all the
+	// requests are sent, like being stopped or violating SLO.
+	TerminatedEarly bool `json:"terminatedEarly,omitempty"`
+	// TerminationCause is the reason why benchmark is terminated early.
+	TerminationCause string `json:"terminationCause,omitempty"`
+	// FailFastError is the error which terminates benchmark by fail-fast.
+	FailFastError *ResponseError `json:"failFastError,omitempty"`
 	// Errors stores all the observed errors.
 	Errors []ResponseError `json:"errors,omitempty"`
 	// ErrorStats means summary of errors group by type.
 	ErrorStats map[string]int32 `json:"errorStats,omitempty"`
+	// ErrorRateByURL represents the error rate per request.
+	ErrorRateByURL map[string]ErrorRate `json:"errorRateByURL,omitempty"`
+	// ErrorRateByMethod represents the error rate per method.
+	ErrorRateByMethod map[string]ErrorRate `json:"errorRateByMethod,omitempty"`
 	// TotalReceivedBytes is total bytes read from apiserver.
 	TotalReceivedBytes int64 `json:"totalReceivedBytes"`
+	// ExecutorReport is the mode-specific report, like the sustainable
+	// rate found by adaptive mode.
+	ExecutorReport *ExecutorReport `json:"executorReport,omitempty"`
+	// DispatchBlockedTime is the cumulative time executor is blocked on
+	// sending requests because all the workers are busy.
+	DispatchBlockedTime string `json:"dispatchBlockedTime,omitempty"`
+	// MaxDispatchBlockedTime is the longest time of one blocked send.
+	MaxDispatchBlockedTime string `json:"maxDispatchBlockedTime,omitempty"`
+	// ConnectionRampInterval is the interval between connections put into
+	// use if connection ramp-up is enabled.
+	ConnectionRampInterval string `json:"connectionRampInterval,omitempty"`
+	// ClientRotationCount is the number of times the clients are rebuilt
+	// from kubeconfig if kubeconfig rotation is enabled.
+	ClientRotationCount int `json:"clientRotationCount,omitempty"`
+	// BenchmarkStartTime is when executor starts to send the first request,
+	// in RFC3339. It's the start of the window to query apiserver's
+	// metrics, like from Prometheus.
+	BenchmarkStartTime string `json:"benchmarkStartTime,omitempty"`
+	// BenchmarkEndTime is when executor finishes sending the last request,
+	// in RFC3339.
+	BenchmarkEndTime string `json:"benchmarkEndTime,omitempty"`
 	// LatenciesByURL stores all the observed latencies.
 	LatenciesByURL map[string][]float64 `json:"latenciesByURL,omitempty"`
 	// PercentileLatencies represents the latency distribution in seconds.
 	PercentileLatencies [][2]float64 `json:"percentileLatencies,omitempty"`
 	// PercentileLatenciesByURL represents the latency distribution in seconds per request.
 	PercentileLatenciesByURL map[string][][2]float64 `json:"percentileLatenciesByURL,omitempty"`
+	// LatencyHistograms represents the latency histogram per request.
+	LatencyHistograms map[string]LatencyHistogram `json:"latencyHistograms,omitempty"`
+	// WatchSetupLatenciesByURL stores all the observed watch setup latencies.
+	WatchSetupLatenciesByURL map[string][]float64 `json:"watchSetupLatenciesByURL,omitempty"`
+	// PercentileWatchSetupLatenciesByURL represents the watch setup latency
+	// (time to first event or bookmark) distribution in seconds per request.
+	PercentileWatchSetupLatenciesByURL map[string][][2]float64 `json:"percentileWatchSetupLatenciesByURL,omitempty"`
+	// WatchEventLagsByURL stores all the observed watch event lags.
+	WatchEventLagsByURL map[string][]float64 `json:"watchEventLagsByURL,omitempty"`
+	// PercentileWatchEventLagsByURL represents the watch event delivery lag
+	// (time from object's creation to receiving its ADDED event)
+	// distribution in seconds per request.
+	PercentileWatchEventLagsByURL map[string][][2]float64 `json:"percentileWatchEventLagsByURL,omitempty"`
+	// TTFBByURL stores all the observed time to first byte.
+	TTFBByURL map[string][]float64 `json:"ttfbByURL,omitempty"`
+	// PercentileTTFBByURL represents the time to first byte, which is until
+	// response headers are received, distribution in seconds per request.
+	PercentileTTFBByURL map[string][][2]float64 `json:"percentileTTFBByURL,omitempty"`
+	// BodyReadLatenciesByURL stores all the observed body read latencies.
+	BodyReadLatenciesByURL map[string][]float64 `json:"bodyReadLatenciesByURL,omitempty"`
+	// PercentileBodyReadLatencyByURL represents the distribution of time in
+	// seconds to read the whole response body after headers per request.
+	PercentileBodyReadLatencyByURL map[string][][2]float64 `json:"percentileBodyReadLatencyByURL,omitempty"`
+	// TotalWatchEvents is total number of events received by watch-churn requests.
+	TotalWatchEvents int64 `json:"totalWatchEvents,omitempty"`
+	// TotalWatchBookmarks is total number of bookmarks received by watch-churn requests.
+	TotalWatchBookmarks int64 `json:"totalWatchBookmarks,omitempty"`
+	// LogLinesByURL is the number of received log lines per getPodLog
+	// request with countLines.
+	LogLinesByURL map[string]int64 `json:"logLinesByURL,omitempty"`
+	// LogTimeSpanByURL is the longest time span in seconds between the first
+	// and last log lines per getPodLog request with parseTimestamps.
+	LogTimeSpanByURL map[string]float64 `json:"logTimeSpanByURL,omitempty"`
+	// PercentileLatenciesByConnection represents the latency distribution in
+	// seconds per connection. The key is in conn-{index} format.
+	PercentileLatenciesByConnection map[string][][2]float64 `json:"percentileLatenciesByConnection,omitempty"`
+	// WorkerStats shows how requests are distributed among workers.
+	WorkerStats *WorkerStats `json:"workerStats,omitempty"`
+	// ConnectionStats shows how requests are distributed among connections.
+	ConnectionStats *ConnectionStats `json:"connectionStats,omitempty"`
+	// TransportStats shows how HTTP connections are established and used.
+	TransportStats *TransportStats `json:"transportStats,omitempty"`
+	// PercentileResponseSizeByURL represents the response size distribution
+	// in bytes per request.
+	PercentileResponseSizeByURL map[string][][2]float64 `json:"percentileResponseSizeByURL,omitempty"`
+	// MergedReports lists the reports which this report is merged from.
+	MergedReports []MergedReport `json:"mergedReports,omitempty"`
+	// SkippedLatencyHistograms lists the latency histograms which aren't
+	// merged into LatencyHistograms, like the ones with different bucket
+	// boundaries.
+	SkippedLatencyHistograms []SkippedLatencyHistogram `json:"skippedLatencyHistograms,omitempty"`
+}
+
+// WorkerStats is the distribution of requests among workers.
+type WorkerStats struct {
+	// Requests is the number of finished requests per worker index.
+	Requests []int64 `json:"requests"`
+	// Skew is the ratio of max to min requests. The min is treated as 1 if
+	// it's zero. 1 means requests are evenly distributed.
+	Skew float64 `json:"skew"`
+}
+
+// ConnectionStats is the distribution of requests among connections.
+type ConnectionStats struct {
+	// Requests is the number of finished requests per connection index.
+	Requests []int64 `json:"requests"`
+	// Failures is the number of failed requests per connection index.
+	Failures []int64 `json:"failures"`
+	// AverageLatencies is the average latency in seconds of successful
+	// requests per connection index.
+	AverageLatencies []float64 `json:"averageLatencies"`
+	// Skew is the ratio of max to min requests. The min is treated as 1 if
+	// it's zero. 1 means requests are evenly distributed.
+	Skew float64 `json:"skew"`
+}
+
+// TransportStats is the statistics of HTTP transports.
+type TransportStats struct {
+	// ConnectionsOpened is the number of connections dialed successfully.
+	ConnectionsOpened int64 `json:"connectionsOpened"`
+	// ConnectionsReused is the number of HTTP requests sent over an
+	// existing connection.
+	ConnectionsReused int64 `json:"connectionsReused"`
+	// TLSHandshakes is the number of successful TLS handshakes.
+	TLSHandshakes int64 `json:"tlsHandshakes"`
+	// ProxyConnects is the number of successful CONNECT tunnels set up
+	// through HTTP proxy.
+	ProxyConnects int64 `json:"proxyConnects,omitempty"`
+	// ProxyConnectSeconds is the total time in seconds spent on CONNECT
+	// tunnels, from connecting to proxy to receiving CONNECT response.
+	// It's also counted in the latencies of requests which dialed the
+	// connections.
+	ProxyConnectSeconds float64 `json:"proxyConnectSeconds,omitempty"`
+	// DNSResolutions is the number of DNS resolutions for new connections.
+	// It excludes the ones served by DNS cache.
+	DNSResolutions int64 `json:"dnsResolutions,omitempty"`
+	// DNSResolutionDurationSeconds is the histogram of DNS resolution
+	// durations in seconds.
+	DNSResolutionDurationSeconds *LatencyHistogram `json:"dnsResolutionDurationSeconds,omitempty"`
+	// Protocols is the number of connections per negotiated protocol,
+	// like h2 or http/1.1.
+	Protocols map[string]int `json:"protocols"`
+	// Connections lists the connections which have served HTTP requests.
+	Connections []TransportConnectionStats `json:"connections"`
+}
+
+// TransportConnectionStats is the statistics of one HTTP connection.
+type TransportConnectionStats struct {
+	// Client is the index of client which owns the connection.
+	Client int `json:"client"`
+	// LocalAddr is the local address of the connection.
+	LocalAddr string `json:"localAddr"`
+	// Protocol is the negotiated protocol, like h2 or http/1.1.
+	Protocol string `json:"protocol"`
+	// Requests is the number of HTTP requests sent over the connection.
+	Requests int64 `json:"requests"`
+}
+
+// RunnerState represents the state of running runner.
+type RunnerState string
+
+const (
+	// RunnerStatePending means the benchmark isn't started yet.
+	RunnerStatePending RunnerState = "pending"
+	// RunnerStateRunning means the benchmark is running.
+	RunnerStateRunning RunnerState = "running"
+	// RunnerStateFinished means the benchmark is done.
+	RunnerStateFinished RunnerState = "finished"
+)
+
+// RunnerStatus represents the progress of running runner.
+type RunnerStatus struct {
+	// State is the state of benchmark.
+	State RunnerState `json:"state"`
+	// Elapsed is the time since benchmark started.
+	Elapsed string `json:"elapsed,omitempty"`
+	// Completed is the number of finished requests.
+	Completed int64 `json:"completed"`
+	// Errors is the number of failed requests.
+	Errors int64 `json:"errors"`
+	// ExpectedTotal is the total number of requests expected (0 if unbounded).
+	ExpectedTotal int `json:"expectedTotal"`
+	// CurrentRate is the number of finished requests per second recently.
+	CurrentRate float64 `json:"currentRate"`
+	// AverageRate is the number of finished requests per second since
+	// benchmark started.
+	AverageRate float64 `json:"averageRate"`
+	// OpenWatches is the number of open watch-churn requests.
+	OpenWatches int64 `json:"openWatches"`
+	// InFlight is the number of dispatched requests which are not done
+	// yet. It's only reported by executors capping in-flight requests,
+	// like weighted-random mode with maxInFlight.
+	InFlight int64 `json:"inFlight,omitempty"`
+	// Stopped means that stop has been requested.
+	Stopped bool `json:"stopped"`
+	// Paused means that benchmark is paused from sending new requests.
+	Paused bool `json:"paused,omitempty"`
+}
+
+// RunnerMetricReportSchemaVersion is the current version of RunnerMetricReport.
+const RunnerMetricReportSchemaVersion = "v1"
+
+// RunMetadata is the provenance of one benchmark run.
+type RunMetadata struct {
+	// RunID is unique ID generated at the start of benchmark.
+	RunID string `json:"runID"`
+	// StartTime is when benchmark started.
+	StartTime time.Time `json:"startTime"`
+	// EndTime is when benchmark finished. It's empty if benchmark is
+	// still running.
+	EndTime *time.Time `json:"endTime,omitempty"`
+	// Version is kperf's version.
+	Version string `json:"version"`
+	// Revision is kperf's VCS revision.
+	Revision string `json:"revision,omitempty"`
+	// ProfileHash is LoadProfile.Checksum of the load profile, which is the
+	// same as the report's ProfileChecksum.
+	ProfileHash string `json:"profileHash,omitempty"`
+	// Hostname is the host which runs benchmark.
+	Hostname string `json:"hostname,omitempty"`
+	// Context is the kubeconfig context used by clients. It's empty for
+	// in-cluster config.
+	Context string `json:"context,omitempty"`
+	// Cluster is the kubeconfig cluster used by clients.
+	Cluster string `json:"cluster,omitempty"`
+	// Server is the address of apiserver which requests were sent to.
+	Server string `json:"server,omitempty"`
+	// UserAgent is the user agent pattern of clients. The {index}
+	// placeholder, if any, is replaced with the index of each client.
+	UserAgent string `json:"userAgent,omitempty"`
+	// Proxy is the HTTP proxy, without password, which the requests were
+	// sent through. It's empty if there is no proxy.
+	Proxy string `json:"proxy,omitempty"`
+	// Description is the description of load profile.
+	Description string `json:"description,omitempty"`
+	// Spec is the load profile spec after CLI overrides.
+	Spec *LoadProfileSpec `json:"spec,omitempty"`
+	// Labels are user-supplied key-value pairs.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// MergedReport is the brief of one report merged into RunnerMetricReport.
+type MergedReport struct {
+	// Source is where the report comes from, like file path.
+	Source string `json:"source"`
+	// RunID is the run ID of that report if any.
+	RunID string `json:"runID,omitempty"`
+	// Labels are the labels of that report if any.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Total represents total number of attempted requests, which is
+	// successCount plus errorCount.in that report.
+	Total int `json:"total"`
+	// Duration means the time of benchmark in that report.
+	Duration string `json:"duration"`
+}
+
+// SkippedLatencyHistogram is the latency histogram which isn't merged into
+// RunnerMetricReport.
+type SkippedLatencyHistogram struct {
+	// Source is where the histogram comes from, like runner's name.
+	Source string `json:"source"`
+	// URL is the request of the histogram.
+	URL string `json:"url"`
+	// Reason is why the histogram is skipped.
+	Reason string `json:"reason"`
 }
 
 // TODO(weifu): build brand new struct for RunnerGroupsReport to include more
@@ -3,6 +3,16 @@
 
 package types
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
 // ModeConfig is a discriminated union for mode-specific configuration.
 // It automatically deserializes to the correct concrete type based on the Mode field.
 type ModeConfig interface {
@@ -25,10 +35,51 @@ type ModeConfig interface {
 	ConfigureClientOptions() ClientOptions
 }
 
+// PluginModeConfig should be embedded by mode-specific configuration defined
+// outside this package, like executor plugins, to implement ModeConfig.
+type PluginModeConfig struct{}
+
+func (PluginModeConfig) isModeConfig() {}
+
+var (
+	modeConfigsMu sync.RWMutex
+	// modeConfigs are the config constructors of custom modes.
+	modeConfigs = map[ExecutionMode]func() ModeConfig{}
+)
+
+// RegisterModeConfig registers the config constructor of custom mode so
+// that load profile with the mode passes validation and its modeConfig is
+// decoded into the returned config. The constructor must return a pointer
+// to the empty config struct, which usually embeds PluginModeConfig.
+//
+// It panics if mode is empty or built-in, like database/sql.Register.
+func RegisterModeConfig(mode ExecutionMode, newConfig func() ModeConfig) {
+	if mode == "" || slices.Contains(builtinModes, mode) {
+		panic(fmt.Sprintf("can't register config of mode %q", mode))
+	}
+	if newConfig == nil {
+		panic(fmt.Sprintf("config constructor of mode %q is nil", mode))
+	}
+
+	modeConfigsMu.Lock()
+	defer modeConfigsMu.Unlock()
+	modeConfigs[mode] = newConfig
+}
+
+// registeredModeConfig returns the config constructor of custom mode.
+func registeredModeConfig(mode ExecutionMode) (func() ModeConfig, bool) {
+	modeConfigsMu.RLock()
+	defer modeConfigsMu.RUnlock()
+	newConfig, ok := modeConfigs[mode]
+	return newConfig, ok
+}
+
 // ClientOptions contains mode-specific REST client configuration
 type ClientOptions struct {
 	// QPS is the queries per second limit (0 means no limit)
 	QPS float64
+	// Burst is the burst of client-side token bucket (0 means default)
+	Burst int
 }
 
 // OverridableField describes a config field that can be overridden via CLI flags.
@@ -49,6 +100,9 @@ const (
 	FieldTypeInt     FieldType = "int"
 	FieldTypeString  FieldType = "string"
 	FieldTypeBool    FieldType = "bool"
+	// FieldTypeDuration is parsed from string flag by ParseSeconds, so that
+	// it accepts either seconds or duration string like 90s.
+	FieldTypeDuration FieldType = "duration"
 )
 
 // CLIContext is an interface for CLI flag access (wraps urfave/cli.Context)
@@ -80,8 +134,100 @@ func BuildOverridesFromCLI(config ModeConfig, cliCtx CLIContext) map[string]inte
 			overrides[field.Name] = cliCtx.String(field.Name)
 		case FieldTypeBool:
 			overrides[field.Name] = cliCtx.Bool(field.Name)
+		case FieldTypeDuration:
+			// NOTE: Invalid value is kept as string and rejected by
+			// ApplyOverrides.
+			overrides[field.Name] = cliCtx.String(field.Name)
 		}
 	}
 
 	return overrides
 }
+
+// builtinModes are the modes whose config is defined in this package. They
+// are suggested when modeConfig doesn't match the mode.
+var builtinModes = []ExecutionMode{
+	ModeWeightedRandom, ModeTimeSeries, ModeAdaptive, ModeBurst, ModeTrace, ModeComposite,
+}
+
+// checkModeConfigKeys returns error if any key of modeConfig isn't a field
+// of mode's config so that misplaced fields aren't dropped silently. The tag is yaml
+// or json, which defines the field names. The error suggests the mode whose
+// config has all the unknown keys if there is one.
+func checkModeConfigKeys(mode ExecutionMode, modeConfig map[string]interface{}, tag string) error {
+	config, err := newModeConfig(mode)
+	if err != nil {
+		return err
+	}
+
+	known := modeConfigFields(config, tag)
+	unknown := []string{}
+	for k := range modeConfig {
+		if !known[normalizeFieldName(k, tag)] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+
+	msg := fmt.Sprintf("unknown keys %v in modeConfig of mode %s", unknown, mode)
+	for _, m := range builtinModes {
+		if m == mode {
+			continue
+		}
+		candidate, _ := newModeConfig(m)
+		fields := modeConfigFields(candidate, tag)
+		if slices.ContainsFunc(unknown, func(k string) bool { return !fields[normalizeFieldName(k, tag)] }) {
+			continue
+		}
+		return fmt.Errorf("%s, did you mean mode %s?", msg, m)
+	}
+	return errors.New(msg)
+}
+
+// modeConfigFields returns the field names of config by the tag.
+func modeConfigFields(config ModeConfig, tag string) map[string]bool {
+	fields := map[string]bool{}
+	collectFieldNames(reflect.TypeOf(config).Elem(), tag, fields)
+	return fields
+}
+
+// collectFieldNames adds the field names of struct type t into fields,
+// including the inline or embedded struct's fields.
+func collectFieldNames(t reflect.Type, tag string, fields map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, opts, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+		inline := strings.Contains(opts, "inline") || (tag == "json" && f.Anonymous && name == "")
+		if inline && f.Type.Kind() == reflect.Struct {
+			collectFieldNames(f.Type, tag, fields)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			// NOTE: YAML uses the lowercased Go field name by default.
+			name = f.Name
+			if tag == "yaml" {
+				name = strings.ToLower(name)
+			}
+		}
+		fields[normalizeFieldName(name, tag)] = true
+	}
+}
+
+// normalizeFieldName returns the name in the form used to match fields.
+// JSON matches field names case-insensitively.
+func normalizeFieldName(name string, tag string) string {
+	if tag == "json" {
+		return strings.ToLower(name)
+	}
+	return name
+}
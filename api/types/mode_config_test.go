@@ -4,6 +4,7 @@
 package types
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -31,10 +32,10 @@ func TestBuildOverridesFromCLI(t *testing.T) {
 		"weighted-random with duration only": {
 			config: &WeightedRandomConfig{},
 			cliValues: map[string]interface{}{
-				"duration": 120,
+				"duration": "90s",
 			},
 			expectedResult: map[string]interface{}{
-				"duration": 120,
+				"duration": "90s",
 			},
 		},
 		"time-series with interval": {
@@ -171,3 +172,76 @@ func (m *mockCLIContext) Bool(name string) bool {
 	}
 	return false
 }
+
+func TestModeConfigMismatch(t *testing.T) {
+	tests := map[string]struct {
+		yaml string
+		json string
+		err  string
+	}{
+		"weighted-random keys in time-series": {
+			yaml: `
+mode: time-series
+modeConfig:
+  rate: 10
+  requests: []
+`,
+			json: `{"mode":"time-series","modeConfig":{"rate":10,"requests":[]}}`,
+			err:  "unknown keys [rate requests] in modeConfig of mode time-series, did you mean mode weighted-random?",
+		},
+		"typo without suggestion": {
+			yaml: `
+mode: weighted-random
+modeConfig:
+  rate: 10
+  totl: 100
+`,
+			json: `{"mode":"weighted-random","modeConfig":{"rate":10,"totl":100}}`,
+			err:  "unknown keys [totl] in modeConfig of mode weighted-random",
+		},
+		"mode without modeConfig": {
+			yaml: `
+mode: time-series
+`,
+			json: `{"mode":"time-series"}`,
+			err:  "mode time-series requires modeConfig",
+		},
+		"composite child": {
+			yaml: `
+mode: composite
+modeConfig:
+  children:
+  - mode: time-series
+    modeConfig:
+      rate: 10
+`,
+			json: `{"mode":"composite","modeConfig":{"children":[{"mode":"time-series","modeConfig":{"rate":10}}]}}`,
+			err:  "unknown keys [rate] in modeConfig of mode time-series",
+		},
+		"matched keys": {
+			yaml: `
+mode: time-series
+modeConfig:
+  interval: 1s
+`,
+			json: `{"mode":"time-series","modeConfig":{"Interval":"1s"}}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var fromYAML, fromJSON LoadProfileSpec
+			errYAML := yaml.Unmarshal([]byte(tc.yaml), &fromYAML)
+			errJSON := json.Unmarshal([]byte(tc.json), &fromJSON)
+			if tc.err == "" {
+				assert.NoError(t, errYAML)
+				assert.NoError(t, errJSON)
+				return
+			}
+			require.Error(t, errYAML)
+			assert.Contains(t, errYAML.Error(), tc.err)
+			require.Error(t, errJSON)
+			assert.Contains(t, errJSON.Error(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// LabelRunID is the label injected into all the objects created by runner.
+// Its value is the run ID so that the objects can be garbage-collected by
+// label selector.
+const LabelRunID = "kperf.io/run-id"
+
+// ObjectMeta defines the labels and annotations merged into the objects
+// created by requests, like postDel.
+type ObjectMeta struct {
+	// Labels are merged into created object's labels.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Annotations are merged into created object's annotations.
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// Validate verifies the syntax of label and annotation keys and values so
+// that requests don't fail in apiserver.
+func (m *ObjectMeta) Validate() error {
+	for k, v := range m.Labels {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return fmt.Errorf("invalid label key %q: %s", k, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return fmt.Errorf("invalid label value %q of %q: %s", v, k, strings.Join(errs, "; "))
+		}
+	}
+	for k := range m.Annotations {
+		if errs := validation.IsQualifiedName(strings.ToLower(k)); len(errs) > 0 {
+			return fmt.Errorf("invalid annotation key %q: %s", k, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// Merge returns a new ObjectMeta which contains m's labels and annotations
+// overridden by the given one's. Either of them can be nil.
+func (m *ObjectMeta) Merge(override *ObjectMeta) *ObjectMeta {
+	if m == nil && override == nil {
+		return nil
+	}
+
+	merged := &ObjectMeta{}
+	for _, src := range []*ObjectMeta{m, override} {
+		if src == nil {
+			continue
+		}
+		if len(src.Labels) > 0 {
+			if merged.Labels == nil {
+				merged.Labels = make(map[string]string, len(src.Labels))
+			}
+			maps.Copy(merged.Labels, src.Labels)
+		}
+		if len(src.Annotations) > 0 {
+			if merged.Annotations == nil {
+				merged.Annotations = make(map[string]string, len(src.Annotations))
+			}
+			maps.Copy(merged.Annotations, src.Annotations)
+		}
+	}
+	return merged
+}
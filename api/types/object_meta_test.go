@@ -0,0 +1,71 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectMetaValidate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		meta ObjectMeta
+		err  bool
+	}{
+		"empty":                  {meta: ObjectMeta{}},
+		"valid":                  {meta: ObjectMeta{Labels: map[string]string{"kperf.io/run-id": "abc", "team": ""}, Annotations: map[string]string{"owner": "a b c"}}},
+		"invalid label key":      {meta: ObjectMeta{Labels: map[string]string{"bad key": "x"}}, err: true},
+		"invalid label prefix":   {meta: ObjectMeta{Labels: map[string]string{"Kperf_IO/x": "x"}}, err: true},
+		"invalid label value":    {meta: ObjectMeta{Labels: map[string]string{"team": "a/b"}}, err: true},
+		"too long label value":   {meta: ObjectMeta{Labels: map[string]string{"team": strings.Repeat("a", 64)}}, err: true},
+		"invalid annotation key": {meta: ObjectMeta{Annotations: map[string]string{"-owner": "x"}}, err: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.meta.Validate()
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestObjectMetaMerge(t *testing.T) {
+	var empty *ObjectMeta
+	assert.Nil(t, empty.Merge(nil))
+
+	base := &ObjectMeta{
+		Labels:      map[string]string{"team": "perf", "app": "kperf"},
+		Annotations: map[string]string{"owner": "alice"},
+	}
+	merged := base.Merge(&ObjectMeta{Labels: map[string]string{"app": "churn"}})
+	assert.Equal(t, &ObjectMeta{
+		Labels:      map[string]string{"team": "perf", "app": "churn"},
+		Annotations: map[string]string{"owner": "alice"},
+	}, merged)
+	assert.Equal(t, "kperf", base.Labels["app"], "base should not be changed")
+
+	assert.Equal(t, base, empty.Merge(base))
+}
+
+func TestWeightedRequestWithDefaultObjectMeta(t *testing.T) {
+	meta := &ObjectMeta{Labels: map[string]string{"team": "perf"}}
+
+	get := &WeightedRequest{Shares: 1, StaleGet: &RequestGet{Name: "a"}}
+	assert.Same(t, get, get.WithDefaultObjectMeta(meta))
+
+	postDel := &WeightedRequest{Shares: 1, PostDel: &RequestPostDel{
+		ObjectMeta: &ObjectMeta{Labels: map[string]string{"team": "churn", "app": "kperf"}},
+	}}
+	assert.Same(t, postDel, postDel.WithDefaultObjectMeta(nil))
+
+	got := postDel.WithDefaultObjectMeta(meta)
+	require.NotSame(t, postDel, got)
+	assert.Equal(t, map[string]string{"team": "churn", "app": "kperf"}, got.PostDel.ObjectMeta.Labels)
+	assert.Equal(t, map[string]string{"team": "churn", "app": "kperf"}, postDel.PostDel.ObjectMeta.Labels)
+}
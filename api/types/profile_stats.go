@@ -0,0 +1,111 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+// ProfileStats is the complexity of weighted-random load profile, which
+// helps to estimate the impact on apiserver before benchmark.
+type ProfileStats struct {
+	// Mode is the execution mode of profile.
+	Mode ExecutionMode `json:"mode"`
+	// Rate is the maximum requests per second. Zero means no limit.
+	Rate float64 `json:"rate"`
+	// Requests is the number of weighted request entries.
+	Requests int `json:"requests"`
+	// TotalShares is the sum of all the shares.
+	TotalShares int `json:"totalShares"`
+	// SharesByType is the sum of shares for each request type, like
+	// staleList.
+	SharesByType map[string]int `json:"sharesByType"`
+	// RequestsByType is the number of request entries for each type.
+	RequestsByType map[string]int `json:"requestsByType"`
+	// Namespaces is the number of distinct namespaces targeted by requests.
+	// The cluster-scoped and all-namespaces requests aren't counted.
+	Namespaces int `json:"namespaces"`
+	// EstimatedWriteBytesPerSecond is the bytes of put requests' values
+	// sent per second at Rate. It's zero if Rate has no limit.
+	EstimatedWriteBytesPerSecond float64 `json:"estimatedWriteBytesPerSecond"`
+	// EstimatedReadBytesPerSecond is the bytes received per second at Rate
+	// whose size is known from profile: the put responses echoing the value
+	// and getPodLog with limitBytes, which is the upper bound. The reads
+	// depending on cluster's state, like list, aren't included. It's zero
+	// if Rate has no limit.
+	EstimatedReadBytesPerSecond float64 `json:"estimatedReadBytesPerSecond"`
+}
+
+// AnalyzeProfile returns the statistics of weighted-random profile. Only
+// Mode is set for the other modes.
+func AnalyzeProfile(profile *LoadProfile) ProfileStats {
+	stats := ProfileStats{
+		Mode:           profile.Spec.Mode,
+		SharesByType:   map[string]int{},
+		RequestsByType: map[string]int{},
+	}
+
+	config, ok := profile.Spec.ModeConfig.(*WeightedRandomConfig)
+	if !ok {
+		return stats
+	}
+	stats.Rate = config.Rate
+	stats.Requests = len(config.Requests)
+
+	namespaces := map[string]struct{}{}
+	for _, r := range config.Requests {
+		typ := r.Type()
+		stats.TotalShares += r.Shares
+		stats.SharesByType[typ] += r.Shares
+		stats.RequestsByType[typ]++
+
+		if ns := r.namespace(); ns != "" {
+			namespaces[ns] = struct{}{}
+		}
+	}
+	stats.Namespaces = len(namespaces)
+
+	if stats.TotalShares == 0 || config.Rate <= 0 {
+		return stats
+	}
+	for _, r := range config.Requests {
+		perSecond := config.Rate * float64(r.Shares) / float64(stats.TotalShares)
+		switch {
+		case r.Put != nil:
+			stats.EstimatedWriteBytesPerSecond += float64(r.Put.ValueSize) * perSecond
+			stats.EstimatedReadBytesPerSecond += float64(r.Put.ValueSize) * perSecond
+		case r.GetPodLog != nil && r.GetPodLog.LimitBytes != nil:
+			stats.EstimatedReadBytesPerSecond += float64(*r.GetPodLog.LimitBytes) * perSecond
+		}
+	}
+	return stats
+}
+
+// namespace returns the namespace targeted by the request.
+func (r *WeightedRequest) namespace() string {
+	switch {
+	case r.Get != nil:
+		return r.Get.Namespace
+	case r.StaleGet != nil:
+		return r.StaleGet.Namespace
+	case r.QuorumGet != nil:
+		return r.QuorumGet.Namespace
+	case r.List != nil:
+		return r.List.Namespace
+	case r.StaleList != nil:
+		return r.StaleList.Namespace
+	case r.QuorumList != nil:
+		return r.QuorumList.Namespace
+	case r.WatchList != nil:
+		return r.WatchList.Namespace
+	case r.WatchChurn != nil:
+		return r.WatchChurn.Namespace
+	case r.Put != nil:
+		return r.Put.Namespace
+	case r.Patch != nil:
+		return r.Patch.Namespace
+	case r.GetPodLog != nil:
+		return r.GetPodLog.Namespace
+	case r.PostDel != nil:
+		return r.PostDel.Namespace
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestAnalyzeProfile(t *testing.T) {
+	in := []byte(`
+version: 1
+spec:
+  conns: 1
+  mode: weighted-random
+  modeConfig:
+    rate: 100
+    requests:
+    - shares: 50
+      staleList:
+        version: v1
+        resource: pods
+    - shares: 10
+      get:
+        version: v1
+        resource: pods
+        namespace: default
+        name: x1
+    - shares: 20
+      put:
+        version: v1
+        resource: configmaps
+        namespace: kperf
+        name: kperf
+        keySpaceSize: 10
+        valueSize: 1024
+    - shares: 20
+      getPodLog:
+        namespace: kperf
+        name: x2
+        limitBytes: 512
+`)
+
+	var profile LoadProfile
+	require.NoError(t, yaml.Unmarshal(in, &profile))
+
+	stats := AnalyzeProfile(&profile)
+	assert.Equal(t, ModeWeightedRandom, stats.Mode)
+	assert.Equal(t, float64(100), stats.Rate)
+	assert.Equal(t, 4, stats.Requests)
+	assert.Equal(t, 100, stats.TotalShares)
+	assert.Equal(t, map[string]int{"staleList": 50, "quorumGet": 10, "put": 20, "getPodLog": 20}, stats.SharesByType)
+	assert.Equal(t, map[string]int{"staleList": 1, "quorumGet": 1, "put": 1, "getPodLog": 1}, stats.RequestsByType)
+	assert.Equal(t, 2, stats.Namespaces)
+	assert.InDelta(t, 1024*20, stats.EstimatedWriteBytesPerSecond, 0.001)
+	assert.InDelta(t, 1024*20+512*20, stats.EstimatedReadBytesPerSecond, 0.001)
+
+	// no rate limit
+	profile.Spec.ModeConfig.(*WeightedRandomConfig).Rate = 0
+	stats = AnalyzeProfile(&profile)
+	assert.Equal(t, 100, stats.TotalShares)
+	assert.Zero(t, stats.EstimatedWriteBytesPerSecond)
+	assert.Zero(t, stats.EstimatedReadBytesPerSecond)
+
+	// other modes
+	stats = AnalyzeProfile(&LoadProfile{Spec: LoadProfileSpec{Mode: ModeTimeSeries, ModeConfig: &TimeSeriesConfig{}}})
+	assert.Equal(t, ModeTimeSeries, stats.Mode)
+	assert.Zero(t, stats.Requests)
+}
@@ -3,7 +3,12 @@
 
 package types
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // RunnerGroup defines a set of runners with same load profile.
 type RunnerGroup struct {
@@ -30,6 +35,93 @@ type RunnerGroupSpec struct {
 	//
 	// FORMAT: APIVersion:Kind:Name:UID
 	OwnerReference *string `json:"ownerReference,omitempty" yaml:"ownerReference,omitempty"`
+	// Resources defines the resource requests of each runner.
+	Resources *RunnerResources `json:"resources,omitempty" yaml:"resources,omitempty"`
+	// NodeSelector deploys runners into the nodes which have all the
+	// labels.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty" yaml:"nodeSelector,omitempty"`
+	// Tolerations allows runners to be deployed into the nodes with
+	// matching taints.
+	Tolerations []RunnerToleration `json:"tolerations,omitempty" yaml:"tolerations,omitempty"`
+}
+
+// RunnerResources defines the resource requests of each runner.
+type RunnerResources struct {
+	// CPU is the quantity of cpu, like 500m or 2. Empty means no request.
+	CPU string `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	// Memory is the quantity of memory, like 4Gi. Empty means no request.
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+}
+
+// Validate verifies fields of RunnerResources.
+func (r *RunnerResources) Validate() error {
+	for _, q := range [][2]string{{"cpu", r.CPU}, {"memory", r.Memory}} {
+		if q[1] == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(q[1]); err != nil {
+			return fmt.Errorf("invalid %s quantity %q: %w", q[0], q[1], err)
+		}
+	}
+	return nil
+}
+
+// RunnerToleration is the toleration of runner pods, which is the same as
+// kubernetes pod's toleration except tolerationSeconds.
+type RunnerToleration struct {
+	// Key is the taint key. Empty with Exists operator matches all taints.
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+	// Operator is Equal or Exists. Empty means Equal.
+	Operator string `json:"operator,omitempty" yaml:"operator,omitempty"`
+	// Value is the taint value, which must be empty with Exists operator.
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+	// Effect is NoSchedule, PreferNoSchedule or NoExecute. Empty matches
+	// all effects.
+	Effect string `json:"effect,omitempty" yaml:"effect,omitempty"`
+}
+
+// Validate verifies fields of RunnerToleration.
+func (t RunnerToleration) Validate() error {
+	switch t.Operator {
+	case "", "Equal":
+		if t.Key == "" {
+			return fmt.Errorf("key is required by Equal operator")
+		}
+	case "Exists":
+		if t.Value != "" {
+			return fmt.Errorf("value must be empty with Exists operator: %q", t.Value)
+		}
+	default:
+		return fmt.Errorf("unsupported operator: %q", t.Operator)
+	}
+
+	switch t.Effect {
+	case "", "NoSchedule", "PreferNoSchedule", "NoExecute":
+	default:
+		return fmt.Errorf("unsupported effect: %q", t.Effect)
+	}
+	return nil
+}
+
+// ValidatePlacement verifies the fields which place runners into nodes,
+// including resources, node selector and tolerations.
+func (spec *RunnerGroupSpec) ValidatePlacement() error {
+	if spec.Resources != nil {
+		if err := spec.Resources.Validate(); err != nil {
+			return fmt.Errorf("resources: %w", err)
+		}
+	}
+	for key := range spec.NodeSelector {
+		if key == "" {
+			return fmt.Errorf("nodeSelector: key is required")
+		}
+	}
+	for i, t := range spec.Tolerations {
+		if err := t.Validate(); err != nil {
+			return fmt.Errorf("tolerations[%d]: %w", i, err)
+		}
+	}
+	return nil
 }
 
 // RunnerGroupStatus represents current state of RunnerGroup.
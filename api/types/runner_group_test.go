@@ -0,0 +1,62 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunnerGroupSpecValidatePlacement(t *testing.T) {
+	for name, tc := range map[string]struct {
+		spec RunnerGroupSpec
+		err  string
+	}{
+		"empty": {},
+		"valid": {
+			spec: RunnerGroupSpec{
+				Resources:    &RunnerResources{CPU: "500m", Memory: "4Gi"},
+				NodeSelector: map[string]string{"dedicated": "kperf"},
+				Tolerations: []RunnerToleration{
+					{Key: "dedicated", Value: "kperf", Effect: "NoSchedule"},
+					{Operator: "Exists"},
+				},
+			},
+		},
+		"invalid memory": {
+			spec: RunnerGroupSpec{Resources: &RunnerResources{Memory: "4GB"}},
+			err:  `resources: invalid memory quantity "4GB"`,
+		},
+		"empty node selector key": {
+			spec: RunnerGroupSpec{NodeSelector: map[string]string{"": "kperf"}},
+			err:  "nodeSelector: key is required",
+		},
+		"toleration without key": {
+			spec: RunnerGroupSpec{Tolerations: []RunnerToleration{{Value: "kperf"}}},
+			err:  "tolerations[0]: key is required by Equal operator",
+		},
+		"toleration with value and exists": {
+			spec: RunnerGroupSpec{Tolerations: []RunnerToleration{{Key: "a", Operator: "Exists", Value: "kperf"}}},
+			err:  "tolerations[0]: value must be empty",
+		},
+		"toleration with unknown operator": {
+			spec: RunnerGroupSpec{Tolerations: []RunnerToleration{{Key: "a", Operator: "In"}}},
+			err:  `tolerations[0]: unsupported operator: "In"`,
+		},
+		"toleration with unknown effect": {
+			spec: RunnerGroupSpec{Tolerations: []RunnerToleration{{Key: "a", Effect: "Never"}}},
+			err:  `tolerations[0]: unsupported effect: "Never"`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.spec.ValidatePlacement()
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -3,7 +3,15 @@
 
 package types
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
 
 // TimeSeriesConfig defines configuration for time-series execution mode.
 type TimeSeriesConfig struct {
@@ -11,8 +19,165 @@ type TimeSeriesConfig struct {
 	Interval string `json:"interval" yaml:"interval" mapstructure:"interval"`
 	// Buckets contains the time-bucketed requests.
 	Buckets []RequestBucket `json:"buckets" yaml:"buckets" mapstructure:"buckets"`
+	// Repeat defines how many times the bucket sequence is replayed again
+	// after the first replay. Zero means replaying only once.
+	Repeat int `json:"repeat,omitempty" yaml:"repeat,omitempty" mapstructure:"repeat"`
+	// TimeScale scales bucket's start time. For example, 0.5 replays twice
+	// as fast and 2 replays twice as slow. Zero means no scaling.
+	TimeScale float64 `json:"timeScale,omitempty" yaml:"timeScale,omitempty" mapstructure:"timeScale"`
+	// BucketOverlapMode defines how to dispatch buckets when dispatching
+	// one bucket takes longer than its interval. Empty means sequential.
+	BucketOverlapMode BucketOverlapMode `json:"bucketOverlapMode,omitempty" yaml:"bucketOverlapMode,omitempty" mapstructure:"bucketOverlapMode"`
+	// BucketConcurrency defines the maximum number of buckets dispatched at
+	// the same time in concurrent mode. Zero means no limit.
+	BucketConcurrency int `json:"bucketConcurrency,omitempty" yaml:"bucketConcurrency,omitempty" mapstructure:"bucketConcurrency"`
+	// BucketFilter selects the requests to replay. Nil means all.
+	BucketFilter *BucketFilterConfig `json:"bucketFilter,omitempty" yaml:"bucketFilter,omitempty" mapstructure:"bucketFilter"`
+	// Spread defines how a bucket's requests are dispatched over time.
+	// Empty means none.
+	Spread SpreadMode `json:"spread,omitempty" yaml:"spread,omitempty" mapstructure:"spread"`
+	// StartOffset selects the buckets starting at or after the offset in
+	// seconds, like 30m. The selected buckets are re-based so that the
+	// replay begins immediately. Zero means the beginning.
+	StartOffset Seconds `json:"startOffset,omitempty" yaml:"startOffset,omitempty" mapstructure:"startOffset"`
+	// EndOffset selects the buckets starting before the offset in seconds.
+	// Zero means the end.
+	EndOffset Seconds `json:"endOffset,omitempty" yaml:"endOffset,omitempty" mapstructure:"endOffset"`
+	// SortBuckets sorts buckets by start time before replay, like the
+	// buckets merged from multiple audit logs. Otherwise, the buckets must
+	// be sorted already.
+	SortBuckets bool `json:"sortBuckets,omitempty" yaml:"sortBuckets,omitempty" mapstructure:"sortBuckets"`
+	// MergeBucketsAtSameTime merges the requests of the buckets at the same
+	// start time into one bucket in order.
+	MergeBucketsAtSameTime bool `json:"mergeBucketsAtSameTime,omitempty" yaml:"mergeBucketsAtSameTime,omitempty" mapstructure:"mergeBucketsAtSameTime"`
+}
+
+// WindowBuckets returns the buckets in the window of StartOffset and
+// EndOffset, whose start time is re-based to StartOffset. It's Buckets if
+// there is no window.
+func (c *TimeSeriesConfig) WindowBuckets() []RequestBucket {
+	if c.StartOffset == 0 && c.EndOffset == 0 {
+		return c.Buckets
+	}
+
+	start := float64(c.StartOffset)
+	res := make([]RequestBucket, 0, len(c.Buckets))
+	for _, bucket := range c.Buckets {
+		if bucket.StartTime < start || (c.EndOffset > 0 && bucket.StartTime >= float64(c.EndOffset)) {
+			continue
+		}
+		res = append(res, RequestBucket{StartTime: bucket.StartTime - start, Requests: bucket.Requests})
+	}
+	return res
+}
+
+// PrepareBuckets sorts Buckets by start time if SortBuckets is set, and
+// then merges the adjacent buckets at the same start time if
+// MergeBucketsAtSameTime is set. The sort is stable so that the requests at
+// the same time keep their order.
+func (c *TimeSeriesConfig) PrepareBuckets() {
+	if c.SortBuckets {
+		sort.SliceStable(c.Buckets, func(i, j int) bool {
+			return c.Buckets[i].StartTime < c.Buckets[j].StartTime
+		})
+	}
+
+	if !c.MergeBucketsAtSameTime || len(c.Buckets) == 0 {
+		return
+	}
+	merged := make([]RequestBucket, 0, len(c.Buckets))
+	for _, bucket := range c.Buckets {
+		if last := len(merged) - 1; last >= 0 && merged[last].StartTime == bucket.StartTime {
+			merged[last].Requests = append(merged[last].Requests, bucket.Requests...)
+			continue
+		}
+		// NOTE: Requests is copied so that appending doesn't change the
+		// original bucket.
+		merged = append(merged, RequestBucket{
+			StartTime: bucket.StartTime,
+			Requests:  append([]ExactRequest(nil), bucket.Requests...),
+		})
+	}
+	c.Buckets = merged
+}
+
+// BucketFilterConfig selects requests of buckets by regular expressions.
+// Each pattern must match the whole field and empty pattern matches all.
+// A request is selected only if it matches all the patterns.
+type BucketFilterConfig struct {
+	// MethodRegexp matches request's method, like GET or LIST.
+	MethodRegexp string `json:"methodRegexp,omitempty" yaml:"methodRegexp,omitempty" mapstructure:"methodRegexp"`
+	// NamespaceRegexp matches request's namespace.
+	NamespaceRegexp string `json:"namespaceRegexp,omitempty" yaml:"namespaceRegexp,omitempty" mapstructure:"namespaceRegexp"`
+	// ResourceRegexp matches request's resource.
+	ResourceRegexp string `json:"resourceRegexp,omitempty" yaml:"resourceRegexp,omitempty" mapstructure:"resourceRegexp"`
+}
+
+// Compile returns the function which reports whether the request is
+// selected by filter.
+func (f *BucketFilterConfig) Compile() (func(*ExactRequest) bool, error) {
+	method, err := compileFullMatch("methodRegexp", f.MethodRegexp)
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := compileFullMatch("namespaceRegexp", f.NamespaceRegexp)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := compileFullMatch("resourceRegexp", f.ResourceRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(r *ExactRequest) bool {
+		return method.MatchString(r.Method) &&
+			namespace.MatchString(r.Namespace) &&
+			resource.MatchString(r.Resource)
+	}, nil
+}
+
+// compileFullMatch compiles the pattern which must match the whole string.
+// Empty pattern matches all.
+func compileFullMatch(name, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		pattern = ".*"
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", name, pattern, err)
+	}
+	return re, nil
 }
 
+// BucketOverlapMode defines how buckets are dispatched in time-series mode.
+type BucketOverlapMode string
+
+const (
+	// BucketOverlapModeSequential dispatches buckets one by one. The next
+	// bucket waits until the previous bucket is dispatched.
+	BucketOverlapModeSequential BucketOverlapMode = "sequential"
+	// BucketOverlapModeConcurrent dispatches each bucket in its own
+	// goroutine at its target time, regardless of previous buckets.
+	BucketOverlapModeConcurrent BucketOverlapMode = "concurrent"
+	// BucketOverlapModeBestEffort dispatches buckets one by one, but logs
+	// warning if the bucket's target time is already in the past.
+	BucketOverlapModeBestEffort BucketOverlapMode = "best-effort"
+)
+
+// SpreadMode defines how a bucket's requests are dispatched over time in
+// time-series mode.
+type SpreadMode string
+
+const (
+	// SpreadModeNone dispatches a bucket's requests back-to-back at its
+	// start time.
+	SpreadModeNone SpreadMode = "none"
+	// SpreadModeUniform spreads a bucket's requests evenly over the
+	// interval, or until the next bucket's start time if it's sooner, so
+	// that coarse buckets don't produce artificial micro-bursts.
+	SpreadModeUniform SpreadMode = "uniform"
+)
+
 // RequestBucket represents requests for one time slot.
 type RequestBucket struct {
 	// StartTime is the relative time in seconds from benchmark start.
@@ -47,6 +212,26 @@ type ExactRequest struct {
 	Limit int `json:"limit,omitempty" yaml:"limit,omitempty" mapstructure:"limit"`
 	// ResourceVersion for consistency.
 	ResourceVersion string `json:"resourceVersion,omitempty" yaml:"resourceVersion,omitempty" mapstructure:"resourceVersion"`
+	// MaxRetries overrides spec's MaxRetries for this request if it's set,
+	// including zero which disables retry.
+	MaxRetries *int `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty" mapstructure:"maxRetries"`
+}
+
+// ValidateMaxRetries verifies that MaxRetries isn't negative if it's set.
+func (r *ExactRequest) ValidateMaxRetries() error {
+	if r.MaxRetries != nil && *r.MaxRetries < 0 {
+		return fmt.Errorf("maxRetries(%v) requires >= 0", *r.MaxRetries)
+	}
+	return nil
+}
+
+// ValidatePatch verifies the patch type and body of PATCH request. It's
+// no-op for other methods.
+func (r *ExactRequest) ValidatePatch() error {
+	if r.Method != "PATCH" {
+		return nil
+	}
+	return ValidatePatchBody(r.PatchType, strings.TrimSpace(r.Body))
 }
 
 // Ensure TimeSeriesConfig implements ModeConfig
@@ -60,6 +245,16 @@ func (c *TimeSeriesConfig) GetOverridableFields() []OverridableField {
 			Type:        FieldTypeString,
 			Description: "Time bucket interval (e.g., '1s', '100ms')",
 		},
+		{
+			Name:        "start-offset",
+			Type:        FieldTypeDuration,
+			Description: "Replay the buckets starting at or after the offset in seconds or duration string like 30m",
+		},
+		{
+			Name:        "end-offset",
+			Type:        FieldTypeDuration,
+			Description: "Replay the buckets starting before the offset in seconds or duration string like 40m (0 means the end)",
+		},
 	}
 }
 
@@ -73,6 +268,18 @@ func (c *TimeSeriesConfig) ApplyOverrides(overrides map[string]interface{}) erro
 			} else {
 				return fmt.Errorf("interval must be string, got %T", value)
 			}
+		case "start-offset":
+			v, err := secondsFromOverride(value)
+			if err != nil {
+				return fmt.Errorf("start-offset: %w", err)
+			}
+			c.StartOffset = v
+		case "end-offset":
+			v, err := secondsFromOverride(value)
+			if err != nil {
+				return fmt.Errorf("end-offset: %w", err)
+			}
+			c.EndOffset = v
 		default:
 			return fmt.Errorf("unknown override key for time-series mode: %s", key)
 		}
@@ -84,6 +291,90 @@ func (c *TimeSeriesConfig) ApplyOverrides(overrides map[string]interface{}) erro
 func (c *TimeSeriesConfig) Validate(defaultOverrides map[string]interface{}) error {
 	// Time-series mode doesn't have conflicting settings or defaults
 	// Could add validation for interval format, bucket ordering, etc.
+	if c.Repeat < 0 {
+		return fmt.Errorf("repeat requires >= 0: %v", c.Repeat)
+	}
+	if c.TimeScale < 0 {
+		return fmt.Errorf("timeScale requires >= 0: %v", c.TimeScale)
+	}
+	switch c.BucketOverlapMode {
+	case "", BucketOverlapModeSequential, BucketOverlapModeConcurrent, BucketOverlapModeBestEffort:
+	default:
+		return fmt.Errorf("unsupported bucketOverlapMode: %s", c.BucketOverlapMode)
+	}
+	switch c.Spread {
+	case "", SpreadModeNone, SpreadModeUniform:
+	default:
+		return fmt.Errorf("unsupported spread: %s", c.Spread)
+	}
+	if c.BucketConcurrency < 0 {
+		return fmt.Errorf("bucketConcurrency requires >= 0: %v", c.BucketConcurrency)
+	}
+	if c.BucketFilter != nil {
+		if _, err := c.BucketFilter.Compile(); err != nil {
+			return fmt.Errorf("bucketFilter: %w", err)
+		}
+	}
+	if err := c.validateWindow(); err != nil {
+		return err
+	}
+	if !c.SortBuckets {
+		for i := 1; i < len(c.Buckets); i++ {
+			if c.Buckets[i].StartTime < c.Buckets[i-1].StartTime {
+				return fmt.Errorf("bucket %d's startTime(%v) is before bucket %d's(%v), set sortBuckets to sort them",
+					i, c.Buckets[i].StartTime, i-1, c.Buckets[i-1].StartTime)
+			}
+		}
+	}
+	for i := range c.Buckets {
+		for j := range c.Buckets[i].Requests {
+			if err := c.Buckets[i].Requests[j].ValidateMaxRetries(); err != nil {
+				return fmt.Errorf("bucket %d request %d: %w", i, j, err)
+			}
+			if err := c.Buckets[i].Requests[j].ValidatePatch(); err != nil {
+				return fmt.Errorf("bucket %d request %d: %w", i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateWindow verifies that the window of StartOffset and EndOffset
+// selects at least one bucket. It warns if the window's edge is in the
+// middle of a bucket, whose requests are either all replayed or dropped.
+func (c *TimeSeriesConfig) validateWindow() error {
+	if c.StartOffset < 0 {
+		return fmt.Errorf("startOffset requires >= 0: %v", c.StartOffset)
+	}
+	if c.EndOffset < 0 {
+		return fmt.Errorf("endOffset requires >= 0: %v", c.EndOffset)
+	}
+	if c.StartOffset == 0 && c.EndOffset == 0 {
+		return nil
+	}
+	if c.EndOffset > 0 && c.EndOffset <= c.StartOffset {
+		return fmt.Errorf("endOffset(%v) requires > startOffset(%v)", c.EndOffset, c.StartOffset)
+	}
+	if len(c.WindowBuckets()) == 0 {
+		return fmt.Errorf("window [%v, %v) of startOffset and endOffset selects no bucket", c.StartOffset, c.EndOffset)
+	}
+
+	interval, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid interval: %v", err)
+	}
+	for _, edge := range []Seconds{c.StartOffset, c.EndOffset} {
+		if edge == 0 {
+			continue
+		}
+		for _, bucket := range c.Buckets {
+			bucketStart := time.Duration(bucket.StartTime * float64(time.Second))
+			if bucketStart < edge.Duration() && edge.Duration() < bucketStart+interval {
+				klog.Warningf("Offset %v clips bucket at %vs of interval %v", edge.Duration(), bucket.StartTime, interval)
+				break
+			}
+		}
+	}
 	return nil
 }
 
@@ -95,3 +386,27 @@ func (c *TimeSeriesConfig) ConfigureClientOptions() ClientOptions {
 		QPS: 0, // No limit
 	}
 }
+
+// TimeSeriesReport is the result of time-series mode.
+type TimeSeriesReport struct {
+	// Buckets are the dispatch lags of dispatched buckets in order of
+	// round and start time.
+	Buckets []BucketDispatchLag `json:"buckets"`
+	// MaxLagSeconds is the largest lag in seconds of all the requests.
+	MaxLagSeconds float64 `json:"maxLagSeconds"`
+}
+
+// BucketDispatchLag is how late a bucket's requests are sent compared to
+// its scheduled time, which quantifies the fidelity of replay.
+type BucketDispatchLag struct {
+	// Round is the index of replay, which is from zero to Repeat.
+	Round int `json:"round"`
+	// StartTime is the bucket's start time in seconds before scaling.
+	StartTime float64 `json:"startTime"`
+	// Requests is the number of sent requests.
+	Requests int `json:"requests"`
+	// FirstLagSeconds is the lag in seconds of the first sent request.
+	FirstLagSeconds float64 `json:"firstLagSeconds"`
+	// LastLagSeconds is the lag in seconds of the last sent request.
+	LastLagSeconds float64 `json:"lastLagSeconds"`
+}
@@ -15,10 +15,14 @@ func TestTimeSeriesConfigGetOverridableFields(t *testing.T) {
 	config := &TimeSeriesConfig{}
 	fields := config.GetOverridableFields()
 
-	assert.Len(t, fields, 1)
+	assert.Len(t, fields, 3)
 	assert.Equal(t, "interval", fields[0].Name)
 	assert.Equal(t, FieldTypeString, fields[0].Type)
 	assert.Contains(t, fields[0].Description, "Time bucket")
+	assert.Equal(t, "start-offset", fields[1].Name)
+	assert.Equal(t, FieldTypeDuration, fields[1].Type)
+	assert.Equal(t, "end-offset", fields[2].Name)
+	assert.Equal(t, FieldTypeDuration, fields[2].Type)
 }
 
 func TestTimeSeriesConfigApplyOverrides(t *testing.T) {
@@ -44,6 +48,22 @@ func TestTimeSeriesConfigApplyOverrides(t *testing.T) {
 			expected: TimeSeriesConfig{Interval: "1s"},
 			err:      true,
 		},
+		"offsets override": {
+			initial: TimeSeriesConfig{Interval: "1s"},
+			overrides: map[string]interface{}{
+				"start-offset": "30m",
+				"end-offset":   "2400",
+			},
+			expected: TimeSeriesConfig{Interval: "1s", StartOffset: 1800, EndOffset: 2400},
+		},
+		"invalid offset": {
+			initial: TimeSeriesConfig{Interval: "1s"},
+			overrides: map[string]interface{}{
+				"start-offset": "abc",
+			},
+			expected: TimeSeriesConfig{Interval: "1s"},
+			err:      true,
+		},
 		"unknown key": {
 			initial: TimeSeriesConfig{Interval: "1s"},
 			overrides: map[string]interface{}{
@@ -62,7 +82,7 @@ func TestTimeSeriesConfigApplyOverrides(t *testing.T) {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tc.expected.Interval, config.Interval)
+				assert.Equal(t, tc.expected, config)
 			}
 		})
 	}
@@ -72,6 +92,136 @@ func TestTimeSeriesConfigValidate(t *testing.T) {
 	config := &TimeSeriesConfig{Interval: "1s"}
 	err := config.Validate(nil)
 	assert.NoError(t, err)
+
+	config = &TimeSeriesConfig{Interval: "1s", Repeat: 2, TimeScale: 0.5}
+	assert.NoError(t, config.Validate(nil))
+
+	config = &TimeSeriesConfig{Interval: "1s", Repeat: -1}
+	assert.Error(t, config.Validate(nil))
+
+	config = &TimeSeriesConfig{Interval: "1s", TimeScale: -1}
+	assert.Error(t, config.Validate(nil))
+
+	config = &TimeSeriesConfig{Interval: "1s", BucketOverlapMode: BucketOverlapModeConcurrent, BucketConcurrency: 4}
+	assert.NoError(t, config.Validate(nil))
+
+	config = &TimeSeriesConfig{Interval: "1s", BucketOverlapMode: "unknown"}
+	assert.Error(t, config.Validate(nil))
+
+	config = &TimeSeriesConfig{Interval: "1s", BucketConcurrency: -1}
+	assert.Error(t, config.Validate(nil))
+
+	config = &TimeSeriesConfig{Interval: "1s", Spread: SpreadModeUniform}
+	assert.NoError(t, config.Validate(nil))
+
+	config = &TimeSeriesConfig{Interval: "1s", Spread: "unknown"}
+	assert.Error(t, config.Validate(nil))
+
+	zero, negative := 0, -1
+	config = &TimeSeriesConfig{Interval: "1s", Buckets: []RequestBucket{
+		{Requests: []ExactRequest{{Method: "GET", MaxRetries: &zero}}},
+	}}
+	assert.NoError(t, config.Validate(nil))
+
+	config.Buckets[0].Requests = append(config.Buckets[0].Requests, ExactRequest{Method: "GET", MaxRetries: &negative})
+	assert.Error(t, config.Validate(nil))
+
+	config = &TimeSeriesConfig{Interval: "1s", Buckets: []RequestBucket{
+		{Requests: []ExactRequest{{Method: "PATCH", PatchType: "merge", Body: `{"data":{"k":"v"}}`}}},
+		{Requests: []ExactRequest{
+			{Method: "PATCH", PatchType: "json", Body: `[{"op":"replace","path":"/data/k","value":"v"}]`},
+			{Method: "PATCH", PatchType: "json", Body: `{"data":{"k":"v"}}`},
+		}},
+	}}
+	err = config.Validate(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bucket 1 request 1")
+}
+
+func TestTimeSeriesConfigWindow(t *testing.T) {
+	buckets := []RequestBucket{
+		{StartTime: 0, Requests: []ExactRequest{{Method: "GET"}}},
+		{StartTime: 1800, Requests: []ExactRequest{{Method: "LIST"}}},
+		{StartTime: 2100, Requests: []ExactRequest{{Method: "POST"}}},
+		{StartTime: 2400, Requests: []ExactRequest{{Method: "PUT"}}},
+	}
+
+	config := &TimeSeriesConfig{Interval: "60s", Buckets: buckets}
+	assert.Equal(t, buckets, config.WindowBuckets())
+
+	config.StartOffset, config.EndOffset = 1800, 2400
+	require.NoError(t, config.Validate(nil))
+	assert.Equal(t, []RequestBucket{
+		{StartTime: 0, Requests: []ExactRequest{{Method: "LIST"}}},
+		{StartTime: 300, Requests: []ExactRequest{{Method: "POST"}}},
+	}, config.WindowBuckets())
+
+	// Zero end offset means the end.
+	config.EndOffset = 0
+	require.NoError(t, config.Validate(nil))
+	assert.Len(t, config.WindowBuckets(), 3)
+
+	// The window clipping a bucket is still valid.
+	config.StartOffset = 1830
+	require.NoError(t, config.Validate(nil))
+	assert.Len(t, config.WindowBuckets(), 2)
+
+	for name, window := range map[string][2]Seconds{
+		"no bucket":           {2401, 0},
+		"end before start":    {2400, 1800},
+		"negative start":      {-1, 0},
+		"negative end":        {0, -1},
+		"no bucket in middle": {1, 1800},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := &TimeSeriesConfig{Interval: "60s", Buckets: buckets, StartOffset: window[0], EndOffset: window[1]}
+			assert.Error(t, config.Validate(nil))
+		})
+	}
+}
+
+func TestTimeSeriesConfigPrepareBuckets(t *testing.T) {
+	newBuckets := func() []RequestBucket {
+		return []RequestBucket{
+			{StartTime: 2, Requests: []ExactRequest{{Method: "GET"}}},
+			{StartTime: 0, Requests: []ExactRequest{{Method: "LIST"}}},
+			{StartTime: 2, Requests: []ExactRequest{{Method: "POST"}}},
+			{StartTime: 1, Requests: []ExactRequest{{Method: "PUT"}}},
+		}
+	}
+
+	// The unsorted buckets are rejected unless they are sorted.
+	config := &TimeSeriesConfig{Interval: "1s", Buckets: newBuckets()}
+	assert.ErrorContains(t, config.Validate(nil), "bucket 1's startTime(0) is before bucket 0's(2)")
+
+	config.SortBuckets = true
+	require.NoError(t, config.Validate(nil))
+	config.PrepareBuckets()
+	assert.Equal(t, []RequestBucket{
+		{StartTime: 0, Requests: []ExactRequest{{Method: "LIST"}}},
+		{StartTime: 1, Requests: []ExactRequest{{Method: "PUT"}}},
+		{StartTime: 2, Requests: []ExactRequest{{Method: "GET"}}},
+		{StartTime: 2, Requests: []ExactRequest{{Method: "POST"}}},
+	}, config.Buckets)
+
+	// The buckets at the same time are merged in order.
+	original := newBuckets()
+	config = &TimeSeriesConfig{Interval: "1s", Buckets: original, SortBuckets: true, MergeBucketsAtSameTime: true}
+	config.PrepareBuckets()
+	assert.Equal(t, []RequestBucket{
+		{StartTime: 0, Requests: []ExactRequest{{Method: "LIST"}}},
+		{StartTime: 1, Requests: []ExactRequest{{Method: "PUT"}}},
+		{StartTime: 2, Requests: []ExactRequest{{Method: "GET"}, {Method: "POST"}}},
+	}, config.Buckets)
+	assert.Len(t, original[0].Requests, 1)
+
+	// It's idempotent.
+	config.PrepareBuckets()
+	assert.Len(t, config.Buckets, 3)
+
+	// The sorted buckets at the same time are valid without sorting.
+	config = &TimeSeriesConfig{Interval: "1s", Buckets: config.Buckets[2:], MergeBucketsAtSameTime: true}
+	require.NoError(t, config.Validate(nil))
 }
 
 func TestTimeSeriesConfigConfigureClientOptions(t *testing.T) {
@@ -91,6 +241,9 @@ spec:
   mode: time-series
   modeConfig:
     interval: "1s"
+    repeat: 2
+    timeScale: 0.5
+    bucketOverlapMode: best-effort
     buckets:
     - startTime: 0.0
       requests:
@@ -126,6 +279,9 @@ spec:
 	require.NotNil(t, tsConfig)
 
 	assert.Equal(t, "1s", tsConfig.Interval)
+	assert.Equal(t, 2, tsConfig.Repeat)
+	assert.Equal(t, 0.5, tsConfig.TimeScale)
+	assert.Equal(t, BucketOverlapModeBestEffort, tsConfig.BucketOverlapMode)
 	assert.Len(t, tsConfig.Buckets, 2)
 
 	assert.Equal(t, 0.0, tsConfig.Buckets[0].StartTime)
@@ -149,3 +305,40 @@ spec:
 
 	assert.NoError(t, target.Validate())
 }
+
+func TestBucketFilterConfig(t *testing.T) {
+	tests := map[string]struct {
+		filter   BucketFilterConfig
+		req      ExactRequest
+		selected bool
+	}{
+		"empty matches all": {
+			req:      ExactRequest{Method: "LIST", Resource: "pods"},
+			selected: true,
+		},
+		"method": {
+			filter:   BucketFilterConfig{MethodRegexp: "GET|LIST"},
+			req:      ExactRequest{Method: "LIST", Resource: "pods"},
+			selected: true,
+		},
+		"whole namespace": {
+			filter: BucketFilterConfig{NamespaceRegexp: "kube-system"},
+			req:    ExactRequest{Method: "GET", Namespace: "kube-system-x"},
+		},
+		"all patterns": {
+			filter: BucketFilterConfig{MethodRegexp: "GET", ResourceRegexp: "pods"},
+			req:    ExactRequest{Method: "GET", Resource: "configmaps"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			selected, err := tc.filter.Compile()
+			require.NoError(t, err)
+			assert.Equal(t, tc.selected, selected(&tc.req))
+		})
+	}
+
+	config := &TimeSeriesConfig{BucketFilter: &BucketFilterConfig{ResourceRegexp: "("}}
+	assert.ErrorContains(t, config.Validate(nil), "invalid resourceRegexp")
+}
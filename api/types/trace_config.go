@@ -0,0 +1,111 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import "fmt"
+
+// TraceConfig defines configuration for trace execution mode. It replays
+// requests from a file with per-request relative timestamps, like the one
+// exported from L7 proxy, without bucketing.
+type TraceConfig struct {
+	// File is the path to the trace file. It's streamed so that it can be
+	// larger than memory.
+	File string `json:"file" yaml:"file" mapstructure:"file"`
+	// Format is the format of the trace file. Empty means it's detected by
+	// file extension: .jsonl and .ndjson are jsonl, others are csv.
+	Format TraceFormat `json:"format,omitempty" yaml:"format,omitempty" mapstructure:"format"`
+	// TimeScale scales request's offset. For example, 0.5 replays twice as
+	// fast and 2 replays twice as slow. Zero means no scaling.
+	TimeScale float64 `json:"timeScale,omitempty" yaml:"timeScale,omitempty" mapstructure:"timeScale"`
+}
+
+// TraceFormat is the format of trace file.
+type TraceFormat string
+
+const (
+	// TraceFormatCSV is CSV with offset, verb and path columns. If the first
+	// row is a header, the columns are identified by names and GVR columns,
+	// like version, resource and namespace, can be used instead of path.
+	TraceFormatCSV TraceFormat = "csv"
+	// TraceFormatJSONL is one TraceRecord in JSON per line.
+	TraceFormatJSONL TraceFormat = "jsonl"
+)
+
+// TraceRecord is one request in trace file.
+type TraceRecord struct {
+	// Offset is the relative time in seconds from benchmark start.
+	Offset float64 `json:"offset"`
+	// Path is the full request URI, like /api/v1/namespaces/default/pods?limit=10.
+	// If it's set, it overrides GVR fields of ExactRequest.
+	Path string `json:"path,omitempty"`
+
+	ExactRequest
+}
+
+// Ensure TraceConfig implements ModeConfig
+func (*TraceConfig) isModeConfig() {}
+
+// GetOverridableFields implements ModeConfig for TraceConfig
+func (c *TraceConfig) GetOverridableFields() []OverridableField {
+	return []OverridableField{
+		{
+			Name:        "file",
+			Type:        FieldTypeString,
+			Description: "Path to the trace file",
+		},
+	}
+}
+
+// ApplyOverrides implements ModeConfig for TraceConfig
+func (c *TraceConfig) ApplyOverrides(overrides map[string]interface{}) error {
+	for key, value := range overrides {
+		switch key {
+		case "file":
+			if v, ok := value.(string); ok {
+				c.File = v
+			} else {
+				return fmt.Errorf("file must be string, got %T", value)
+			}
+		default:
+			return fmt.Errorf("unknown override key for trace mode: %s", key)
+		}
+	}
+	return nil
+}
+
+// Validate implements ModeConfig for TraceConfig
+func (c *TraceConfig) Validate(_ map[string]interface{}) error {
+	if c.File == "" {
+		return fmt.Errorf("file is required")
+	}
+	switch c.Format {
+	case "", TraceFormatCSV, TraceFormatJSONL:
+	default:
+		return fmt.Errorf("unsupported format: %s", c.Format)
+	}
+	if c.TimeScale < 0 {
+		return fmt.Errorf("timeScale requires >= 0: %v", c.TimeScale)
+	}
+	return nil
+}
+
+// ConfigureClientOptions implements ModeConfig for TraceConfig
+func (c *TraceConfig) ConfigureClientOptions() ClientOptions {
+	// The rate is controlled by request's offset.
+	return ClientOptions{
+		QPS: 0, // No limit
+	}
+}
+
+// TraceReport is the result of trace mode.
+type TraceReport struct {
+	// Requests is the number of dispatched requests.
+	Requests int64 `json:"requests"`
+	// Late is the number of requests dispatched after their scheduled
+	// time because the replay fell behind.
+	Late int64 `json:"late"`
+	// Skipped is the number of requests which aren't supported, like
+	// unknown verbs.
+	Skipped int64 `json:"skipped"`
+}
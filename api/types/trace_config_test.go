@@ -0,0 +1,58 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestTraceConfigUnmarshalAndValidate(t *testing.T) {
+	in := `
+conns: 1
+client: 1
+contentType: json
+mode: trace
+modeConfig:
+  file: /tmp/trace.csv
+  timeScale: 0.5
+`
+	var spec LoadProfileSpec
+	require.NoError(t, yaml.Unmarshal([]byte(in), &spec))
+	require.Equal(t, ModeTrace, spec.Mode)
+
+	config, ok := spec.ModeConfig.(*TraceConfig)
+	require.True(t, ok)
+	require.NoError(t, config.Validate(nil))
+	require.NoError(t, spec.Validate())
+
+	assert.Equal(t, "/tmp/trace.csv", config.File)
+	assert.Equal(t, 0.5, config.TimeScale)
+
+	require.NoError(t, config.ApplyOverrides(map[string]interface{}{"file": "/tmp/trace.jsonl"}))
+	assert.Equal(t, "/tmp/trace.jsonl", config.File)
+	assert.Error(t, config.ApplyOverrides(map[string]interface{}{"rate": float64(1)}))
+
+	for name, c := range map[string]TraceConfig{
+		"no file":            {},
+		"unknown format":     {File: "a", Format: "xml"},
+		"negative timeScale": {File: "a", TimeScale: -1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, c.Validate(nil))
+		})
+	}
+
+	var record TraceRecord
+	require.NoError(t, json.Unmarshal([]byte(`{"offset":1.5,"method":"GET","path":"/api/v1/pods","limit":10}`), &record))
+	assert.Equal(t, TraceRecord{
+		Offset:       1.5,
+		Path:         "/api/v1/pods",
+		ExactRequest: ExactRequest{Method: "GET", Limit: 10},
+	}, record)
+}
@@ -0,0 +1,91 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ExpandVariables returns a copy of profile whose ${VAR} occurrences in all
+// the string fields of Spec are replaced with the variable's value. The vars
+// take precedence over profile's Variables. Unknown variables are kept as
+// they are. The given profile isn't changed.
+func ExpandVariables(profile *LoadProfile, vars map[string]string) *LoadProfile {
+	merged := make(map[string]string, len(profile.Variables)+len(vars))
+	for k, v := range profile.Variables {
+		merged[k] = v
+	}
+	for k, v := range vars {
+		merged[k] = v
+	}
+
+	oldnew := make([]string, 0, 2*len(merged))
+	for k, v := range merged {
+		oldnew = append(oldnew, "${"+k+"}", v)
+	}
+	replacer := strings.NewReplacer(oldnew...)
+
+	res := *profile
+	if len(merged) > 0 {
+		res.Variables = merged
+	}
+	res.Spec = expandValue(reflect.ValueOf(profile.Spec), replacer).Interface().(LoadProfileSpec)
+	return &res
+}
+
+// expandValue returns a deep copy of v with replaced strings. The unexported
+// fields are copied shallowly.
+func expandValue(v reflect.Value, replacer *strings.Replacer) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		res := reflect.New(v.Type()).Elem()
+		res.SetString(replacer.Replace(v.String()))
+		return res
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Type().Elem())
+		res.Elem().Set(expandValue(v.Elem(), replacer))
+		return res
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.Set(expandValue(v.Elem(), replacer))
+		return res
+	case reflect.Struct:
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := res.Field(i); field.CanSet() {
+				field.Set(expandValue(v.Field(i), replacer))
+			}
+		}
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(expandValue(v.Index(i), replacer))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			res.SetMapIndex(iter.Key(), expandValue(iter.Value(), replacer))
+		}
+		return res
+	default:
+		return v
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestExpandVariables(t *testing.T) {
+	in := []byte(`
+version: 1
+variables:
+  NS: kperf
+  APP: web
+spec:
+  conns: 1
+  mode: weighted-random
+  modeConfig:
+    rate: 10
+    requests:
+    - shares: 1
+      get:
+        version: v1
+        resource: pods
+        namespace: ${NS}
+        name: ${APP}-0
+    - shares: 1
+      list:
+        version: v1
+        resource: pods
+        selector: app=${APP},tier=${TIER}
+    - shares: 1
+      put:
+        version: v1
+        resource: configmaps
+        namespace: ${NS}
+        name: cm
+        keySpaceSize: 1
+        valueSize: 1
+    - shares: 1
+      patch:
+        version: v1
+        resource: pods
+        namespace: ${NS}
+        name: ${APP}-0
+        patchType: merge
+        body: '{"metadata":{"labels":{"app":"${APP}"}}}'
+`)
+
+	var profile LoadProfile
+	require.NoError(t, yaml.Unmarshal(in, &profile))
+
+	expanded := ExpandVariables(&profile, map[string]string{"APP": "api"})
+	assert.Equal(t, map[string]string{"NS": "kperf", "APP": "api"}, expanded.Variables)
+
+	reqs := expanded.Spec.ModeConfig.(*WeightedRandomConfig).Requests
+	assert.Equal(t, "kperf", reqs[0].Get.Namespace)
+	assert.Equal(t, "api-0", reqs[0].Get.Name)
+	assert.Equal(t, "app=api,tier=${TIER}", reqs[1].List.Selector, "unknown variable is kept")
+	assert.Equal(t, "kperf", reqs[2].Put.Namespace)
+	assert.Equal(t, `{"metadata":{"labels":{"app":"api"}}}`, reqs[3].Patch.Body)
+	assert.Equal(t, float64(10), expanded.Spec.ModeConfig.(*WeightedRandomConfig).Rate)
+
+	// The original profile isn't changed.
+	origin := profile.Spec.ModeConfig.(*WeightedRandomConfig).Requests
+	assert.Equal(t, "${APP}-0", origin[0].Get.Name)
+	assert.Equal(t, "${NS}", origin[2].Put.Namespace)
+	assert.Equal(t, map[string]string{"NS": "kperf", "APP": "web"}, profile.Variables)
+}
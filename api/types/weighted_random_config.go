@@ -3,20 +3,58 @@
 
 package types
 
-import "fmt"
+import (
+	"fmt"
+
+	"k8s.io/klog/v2"
+)
 
 // WeightedRandomConfig defines configuration for weighted-random execution mode.
 type WeightedRandomConfig struct {
 	// Rate defines the maximum requests per second (zero is no limit).
 	Rate float64 `json:"rate" yaml:"rate" mapstructure:"rate"`
+	// Burst defines the burst of each client's token bucket rate limiter,
+	// which is different from the executor's rate limiter (zero means
+	// client-go's default).
+	Burst int `json:"burst,omitempty" yaml:"burst,omitempty" mapstructure:"burst"`
 	// Total defines the total number of requests.
 	Total int `json:"total" yaml:"total" mapstructure:"total"`
 	// Duration defines the running time in seconds.
-	Duration int `json:"duration" yaml:"duration" mapstructure:"duration"`
+	Duration Seconds `json:"duration" yaml:"duration" mapstructure:"duration"`
 	// Requests defines the different kinds of requests with weights.
 	Requests []*WeightedRequest `json:"requests" yaml:"requests" mapstructure:"requests"`
+	// MaxRetryPicks defines how many times to pick again if the picked
+	// request's condition is not met. Zero means DefaultMaxRetryPicks.
+	MaxRetryPicks int `json:"maxRetryPicks,omitempty" yaml:"maxRetryPicks,omitempty" mapstructure:"maxRetryPicks"`
+	// Distribution defines how requests are picked. Empty means random.
+	Distribution Distribution `json:"distribution,omitempty" yaml:"distribution,omitempty" mapstructure:"distribution"`
+	// Seed makes the picks reproducible, which means the same sequence of
+	// picks for the same seed and requests. In deterministic distribution,
+	// zero means a random seed. In random distribution, zero means picking
+	// by crypto/rand. The seeded picks aren't cryptographically secure.
+	Seed int64 `json:"seed,omitempty" yaml:"seed,omitempty" mapstructure:"seed"`
+	// MaxInFlight caps the number of requests which are sent but not done
+	// yet, so that backed-up workers don't overwhelm slow apiserver. Zero
+	// means no limit.
+	MaxInFlight int `json:"maxInFlight,omitempty" yaml:"maxInFlight,omitempty" mapstructure:"maxInFlight"`
 }
 
+// DefaultMaxRetryPicks is the default value of WeightedRandomConfig.MaxRetryPicks.
+const DefaultMaxRetryPicks = 10
+
+// Distribution defines how weighted-random mode picks requests.
+type Distribution string
+
+const (
+	// DistributionRandom picks each request randomly by shares, so the
+	// realized mix can be skewed for small total.
+	DistributionRandom Distribution = "random"
+	// DistributionDeterministic apportions total by shares with largest
+	// remainder method and shuffles them by seed, so the realized mix
+	// matches shares for any total.
+	DistributionDeterministic Distribution = "deterministic"
+)
+
 // Ensure WeightedRandomConfig implements ModeConfig
 func (*WeightedRandomConfig) isModeConfig() {}
 
@@ -35,8 +73,13 @@ func (c *WeightedRandomConfig) GetOverridableFields() []OverridableField {
 		},
 		{
 			Name:        "duration",
+			Type:        FieldTypeDuration,
+			Description: "Duration in seconds or duration string like 90s (ignored if total is set)",
+		},
+		{
+			Name:        "seed",
 			Type:        FieldTypeInt,
-			Description: "Duration in seconds (ignored if total is set)",
+			Description: "Seed for reproducible picks of requests (0 means random)",
 		},
 	}
 }
@@ -58,10 +101,19 @@ func (c *WeightedRandomConfig) ApplyOverrides(overrides map[string]interface{})
 				return fmt.Errorf("total must be int, got %T", value)
 			}
 		case "duration":
-			if v, ok := value.(int); ok {
-				c.Duration = v
-			} else {
-				return fmt.Errorf("duration must be int, got %T", value)
+			v, err := secondsFromOverride(value)
+			if err != nil {
+				return fmt.Errorf("duration: %w", err)
+			}
+			c.Duration = v
+		case "seed":
+			switch v := value.(type) {
+			case int:
+				c.Seed = int64(v)
+			case int64:
+				c.Seed = v
+			default:
+				return fmt.Errorf("seed must be int, got %T", value)
 			}
 		default:
 			return fmt.Errorf("unknown override key for weighted-random mode: %s", key)
@@ -72,6 +124,18 @@ func (c *WeightedRandomConfig) ApplyOverrides(overrides map[string]interface{})
 
 // Validate implements ModeConfig for WeightedRandomConfig
 func (c *WeightedRandomConfig) Validate(defaultOverrides map[string]interface{}) error {
+	if c.MaxRetryPicks < 0 {
+		return fmt.Errorf("maxRetryPicks requires >= 0: %v", c.MaxRetryPicks)
+	}
+
+	if c.Burst < 0 {
+		return fmt.Errorf("burst requires >= 0: %v", c.Burst)
+	}
+
+	if c.MaxInFlight < 0 {
+		return fmt.Errorf("maxInFlight requires >= 0: %v", c.MaxInFlight)
+	}
+
 	// Check for conflicting Total and Duration settings
 	if c.Total > 0 && c.Duration > 0 {
 		// Both set - Duration is ignored
@@ -85,12 +149,89 @@ func (c *WeightedRandomConfig) Validate(defaultOverrides map[string]interface{})
 		}
 	}
 
+	if err := validateWeightedRequests(c.Requests); err != nil {
+		return err
+	}
+
+	switch c.Distribution {
+	case "", DistributionRandom:
+	case DistributionDeterministic:
+		if c.Total <= 0 {
+			return fmt.Errorf("deterministic distribution requires total > 0")
+		}
+	default:
+		return fmt.Errorf("unsupported distribution: %s", c.Distribution)
+	}
+
 	return nil
 }
 
+// validateWeightedRequests verifies each request and rejects duplicate
+// requests, which should be merged into one with the sum of shares. It warns
+// if all the requests have the same shares, which is likely that weights are
+// not set.
+func validateWeightedRequests(requests []*WeightedRequest) error {
+	for i, r := range requests {
+		if r == nil {
+			return fmt.Errorf("request[%d]: null", i)
+		}
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("request[%d]: %w", i, err)
+		}
+		for j := 0; j < i; j++ {
+			if r.Equal(*requests[j]) {
+				return fmt.Errorf("request[%d]: duplicate of request[%d]", i, j)
+			}
+		}
+	}
+
+	if len(requests) > 1 && sameShares(requests) {
+		klog.Warningf("All %d requests have the same shares %d, which is even distribution. Set shares if it's unexpected",
+			len(requests), requests[0].Shares)
+	}
+	return nil
+}
+
+// sameShares returns true if all the requests have the same shares.
+func sameShares(requests []*WeightedRequest) bool {
+	for _, r := range requests[1:] {
+		if r.Shares != requests[0].Shares {
+			return false
+		}
+	}
+	return true
+}
+
 // ConfigureClientOptions implements ModeConfig for WeightedRandomConfig
 func (c *WeightedRandomConfig) ConfigureClientOptions() ClientOptions {
 	return ClientOptions{
-		QPS: c.Rate,
+		QPS:   c.Rate,
+		Burst: c.Burst,
 	}
 }
+
+// WeightedRandomReport is the result of weighted-random mode.
+type WeightedRandomReport struct {
+	// Distribution is how requests were picked.
+	Distribution Distribution `json:"distribution"`
+	// Seed is the seed used by deterministic distribution.
+	Seed int64 `json:"seed,omitempty"`
+	// Requests are the realized counts of each request in config's order.
+	Requests []WeightedRequestCount `json:"requests"`
+}
+
+// WeightedRequestCount is the realized count of one WeightedRequest.
+type WeightedRequestCount struct {
+	// Type is the request type, like staleList.
+	Type string `json:"type"`
+	// Shares is the weight of the request.
+	Shares int `json:"shares"`
+	// Count is the number of dispatched requests.
+	Count int64 `json:"count"`
+	// CapSkipped is the number of times the request was skipped because
+	// of maxConcurrency.
+	CapSkipped int64 `json:"capSkipped,omitempty"`
+	// KeySpaceShard is the key space shard, in {offset}/{partitions}
+	// format, used by the request if any.
+	KeySpaceShard string `json:"keySpaceShard,omitempty"`
+}
@@ -4,18 +4,22 @@
 package types
 
 import (
+	"bytes"
+	"flag"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
+	"k8s.io/klog/v2"
 )
 
 func TestWeightedRandomConfigGetOverridableFields(t *testing.T) {
 	config := &WeightedRandomConfig{}
 	fields := config.GetOverridableFields()
 
-	assert.Len(t, fields, 3)
+	assert.Len(t, fields, 4)
 
 	fieldMap := make(map[string]OverridableField)
 	for _, f := range fields {
@@ -28,8 +32,11 @@ func TestWeightedRandomConfigGetOverridableFields(t *testing.T) {
 	assert.Equal(t, FieldTypeInt, fieldMap["total"].Type)
 	assert.Contains(t, fieldMap["total"].Description, "Total number")
 
-	assert.Equal(t, FieldTypeInt, fieldMap["duration"].Type)
+	assert.Equal(t, FieldTypeDuration, fieldMap["duration"].Type)
 	assert.Contains(t, fieldMap["duration"].Description, "Duration")
+
+	assert.Equal(t, FieldTypeInt, fieldMap["seed"].Type)
+	assert.Contains(t, fieldMap["seed"].Description, "Seed")
 }
 
 func TestWeightedRandomConfigApplyOverrides(t *testing.T) {
@@ -47,6 +54,14 @@ func TestWeightedRandomConfigApplyOverrides(t *testing.T) {
 			expected: WeightedRandomConfig{Rate: 200, Total: 1000},
 			err:      false,
 		},
+		"seed override": {
+			initial: WeightedRandomConfig{Rate: 100, Total: 1000},
+			overrides: map[string]interface{}{
+				"seed": 42,
+			},
+			expected: WeightedRandomConfig{Rate: 100, Total: 1000, Seed: 42},
+			err:      false,
+		},
 		"total override": {
 			initial: WeightedRandomConfig{Rate: 100, Total: 1000},
 			overrides: map[string]interface{}{
@@ -120,7 +135,7 @@ func TestWeightedRandomConfigValidate(t *testing.T) {
 		config           WeightedRandomConfig
 		defaultOverrides map[string]interface{}
 		expectedTotal    int
-		expectedDuration int
+		expectedDuration Seconds
 		err              bool
 	}{
 		"total and duration set - duration ignored": {
@@ -158,6 +173,44 @@ func TestWeightedRandomConfigValidate(t *testing.T) {
 			expectedDuration: 0,
 			err:              false,
 		},
+		"deterministic distribution with default total": {
+			config:           WeightedRandomConfig{Distribution: DistributionDeterministic},
+			defaultOverrides: map[string]interface{}{"total": 500},
+			expectedTotal:    500,
+			expectedDuration: 0,
+			err:              false,
+		},
+		"deterministic distribution without total": {
+			config:           WeightedRandomConfig{Duration: 60, Distribution: DistributionDeterministic},
+			defaultOverrides: nil,
+			err:              true,
+		},
+		"negative burst": {
+			config:           WeightedRandomConfig{Total: 1000, Burst: -1},
+			defaultOverrides: nil,
+			err:              true,
+		},
+		"negative maxInFlight": {
+			config:           WeightedRandomConfig{Total: 1000, MaxInFlight: -1},
+			defaultOverrides: nil,
+			err:              true,
+		},
+		"unsupported distribution": {
+			config:           WeightedRandomConfig{Total: 1000, Distribution: "round-robin"},
+			defaultOverrides: nil,
+			err:              true,
+		},
+		"invalid request": {
+			config: WeightedRandomConfig{Total: 1000, Requests: []*WeightedRequest{
+				{Shares: 1, List: &RequestList{KubeGroupVersionResource: KubeGroupVersionResource{Version: "v1", Resource: "pods"}}},
+				{Shares: -1, List: &RequestList{KubeGroupVersionResource: KubeGroupVersionResource{Version: "v1", Resource: "nodes"}}},
+			}},
+			err: true,
+		},
+		"null request": {
+			config: WeightedRandomConfig{Total: 1000, Requests: []*WeightedRequest{nil}},
+			err:    true,
+		},
 	}
 
 	for name, tc := range tests {
@@ -177,13 +230,19 @@ func TestWeightedRandomConfigValidate(t *testing.T) {
 
 func TestWeightedRandomConfigConfigureClientOptions(t *testing.T) {
 	tests := map[string]struct {
-		config      WeightedRandomConfig
-		expectedQPS float64
+		config        WeightedRandomConfig
+		expectedQPS   float64
+		expectedBurst int
 	}{
 		"rate set": {
 			config:      WeightedRandomConfig{Rate: 100},
 			expectedQPS: 100,
 		},
+		"rate and burst set": {
+			config:        WeightedRandomConfig{Rate: 100, Burst: 200},
+			expectedQPS:   100,
+			expectedBurst: 200,
+		},
 		"rate zero": {
 			config:      WeightedRandomConfig{Rate: 0},
 			expectedQPS: 0,
@@ -198,6 +257,7 @@ func TestWeightedRandomConfigConfigureClientOptions(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			opts := tc.config.ConfigureClientOptions()
 			assert.Equal(t, tc.expectedQPS, opts.QPS)
+			assert.Equal(t, tc.expectedBurst, opts.Burst)
 		})
 	}
 }
@@ -287,28 +347,41 @@ spec:
 	assert.Len(t, wrConfig.Requests, 7)
 
 	assert.Equal(t, 100, wrConfig.Requests[0].Shares)
-	assert.NotNil(t, wrConfig.Requests[0].StaleGet)
-	assert.Equal(t, "pods", wrConfig.Requests[0].StaleGet.Resource)
-	assert.Equal(t, "v1", wrConfig.Requests[0].StaleGet.Version)
-	assert.Equal(t, "core", wrConfig.Requests[0].StaleGet.Group)
-	assert.Equal(t, "default", wrConfig.Requests[0].StaleGet.Namespace)
-	assert.Equal(t, "x1", wrConfig.Requests[0].StaleGet.Name)
-
-	assert.NotNil(t, wrConfig.Requests[1].QuorumGet)
+	// The deprecated request types are converted into get and list.
+	assert.Nil(t, wrConfig.Requests[0].StaleGet)
+	require.NotNil(t, wrConfig.Requests[0].Get)
+	assert.Equal(t, ConsistencyStale, wrConfig.Requests[0].Get.Consistency)
+	assert.Equal(t, "pods", wrConfig.Requests[0].Get.Resource)
+	assert.Equal(t, "v1", wrConfig.Requests[0].Get.Version)
+	assert.Equal(t, "core", wrConfig.Requests[0].Get.Group)
+	assert.Equal(t, "default", wrConfig.Requests[0].Get.Namespace)
+	assert.Equal(t, "x1", wrConfig.Requests[0].Get.Name)
+	assert.Equal(t, "staleGet", wrConfig.Requests[0].Type())
+
+	assert.Nil(t, wrConfig.Requests[1].QuorumGet)
+	require.NotNil(t, wrConfig.Requests[1].Get)
+	assert.Equal(t, ConsistencyQuorum, wrConfig.Requests[1].Get.Consistency)
 	assert.Equal(t, 150, wrConfig.Requests[1].Shares)
+	assert.Equal(t, "quorumGet", wrConfig.Requests[1].Type())
 
 	assert.Equal(t, 200, wrConfig.Requests[2].Shares)
-	assert.NotNil(t, wrConfig.Requests[2].StaleList)
-	assert.Equal(t, "pods", wrConfig.Requests[2].StaleList.Resource)
-	assert.Equal(t, "v1", wrConfig.Requests[2].StaleList.Version)
-	assert.Equal(t, "core", wrConfig.Requests[2].StaleList.Group)
-	assert.Equal(t, "default", wrConfig.Requests[2].StaleList.Namespace)
-	assert.Equal(t, 0, wrConfig.Requests[2].StaleList.Limit)
-	assert.Equal(t, "app=x2", wrConfig.Requests[2].StaleList.Selector)
-	assert.Equal(t, "spec.nodeName=x", wrConfig.Requests[2].StaleList.FieldSelector)
-
-	assert.NotNil(t, wrConfig.Requests[3].QuorumList)
+	assert.Nil(t, wrConfig.Requests[2].StaleList)
+	require.NotNil(t, wrConfig.Requests[2].List)
+	assert.Equal(t, ConsistencyStale, wrConfig.Requests[2].List.Consistency)
+	assert.Equal(t, "pods", wrConfig.Requests[2].List.Resource)
+	assert.Equal(t, "v1", wrConfig.Requests[2].List.Version)
+	assert.Equal(t, "core", wrConfig.Requests[2].List.Group)
+	assert.Equal(t, "default", wrConfig.Requests[2].List.Namespace)
+	assert.Equal(t, 0, wrConfig.Requests[2].List.Limit)
+	assert.Equal(t, "app=x2", wrConfig.Requests[2].List.Selector)
+	assert.Equal(t, "spec.nodeName=x", wrConfig.Requests[2].List.FieldSelector)
+	assert.Equal(t, "staleList", wrConfig.Requests[2].Type())
+
+	assert.Nil(t, wrConfig.Requests[3].QuorumList)
+	require.NotNil(t, wrConfig.Requests[3].List)
+	assert.Equal(t, ConsistencyQuorum, wrConfig.Requests[3].List.Consistency)
 	assert.Equal(t, 400, wrConfig.Requests[3].Shares)
+	assert.Equal(t, "quorumList", wrConfig.Requests[3].Type())
 
 	assert.Equal(t, 1000, wrConfig.Requests[4].Shares)
 	assert.NotNil(t, wrConfig.Requests[4].Put)
@@ -341,6 +414,7 @@ version: 1
 description: legacy format test
 spec:
   rate: 50
+  burst: 80
   total: 5000
   duration: 120
   conns: 4
@@ -380,21 +454,92 @@ spec:
 
 	// Verify legacy fields are migrated
 	assert.Equal(t, float64(50), wrConfig.Rate)
+	assert.Equal(t, 80, wrConfig.Burst)
 	assert.Equal(t, 5000, wrConfig.Total)
-	assert.Equal(t, 120, wrConfig.Duration)
+	assert.Equal(t, Seconds(120), wrConfig.Duration)
 	assert.Len(t, wrConfig.Requests, 2)
 
 	assert.Equal(t, 50, wrConfig.Requests[0].Shares)
-	assert.NotNil(t, wrConfig.Requests[0].StaleGet)
-	assert.Equal(t, "pods", wrConfig.Requests[0].StaleGet.Resource)
-	assert.Equal(t, "v1", wrConfig.Requests[0].StaleGet.Version)
-	assert.Equal(t, "default", wrConfig.Requests[0].StaleGet.Namespace)
-	assert.Equal(t, "test-pod", wrConfig.Requests[0].StaleGet.Name)
+	require.NotNil(t, wrConfig.Requests[0].Get)
+	assert.Equal(t, ConsistencyStale, wrConfig.Requests[0].Get.Consistency)
+	assert.Equal(t, "pods", wrConfig.Requests[0].Get.Resource)
+	assert.Equal(t, "v1", wrConfig.Requests[0].Get.Version)
+	assert.Equal(t, "default", wrConfig.Requests[0].Get.Namespace)
+	assert.Equal(t, "test-pod", wrConfig.Requests[0].Get.Name)
 
 	assert.Equal(t, 100, wrConfig.Requests[1].Shares)
-	assert.NotNil(t, wrConfig.Requests[1].QuorumList)
-	assert.Equal(t, "configmaps", wrConfig.Requests[1].QuorumList.Resource)
-	assert.Equal(t, 100, wrConfig.Requests[1].QuorumList.Limit)
+	require.NotNil(t, wrConfig.Requests[1].List)
+	assert.Equal(t, ConsistencyQuorum, wrConfig.Requests[1].List.Consistency)
+	assert.Equal(t, "configmaps", wrConfig.Requests[1].List.Resource)
+	assert.Equal(t, 100, wrConfig.Requests[1].List.Limit)
 
 	assert.NoError(t, target.Validate())
 }
+
+func TestWeightedRandomConfigValidateRequests(t *testing.T) {
+	gvr := KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+	list := func(shares int, consistency Consistency) *WeightedRequest {
+		return &WeightedRequest{Shares: shares, List: &RequestList{KubeGroupVersionResource: gvr, Consistency: consistency}}
+	}
+	staleList := func(shares int) *WeightedRequest {
+		return &WeightedRequest{Shares: shares, StaleList: &RequestList{KubeGroupVersionResource: gvr}}
+	}
+
+	for name, tc := range map[string]struct {
+		requests []*WeightedRequest
+		err      string
+		warn     bool
+	}{
+		"different requests": {
+			requests: []*WeightedRequest{list(9, ConsistencyStale), list(1, ConsistencyQuorum)},
+		},
+		"invalid request": {
+			requests: []*WeightedRequest{list(1, ConsistencyStale), {Shares: 1}},
+			err:      "request[1]: empty request value",
+		},
+		"duplicate request": {
+			requests: []*WeightedRequest{list(1, ConsistencyStale), list(2, ConsistencyQuorum), list(3, ConsistencyStale)},
+			err:      "request[2]: duplicate of request[0]",
+		},
+		"duplicate deprecated request": {
+			requests: []*WeightedRequest{list(1, ConsistencyStale), staleList(2)},
+			err:      "request[1]: duplicate of request[0]",
+		},
+		"same shares": {
+			requests: []*WeightedRequest{list(1, ConsistencyStale), list(1, ConsistencyQuorum)},
+			warn:     true,
+		},
+		"one request": {
+			requests: []*WeightedRequest{list(1, ConsistencyStale)},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			captureKlog(t, &buf)
+
+			config := WeightedRandomConfig{Total: 10, Requests: tc.requests}
+			err := config.Validate(nil)
+			klog.Flush()
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.warn, bytes.Contains(buf.Bytes(), []byte("same shares")), buf.String())
+		})
+	}
+}
+
+// captureKlog redirects klog's output into buf until the test finishes.
+func captureKlog(t *testing.T, buf *bytes.Buffer) {
+	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	require.NoError(t, fs.Set("logtostderr", "false"))
+	require.NoError(t, fs.Set("alsologtostderr", "false"))
+	klog.SetOutput(buf)
+	t.Cleanup(func() {
+		klog.Flush()
+		require.NoError(t, fs.Set("logtostderr", "true"))
+		klog.SetOutput(os.Stderr)
+	})
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package analyze
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/urfave/cli"
+)
+
+// Command represents analyze subcommand.
+var Command = cli.Command{
+	Name:  "analyze",
+	Usage: "Compute aggregate statistics of runner's results",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:     "input-ndjson",
+			Usage:    "Path to the file which stores results in NDJSON format, like the one written by runner run --output-append",
+			Required: true,
+		},
+	},
+	Action: func(cliCtx *cli.Context) error {
+		fpath := cliCtx.String("input-ndjson")
+
+		f, err := os.Open(fpath)
+		if err != nil {
+			return fmt.Errorf("failed to open file %s: %w", fpath, err)
+		}
+		defer f.Close()
+
+		reports, err := readReports(f)
+		if err != nil {
+			return fmt.Errorf("failed to read results from %s: %w", fpath, err)
+		}
+
+		res, err := analyzeReports(reports)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(res); err != nil {
+			return fmt.Errorf("failed to encode json: %w", err)
+		}
+		return nil
+	},
+}
+
+// Analysis is the aggregate statistics of multiple runs.
+type Analysis struct {
+	// Runs is the number of runs.
+	Runs int `json:"runs"`
+	// RunIDs are the run IDs of results in order if any.
+	RunIDs []string `json:"runIDs,omitempty"`
+	// Total is the total number of requests of all the runs.
+	Total int `json:"total"`
+	// ErrorCount is the total number of failed requests of all the runs.
+	ErrorCount int `json:"errorCount"`
+	// ErrorRate is the ratio of failed requests to total.
+	ErrorRate float64 `json:"errorRate"`
+	// Throughput summarizes requests per second of each run.
+	Throughput *Summary `json:"throughput,omitempty"`
+	// PercentileLatencies summarizes each run's percentile latency in
+	// seconds, keyed by percentile like p99.
+	PercentileLatencies map[string]Summary `json:"percentileLatencies,omitempty"`
+}
+
+// Summary describes the distribution of one value across runs.
+type Summary struct {
+	Min    float64 `json:"min"`
+	Mean   float64 `json:"mean"`
+	Max    float64 `json:"max"`
+	StdDev float64 `json:"stdDev"`
+}
+
+// readReports reads all the JSON objects from r.
+func readReports(r io.Reader) ([]types.RunnerMetricReport, error) {
+	var reports []types.RunnerMetricReport
+
+	decoder := json.NewDecoder(r)
+	for {
+		var report types.RunnerMetricReport
+		err := decoder.Decode(&report)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode result #%d: %w", len(reports)+1, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// analyzeReports computes aggregate statistics of reports.
+func analyzeReports(reports []types.RunnerMetricReport) (*Analysis, error) {
+	if len(reports) == 0 {
+		return nil, fmt.Errorf("no result to analyze")
+	}
+
+	res := &Analysis{Runs: len(reports)}
+
+	var throughputs []float64
+	latencies := map[string][]float64{}
+	for idx, report := range reports {
+		if report.Metadata != nil && report.Metadata.RunID != "" {
+			res.RunIDs = append(res.RunIDs, report.Metadata.RunID)
+		}
+
+		res.Total += report.Total
+		res.ErrorCount += report.ErrorCount
+
+		if report.Duration != "" {
+			d, err := time.ParseDuration(report.Duration)
+			if err != nil {
+				return nil, fmt.Errorf("invalid duration %q in result #%d: %w", report.Duration, idx+1, err)
+			}
+			if d > 0 {
+				throughputs = append(throughputs, float64(report.Total)/d.Seconds())
+			}
+		}
+
+		for _, p := range report.PercentileLatencies {
+			key := "p" + strconv.FormatFloat(p[0]*100, 'f', -1, 64)
+			latencies[key] = append(latencies[key], p[1])
+		}
+	}
+
+	if res.Total > 0 {
+		res.ErrorRate = float64(res.ErrorCount) / float64(res.Total)
+	}
+	if len(throughputs) > 0 {
+		s := summarize(throughputs)
+		res.Throughput = &s
+	}
+	if len(latencies) > 0 {
+		res.PercentileLatencies = make(map[string]Summary, len(latencies))
+		for key, values := range latencies {
+			res.PercentileLatencies[key] = summarize(values)
+		}
+	}
+	return res, nil
+}
+
+// summarize computes min, mean, max and population standard deviation.
+func summarize(values []float64) Summary {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(values))
+
+	return Summary{
+		Min:    slices.Min(values),
+		Mean:   mean,
+		Max:    slices.Max(values),
+		StdDev: math.Sqrt(variance),
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package analyze
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeReports(t *testing.T) {
+	in := `{"metadata":{"runID":"a"},"total":100,"errorCount":2,"duration":"10s","percentileLatencies":[[0.5,0.1],[0.99,0.4]]}
+{"metadata":{"runID":"b"},"total":200,"errorCount":0,"duration":"10s","percentileLatencies":[[0.5,0.2],[0.99,0.6]]}
+{"total":300,"errorCount":4,"duration":"10s","percentileLatencies":[[0.5,0.3],[0.99,0.8]]}
+`
+	reports, err := readReports(strings.NewReader(in))
+	require.NoError(t, err)
+	require.Len(t, reports, 3)
+
+	res, err := analyzeReports(reports)
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Runs)
+	assert.Equal(t, []string{"a", "b"}, res.RunIDs)
+	assert.Equal(t, 600, res.Total)
+	assert.Equal(t, 6, res.ErrorCount)
+	assert.InDelta(t, 0.01, res.ErrorRate, 1e-9)
+
+	require.NotNil(t, res.Throughput)
+	assert.InDelta(t, 10, res.Throughput.Min, 1e-9)
+	assert.InDelta(t, 20, res.Throughput.Mean, 1e-9)
+	assert.InDelta(t, 30, res.Throughput.Max, 1e-9)
+
+	require.Contains(t, res.PercentileLatencies, "p99")
+	p99 := res.PercentileLatencies["p99"]
+	assert.InDelta(t, 0.4, p99.Min, 1e-9)
+	assert.InDelta(t, 0.6, p99.Mean, 1e-9)
+	assert.InDelta(t, 0.8, p99.Max, 1e-9)
+	assert.InDelta(t, 0.1633, p99.StdDev, 1e-4)
+	assert.Contains(t, res.PercentileLatencies, "p50")
+
+	_, err = readReports(strings.NewReader(`{"total":1}` + "\n{broken"))
+	assert.Error(t, err)
+
+	_, err = analyzeReports(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+// Command represents profile subcommand.
+var Command = cli.Command{
+	Name:  "profile",
+	Usage: "Manage load profile files",
+	Subcommands: []cli.Command{
+		annotateCommand,
+		statsCommand,
+	},
+}
+
+var annotateCommand = cli.Command{
+	Name:  "annotate",
+	Usage: "Set annotation of load profile file in-place",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:     "config",
+			Usage:    "Path to the load profile file",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     "key",
+			Usage:    "Annotation's key, like " + types.AnnotationClusterVersion,
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:  "value",
+			Usage: "Annotation's value",
+		},
+	},
+	Action: func(cliCtx *cli.Context) error {
+		return annotateFile(cliCtx.String("config"), cliCtx.String("key"), cliCtx.String("value"))
+	},
+}
+
+// annotateFile sets annotation key=value of load profile stored in fpath.
+//
+// NOTE: The file is rewritten from the decoded profile so that comments
+// are dropped and legacy format is migrated to weighted-random mode.
+func annotateFile(fpath, key, value string) error {
+	if key == "" {
+		return fmt.Errorf("annotation key is required")
+	}
+
+	info, err := os.Stat(fpath)
+	if err != nil {
+		return fmt.Errorf("failed to stat file %s: %w", fpath, err)
+	}
+
+	in, err := os.ReadFile(fpath)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", fpath, err)
+	}
+
+	out, err := annotate(in, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to annotate %s: %w", fpath, err)
+	}
+
+	if err := os.WriteFile(fpath, out, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", fpath, err)
+	}
+	return nil
+}
+
+// annotate sets annotation key=value of load profile in YAML format.
+func annotate(in []byte, key, value string) ([]byte, error) {
+	var profile types.LoadProfile
+	if err := yaml.Unmarshal(in, &profile); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal load profile: %w", err)
+	}
+
+	if profile.Annotations == nil {
+		profile.Annotations = map[string]string{}
+	}
+	profile.Annotations[key] = value
+
+	out, err := yaml.Marshal(&profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal load profile: %w", err)
+	}
+	return out, nil
+}
+
+var statsCommand = cli.Command{
+	Name:  "stats",
+	Usage: "Show statistics of load profile file",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:     "config",
+			Usage:    "Path to the load profile file",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:  "output-format",
+			Usage: "Format of statistics (json or table)",
+			Value: "table",
+		},
+	},
+	Action: func(cliCtx *cli.Context) error {
+		fpath := cliCtx.String("config")
+
+		in, err := os.ReadFile(fpath)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", fpath, err)
+		}
+
+		var profile types.LoadProfile
+		if err := yaml.Unmarshal(in, &profile); err != nil {
+			return fmt.Errorf("failed to unmarshal load profile %s: %w", fpath, err)
+		}
+		if profile.Spec.Mode != types.ModeWeightedRandom {
+			return fmt.Errorf("stats only supports %s mode, but got %s",
+				types.ModeWeightedRandom, profile.Spec.Mode)
+		}
+
+		stats := types.AnalyzeProfile(&profile)
+		switch format := cliCtx.String("output-format"); format {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(stats)
+		case "table":
+			return printStats(os.Stdout, stats)
+		default:
+			return fmt.Errorf("unsupported output format: %s", format)
+		}
+	},
+}
+
+// printStats writes statistics of load profile as table.
+func printStats(w io.Writer, stats types.ProfileStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Requests:\t%d\n", stats.Requests)
+	fmt.Fprintf(tw, "TotalShares:\t%d\n", stats.TotalShares)
+	fmt.Fprintf(tw, "Namespaces:\t%d\n", stats.Namespaces)
+	fmt.Fprintf(tw, "Rate(req/s):\t%g\n", stats.Rate)
+	fmt.Fprintf(tw, "EstimatedWrite(bytes/s):\t%.0f\n", stats.EstimatedWriteBytesPerSecond)
+	fmt.Fprintf(tw, "EstimatedRead(bytes/s):\t%.0f\n", stats.EstimatedReadBytesPerSecond)
+	fmt.Fprintln(tw)
+
+	typs := make([]string, 0, len(stats.RequestsByType))
+	for typ := range stats.RequestsByType {
+		typs = append(typs, typ)
+	}
+	sort.Strings(typs)
+
+	fmt.Fprintln(tw, "Type\tRequests\tShares\tShareFraction")
+	for _, typ := range typs {
+		fraction := 0.0
+		if stats.TotalShares > 0 {
+			fraction = float64(stats.SharesByType[typ]) / float64(stats.TotalShares)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f%%\n", typ,
+			stats.RequestsByType[typ], stats.SharesByType[typ], fraction*100)
+	}
+	return tw.Flush()
+}
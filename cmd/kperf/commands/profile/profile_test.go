@@ -0,0 +1,155 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package profile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestAnnotateRoundTrip(t *testing.T) {
+	for name, in := range map[string]string{
+		"weighted-random": `
+version: 1
+description: read and update
+annotations:
+  kperf.io/operator: alice
+spec:
+  conns: 2
+  client: 4
+  contentType: json
+  readTimeoutSeconds: 30s
+  mode: weighted-random
+  modeConfig:
+    rate: 10
+    total: 100
+    seed: 7
+    requests:
+    - shares: 10
+      staleList:
+        version: v1
+        resource: pods
+        limit: 500
+    - shares: 5
+      maxRetries: 0
+      patch:
+        version: v1
+        resource: configmaps
+        namespace: default
+        name: kperf
+        keySpaceSize: 100
+        keySpacePartitions: 2
+        keySpaceOffset: 1
+        patchType: merge
+        body: '{"data":{"k":"v"}}'
+`,
+		"time-series": `
+version: 1
+spec:
+  conns: 1
+  client: 1
+  contentType: json
+  mode: time-series
+  modeConfig:
+    interval: 1s
+    buckets:
+    - startTime: 0
+      requests:
+      - method: GET
+        version: v1
+        resource: pods
+        namespace: default
+        name: pod-1
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var expected types.LoadProfile
+			require.NoError(t, yaml.Unmarshal([]byte(in), &expected))
+
+			out, err := annotate([]byte(in), types.AnnotationClusterVersion, "v1.31.1")
+			require.NoError(t, err)
+
+			var got types.LoadProfile
+			require.NoError(t, yaml.Unmarshal(out, &got))
+			assert.Equal(t, "v1.31.1", got.Annotations[types.AnnotationClusterVersion])
+
+			if expected.Annotations == nil {
+				expected.Annotations = map[string]string{}
+			}
+			expected.Annotations[types.AnnotationClusterVersion] = "v1.31.1"
+			assert.Equal(t, expected, got)
+		})
+	}
+}
+
+func TestAnnotateFile(t *testing.T) {
+	fpath := filepath.Join(t.TempDir(), "profile.yaml")
+	require.NoError(t, os.WriteFile(fpath, []byte(`
+version: 1
+annotations:
+  kperf.io/node-count: "10"
+spec:
+  conns: 1
+  client: 1
+  contentType: json
+  mode: weighted-random
+  modeConfig:
+    total: 1
+    requests:
+    - shares: 1
+      quorumGet:
+        version: v1
+        resource: pods
+        namespace: default
+        name: pod-1
+`), 0600))
+
+	require.NoError(t, annotateFile(fpath, types.AnnotationNodeCount, "100"))
+	require.NoError(t, annotateFile(fpath, types.AnnotationGitSHA, "abc123"))
+
+	raw, err := os.ReadFile(fpath)
+	require.NoError(t, err)
+
+	var got types.LoadProfile
+	require.NoError(t, yaml.Unmarshal(raw, &got))
+	require.NoError(t, got.Validate())
+	assert.Equal(t, map[string]string{
+		types.AnnotationNodeCount: "100",
+		types.AnnotationGitSHA:    "abc123",
+	}, got.Annotations)
+
+	info, err := os.Stat(fpath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	assert.Error(t, annotateFile(fpath, "", "v"))
+	assert.Error(t, annotateFile(filepath.Join(t.TempDir(), "missing.yaml"), "k", "v"))
+}
+
+func TestPrintStats(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, printStats(&buf, types.ProfileStats{
+		Requests:       3,
+		TotalShares:    40,
+		Rate:           10,
+		SharesByType:   map[string]int{"staleList": 30, "put": 10},
+		RequestsByType: map[string]int{"staleList": 2, "put": 1},
+	}))
+
+	out := buf.String()
+	assert.Contains(t, out, "TotalShares:")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.GreaterOrEqual(t, len(lines), 2)
+	assert.Equal(t, []string{"put", "1", "10", "25.00%"}, strings.Fields(lines[len(lines)-2]))
+	assert.Equal(t, []string{"staleList", "2", "30", "75.00%"}, strings.Fields(lines[len(lines)-1]))
+}
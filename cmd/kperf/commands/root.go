@@ -9,6 +9,8 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/Azure/kperf/cmd/kperf/commands/analyze"
+	"github.com/Azure/kperf/cmd/kperf/commands/profile"
 	"github.com/Azure/kperf/cmd/kperf/commands/runner"
 	"github.com/Azure/kperf/cmd/kperf/commands/runnergroup"
 	"github.com/Azure/kperf/cmd/kperf/commands/virtualcluster"
@@ -26,6 +28,8 @@ func App() *cli.App {
 			runner.Command,
 			runnergroup.Command,
 			virtualcluster.Command,
+			analyze.Command,
+			profile.Command,
 		},
 		Flags: []cli.Flag{
 			cli.StringFlag{
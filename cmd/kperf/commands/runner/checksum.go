@@ -0,0 +1,75 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+var verifyChecksumCommand = cli.Command{
+	Name:  "verify-checksum",
+	Usage: "verify that the result was produced by the load profile",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "result",
+			Usage: "Path to the result file of runner",
+		},
+		cli.StringFlag{
+			Name:  "config",
+			Usage: "Path to the load profile",
+		},
+	},
+	Action: func(cliCtx *cli.Context) error {
+		resultPath, cfgPath := cliCtx.String("result"), cliCtx.String("config")
+		if resultPath == "" || cfgPath == "" {
+			return fmt.Errorf("required --result and --config")
+		}
+
+		checksum, err := verifyChecksum(resultPath, cfgPath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("checksum matches: %s\n", checksum)
+		return nil
+	},
+}
+
+// verifyChecksum returns the checksum of load profile stored in cfgPath if
+// it's the same as the result's ProfileChecksum.
+func verifyChecksum(resultPath, cfgPath string) (string, error) {
+	report, err := loadRunnerMetricReport(resultPath)
+	if err != nil {
+		return "", err
+	}
+	if report.ProfileChecksum == "" {
+		return "", fmt.Errorf("result %s doesn't have profileChecksum", resultPath)
+	}
+
+	cfgInRaw, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", cfgPath, err)
+	}
+
+	var profile types.LoadProfile
+	if err := yaml.Unmarshal(cfgInRaw, &profile); err != nil {
+		return "", fmt.Errorf("failed to unmarshal %s from yaml format: %w", cfgPath, err)
+	}
+
+	checksum, err := profile.Checksum()
+	if err != nil {
+		return "", err
+	}
+
+	if checksum != report.ProfileChecksum {
+		return "", fmt.Errorf("checksum mismatch: result %s has %s, but profile %s has %s",
+			resultPath, report.ProfileChecksum, cfgPath, checksum)
+	}
+	return checksum, nil
+}
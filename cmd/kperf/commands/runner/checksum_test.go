@@ -0,0 +1,64 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	dir := t.TempDir()
+
+	profile := `
+version: 1
+spec:
+  conns: 1
+  client: 1
+  contentType: json
+  mode: weighted-random
+  modeConfig:
+    rate: 10
+    total: 10
+    requests:
+    - staleGet:
+        version: v1
+        resource: pods
+        name: a
+      shares: 1
+`
+	cfgPath := filepath.Join(dir, "profile.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(profile), 0600))
+
+	var lp types.LoadProfile
+	require.NoError(t, yaml.Unmarshal([]byte(profile), &lp))
+	checksum, err := lp.Checksum()
+	require.NoError(t, err)
+
+	writeResult := func(checksum string) string {
+		data, err := json.Marshal(types.RunnerMetricReport{Total: 1, ProfileChecksum: checksum})
+		require.NoError(t, err)
+		fpath := filepath.Join(dir, "result-"+checksum+".json")
+		require.NoError(t, os.WriteFile(fpath, data, 0600))
+		return fpath
+	}
+
+	got, err := verifyChecksum(writeResult(checksum), cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, checksum, got)
+
+	_, err = verifyChecksum(writeResult("0000"), cfgPath)
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	_, err = verifyChecksum(writeResult(""), cfgPath)
+	assert.ErrorContains(t, err, "doesn't have profileChecksum")
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/request"
+
+	"github.com/gorilla/mux"
+	"k8s.io/klog/v2"
+)
+
+// controlServer exposes HTTP API to query progress of running benchmark or
+// stop it.
+type controlServer struct {
+	progress *request.Progress
+	token    string
+
+	// partialReport returns partial report of benchmark. It returns nil
+	// if benchmark isn't started yet.
+	partialReport func() *types.RunnerMetricReport
+}
+
+// handler returns http.Handler for control API.
+func (s *controlServer) handler() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/healthz", s.getHealthz).Methods("GET")
+	r.Handle("/status", s.authorized(s.getStatus)).Methods("GET")
+	r.Handle("/result", s.authorized(s.getResult)).Methods("GET")
+	r.Handle("/stop", s.authorized(s.postStop)).Methods("POST")
+	return r
+}
+
+// serve starts control API on the given address. The returned function shuts
+// down the server.
+func (s *controlServer) serve(addr string) (func(), error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	srv := &http.Server{
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.ErrorS(err, "control server exited", "addr", addr)
+		}
+	}()
+	klog.V(2).InfoS("Control server started", "addr", lis.Addr().String())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}, nil
+}
+
+// authorized requires bearer token if it's configured.
+func (s *controlServer) authorized(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				renderControlError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+				return
+			}
+		}
+		next(w, r)
+	})
+}
+
+// getHealthz returns ok if server is alive.
+func (s *controlServer) getHealthz(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// getStatus returns progress of benchmark.
+func (s *controlServer) getStatus(w http.ResponseWriter, _ *http.Request) {
+	renderControlJSON(w, http.StatusOK, s.progress.Status())
+}
+
+// getResult returns partial report of benchmark.
+func (s *controlServer) getResult(w http.ResponseWriter, _ *http.Request) {
+	report := s.partialReport()
+	if report == nil {
+		renderControlError(w, http.StatusNotFound, fmt.Errorf("benchmark is not started"))
+		return
+	}
+	renderControlJSON(w, http.StatusOK, report)
+}
+
+// postStop cancels benchmark gracefully.
+func (s *controlServer) postStop(w http.ResponseWriter, _ *http.Request) {
+	s.progress.Stop()
+	renderControlJSON(w, http.StatusAccepted, s.progress.Status())
+}
+
+// renderControlJSON renders v in json format.
+func renderControlJSON(w http.ResponseWriter, code int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		renderControlError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
+
+// renderControlError renders error into types.HTTPError format.
+func renderControlError(w http.ResponseWriter, code int, err error) {
+	data, _ := json.Marshal(types.HTTPError{
+		ErrorMessage: err.Error(),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+	kperfrunner "github.com/Azure/kperf/pkg/runner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlServer(t *testing.T) {
+	bench := &kperfrunner.Runner{}
+	srv := httptest.NewServer((&controlServer{
+		progress: bench.Progress(),
+		token:    "secret",
+		partialReport: func() *types.RunnerMetricReport {
+			if report := bench.PartialReport(); report != nil {
+				return report.Metrics
+			}
+			return nil
+		},
+	}).handler())
+	defer srv.Close()
+
+	do := func(method, path, token string) *http.Response {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := do("GET", "/healthz", "")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	for _, token := range []string{"", "wrong"} {
+		resp = do("GET", "/status", token)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	resp = do("GET", "/status", "secret")
+	var status types.RunnerStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, types.RunnerStatePending, status.State)
+
+	resp = do("GET", "/result", "secret")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = do("POST", "/stop", "secret")
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.True(t, status.Stopped)
+}
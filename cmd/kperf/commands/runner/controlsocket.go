@@ -0,0 +1,161 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Azure/kperf/request"
+
+	"k8s.io/klog/v2"
+)
+
+// controlSocketServer accepts line-based text commands on unix socket to
+// control running benchmark. Like requestUpdateServer, it's protected by the
+// socket file's permission. The commands are
+//
+//	rate <qps>  changes the requests per second (0 means unlimited)
+//	pause       holds benchmark from sending new requests
+//	resume      continues paused benchmark
+//	status      returns progress in json format
+//	stop        stops benchmark gracefully
+//
+// Each command is replied by one line, which is "ok", "error: <reason>" or
+// the json of status.
+type controlSocketServer struct {
+	controller request.RunnerController
+}
+
+// serve starts accepting commands on the given unix socket path. The
+// returned function closes the socket and the connections.
+func (s *controlSocketServer) serve(path string) (func(), error) {
+	lis, err := listenUnix(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu     sync.Mutex
+		conns  = map[net.Conn]struct{}{}
+		closed bool
+		wg     sync.WaitGroup
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					klog.ErrorS(err, "control socket server exited", "path", path)
+				}
+				return
+			}
+
+			mu.Lock()
+			if closed {
+				mu.Unlock()
+				conn.Close()
+				return
+			}
+			conns[conn] = struct{}{}
+			mu.Unlock()
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() {
+					mu.Lock()
+					delete(conns, conn)
+					mu.Unlock()
+					conn.Close()
+				}()
+				s.handle(conn, conn)
+			}()
+		}
+	}()
+	klog.V(2).InfoS("Control socket server started", "path", path)
+
+	return func() {
+		mu.Lock()
+		closed = true
+		lis.Close()
+		for conn := range conns {
+			conn.Close()
+		}
+		mu.Unlock()
+		wg.Wait()
+	}, nil
+}
+
+// handle processes the commands read from r one by one and writes replies
+// into w.
+func (s *controlSocketServer) handle(r io.Reader, w io.Writer) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		reply, err := s.exec(line)
+		if err != nil {
+			reply = "error: " + err.Error()
+		}
+		if _, err := fmt.Fprintln(w, reply); err != nil {
+			return
+		}
+	}
+}
+
+// exec runs one command and returns the reply.
+func (s *controlSocketServer) exec(line string) (string, error) {
+	fields := strings.Fields(line)
+	cmd, args := fields[0], fields[1:]
+
+	var err error
+	switch cmd {
+	case "rate":
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: rate <qps>")
+		}
+
+		var qps float64
+		qps, err = strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid rate %q: %w", args[0], err)
+		}
+		err = s.controller.UpdateRate(qps)
+	case "pause":
+		err = s.controller.Pause()
+	case "resume":
+		err = s.controller.Resume()
+	case "status":
+		data, err := json.Marshal(s.controller.Status())
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case "stop":
+		s.controller.Stop()
+	default:
+		return "", fmt.Errorf("unknown command %q", cmd)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	klog.V(2).InfoS("Control command applied", "command", line)
+	return "ok", nil
+}
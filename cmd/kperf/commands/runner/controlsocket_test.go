@@ -0,0 +1,139 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/request"
+	"github.com/Azure/kperf/request/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestControlSocketServer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","items":[]}`)
+	}))
+	defer srv.Close()
+
+	spec := &types.LoadProfileSpec{
+		Conns:       1,
+		Client:      2,
+		ContentType: types.ContentTypeJSON,
+		Mode:        types.ModeWeightedRandom,
+		ModeConfig: &types.WeightedRandomConfig{
+			Rate:     10,
+			Duration: 60,
+			Requests: []*types.WeightedRequest{
+				{
+					Shares: 1,
+					StaleList: &types.RequestList{
+						KubeGroupVersionResource: types.KubeGroupVersionResource{
+							Version:  "v1",
+							Resource: "pods",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	restClis, err := request.NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	exec, err := executor.NewWeightedRandomExecutor(spec)
+	require.NoError(t, err)
+
+	// NOTE: Unix socket path is limited to ~100 bytes, which t.TempDir
+	// may exceed.
+	dir, err := os.MkdirTemp("", "kperf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	sockPath := filepath.Join(dir, "ctl.sock")
+
+	progress := &request.Progress{}
+	shutdown, err := (&controlSocketServer{controller: progress}).serve(sockPath)
+	require.NoError(t, err)
+	defer shutdown()
+
+	conn, err := net.Dial("unix", sockPath)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	send := func(cmd string) string {
+		_, err := fmt.Fprintf(conn, "%s\n", cmd)
+		require.NoError(t, err)
+		reply, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		return strings.TrimSpace(reply)
+	}
+
+	// Schedule isn't started yet.
+	assert.True(t, strings.HasPrefix(send("rate 100"), "error: "))
+
+	type scheduleResult struct {
+		res *request.Result
+		err error
+	}
+	doneCh := make(chan scheduleResult, 1)
+	go func() {
+		res, err := request.Schedule(context.TODO(), spec, restClis,
+			request.WithScheduleExecutorOpt(exec),
+			request.WithScheduleProgressOpt(progress),
+		)
+		doneCh <- scheduleResult{res: res, err: err}
+	}()
+
+	require.Eventually(t, func() bool {
+		return progress.Status().State == types.RunnerStateRunning
+	}, 5*time.Second, 10*time.Millisecond)
+
+	limiter := exec.GetRateLimiter().(*rate.Limiter)
+	assert.Equal(t, rate.Limit(10), limiter.Limit())
+	assert.Equal(t, "ok", send("rate 100"))
+	assert.Equal(t, rate.Limit(100), limiter.Limit())
+
+	assert.True(t, strings.HasPrefix(send("rate -1"), "error: "))
+	assert.True(t, strings.HasPrefix(send("rate fast"), "error: "))
+	assert.True(t, strings.HasPrefix(send("unknown"), "error: "))
+
+	status := func() types.RunnerStatus {
+		var s types.RunnerStatus
+		require.NoError(t, json.Unmarshal([]byte(send("status")), &s))
+		return s
+	}
+	assert.Equal(t, "ok", send("pause"))
+	assert.True(t, status().Paused)
+	assert.Equal(t, "ok", send("resume"))
+	assert.False(t, status().Paused)
+
+	// Stop releases paused workers.
+	assert.Equal(t, "ok", send("pause"))
+	assert.Equal(t, "ok", send("stop"))
+
+	select {
+	case r := <-doneCh:
+		require.NoError(t, r.err)
+		assert.ErrorIs(t, r.res.TerminationCause, request.ErrScheduleStopped)
+	case <-time.After(10 * time.Second):
+		t.Fatal("schedule isn't stopped")
+	}
+}
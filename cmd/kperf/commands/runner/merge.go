@@ -0,0 +1,255 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/metrics"
+
+	"github.com/urfave/cli"
+)
+
+var mergeCommand = cli.Command{
+	Name:      "merge",
+	Usage:     "merge multiple runner's results into one",
+	ArgsUsage: "RESULT_FILE...",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "output, o",
+			Usage: "Path to the file which stores merged result (default is stdout)",
+		},
+	},
+	Action: func(cliCtx *cli.Context) error {
+		if cliCtx.NArg() == 0 {
+			return fmt.Errorf("required at least one result file")
+		}
+
+		sources := []string(cliCtx.Args())
+		reports := make([]types.RunnerMetricReport, 0, len(sources))
+		for _, fpath := range sources {
+			report, err := loadRunnerMetricReport(fpath)
+			if err != nil {
+				return err
+			}
+			reports = append(reports, *report)
+		}
+
+		merged, err := mergeRunnerMetricReports(sources, reports)
+		if err != nil {
+			return err
+		}
+
+		var f *os.File = os.Stdout
+		if outputFilePath := cliCtx.String("output"); outputFilePath != "" {
+			outputFileDir := filepath.Dir(outputFilePath)
+			if err := os.MkdirAll(outputFileDir, 0750); err != nil {
+				return fmt.Errorf("failed to ensure output's dir %s: %w", outputFileDir, err)
+			}
+
+			f, err = os.Create(outputFilePath)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+		}
+
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(merged); err != nil {
+			return fmt.Errorf("failed to encode json: %w", err)
+		}
+		return nil
+	},
+}
+
+// loadRunnerMetricReport loads types.RunnerMetricReport from file.
+func loadRunnerMetricReport(fpath string) (*types.RunnerMetricReport, error) {
+	data, err := os.ReadFile(fpath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", fpath, err)
+	}
+
+	var report types.RunnerMetricReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s from json format: %w", fpath, err)
+	}
+	return &report, nil
+}
+
+// mergeRunnerMetricReports merges reports into one. sources[i] is the name
+// of reports[i].
+//
+// The percentile latencies are recomputed from raw latencies if all the
+// reports include raw data. Otherwise, they are recomputed from merged
+// histograms, which requires identical bucket boundaries.
+func mergeRunnerMetricReports(sources []string, reports []types.RunnerMetricReport) (*types.RunnerMetricReport, error) {
+	if len(sources) != len(reports) {
+		return nil, fmt.Errorf("expected %d sources, but got %d", len(reports), len(sources))
+	}
+
+	res := &types.RunnerMetricReport{
+		ErrorStats:               map[string]int32{},
+		ErrorRateByURL:           map[string]types.ErrorRate{},
+		ErrorRateByMethod:        map[string]types.ErrorRate{},
+		PercentileLatenciesByURL: map[string][][2]float64{},
+		LatencyHistograms:        map[string]types.LatencyHistogram{},
+		MergedReports:            make([]types.MergedReport, 0, len(reports)),
+	}
+
+	hasRawData := true
+	latenciesByURL := map[string][]float64{}
+	watchSetupLatenciesByURL := map[string][]float64{}
+	watchEventLagsByURL := map[string][]float64{}
+	ttfbByURL := map[string][]float64{}
+	bodyReadLatenciesByURL := map[string][]float64{}
+
+	maxDuration := time.Duration(0)
+	for idx, report := range reports {
+		src := sources[idx]
+
+		if idx == 0 {
+			res.SchemaVersion = report.SchemaVersion
+		} else if report.SchemaVersion != res.SchemaVersion {
+			return nil, fmt.Errorf("schema version of %s is %q, but %s is %q",
+				src, report.SchemaVersion, sources[0], res.SchemaVersion)
+		}
+
+		res.Total += report.Total
+		res.TotalReceivedBytes += report.TotalReceivedBytes
+		res.TotalWatchEvents += report.TotalWatchEvents
+		res.TotalWatchBookmarks += report.TotalWatchBookmarks
+		res.ClientRotationCount += report.ClientRotationCount
+		metrics.MergeBenchmarkWindow(&res.BenchmarkStartTime, &res.BenchmarkEndTime,
+			report.BenchmarkStartTime, report.BenchmarkEndTime)
+		for u, n := range report.LogLinesByURL {
+			if res.LogLinesByURL == nil {
+				res.LogLinesByURL = map[string]int64{}
+			}
+			res.LogLinesByURL[u] += n
+		}
+		for u, span := range report.LogTimeSpanByURL {
+			if res.LogTimeSpanByURL == nil {
+				res.LogTimeSpanByURL = map[string]float64{}
+			}
+			res.LogTimeSpanByURL[u] = max(res.LogTimeSpanByURL[u], span)
+		}
+		res.Errors = append(res.Errors, report.Errors...)
+
+		for e, n := range report.ErrorStats {
+			res.ErrorStats[e] += n
+		}
+		metrics.MergeErrorRates(res.ErrorRateByURL, report.ErrorRateByURL)
+		metrics.MergeErrorRates(res.ErrorRateByMethod, report.ErrorRateByMethod)
+
+		for u, h := range report.LatencyHistograms {
+			merged := res.LatencyHistograms[u]
+			if err := metrics.MergeLatencyHistogram(&merged, h); err != nil {
+				return nil, fmt.Errorf("failed to merge histogram of %s from %s: %w", u, src, err)
+			}
+			res.LatencyHistograms[u] = merged
+		}
+
+		// NOTE: The report with raw data records either latency or error
+		// of each request. Latencies can be empty if all the requests
+		// failed.
+		if report.Total > 0 && len(report.LatenciesByURL) == 0 && len(report.Errors) == 0 {
+			hasRawData = false
+		}
+		for u, l := range report.LatenciesByURL {
+			latenciesByURL[u] = append(latenciesByURL[u], l...)
+		}
+		for u, l := range report.WatchSetupLatenciesByURL {
+			watchSetupLatenciesByURL[u] = append(watchSetupLatenciesByURL[u], l...)
+		}
+		for u, l := range report.WatchEventLagsByURL {
+			watchEventLagsByURL[u] = append(watchEventLagsByURL[u], l...)
+		}
+		for u, l := range report.TTFBByURL {
+			ttfbByURL[u] = append(ttfbByURL[u], l...)
+		}
+		for u, l := range report.BodyReadLatenciesByURL {
+			bodyReadLatenciesByURL[u] = append(bodyReadLatenciesByURL[u], l...)
+		}
+
+		dur, err := time.ParseDuration(report.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse duration %s from %s: %w", report.Duration, src, err)
+		}
+		if dur > maxDuration {
+			maxDuration = dur
+		}
+
+		merged := types.MergedReport{
+			Source:   src,
+			Total:    report.Total,
+			Duration: report.Duration,
+		}
+		if report.Metadata != nil {
+			merged.RunID = report.Metadata.RunID
+			merged.Labels = report.Metadata.Labels
+		}
+		res.MergedReports = append(res.MergedReports, merged)
+	}
+	res.Duration = maxDuration.String()
+
+	if hasRawData {
+		res.LatenciesByURL = latenciesByURL
+
+		latencies := make([]float64, 0, res.Total)
+		for u, l := range latenciesByURL {
+			latencies = append(latencies, l...)
+			res.PercentileLatenciesByURL[u] = metrics.BuildPercentileLatencies(l)
+		}
+		res.PercentileLatencies = metrics.BuildPercentileLatencies(latencies)
+	} else {
+		if len(res.LatencyHistograms) == 0 {
+			return nil, fmt.Errorf("unable to recompute percentiles: reports include neither raw latencies nor histograms")
+		}
+
+		var total types.LatencyHistogram
+		for u, h := range res.LatencyHistograms {
+			res.PercentileLatenciesByURL[u] = metrics.BuildPercentileLatenciesFromHistogram(h)
+			if err := metrics.MergeLatencyHistogram(&total, h); err != nil {
+				return nil, fmt.Errorf("failed to merge histogram of %s: %w", u, err)
+			}
+		}
+		res.PercentileLatencies = metrics.BuildPercentileLatenciesFromHistogram(total)
+	}
+
+	if len(watchSetupLatenciesByURL) > 0 {
+		res.WatchSetupLatenciesByURL = watchSetupLatenciesByURL
+		res.PercentileWatchSetupLatenciesByURL = map[string][][2]float64{}
+		for u, l := range watchSetupLatenciesByURL {
+			res.PercentileWatchSetupLatenciesByURL[u] = metrics.BuildPercentileLatencies(l)
+		}
+	}
+	if len(watchEventLagsByURL) > 0 {
+		res.WatchEventLagsByURL = watchEventLagsByURL
+		res.PercentileWatchEventLagsByURL = map[string][][2]float64{}
+		for u, l := range watchEventLagsByURL {
+			res.PercentileWatchEventLagsByURL[u] = metrics.BuildPercentileLatencies(l)
+		}
+	}
+	if len(ttfbByURL) > 0 {
+		res.TTFBByURL = ttfbByURL
+		res.PercentileTTFBByURL = map[string][][2]float64{}
+		for u, l := range ttfbByURL {
+			res.PercentileTTFBByURL[u] = metrics.BuildPercentileLatencies(l)
+		}
+	}
+	if len(bodyReadLatenciesByURL) > 0 {
+		res.BodyReadLatenciesByURL = bodyReadLatenciesByURL
+		res.PercentileBodyReadLatencyByURL = map[string][][2]float64{}
+		for u, l := range bodyReadLatenciesByURL {
+			res.PercentileBodyReadLatencyByURL[u] = metrics.BuildPercentileLatencies(l)
+		}
+	}
+	return res, nil
+}
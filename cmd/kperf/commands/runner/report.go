@@ -0,0 +1,391 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+//go:embed report.html.tmpl
+var reportHTMLTemplate string
+
+var reportCommand = cli.Command{
+	Name:      "report",
+	Usage:     "render runner's results into self-contained HTML report",
+	ArgsUsage: "RESULT_FILE...",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "output, o",
+			Usage: "Path to the HTML report (default is stdout)",
+		},
+		cli.StringFlag{
+			Name:  "config",
+			Usage: "Path to the load profile used by results, which is rendered as run metadata",
+		},
+	},
+	Action: func(cliCtx *cli.Context) error {
+		if cliCtx.NArg() == 0 {
+			return fmt.Errorf("required at least one result file")
+		}
+
+		data := htmlReportData{
+			Title:       "kperf runner report",
+			GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		}
+
+		var profile *types.LoadProfile
+		if cfgPath := cliCtx.String("config"); cfgPath != "" {
+			cfgInRaw, err := os.ReadFile(cfgPath)
+			if err != nil {
+				return fmt.Errorf("failed to read file %s: %w", cfgPath, err)
+			}
+
+			profile = &types.LoadProfile{}
+			if err := yaml.Unmarshal(cfgInRaw, profile); err != nil {
+				return fmt.Errorf("failed to unmarshal %s from yaml format: %w", cfgPath, err)
+			}
+		}
+
+		sources := []string(cliCtx.Args())
+		reports := make([]types.RunnerMetricReport, 0, len(sources))
+		for _, fpath := range sources {
+			report, err := loadRunnerMetricReport(fpath)
+			if err != nil {
+				return err
+			}
+			reports = append(reports, *report)
+			data.Sections = append(data.Sections, newHTMLReportSection(fpath, *report, profile))
+		}
+
+		data.Inputs = len(reports)
+		data.Run = newHTMLRunInfo(profile, reports)
+
+		// Multiple results get the aggregate section at the end.
+		if len(reports) > 1 {
+			merged, err := mergeRunnerMetricReports(sources, reports)
+			if err != nil {
+				return fmt.Errorf("failed to build aggregate: %w", err)
+			}
+			data.Sections = append(data.Sections, newHTMLReportSection("Aggregate", *merged, profile))
+		}
+
+		var f *os.File = os.Stdout
+		if outputFilePath := cliCtx.String("output"); outputFilePath != "" {
+			outputFileDir := filepath.Dir(outputFilePath)
+			if err := os.MkdirAll(outputFileDir, 0750); err != nil {
+				return fmt.Errorf("failed to ensure output's dir %s: %w", outputFileDir, err)
+			}
+
+			var err error
+			f, err = os.Create(outputFilePath)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+		}
+		return renderHTMLReport(f, data)
+	},
+}
+
+// htmlReportData is the input of HTML report template.
+type htmlReportData struct {
+	Title       string
+	GeneratedAt string
+	// Inputs is the number of results.
+	Inputs int
+	// Run is the run metadata. It's nil if there is neither load profile
+	// nor metadata in results.
+	Run *htmlRunInfo
+	// Sections has one section per result, plus the aggregate if there
+	// are multiple results.
+	Sections []htmlReportSection
+	// Percentiles are columns of latency percentile tables.
+	Percentiles []float64
+}
+
+// htmlRunInfo is the run metadata of HTML report.
+type htmlRunInfo struct {
+	Description string
+	Mode        types.ExecutionMode
+	// Rate is empty if the mode has no rate limit.
+	Rate   string
+	Conns  int
+	Client int
+}
+
+// htmlReportSection renders one report.
+type htmlReportSection struct {
+	Title  string
+	Report types.RunnerMetricReport
+	// Charts are the latency-over-time charts from executor's report.
+	Charts []htmlChart
+	// Specs are the sections of each spec if the report is from multiple
+	// specs. Then the report is the aggregate of them.
+	Specs []htmlSpecSection
+}
+
+// htmlSpecSection renders one spec of multi-spec report.
+type htmlSpecSection struct {
+	Name string
+	Mode types.ExecutionMode
+	// Rate is empty if the mode has no rate limit.
+	Rate   string
+	Charts []htmlChart
+}
+
+// htmlChart is the line chart drawn by the report's inline script.
+type htmlChart struct {
+	Title  string            `json:"title"`
+	Labels []string          `json:"labels"`
+	Series []htmlChartSeries `json:"series"`
+}
+
+// htmlChartSeries is one line of htmlChart.
+type htmlChartSeries struct {
+	Name string `json:"name"`
+	// Values has one value per label. Nil is a gap, like infinite
+	// latency, which can't be encoded in JSON.
+	Values []*float64 `json:"values"`
+}
+
+// newHTMLRunInfo returns the run metadata from the load profile. Without
+// load profile, it falls back to the first result's metadata.
+func newHTMLRunInfo(profile *types.LoadProfile, reports []types.RunnerMetricReport) *htmlRunInfo {
+	if profile != nil {
+		return newHTMLRunInfoFromSpec(profile.Description, &profile.Spec)
+	}
+	for _, report := range reports {
+		if report.Metadata != nil && report.Metadata.Spec != nil {
+			return newHTMLRunInfoFromSpec(report.Metadata.Description, report.Metadata.Spec)
+		}
+	}
+	return nil
+}
+
+// newHTMLRunInfoFromSpec returns the run metadata of spec.
+func newHTMLRunInfoFromSpec(description string, spec *types.LoadProfileSpec) *htmlRunInfo {
+	return &htmlRunInfo{
+		Description: description,
+		Mode:        spec.Mode,
+		Rate:        reportModeRate(spec.ModeConfig),
+		Conns:       spec.Conns,
+		Client:      spec.Client,
+	}
+}
+
+// newHTMLReportSection returns the section of report. The spec is from the
+// load profile if any, otherwise from report's metadata, so that the report
+// of multiple specs, which runs in composite mode, has a section per spec.
+func newHTMLReportSection(title string, report types.RunnerMetricReport, profile *types.LoadProfile) htmlReportSection {
+	section := htmlReportSection{
+		Title:  title,
+		Report: report,
+	}
+
+	var children map[string]*types.ExecutorReport
+	if report.ExecutorReport != nil {
+		section.Charts = reportOverTimeCharts(report.ExecutorReport)
+		children = report.ExecutorReport.Children
+	}
+
+	var spec *types.LoadProfileSpec
+	switch {
+	case profile != nil:
+		spec = &profile.Spec
+	case report.Metadata != nil:
+		spec = report.Metadata.Spec
+	}
+
+	if spec != nil {
+		if cfg, ok := spec.ModeConfig.(*types.CompositeConfig); ok && cfg != nil {
+			for idx, child := range cfg.Children {
+				name := child.Name
+				if name == "" {
+					name = fmt.Sprintf("child-%d", idx)
+				}
+				section.Specs = append(section.Specs, htmlSpecSection{
+					Name:   name,
+					Mode:   child.Mode,
+					Rate:   reportModeRate(child.ModeConfig),
+					Charts: reportOverTimeCharts(children[name]),
+				})
+			}
+			return section
+		}
+	}
+
+	// NOTE: Without spec, the specs are still known from children's
+	// executor reports.
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		section.Specs = append(section.Specs, htmlSpecSection{
+			Name:   name,
+			Charts: reportOverTimeCharts(children[name]),
+		})
+	}
+	return section
+}
+
+// reportOverTimeCharts returns the latency-over-time charts from executor's
+// report, like latencies per burst. It returns nil if the mode doesn't
+// record latencies over time.
+func reportOverTimeCharts(report *types.ExecutorReport) []htmlChart {
+	if report == nil {
+		return nil
+	}
+
+	var charts []htmlChart
+	if r := report.Burst; r != nil && len(r.Bursts) > 0 {
+		chart := htmlChart{
+			Title: "Latency per burst (seconds)",
+			Series: []htmlChartSeries{
+				{Name: "p50"},
+				{Name: "p99"},
+			},
+		}
+		for _, b := range r.Bursts {
+			chart.Labels = append(chart.Labels, fmt.Sprintf("#%d", b.Index))
+			chart.Series[0].Values = append(chart.Series[0].Values, lookupPercentile(b.PercentileLatencies, 0.5))
+			chart.Series[1].Values = append(chart.Series[1].Values, lookupPercentile(b.PercentileLatencies, 0.99))
+		}
+		charts = append(charts, chart)
+	}
+
+	if r := report.Adaptive; r != nil && len(r.Steps) > 0 {
+		chart := htmlChart{
+			Title:  "P99 latency per probed rate (seconds)",
+			Series: []htmlChartSeries{{Name: "p99"}},
+		}
+		for _, step := range r.Steps {
+			chart.Labels = append(chart.Labels, strconv.FormatFloat(step.Rate, 'f', -1, 64)+"/s")
+			chart.Series[0].Values = append(chart.Series[0].Values, chartValue(step.P99Seconds))
+		}
+		charts = append(charts, chart)
+	}
+
+	if r := report.TimeSeries; r != nil && len(r.Buckets) > 0 {
+		chart := htmlChart{
+			Title: "Dispatch lag per bucket (seconds)",
+			Series: []htmlChartSeries{
+				{Name: "first"},
+				{Name: "last"},
+			},
+		}
+		for _, b := range r.Buckets {
+			chart.Labels = append(chart.Labels, fmt.Sprintf("%d:%ss", b.Round, strconv.FormatFloat(b.StartTime, 'f', -1, 64)))
+			chart.Series[0].Values = append(chart.Series[0].Values, chartValue(b.FirstLagSeconds))
+			chart.Series[1].Values = append(chart.Series[1].Values, chartValue(b.LastLagSeconds))
+		}
+		charts = append(charts, chart)
+	}
+	return charts
+}
+
+// lookupPercentile returns the latency at percentile p. It returns nil if
+// there is no such percentile.
+func lookupPercentile(latencies [][2]float64, p float64) *float64 {
+	for _, l := range latencies {
+		if l[0] == p {
+			return chartValue(l[1])
+		}
+	}
+	return nil
+}
+
+// chartValue returns v as the value of htmlChartSeries. It returns nil if v
+// is infinite or NaN.
+func chartValue(v float64) *float64 {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return nil
+	}
+	return &v
+}
+
+// renderHTMLReport renders data into self-contained HTML page.
+func renderHTMLReport(w io.Writer, data htmlReportData) error {
+	if len(data.Percentiles) == 0 {
+		data.Percentiles = []float64{0, 0.5, 0.9, 0.95, 0.99, 1}
+	}
+
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"rate":           reportModeRate,
+		"percentile":     reportPercentile,
+		"percentileName": reportPercentileName,
+		"percent": func(v float64) string {
+			return strconv.FormatFloat(v*100, 'f', 2, 64) + "%"
+		},
+		"totalErrors": func(stats map[string]int32) int32 {
+			total := int32(0)
+			for _, n := range stats {
+				total += n
+			}
+			return total
+		},
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(reportHTMLTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse report template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// reportModeRate returns the rate of mode, which is the QPS of clients. It
+// returns empty string if the mode has no rate limit.
+func reportModeRate(cfg types.ModeConfig) string {
+	if cfg == nil {
+		return ""
+	}
+	if qps := cfg.ConfigureClientOptions().QPS; qps > 0 {
+		return strconv.FormatFloat(qps, 'f', -1, 64)
+	}
+	return ""
+}
+
+// reportPercentile returns the latency at percentile p in seconds.
+func reportPercentile(latencies [][2]float64, p float64) string {
+	for _, l := range latencies {
+		if l[0] == p {
+			return strconv.FormatFloat(l[1], 'f', 4, 64)
+		}
+	}
+	return "-"
+}
+
+// reportPercentileName returns the column name of percentile p.
+func reportPercentileName(p float64) string {
+	switch p {
+	case 0:
+		return "min"
+	case 1:
+		return "max"
+	default:
+		return "p" + strconv.FormatFloat(p*100, 'f', -1, 64)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTMLReport(t *testing.T) {
+	report := types.RunnerMetricReport{
+		Total:              100,
+		Duration:           "1m30s",
+		TotalReceivedBytes: 4096,
+		ErrorStats: map[string]int32{
+			"http-429": 7,
+		},
+		ErrorRateByURL: map[string]types.ErrorRate{
+			"GET /api/v1/pods": {Attempts: 100, Failures: 7, Rate: 0.07},
+		},
+		PercentileLatencies: [][2]float64{{0, 0.01}, {0.5, 0.123}, {0.9, 0.5}, {0.95, 0.6}, {0.99, 0.987}, {1, 1.5}},
+		PercentileLatenciesByURL: map[string][][2]float64{
+			"GET /api/v1/pods": {{0, 0.01}, {0.5, 0.123}, {0.9, 0.5}, {0.95, 0.6}, {0.99, 0.987}, {1, 1.5}},
+		},
+		LatencyHistograms: map[string]types.LatencyHistogram{
+			"GET /api/v1/pods": {Buckets: []float64{0.5, 1}, Counts: []int64{90, 9, 1}, Count: 100, Sum: 20},
+		},
+		ExecutorReport: &types.ExecutorReport{
+			Burst: &types.BurstReport{
+				Bursts: []types.BurstStats{
+					{Index: 0, Requests: 50, PercentileLatencies: [][2]float64{{0.5, 0.1}, {0.99, 0.2}}},
+					{Index: 1, Requests: 50, PercentileLatencies: [][2]float64{{0.5, 0.3}, {0.99, 0.4}}},
+				},
+			},
+		},
+	}
+
+	profile := &types.LoadProfile{
+		Description: "list pods in default namespace",
+		Spec: types.LoadProfileSpec{
+			Conns:      2,
+			Client:     10,
+			Mode:       types.ModeWeightedRandom,
+			ModeConfig: &types.WeightedRandomConfig{Rate: 25},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, renderHTMLReport(&buf, htmlReportData{
+		Title:    "test report",
+		Inputs:   1,
+		Run:      newHTMLRunInfo(profile, nil),
+		Sections: []htmlReportSection{newHTMLReportSection("result.json", report, profile)},
+	}))
+
+	out := buf.String()
+	for _, expected := range []string{
+		"<title>test report</title>",
+		"list pods in default namespace",
+		"weighted-random",
+		"<td>25</td>",
+		"<td>100</td>",
+		"1m30s",
+		"<td>4096</td>",
+		"http-429",
+		"7.00%",
+		"GET /api/v1/pods",
+		"0.1230",
+		"0.9870",
+		"1.5000",
+		"<th>p99</th>",
+		"Latency over time",
+		"Latency per burst (seconds)",
+		"#1",
+	} {
+		assert.Contains(t, out, expected)
+	}
+	assert.NotContains(t, out, "<script src=")
+	assert.NotContains(t, out, "Spec ")
+}
+
+func TestRenderHTMLReportMultiSpec(t *testing.T) {
+	// The load profile isn't provided so that run metadata is from the
+	// results.
+	spec := &types.LoadProfileSpec{
+		Conns:  2,
+		Client: 4,
+		Mode:   types.ModeComposite,
+		ModeConfig: &types.CompositeConfig{
+			Children: []types.CompositeChild{
+				{Name: "weighted-random", Mode: types.ModeWeightedRandom, ModeConfig: &types.WeightedRandomConfig{Rate: 25}},
+				{Name: "time-series", Mode: types.ModeTimeSeries, ModeConfig: &types.TimeSeriesConfig{}},
+			},
+		},
+	}
+	newReport := func(total int) types.RunnerMetricReport {
+		return types.RunnerMetricReport{
+			Metadata: &types.RunMetadata{
+				Description: "background list with replay",
+				Spec:        spec,
+			},
+			Total:               total,
+			PercentileLatencies: [][2]float64{{0.5, 0.123}},
+			ExecutorReport: &types.ExecutorReport{
+				Children: map[string]*types.ExecutorReport{
+					"time-series": {
+						TimeSeries: &types.TimeSeriesReport{
+							Buckets: []types.BucketDispatchLag{
+								{Round: 0, StartTime: 0, Requests: 3, FirstLagSeconds: 0.01, LastLagSeconds: 0.02},
+								{Round: 0, StartTime: 1.5, Requests: 3, FirstLagSeconds: 0.03, LastLagSeconds: 0.04},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	reports := []types.RunnerMetricReport{newReport(10), newReport(20)}
+	merged := types.RunnerMetricReport{Total: 30}
+
+	var buf bytes.Buffer
+	require.NoError(t, renderHTMLReport(&buf, htmlReportData{
+		Title:  "test report",
+		Inputs: len(reports),
+		Run:    newHTMLRunInfo(nil, reports),
+		Sections: []htmlReportSection{
+			newHTMLReportSection("a.json", reports[0], nil),
+			newHTMLReportSection("b.json", reports[1], nil),
+			newHTMLReportSection("Aggregate", merged, nil),
+		},
+	}))
+
+	out := buf.String()
+	for _, expected := range []string{
+		// Run metadata falls back to the results' metadata.
+		"background list with replay",
+		"<tr><th>Mode</th><td>composite</td></tr>",
+		"<tr><th>Rate</th><td>25</td></tr>",
+		"<tr><th>Conns</th><td>2</td></tr>",
+		// Each result has a section per spec.
+		"<h2>a.json</h2>",
+		"<h2>b.json</h2>",
+		"Aggregate of 2 specs",
+		"<h3>Spec weighted-random</h3>",
+		"<h3>Spec time-series</h3>",
+		"<tr><th>Mode</th><td>time-series</td></tr>",
+		"Dispatch lag per bucket (seconds)",
+		"0:1.5s",
+		// Multiple results have the aggregate.
+		"<h2>Aggregate</h2>",
+		"<td>30</td>",
+	} {
+		assert.Contains(t, out, expected)
+	}
+	assert.Equal(t, 2, strings.Count(out, "<h3>Spec weighted-random</h3>"))
+}
@@ -0,0 +1,148 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/kperf/request"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// requestLogEntry is one line of request log.
+type requestLogEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Method    string    `json:"method"`
+	URL       string    `json:"url"`
+	LatencyMS float64   `json:"latency_ms"`
+	Bytes     int64     `json:"bytes"`
+	Error     string    `json:"error,omitempty"`
+	// StatusCode is only available if apiserver returns error status,
+	// since the response of successful request is discarded.
+	StatusCode int32 `json:"status_code,omitempty"`
+}
+
+// RequestLogger writes one JSON line for each request. It's safe to use
+// concurrently.
+type RequestLogger struct {
+	mu sync.Mutex
+	// maxEntries is the maximum number of logged requests. Zero means no
+	// limit.
+	maxEntries int
+	entries    int
+
+	w       *bufio.Writer
+	closers []io.Closer
+}
+
+// NewRequestLogger creates the request log file. The log is compressed by
+// gzip if the path ends with .gz.
+func NewRequestLogger(path string, maxEntries int) (*RequestLogger, error) {
+	if maxEntries < 0 {
+		return nil, fmt.Errorf("max entries of request log requires >= 0: %d", maxEntries)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request log %s: %w", path, err)
+	}
+
+	l := &RequestLogger{
+		maxEntries: maxEntries,
+		closers:    []io.Closer{f},
+	}
+
+	var w io.Writer = f
+	if strings.HasSuffix(path, ".gz") {
+		gw := gzip.NewWriter(f)
+		// NOTE: gzip writer should be closed before file.
+		l.closers = append([]io.Closer{gw}, l.closers...)
+		w = gw
+	}
+	l.w = bufio.NewWriter(w)
+	return l, nil
+}
+
+// Intercept implements request.RequestInterceptor.
+func (l *RequestLogger) Intercept(ctx context.Context, req request.Requester, do func(context.Context) (int64, error)) (int64, error) {
+	start := time.Now()
+	bytes, err := do(ctx)
+	latency := time.Since(start)
+
+	entry := requestLogEntry{
+		Timestamp: start.UTC(),
+		Method:    req.Method(),
+		URL:       req.URL().String(),
+		LatencyMS: float64(latency) / float64(time.Millisecond),
+		Bytes:     bytes,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+
+		var status apierrors.APIStatus
+		if errors.As(err, &status) {
+			entry.StatusCode = status.Status().Code
+		}
+	}
+	l.log(&entry)
+	return bytes, err
+}
+
+// log writes the entry as one line. The entries beyond maxEntries are
+// dropped.
+func (l *RequestLogger) log(entry *requestLogEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		klog.ErrorS(err, "failed to marshal request log entry")
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.w == nil || (l.maxEntries > 0 && l.entries >= l.maxEntries) {
+		return
+	}
+	l.entries++
+	if l.entries == l.maxEntries {
+		klog.V(2).InfoS("Request log reaches max entries, dropping the rest", "maxEntries", l.maxEntries)
+	}
+
+	data = append(data, '\n')
+	if _, err := l.w.Write(data); err != nil {
+		klog.ErrorS(err, "failed to write request log")
+	}
+}
+
+// Close flushes the buffered entries and closes the file.
+func (l *RequestLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.w == nil {
+		return nil
+	}
+
+	errs := []error{l.w.Flush()}
+	for _, c := range l.closers {
+		errs = append(errs, c.Close())
+	}
+	l.w = nil
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to close request log: %w", err)
+	}
+	return nil
+}
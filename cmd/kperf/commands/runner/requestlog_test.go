@@ -0,0 +1,128 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type fakeRequester struct {
+	method string
+	url    *url.URL
+}
+
+func (r *fakeRequester) Method() string                    { return r.method }
+func (r *fakeRequester) URL() *url.URL                     { return r.url }
+func (r *fakeRequester) MaskedURL() *url.URL               { return r.url }
+func (r *fakeRequester) Timeout(time.Duration)             {}
+func (r *fakeRequester) Do(context.Context) (int64, error) { return 0, nil }
+
+func TestRequestLoggerConcurrentWrites(t *testing.T) {
+	for name, path := range map[string]string{
+		"plain": "requests.ndjson",
+		"gzip":  "requests.ndjson.gz",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), path)
+			logger, err := NewRequestLogger(path, 0)
+			require.NoError(t, err)
+
+			// NOTE: The long URL makes entries larger than bufio's
+			// buffer so that interleaved writes would break lines.
+			u := &url.URL{Scheme: "https", Host: "apiserver", Path: "/api/v1/pods", RawQuery: "labelSelector=" + strings.Repeat("a", 8192)}
+
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 50; j++ {
+						req := &fakeRequester{method: fmt.Sprintf("GET-%d", i), url: u}
+						_, _ = logger.Intercept(context.TODO(), req, func(context.Context) (int64, error) {
+							return int64(j), nil
+						})
+					}
+				}(i)
+			}
+			wg.Wait()
+			require.NoError(t, logger.Close())
+
+			entries := readRequestLog(t, path)
+			assert.Len(t, entries, 1000)
+		})
+	}
+}
+
+func TestRequestLoggerEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.ndjson")
+	logger, err := NewRequestLogger(path, 2)
+	require.NoError(t, err)
+
+	req := &fakeRequester{method: "GET", url: &url.URL{Path: "/api/v1/namespaces/default/pods/a"}}
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "a")
+
+	for i := 0; i < 3; i++ {
+		bytes, err := logger.Intercept(context.TODO(), req, func(context.Context) (int64, error) {
+			time.Sleep(time.Millisecond)
+			return 10, notFound
+		})
+		assert.Equal(t, int64(10), bytes)
+		assert.Equal(t, notFound, err)
+	}
+	require.NoError(t, logger.Close())
+	require.NoError(t, logger.Close(), "close is idempotent")
+
+	entries := readRequestLog(t, path)
+	require.Len(t, entries, 2, "capped by max entries")
+	assert.Equal(t, "GET", entries[0].Method)
+	assert.Equal(t, "/api/v1/namespaces/default/pods/a", entries[0].URL)
+	assert.Equal(t, int64(10), entries[0].Bytes)
+	assert.Equal(t, int32(404), entries[0].StatusCode)
+	assert.Equal(t, notFound.Error(), entries[0].Error)
+	assert.GreaterOrEqual(t, entries[0].LatencyMS, float64(1))
+	assert.False(t, entries[0].Timestamp.IsZero())
+}
+
+// readRequestLog reads all the entries of request log.
+func readRequestLog(t *testing.T, path string) []requestLogEntry {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var r io.Reader = f
+	if filepath.Ext(path) == ".gz" {
+		gr, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		defer gr.Close()
+		r = gr
+	}
+
+	entries := []requestLogEntry{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var entry requestLogEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry), "line %d", len(entries))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
@@ -0,0 +1,99 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/request"
+	"github.com/Azure/kperf/request/executor"
+
+	"github.com/gorilla/mux"
+	"k8s.io/klog/v2"
+)
+
+// requestUpdateServer exposes HTTP API on unix socket to replace the requests
+// of running benchmark. Unlike control API, it's protected by the socket
+// file's permission instead of token.
+type requestUpdateServer struct {
+	controller request.RunnerController
+}
+
+// handler returns http.Handler for request update API.
+func (s *requestUpdateServer) handler() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/requests", s.putRequests).Methods("PUT")
+	return r
+}
+
+// serve starts request update API on the given unix socket path. The
+// returned function shuts down the server and removes the socket.
+func (s *requestUpdateServer) serve(path string) (func(), error) {
+	lis, err := listenUnix(path)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &http.Server{
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.ErrorS(err, "request update server exited", "path", path)
+		}
+	}()
+	klog.V(2).InfoS("Request update server started", "path", path)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}, nil
+}
+
+// listenUnix listens on the given unix socket path. The socket left by
+// previous run is removed, which fails listen otherwise.
+func listenUnix(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+		}
+	}
+
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	return lis, nil
+}
+
+// putRequests replaces the requests by the list of weighted requests in
+// json format.
+func (s *requestUpdateServer) putRequests(w http.ResponseWriter, r *http.Request) {
+	var requests []*types.WeightedRequest
+	if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+		renderControlError(w, http.StatusBadRequest, fmt.Errorf("invalid requests: %w", err))
+		return
+	}
+
+	if err := s.controller.UpdateRequests(requests); err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, executor.ErrUpdateRequestsNotSupported) {
+			code = http.StatusConflict
+		}
+		renderControlError(w, code, err)
+		return
+	}
+	klog.V(2).InfoS("Requests updated", "count", len(requests))
+	renderControlJSON(w, http.StatusOK, s.controller.Status())
+}
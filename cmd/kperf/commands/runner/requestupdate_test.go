@@ -0,0 +1,94 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/request/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRunnerController struct {
+	requests []*types.WeightedRequest
+	err      error
+}
+
+func (c *fakeRunnerController) Status() types.RunnerStatus {
+	return types.RunnerStatus{State: types.RunnerStateRunning}
+}
+
+func (c *fakeRunnerController) Stop() {}
+
+func (c *fakeRunnerController) UpdateRequests(requests []*types.WeightedRequest) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.requests = requests
+	return nil
+}
+
+func (c *fakeRunnerController) UpdateRate(float64) error { return c.err }
+
+func (c *fakeRunnerController) Pause() error { return c.err }
+
+func (c *fakeRunnerController) Resume() error { return c.err }
+
+func (c *fakeRunnerController) DistributionStats() (<-chan executor.DistributionSnapshot, error) {
+	return nil, c.err
+}
+
+func TestRequestUpdateServer(t *testing.T) {
+	// NOTE: Unix socket path is limited to ~100 bytes, which t.TempDir
+	// may exceed.
+	dir, err := os.MkdirTemp("", "kperf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	sockPath := filepath.Join(dir, "update.sock")
+
+	// Stale socket is removed.
+	lis, err := net.Listen("unix", sockPath)
+	require.NoError(t, err)
+	lis.(*net.UnixListener).SetUnlinkOnClose(false)
+	lis.Close()
+
+	ctrl := &fakeRunnerController{}
+	shutdown, err := (&requestUpdateServer{controller: ctrl}).serve(sockPath)
+	require.NoError(t, err)
+	defer shutdown()
+
+	cli := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", sockPath)
+			},
+		},
+	}
+	put := func(body string) int {
+		req, err := http.NewRequest("PUT", "http://kperf/requests", strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := cli.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, put(`[{"shares": 1, "staleList": {"version": "v1", "resource": "pods"}}]`))
+	require.Len(t, ctrl.requests, 1)
+	assert.Equal(t, "staleList", ctrl.requests[0].Type())
+
+	assert.Equal(t, http.StatusBadRequest, put(`{`))
+
+	ctrl.err = executor.ErrUpdateRequestsNotSupported
+	assert.Equal(t, http.StatusConflict, put(`[]`))
+}
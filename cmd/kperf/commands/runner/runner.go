@@ -8,16 +8,27 @@ import (
 	"encoding/json"
 
 	"fmt"
+	"maps"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Azure/kperf/api/types"
 	"github.com/Azure/kperf/cmd/kperf/commands/utils"
 	"github.com/Azure/kperf/metrics"
+	kperfrunner "github.com/Azure/kperf/pkg/runner"
 	"github.com/Azure/kperf/request"
+	"github.com/Azure/kperf/version"
 
+	"github.com/google/uuid"
 	"github.com/urfave/cli"
+	"golang.org/x/net/http2"
 	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 // Command represents runner subcommand.
@@ -26,6 +37,9 @@ var Command = cli.Command{
 	Usage: "Setup benchmark to kube-apiserver from one endpoint",
 	Subcommands: []cli.Command{
 		runCommand,
+		mergeCommand,
+		reportCommand,
+		verifyChecksumCommand,
 	},
 }
 
@@ -38,15 +52,43 @@ var runCommand = cli.Command{
 			Usage: "Path to the kubeconfig file",
 			Value: utils.DefaultKubeConfigPath,
 		},
+		cli.StringFlag{
+			Name:  "kubeconfig-context, context",
+			Usage: "The name of the kubeconfig context to use (Empty means current context)",
+		},
+		cli.StringFlag{
+			Name:  "kubeconfig-cluster, cluster",
+			Usage: "The name of the kubeconfig cluster to use, overriding the context's one",
+		},
+		cli.StringFlag{
+			Name:  "kubeconfig-user, user",
+			Usage: "The name of the kubeconfig user to use, overriding the context's one",
+		},
 		cli.IntFlag{
 			Name:  "client",
 			Usage: "Total number of HTTP clients",
 			Value: 1,
 		},
+		cli.IntFlag{
+			Name:  "concurrency-limit",
+			Usage: "Maximum number of HTTP clients sending requests concurrently, without changing the number of connections (0 means no limit)",
+		},
+		cli.IntFlag{
+			Name:  "connection-ramp-up",
+			Usage: "Put up to N connections into use one by one, every expected duration / conns, instead of all at once (0 means no ramp-up). It requires the mode's expected duration",
+		},
 		cli.StringFlag{
-			Name:     "config",
-			Usage:    "Path to the configuration file",
-			Required: true,
+			Name:  "config",
+			Usage: "Path to the configuration file. It can't be used with --config-configmap",
+		},
+		cli.StringFlag{
+			Name:  "config-configmap",
+			Usage: "Name of the ConfigMap which stores the configuration in data[\"" + configMapProfileKey + "\"]. It can't be used with --config",
+		},
+		cli.StringFlag{
+			Name:  "config-configmap-namespace",
+			Usage: "Namespace of the ConfigMap specified by --config-configmap",
+			Value: "default",
 		},
 		cli.IntFlag{
 			Name:  "conns",
@@ -62,111 +104,605 @@ var runCommand = cli.Command{
 			Name:  "rate",
 			Usage: "Maximum requests per second (Zero means no limitation). It can override corresponding value defined by --config",
 		},
+		cli.IntFlag{
+			Name:  "client-burst",
+			Usage: "Burst of each client's token bucket rate limiter (0 means default). It can override corresponding value defined by --config",
+		},
 		cli.IntFlag{
 			Name:  "total",
 			Usage: "Total number of requests. It can override corresponding value defined by --config",
 			Value: 1000,
 		},
+		cli.Int64Flag{
+			Name:  "seed",
+			Usage: "Seed for reproducible picks of requests in weighted-random mode (0 means random). It can override corresponding value defined by --config",
+		},
 		cli.StringFlag{
 			Name:  "user-agent",
-			Usage: "User Agent",
+			Usage: "User Agent. It can contain {run-id} and {index} placeholders, like kperf-runner/{run-id}/client-{index}, to distinguish each client in apiserver's metrics",
 		},
 		cli.BoolFlag{
 			Name:  "disable-http2",
 			Usage: "Disable HTTP2 protocol",
 		},
+		cli.Uint64Flag{
+			Name:  "http2-window-size",
+			Usage: "HTTP2 SETTINGS_INITIAL_WINDOW_SIZE in bytes sent to server, which is the flow-control window of each stream (0 means default)",
+		},
+		cli.Uint64Flag{
+			Name:  "http2-frame-size",
+			Usage: "HTTP2 SETTINGS_MAX_FRAME_SIZE in bytes sent to server, which is the largest frame payload to receive (0 means default)",
+		},
 		cli.IntFlag{
 			Name:  "max-retries",
 			Usage: "Retry request after receiving 429 http code (<=0 means no retry)",
 			Value: 0,
 		},
+		cli.StringFlag{
+			Name:  "connect-timeout",
+			Usage: "Timeout in seconds or duration string like 5s to establish a new connection (0 means default). It can override corresponding value defined by --config",
+		},
+		cli.StringFlag{
+			Name:  "read-timeout",
+			Usage: "Timeout in seconds or duration string like 30s to wait for response's headers (0 means no limit). It can override corresponding value defined by --config",
+		},
+		cli.StringFlag{
+			Name:  "proxy-url",
+			Usage: "Send all the requests through the given proxy, like http://proxy:3128. By default, proxy-url in kubeconfig or HTTPS_PROXY/NO_PROXY environment variables are honored. Latencies include the time spent in proxy",
+		},
+		cli.StringFlag{
+			Name:  "idle-conn-timeout",
+			Usage: "Timeout in seconds or duration string like 90s to close idle connection (0 means default). It can override corresponding value defined by --config",
+		},
+		cli.BoolFlag{
+			Name:  "disable-keep-alives",
+			Usage: "Close connection after each request. It can override corresponding value defined by --config",
+		},
+		cli.IntFlag{
+			Name:  "max-idle-conns-per-host",
+			Usage: "Maximum idle connections to keep per host (0 means default). It can override corresponding value defined by --config",
+		},
+		cli.StringFlag{
+			Name:  "tls-handshake-timeout",
+			Usage: "Timeout in seconds or duration string like 10s to wait for TLS handshake (0 means default). It can override corresponding value defined by --config",
+		},
 		cli.StringFlag{
 			Name:  "result",
 			Usage: "Path to the file which stores results",
 		},
+		cli.BoolFlag{
+			Name:  "output-append",
+			Usage: "Append result to --result file as one line of compact JSON (NDJSON) instead of overwriting it",
+		},
+		cli.BoolFlag{
+			Name:  "output-streaming",
+			Usage: "Write each completed request to --result file or stdout as one line of compact JSON (NDJSON) in real time, followed by the result as the final line",
+		},
+		cli.StringFlag{
+			Name:  "output-format",
+			Usage: "Format of result (json or table). By default, it's table if result is written to terminal, otherwise json",
+		},
+		cli.BoolFlag{
+			Name:  "quiet",
+			Usage: "Don't print the summary to stderr",
+		},
+		cli.BoolFlag{
+			Name:  "color",
+			Usage: "Highlight slow requests in table format with ANSI colors even if result isn't written to terminal",
+		},
+		cli.BoolFlag{
+			Name:  "no-color",
+			Usage: "Disable ANSI colors in table format",
+		},
 		cli.BoolFlag{
 			Name:  "raw-data",
 			Usage: "show raw letencies data in result",
 		},
+		cli.StringFlag{
+			Name:  "listen",
+			Usage: "Address to serve control API, like :8090 (Empty means disabled). It exposes GET /status, GET /result, POST /stop and GET /healthz",
+		},
+		cli.StringFlag{
+			Name:  "listen-token",
+			Usage: "Bearer token required by control API except /healthz (Empty means no auth)",
+		},
+		cli.StringFlag{
+			Name:  "request-update-socket",
+			Usage: "Unix socket path to serve PUT /requests, which replaces the requests of running weighted-random benchmark by json list (Empty means disabled)",
+		},
+		cli.StringFlag{
+			Name:  "socket",
+			Usage: "Unix socket path to accept line-based commands: rate <qps>, pause, resume, status and stop (Empty means disabled)",
+		},
+		cli.StringSliceFlag{
+			Name:  "label",
+			Usage: "Label in key=value format stamped into result's metadata (can specify multiple times)",
+		},
+		cli.StringSliceFlag{
+			Name:  "annotate",
+			Usage: "Annotation in key=value format copied into result. It overrides the same key in profile's annotations (can specify multiple times)",
+		},
+		cli.StringSliceFlag{
+			Name:  "var",
+			Usage: "Variable in key=value format substituted for ${key} in the load profile. It overrides the same key in profile's variables (can specify multiple times)",
+		},
+		cli.BoolFlag{
+			Name:  "track-per-connection",
+			Usage: "Show percentile latencies per connection in result",
+		},
+		cli.BoolFlag{
+			Name:  "show-response-size-histogram",
+			Usage: "Show percentile response sizes per request in result",
+		},
+		cli.StringFlag{
+			Name:  "request-log",
+			Usage: "Path to the file logging each request as one JSON line for debugging. It's compressed by gzip if the path ends with .gz",
+		},
 		cli.IntFlag{
+			Name:  "request-log-max-entries",
+			Usage: "Maximum number of requests logged by --request-log (0 means no limit)",
+		},
+		cli.BoolFlag{
+			Name:  "tiered-latency",
+			Usage: "Show percentile time to first byte and time to read body per request in result",
+		},
+		cli.StringFlag{
+			Name:  "histogram-buckets",
+			Usage: "Comma-separated upper bounds in seconds of latency histogram (e.g. 0.1,0.5,1). It can override corresponding value defined by --config",
+		},
+		cli.BoolFlag{
+			Name:  "cleanup-postdel",
+			Usage: "Delete all the objects created by postDel, labeled with run ID and matching postDel's nameTemplate, after benchmark",
+		},
+		cli.StringFlag{
 			Name:  "duration",
-			Usage: "Duration of the benchmark in seconds. It will be ignored if --total is set.",
-			Value: 0,
+			Usage: "Duration of the benchmark in seconds or duration string like 90s. It will be ignored if --total is set.",
+		},
+		cli.StringFlag{
+			Name:  "start-offset",
+			Usage: "Replay the time-series buckets starting at or after the offset in seconds or duration string like 30m",
+		},
+		cli.StringFlag{
+			Name:  "end-offset",
+			Usage: "Replay the time-series buckets starting before the offset in seconds or duration string like 40m (0 means the end)",
+		},
+		cli.StringFlag{
+			Name:  "max-duration",
+			Usage: "Hard limit of the benchmark's duration in seconds or duration string like 90s, regardless of the mode (0 means no limit)",
+		},
+		cli.BoolFlag{
+			Name:  "fail-fast",
+			Usage: fmt.Sprintf("Terminate the benchmark on the first failed request and exit with %d", failFastExitCode),
+		},
+		cli.StringFlag{
+			Name:  "fail-fast-type",
+			Usage: "Like --fail-fast, but only the error of the type terminates the benchmark (unknown, http, http2-protocol or connection)",
+		},
+		cli.IntFlag{
+			Name:  "warmup-total",
+			Usage: "Total number of requests sent before benchmark with the same request distribution (0 means no warmup). Only weighted-random mode is supported",
+		},
+		cli.StringFlag{
+			Name:  "kubeconfig-rotation-interval",
+			Usage: "Rebuild the clients from kubeconfig in seconds or duration string like 5m, which picks up rotated credentials, like client certificates (0 means no rotation)",
+		},
+		cli.StringFlag{
+			Name:  "warmup-duration",
+			Usage: "Duration of warmup in seconds or duration string like 30s. It will be ignored if --warmup-total is set",
+		},
+		cli.Float64Flag{
+			Name:  "warmup-rate",
+			Usage: "Maximum requests per second during warmup (0 means no limit)",
 		},
 	},
 	Action: func(cliCtx *cli.Context) error {
 		kubeCfgPath := cliCtx.String("kubeconfig")
 
-		profileCfg, err := loadConfig(cliCtx)
+		profileCfg, profileChecksum, err := loadConfig(cliCtx)
+		if err != nil {
+			return err
+		}
+
+		metadata, err := buildRunMetadata(cliCtx, profileCfg, profileChecksum)
 		if err != nil {
 			return err
 		}
 
-		clientNum := profileCfg.Spec.Conns
+		// NOTE: Resolve the target before anything else so that invalid
+		// context fails before generating any load.
+		kubeCfgOpts := kubeconfigOpts(cliCtx)
+		target, err := request.ResolveKubeconfigTarget(kubeCfgPath, kubeCfgOpts...)
+		if err != nil {
+			return fmt.Errorf("failed to load kubeconfig %s: %w", kubeCfgPath, err)
+		}
+		metadata.Context = target.Context
+		metadata.Cluster = target.Cluster
+		metadata.Server = target.Server
 
-		// Get mode-specific client options
-		clientOpts := profileCfg.Spec.ModeConfig.ConfigureClientOptions()
+		// NOTE: All the created objects carry run ID label so that they
+		// can be garbage-collected by label selector.
+		profileCfg.Spec.ObjectMeta = profileCfg.Spec.ObjectMeta.Merge(&types.ObjectMeta{
+			Labels: map[string]string{types.LabelRunID: metadata.RunID},
+		})
 
-		restClis, err := request.NewClients(kubeCfgPath,
-			clientNum,
-			request.WithClientUserAgentOpt(cliCtx.String("user-agent")),
-			request.WithClientQPSOpt(clientOpts.QPS),
-			request.WithClientContentTypeOpt(profileCfg.Spec.ContentType),
-			request.WithClientDisableHTTP2Opt(profileCfg.Spec.DisableHTTP2),
-		)
+		warmupDuration, err := secondsFlag(cliCtx, "warmup-duration")
 		if err != nil {
 			return err
 		}
 
-		stats, err := request.Schedule(context.TODO(), &profileCfg.Spec, restClis)
+		maxDuration, err := secondsFlag(cliCtx, "max-duration")
 		if err != nil {
 			return err
 		}
 
-		var f *os.File = os.Stdout
-		outputFilePath := cliCtx.String("result")
-		if outputFilePath != "" {
-			outputFileDir := filepath.Dir(outputFilePath)
+		failFast, err := failFastFromFlags(cliCtx)
+		if err != nil {
+			return err
+		}
+
+		appendMode := cliCtx.Bool("output-append")
+		outputFormat, color, err := resolveRunOutputFormat(cliCtx)
+		if err != nil {
+			return err
+		}
+
+		rotationInterval, err := secondsFlag(cliCtx, "kubeconfig-rotation-interval")
+		if err != nil {
+			return err
+		}
 
-			_, err = os.Stat(outputFileDir)
-			if err != nil && os.IsNotExist(err) {
-				err = os.MkdirAll(outputFileDir, 0750)
+		warmupSpec, err := buildWarmupSpec(&profileCfg.Spec,
+			cliCtx.Int("warmup-total"), cliCtx.Float64("warmup-rate"), warmupDuration)
+		if err != nil {
+			return err
+		}
+
+		annotations, err := buildAnnotations(cliCtx, profileCfg)
+		if err != nil {
+			return err
+		}
+
+		// Get mode-specific client options
+		clientOpts := profileCfg.Spec.ModeConfig.ConfigureClientOptions()
+		if v := cliCtx.Int("client-burst"); v > 0 {
+			clientOpts.Burst = v
+		}
+
+		http2Settings, err := http2SettingsFromFlags(cliCtx)
+		if err != nil {
+			return err
+		}
+
+		runOpts := kperfrunner.Options{
+			KubeconfigPath: kubeCfgPath,
+			ClientOpts: append(kubeCfgOpts,
+				request.WithClientUserAgentOpt(cliCtx.String("user-agent")),
+				request.WithClientRunIDOpt(metadata.RunID),
+				request.WithClientOptionsOpt(clientOpts),
+				request.WithClientHTTP2SettingsOpt(http2Settings),
+				request.WithClientProxyURLOpt(cliCtx.String("proxy-url")),
+			),
+			ScheduleOpts: []request.ScheduleOpt{
+				request.WithScheduleConcurrencyLimitOpt(cliCtx.Int("concurrency-limit")),
+				request.WithScheduleTrackPerConnectionOpt(cliCtx.Bool("track-per-connection")),
+				request.WithScheduleTrackResponseSizeOpt(cliCtx.Bool("show-response-size-histogram")),
+				request.WithScheduleTieredLatencyOpt(cliCtx.Bool("tiered-latency")),
+				request.WithScheduleMaxDurationOpt(maxDuration.Duration()),
+				request.WithScheduleConnectionRampUpOpt(cliCtx.Int("connection-ramp-up")),
+			},
+			ClientRotationInterval: rotationInterval.Duration(),
+			Warmup:                 warmupSpec,
+			WarmupScheduleOpts: []request.ScheduleOpt{
+				request.WithScheduleConcurrencyLimitOpt(cliCtx.Int("concurrency-limit")),
+			},
+			CleanupPostDel:  cliCtx.Bool("cleanup-postdel"),
+			RawData:         cliCtx.Bool("raw-data"),
+			Metadata:        metadata,
+			Annotations:     annotations,
+			ProfileChecksum: profileChecksum,
+		}
+
+		bench := &kperfrunner.Runner{}
+		if addr := cliCtx.String("listen"); addr != "" {
+			ctrlSrv := &controlServer{
+				progress: bench.Progress(),
+				token:    cliCtx.String("listen-token"),
+				partialReport: func() *types.RunnerMetricReport {
+					if report := bench.PartialReport(); report != nil {
+						return report.Metrics
+					}
+					return nil
+				},
 			}
+
+			shutdown, err := ctrlSrv.serve(addr)
+			if err != nil {
+				return err
+			}
+			defer shutdown()
+		}
+
+		if path := cliCtx.String("request-update-socket"); path != "" {
+			shutdown, err := (&requestUpdateServer{controller: bench.Progress()}).serve(path)
+			if err != nil {
+				return err
+			}
+			defer shutdown()
+		}
+
+		if path := cliCtx.String("socket"); path != "" {
+			shutdown, err := (&controlSocketServer{controller: bench.Progress()}).serve(path)
+			if err != nil {
+				return err
+			}
+			defer shutdown()
+		}
+
+		if failFast != nil {
+			runOpts.ScheduleOpts = append(runOpts.ScheduleOpts, request.WithScheduleErrorCallbackOpt(failFast.OnError))
+		}
+
+		var reqLogger *RequestLogger
+		if reqLogPath := cliCtx.String("request-log"); reqLogPath != "" {
+			reqLogger, err = NewRequestLogger(reqLogPath, cliCtx.Int("request-log-max-entries"))
 			if err != nil {
-				return fmt.Errorf("failed to ensure output's dir %s: %w", outputFileDir, err)
+				return err
+			}
+			defer reqLogger.Close()
+			runOpts.ScheduleOpts = append(runOpts.ScheduleOpts, request.WithScheduleRequestInterceptorOpt(reqLogger.Intercept))
+		}
+
+		// NOTE: The result file is opened before schedule if streaming,
+		// since each request is written once it completes.
+		var streamFile *os.File
+		var streamMetric *metrics.StreamingResponseMetric
+		if cliCtx.Bool("output-streaming") {
+			streamFile = os.Stdout
+			if outputFilePath := cliCtx.String("result"); outputFilePath != "" {
+				streamFile, err = openResultFile(outputFilePath, appendMode)
+				if err != nil {
+					return err
+				}
+				defer streamFile.Close()
+			}
+			streamMetric = metrics.NewStreamingResponseMetric(streamFile)
+			runOpts.ScheduleOpts = append(runOpts.ScheduleOpts, request.WithScheduleRequestInterceptorOpt(newStreamingInterceptor(streamMetric)))
+		}
+
+		// NOTE: The partial report is written before exiting with
+		// benchmark's error.
+		result, runErr := bench.Run(context.TODO(), *profileCfg, runOpts)
+		if result == nil {
+			return runErr
+		}
+		stats := result.Result
+		if reqLogger != nil {
+			if err := reqLogger.Close(); err != nil {
+				return err
 			}
+		}
 
-			f, err = os.Create(outputFilePath)
+		var f *os.File = os.Stdout
+		switch outputFilePath := cliCtx.String("result"); {
+		case streamFile != nil:
+			f = streamFile
+		case outputFilePath != "":
+			f, err = openResultFile(outputFilePath, appendMode)
 			if err != nil {
 				return err
 			}
 			defer f.Close()
 		}
 
-		rawDataFlagIncluded := cliCtx.Bool("raw-data")
-		err = printResponseStats(f, rawDataFlagIncluded, stats)
-		if err != nil {
-			return fmt.Errorf("error while printing response stats: %w", err)
+		if outputFormat == outputFormatTable {
+			if err := printResponseStatsTable(f, stats, color); err != nil {
+				return err
+			}
+		} else {
+			report := result.Metrics
+			if streamMetric != nil {
+				err = streamMetric.WriteSummary(report)
+			} else {
+				err = printResponseStats(f, report, appendMode)
+			}
+			if err != nil {
+				return fmt.Errorf("error while printing response stats: %w", err)
+			}
 		}
 
-		return nil
+		// NOTE: The summary is redundant if the table is already printed
+		// to stdout.
+		if !cliCtx.Bool("quiet") && (f != os.Stdout || outputFormat != outputFormatTable) {
+			if err := printSummaryTable(os.Stderr, stats); err != nil {
+				return err
+			}
+		}
+
+		if stats.FailFastError != nil {
+			return failFastExitError(stats.FailFastError)
+		}
+		return runErr
 	},
 }
 
-// loadConfig loads and validates the config.
-func loadConfig(cliCtx *cli.Context) (*types.LoadProfile, error) {
-	var profileCfg types.LoadProfile
+// resolveRunOutputFormat returns the output format and whether it's colored
+// from flags. It's resolved before benchmark so that invalid flags don't
+// throw away the result.
+//
+// NOTE: The terminal is detected on stdout unless --result is set, which is
+// regarded as a regular file.
+func resolveRunOutputFormat(cliCtx *cli.Context) (string, bool, error) {
+	outputFormat := cliCtx.String("output-format")
+	if cliCtx.Bool("output-streaming") {
+		if outputFormat == outputFormatTable {
+			return "", false, fmt.Errorf("--output-streaming requires %s output format", outputFormatJSON)
+		}
+		outputFormat = outputFormatJSON
+	}
+
+	var f *os.File
+	if cliCtx.String("result") == "" {
+		f = os.Stdout
+	}
+	format, color, err := resolveOutputFormat(f, outputFormat,
+		cliCtx.Bool("color"), cliCtx.Bool("no-color"))
+	if err != nil {
+		return "", false, err
+	}
+
+	if format == outputFormatTable && cliCtx.Bool("output-append") {
+		return "", false, fmt.Errorf("--output-append requires %s output format", outputFormatJSON)
+	}
+	return format, color, nil
+}
+
+// failFastExitCode is the exit code if the benchmark is terminated by
+// --fail-fast or --fail-fast-type.
+const failFastExitCode = 2
+
+// failFastFromFlags returns the error callback terminating the benchmark on
+// the first error of --fail-fast-type, or any error by --fail-fast. It
+// returns nil if both are unset.
+func failFastFromFlags(cliCtx *cli.Context) (*request.FailFast, error) {
+	if v := cliCtx.String("fail-fast-type"); v != "" {
+		errType := types.ResponseErrorType(v)
+		if err := errType.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid --fail-fast-type: %w", err)
+		}
+		return &request.FailFast{MaxErrors: 1, Type: errType}, nil
+	}
+	if cliCtx.Bool("fail-fast") {
+		return &request.FailFast{MaxErrors: 1}, nil
+	}
+	return nil, nil
+}
+
+// failFastExitError returns the error with failFastExitCode, which shows
+// the URL, type and timestamp of the error terminating the benchmark.
+func failFastExitError(respErr *types.ResponseError) error {
+	detail := string(respErr.Type)
+	switch {
+	case respErr.Type == types.ResponseErrorTypeHTTP:
+		detail = fmt.Sprintf("%s (code %d)", respErr.Type, respErr.Code)
+	case respErr.Message != "":
+		detail = fmt.Sprintf("%s (%s)", respErr.Type, respErr.Message)
+	}
+	return cli.NewExitError(
+		fmt.Sprintf("fail fast: %s %s failed at %s with %s error",
+			respErr.Method, respErr.URL, respErr.Timestamp.UTC().Format(time.RFC3339Nano), detail),
+		failFastExitCode,
+	)
+}
+
+// buildWarmupSpec returns the load profile spec for warmup, which sends the
+// same request distribution with the given total, rate and duration. It
+// returns nil if warmup is disabled.
+func buildWarmupSpec(spec *types.LoadProfileSpec, total int, rate float64, duration types.Seconds) (*types.LoadProfileSpec, error) {
+	if total < 0 || duration < 0 || rate < 0 {
+		return nil, fmt.Errorf("warmup total, rate and duration require >= 0")
+	}
+	if total == 0 && duration == 0 {
+		if rate > 0 {
+			return nil, fmt.Errorf("warmup rate requires warmup total or duration")
+		}
+		return nil, nil
+	}
+
+	modeCfg, ok := spec.ModeConfig.(*types.WeightedRandomConfig)
+	if !ok {
+		return nil, fmt.Errorf("warmup only supports %s mode, got %s", types.ModeWeightedRandom, spec.Mode)
+	}
+
+	warmupModeCfg := *modeCfg
+	warmupModeCfg.Total = total
+	warmupModeCfg.Rate = rate
+	warmupModeCfg.Duration = duration
+	if err := warmupModeCfg.Validate(nil); err != nil {
+		return nil, fmt.Errorf("invalid warmup config: %w", err)
+	}
+
+	warmupSpec := *spec
+	warmupSpec.ModeConfig = &warmupModeCfg
+	return &warmupSpec, nil
+}
+
+// configMapProfileKey is the key of ConfigMap's data which stores the load
+// profile.
+const configMapProfileKey = "profile.yaml"
+
+// kubeconfigOpts returns the kubeconfig's context, cluster and user overrides
+// from flags. They apply to all the clients, including the one loading
+// config from ConfigMap.
+func kubeconfigOpts(cliCtx *cli.Context) []request.ClientCfgOpt {
+	return []request.ClientCfgOpt{
+		request.WithClientContextOpt(cliCtx.String("kubeconfig-context")),
+		request.WithClientClusterOpt(cliCtx.String("kubeconfig-cluster")),
+		request.WithClientUserOpt(cliCtx.String("kubeconfig-user")),
+	}
+}
+
+// http2SettingsFromFlags returns the HTTP2 settings from flags. Zero value
+// keeps the default.
+func http2SettingsFromFlags(cliCtx *cli.Context) (map[http2.SettingID]uint32, error) {
+	settings := map[http2.SettingID]uint32{}
+	for flag, id := range map[string]http2.SettingID{
+		"http2-window-size": http2.SettingInitialWindowSize,
+		"http2-frame-size":  http2.SettingMaxFrameSize,
+	} {
+		v := cliCtx.Uint64(flag)
+		if v > math.MaxUint32 {
+			return nil, fmt.Errorf("invalid --%s value: %d, requires <= %d", flag, v, uint32(math.MaxUint32))
+		}
+		if v > 0 {
+			settings[id] = uint32(v)
+		}
+	}
+	return settings, nil
+}
+
+// loadConfig loads and validates the config. It also returns the checksum
+// of the config before variable substitution and CLI overrides, so that it
+// identifies the profile template and can be verified against the file.
+func loadConfig(cliCtx *cli.Context) (*types.LoadProfile, string, error) {
+	var profileCfg *types.LoadProfile
 
 	cfgPath := cliCtx.String("config")
+	cmName := cliCtx.String("config-configmap")
+
+	switch {
+	case cfgPath != "" && cmName != "":
+		return nil, "", fmt.Errorf("--config and --config-configmap are mutually exclusive")
+	case cfgPath == "" && cmName == "":
+		return nil, "", fmt.Errorf("required one of --config or --config-configmap")
+	case cmName != "":
+		var err error
+		profileCfg, err = loadConfigFromConfigMap(context.TODO(),
+			cliCtx.String("kubeconfig"), kubeconfigOpts(cliCtx), cliCtx.String("config-configmap-namespace"), cmName)
+		if err != nil {
+			return nil, "", err
+		}
+	default:
+		cfgInRaw, err := os.ReadFile(cfgPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read file %s: %w", cfgPath, err)
+		}
+
+		profileCfg = &types.LoadProfile{}
+		if err := yaml.Unmarshal(cfgInRaw, profileCfg); err != nil {
+			return nil, "", fmt.Errorf("failed to unmarshal %s from yaml format: %w", cfgPath, err)
+		}
+	}
 
-	cfgInRaw, err := os.ReadFile(cfgPath)
+	checksum, err := profileCfg.Checksum()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file %s: %w", cfgPath, err)
+		return nil, "", err
 	}
 
-	if err := yaml.Unmarshal(cfgInRaw, &profileCfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal %s from yaml format: %w", cfgPath, err)
+	vars, err := utils.KeyValueMap(cliCtx.StringSlice("var"))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid --var: %w", err)
 	}
+	profileCfg = types.ExpandVariables(profileCfg, vars)
 
 	// Apply CLI overrides to common fields
 	if v := "conns"; cliCtx.IsSet(v) || profileCfg.Spec.Conns == 0 {
@@ -175,6 +711,12 @@ func loadConfig(cliCtx *cli.Context) (*types.LoadProfile, error) {
 	if v := "client"; cliCtx.IsSet(v) || profileCfg.Spec.Client == 0 {
 		profileCfg.Spec.Client = cliCtx.Int(v)
 	}
+	if v := "concurrency-limit"; cliCtx.IsSet(v) && cliCtx.Int(v) <= 0 {
+		return nil, "", fmt.Errorf("--concurrency-limit requires > 0: %v", cliCtx.Int(v))
+	}
+	if v := "connection-ramp-up"; cliCtx.IsSet(v) && cliCtx.Int(v) <= 0 {
+		return nil, "", fmt.Errorf("--connection-ramp-up requires > 0: %v", cliCtx.Int(v))
+	}
 	if v := "content-type"; cliCtx.IsSet(v) || profileCfg.Spec.ContentType == "" {
 		profileCfg.Spec.ContentType = types.ContentType(cliCtx.String(v))
 	}
@@ -184,61 +726,249 @@ func loadConfig(cliCtx *cli.Context) (*types.LoadProfile, error) {
 	if v := "max-retries"; cliCtx.IsSet(v) {
 		profileCfg.Spec.MaxRetries = cliCtx.Int(v)
 	}
+	if v := "connect-timeout"; cliCtx.IsSet(v) {
+		d, err := secondsFlag(cliCtx, v)
+		if err != nil {
+			return nil, "", err
+		}
+		profileCfg.Spec.ConnectTimeoutSeconds = d
+	}
+	if v := "read-timeout"; cliCtx.IsSet(v) {
+		d, err := secondsFlag(cliCtx, v)
+		if err != nil {
+			return nil, "", err
+		}
+		profileCfg.Spec.ReadTimeoutSeconds = d
+	}
+	transport := &types.TransportSpec{}
+	if profileCfg.Spec.Transport != nil {
+		transport = profileCfg.Spec.Transport
+	}
+	if v := "idle-conn-timeout"; cliCtx.IsSet(v) {
+		d, err := secondsFlag(cliCtx, v)
+		if err != nil {
+			return nil, "", err
+		}
+		transport.IdleConnTimeoutSeconds = d
+	}
+	if v := "disable-keep-alives"; cliCtx.IsSet(v) {
+		transport.DisableKeepAlives = cliCtx.Bool(v)
+	}
+	if v := "max-idle-conns-per-host"; cliCtx.IsSet(v) {
+		transport.MaxIdleConnsPerHost = cliCtx.Int(v)
+	}
+	if v := "tls-handshake-timeout"; cliCtx.IsSet(v) {
+		d, err := secondsFlag(cliCtx, v)
+		if err != nil {
+			return nil, "", err
+		}
+		transport.TLSHandshakeTimeoutSeconds = d
+	}
+	if *transport != (types.TransportSpec{}) {
+		profileCfg.Spec.Transport = transport
+	}
+	if v := "histogram-buckets"; cliCtx.IsSet(v) {
+		buckets, err := parseHistogramBuckets(cliCtx.String(v))
+		if err != nil {
+			return nil, "", err
+		}
+		profileCfg.Spec.HistogramBuckets = buckets
+	}
+
+	if profileCfg.Spec.ModeConfig == nil {
+		return nil, "", fmt.Errorf("mode and modeConfig are required")
+	}
 
 	// Apply mode-specific CLI flag overrides
+	if err := checkModeFlags(cliCtx, &profileCfg.Spec); err != nil {
+		return nil, "", err
+	}
 	modeOverrides := types.BuildOverridesFromCLI(profileCfg.Spec.ModeConfig, cliCtx)
 	if len(modeOverrides) > 0 {
 		if err := profileCfg.Spec.ModeConfig.ApplyOverrides(modeOverrides); err != nil {
-			return nil, fmt.Errorf("failed to apply config overrides: %w", err)
+			return nil, "", fmt.Errorf("failed to apply config overrides: %w", err)
 		}
 	}
 
+	// NOTE: The runner group sets the runner's index in environment so
+	// that the runners sharing one profile use disjoint key space shards.
+	if raw := strings.TrimSpace(os.Getenv(types.RunnerIndexEnv)); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid %s %q: %w", types.RunnerIndexEnv, raw, err)
+		}
+		types.SetDefaultKeySpaceOffset(&profileCfg.Spec, offset)
+	}
+
 	// Mode-specific validation with defaults
 	defaultOverrides := map[string]interface{}{
 		"total": cliCtx.Int("total"),
 	}
 	if err := profileCfg.Spec.ModeConfig.Validate(defaultOverrides); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
+		return nil, "", fmt.Errorf("config validation failed: %w", err)
 	}
 
 	if err := profileCfg.Validate(); err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	return profileCfg, checksum, nil
+}
+
+// modeFlags are the flags which override fields of mode's config. They are
+// applied by BuildOverridesFromCLI if the mode supports them.
+var modeFlags = []string{"rate", "total", "duration", "seed", "start-offset", "end-offset"}
+
+// checkModeFlags returns error if any of modeFlags is set but the mode
+// doesn't support it, so that the flag isn't ignored silently.
+func checkModeFlags(cliCtx *cli.Context, spec *types.LoadProfileSpec) error {
+	fields := map[string]bool{}
+	for _, field := range spec.ModeConfig.GetOverridableFields() {
+		fields[field.Name] = true
+	}
+
+	for _, name := range modeFlags {
+		if cliCtx.IsSet(name) && !fields[name] {
+			return fmt.Errorf("--%s is not overridable for %s mode", name, spec.Mode)
+		}
+	}
+	return nil
+}
+
+// loadConfigFromConfigMap loads the config from data["profile.yaml"] of the
+// given ConfigMap. The kubeconfig is loaded with kubeCfgOpts in the same way
+// as the runner's clients. It uses in-cluster config if kubeCfgPath is empty.
+func loadConfigFromConfigMap(ctx context.Context, kubeCfgPath string, kubeCfgOpts []request.ClientCfgOpt, namespace, name string) (*types.LoadProfile, error) {
+	restCfg, err := request.BuildRestConfig(kubeCfgPath, kubeCfgOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rest config: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configmap %s/%s: %w", namespace, name, err)
+	}
+
+	data, ok := cm.Data[configMapProfileKey]
+	if !ok {
+		return nil, fmt.Errorf("configmap %s/%s doesn't have key %s", namespace, name, configMapProfileKey)
+	}
+
+	var profileCfg types.LoadProfile
+	if err := yaml.Unmarshal([]byte(data), &profileCfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal configmap %s/%s from yaml format: %w", namespace, name, err)
 	}
 	return &profileCfg, nil
 }
 
-// printResponseStats prints types.RunnerMetricReport into underlying file.
-func printResponseStats(f *os.File, rawDataFlagIncluded bool, stats *request.Result) error {
-	output := types.RunnerMetricReport{
-		Total:              stats.Total,
-		ErrorStats:         metrics.BuildErrorStatsGroupByType(stats.Errors),
-		Duration:           stats.Duration.String(),
-		TotalReceivedBytes: stats.TotalReceivedBytes,
+// buildRunMetadata builds the provenance of this run. The profileChecksum is
+// the one returned by loadConfig. The start and end time are filled by
+// caller.
+func buildRunMetadata(cliCtx *cli.Context, profileCfg *types.LoadProfile, profileChecksum string) (*types.RunMetadata, error) {
+	labels, err := utils.KeyValueMap(cliCtx.StringSlice("label"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --label: %w", err)
+	}
+	if len(labels) == 0 {
+		labels = nil
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+
+	runID := uuid.New().String()
+	return &types.RunMetadata{
+		RunID:       runID,
+		Version:     version.Version,
+		Revision:    version.Revision,
+		ProfileHash: profileChecksum,
+		Hostname:    hostname,
+		UserAgent:   request.ResolveUserAgent(cliCtx.String("user-agent"), runID),
+		Description: profileCfg.Description,
+		Spec:        &profileCfg.Spec,
+		Labels:      labels,
+	}, nil
+}
 
-		PercentileLatenciesByURL: map[string][][2]float64{},
+// buildAnnotations merges profile's annotations with --annotate flags. The
+// flags take precedence.
+func buildAnnotations(cliCtx *cli.Context, profileCfg *types.LoadProfile) (map[string]string, error) {
+	overrides, err := utils.KeyValueMap(cliCtx.StringSlice("annotate"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --annotate: %w", err)
 	}
 
-	total := 0
-	for _, latencies := range stats.LatenciesByURL {
-		total += len(latencies)
+	annotations := make(map[string]string, len(profileCfg.Annotations)+len(overrides))
+	maps.Copy(annotations, profileCfg.Annotations)
+	maps.Copy(annotations, overrides)
+	if len(annotations) == 0 {
+		return nil, nil
 	}
-	latencies := make([]float64, 0, total)
-	for _, l := range stats.LatenciesByURL {
-		latencies = append(latencies, l...)
+	return annotations, nil
+}
+
+// secondsFlag parses the flag which is either seconds or duration string
+// like 90s. It returns zero if the flag is empty.
+func secondsFlag(cliCtx *cli.Context, name string) (types.Seconds, error) {
+	value := cliCtx.String(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := types.ParseSeconds(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --%s: %w", name, err)
+	}
+	return d, nil
+}
+
+// parseHistogramBuckets parses comma-separated bounds into []float64.
+func parseHistogramBuckets(value string) ([]float64, error) {
+	strs := strings.Split(value, ",")
+
+	buckets := make([]float64, 0, len(strs))
+	for _, str := range strs {
+		b, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid histogram bucket %q: %w", str, err)
+		}
+		buckets = append(buckets, b)
 	}
-	output.PercentileLatencies = metrics.BuildPercentileLatencies(latencies)
+	return buckets, nil
+}
 
-	for u, l := range stats.LatenciesByURL {
-		output.PercentileLatenciesByURL[u] = metrics.BuildPercentileLatencies(l)
+// openResultFile opens the file to store result. The file is truncated
+// unless appendMode is true.
+func openResultFile(fpath string, appendMode bool) (*os.File, error) {
+	dir := filepath.Dir(fpath)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to ensure output's dir %s: %w", dir, err)
 	}
 
-	if rawDataFlagIncluded {
-		output.LatenciesByURL = stats.LatenciesByURL
-		output.Errors = stats.Errors
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendMode {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
 	}
+	f, err := os.OpenFile(fpath, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", fpath, err)
+	}
+	return f, nil
+}
 
+// printResponseStats prints types.RunnerMetricReport into underlying file.
+// In append mode, it's compact JSON in one line so that the file is NDJSON.
+func printResponseStats(f *os.File, output *types.RunnerMetricReport, appendMode bool) error {
 	encoder := json.NewEncoder(f)
-	encoder.SetIndent("", "  ")
+	if !appendMode {
+		encoder.SetIndent("", "  ")
+	}
 
 	err := encoder.Encode(output)
 	if err != nil {
@@ -0,0 +1,688 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/metrics"
+	kperfrunner "github.com/Azure/kperf/pkg/runner"
+	"github.com/Azure/kperf/request"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+func TestMergeRunnerMetricReportsWithMetadata(t *testing.T) {
+	endTime := time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC)
+	reportA := types.RunnerMetricReport{
+		SchemaVersion: types.RunnerMetricReportSchemaVersion,
+		Metadata: &types.RunMetadata{
+			RunID:     "run-a",
+			StartTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   &endTime,
+			Version:   "v0.1.0",
+			Spec: &types.LoadProfileSpec{
+				Conns:  1,
+				Client: 1,
+				Mode:   types.ModeWeightedRandom,
+				ModeConfig: &types.WeightedRandomConfig{
+					Rate:  10,
+					Total: 1,
+					Requests: []*types.WeightedRequest{
+						{
+							Shares: 1,
+							List: &types.RequestList{
+								KubeGroupVersionResource: types.KubeGroupVersionResource{
+									Version:  "v1",
+									Resource: "pods",
+								},
+								Consistency: types.ConsistencyStale,
+							},
+						},
+					},
+				},
+			},
+			Labels: map[string]string{"env": "a"},
+		},
+		Total:              1,
+		Duration:           "1m0s",
+		LatenciesByURL:     map[string][]float64{"GET /api/v1/pods": {0.1}},
+		BenchmarkStartTime: "2024-01-01T00:00:00Z",
+		BenchmarkEndTime:   "2024-01-01T00:01:00Z",
+	}
+
+	// The metadata should survive the round trip of result file.
+	data, err := json.Marshal(reportA)
+	require.NoError(t, err)
+	var decoded types.RunnerMetricReport
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, reportA.Metadata, decoded.Metadata)
+
+	reportB := types.RunnerMetricReport{
+		SchemaVersion:      types.RunnerMetricReportSchemaVersion,
+		Total:              1,
+		Duration:           "30s",
+		LatenciesByURL:     map[string][]float64{"GET /api/v1/pods": {0.2}},
+		BenchmarkStartTime: "2024-01-01T00:00:45Z",
+		BenchmarkEndTime:   "2024-01-01T00:01:15Z",
+	}
+
+	merged, err := mergeRunnerMetricReports([]string{"a.json", "b.json"}, []types.RunnerMetricReport{decoded, reportB})
+	require.NoError(t, err)
+	assert.Equal(t, []types.MergedReport{
+		{Source: "a.json", RunID: "run-a", Labels: map[string]string{"env": "a"}, Total: 1, Duration: "1m0s"},
+		{Source: "b.json", Total: 1, Duration: "30s"},
+	}, merged.MergedReports)
+	assert.Equal(t, "2024-01-01T00:00:00Z", merged.BenchmarkStartTime)
+	assert.Equal(t, "2024-01-01T00:01:15Z", merged.BenchmarkEndTime)
+}
+
+func TestMergeRunnerMetricReportsAllFailedRawData(t *testing.T) {
+	// All the requests of reportA failed so that its raw data only has
+	// errors, and neither report has histograms.
+	reportA := types.RunnerMetricReport{
+		Total:      2,
+		ErrorCount: 2,
+		Duration:   "1m0s",
+		Errors: []types.ResponseError{
+			{Method: "GET", URL: "/api/v1/pods", Type: types.ResponseErrorTypeHTTP, Code: 500},
+			{Method: "GET", URL: "/api/v1/pods", Type: types.ResponseErrorTypeHTTP, Code: 500},
+		},
+	}
+	reportB := types.RunnerMetricReport{
+		Total:          1,
+		Duration:       "30s",
+		LatenciesByURL: map[string][]float64{"GET /api/v1/pods": {0.2}},
+	}
+
+	merged, err := mergeRunnerMetricReports([]string{"a.json", "b.json"}, []types.RunnerMetricReport{reportA, reportB})
+	require.NoError(t, err)
+	assert.Equal(t, 3, merged.Total)
+	assert.Len(t, merged.Errors, 2)
+	assert.Equal(t, map[string][]float64{"GET /api/v1/pods": {0.2}}, merged.LatenciesByURL)
+	assert.Equal(t, metrics.BuildPercentileLatencies([]float64{0.2}), merged.PercentileLatencies)
+}
+
+func TestLoadConfigFromConfigMap(t *testing.T) {
+	profile := `version: 1
+description: from configmap
+spec:
+  conns: 2
+  client: 4
+  contentType: json
+  mode: weighted-random
+  modeConfig:
+    rate: 10
+    total: 100
+    requests:
+    - staleList:
+        version: v1
+        resource: pods
+      shares: 100
+`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/namespaces/kperf/configmaps/profile":
+			data, _ := json.Marshal(map[string]string{configMapProfileKey: profile})
+			fmt.Fprintf(w, `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"profile","namespace":"kperf"},"data":%s}`, data)
+		case "/api/v1/namespaces/kperf/configmaps/nokey":
+			fmt.Fprint(w, `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"nokey","namespace":"kperf"},"data":{"other":"x"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+		}
+	}))
+	defer srv.Close()
+
+	// The current context points to nowhere, so the ConfigMap can only be
+	// loaded with the context override.
+	kubeCfgPath := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeCfgPath, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+- name: unreachable
+  cluster:
+    server: http://127.0.0.1:1
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+- name: unreachable
+  context:
+    cluster: unreachable
+    user: test
+current-context: unreachable
+users:
+- name: test
+  user: {}
+`, srv.URL)), 0600))
+	kubeCfgOpts := []request.ClientCfgOpt{request.WithClientContextOpt("test")}
+
+	cfg, err := loadConfigFromConfigMap(context.TODO(), kubeCfgPath, kubeCfgOpts, "kperf", "profile")
+	require.NoError(t, err)
+	assert.Equal(t, "from configmap", cfg.Description)
+	assert.Equal(t, 2, cfg.Spec.Conns)
+	assert.Equal(t, 4, cfg.Spec.Client)
+	assert.Equal(t, types.ModeWeightedRandom, cfg.Spec.Mode)
+
+	_, err = loadConfigFromConfigMap(context.TODO(), kubeCfgPath, kubeCfgOpts, "kperf", "nokey")
+	assert.ErrorContains(t, err, "doesn't have key")
+
+	_, err = loadConfigFromConfigMap(context.TODO(), kubeCfgPath, kubeCfgOpts, "kperf", "missing")
+	assert.Error(t, err)
+
+	_, err = loadConfigFromConfigMap(context.TODO(), kubeCfgPath, nil, "kperf", "profile")
+	assert.Error(t, err)
+
+	_, err = loadConfigFromConfigMap(context.TODO(), kubeCfgPath,
+		[]request.ClientCfgOpt{request.WithClientContextOpt("test"), request.WithClientClusterOpt("unreachable")},
+		"kperf", "profile")
+	assert.Error(t, err)
+
+	_, err = loadConfigFromConfigMap(context.TODO(), kubeCfgPath,
+		[]request.ClientCfgOpt{request.WithClientClusterOpt("test")}, "kperf", "profile")
+	require.NoError(t, err)
+}
+
+func TestWarmup(t *testing.T) {
+	var received int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&received, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","items":[]}`)
+	}))
+	defer srv.Close()
+
+	spec := &types.LoadProfileSpec{
+		Conns:       1,
+		Client:      2,
+		ContentType: types.ContentTypeJSON,
+		Mode:        types.ModeWeightedRandom,
+		ModeConfig: &types.WeightedRandomConfig{
+			Total: 10,
+			Requests: []*types.WeightedRequest{
+				{
+					Shares: 1,
+					StaleList: &types.RequestList{
+						KubeGroupVersionResource: types.KubeGroupVersionResource{
+							Version:  "v1",
+							Resource: "pods",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	warmupSpec, err := buildWarmupSpec(spec, 0, 0, 0)
+	require.NoError(t, err)
+	assert.Nil(t, warmupSpec)
+
+	_, err = buildWarmupSpec(spec, 0, 50, 0)
+	assert.Error(t, err)
+
+	_, err = buildWarmupSpec(&types.LoadProfileSpec{Mode: types.ModeTimeSeries, ModeConfig: &types.TimeSeriesConfig{}}, 5, 0, 0)
+	assert.Error(t, err)
+
+	warmupSpec, err = buildWarmupSpec(spec, 5, 50, 10)
+	require.NoError(t, err)
+	warmupModeCfg := warmupSpec.ModeConfig.(*types.WeightedRandomConfig)
+	assert.Equal(t, 5, warmupModeCfg.Total)
+	assert.Equal(t, float64(50), warmupModeCfg.Rate)
+	assert.Equal(t, types.Seconds(0), warmupModeCfg.Duration)
+	assert.Equal(t, spec.ModeConfig.(*types.WeightedRandomConfig).Requests, warmupModeCfg.Requests)
+	assert.Equal(t, 10, spec.ModeConfig.(*types.WeightedRandomConfig).Total)
+
+	warmupSpec, err = buildWarmupSpec(spec, 5, 0, 0)
+	require.NoError(t, err)
+
+	report, err := (&kperfrunner.Runner{}).Run(context.TODO(), types.LoadProfile{Version: 1, Spec: *spec}, kperfrunner.Options{
+		KubeconfigPath: newTestKubeconfig(t, srv.URL),
+		Warmup:         warmupSpec,
+	})
+	require.NoError(t, err)
+
+	// The benchmark result doesn't include warmup latencies.
+	latencies := 0
+	for _, l := range report.Result.LatenciesByURL {
+		latencies += len(l)
+	}
+	assert.Equal(t, 10, latencies)
+	assert.Equal(t, int32(15), atomic.LoadInt32(&received))
+}
+
+func TestRunFailFast(t *testing.T) {
+	var received int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	kubeCfgPath := newTestKubeconfig(t, srv.URL)
+	cfgPath := writeWeightedRandomProfile(t, staleListRequest)
+	run := runCommand.Action.(func(*cli.Context) error)
+
+	for name, tc := range map[string]struct {
+		args           []string
+		expectedErrors int
+		expectedErr    string
+	}{
+		"fail-fast": {
+			args:           []string{"--fail-fast"},
+			expectedErrors: 1,
+		},
+		"fail-fast-type matched": {
+			args:           []string{"--fail-fast-type", "http"},
+			expectedErrors: 1,
+		},
+		"fail-fast-type unmatched": {
+			args:           []string{"--fail-fast-type", "connection"},
+			expectedErrors: 5,
+		},
+		"fail-fast-type invalid": {
+			args:        []string{"--fail-fast-type", "dns"},
+			expectedErr: "invalid --fail-fast-type",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			atomic.StoreInt32(&received, 0)
+			resultPath := filepath.Join(t.TempDir(), "result.json")
+
+			err := run(newRunCliCtx(t, append([]string{
+				"--kubeconfig", kubeCfgPath,
+				"--config", cfgPath,
+				"--total", "5",
+				"--rate", "0",
+				"--result", resultPath,
+				"--quiet",
+			}, tc.args...)...))
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+
+			data, rerr := os.ReadFile(resultPath)
+			require.NoError(t, rerr)
+			var report types.RunnerMetricReport
+			require.NoError(t, json.Unmarshal(data, &report))
+			assert.Equal(t, tc.expectedErrors, report.ErrorCount)
+			assert.Equal(t, int32(tc.expectedErrors), atomic.LoadInt32(&received))
+
+			if tc.expectedErrors > 1 {
+				assert.NoError(t, err)
+				assert.Nil(t, report.FailFastError)
+				return
+			}
+
+			var exitErr cli.ExitCoder
+			require.ErrorAs(t, err, &exitErr)
+			assert.Equal(t, failFastExitCode, exitErr.ExitCode())
+			assert.Contains(t, err.Error(), "/api/v1/pods")
+			assert.Contains(t, err.Error(), "http (code 500) error")
+
+			assert.True(t, report.TerminatedEarly)
+			require.NotNil(t, report.FailFastError)
+			assert.Equal(t, types.ResponseErrorTypeHTTP, report.FailFastError.Type)
+			assert.Equal(t, http.StatusInternalServerError, report.FailFastError.Code)
+		})
+	}
+}
+
+func TestRunInvalidOutputFlags(t *testing.T) {
+	var received int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&received, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","items":[]}`)
+	}))
+	defer srv.Close()
+
+	kubeCfgPath := newTestKubeconfig(t, srv.URL)
+	cfgPath := writeWeightedRandomProfile(t, staleListRequest)
+	run := runCommand.Action.(func(*cli.Context) error)
+
+	for name, tc := range map[string]struct {
+		args        []string
+		expectedErr string
+	}{
+		"unsupported output format": {
+			args:        []string{"--output-format", "yaml"},
+			expectedErr: "unsupported output format",
+		},
+		"color with no color": {
+			args:        []string{"--color", "--no-color"},
+			expectedErr: "--color and --no-color can't be used together",
+		},
+		"append with table": {
+			args:        []string{"--output-format", "table", "--output-append"},
+			expectedErr: "--output-append requires json output format",
+		},
+		"streaming with table": {
+			args:        []string{"--output-format", "table", "--output-streaming"},
+			expectedErr: "--output-streaming requires json output format",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := run(newRunCliCtx(t, append([]string{
+				"--kubeconfig", kubeCfgPath,
+				"--config", cfgPath,
+				"--total", "5",
+				"--result", filepath.Join(t.TempDir(), "result.json"),
+			}, tc.args...)...))
+			assert.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+
+	// The invalid flags fail before sending any request.
+	assert.Equal(t, int32(0), atomic.LoadInt32(&received))
+}
+
+// newTestKubeconfig creates kubeconfig file which points to the given server.
+func newTestKubeconfig(t *testing.T, serverURL string) string {
+	kubeCfgPath := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeCfgPath, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user: {}
+`, serverURL)), 0600))
+	return kubeCfgPath
+}
+
+func TestPrintResponseStatsAppend(t *testing.T) {
+	fpath := filepath.Join(t.TempDir(), "results", "result.ndjson")
+
+	for i := 0; i < 3; i++ {
+		f, err := openResultFile(fpath, true)
+		require.NoError(t, err)
+		require.NoError(t, printResponseStats(f, &types.RunnerMetricReport{Total: i + 1, Duration: "1s"}, true))
+		require.NoError(t, f.Close())
+	}
+
+	data, err := os.ReadFile(fpath)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 3)
+	for i, line := range lines {
+		var report types.RunnerMetricReport
+		require.NoError(t, json.Unmarshal([]byte(line), &report))
+		assert.Equal(t, i+1, report.Total)
+	}
+
+	// Without append mode, the file is overwritten with indented JSON.
+	f, err := openResultFile(fpath, false)
+	require.NoError(t, err)
+	require.NoError(t, printResponseStats(f, &types.RunnerMetricReport{Total: 10}, false))
+	require.NoError(t, f.Close())
+
+	data, err = os.ReadFile(fpath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n  \"total\": 10,")
+
+	var report types.RunnerMetricReport
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, 10, report.Total)
+}
+
+func TestLoadConfigModeFlags(t *testing.T) {
+	dir := t.TempDir()
+
+	weightedRandomPath := writeWeightedRandomProfile(t, staleListRequest)
+
+	timeSeriesPath := filepath.Join(dir, "time-series.yaml")
+	require.NoError(t, os.WriteFile(timeSeriesPath, []byte(`
+version: 1
+spec:
+  conns: 1
+  client: 1
+  contentType: json
+  mode: time-series
+  modeConfig:
+    interval: 1s
+    buckets:
+    - startTime: 0
+      requests:
+      - method: GET
+        version: v1
+        resource: pods
+        namespace: default
+        name: a
+`), 0600))
+
+	t.Run("weighted-random with default total", func(t *testing.T) {
+		profile, _, err := loadConfig(newRunCliCtx(t, "--config", weightedRandomPath))
+		require.NoError(t, err)
+		cfg := profile.Spec.ModeConfig.(*types.WeightedRandomConfig)
+		assert.Equal(t, float64(10), cfg.Rate)
+		assert.Equal(t, 1000, cfg.Total)
+	})
+
+	t.Run("weighted-random with overrides", func(t *testing.T) {
+		profile, _, err := loadConfig(newRunCliCtx(t, "--config", weightedRandomPath, "--rate", "20", "--total", "5"))
+		require.NoError(t, err)
+		cfg := profile.Spec.ModeConfig.(*types.WeightedRandomConfig)
+		assert.Equal(t, float64(20), cfg.Rate)
+		assert.Equal(t, 5, cfg.Total)
+	})
+
+	t.Run("time-series", func(t *testing.T) {
+		profile, _, err := loadConfig(newRunCliCtx(t, "--config", timeSeriesPath, "--conns", "2"))
+		require.NoError(t, err)
+		assert.Equal(t, types.ModeTimeSeries, profile.Spec.Mode)
+		assert.Equal(t, 2, profile.Spec.Conns)
+		cfg := profile.Spec.ModeConfig.(*types.TimeSeriesConfig)
+		assert.Equal(t, "1s", cfg.Interval)
+		assert.Len(t, cfg.Buckets, 1)
+	})
+
+	t.Run("time-series with window", func(t *testing.T) {
+		profile, _, err := loadConfig(newRunCliCtx(t, "--config", timeSeriesPath, "--end-offset", "1m"))
+		require.NoError(t, err)
+		cfg := profile.Spec.ModeConfig.(*types.TimeSeriesConfig)
+		assert.Equal(t, types.Seconds(60), cfg.EndOffset)
+
+		_, _, err = loadConfig(newRunCliCtx(t, "--config", timeSeriesPath, "--start-offset", "1m"))
+		assert.ErrorContains(t, err, "selects no bucket")
+
+		_, _, err = loadConfig(newRunCliCtx(t, "--config", weightedRandomPath, "--start-offset", "1m"))
+		assert.ErrorContains(t, err, "--start-offset is not overridable for weighted-random mode")
+	})
+
+	for _, args := range [][]string{
+		{"--rate", "10"},
+		{"--total", "10"},
+		{"--duration", "10s"},
+	} {
+		t.Run("time-series with "+args[0], func(t *testing.T) {
+			_, _, err := loadConfig(newRunCliCtx(t, append([]string{"--config", timeSeriesPath}, args...)...))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), args[0]+" is not overridable for time-series mode")
+		})
+	}
+}
+
+func TestLoadConfigVariables(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "profile.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`
+version: 1
+variables:
+  NS: default
+spec:
+  conns: 1
+  client: 1
+  contentType: json
+  mode: weighted-random
+  modeConfig:
+    rate: 10
+    requests:
+    - shares: 1
+      get:
+        version: v1
+        resource: pods
+        namespace: ${NS}
+        name: ${NAME}
+`), 0600))
+
+	profile, checksum, err := loadConfig(newRunCliCtx(t, "--config", cfgPath, "--var", "NAME=a", "--var", "NS=kperf"))
+	require.NoError(t, err)
+	get := profile.Spec.ModeConfig.(*types.WeightedRandomConfig).Requests[0].Get
+	assert.Equal(t, "kperf", get.Namespace)
+	assert.Equal(t, "a", get.Name)
+
+	// The checksum covers the template only, which is before substitution
+	// and CLI overrides, so that it matches the file.
+	other, expected, err := loadConfig(newRunCliCtx(t, "--config", cfgPath, "--var", "NAME=b", "--conns", "3"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, checksum)
+	assert.Equal(t, "b", other.Spec.ModeConfig.(*types.WeightedRandomConfig).Requests[0].Get.Name)
+	assert.Equal(t, 3, other.Spec.Conns)
+
+	raw, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	var template types.LoadProfile
+	require.NoError(t, yaml.Unmarshal(raw, &template))
+	fromFile, err := template.Checksum()
+	require.NoError(t, err)
+	assert.Equal(t, fromFile, checksum)
+
+	_, _, err = loadConfig(newRunCliCtx(t, "--config", cfgPath, "--var", "NAME"))
+	assert.ErrorContains(t, err, "invalid --var")
+}
+
+func TestLoadConfigRunnerIndex(t *testing.T) {
+	cfgPath := writeWeightedRandomProfile(t, `
+patch:
+  version: v1
+  resource: configmaps
+  namespace: default
+  name: kperf
+  keySpaceSize: 100
+  keySpacePartitions: 4
+  patchType: merge
+  body: '{"data":{"k":"v"}}'
+`)
+
+	// NOTE: The requests are validated after the offset is defaulted by
+	// runner index.
+	for name, tc := range map[string]struct {
+		env   string
+		shard string
+		err   string
+	}{
+		"unset":        {err: "keySpaceOffset or " + types.RunnerIndexEnv + " is required"},
+		"index":        {env: "2", shard: "2/4"},
+		"out of range": {env: "4", err: "keySpaceOffset(4)"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(types.RunnerIndexEnv, tc.env)
+
+			profile, _, err := loadConfig(newRunCliCtx(t, "--config", cfgPath))
+			if tc.err != "" {
+				assert.ErrorContains(t, err, "request[0]: "+tc.err)
+				return
+			}
+			require.NoError(t, err)
+			req := profile.Spec.ModeConfig.(*types.WeightedRandomConfig).Requests[0]
+			assert.Equal(t, tc.shard, req.Patch.Shard())
+		})
+	}
+
+	t.Setenv(types.RunnerIndexEnv, "x")
+	_, _, err := loadConfig(newRunCliCtx(t, "--config", cfgPath))
+	assert.ErrorContains(t, err, "invalid "+types.RunnerIndexEnv)
+}
+
+func TestBuildRunMetadata(t *testing.T) {
+	cfgPath := writeWeightedRandomProfile(t, staleListRequest)
+
+	cliCtx := newRunCliCtx(t, "--config", cfgPath, "--label", "env=test")
+	profile, checksum, err := loadConfig(cliCtx)
+	require.NoError(t, err)
+
+	profile.Description = "list pods from cache"
+
+	// The metadata carries the same checksum as the report.
+	metadata, err := buildRunMetadata(cliCtx, profile, checksum)
+	require.NoError(t, err)
+	assert.Equal(t, checksum, metadata.ProfileHash)
+	assert.Equal(t, map[string]string{"env": "test"}, metadata.Labels)
+	assert.Equal(t, "list pods from cache", metadata.Description)
+	assert.Same(t, &profile.Spec, metadata.Spec)
+	assert.NotEmpty(t, metadata.RunID)
+}
+
+// staleListRequest lists pods from cache.
+const staleListRequest = `
+staleList:
+  version: v1
+  resource: pods
+`
+
+// writeWeightedRandomProfile writes the weighted-random profile, whose only
+// request is the given one in YAML format, and returns the path.
+func writeWeightedRandomProfile(t *testing.T, request string) string {
+	var sb strings.Builder
+	sb.WriteString(`
+version: 1
+spec:
+  conns: 1
+  client: 1
+  contentType: json
+  mode: weighted-random
+  modeConfig:
+    rate: 10
+    requests:
+    - shares: 1
+`)
+	for _, line := range strings.Split(strings.TrimSpace(request), "\n") {
+		sb.WriteString("      " + line + "\n")
+	}
+
+	cfgPath := filepath.Join(t.TempDir(), "profile.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(sb.String()), 0600))
+	return cfgPath
+}
+
+// newRunCliCtx returns the context of run command with the given args.
+func newRunCliCtx(t *testing.T, args ...string) *cli.Context {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range runCommand.Flags {
+		f.Apply(set)
+	}
+	require.NoError(t, set.Parse(args))
+	return cli.NewContext(nil, set, nil)
+}
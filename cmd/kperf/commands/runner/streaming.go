@@ -0,0 +1,23 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/kperf/metrics"
+	"github.com/Azure/kperf/request"
+)
+
+// newStreamingInterceptor returns the request.RequestInterceptor which
+// writes every completed request into m.
+func newStreamingInterceptor(m *metrics.StreamingResponseMetric) request.RequestInterceptor {
+	return func(ctx context.Context, req request.Requester, do func(context.Context) (int64, error)) (int64, error) {
+		start := time.Now()
+		bytes, err := do(ctx)
+		m.ObserveRequest(start, req.Method(), req.URL().String(), time.Since(start).Seconds(), bytes, err)
+		return bytes, err
+	}
+}
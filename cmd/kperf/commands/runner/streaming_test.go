@@ -0,0 +1,66 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamingInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	m := metrics.NewStreamingResponseMetric(&buf)
+	intercept := newStreamingInterceptor(m)
+
+	req := &fakeRequester{method: "GET", url: &url.URL{Path: "/api/v1/pods"}}
+	before := time.Now()
+	n, err := intercept(context.TODO(), req, func(context.Context) (int64, error) {
+		time.Sleep(time.Millisecond)
+		return 1024, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), n)
+
+	boom := errors.New("boom")
+	_, err = intercept(context.TODO(), req, func(context.Context) (int64, error) {
+		return 0, boom
+	})
+	assert.Equal(t, boom, err)
+	require.NoError(t, m.WriteSummary(map[string]int{"total": 2}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+
+	var entries [2]struct {
+		TS        float64 `json:"ts"`
+		Method    string  `json:"method"`
+		URL       string  `json:"url"`
+		LatencyMS float64 `json:"latency_ms"`
+		Bytes     int64   `json:"bytes"`
+		Error     *string `json:"error"`
+	}
+	for i := range entries {
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &entries[i]))
+		assert.Equal(t, "GET", entries[i].Method)
+		assert.Equal(t, "/api/v1/pods", entries[i].URL)
+		assert.GreaterOrEqual(t, entries[i].TS, float64(before.Unix()))
+	}
+	assert.Equal(t, int64(1024), entries[0].Bytes)
+	assert.GreaterOrEqual(t, entries[0].LatencyMS, float64(1))
+	assert.Nil(t, entries[0].Error)
+	require.NotNil(t, entries[1].Error)
+	assert.Equal(t, "boom", *entries[1].Error)
+	assert.Equal(t, `{"total":2}`, lines[2])
+}
@@ -0,0 +1,211 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/kperf/metrics"
+	"github.com/Azure/kperf/request"
+)
+
+const (
+	// outputFormatJSON prints the result as RunnerMetricReport in JSON.
+	outputFormatJSON = "json"
+	// outputFormatTable prints the result as human-readable table.
+	outputFormatTable = "table"
+)
+
+// slowP99Seconds is the P99 latency highlighted in red in table.
+const slowP99Seconds = 1.0
+
+const (
+	ansiRed = "\x1b[31m"
+	// ansiDefault is the default foreground color. It has the same length
+	// as ansiRed so that the colored and uncolored cells are aligned by
+	// tabwriter.
+	ansiDefault = "\x1b[39m"
+	ansiReset   = "\x1b[0m"
+)
+
+// isTerminal returns true if f is a terminal.
+func isTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// resolveOutputFormat returns the output format and whether it's colored.
+// The table format with color is the default if f is a terminal. Otherwise,
+// it's JSON without color.
+func resolveOutputFormat(f *os.File, format string, forceColor, noColor bool) (string, bool, error) {
+	if forceColor && noColor {
+		return "", false, fmt.Errorf("--color and --no-color can't be used together")
+	}
+
+	tty := isTerminal(f)
+	switch format {
+	case "":
+		format = outputFormatJSON
+		if tty {
+			format = outputFormatTable
+		}
+	case outputFormatJSON, outputFormatTable:
+	default:
+		return "", false, fmt.Errorf("unsupported output format %q (valid formats: %s, %s)",
+			format, outputFormatJSON, outputFormatTable)
+	}
+
+	color := tty
+	if forceColor {
+		color = true
+	}
+	if noColor {
+		color = false
+	}
+	return format, color && format == outputFormatTable, nil
+}
+
+// printResponseStatsTable prints the latency distribution per request as
+// ASCII table. The last row is the total of all the requests. P99 over one
+// second is highlighted in red if color is true.
+func printResponseStatsTable(f *os.File, stats *request.Result, color bool) error {
+	keys := make([]string, 0, len(stats.AttemptsByURL))
+	for key := range stats.AttemptsByURL {
+		keys = append(keys, key)
+	}
+	for key := range stats.LatenciesByURL {
+		if _, ok := stats.AttemptsByURL[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	seconds := stats.Duration.Seconds()
+	bytesPerSecond := func(bytes int64) string {
+		if seconds <= 0 {
+			return "-"
+		}
+		return strconv.FormatFloat(float64(bytes)/seconds, 'f', 1, 64)
+	}
+
+	w := tabwriter.NewWriter(f, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Method\tURL\tCount\t%s\tErrors\tBytes/s\t\n", colorize("P50(ms)\tP90(ms)\t", "P99(ms)", false, color))
+
+	all := []float64{}
+	for _, key := range keys {
+		// NOTE: The key is in "method url" format.
+		method, url, _ := strings.Cut(key, " ")
+		latencies := stats.LatenciesByURL[key]
+		all = append(all, latencies...)
+
+		count := stats.AttemptsByURL[key]
+		if count == 0 {
+			count = int64(len(latencies))
+		}
+
+		throughput := "-"
+		if sizes, ok := stats.ResponseSizesByURL[key]; ok {
+			total := int64(0)
+			for _, s := range sizes {
+				total += s
+			}
+			throughput = bytesPerSecond(total)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%d\t%s\t\n",
+			method, url, count, formatPercentiles(latencies, color), stats.FailuresByURL[key], throughput)
+	}
+
+	fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%d\t%s\t\n",
+		"TOTAL", "", stats.Total, formatPercentiles(all, color), stats.ErrorCount(), bytesPerSecond(stats.TotalReceivedBytes))
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write table: %w", err)
+	}
+	return nil
+}
+
+// printSummaryTable prints the concise summary of the whole benchmark, which
+// is the number of finished requests, error rate, duration, achieved rate
+// and P50, P90 and P99 latencies.
+func printSummaryTable(w io.Writer, stats *request.Result) error {
+	requests := int64(0)
+	for _, n := range stats.AttemptsByURL {
+		requests += n
+	}
+	errs := int64(stats.ErrorCount())
+
+	errRate, rate := "-", "-"
+	if requests > 0 {
+		errRate = strconv.FormatFloat(float64(errs)/float64(requests)*100, 'f', 2, 64) + "%"
+	}
+	if seconds := stats.Duration.Seconds(); seconds > 0 {
+		rate = strconv.FormatFloat(float64(requests)/seconds, 'f', 2, 64)
+	}
+
+	all := []float64{}
+	for _, latencies := range stats.LatenciesByURL {
+		all = append(all, latencies...)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Requests\tErrors\tErrorRate\tDuration\tRate(req/s)\tP50(ms)\tP90(ms)\tP99(ms)\t\n")
+	fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\t\n",
+		requests, errs, errRate, stats.Duration.Round(time.Millisecond), rate, formatPercentiles(all, false))
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return nil
+}
+
+// formatPercentiles returns P50, P90 and P99 cells in milliseconds.
+func formatPercentiles(latencies []float64, color bool) string {
+	if len(latencies) == 0 {
+		return colorize("-\t-\t", "-", false, color)
+	}
+
+	var p50, p90, p99 float64
+	for _, p := range metrics.BuildPercentileLatencies(append([]float64{}, latencies...)) {
+		switch p[0] {
+		case 0.5:
+			p50 = p[1]
+		case 0.9:
+			p90 = p[1]
+		case 0.99:
+			p99 = p[1]
+		}
+	}
+
+	ms := func(seconds float64) string {
+		return strconv.FormatFloat(seconds*1000, 'f', 2, 64)
+	}
+	return colorize(ms(p50)+"\t"+ms(p90)+"\t", ms(p99), p99 > slowP99Seconds, color)
+}
+
+// colorize returns prefix followed by the P99 cell, which is red if slow.
+// All the P99 cells are wrapped by color codes of the same length if color
+// is enabled so that tabwriter aligns the columns.
+func colorize(prefix, p99 string, slow bool, color bool) string {
+	if !color {
+		return prefix + p99
+	}
+	code := ansiDefault
+	if slow {
+		code = ansiRed
+	}
+	return prefix + code + p99 + ansiReset
+}
@@ -0,0 +1,152 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/request"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveOutputFormat(t *testing.T) {
+	// NOTE: Pipe isn't a terminal, like the result redirected to file.
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	assert.False(t, isTerminal(w))
+
+	tests := map[string]struct {
+		format     string
+		forceColor bool
+		noColor    bool
+		expected   string
+		color      bool
+		err        bool
+	}{
+		"default is json":           {expected: outputFormatJSON},
+		"table without color":       {format: outputFormatTable, expected: outputFormatTable},
+		"table with forced color":   {format: outputFormatTable, forceColor: true, expected: outputFormatTable, color: true},
+		"json ignores color":        {format: outputFormatJSON, forceColor: true, expected: outputFormatJSON},
+		"table with no color":       {format: outputFormatTable, noColor: true, expected: outputFormatTable},
+		"color with no color":       {format: outputFormatTable, forceColor: true, noColor: true, err: true},
+		"unsupported output format": {format: "yaml", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			format, color, err := resolveOutputFormat(w, tc.format, tc.forceColor, tc.noColor)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, format)
+			assert.Equal(t, tc.color, color)
+		})
+	}
+}
+
+func TestPrintResponseStatsTable(t *testing.T) {
+	stats := &request.Result{
+		ResponseStats: types.ResponseStats{
+			Errors: []types.ResponseError{{URL: "/api/v1/pods/a"}},
+			LatenciesByURL: map[string][]float64{
+				"LIST /api/v1/pods":  {0.1, 0.2, 0.3, 0.4},
+				"GET /api/v1/pods/a": {1.5, 2},
+			},
+			AttemptsByURL: map[string]int64{
+				"LIST /api/v1/pods":  4,
+				"GET /api/v1/pods/a": 3,
+			},
+			FailuresByURL: map[string]int64{
+				"GET /api/v1/pods/a": 1,
+			},
+			ResponseSizesByURL: map[string][]int64{
+				"LIST /api/v1/pods": {100, 100, 100, 100},
+			},
+			TotalReceivedBytes: 1000,
+		},
+		Duration: 2 * time.Second,
+		Total:    7,
+	}
+
+	for _, color := range []bool{false, true} {
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+
+		require.NoError(t, printResponseStatsTable(w, stats, color))
+		require.NoError(t, w.Close())
+
+		out, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		require.Len(t, lines, 4)
+		assert.Equal(t, []string{"Method", "URL", "Count", "P50(ms)", "P90(ms)", "P99(ms)", "Errors", "Bytes/s"},
+			strings.Fields(stripANSI(lines[0])))
+		assert.Equal(t, []string{"GET", "/api/v1/pods/a", "3", "1500.00", "2000.00", "2000.00", "1", "-"},
+			strings.Fields(stripANSI(lines[1])))
+		assert.Equal(t, []string{"LIST", "/api/v1/pods", "4", "200.00", "400.00", "400.00", "0", "200.0"},
+			strings.Fields(stripANSI(lines[2])))
+		assert.Equal(t, []string{"TOTAL", "7", "300.00", "2000.00", "2000.00", "1", "500.0"},
+			strings.Fields(stripANSI(lines[3])))
+
+		// Only the slow P99 is red.
+		assert.Equal(t, color, strings.Contains(lines[1], ansiRed+"2000.00"+ansiReset))
+		assert.NotContains(t, lines[2], ansiRed)
+	}
+}
+
+func TestPrintSummaryTable(t *testing.T) {
+	stats := &request.Result{
+		ResponseStats: types.ResponseStats{
+			Errors: []types.ResponseError{{URL: "/api/v1/pods/a"}},
+			LatenciesByURL: map[string][]float64{
+				"LIST /api/v1/pods":  {0.1, 0.2, 0.3},
+				"GET /api/v1/pods/a": {0.4},
+			},
+			AttemptsByURL: map[string]int64{
+				"LIST /api/v1/pods":  3,
+				"GET /api/v1/pods/a": 2,
+			},
+		},
+		Duration: 2 * time.Second,
+	}
+
+	var buf strings.Builder
+	require.NoError(t, printSummaryTable(&buf, stats))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"Requests", "Errors", "ErrorRate", "Duration", "Rate(req/s)", "P50(ms)", "P90(ms)", "P99(ms)"},
+		strings.Fields(lines[0]))
+	assert.Equal(t, []string{"5", "1", "20.00%", "2s", "2.50", "200.00", "400.00", "400.00"},
+		strings.Fields(lines[1]))
+
+	// No request at all.
+	buf.Reset()
+	require.NoError(t, printSummaryTable(&buf, &request.Result{}))
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"0", "0", "-", "0s", "-", "-", "-", "-"}, strings.Fields(lines[1]))
+}
+
+// stripANSI removes the color codes used by table.
+func stripANSI(s string) string {
+	for _, code := range []string{ansiRed, ansiDefault, ansiReset} {
+		s = strings.ReplaceAll(s, code, "")
+	}
+	return s
+}
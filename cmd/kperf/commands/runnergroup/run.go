@@ -51,6 +51,22 @@ var runCommand = cli.Command{
 			Usage: "The verbosity level of runners",
 			Value: 2,
 		},
+		cli.StringFlag{
+			Name:  "runner-cpu",
+			Usage: "The cpu request of each runner, like 500m or 2. It overrides spec's resources",
+		},
+		cli.StringFlag{
+			Name:  "runner-memory",
+			Usage: "The memory request of each runner, like 4Gi. It overrides spec's resources",
+		},
+		cli.StringSliceFlag{
+			Name:  "runner-node-selector",
+			Usage: "Deploy runners to the node with the label (FORMAT: KEY=VALUE). It overrides spec's nodeSelector (can specify multiple times)",
+		},
+		cli.StringSliceFlag{
+			Name:  "runner-tolerations",
+			Usage: "Toleration of runners (FORMAT: KEY[=VALUE][:EFFECT] or JSON). It overrides spec's tolerations (can specify multiple times)",
+		},
 	},
 	Action: func(cliCtx *cli.Context) error {
 		imgRef := cliCtx.String("runner-image")
@@ -77,6 +93,9 @@ var runCommand = cli.Command{
 		}
 
 		specs[0].NodeAffinity = affinityLabels
+		if err := applyRunnerPlacement(cliCtx, specs[0]); err != nil {
+			return err
+		}
 
 		kubeCfgPath := cliCtx.GlobalString("kubeconfig")
 		return runner.CreateRunnerGroupServer(context.Background(),
@@ -90,6 +109,31 @@ var runCommand = cli.Command{
 	},
 }
 
+// applyRunnerPlacement overrides spec's resources, node selector and
+// tolerations by flags, and validates them.
+func applyRunnerPlacement(cliCtx *cli.Context, spec *types.RunnerGroupSpec) error {
+	if cpu, memory := cliCtx.String("runner-cpu"), cliCtx.String("runner-memory"); cpu != "" || memory != "" {
+		spec.Resources = &types.RunnerResources{CPU: cpu, Memory: memory}
+	}
+
+	if cliCtx.IsSet("runner-node-selector") {
+		nodeSelector, err := utils.KeyValueMap(cliCtx.StringSlice("runner-node-selector"))
+		if err != nil {
+			return fmt.Errorf("failed to parse runner-node-selector: %w", err)
+		}
+		spec.NodeSelector = nodeSelector
+	}
+
+	if cliCtx.IsSet("runner-tolerations") {
+		tolerations, err := utils.ParseTolerations(cliCtx.StringSlice("runner-tolerations"))
+		if err != nil {
+			return fmt.Errorf("failed to parse runner-tolerations: %w", err)
+		}
+		spec.Tolerations = tolerations
+	}
+	return spec.ValidatePlacement()
+}
+
 // loadRunnerGroupSpec loads runner group spec from URIs.
 func loadRunnerGroupSpec(cliCtx *cli.Context) ([]*types.RunnerGroupSpec, error) {
 	clientset, err := buildKubernetesClientset(cliCtx)
@@ -5,11 +5,15 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/Azure/kperf/api/types"
+
+	corev1 "k8s.io/api/core/v1"
 	flowcontrolv1 "k8s.io/api/flowcontrol/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -56,6 +60,79 @@ func KeyValueMap(strs []string) (map[string]string, error) {
 	return res, nil
 }
 
+// ParseTaints converts KEY[=VALUE]:EFFECT into taints. The effect is one of
+// NoSchedule, PreferNoSchedule and NoExecute.
+func ParseTaints(strs []string) ([]corev1.Taint, error) {
+	res := make([]corev1.Taint, 0, len(strs))
+	for _, str := range strs {
+		keyValue, effect, ok := strings.Cut(str, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected key[=value]:effect format, but got %s", str)
+		}
+
+		key, value, _ := strings.Cut(keyValue, "=")
+		if key == "" {
+			return nil, fmt.Errorf("required non-empty key in %s", str)
+		}
+
+		switch corev1.TaintEffect(effect) {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			return nil, fmt.Errorf("invalid effect %q in %s", effect, str)
+		}
+
+		res = append(res, corev1.Taint{
+			Key:    key,
+			Value:  value,
+			Effect: corev1.TaintEffect(effect),
+		})
+	}
+	return res, nil
+}
+
+// ParseTolerations converts tolerations into runner tolerations. Each one is
+// either KEY[=VALUE][:EFFECT] or the json of one toleration or list. The
+// operator is Equal if VALUE is set, or Exists otherwise.
+func ParseTolerations(strs []string) ([]types.RunnerToleration, error) {
+	res := make([]types.RunnerToleration, 0, len(strs))
+	for _, str := range strs {
+		var tolerations []types.RunnerToleration
+
+		switch str = strings.TrimSpace(str); {
+		case strings.HasPrefix(str, "["):
+			if err := json.Unmarshal([]byte(str), &tolerations); err != nil {
+				return nil, fmt.Errorf("invalid tolerations %s: %w", str, err)
+			}
+		case strings.HasPrefix(str, "{"):
+			var t types.RunnerToleration
+			if err := json.Unmarshal([]byte(str), &t); err != nil {
+				return nil, fmt.Errorf("invalid toleration %s: %w", str, err)
+			}
+			tolerations = append(tolerations, t)
+		default:
+			keyValue, effect, _ := strings.Cut(str, ":")
+			key, value, hasValue := strings.Cut(keyValue, "=")
+			if key == "" {
+				return nil, fmt.Errorf("expected key[=value][:effect] format, but got %s", str)
+			}
+
+			t := types.RunnerToleration{Key: key, Operator: "Exists", Effect: effect}
+			if hasValue {
+				t.Operator, t.Value = "Equal", value
+			}
+			tolerations = append(tolerations, t)
+		}
+
+		for _, t := range tolerations {
+			if err := t.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid toleration %s: %w", str, err)
+			}
+		}
+		res = append(res, tolerations...)
+	}
+	return res, nil
+}
+
 // inCluster is to check if current process is in pod.
 func inCluster() bool {
 	f, err := os.Stat("/var/run/secrets/kubernetes.io/serviceaccount/token")
@@ -72,6 +72,10 @@ var nodepoolAddCommand = cli.Command{
 			Name:  "node-labels",
 			Usage: "Additional labels to node (FORMAT: KEY=VALUE)",
 		},
+		cli.StringSliceFlag{
+			Name:  "node-taints",
+			Usage: "Additional taints to node (FORMAT: KEY[=VALUE]:EFFECT)",
+		},
 		cli.StringFlag{
 			Name:   "shared-provider-id",
 			Usage:  "Force all the virtual nodes using one provider ID",
@@ -104,6 +108,11 @@ var nodepoolAddCommand = cli.Command{
 			return fmt.Errorf("failed to parse node-labels: %w", err)
 		}
 
+		nodeTaints, err := utils.ParseTaints(cliCtx.StringSlice("node-taints"))
+		if err != nil {
+			return fmt.Errorf("failed to parse node-taints: %w", err)
+		}
+
 		nodes := cliCtx.Int("nodes")
 		if nodes > maxNodesPerPool {
 			klog.Warningf("Creating a node pool with a large number of nodes may cause performance issues. Consider using batch-add command for large node pools.")
@@ -118,6 +127,7 @@ var nodepoolAddCommand = cli.Command{
 			virtualcluster.WithNodepoolMaxPodsOpt(cliCtx.Int("max-pods")),
 			virtualcluster.WithNodepoolNodeControllerAffinity(affinityLabels),
 			virtualcluster.WithNodepoolLabelsOpt(nodeLabels),
+			virtualcluster.WithNodepoolTaintsOpt(nodeTaints),
 			virtualcluster.WithNodepoolSharedProviderID(cliCtx.String("shared-provider-id")),
 		)
 	},
@@ -156,6 +166,10 @@ var nodepoolBatchAddCommand = cli.Command{
 			Name:  "node-labels",
 			Usage: "Additional labels to node (FORMAT: KEY=VALUE)",
 		},
+		cli.StringSliceFlag{
+			Name:  "node-taints",
+			Usage: "Additional taints to node (FORMAT: KEY[=VALUE]:EFFECT)",
+		},
 		cli.StringFlag{
 			Name:   "shared-provider-id",
 			Usage:  "Force all the virtual nodes using one provider ID",
@@ -192,6 +206,11 @@ var nodepoolBatchAddCommand = cli.Command{
 			return fmt.Errorf("failed to parse node labels: %w", err)
 		}
 
+		nodeTaints, err := utils.ParseTaints(cliCtx.StringSlice("node-taints"))
+		if err != nil {
+			return fmt.Errorf("failed to parse node taints: %w", err)
+		}
+
 		totalNodes := cliCtx.Int("nodes")
 		batchSize := cliCtx.Int("batch-size")
 		if batchSize <= 0 {
@@ -214,6 +233,7 @@ var nodepoolBatchAddCommand = cli.Command{
 				virtualcluster.WithNodepoolMaxPodsOpt(cliCtx.Int("max-pods")),
 				virtualcluster.WithNodepoolNodeControllerAffinity(affinityLabels),
 				virtualcluster.WithNodepoolLabelsOpt(nodeLabels),
+				virtualcluster.WithNodepoolTaintsOpt(nodeTaints),
 				virtualcluster.WithNodepoolSharedProviderID(cliCtx.String("shared-provider-id")),
 			); err != nil {
 				return fmt.Errorf("failed to create nodepool batch %s: %w", batchNodepoolName, err)
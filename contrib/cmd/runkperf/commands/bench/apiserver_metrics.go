@@ -0,0 +1,209 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package bench
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	internaltypes "github.com/Azure/kperf/contrib/internal/types"
+	"github.com/Azure/kperf/contrib/log"
+	"github.com/Azure/kperf/contrib/utils"
+
+	"github.com/urfave/cli"
+)
+
+// defaultAPIServerMetrics is the default value of --apiserver-metrics.
+var defaultAPIServerMetrics = strings.Join([]string{
+	"apiserver_request_duration_seconds",
+	"apiserver_current_inflight_requests",
+	"apiserver_flowcontrol_rejected_requests_total",
+	"etcd_request_duration_seconds",
+}, ",")
+
+// apiserverMetrics is the selected metrics of each kube-apiserver. The key
+// is apiserver's IP and the value is summed by sample name.
+type apiserverMetrics map[string]map[string]float64
+
+// apiserverMetricsSample is the metrics scraped during benchmark.
+type apiserverMetricsSample struct {
+	// Elapsed is the time since benchmark started.
+	Elapsed string `json:"elapsed"`
+	// Metrics is the selected metrics of each kube-apiserver.
+	Metrics apiserverMetrics `json:"metrics"`
+}
+
+// addAPIServerMetricsInfoInterceptor adds the apiserver metrics selected by
+// --apiserver-metrics before and after benchmark, and their deltas, into
+// benchmark report. If --apiserver-metrics-interval is set, the metrics are
+// also scraped on that interval during benchmark. The failure of scraping is
+// logged as warning.
+func addAPIServerMetricsInfoInterceptor(handler subcmdActionFunc) subcmdActionFunc {
+	return func(cliCtx *cli.Context) (*internaltypes.BenchmarkReport, error) {
+		names := parseAPIServerMetricNames(cliCtx.GlobalString("apiserver-metrics"))
+		if len(names) == 0 {
+			return handler(cliCtx)
+		}
+
+		ctx := context.Background()
+		kubeCfgPath := cliCtx.GlobalString("kubeconfig")
+
+		before := scrapeAPIServerMetrics(ctx, kubeCfgPath, names)
+
+		var samples []apiserverMetricsSample
+		var wg sync.WaitGroup
+		sampleCtx, sampleCancel := context.WithCancel(ctx)
+		if interval := cliCtx.GlobalDuration("apiserver-metrics-interval"); interval > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				start := time.Now()
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-sampleCtx.Done():
+						return
+					case <-ticker.C:
+					}
+
+					if m := scrapeAPIServerMetrics(sampleCtx, kubeCfgPath, names); m != nil {
+						samples = append(samples, apiserverMetricsSample{
+							Elapsed: time.Since(start).Round(time.Second).String(),
+							Metrics: m,
+						})
+					}
+				}
+			}()
+		}
+
+		report, err := handler(cliCtx)
+		sampleCancel()
+		wg.Wait()
+		if err != nil {
+			return nil, err
+		}
+
+		after := scrapeAPIServerMetrics(ctx, kubeCfgPath, names)
+
+		info := map[string]interface{}{
+			"before": before,
+			"after":  after,
+			"delta":  diffAPIServerMetrics(before, after),
+		}
+		if len(samples) > 0 {
+			info["samples"] = samples
+		}
+		report.Info["apiserverMetrics"] = info
+		return report, nil
+	}
+}
+
+// parseAPIServerMetricNames splits comma-separated metric names.
+func parseAPIServerMetricNames(str string) []string {
+	var names []string
+	for _, name := range strings.Split(str, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// scrapeAPIServerMetrics returns the selected metrics of each kube-apiserver.
+// It returns nil with warning if it fails.
+func scrapeAPIServerMetrics(ctx context.Context, kubeCfgPath string, names []string) apiserverMetrics {
+	raw, err := utils.FetchAPIServerMetrics(ctx, kubeCfgPath)
+	if err != nil {
+		log.GetLogger(ctx).
+			WithKeyValues("level", "warn").
+			LogKV("msg", "failed to fetch apiserver metrics", "error", err)
+		return nil
+	}
+
+	res := make(apiserverMetrics, len(raw))
+	for ip, data := range raw {
+		res[ip] = parseAPIServerMetrics(data, names)
+	}
+	return res
+}
+
+// parseAPIServerMetrics sums the samples of the selected metrics in
+// Prometheus text format by sample name, regardless of labels. Histograms
+// and summaries are reported by _sum and _count; the buckets and quantiles
+// are skipped.
+func parseAPIServerMetrics(data []byte, names []string) map[string]float64 {
+	selected := make(map[string]bool, len(names)*3)
+	for _, name := range names {
+		selected[name] = true
+		selected[name+"_sum"] = true
+		selected[name+"_count"] = true
+	}
+
+	res := map[string]float64{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, rest := line, ""
+		if idx := strings.IndexAny(line, "{ "); idx >= 0 {
+			name, rest = line[:idx], line[idx:]
+		}
+		if !selected[name] {
+			continue
+		}
+
+		// Skip labels and the optional timestamp.
+		if idx := strings.LastIndex(rest, "}"); idx >= 0 {
+			// The summary's quantiles share the metric name.
+			if strings.Contains(rest[:idx], `quantile="`) {
+				continue
+			}
+			rest = rest[idx+1:]
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+		res[name] += v
+	}
+	return res
+}
+
+// diffAPIServerMetrics returns after minus before for the apiservers and
+// metrics in both.
+func diffAPIServerMetrics(before, after apiserverMetrics) apiserverMetrics {
+	res := make(apiserverMetrics, len(after))
+	for ip, afterValues := range after {
+		beforeValues, ok := before[ip]
+		if !ok {
+			continue
+		}
+
+		delta := make(map[string]float64, len(afterValues))
+		for name, v := range afterValues {
+			if b, ok := beforeValues[name]; ok {
+				delta[name] = v - b
+			}
+		}
+		res[ip] = delta
+	}
+	return res
+}
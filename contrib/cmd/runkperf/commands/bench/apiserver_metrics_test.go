@@ -0,0 +1,62 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package bench
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const syntheticAPIServerMetrics = `# HELP apiserver_current_inflight_requests Maximal number of currently used inflight request limit.
+# TYPE apiserver_current_inflight_requests gauge
+apiserver_current_inflight_requests{request_kind="mutating"} 2
+apiserver_current_inflight_requests{request_kind="readOnly"} 5
+# TYPE apiserver_request_duration_seconds histogram
+apiserver_request_duration_seconds_bucket{verb="GET",le="0.005"} 10
+apiserver_request_duration_seconds_bucket{verb="GET",le="+Inf"} 12
+apiserver_request_duration_seconds_sum{verb="GET"} 0.5
+apiserver_request_duration_seconds_count{verb="GET"} 12
+apiserver_request_duration_seconds_sum{verb="LIST"} 1.5
+apiserver_request_duration_seconds_count{verb="LIST"} 3
+# TYPE etcd_request_duration_seconds summary
+etcd_request_duration_seconds{operation="get",quantile="0.99"} 0.1
+etcd_request_duration_seconds_sum{operation="get"} 2
+etcd_request_duration_seconds_count{operation="get"} 20 1700000000000
+go_sched_gomaxprocs_threads 8
+`
+
+func TestParseAPIServerMetrics(t *testing.T) {
+	names := parseAPIServerMetricNames(defaultAPIServerMetrics)
+	assert.Len(t, names, 4)
+
+	assert.Equal(t, map[string]float64{
+		"apiserver_current_inflight_requests":      7,
+		"apiserver_request_duration_seconds_sum":   2,
+		"apiserver_request_duration_seconds_count": 15,
+		"etcd_request_duration_seconds_sum":        2,
+		"etcd_request_duration_seconds_count":      20,
+	}, parseAPIServerMetrics([]byte(syntheticAPIServerMetrics), names))
+
+	assert.Equal(t, map[string]float64{
+		"go_sched_gomaxprocs_threads": 8,
+	}, parseAPIServerMetrics([]byte(syntheticAPIServerMetrics), parseAPIServerMetricNames(" go_sched_gomaxprocs_threads, ")))
+
+	assert.Empty(t, parseAPIServerMetricNames(""))
+}
+
+func TestDiffAPIServerMetrics(t *testing.T) {
+	before := apiserverMetrics{
+		"10.0.0.1": {"a_count": 10, "b": 1},
+		"10.0.0.2": {"a_count": 5},
+	}
+	after := apiserverMetrics{
+		"10.0.0.1": {"a_count": 15, "b": 3, "c": 1},
+		"10.0.0.3": {"a_count": 1},
+	}
+	assert.Equal(t, apiserverMetrics{
+		"10.0.0.1": {"a_count": 5, "b": 2},
+	}, diffAPIServerMetrics(before, after))
+	assert.Empty(t, diffAPIServerMetrics(nil, after))
+}
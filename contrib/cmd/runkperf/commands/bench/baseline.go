@@ -0,0 +1,214 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	apitypes "github.com/Azure/kperf/api/types"
+	internaltypes "github.com/Azure/kperf/contrib/internal/types"
+	"github.com/Azure/kperf/contrib/log"
+
+	"github.com/urfave/cli"
+)
+
+// regressionExitCode is the exit code if any metric regresses compared to
+// baseline.
+const regressionExitCode = 2
+
+// comparison metric names. P99 latency by URL is named p99:<URL>.
+const (
+	comparisonMetricP99            = "p99"
+	comparisonMetricErrorRate      = "errorRate"
+	comparisonMetricQPS            = "qps"
+	comparisonMetricAPIServerCores = "apiserverCores"
+)
+
+// compareWithBaseline compares report with the baseline report specified by
+// --baseline, and sets the result as report's comparison. It's no-op if
+// --baseline isn't set.
+func compareWithBaseline(cliCtx *cli.Context, report *internaltypes.BenchmarkReport) error {
+	baselinePath := cliCtx.GlobalString("baseline")
+	if baselinePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(baselinePath)
+	if err != nil {
+		return fmt.Errorf("failed to read baseline %s: %w", baselinePath, err)
+	}
+
+	var baseline internaltypes.BenchmarkReport
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return fmt.Errorf("failed to unmarshal baseline %s: %w", baselinePath, err)
+	}
+
+	if baseline.Name != "" && report.Name != "" && baseline.Name != report.Name {
+		log.GetLogger(context.TODO()).
+			WithKeyValues("level", "warn").
+			LogKV("msg", "baseline is from another benchmark",
+				"baseline", baseline.Name, "benchmark", report.Name)
+	}
+
+	thresholds := internaltypes.RegressionThresholds{
+		P99:       cliCtx.GlobalFloat64("regression-p99-threshold"),
+		ErrorRate: cliCtx.GlobalFloat64("regression-error-rate-threshold"),
+		QPS:       cliCtx.GlobalFloat64("regression-qps-threshold"),
+	}
+	if thresholds.P99 < 0 || thresholds.ErrorRate < 0 || thresholds.QPS < 0 {
+		return fmt.Errorf("regression thresholds require >= 0: %+v", thresholds)
+	}
+
+	comparison := compareReports(&baseline, report, thresholds)
+	comparison.Baseline = baselinePath
+	report.Comparison = comparison
+	return nil
+}
+
+// compareReports computes the deltas of key metrics in both reports.
+func compareReports(baseline, current *internaltypes.BenchmarkReport, thresholds internaltypes.RegressionThresholds) *internaltypes.BenchmarkComparison {
+	baselineMetrics := benchmarkKeyMetrics(baseline)
+	currentMetrics := benchmarkKeyMetrics(current)
+
+	res := &internaltypes.BenchmarkComparison{
+		BaselineName: baseline.Name,
+		Thresholds:   thresholds,
+		Metrics:      []internaltypes.MetricComparison{},
+	}
+	for name, b := range baselineMetrics {
+		c, ok := currentMetrics[name]
+		if !ok {
+			continue
+		}
+
+		m := internaltypes.MetricComparison{
+			Name:     name,
+			Baseline: b,
+			Current:  c,
+			Delta:    c - b,
+		}
+		if b != 0 {
+			m.DeltaRatio = m.Delta / b
+		}
+		m.Regressed = isRegressed(name, m, thresholds)
+		res.Metrics = append(res.Metrics, m)
+	}
+
+	sort.Slice(res.Metrics, func(i, j int) bool {
+		return res.Metrics[i].Name < res.Metrics[j].Name
+	})
+	for _, m := range res.Metrics {
+		if m.Regressed {
+			res.Regressions = append(res.Regressions, m.Name)
+		}
+	}
+	return res
+}
+
+// isRegressed returns true if the metric's delta is beyond threshold. The
+// apiserver cores are only informational.
+func isRegressed(name string, m internaltypes.MetricComparison, thresholds internaltypes.RegressionThresholds) bool {
+	switch {
+	case name == comparisonMetricP99 || strings.HasPrefix(name, comparisonMetricP99+":"):
+		return m.Baseline > 0 && m.DeltaRatio > thresholds.P99
+	case name == comparisonMetricErrorRate:
+		return m.Delta > thresholds.ErrorRate
+	case name == comparisonMetricQPS:
+		return m.Baseline > 0 && -m.DeltaRatio > thresholds.QPS
+	default:
+		return false
+	}
+}
+
+// benchmarkKeyMetrics returns the key metrics of report by name.
+func benchmarkKeyMetrics(report *internaltypes.BenchmarkReport) map[string]float64 {
+	res := map[string]float64{}
+
+	result := &report.Result
+	if p99, ok := p99Latency(result.PercentileLatencies); ok {
+		res[comparisonMetricP99] = p99
+	}
+	for u, percentiles := range result.PercentileLatenciesByURL {
+		if p99, ok := p99Latency(percentiles); ok {
+			res[comparisonMetricP99+":"+u] = p99
+		}
+	}
+
+	if rate, ok := errorRate(result); ok {
+		res[comparisonMetricErrorRate] = rate
+	}
+
+	if d, err := time.ParseDuration(result.Duration); err == nil && d > 0 {
+		res[comparisonMetricQPS] = float64(result.Total) / d.Seconds()
+	}
+
+	if cores, ok := apiserverCores(report.Info); ok {
+		res[comparisonMetricAPIServerCores] = cores
+	}
+	return res
+}
+
+// errorRate returns the ratio of failed requests to all the requests.
+func errorRate(result *apitypes.RunnerGroupsReport) (float64, bool) {
+	if result.ErrorRate > 0 || result.SuccessCount > 0 {
+		return result.ErrorRate, true
+	}
+
+	// NOTE: The runner group's summary doesn't have the counts, and its
+	// Total is the number of successful requests.
+	attempts := result.Total + len(result.Errors)
+	if attempts == 0 {
+		return 0, false
+	}
+	return float64(len(result.Errors)) / float64(attempts), true
+}
+
+// apiserverCores returns the sum of kube-apiserver's cores after benchmark,
+// which is added by addAPIServerCoresInfoInterceptor. The info is
+// round-tripped in JSON so that it works for both new and decoded reports.
+func apiserverCores(info map[string]interface{}) (float64, bool) {
+	raw, ok := info["apiserver"]
+	if !ok {
+		return 0, false
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return 0, false
+	}
+
+	var apiserver struct {
+		Cores struct {
+			After map[string]float64 `json:"after"`
+		} `json:"cores"`
+	}
+	if err := json.Unmarshal(data, &apiserver); err != nil || len(apiserver.Cores.After) == 0 {
+		return 0, false
+	}
+
+	total := 0.0
+	for _, cores := range apiserver.Cores.After {
+		total += cores
+	}
+	return total, true
+}
+
+// regressionError returns the error with regressionExitCode if any metric
+// regresses.
+func regressionError(comparison *internaltypes.BenchmarkComparison) error {
+	if comparison == nil || len(comparison.Regressions) == 0 {
+		return nil
+	}
+	return cli.NewExitError(
+		fmt.Sprintf("regressed compared to baseline %s: %s",
+			comparison.Baseline, strings.Join(comparison.Regressions, ", ")),
+		regressionExitCode,
+	)
+}
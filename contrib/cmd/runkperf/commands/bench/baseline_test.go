@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package bench
+
+import (
+	"encoding/json"
+	"testing"
+
+	apitypes "github.com/Azure/kperf/api/types"
+	internaltypes "github.com/Azure/kperf/contrib/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+// newComparisonTestReport returns the report whose P99 latency of pods and
+// overall is p99, with total successful requests in 10s and errors.
+func newComparisonTestReport(name string, p99 float64, total, errors int, cores int) *internaltypes.BenchmarkReport {
+	percentiles := [][2]float64{{0.5, p99 / 2}, {0.99, p99}}
+	report := &internaltypes.BenchmarkReport{
+		Name: name,
+		Result: apitypes.RunnerGroupsReport{
+			Total:                    total,
+			Errors:                   make([]apitypes.ResponseError, errors),
+			Duration:                 "10s",
+			PercentileLatencies:      percentiles,
+			PercentileLatenciesByURL: map[string][][2]float64{"/api/v1/pods": percentiles},
+		},
+		Info: map[string]interface{}{},
+	}
+	if cores > 0 {
+		report.Info["apiserver"] = map[string]interface{}{
+			"cores": map[string]interface{}{
+				"before": map[string]int{"10.0.0.1": cores},
+				"after":  map[string]int{"10.0.0.1": cores},
+			},
+		}
+	}
+	return report
+}
+
+func TestCompareReports(t *testing.T) {
+	thresholds := internaltypes.RegressionThresholds{P99: 0.1, ErrorRate: 0.01, QPS: 0.1}
+
+	for name, tc := range map[string]struct {
+		current     *internaltypes.BenchmarkReport
+		regressions []string
+	}{
+		"same": {
+			current: newComparisonTestReport("a", 1, 1000, 0, 4),
+		},
+		"within thresholds": {
+			current: newComparisonTestReport("a", 1.05, 950, 5, 8),
+		},
+		"p99": {
+			current:     newComparisonTestReport("a", 1.2, 1000, 0, 4),
+			regressions: []string{"p99", "p99:/api/v1/pods"},
+		},
+		"error rate and qps": {
+			current:     newComparisonTestReport("a", 1, 800, 200, 4),
+			regressions: []string{"errorRate", "qps"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			baseline := newComparisonTestReport("a", 1, 1000, 0, 4)
+
+			// The baseline is decoded from file.
+			data, err := json.Marshal(baseline)
+			require.NoError(t, err)
+			baseline = &internaltypes.BenchmarkReport{}
+			require.NoError(t, json.Unmarshal(data, baseline))
+
+			res := compareReports(baseline, tc.current, thresholds)
+			assert.Equal(t, "a", res.BaselineName)
+			assert.Equal(t, thresholds, res.Thresholds)
+			assert.Equal(t, tc.regressions, res.Regressions)
+
+			names := []string{}
+			for _, m := range res.Metrics {
+				names = append(names, m.Name)
+			}
+			assert.Equal(t, []string{"apiserverCores", "errorRate", "p99", "p99:/api/v1/pods", "qps"}, names)
+			assert.Equal(t, float64(100), res.Metrics[4].Baseline)
+			assert.Equal(t, float64(tc.current.Result.Total)/10, res.Metrics[4].Current)
+
+			err = regressionError(res)
+			if len(tc.regressions) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var exitErr cli.ExitCoder
+			require.ErrorAs(t, err, &exitErr)
+			assert.Equal(t, regressionExitCode, exitErr.ExitCode())
+		})
+	}
+}
+
+func TestCompareReportsMissingMetrics(t *testing.T) {
+	baseline := newComparisonTestReport("a", 1, 1000, 0, 0)
+	current := newComparisonTestReport("b", 2, 1000, 0, 4)
+	current.Result.PercentileLatenciesByURL = nil
+
+	res := compareReports(baseline, current, internaltypes.RegressionThresholds{})
+	names := []string{}
+	for _, m := range res.Metrics {
+		names = append(names, m.Name)
+	}
+	assert.Equal(t, []string{"errorRate", "p99", "qps"}, names)
+	assert.Equal(t, []string{"p99"}, res.Regressions)
+	assert.Equal(t, float64(1), res.Metrics[1].DeltaRatio)
+}
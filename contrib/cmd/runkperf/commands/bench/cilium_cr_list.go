@@ -57,7 +57,13 @@ var benchCiliumCustomResourceListCase = cli.Command{
 		commonFlags...,
 	),
 	Action: func(cliCtx *cli.Context) error {
-		_, err := renderBenchmarkReportInterceptor(ciliumCustomResourceListRun)(cliCtx)
+		_, err := renderBenchmarkReportInterceptor(
+			addWarmupInfoInterceptor(
+				addRunnerPlacementInfoInterceptor(
+					addLoadProfileInfoInterceptor(ciliumCustomResourceListRun),
+				),
+			),
+		)(cliCtx)
 		return err
 	},
 }
@@ -96,6 +102,7 @@ func ciliumCustomResourceListRun(cliCtx *cli.Context) (*internaltypes.BenchmarkR
 		rgCfgFile,
 		cliCtx.GlobalString("runner-flowcontrol"),
 		cliCtx.GlobalString("rg-affinity"),
+		deployRunnerGroupOpts(cliCtx)...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to deploy runner group: %w", err)
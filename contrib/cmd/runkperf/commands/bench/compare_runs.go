@@ -0,0 +1,190 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/Azure/kperf/api/types"
+	internaltypes "github.com/Azure/kperf/contrib/internal/types"
+	"github.com/Azure/kperf/contrib/log"
+	"github.com/Azure/kperf/contrib/utils"
+
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// highVarianceCV is the coefficient of variation above which results
+	// are considered as high-variance.
+	highVarianceCV = 0.1
+	// suspiciousRunZScore is the absolute z-score above which one run is
+	// considered as outlier.
+	suspiciousRunZScore = 2.0
+)
+
+var benchCompareRunsCase = cli.Command{
+	Name: "compare_runs",
+	Usage: `
+Run the same runner group spec N times against the cluster as it is and
+compare P99 latency across runs. It reports mean, standard deviation, min/max
+and coefficient of variation, which helps determine how many runs are required
+for reliable benchmarking.
+	`,
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "runs",
+			Usage: "The number of times to run the benchmark",
+			Value: 3,
+		},
+		cli.StringFlag{
+			Name:     "config",
+			Usage:    "Path to the runner group spec file",
+			Required: true,
+		},
+	},
+	Action: func(cliCtx *cli.Context) error {
+		report, err := benchCompareRunsCaseRun(cliCtx)
+		if err != nil {
+			return err
+		}
+		return renderReport(cliCtx, report)
+	},
+}
+
+// benchCompareRunsCaseRun is for benchCompareRunsCase subcommand.
+func benchCompareRunsCaseRun(cliCtx *cli.Context) (*internaltypes.MultiRunReport, error) {
+	ctx := context.Background()
+
+	runs := cliCtx.Int("runs")
+	if runs < 2 {
+		return nil, fmt.Errorf("invalid runs value: %v, requires >= 2", runs)
+	}
+
+	if _, err := newRunnerPlacement(cliCtx); err != nil {
+		return nil, err
+	}
+
+	rgCfgFile := cliCtx.String("config")
+	data, err := os.ReadFile(rgCfgFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rgCfgFile, err)
+	}
+
+	var rgSpec types.RunnerGroupSpec
+	if err := yaml.Unmarshal(data, &rgSpec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s into RunnerGroupSpec: %w", rgCfgFile, err)
+	}
+
+	results := make([]types.RunnerGroupsReport, 0, runs)
+	for i := 0; i < runs; i++ {
+		log.GetLogger(ctx).
+			WithKeyValues("level", "info").
+			LogKV("msg", "starting run", "run", i, "runs", runs)
+
+		rgResult, err := utils.DeployRunnerGroup(ctx,
+			cliCtx.GlobalString("kubeconfig"),
+			cliCtx.GlobalString("runner-image"),
+			rgCfgFile,
+			cliCtx.GlobalString("runner-flowcontrol"),
+			cliCtx.GlobalString("rg-affinity"),
+			deployRunnerGroupOpts(cliCtx)...,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("run %d: %w", i, err)
+		}
+		results = append(results, *rgResult)
+	}
+
+	summary, suspicious, err := summarizeRuns(results)
+	if err != nil {
+		return nil, err
+	}
+
+	if summary.HighVariance {
+		log.GetLogger(ctx).
+			WithKeyValues("level", "warn").
+			LogKV("msg", "results are high-variance, consider more runs",
+				"cv", summary.CV, "threshold", highVarianceCV)
+	}
+	if len(suspicious) > 0 {
+		log.GetLogger(ctx).
+			WithKeyValues("level", "warn").
+			LogKV("msg", "found suspicious runs", "runs", suspicious)
+	}
+
+	return &internaltypes.MultiRunReport{
+		Description: fmt.Sprintf(`
+Environment: the cluster as it is
+Workload: none
+Mode: runner group spec %s run %d times`, rgCfgFile, runs),
+		LoadSpec:       rgSpec,
+		Runs:           results,
+		Summary:        summary,
+		SuspiciousRuns: suspicious,
+	}, nil
+}
+
+// summarizeRuns computes the statistics of P99 latency across runs and
+// returns the indexes of runs whose z-score is beyond suspiciousRunZScore.
+func summarizeRuns(results []types.RunnerGroupsReport) (internaltypes.MultiRunSummary, []int, error) {
+	var summary internaltypes.MultiRunSummary
+
+	if len(results) == 0 {
+		return summary, nil, fmt.Errorf("no run to summarize")
+	}
+
+	p99s := make([]float64, 0, len(results))
+	for i, r := range results {
+		p99, ok := p99Latency(r.PercentileLatencies)
+		if !ok {
+			return summary, nil, fmt.Errorf("run %d has no P99 latency", i)
+		}
+		p99s = append(p99s, p99)
+	}
+
+	summary.P99Min, summary.P99Max = p99s[0], p99s[0]
+	sum := 0.0
+	for _, v := range p99s {
+		sum += v
+		summary.P99Min = math.Min(summary.P99Min, v)
+		summary.P99Max = math.Max(summary.P99Max, v)
+	}
+	summary.P99Mean = sum / float64(len(p99s))
+
+	if len(p99s) > 1 {
+		variance := 0.0
+		for _, v := range p99s {
+			variance += (v - summary.P99Mean) * (v - summary.P99Mean)
+		}
+		summary.P99StdDev = math.Sqrt(variance / float64(len(p99s)-1))
+	}
+	if summary.P99Mean > 0 {
+		summary.CV = summary.P99StdDev / summary.P99Mean
+	}
+	summary.HighVariance = summary.CV > highVarianceCV
+
+	var suspicious []int
+	if summary.P99StdDev > 0 {
+		for i, v := range p99s {
+			if math.Abs(v-summary.P99Mean)/summary.P99StdDev > suspiciousRunZScore {
+				suspicious = append(suspicious, i)
+			}
+		}
+	}
+	return summary, suspicious, nil
+}
+
+// p99Latency returns P99 latency from percentile latencies.
+func p99Latency(percentiles [][2]float64) (float64, bool) {
+	for _, p := range percentiles {
+		if p[0] == 0.99 {
+			return p[1], true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package bench
+
+import (
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReportWithP99(p99 float64) types.RunnerGroupsReport {
+	return types.RunnerGroupsReport{
+		PercentileLatencies: [][2]float64{{0.5, p99 / 2}, {0.99, p99}, {1, p99 * 2}},
+	}
+}
+
+func TestSummarizeRuns(t *testing.T) {
+	for name, tc := range map[string]struct {
+		p99s           []float64
+		mean           float64
+		stddev         float64
+		cv             float64
+		highVariance   bool
+		suspiciousRuns []int
+	}{
+		"stable": {
+			p99s:   []float64{0.1, 0.1, 0.1},
+			mean:   0.1,
+			stddev: 0,
+			cv:     0,
+		},
+		"high variance": {
+			p99s:         []float64{0.1, 0.2, 0.3},
+			mean:         0.2,
+			stddev:       0.1,
+			cv:           0.5,
+			highVariance: true,
+		},
+		"outlier": {
+			p99s:           []float64{0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 1.0},
+			mean:           0.19,
+			stddev:         0.284605,
+			cv:             1.497921,
+			highVariance:   true,
+			suspiciousRuns: []int{9},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			results := make([]types.RunnerGroupsReport, 0, len(tc.p99s))
+			for _, v := range tc.p99s {
+				results = append(results, newReportWithP99(v))
+			}
+
+			summary, suspicious, err := summarizeRuns(results)
+			require.NoError(t, err)
+			assert.InDelta(t, tc.mean, summary.P99Mean, 1e-6)
+			assert.InDelta(t, tc.stddev, summary.P99StdDev, 1e-6)
+			assert.InDelta(t, tc.cv, summary.CV, 1e-6)
+			assert.Equal(t, tc.highVariance, summary.HighVariance)
+			assert.Equal(t, tc.suspiciousRuns, suspicious)
+		})
+	}
+}
+
+func TestSummarizeRunsMinMax(t *testing.T) {
+	summary, _, err := summarizeRuns([]types.RunnerGroupsReport{
+		newReportWithP99(0.3), newReportWithP99(0.1), newReportWithP99(0.2),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0.1, summary.P99Min)
+	assert.Equal(t, 0.3, summary.P99Max)
+}
+
+func TestSummarizeRunsWithoutP99(t *testing.T) {
+	_, _, err := summarizeRuns([]types.RunnerGroupsReport{newReportWithP99(0.1), {}})
+	assert.ErrorContains(t, err, "run 1 has no P99 latency")
+}
@@ -46,10 +46,19 @@ The test suite is to generate configmaps in a namespace and list them. The load
 			Usage: "Duration of the benchmark in seconds. It will be ignored if --total is set.",
 			Value: 0,
 		},
+		loadProfileFlag,
 	},
 	Action: func(cliCtx *cli.Context) error {
 		_, err := renderBenchmarkReportInterceptor(
-			addAPIServerCoresInfoInterceptor(benchListConfigmapsRun),
+			addWarmupInfoInterceptor(
+				addRunnerPlacementInfoInterceptor(
+					addAPIServerCoresInfoInterceptor(
+						addAPIServerMetricsInfoInterceptor(
+							addLoadProfileInfoInterceptor(benchListConfigmapsRun),
+						),
+					),
+				),
+			),
 		)(cliCtx)
 		return err
 	},
@@ -112,6 +121,7 @@ func benchListConfigmapsRun(cliCtx *cli.Context) (*internaltypes.BenchmarkReport
 		rgCfgFile,
 		cliCtx.GlobalString("runner-flowcontrol"),
 		cliCtx.GlobalString("rg-affinity"),
+		deployRunnerGroupOpts(cliCtx)...,
 	)
 
 	if derr != nil {
@@ -45,7 +45,15 @@ var benchNode100Job10Pod10kCase = cli.Command{
 	),
 	Action: func(cliCtx *cli.Context) error {
 		_, err := renderBenchmarkReportInterceptor(
-			addAPIServerCoresInfoInterceptor(benchNode100Job10Pod10kCaseRun),
+			addWarmupInfoInterceptor(
+				addRunnerPlacementInfoInterceptor(
+					addAPIServerCoresInfoInterceptor(
+						addAPIServerMetricsInfoInterceptor(
+							addLoadProfileInfoInterceptor(benchNode100Job10Pod10kCaseRun),
+						),
+					),
+				),
+			),
 		)(cliCtx)
 		return err
 	},
@@ -57,12 +65,16 @@ func benchNode100Job10Pod10kCaseRun(cliCtx *cli.Context) (*internaltypes.Benchma
 
 	// Fixed benchmark configuration
 	const (
-		nodeCount  = 100
 		jobCount   = 10
 		podsPerJob = 1000
 		totalPods  = jobCount * podsPerJob // 10,000 pods
 	)
 
+	np, err := newVirtualNodepool(cliCtx, "node100job10pod10k", 100)
+	if err != nil {
+		return nil, err
+	}
+
 	rgCfgFile, rgSpec, rgCfgFileDone, err := newLoadProfileFromEmbed(cliCtx,
 		"loadprofile/node100_job10_pod10k.yaml")
 	if err != nil {
@@ -71,12 +83,7 @@ func benchNode100Job10Pod10kCaseRun(cliCtx *cli.Context) (*internaltypes.Benchma
 	defer func() { _ = rgCfgFileDone() }()
 
 	// Deploy virtual nodes
-	vcDone, err := deployVirtualNodepool(ctx, cliCtx, "node100job10pod10k",
-		nodeCount,
-		cliCtx.Int("cpu"),
-		cliCtx.Int("memory"),
-		cliCtx.Int("max-pods"),
-	)
+	vcDone, err := deployVirtualNodepool(ctx, cliCtx, np)
 	if err != nil {
 		return nil, fmt.Errorf("failed to deploy virtual node: %w", err)
 	}
@@ -107,6 +114,7 @@ func benchNode100Job10Pod10kCaseRun(cliCtx *cli.Context) (*internaltypes.Benchma
 		rgCfgFile,
 		cliCtx.GlobalString("runner-flowcontrol"),
 		cliCtx.GlobalString("rg-affinity"),
+		deployRunnerGroupOpts(cliCtx)...,
 	)
 	if err != nil {
 		return nil, err
@@ -117,9 +125,11 @@ func benchNode100Job10Pod10kCaseRun(cliCtx *cli.Context) (*internaltypes.Benchma
 		Environment: %d virtual nodes managed by kwok-controller,
 		Workload: Deploy %d jobs with %d pods each (total %d pods) with parallelism %d.
 		Measures read-only performance against stable workload.`,
-			nodeCount, jobCount, podsPerJob, totalPods, parallelism),
+			np.Nodes, jobCount, podsPerJob, totalPods, parallelism),
 		LoadSpec: *rgSpec,
 		Result:   *rgResult,
-		Info:     make(map[string]interface{}),
+		Info: map[string]interface{}{
+			"virtualNodepool": np,
+		},
 	}, nil
 }
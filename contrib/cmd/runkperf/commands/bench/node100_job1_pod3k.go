@@ -34,7 +34,15 @@ that nodes. It repeats to create and delete job. The load profile is fixed.
 	),
 	Action: func(cliCtx *cli.Context) error {
 		_, err := renderBenchmarkReportInterceptor(
-			addAPIServerCoresInfoInterceptor(benchNode100Job1Pod3KCaseRun),
+			addWarmupInfoInterceptor(
+				addRunnerPlacementInfoInterceptor(
+					addAPIServerCoresInfoInterceptor(
+						addAPIServerMetricsInfoInterceptor(
+							addLoadProfileInfoInterceptor(benchNode100Job1Pod3KCaseRun),
+						),
+					),
+				),
+			),
 		)(cliCtx)
 		return err
 	},
@@ -45,6 +53,11 @@ func benchNode100Job1Pod3KCaseRun(cliCtx *cli.Context) (*internaltypes.Benchmark
 	ctx := context.Background()
 	kubeCfgPath := cliCtx.GlobalString("kubeconfig")
 
+	np, err := newVirtualNodepool(cliCtx, "node100job1pod3k", 100)
+	if err != nil {
+		return nil, err
+	}
+
 	rgCfgFile, rgSpec, rgCfgFileDone, err := newLoadProfileFromEmbed(cliCtx,
 		"loadprofile/node100_job1_pod3k.yaml")
 	if err != nil {
@@ -52,12 +65,7 @@ func benchNode100Job1Pod3KCaseRun(cliCtx *cli.Context) (*internaltypes.Benchmark
 	}
 	defer func() { _ = rgCfgFileDone() }()
 
-	vcDone, err := deployVirtualNodepool(ctx, cliCtx, "node100job1pod3k",
-		100,
-		cliCtx.Int("cpu"),
-		cliCtx.Int("memory"),
-		cliCtx.Int("max-pods"),
-	)
+	vcDone, err := deployVirtualNodepool(ctx, cliCtx, np)
 	if err != nil {
 		return nil, fmt.Errorf("failed to deploy virtual node: %w", err)
 	}
@@ -81,6 +89,7 @@ func benchNode100Job1Pod3KCaseRun(cliCtx *cli.Context) (*internaltypes.Benchmark
 		rgCfgFile,
 		cliCtx.GlobalString("runner-flowcontrol"),
 		cliCtx.GlobalString("rg-affinity"),
+		deployRunnerGroupOpts(cliCtx)...,
 	)
 	jobCancel()
 	wg.Wait()
@@ -91,10 +100,12 @@ func benchNode100Job1Pod3KCaseRun(cliCtx *cli.Context) (*internaltypes.Benchmark
 
 	return &internaltypes.BenchmarkReport{
 		Description: fmt.Sprintf(`
-Environment: 100 virtual nodes managed by kwok-controller,
-Workload: Deploy 1 job with 3,000 pods repeatedly. The parallelism is 100. The interval is %v`, jobInterval),
+Environment: %d virtual nodes managed by kwok-controller,
+Workload: Deploy 1 job with 3,000 pods repeatedly. The parallelism is 100. The interval is %v`, np.Nodes, jobInterval),
 		LoadSpec: *rgSpec,
 		Result:   *rgResult,
-		Info:     make(map[string]interface{}),
+		Info: map[string]interface{}{
+			"virtualNodepool": np,
+		},
 	}, nil
 }
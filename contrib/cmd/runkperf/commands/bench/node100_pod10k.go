@@ -53,7 +53,15 @@ benchmark.
 	),
 	Action: func(cliCtx *cli.Context) error {
 		_, err := renderBenchmarkReportInterceptor(
-			addAPIServerCoresInfoInterceptor(benchNode100DeploymentNPod10KRun),
+			addWarmupInfoInterceptor(
+				addRunnerPlacementInfoInterceptor(
+					addAPIServerCoresInfoInterceptor(
+						addAPIServerMetricsInfoInterceptor(
+							addLoadProfileInfoInterceptor(benchNode100DeploymentNPod10KRun),
+						),
+					),
+				),
+			),
 		)(cliCtx)
 		return err
 	},
@@ -64,6 +72,12 @@ func benchNode100DeploymentNPod10KRun(cliCtx *cli.Context) (*internaltypes.Bench
 	ctx := context.Background()
 	kubeCfgPath := cliCtx.GlobalString("kubeconfig")
 
+	// NOTE: The nodepool name should be aligned with ../../../../internal/manifests/loadprofile/node100_pod10k.yaml.
+	np, err := newVirtualNodepool(cliCtx, "node100pod10k", 100)
+	if err != nil {
+		return nil, err
+	}
+
 	rgCfgFile, rgSpec, rgCfgFileDone, err := newLoadProfileFromEmbed(cliCtx,
 		"loadprofile/node100_pod10k.yaml")
 	if err != nil {
@@ -71,13 +85,7 @@ func benchNode100DeploymentNPod10KRun(cliCtx *cli.Context) (*internaltypes.Bench
 	}
 	defer func() { _ = rgCfgFileDone() }()
 
-	// NOTE: The nodepool name should be aligned with ../../../../internal/manifests/loadprofile/node100_pod10k.yaml.
-	vcDone, err := deployVirtualNodepool(ctx, cliCtx, "node100pod10k",
-		100,
-		cliCtx.Int("cpu"),
-		cliCtx.Int("memory"),
-		cliCtx.Int("max-pods"),
-	)
+	vcDone, err := deployVirtualNodepool(ctx, cliCtx, np)
 	if err != nil {
 		return nil, fmt.Errorf("failed to deploy virtual node: %w", err)
 	}
@@ -138,6 +146,7 @@ func benchNode100DeploymentNPod10KRun(cliCtx *cli.Context) (*internaltypes.Bench
 		rgCfgFile,
 		cliCtx.GlobalString("runner-flowcontrol"),
 		cliCtx.GlobalString("rg-affinity"),
+		deployRunnerGroupOpts(cliCtx)...,
 	)
 	dpCancel()
 	wg.Wait()
@@ -148,15 +157,16 @@ func benchNode100DeploymentNPod10KRun(cliCtx *cli.Context) (*internaltypes.Bench
 
 	return &internaltypes.BenchmarkReport{
 		Description: fmt.Sprintf(`
-Environment: 100 virtual nodes managed by kwok-controller,
+Environment: %d virtual nodes managed by kwok-controller,
 Workload: Deploy %d deployments with %d pods. Rolling-update deployments one by one and the interval is %v`,
-			total, total*replica, restartInterval),
+			np.Nodes, total, total*replica, restartInterval),
 
 		LoadSpec: *rgSpec,
 		Result:   *rgResult,
 		Info: map[string]interface{}{
-			"podSizeInBytes": podSize,
-			"interval":       restartInterval.String(),
+			"podSizeInBytes":  podSize,
+			"interval":        restartInterval.String(),
+			"virtualNodepool": np,
 		},
 	}, nil
 }
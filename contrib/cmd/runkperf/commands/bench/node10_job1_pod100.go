@@ -34,7 +34,15 @@ that nodes. It repeats to create and delete job. The load profile is fixed.
 	),
 	Action: func(cliCtx *cli.Context) error {
 		_, err := renderBenchmarkReportInterceptor(
-			addAPIServerCoresInfoInterceptor(benchNode10Job1Pod100CaseRun),
+			addWarmupInfoInterceptor(
+				addRunnerPlacementInfoInterceptor(
+					addAPIServerCoresInfoInterceptor(
+						addAPIServerMetricsInfoInterceptor(
+							addLoadProfileInfoInterceptor(benchNode10Job1Pod100CaseRun),
+						),
+					),
+				),
+			),
 		)(cliCtx)
 		return err
 	},
@@ -45,6 +53,11 @@ func benchNode10Job1Pod100CaseRun(cliCtx *cli.Context) (*internaltypes.Benchmark
 	ctx := context.Background()
 	kubeCfgPath := cliCtx.GlobalString("kubeconfig")
 
+	np, err := newVirtualNodepool(cliCtx, "node10job1pod100", 10)
+	if err != nil {
+		return nil, err
+	}
+
 	rgCfgFile, rgSpec, rgCfgFileDone, err := newLoadProfileFromEmbed(cliCtx,
 		"loadprofile/node10_job1_pod100.yaml")
 	if err != nil {
@@ -52,12 +65,7 @@ func benchNode10Job1Pod100CaseRun(cliCtx *cli.Context) (*internaltypes.Benchmark
 	}
 	defer func() { _ = rgCfgFileDone() }()
 
-	vcDone, err := deployVirtualNodepool(ctx, cliCtx, "node10job1pod100",
-		10,
-		cliCtx.Int("cpu"),
-		cliCtx.Int("memory"),
-		cliCtx.Int("max-pods"),
-	)
+	vcDone, err := deployVirtualNodepool(ctx, cliCtx, np)
 	if err != nil {
 		return nil, fmt.Errorf("failed to deploy virtual node: %w", err)
 	}
@@ -81,6 +89,7 @@ func benchNode10Job1Pod100CaseRun(cliCtx *cli.Context) (*internaltypes.Benchmark
 		rgCfgFile,
 		cliCtx.GlobalString("runner-flowcontrol"),
 		cliCtx.GlobalString("rg-affinity"),
+		deployRunnerGroupOpts(cliCtx)...,
 	)
 	jobCancel()
 	wg.Wait()
@@ -91,10 +100,12 @@ func benchNode10Job1Pod100CaseRun(cliCtx *cli.Context) (*internaltypes.Benchmark
 
 	return &internaltypes.BenchmarkReport{
 		Description: fmt.Sprintf(`
-Environment: 100 virtual nodes managed by kwok-controller,
-Workload: Deploy 1 job with 3,000 pods repeatedly. The parallelism is 100. The interval is %v`, jobInterval),
+Environment: %d virtual nodes managed by kwok-controller,
+Workload: Deploy 1 job with 100 pods repeatedly. The parallelism is 100. The interval is %v`, np.Nodes, jobInterval),
 		LoadSpec: *rgSpec,
 		Result:   *rgResult,
-		Info:     make(map[string]interface{}),
+		Info: map[string]interface{}{
+			"virtualNodepool": np,
+		},
 	}, nil
 }
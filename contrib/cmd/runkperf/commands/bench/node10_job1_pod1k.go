@@ -36,7 +36,15 @@ var benchNode10Job1Pod1kCase = cli.Command{
 	),
 	Action: func(cliCtx *cli.Context) error {
 		_, err := renderBenchmarkReportInterceptor(
-			addAPIServerCoresInfoInterceptor(benchNode10Job1Pod1kCaseRun),
+			addWarmupInfoInterceptor(
+				addRunnerPlacementInfoInterceptor(
+					addAPIServerCoresInfoInterceptor(
+						addAPIServerMetricsInfoInterceptor(
+							addLoadProfileInfoInterceptor(benchNode10Job1Pod1kCaseRun),
+						),
+					),
+				),
+			),
 		)(cliCtx)
 		return err
 	},
@@ -46,6 +54,11 @@ func benchNode10Job1Pod1kCaseRun(cliCtx *cli.Context) (*internaltypes.BenchmarkR
 	ctx := context.Background()
 	kubeCfgPath := cliCtx.GlobalString("kubeconfig")
 
+	np, err := newVirtualNodepool(cliCtx, "node10job1pod1k", 10)
+	if err != nil {
+		return nil, err
+	}
+
 	rgCfgFile, rgSpec, rgCfgFileDone, err := newLoadProfileFromEmbed(cliCtx,
 		"loadprofile/node10_job1_pod1k.yaml")
 	if err != nil {
@@ -53,12 +66,7 @@ func benchNode10Job1Pod1kCaseRun(cliCtx *cli.Context) (*internaltypes.BenchmarkR
 	}
 	defer func() { _ = rgCfgFileDone() }()
 
-	vcDone, err := deployVirtualNodepool(ctx, cliCtx, "node10job1pod1k",
-		10,
-		cliCtx.Int("cpu"),
-		cliCtx.Int("memory"),
-		cliCtx.Int("max-pods"),
-	)
+	vcDone, err := deployVirtualNodepool(ctx, cliCtx, np)
 	if err != nil {
 		return nil, fmt.Errorf("failed to deploy virtual node: %w", err)
 	}
@@ -83,6 +91,7 @@ func benchNode10Job1Pod1kCaseRun(cliCtx *cli.Context) (*internaltypes.BenchmarkR
 		rgCfgFile,
 		cliCtx.GlobalString("runner-flowcontrol"),
 		cliCtx.GlobalString("rg-affinity"),
+		deployRunnerGroupOpts(cliCtx)...,
 	)
 	jobCancel()
 	wg.Wait()
@@ -93,10 +102,12 @@ func benchNode10Job1Pod1kCaseRun(cliCtx *cli.Context) (*internaltypes.BenchmarkR
 
 	return &internaltypes.BenchmarkReport{
 		Description: fmt.Sprintf(`
-		Environment: 10 virtual nodes managed by kwok-controller,
-		Workload: Deploy 1 job with 1,000 pods repeatedly. The parallelism is 100. The interval is %v`, jobInterval),
+		Environment: %d virtual nodes managed by kwok-controller,
+		Workload: Deploy 1 job with 1,000 pods repeatedly. The parallelism is 100. The interval is %v`, np.Nodes, jobInterval),
 		LoadSpec: *rgSpec,
 		Result:   *rgResult,
-		Info:     make(map[string]interface{}),
+		Info: map[string]interface{}{
+			"virtualNodepool": np,
+		},
 	}, nil
 }
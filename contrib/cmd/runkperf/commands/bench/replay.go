@@ -0,0 +1,279 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package bench
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	internaltypes "github.com/Azure/kperf/contrib/internal/types"
+	"github.com/Azure/kperf/contrib/log"
+	"github.com/Azure/kperf/contrib/utils"
+
+	"github.com/urfave/cli"
+)
+
+// replayBucketInterval is the bucket size of time-series generated from
+// audit log.
+const replayBucketInterval = time.Second
+
+var benchReplayCase = cli.Command{
+	Name: "replay",
+	Usage: `
+Replay the requests recorded in kube-apiserver's audit log.
+The audit log, in JSON lines, is converted into time-series load profile with
+1s buckets. Only get, list, create and delete requests are replayed.
+	`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:     "audit-log",
+			Usage:    "Path to the audit log file in JSON lines",
+			Required: true,
+		},
+		cli.DurationFlag{
+			Name:  "duration",
+			Usage: "Only replay the requests within the given duration since the first request (0 means no limit)",
+			Value: 300 * time.Second,
+		},
+		cli.Float64Flag{
+			Name:  "time-scale",
+			Usage: "Scale bucket's start time (e.g. 0.5 replays twice as fast, 2 replays twice as slow)",
+			Value: 1,
+		},
+		cli.IntFlag{
+			Name:  "conns",
+			Usage: "Total number of connections",
+			Value: 10,
+		},
+		cli.StringFlag{
+			Name:  "content-type",
+			Usage: "Content type (json or protobuf)",
+			Value: "json",
+		},
+	},
+	Action: func(cliCtx *cli.Context) error {
+		_, err := renderBenchmarkReportInterceptor(
+			addWarmupInfoInterceptor(
+				addRunnerPlacementInfoInterceptor(
+					addAPIServerCoresInfoInterceptor(
+						addAPIServerMetricsInfoInterceptor(benchReplayCaseRun),
+					),
+				),
+			),
+		)(cliCtx)
+		return err
+	},
+}
+
+// benchReplayCaseRun is for benchReplayCase subcommand.
+func benchReplayCaseRun(cliCtx *cli.Context) (*internaltypes.BenchmarkReport, error) {
+	ctx := context.Background()
+
+	rgSpec, err := newReplayRunnerGroupSpec(cliCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	rgCfgFile, rgCfgFileDone, err := newLoadProfileFromSpec(cliCtx, rgSpec)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rgCfgFileDone() }()
+
+	rgResult, err := utils.DeployRunnerGroup(ctx,
+		cliCtx.GlobalString("kubeconfig"),
+		cliCtx.GlobalString("runner-image"),
+		rgCfgFile,
+		cliCtx.GlobalString("runner-flowcontrol"),
+		cliCtx.GlobalString("rg-affinity"),
+		deployRunnerGroupOpts(cliCtx)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &internaltypes.BenchmarkReport{
+		Description: fmt.Sprintf(`
+Environment: the cluster as it is
+Workload: none
+Mode: time-series replay of audit log %s within %v, with time scale %v`,
+			cliCtx.String("audit-log"), cliCtx.Duration("duration"), cliCtx.Float64("time-scale")),
+		LoadSpec: *rgSpec,
+		Result:   *rgResult,
+		Info:     make(map[string]interface{}),
+	}, nil
+}
+
+// newReplayRunnerGroupSpec generates one runner's spec from audit log.
+func newReplayRunnerGroupSpec(cliCtx *cli.Context) (*types.RunnerGroupSpec, error) {
+	timeScale := cliCtx.Float64("time-scale")
+	if timeScale <= 0 {
+		return nil, fmt.Errorf("invalid time-scale value: %v, requires > 0", timeScale)
+	}
+
+	conns := cliCtx.Int("conns")
+	if conns <= 0 {
+		return nil, fmt.Errorf("invalid conns value: %v, requires > 0", conns)
+	}
+
+	auditLog := cliCtx.String("audit-log")
+	f, err := os.Open(auditLog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", auditLog, err)
+	}
+	defer f.Close()
+
+	tsConfig, skipped, err := newTimeSeriesConfigFromAuditLog(f, cliCtx.Duration("duration"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse audit log %s: %w", auditLog, err)
+	}
+	tsConfig.TimeScale = timeScale
+
+	total := 0
+	for _, b := range tsConfig.Buckets {
+		total += len(b.Requests)
+	}
+	log.GetLogger(context.TODO()).
+		WithKeyValues("level", "info").
+		LogKV("msg", "parsed audit log", "requests", total, "buckets", len(tsConfig.Buckets), "skipped", skipped)
+
+	return &types.RunnerGroupSpec{
+		Count: 1,
+		Profile: &types.LoadProfile{
+			Version:     1,
+			Description: fmt.Sprintf("replay %s", auditLog),
+			Spec: types.LoadProfileSpec{
+				Conns:      conns,
+				Client:     conns,
+				Mode:       types.ModeTimeSeries,
+				ModeConfig: tsConfig,
+			},
+		},
+	}, nil
+}
+
+// auditEvent is the subset of audit.k8s.io/v1 Event used by replay.
+type auditEvent struct {
+	AuditID    string `json:"auditID"`
+	Verb       string `json:"verb"`
+	RequestURI string `json:"requestURI"`
+	ObjectRef  *struct {
+		Resource    string `json:"resource"`
+		Namespace   string `json:"namespace"`
+		Name        string `json:"name"`
+		APIGroup    string `json:"apiGroup"`
+		APIVersion  string `json:"apiVersion"`
+		Subresource string `json:"subresource"`
+	} `json:"objectRef"`
+	RequestReceivedTimestamp time.Time `json:"requestReceivedTimestamp"`
+}
+
+// auditVerbToMethods maps the replayable verbs to ExactRequest's methods.
+var auditVerbToMethods = map[string]string{
+	"get":    "GET",
+	"list":   "LIST",
+	"create": "POST",
+	"delete": "DELETE",
+}
+
+// newTimeSeriesConfigFromAuditLog converts audit log in JSON lines into
+// time-series config. The requests received after the given duration since
+// the first one are dropped if duration > 0. It also returns the number of
+// requests which can't be replayed, like watch and subresource requests.
+func newTimeSeriesConfigFromAuditLog(r io.Reader, duration time.Duration) (*types.TimeSeriesConfig, int, error) {
+	type entry struct {
+		ts  time.Time
+		req types.ExactRequest
+	}
+
+	var entries []entry
+	skipped := 0
+	// NOTE: Each request can be logged at multiple stages.
+	seen := map[string]struct{}{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var ev auditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			return nil, 0, fmt.Errorf("invalid event at line %d: %w", line, err)
+		}
+
+		if ev.AuditID != "" {
+			if _, ok := seen[ev.AuditID]; ok {
+				continue
+			}
+			seen[ev.AuditID] = struct{}{}
+		}
+
+		method, ok := auditVerbToMethods[ev.Verb]
+		if !ok || ev.ObjectRef == nil || ev.ObjectRef.Subresource != "" || ev.RequestReceivedTimestamp.IsZero() {
+			skipped++
+			continue
+		}
+
+		req := types.ExactRequest{
+			Method:    method,
+			Group:     ev.ObjectRef.APIGroup,
+			Version:   ev.ObjectRef.APIVersion,
+			Resource:  ev.ObjectRef.Resource,
+			Namespace: ev.ObjectRef.Namespace,
+			Name:      ev.ObjectRef.Name,
+		}
+		if u, err := url.Parse(ev.RequestURI); err == nil {
+			query := u.Query()
+			req.LabelSelector = query.Get("labelSelector")
+			req.FieldSelector = query.Get("fieldSelector")
+			req.ResourceVersion = query.Get("resourceVersion")
+			if limit, err := strconv.Atoi(query.Get("limit")); err == nil {
+				req.Limit = limit
+			}
+		}
+		entries = append(entries, entry{ts: ev.RequestReceivedTimestamp, req: req})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if len(entries) == 0 {
+		return nil, 0, fmt.Errorf("no replayable request")
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ts.Before(entries[j].ts)
+	})
+
+	config := &types.TimeSeriesConfig{
+		Interval: replayBucketInterval.String(),
+	}
+	first := entries[0].ts
+	for _, e := range entries {
+		offset := e.ts.Sub(first)
+		if duration > 0 && offset >= duration {
+			break
+		}
+
+		startTime := math.Floor(offset.Seconds()/replayBucketInterval.Seconds()) * replayBucketInterval.Seconds()
+		if n := len(config.Buckets); n == 0 || config.Buckets[n-1].StartTime != startTime {
+			config.Buckets = append(config.Buckets, types.RequestBucket{StartTime: startTime})
+		}
+		last := &config.Buckets[len(config.Buckets)-1]
+		last.Requests = append(last.Requests, e.req)
+	}
+	return config, skipped, nil
+}
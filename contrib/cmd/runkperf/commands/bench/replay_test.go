@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package bench
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+// syntheticAuditLog has 10 entries. The first request is logged at two
+// stages, and watch, subresource and non-resource requests are skipped.
+var syntheticAuditLog = strings.Join([]string{
+	`{"auditID":"1","stage":"RequestReceived","verb":"list","requestURI":"/api/v1/namespaces/default/pods?limit=500&resourceVersion=0","objectRef":{"resource":"pods","namespace":"default","apiVersion":"v1"},"requestReceivedTimestamp":"2024-01-01T00:00:00.000000Z"}`,
+	`{"auditID":"1","stage":"ResponseComplete","verb":"list","requestURI":"/api/v1/namespaces/default/pods?limit=500&resourceVersion=0","objectRef":{"resource":"pods","namespace":"default","apiVersion":"v1"},"requestReceivedTimestamp":"2024-01-01T00:00:00.000000Z"}`,
+	`{"auditID":"2","stage":"ResponseComplete","verb":"get","requestURI":"/api/v1/namespaces/default/pods/a","objectRef":{"resource":"pods","namespace":"default","name":"a","apiVersion":"v1"},"requestReceivedTimestamp":"2024-01-01T00:00:00.500000Z"}`,
+	`{"auditID":"3","stage":"ResponseComplete","verb":"watch","requestURI":"/api/v1/pods?watch=true","objectRef":{"resource":"pods","apiVersion":"v1"},"requestReceivedTimestamp":"2024-01-01T00:00:00.600000Z"}`,
+	`{"auditID":"4","stage":"ResponseComplete","verb":"get","requestURI":"/api/v1/namespaces/default/pods/a/log","objectRef":{"resource":"pods","namespace":"default","name":"a","apiVersion":"v1","subresource":"log"},"requestReceivedTimestamp":"2024-01-01T00:00:00.700000Z"}`,
+	`{"auditID":"5","stage":"ResponseComplete","verb":"get","requestURI":"/healthz","requestReceivedTimestamp":"2024-01-01T00:00:00.800000Z"}`,
+	`{"auditID":"7","stage":"ResponseComplete","verb":"create","requestURI":"/apis/apps/v1/namespaces/default/deployments","objectRef":{"resource":"deployments","namespace":"default","apiGroup":"apps","apiVersion":"v1"},"requestReceivedTimestamp":"2024-01-01T00:00:02.100000Z"}`,
+	`{"auditID":"6","stage":"ResponseComplete","verb":"list","requestURI":"/api/v1/configmaps?labelSelector=app%3Dx","objectRef":{"resource":"configmaps","apiVersion":"v1"},"requestReceivedTimestamp":"2024-01-01T00:00:01.200000Z"}`,
+	`{"auditID":"8","stage":"ResponseComplete","verb":"delete","requestURI":"/api/v1/namespaces/default/configmaps/b","objectRef":{"resource":"configmaps","namespace":"default","name":"b","apiVersion":"v1"},"requestReceivedTimestamp":"2024-01-01T00:00:02.900000Z"}`,
+	`{"auditID":"9","stage":"ResponseComplete","verb":"get","requestURI":"/api/v1/namespaces/default/pods/a","objectRef":{"resource":"pods","namespace":"default","name":"a","apiVersion":"v1"},"requestReceivedTimestamp":"2024-01-01T00:00:03.000000Z"}`,
+	"",
+}, "\n")
+
+func TestReplayRunnerGroupSpec(t *testing.T) {
+	auditLog := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, os.WriteFile(auditLog, []byte(syntheticAuditLog), 0600))
+
+	var rgSpec types.RunnerGroupSpec
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{
+		cli.StringFlag{Name: "rg-affinity", Value: "kperf=runner"},
+	}
+	app.Commands = []cli.Command{
+		{
+			Name:  benchReplayCase.Name,
+			Flags: benchReplayCase.Flags,
+			Action: func(cliCtx *cli.Context) error {
+				spec, err := newReplayRunnerGroupSpec(cliCtx)
+				if err != nil {
+					return err
+				}
+
+				rgCfgFile, rgCfgFileDone, err := newLoadProfileFromSpec(cliCtx, spec)
+				if err != nil {
+					return err
+				}
+				defer func() { _ = rgCfgFileDone() }()
+
+				data, err := os.ReadFile(rgCfgFile)
+				if err != nil {
+					return err
+				}
+				return yaml.Unmarshal(data, &rgSpec)
+			},
+		},
+	}
+	require.NoError(t, app.Run([]string{"runkperf", "replay",
+		"--audit-log", auditLog, "--duration", "3s", "--time-scale", "0.5", "--conns", "2"}))
+
+	assert.Equal(t, int32(1), rgSpec.Count)
+	assert.Equal(t, map[string][]string{"kperf": {"runner"}}, rgSpec.NodeAffinity)
+
+	profile := rgSpec.Profile
+	require.NotNil(t, profile)
+	require.NoError(t, profile.Validate())
+	assert.Equal(t, 2, profile.Spec.Conns)
+	assert.Equal(t, types.ContentTypeJSON, profile.Spec.ContentType)
+
+	tsConfig, ok := profile.Spec.ModeConfig.(*types.TimeSeriesConfig)
+	require.True(t, ok)
+	require.NoError(t, tsConfig.Validate(nil))
+	assert.Equal(t, "1s", tsConfig.Interval)
+	assert.Equal(t, 0.5, tsConfig.TimeScale)
+
+	// The last request at 3s is out of duration.
+	assert.Equal(t, []types.RequestBucket{
+		{
+			StartTime: 0,
+			Requests: []types.ExactRequest{
+				{Method: "LIST", Version: "v1", Resource: "pods", Namespace: "default", Limit: 500, ResourceVersion: "0"},
+				{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "a"},
+			},
+		},
+		{
+			StartTime: 1,
+			Requests: []types.ExactRequest{
+				{Method: "LIST", Version: "v1", Resource: "configmaps", LabelSelector: "app=x"},
+			},
+		},
+		{
+			StartTime: 2,
+			Requests: []types.ExactRequest{
+				{Method: "POST", Group: "apps", Version: "v1", Resource: "deployments", Namespace: "default"},
+				{Method: "DELETE", Version: "v1", Resource: "configmaps", Namespace: "default", Name: "b"},
+			},
+		},
+	}, tsConfig.Buckets)
+
+	_, _, err := newTimeSeriesConfigFromAuditLog(strings.NewReader(`{"verb":"watch"}`), 0)
+	assert.Error(t, err)
+}
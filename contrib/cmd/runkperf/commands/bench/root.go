@@ -4,6 +4,8 @@
 package bench
 
 import (
+	"fmt"
+
 	kperfcmdutils "github.com/Azure/kperf/cmd/kperf/commands/utils"
 
 	"github.com/urfave/cli"
@@ -44,6 +46,22 @@ var Command = cli.Command{
 			Usage: "Deploy runner group with a specific labels (FORMAT: KEY=VALUE[,VALUE])",
 			Value: "node.kubernetes.io/instance-type=Standard_D16s_v3,m4.4xlarge,n1-standard-16",
 		},
+		cli.StringFlag{
+			Name:  "runner-cpu",
+			Usage: "The cpu request of each runner, like 500m or 2",
+		},
+		cli.StringFlag{
+			Name:  "runner-memory",
+			Usage: "The memory request of each runner, like 4Gi",
+		},
+		cli.StringSliceFlag{
+			Name:  "runner-node-selector",
+			Usage: "Deploy runners to the node with the label (FORMAT: KEY=VALUE) (can specify multiple times)",
+		},
+		cli.StringSliceFlag{
+			Name:  "runner-tolerations",
+			Usage: "Toleration of runners (FORMAT: KEY[=VALUE][:EFFECT] or JSON) (can specify multiple times)",
+		},
 		cli.BoolFlag{
 			Name:   "eks",
 			Usage:  "Indicates the target kubernetes cluster is EKS",
@@ -53,6 +71,47 @@ var Command = cli.Command{
 			Name:  "result",
 			Usage: "Path to the file which stores results",
 		},
+		cli.StringFlag{
+			Name:  "apiserver-metrics",
+			Usage: "Comma-separated kube-apiserver metrics scraped before and after benchmark into report (empty means disabled)",
+			Value: defaultAPIServerMetrics,
+		},
+		cli.DurationFlag{
+			Name:  "apiserver-metrics-interval",
+			Usage: "Scrape kube-apiserver metrics on the interval during benchmark as well (0 means disabled)",
+		},
+		cli.StringFlag{
+			Name:  "baseline",
+			Usage: fmt.Sprintf("Path to the benchmark report to compare with. Exit with %d if any metric regresses beyond thresholds", regressionExitCode),
+		},
+		cli.Float64Flag{
+			Name:  "regression-p99-threshold",
+			Usage: "Max ratio of P99 latency increase compared to baseline, like 0.1 for 10%",
+			Value: 0.1,
+		},
+		cli.Float64Flag{
+			Name:  "regression-error-rate-threshold",
+			Usage: "Max increase of error rate compared to baseline, like 0.01 for 1%",
+			Value: 0.01,
+		},
+		cli.Float64Flag{
+			Name:  "regression-qps-threshold",
+			Usage: "Max ratio of achieved QPS decrease compared to baseline, like 0.1 for 10%",
+			Value: 0.1,
+		},
+		cli.DurationFlag{
+			Name:  "warmup-duration",
+			Usage: "Run the same load profile at reduced rate for the duration before the measured run and discard its result (0 means no warm-up). Only weighted-random mode is supported",
+		},
+		cli.Float64Flag{
+			Name:  "warmup-rate-ratio",
+			Usage: "The ratio of warm-up's rate to the load profile's rate, in (0, 1]",
+			Value: 0.5,
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "Fail with runner pods' phases and last logs if runner group doesn't finish in time (0 means no timeout)",
+		},
 	},
 	Subcommands: []cli.Command{
 		benchNode10Job1Pod100Case,
@@ -64,6 +123,9 @@ var Command = cli.Command{
 		benchNode100Job10Pod10kCase,
 		benchReadUpdateCase,
 		benchTimeSeriesSimpleCase,
+		benchReplayCase,
+		benchCompareRunsCase,
+		benchWatchFanoutCase,
 	},
 }
 
@@ -73,7 +135,15 @@ var Command = cli.Command{
 // easy to extend existing configuration. If the subcommand extends it with
 // its own option, the user can just append options, like `subcommand --options
 // xyz.
-var commonFlags = []cli.Flag{
+var commonFlags = append([]cli.Flag{loadProfileFlag}, clusterFlags...)
+
+// clusterFlags configures virtual nodes and content type. They are used
+// without --load-profile by the subcommands which generate load profile.
+var clusterFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "nodes",
+		Usage: "The number of virtual nodes (0 means the case's default)",
+	},
 	cli.IntFlag{
 		Name:  "cpu",
 		Usage: "the allocatable cpu resource per node",
@@ -89,9 +159,23 @@ var commonFlags = []cli.Flag{
 		Usage: "The maximum Pods per node",
 		Value: 110,
 	},
+	cli.StringSliceFlag{
+		Name:  "node-labels",
+		Usage: "Additional labels to virtual node (FORMAT: KEY=VALUE)",
+	},
+	cli.StringSliceFlag{
+		Name:  "node-taints",
+		Usage: "Additional taints to virtual node (FORMAT: KEY[=VALUE]:EFFECT)",
+	},
 	cli.StringFlag{
 		Name:  "content-type",
 		Usage: "Content type (json or protobuf)",
 		Value: "json",
 	},
 }
+
+// loadProfileFlag overrides the embedded load profile of subcommand.
+var loadProfileFlag = cli.StringFlag{
+	Name:  "load-profile",
+	Usage: "Path to the load profile (RunnerGroupSpec) file used instead of the embedded one",
+}
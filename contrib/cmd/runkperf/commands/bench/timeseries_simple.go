@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Azure/kperf/api/types"
 	internaltypes "github.com/Azure/kperf/contrib/internal/types"
 	"github.com/Azure/kperf/contrib/utils"
 
@@ -22,10 +23,32 @@ Test time-series replay mode with a simple 3-bucket pattern.
 This benchmark replays exact API requests in time-bucketed intervals to simulate
 real production traffic patterns captured from audit logs.
 	`,
-	Flags: commonFlags,
+	Flags: append(
+		[]cli.Flag{
+			cli.IntFlag{
+				Name:  "replay-count",
+				Usage: "Replay the bucket sequence N times",
+				Value: 1,
+			},
+			cli.Float64Flag{
+				Name:  "time-scale",
+				Usage: "Scale bucket's start time (e.g. 0.5 replays twice as fast, 2 replays twice as slow)",
+				Value: 1,
+			},
+		},
+		commonFlags...,
+	),
 	Action: func(cliCtx *cli.Context) error {
 		_, err := renderBenchmarkReportInterceptor(
-			addAPIServerCoresInfoInterceptor(benchTimeSeriesSimpleCaseRun),
+			addWarmupInfoInterceptor(
+				addRunnerPlacementInfoInterceptor(
+					addAPIServerCoresInfoInterceptor(
+						addAPIServerMetricsInfoInterceptor(
+							addLoadProfileInfoInterceptor(benchTimeSeriesSimpleCaseRun),
+						),
+					),
+				),
+			),
 		)(cliCtx)
 		return err
 	},
@@ -36,6 +59,11 @@ func benchTimeSeriesSimpleCaseRun(cliCtx *cli.Context) (*internaltypes.Benchmark
 	ctx := context.Background()
 	kubeCfgPath := cliCtx.GlobalString("kubeconfig")
 
+	np, err := newVirtualNodepool(cliCtx, "timeseriestest", 10)
+	if err != nil {
+		return nil, err
+	}
+
 	rgCfgFile, rgSpec, rgCfgFileDone, err := newLoadProfileFromEmbed(cliCtx,
 		"loadprofile/timeseries_simple.yaml")
 	if err != nil {
@@ -43,12 +71,7 @@ func benchTimeSeriesSimpleCaseRun(cliCtx *cli.Context) (*internaltypes.Benchmark
 	}
 	defer func() { _ = rgCfgFileDone() }()
 
-	vcDone, err := deployVirtualNodepool(ctx, cliCtx, "timeseriestest",
-		10,
-		cliCtx.Int("cpu"),
-		cliCtx.Int("memory"),
-		cliCtx.Int("max-pods"),
-	)
+	vcDone, err := deployVirtualNodepool(ctx, cliCtx, np)
 	if err != nil {
 		return nil, fmt.Errorf("failed to deploy virtual node: %w", err)
 	}
@@ -72,6 +95,7 @@ func benchTimeSeriesSimpleCaseRun(cliCtx *cli.Context) (*internaltypes.Benchmark
 		rgCfgFile,
 		cliCtx.GlobalString("runner-flowcontrol"),
 		cliCtx.GlobalString("rg-affinity"),
+		deployRunnerGroupOpts(cliCtx)...,
 	)
 	jobCancel()
 	wg.Wait()
@@ -82,11 +106,37 @@ func benchTimeSeriesSimpleCaseRun(cliCtx *cli.Context) (*internaltypes.Benchmark
 
 	return &internaltypes.BenchmarkReport{
 		Description: fmt.Sprintf(`
-Environment: 10 virtual nodes managed by kwok-controller
+Environment: %d virtual nodes managed by kwok-controller
 Workload: Deploy 1 job with 100 pods repeatedly. The parallelism is 100. The interval is %v
-Mode: time-series replay with 3 time buckets (1s intervals)`, jobInterval),
+Mode: time-series replay with 3 time buckets (1s intervals), replayed %d times with time scale %v`,
+			np.Nodes, jobInterval, cliCtx.Int("replay-count"), cliCtx.Float64("time-scale")),
 		LoadSpec: *rgSpec,
 		Result:   *rgResult,
-		Info:     make(map[string]interface{}),
+		Info: map[string]interface{}{
+			"virtualNodepool": np,
+		},
 	}, nil
 }
+
+// tweakTimeSeriesProfile applies --replay-count and --time-scale to the
+// time-series load profile.
+func tweakTimeSeriesProfile(cliCtx *cli.Context, spec *types.RunnerGroupSpec) error {
+	tsConfig, ok := spec.Profile.Spec.ModeConfig.(*types.TimeSeriesConfig)
+	if !ok || tsConfig == nil {
+		return fmt.Errorf("expected time-series mode config, got %T", spec.Profile.Spec.ModeConfig)
+	}
+
+	replayCount := cliCtx.Int("replay-count")
+	if replayCount < 1 {
+		return fmt.Errorf("invalid replay-count value: %v, requires >= 1", replayCount)
+	}
+
+	timeScale := cliCtx.Float64("time-scale")
+	if timeScale <= 0 {
+		return fmt.Errorf("invalid time-scale value: %v, requires > 0", timeScale)
+	}
+
+	tsConfig.Repeat = replayCount - 1
+	tsConfig.TimeScale = timeScale
+	return nil
+}
@@ -5,10 +5,12 @@ package bench
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/Azure/kperf/api/types"
 	kperfcmdutils "github.com/Azure/kperf/cmd/kperf/commands/utils"
@@ -59,48 +61,138 @@ func addAPIServerCoresInfoInterceptor(handler subcmdActionFunc) subcmdActionFunc
 	}
 }
 
-// renderBenchmarkReportInterceptor renders benchmark report into file or stdout.
-func renderBenchmarkReportInterceptor(handler subcmdActionFunc) subcmdActionFunc {
+// addLoadProfileInfoInterceptor adds the path and SHA-256 of the load profile
+// file specified by --load-profile into benchmark report.
+func addLoadProfileInfoInterceptor(handler subcmdActionFunc) subcmdActionFunc {
 	return func(cliCtx *cli.Context) (*internaltypes.BenchmarkReport, error) {
 		report, err := handler(cliCtx)
 		if err != nil {
 			return nil, err
 		}
 
-		outF := os.Stdout
-		if targetFile := cliCtx.GlobalString("result"); targetFile != "" {
-			targetFileDir := filepath.Dir(targetFile)
+		loadProfilePath := cliCtx.String("load-profile")
+		if loadProfilePath == "" {
+			return report, nil
+		}
 
-			_, err = os.Stat(targetFileDir)
-			if err != nil && os.IsNotExist(err) {
-				err = os.MkdirAll(targetFileDir, 0750)
-			}
-			if err != nil {
-				return nil, fmt.Errorf("failed to ensure output's dir %s: %w", targetFileDir, err)
-			}
+		data, err := os.ReadFile(loadProfilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", loadProfilePath, err)
+		}
 
-			outF, err = os.Create(targetFile)
-			if err != nil {
-				return nil, err
-			}
-			defer outF.Close()
+		report.Info["loadProfile"] = map[string]interface{}{
+			"path": loadProfilePath,
+			"hash": fmt.Sprintf("%x", sha256.Sum256(data)),
 		}
+		return report, nil
+	}
+}
 
-		encoder := json.NewEncoder(outF)
-		encoder.SetIndent("", "  ")
+// renderBenchmarkReportInterceptor renders benchmark report into file or
+// stdout. If --baseline is set, the report contains the comparison with
+// baseline, and it returns error with regressionExitCode after rendering if
+// any metric regresses.
+func renderBenchmarkReportInterceptor(handler subcmdActionFunc) subcmdActionFunc {
+	return func(cliCtx *cli.Context) (*internaltypes.BenchmarkReport, error) {
+		report, err := handler(cliCtx)
+		if err != nil {
+			return nil, err
+		}
 
-		if err := encoder.Encode(report); err != nil {
-			return nil, fmt.Errorf("failed to encode json: %w", err)
+		report.Name = cliCtx.Command.Name
+		if err := compareWithBaseline(cliCtx, report); err != nil {
+			return nil, err
 		}
-		return report, nil
+
+		if err := renderReport(cliCtx, report); err != nil {
+			return nil, err
+		}
+		return report, regressionError(report.Comparison)
+	}
+}
+
+// renderReport encodes report in JSON into file specified by --result or
+// stdout.
+func renderReport(cliCtx *cli.Context, report interface{}) error {
+	outF := os.Stdout
+	if targetFile := cliCtx.GlobalString("result"); targetFile != "" {
+		targetFileDir := filepath.Dir(targetFile)
+
+		_, err := os.Stat(targetFileDir)
+		if err != nil && os.IsNotExist(err) {
+			err = os.MkdirAll(targetFileDir, 0750)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to ensure output's dir %s: %w", targetFileDir, err)
+		}
+
+		outF, err = os.Create(targetFile)
+		if err != nil {
+			return err
+		}
+		defer outF.Close()
 	}
+
+	encoder := json.NewEncoder(outF)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode json: %w", err)
+	}
+	return nil
+}
+
+// virtualNodepool is the setting of virtual nodepool used by benchmark.
+type virtualNodepool struct {
+	Name    string   `json:"name"`
+	Nodes   int      `json:"nodes"`
+	CPU     int      `json:"cpu"`
+	Memory  int      `json:"memory"`
+	MaxPods int      `json:"maxPods"`
+	Labels  []string `json:"labels,omitempty"`
+	Taints  []string `json:"taints,omitempty"`
+}
+
+// newVirtualNodepool returns virtual nodepool's setting from --nodes, --cpu,
+// --memory, --max-pods, --node-labels and --node-taints. The defaultNodes is
+// used if --nodes is not set.
+func newVirtualNodepool(cliCtx *cli.Context, name string, defaultNodes int) (*virtualNodepool, error) {
+	nodes := cliCtx.Int("nodes")
+	if nodes < 0 {
+		return nil, fmt.Errorf("invalid nodes value: %v", nodes)
+	}
+	if nodes == 0 {
+		nodes = defaultNodes
+	}
+
+	labels := cliCtx.StringSlice("node-labels")
+	if _, err := kperfcmdutils.KeyValueMap(labels); err != nil {
+		return nil, fmt.Errorf("failed to parse node-labels: %w", err)
+	}
+
+	taints := cliCtx.StringSlice("node-taints")
+	if _, err := kperfcmdutils.ParseTaints(taints); err != nil {
+		return nil, fmt.Errorf("failed to parse node-taints: %w", err)
+	}
+
+	return &virtualNodepool{
+		Name:    name,
+		Nodes:   nodes,
+		CPU:     cliCtx.Int("cpu"),
+		Memory:  cliCtx.Int("memory"),
+		MaxPods: cliCtx.Int("max-pods"),
+		Labels:  labels,
+		Taints:  taints,
+	}, nil
 }
 
 // deployVirtualNodepool deploys virtual nodepool.
-func deployVirtualNodepool(ctx context.Context, cliCtx *cli.Context, target string, nodes, cpu, memory, maxPods int) (func() error, error) {
+func deployVirtualNodepool(ctx context.Context, cliCtx *cli.Context, np *virtualNodepool) (func() error, error) {
+	target := np.Name
+
 	log.GetLogger(ctx).
 		WithKeyValues("level", "info").
-		LogKV("msg", "deploying virtual nodepool", "name", target)
+		LogKV("msg", "deploying virtual nodepool", "name", target, "nodes", np.Nodes)
 
 	kubeCfgPath := cliCtx.GlobalString("kubeconfig")
 	virtualNodeAffinity := cliCtx.GlobalString("vc-affinity")
@@ -127,7 +219,8 @@ func deployVirtualNodepool(ctx context.Context, cliCtx *cli.Context, target stri
 			LogKV("msg", "failed to delete nodepool", "name", target, "error", err)
 	}
 
-	err = kr.NewNodepool(ctx, 0, target, nodes, cpu, memory, maxPods, virtualNodeAffinity, sharedProviderID)
+	err = kr.NewNodepool(ctx, 0, target, np.Nodes, np.CPU, np.Memory, np.MaxPods,
+		virtualNodeAffinity, np.Labels, np.Taints, sharedProviderID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create nodepool %s: %w", target, err)
 	}
@@ -139,58 +232,226 @@ func deployVirtualNodepool(ctx context.Context, cliCtx *cli.Context, target stri
 
 func NewRunnerGroupSpecFromYamlFile() {}
 
+// deployRunnerGroupOpts returns the options of utils.DeployRunnerGroup from
+// global flags.
+func deployRunnerGroupOpts(cliCtx *cli.Context) []utils.DeployRunnerGroupOpt {
+	return []utils.DeployRunnerGroupOpt{
+		utils.WithDeployRunnerGroupTimeoutOpt(cliCtx.GlobalDuration("timeout")),
+		utils.WithDeployRunnerGroupWarmupOpt(cliCtx.GlobalDuration("warmup-duration"),
+			cliCtx.GlobalFloat64("warmup-rate-ratio")),
+		utils.WithDeployRunnerGroupRunnerPlacementOpt(utils.RunnerPlacement{
+			CPU:          cliCtx.GlobalString("runner-cpu"),
+			Memory:       cliCtx.GlobalString("runner-memory"),
+			NodeSelector: cliCtx.GlobalStringSlice("runner-node-selector"),
+			Tolerations:  cliCtx.GlobalStringSlice("runner-tolerations"),
+		}),
+	}
+}
+
+// runnerPlacement is the placement constraints of runners.
+type runnerPlacement struct {
+	Affinity     string                   `json:"affinity,omitempty"`
+	Resources    *types.RunnerResources   `json:"resources,omitempty"`
+	NodeSelector map[string]string        `json:"nodeSelector,omitempty"`
+	Tolerations  []types.RunnerToleration `json:"tolerations,omitempty"`
+}
+
+// newRunnerPlacement returns the placement of runners from --rg-affinity,
+// --runner-cpu, --runner-memory, --runner-node-selector and
+// --runner-tolerations. It validates them so that malformed flags fail before
+// anything is applied to the cluster.
+func newRunnerPlacement(cliCtx *cli.Context) (*runnerPlacement, error) {
+	spec := &types.RunnerGroupSpec{}
+	if cpu, memory := cliCtx.GlobalString("runner-cpu"), cliCtx.GlobalString("runner-memory"); cpu != "" || memory != "" {
+		spec.Resources = &types.RunnerResources{CPU: cpu, Memory: memory}
+	}
+
+	nodeSelector, err := kperfcmdutils.KeyValueMap(cliCtx.GlobalStringSlice("runner-node-selector"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse runner-node-selector: %w", err)
+	}
+	if len(nodeSelector) > 0 {
+		spec.NodeSelector = nodeSelector
+	}
+
+	spec.Tolerations, err = kperfcmdutils.ParseTolerations(cliCtx.GlobalStringSlice("runner-tolerations"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse runner-tolerations: %w", err)
+	}
+	if len(spec.Tolerations) == 0 {
+		spec.Tolerations = nil
+	}
+
+	if err := spec.ValidatePlacement(); err != nil {
+		return nil, err
+	}
+	return &runnerPlacement{
+		Affinity:     cliCtx.GlobalString("rg-affinity"),
+		Resources:    spec.Resources,
+		NodeSelector: spec.NodeSelector,
+		Tolerations:  spec.Tolerations,
+	}, nil
+}
+
+// addRunnerPlacementInfoInterceptor validates the placement of runners before
+// benchmark and adds it into benchmark report.
+func addRunnerPlacementInfoInterceptor(handler subcmdActionFunc) subcmdActionFunc {
+	return func(cliCtx *cli.Context) (*internaltypes.BenchmarkReport, error) {
+		placement, err := newRunnerPlacement(cliCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		report, err := handler(cliCtx)
+		if err != nil {
+			return nil, err
+		}
+		report.Info["runnerPlacement"] = placement
+		return report, nil
+	}
+}
+
+// warmupInfo is the warm-up performed before the measured run.
+type warmupInfo struct {
+	Duration  string  `json:"duration"`
+	RateRatio float64 `json:"rateRatio"`
+}
+
+// newWarmupInfo returns the warm-up from --warmup-duration and
+// --warmup-rate-ratio. It returns nil if warm-up is disabled.
+func newWarmupInfo(cliCtx *cli.Context) (*warmupInfo, error) {
+	duration := cliCtx.GlobalDuration("warmup-duration")
+	if duration == 0 {
+		return nil, nil
+	}
+	if duration < time.Second {
+		return nil, fmt.Errorf("invalid warmup-duration value: %v, requires 0 or >= 1s", duration)
+	}
+
+	ratio := cliCtx.GlobalFloat64("warmup-rate-ratio")
+	if ratio <= 0 || ratio > 1 {
+		return nil, fmt.Errorf("invalid warmup-rate-ratio value: %v, requires (0, 1]", ratio)
+	}
+	return &warmupInfo{Duration: duration.String(), RateRatio: ratio}, nil
+}
+
+// addWarmupInfoInterceptor adds the warm-up performed by --warmup-duration
+// into benchmark report. The flags are validated before benchmark so that
+// malformed ones fail before anything is applied to the cluster.
+func addWarmupInfoInterceptor(handler subcmdActionFunc) subcmdActionFunc {
+	return func(cliCtx *cli.Context) (*internaltypes.BenchmarkReport, error) {
+		warmup, err := newWarmupInfo(cliCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		report, err := handler(cliCtx)
+		if err != nil {
+			return nil, err
+		}
+		if warmup != nil {
+			report.Info["warmup"] = warmup
+		}
+		return report, nil
+	}
+}
+
 // newLoadProfileFromEmbed loads load profile from embed and tweaks that load
-// profile.
+// profile. If --load-profile is set, the load profile is loaded from that
+// file instead and validated after tweak.
 func newLoadProfileFromEmbed(cliCtx *cli.Context, name string) (_name string, _spec *types.RunnerGroupSpec, _cleanup func() error, _err error) {
 	var rgSpec types.RunnerGroupSpec
-	rgCfgFile, rgCfgFileDone, err := utils.NewRunnerGroupSpecFileFromEmbed(
-		name,
-		func(spec *types.RunnerGroupSpec) error {
-			reqs := cliCtx.Int("total")
-			if reqs < 0 {
-				return fmt.Errorf("invalid total-requests value: %v", reqs)
-			}
-			// Apply CLI overrides automatically
-			if spec != nil && spec.Profile.Spec.ModeConfig != nil {
-				overrides := types.BuildOverridesFromCLI(spec.Profile.Spec.ModeConfig, cliCtx)
-				if len(overrides) > 0 {
-					if err := spec.Profile.Spec.ModeConfig.ApplyOverrides(overrides); err != nil {
-						return fmt.Errorf("failed to apply config overrides: %w", err)
-					}
-				}
-			}
 
-			rgAffinity := cliCtx.GlobalString("rg-affinity")
-			affinityLabels, err := kperfcmdutils.KeyValuesMap([]string{rgAffinity})
-			if err != nil {
-				return fmt.Errorf("failed to parse %s affinity: %w", rgAffinity, err)
-			}
-			spec.NodeAffinity = affinityLabels
-			spec.Profile.Spec.ContentType = types.ContentType(cliCtx.String("content-type"))
-			data, _ := yaml.Marshal(spec)
-
-			// Tweak the load profile for read-update case
-			if cliCtx.Command.Name == "read_update" {
-				err = tweakReadUpdateProfile(cliCtx, spec)
-				if err != nil {
-					return fmt.Errorf("failed to tweak read-update profile: %w", err)
-				}
+	loadProfilePath := cliCtx.String("load-profile")
+	tweakFn := func(spec *types.RunnerGroupSpec) error {
+		if err := tweakRunnerGroupSpec(cliCtx, spec); err != nil {
+			return err
+		}
+		if loadProfilePath != "" {
+			if err := spec.Profile.Validate(); err != nil {
+				return fmt.Errorf("invalid load profile %s: %w", loadProfilePath, err)
 			}
+		}
+		rgSpec = *spec
+		return nil
+	}
 
-			log.GetLogger(context.TODO()).
-				WithKeyValues("level", "info").
-				LogKV("msg", "dump load profile", "config", string(data))
-
-			rgSpec = *spec
-			return nil
-		},
-	)
+	var rgCfgFile string
+	var rgCfgFileDone func() error
+	var err error
+	if loadProfilePath != "" {
+		rgCfgFile, rgCfgFileDone, err = utils.NewRunnerGroupSpecFileFromPath(loadProfilePath, tweakFn)
+	} else {
+		rgCfgFile, rgCfgFileDone, err = utils.NewRunnerGroupSpecFileFromEmbed(name, tweakFn)
+	}
 	if err != nil {
 		return "", nil, nil, err
 	}
 	return rgCfgFile, &rgSpec, rgCfgFileDone, nil
 }
 
+// newLoadProfileFromSpec tweaks the dynamically generated runner group spec
+// in the same way as newLoadProfileFromEmbed and writes it into temporary
+// file.
+func newLoadProfileFromSpec(cliCtx *cli.Context, spec *types.RunnerGroupSpec) (_name string, _cleanup func() error, _err error) {
+	if err := tweakRunnerGroupSpec(cliCtx, spec); err != nil {
+		return "", nil, err
+	}
+
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal RunnerGroupSpec: %w", err)
+	}
+	return utils.CreateTempFileWithContent(data)
+}
+
+// tweakRunnerGroupSpec applies CLI overrides to the runner group spec.
+func tweakRunnerGroupSpec(cliCtx *cli.Context, spec *types.RunnerGroupSpec) error {
+	reqs := cliCtx.Int("total")
+	if reqs < 0 {
+		return fmt.Errorf("invalid total-requests value: %v", reqs)
+	}
+	// Apply CLI overrides automatically
+	if spec != nil && spec.Profile.Spec.ModeConfig != nil {
+		overrides := types.BuildOverridesFromCLI(spec.Profile.Spec.ModeConfig, cliCtx)
+		if len(overrides) > 0 {
+			if err := spec.Profile.Spec.ModeConfig.ApplyOverrides(overrides); err != nil {
+				return fmt.Errorf("failed to apply config overrides: %w", err)
+			}
+		}
+	}
+
+	rgAffinity := cliCtx.GlobalString("rg-affinity")
+	affinityLabels, err := kperfcmdutils.KeyValuesMap([]string{rgAffinity})
+	if err != nil {
+		return fmt.Errorf("failed to parse %s affinity: %w", rgAffinity, err)
+	}
+	spec.NodeAffinity = affinityLabels
+	spec.Profile.Spec.ContentType = types.ContentType(cliCtx.String("content-type"))
+
+	// Tweak the load profile for time-series replay case
+	if cliCtx.Command.Name == "timeseries_simple" {
+		err = tweakTimeSeriesProfile(cliCtx, spec)
+		if err != nil {
+			return fmt.Errorf("failed to tweak time-series profile: %w", err)
+		}
+	}
+	data, _ := yaml.Marshal(spec)
+
+	// Tweak the load profile for read-update case
+	if cliCtx.Command.Name == "read_update" {
+		err = tweakReadUpdateProfile(cliCtx, spec)
+		if err != nil {
+			return fmt.Errorf("failed to tweak read-update profile: %w", err)
+		}
+	}
+
+	log.GetLogger(context.TODO()).
+		WithKeyValues("level", "info").
+		LogKV("msg", "dump load profile", "config", string(data))
+	return nil
+}
+
 func tweakReadUpdateProfile(cliCtx *cli.Context, spec *types.RunnerGroupSpec) error {
 	namePattern := cliCtx.String("read-update-name-pattern")
 	ratio := cliCtx.Float64("read-ratio")
@@ -218,12 +479,12 @@ func tweakReadUpdateProfile(cliCtx *cli.Context, spec *types.RunnerGroupSpec) er
 						r.Patch.KeySpaceSize = configmapTotal
 					}
 				}
-				if r.StaleList != nil {
+				if r.List != nil && r.List.Consistency == types.ConsistencyStale {
 					if ratio != 0 {
 						r.Shares = int(ratio * 100)
 					}
 					if namespace != "" {
-						r.StaleList.Namespace = namespace
+						r.List.Namespace = namespace
 					}
 				}
 			}
@@ -0,0 +1,287 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package bench
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+const customLoadProfile = `count: 2
+loadProfile:
+  version: 1
+  description: "custom"
+  spec:
+    rate: 5
+    total: 50
+    conns: 2
+    client: 2
+    contentType: json
+    requests:
+      - staleList:
+          version: v1
+          resource: pods
+        shares: 100
+`
+
+// runNewLoadProfileFromEmbed runs newLoadProfileFromEmbed within the
+// node10_job1_pod100 subcommand.
+func runNewLoadProfileFromEmbed(t *testing.T, args ...string) (*types.RunnerGroupSpec, error) {
+	var rgSpec *types.RunnerGroupSpec
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{
+		cli.StringFlag{Name: "rg-affinity", Value: "kperf=runner"},
+	}
+	app.Commands = []cli.Command{
+		{
+			Name:  benchNode10Job1Pod100Case.Name,
+			Flags: benchNode10Job1Pod100Case.Flags,
+			Action: func(cliCtx *cli.Context) error {
+				_, spec, rgCfgFileDone, err := newLoadProfileFromEmbed(cliCtx,
+					"loadprofile/node10_job1_pod100.yaml")
+				if err != nil {
+					return err
+				}
+				rgSpec = spec
+				return rgCfgFileDone()
+			},
+		},
+	}
+	err := app.Run(append([]string{"runkperf", benchNode10Job1Pod100Case.Name}, args...))
+	return rgSpec, err
+}
+
+func TestNewLoadProfileFromEmbed(t *testing.T) {
+	spec, err := runNewLoadProfileFromEmbed(t)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), spec.Count)
+	assert.Equal(t, "node10-job1-pod100", spec.Profile.Description)
+}
+
+func TestNewLoadProfileFromPath(t *testing.T) {
+	loadProfile := filepath.Join(t.TempDir(), "profile.yaml")
+	require.NoError(t, os.WriteFile(loadProfile, []byte(customLoadProfile), 0600))
+
+	spec, err := runNewLoadProfileFromEmbed(t, "--load-profile", loadProfile, "--content-type", "protobuf")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), spec.Count)
+	assert.Equal(t, "custom", spec.Profile.Description)
+	assert.Equal(t, 2, spec.Profile.Spec.Conns)
+	assert.Equal(t, types.ContentType(types.ContentTypeProtobuffer), spec.Profile.Spec.ContentType)
+	assert.Equal(t, map[string][]string{"kperf": {"runner"}}, spec.NodeAffinity)
+}
+
+func TestNewLoadProfileFromInvalidPath(t *testing.T) {
+	loadProfile := filepath.Join(t.TempDir(), "profile.yaml")
+	require.NoError(t, os.WriteFile(loadProfile, []byte(`count: 1
+loadProfile:
+  version: 1
+  spec:
+    rate: 5
+    total: 50
+    conns: 0
+    client: 2
+    contentType: json
+    requests:
+      - staleList:
+          version: v1
+          resource: pods
+        shares: 100
+`), 0600))
+
+	_, err := runNewLoadProfileFromEmbed(t, "--load-profile", loadProfile)
+	assert.ErrorContains(t, err, "conns requires > 0")
+
+	_, err = runNewLoadProfileFromEmbed(t, "--load-profile", filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read")
+}
+
+func TestNewVirtualNodepool(t *testing.T) {
+	for name, tc := range map[string]struct {
+		args     []string
+		expected *virtualNodepool
+		err      string
+	}{
+		"default": {
+			expected: &virtualNodepool{Name: "test", Nodes: 10, CPU: 32, Memory: 96, MaxPods: 110,
+				Labels: []string{}, Taints: []string{},
+			},
+		},
+		"custom": {
+			args: []string{"--nodes", "3", "--cpu", "8", "--max-pods", "20",
+				"--node-labels", "pool=a", "--node-taints", "dedicated=bench:NoSchedule", "--node-taints", "slow:NoExecute"},
+			expected: &virtualNodepool{Name: "test", Nodes: 3, CPU: 8, Memory: 96, MaxPods: 20,
+				Labels: []string{"pool=a"},
+				Taints: []string{"dedicated=bench:NoSchedule", "slow:NoExecute"},
+			},
+		},
+		"invalid label": {
+			args: []string{"--node-labels", "pool"},
+			err:  "failed to parse node-labels",
+		},
+		"taint without effect": {
+			args: []string{"--node-taints", "dedicated=bench"},
+			err:  "failed to parse node-taints",
+		},
+		"invalid taint effect": {
+			args: []string{"--node-taints", "dedicated=bench:Never"},
+			err:  `invalid effect "Never"`,
+		},
+		"negative nodes": {
+			args: []string{"--nodes", "-1"},
+			err:  "invalid nodes value",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var np *virtualNodepool
+			app := cli.NewApp()
+			app.Commands = []cli.Command{
+				{
+					Name:  "test",
+					Flags: commonFlags,
+					Action: func(cliCtx *cli.Context) error {
+						var err error
+						np, err = newVirtualNodepool(cliCtx, "test", 10)
+						return err
+					},
+				},
+			}
+
+			err := app.Run(append([]string{"runkperf", "test"}, tc.args...))
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, np)
+		})
+	}
+}
+
+func TestNewRunnerPlacement(t *testing.T) {
+	for name, tc := range map[string]struct {
+		args     []string
+		expected *runnerPlacement
+		err      string
+	}{
+		"default": {
+			expected: &runnerPlacement{Affinity: "node.kubernetes.io/instance-type=Standard_D16s_v3,m4.4xlarge,n1-standard-16"},
+		},
+		"custom": {
+			args: []string{"--rg-affinity", "pool=runner",
+				"--runner-cpu", "2", "--runner-memory", "4Gi",
+				"--runner-node-selector", "dedicated=kperf",
+				"--runner-tolerations", "dedicated=kperf:NoSchedule",
+				"--runner-tolerations", `[{"key": "slow", "operator": "Exists"}]`},
+			expected: &runnerPlacement{
+				Affinity:     "pool=runner",
+				Resources:    &types.RunnerResources{CPU: "2", Memory: "4Gi"},
+				NodeSelector: map[string]string{"dedicated": "kperf"},
+				Tolerations: []types.RunnerToleration{
+					{Key: "dedicated", Operator: "Equal", Value: "kperf", Effect: "NoSchedule"},
+					{Key: "slow", Operator: "Exists"},
+				},
+			},
+		},
+		"invalid cpu": {
+			args: []string{"--runner-cpu", "two"},
+			err:  `invalid cpu quantity "two"`,
+		},
+		"invalid node selector": {
+			args: []string{"--runner-node-selector", "dedicated"},
+			err:  "failed to parse runner-node-selector",
+		},
+		"invalid toleration effect": {
+			args: []string{"--runner-tolerations", "dedicated=kperf:Never"},
+			err:  `unsupported effect: "Never"`,
+		},
+		"invalid toleration json": {
+			args: []string{"--runner-tolerations", `[{"key": "slow"`},
+			err:  "failed to parse runner-tolerations",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var placement *runnerPlacement
+			app := cli.NewApp()
+			app.Flags = Command.Flags
+			app.Commands = []cli.Command{
+				{
+					Name: "test",
+					Action: func(cliCtx *cli.Context) error {
+						var err error
+						placement, err = newRunnerPlacement(cliCtx)
+						return err
+					},
+				},
+			}
+
+			args := append([]string{"runkperf", "--runner-image", "kperf"}, tc.args...)
+			err := app.Run(append(args, "test"))
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, placement)
+		})
+	}
+}
+
+func TestNewWarmupInfo(t *testing.T) {
+	for name, tc := range map[string]struct {
+		args     []string
+		expected *warmupInfo
+		err      string
+	}{
+		"disabled": {},
+		"default ratio": {
+			args:     []string{"--warmup-duration", "1m"},
+			expected: &warmupInfo{Duration: "1m0s", RateRatio: 0.5},
+		},
+		"custom ratio": {
+			args:     []string{"--warmup-duration", "30s", "--warmup-rate-ratio", "1"},
+			expected: &warmupInfo{Duration: "30s", RateRatio: 1},
+		},
+		"too short": {
+			args: []string{"--warmup-duration", "500ms"},
+			err:  "invalid warmup-duration value",
+		},
+		"invalid ratio": {
+			args: []string{"--warmup-duration", "1m", "--warmup-rate-ratio", "1.5"},
+			err:  "invalid warmup-rate-ratio value",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var warmup *warmupInfo
+			app := cli.NewApp()
+			app.Flags = Command.Flags
+			app.Commands = []cli.Command{
+				{
+					Name: "test",
+					Action: func(cliCtx *cli.Context) error {
+						var err error
+						warmup, err = newWarmupInfo(cliCtx)
+						return err
+					},
+				},
+			}
+
+			args := append([]string{"runkperf", "--runner-image", "kperf"}, tc.args...)
+			err := app.Run(append(args, "test"))
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, warmup)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	internaltypes "github.com/Azure/kperf/contrib/internal/types"
+	"github.com/Azure/kperf/contrib/utils"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	// watchFanoutNamespace is where churn job runs and watchers watch.
+	watchFanoutNamespace = "watchfanout"
+	// watchFanoutWatchersPerRunner is the maximum number of watches held
+	// by one runner.
+	watchFanoutWatchersPerRunner = 100
+	// watchFanoutWatchesPerConn is the number of watches sharing one
+	// connection.
+	watchFanoutWatchesPerConn = 10
+	// watchFanoutEstablishRate is the rate of establishing watches per
+	// runner.
+	watchFanoutEstablishRate = 10
+)
+
+// watchFanoutChurnJobTemplate is the embedded job patched with --churn-pods.
+const watchFanoutChurnJobTemplate = "workload/100pod.job.yaml"
+
+var benchWatchFanoutCase = cli.Command{
+	Name: "watch_fanout",
+	Usage: `
+
+The test suite is to measure the cost of watch event fan-out. It sets up virtual
+nodes and deploys one job with --churn-pods pods on that nodes repeatedly, while
+--watchers watches on pods in the job's namespace are held for --duration. The
+report contains watch event delivery lag, which is the time from pod's creation
+to receiving its ADDED event, as percentileWatchEventLagsByURL.
+	`,
+	Flags: append(
+		[]cli.Flag{
+			cli.IntFlag{
+				Name:  "watchers",
+				Usage: fmt.Sprintf("Total number of watches (%d watches per runner at most)", watchFanoutWatchersPerRunner),
+				Value: 1000,
+			},
+			cli.IntFlag{
+				Name:  "churn-pods",
+				Usage: "The number of pods in churn job",
+				Value: 100,
+			},
+			cli.DurationFlag{
+				Name:  "duration",
+				Usage: "How long each watch is held",
+				Value: 5 * time.Minute,
+			},
+		},
+		clusterFlags...,
+	),
+	Action: func(cliCtx *cli.Context) error {
+		_, err := renderBenchmarkReportInterceptor(
+			addWarmupInfoInterceptor(
+				addRunnerPlacementInfoInterceptor(
+					addAPIServerCoresInfoInterceptor(
+						addAPIServerMetricsInfoInterceptor(benchWatchFanoutCaseRun),
+					),
+				),
+			),
+		)(cliCtx)
+		return err
+	},
+}
+
+// benchWatchFanoutCaseRun is for benchWatchFanoutCase subcommand.
+func benchWatchFanoutCaseRun(cliCtx *cli.Context) (*internaltypes.BenchmarkReport, error) {
+	ctx := context.Background()
+	kubeCfgPath := cliCtx.GlobalString("kubeconfig")
+
+	churnPods := cliCtx.Int("churn-pods")
+	if churnPods <= 0 {
+		return nil, fmt.Errorf("invalid churn-pods value: %v, requires > 0", churnPods)
+	}
+
+	np, err := newVirtualNodepool(cliCtx, "watchfanout", watchFanoutDefaultNodes(churnPods))
+	if err != nil {
+		return nil, err
+	}
+
+	rgSpec, err := newWatchFanoutRunnerGroupSpec(cliCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	rgCfgFile, rgCfgFileDone, err := newLoadProfileFromSpec(cliCtx, rgSpec)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rgCfgFileDone() }()
+
+	vcDone, err := deployVirtualNodepool(ctx, cliCtx, np)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deploy virtual node: %w", err)
+	}
+	defer func() { _ = vcDone() }()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	var churnStats utils.JobChurnStats
+	jobInterval := 5 * time.Second
+	jobCtx, jobCancel := context.WithCancel(ctx)
+	go func() {
+		defer wg.Done()
+
+		churnStats = utils.RepeatJobWithPod(jobCtx, kubeCfgPath, watchFanoutNamespace, watchFanoutChurnJobTemplate,
+			utils.WithJobIntervalOpt(jobInterval),
+			utils.WithJobPodCountOpt(int32(churnPods)),
+			utils.WithJobParallelismOpt(watchFanoutChurnParallelism(churnPods)),
+		)
+	}()
+
+	rgResult, derr := utils.DeployRunnerGroup(ctx,
+		kubeCfgPath,
+		cliCtx.GlobalString("runner-image"),
+		rgCfgFile,
+		cliCtx.GlobalString("runner-flowcontrol"),
+		cliCtx.GlobalString("rg-affinity"),
+		deployRunnerGroupOpts(cliCtx)...,
+	)
+	jobCancel()
+	wg.Wait()
+
+	if derr != nil {
+		return nil, derr
+	}
+
+	watchers := int(rgSpec.Count) * rgSpec.Profile.Spec.Client
+	return &internaltypes.BenchmarkReport{
+		Description: fmt.Sprintf(`
+Environment: %d virtual nodes managed by kwok-controller,
+Workload: Deploy 1 job with %d pods repeatedly. The interval is %v
+Mode: %d watches on pods held for %v`, np.Nodes, churnPods, jobInterval, watchers, cliCtx.Duration("duration")),
+		LoadSpec: *rgSpec,
+		Result:   *rgResult,
+		Info: map[string]interface{}{
+			"virtualNodepool": np,
+			"watchFanout": map[string]interface{}{
+				"watchers":         watchers,
+				"churnPods":        churnPods,
+				"duration":         cliCtx.Duration("duration").String(),
+				"totalWatchEvents": rgResult.TotalWatchEvents,
+				"churn":            churnStats,
+			},
+		},
+	}, nil
+}
+
+// newWatchFanoutRunnerGroupSpec generates the runner group spec whose
+// runners hold --watchers watches on pods in churn job's namespace in total.
+// The watches are spread over runners evenly, so that the total can be
+// rounded up.
+func newWatchFanoutRunnerGroupSpec(cliCtx *cli.Context) (*types.RunnerGroupSpec, error) {
+	watchers := cliCtx.Int("watchers")
+	if watchers <= 0 {
+		return nil, fmt.Errorf("invalid watchers value: %v, requires > 0", watchers)
+	}
+
+	duration := cliCtx.Duration("duration")
+	if duration <= 0 {
+		return nil, fmt.Errorf("invalid duration value: %v, requires > 0", duration)
+	}
+
+	runners := (watchers + watchFanoutWatchersPerRunner - 1) / watchFanoutWatchersPerRunner
+	perRunner := (watchers + runners - 1) / runners
+	conns := (perRunner + watchFanoutWatchesPerConn - 1) / watchFanoutWatchesPerConn
+
+	return &types.RunnerGroupSpec{
+		Count: int32(runners),
+		Profile: &types.LoadProfile{
+			Version:     1,
+			Description: fmt.Sprintf("%d watches on pods held for %v", watchers, duration),
+			Spec: types.LoadProfileSpec{
+				Conns: conns,
+				// NOTE: Each client is blocked by one watch until
+				// it's closed.
+				Client: perRunner,
+				Mode:   types.ModeWeightedRandom,
+				ModeConfig: &types.WeightedRandomConfig{
+					Rate:  watchFanoutEstablishRate,
+					Total: perRunner,
+					Requests: []*types.WeightedRequest{
+						{
+							Shares: 1,
+							WatchChurn: &types.RequestWatchChurn{
+								KubeGroupVersionResource: types.KubeGroupVersionResource{
+									Version:  "v1",
+									Resource: "pods",
+								},
+								Namespace: watchFanoutNamespace,
+								HoldTime:  duration.String(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// watchFanoutDefaultNodes returns the default number of virtual nodes for
+// churn job with pods.
+func watchFanoutDefaultNodes(pods int) int {
+	if pods <= 1000 {
+		return 10
+	}
+	return 100
+}
+
+// watchFanoutChurnParallelism returns the parallelism of churn job with
+// pods, which is 10% of pods within [10, 100].
+func watchFanoutChurnParallelism(pods int) int32 {
+	return int32(min(max(pods/10, 10), 100))
+}
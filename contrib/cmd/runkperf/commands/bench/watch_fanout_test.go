@@ -0,0 +1,128 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package bench
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+// runNewWatchFanoutRunnerGroupSpec runs newWatchFanoutRunnerGroupSpec within
+// the watch_fanout subcommand and returns the spec written into file.
+func runNewWatchFanoutRunnerGroupSpec(t *testing.T, args ...string) (*types.RunnerGroupSpec, error) {
+	var rgSpec *types.RunnerGroupSpec
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{
+		cli.StringFlag{Name: "rg-affinity", Value: "kperf=runner"},
+	}
+	app.Commands = []cli.Command{
+		{
+			Name:  benchWatchFanoutCase.Name,
+			Flags: benchWatchFanoutCase.Flags,
+			Action: func(cliCtx *cli.Context) error {
+				spec, err := newWatchFanoutRunnerGroupSpec(cliCtx)
+				if err != nil {
+					return err
+				}
+
+				rgCfgFile, rgCfgFileDone, err := newLoadProfileFromSpec(cliCtx, spec)
+				if err != nil {
+					return err
+				}
+				defer func() { _ = rgCfgFileDone() }()
+
+				data, err := os.ReadFile(rgCfgFile)
+				if err != nil {
+					return err
+				}
+				rgSpec = &types.RunnerGroupSpec{}
+				return yaml.Unmarshal(data, rgSpec)
+			},
+		},
+	}
+	err := app.Run(append([]string{"runkperf", benchWatchFanoutCase.Name}, args...))
+	return rgSpec, err
+}
+
+func TestWatchFanoutRunnerGroupSpec(t *testing.T) {
+	for name, tc := range map[string]struct {
+		args      []string
+		runners   int32
+		perRunner int
+		conns     int
+		holdTime  string
+		err       string
+	}{
+		"default": {
+			runners:   10,
+			perRunner: 100,
+			conns:     10,
+			holdTime:  "5m0s",
+		},
+		"rounded up": {
+			args:      []string{"--watchers", "150", "--duration", "30s"},
+			runners:   2,
+			perRunner: 75,
+			conns:     8,
+			holdTime:  "30s",
+		},
+		"invalid watchers": {
+			args: []string{"--watchers", "0"},
+			err:  "invalid watchers value",
+		},
+		"invalid duration": {
+			args: []string{"--duration", "0s"},
+			err:  "invalid duration value",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rgSpec, err := runNewWatchFanoutRunnerGroupSpec(t, tc.args...)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.runners, rgSpec.Count)
+			assert.Equal(t, map[string][]string{"kperf": {"runner"}}, rgSpec.NodeAffinity)
+
+			profile := rgSpec.Profile
+			require.NotNil(t, profile)
+			require.NoError(t, profile.Validate())
+			assert.Equal(t, tc.conns, profile.Spec.Conns)
+			assert.Equal(t, tc.perRunner, profile.Spec.Client)
+
+			wrConfig, ok := profile.Spec.ModeConfig.(*types.WeightedRandomConfig)
+			require.True(t, ok)
+			assert.Equal(t, tc.perRunner, wrConfig.Total)
+			require.Len(t, wrConfig.Requests, 1)
+			require.NotNil(t, wrConfig.Requests[0].WatchChurn)
+			assert.Equal(t, watchFanoutNamespace, wrConfig.Requests[0].WatchChurn.Namespace)
+			assert.Equal(t, tc.holdTime, wrConfig.Requests[0].WatchChurn.HoldTime)
+		})
+	}
+}
+
+func TestWatchFanoutChurnJob(t *testing.T) {
+	for pods, expected := range map[int]struct {
+		nodes       int
+		parallelism int32
+	}{
+		50:   {nodes: 10, parallelism: 10},
+		100:  {nodes: 10, parallelism: 10},
+		500:  {nodes: 10, parallelism: 50},
+		1000: {nodes: 10, parallelism: 100},
+		3000: {nodes: 100, parallelism: 100},
+	} {
+		assert.Equal(t, expected.nodes, watchFanoutDefaultNodes(pods), "pods=%d", pods)
+		assert.Equal(t, expected.parallelism, watchFanoutChurnParallelism(pods), "pods=%d", pods)
+	}
+}
@@ -7,6 +7,8 @@ import apitypes "github.com/Azure/kperf/api/types"
 
 // BenchmarkReport represents runkperf-bench's result.
 type BenchmarkReport struct {
+	// Name is the name of test case, which is runkperf-bench's subcommand.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
 	// Description describes test case.
 	Description string `json:"description" yaml:"description"`
 	// LoadSpec represents what the load profile looks like.
@@ -17,4 +19,80 @@ type BenchmarkReport struct {
 	//
 	// FIXME(weifu): Use struct after finialized.
 	Info map[string]interface{} `json:"info" yaml:"info"`
+	// Comparison is the comparison with baseline report if it's specified.
+	Comparison *BenchmarkComparison `json:"comparison,omitempty" yaml:"comparison,omitempty"`
+}
+
+// BenchmarkComparison represents the deltas of key metrics between
+// benchmark report and baseline report.
+type BenchmarkComparison struct {
+	// Baseline is the path to baseline report.
+	Baseline string `json:"baseline" yaml:"baseline"`
+	// BaselineName is the name of baseline's test case.
+	BaselineName string `json:"baselineName,omitempty" yaml:"baselineName,omitempty"`
+	// Thresholds are the thresholds of regression.
+	Thresholds RegressionThresholds `json:"thresholds" yaml:"thresholds"`
+	// Metrics are the compared metrics in order of name. The metrics
+	// missing in either report are skipped.
+	Metrics []MetricComparison `json:"metrics" yaml:"metrics"`
+	// Regressions are the names of regressed metrics.
+	Regressions []string `json:"regressions,omitempty" yaml:"regressions,omitempty"`
+}
+
+// RegressionThresholds defines when the metric is regressed compared to
+// baseline.
+type RegressionThresholds struct {
+	// P99 is the max ratio of P99 latency increase, like 0.1 for 10%.
+	P99 float64 `json:"p99" yaml:"p99"`
+	// ErrorRate is the max increase of error rate, like 0.01 for 1%.
+	ErrorRate float64 `json:"errorRate" yaml:"errorRate"`
+	// QPS is the max ratio of achieved QPS decrease, like 0.1 for 10%.
+	QPS float64 `json:"qps" yaml:"qps"`
+}
+
+// MetricComparison is the delta of one metric.
+type MetricComparison struct {
+	// Name is the metric's name, like p99 or p99:<URL>.
+	Name string `json:"name" yaml:"name"`
+	// Baseline is the value in baseline report.
+	Baseline float64 `json:"baseline" yaml:"baseline"`
+	// Current is the value in benchmark report.
+	Current float64 `json:"current" yaml:"current"`
+	// Delta is Current - Baseline.
+	Delta float64 `json:"delta" yaml:"delta"`
+	// DeltaRatio is Delta / Baseline. It's zero if Baseline is zero.
+	DeltaRatio float64 `json:"deltaRatio" yaml:"deltaRatio"`
+	// Regressed is true if the delta is beyond threshold.
+	Regressed bool `json:"regressed" yaml:"regressed"`
+}
+
+// MultiRunReport represents runkperf-bench compare_runs's result, which runs
+// the same benchmark several times.
+type MultiRunReport struct {
+	// Description describes test case.
+	Description string `json:"description" yaml:"description"`
+	// LoadSpec represents what the load profile looks like.
+	LoadSpec apitypes.RunnerGroupSpec `json:"loadSpec" yaml:"loadSpec"`
+	// Runs represents runner group's report for each run.
+	Runs []apitypes.RunnerGroupsReport `json:"runs" yaml:"runs"`
+	// Summary is the statistical summary across runs.
+	Summary MultiRunSummary `json:"summary" yaml:"summary"`
+	// SuspiciousRuns are the indexes of runs whose P99 latency is outlier.
+	SuspiciousRuns []int `json:"suspiciousRuns,omitempty" yaml:"suspiciousRuns,omitempty"`
+}
+
+// MultiRunSummary is the statistics of P99 latency across runs in seconds.
+type MultiRunSummary struct {
+	// P99Mean is the mean of P99 latency.
+	P99Mean float64 `json:"p99Mean" yaml:"p99Mean"`
+	// P99StdDev is the sample standard deviation of P99 latency.
+	P99StdDev float64 `json:"p99StdDev" yaml:"p99StdDev"`
+	// P99Min is the minimum P99 latency.
+	P99Min float64 `json:"p99Min" yaml:"p99Min"`
+	// P99Max is the maximum P99 latency.
+	P99Max float64 `json:"p99Max" yaml:"p99Max"`
+	// CV is the coefficient of variation, which is P99StdDev / P99Mean.
+	CV float64 `json:"cv" yaml:"cv"`
+	// HighVariance is true if CV is greater than the threshold.
+	HighVariance bool `json:"highVariance" yaml:"highVariance"`
 }
@@ -28,6 +28,7 @@ func (kr *KperfRunner) NewNodepool(
 	timeout time.Duration,
 	name string, nodes int, cpu, memory, maxPods int,
 	affinity string,
+	nodeLabels, nodeTaints []string,
 	sharedProviderID string,
 ) error {
 	args := []string{"vc", "nodepool"}
@@ -43,6 +44,12 @@ func (kr *KperfRunner) NewNodepool(
 	if affinity != "" {
 		args = append(args, fmt.Sprintf("--affinity=%v", affinity))
 	}
+	for _, label := range nodeLabels {
+		args = append(args, fmt.Sprintf("--node-labels=%v", label))
+	}
+	for _, taint := range nodeTaints {
+		args = append(args, fmt.Sprintf("--node-taints=%v", taint))
+	}
 	if sharedProviderID != "" {
 		args = append(args, fmt.Sprintf("--shared-provider-id=%v", sharedProviderID))
 	}
@@ -64,7 +71,7 @@ func (kr *KperfRunner) DeleteNodepool(ctx context.Context, timeout time.Duration
 }
 
 // RGRun deploys runner group into kubernetes cluster.
-func (kr *KperfRunner) RGRun(ctx context.Context, timeout time.Duration, rgCfgPath, flowcontrol, affinity string) error {
+func (kr *KperfRunner) RGRun(ctx context.Context, timeout time.Duration, rgCfgPath, flowcontrol, affinity string, placement RunnerPlacement) error {
 	args := []string{"rg"}
 	if kr.kubeCfgPath != "" {
 		args = append(args, fmt.Sprintf("--kubeconfig=%s", kr.kubeCfgPath))
@@ -79,6 +86,18 @@ func (kr *KperfRunner) RGRun(ctx context.Context, timeout time.Duration, rgCfgPa
 	if flowcontrol != "" {
 		args = append(args, fmt.Sprintf("--runner-flowcontrol=%v", flowcontrol))
 	}
+	if placement.CPU != "" {
+		args = append(args, fmt.Sprintf("--runner-cpu=%v", placement.CPU))
+	}
+	if placement.Memory != "" {
+		args = append(args, fmt.Sprintf("--runner-memory=%v", placement.Memory))
+	}
+	for _, nodeSelector := range placement.NodeSelector {
+		args = append(args, fmt.Sprintf("--runner-node-selector=%v", nodeSelector))
+	}
+	for _, toleration := range placement.Tolerations {
+		args = append(args, fmt.Sprintf("--runner-tolerations=%v", toleration))
+	}
 
 	_, err := runCommand(ctx, timeout, "kperf", args)
 	return err
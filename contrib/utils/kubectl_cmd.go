@@ -5,10 +5,13 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strings"
 	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
 )
 
 // KubectlRunner is the wrapper of exec.Command to execute kubectl command.
@@ -80,6 +83,29 @@ func (kr *KubectlRunner) Wait(ctx context.Context, timeout time.Duration, condit
 	return err
 }
 
+// GetJob returns the job by name.
+func (kr *KubectlRunner) GetJob(ctx context.Context, timeout time.Duration, name string) (*batchv1.Job, error) {
+	args := []string{}
+	if kr.kubeCfgPath != "" {
+		args = append(args, "--kubeconfig", kr.kubeCfgPath)
+	}
+	if kr.namespace != "" {
+		args = append(args, "-n", kr.namespace)
+	}
+	args = append(args, "get", "job", name, "-o", "json")
+
+	data, err := runCommand(ctx, timeout, "kubectl", args)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &batchv1.Job{}
+	if err := json.Unmarshal(data, job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job %s: %w", name, err)
+	}
+	return job, nil
+}
+
 // CreateNamespace creates a new namespace.
 func (kr *KubectlRunner) CreateNamespace(ctx context.Context, timeout time.Duration, name string) error {
 	args := []string{}
@@ -0,0 +1,181 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/kperf/contrib/log"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// runnerGroupNamespace is the namespace of runner group's server and
+	// runners.
+	runnerGroupNamespace = "runnergroups-kperf-io"
+
+	// runnerLogTailLines is the number of log lines fetched from each
+	// pod to find the latest progress.
+	//
+	// NOTE: The runner logs progress every 10 seconds with default
+	// verbosity.
+	runnerLogTailLines = 50
+
+	// runnerDiagnosticLogLines is the number of last log lines of each
+	// pod reported when runner group is stuck.
+	runnerDiagnosticLogLines = 10
+)
+
+// runnerProgressRegexp matches the counters of runner's "Schedule progress"
+// log, like `completed=100 errors=2`.
+var runnerProgressRegexp = regexp.MustCompile(`\b(completed|errors)=(\d+)`)
+
+// runnerPodStatus is the state of one pod of runner group.
+type runnerPodStatus struct {
+	Name  string
+	Phase corev1.PodPhase
+	// Completed is the number of finished requests in latest progress.
+	Completed int64
+	// Errors is the number of failed requests in latest progress.
+	Errors int64
+	// LastLogs is the last lines of pod's log.
+	LastLogs []string
+}
+
+// fetchRunnerPodStatuses returns the state of runner group's pods, sorted by
+// name. The failure of fetching one pod's log is recorded as its last log.
+func fetchRunnerPodStatuses(ctx context.Context, cli kubernetes.Interface) ([]runnerPodStatus, error) {
+	pods, err := cli.CoreV1().Pods(runnerGroupNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in %s: %w", runnerGroupNamespace, err)
+	}
+
+	res := make([]runnerPodStatus, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		st := runnerPodStatus{Name: pod.Name, Phase: pod.Status.Phase}
+		if pod.Status.Phase != corev1.PodPending {
+			tailLines := int64(runnerLogTailLines)
+			data, err := cli.CoreV1().Pods(runnerGroupNamespace).
+				GetLogs(pod.Name, &corev1.PodLogOptions{TailLines: &tailLines}).
+				DoRaw(ctx)
+			if err != nil {
+				st.LastLogs = []string{fmt.Sprintf("failed to fetch logs: %v", err)}
+			} else {
+				st.parseLogs(data)
+			}
+		}
+		res = append(res, st)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// parseLogs updates the progress by the latest "Schedule progress" log and
+// keeps the last lines.
+func (st *runnerPodStatus) parseLogs(data []byte) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		lines = append(lines, line)
+
+		if !strings.Contains(line, `"Schedule progress"`) {
+			continue
+		}
+		for _, m := range runnerProgressRegexp.FindAllStringSubmatch(line, -1) {
+			v, err := strconv.ParseInt(m[2], 10, 64)
+			if err != nil {
+				continue
+			}
+			switch m[1] {
+			case "completed":
+				st.Completed = v
+			case "errors":
+				st.Errors = v
+			}
+		}
+	}
+
+	if len(lines) > runnerDiagnosticLogLines {
+		lines = lines[len(lines)-runnerDiagnosticLogLines:]
+	}
+	st.LastLogs = lines
+}
+
+// logRunnerGroupProgress logs the progress of runner group on the interval
+// until ctx is done.
+func logRunnerGroupProgress(ctx context.Context, cli kubernetes.Interface, interval time.Duration) {
+	infoLogger := log.GetLogger(ctx).WithKeyValues("level", "info")
+	warnLogger := log.GetLogger(ctx).WithKeyValues("level", "warn")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		statuses, err := fetchRunnerPodStatuses(ctx, cli)
+		if err != nil {
+			warnLogger.LogKV("msg", "failed to fetch runner group's progress", "error", err)
+			continue
+		}
+
+		phases := map[corev1.PodPhase]int{}
+		pods := make([]string, 0, len(statuses))
+		var completed, errs int64
+		for _, st := range statuses {
+			phases[st.Phase]++
+			completed += st.Completed
+			errs += st.Errors
+			pods = append(pods, fmt.Sprintf("%s(%s completed=%d errors=%d)",
+				st.Name, st.Phase, st.Completed, st.Errors))
+		}
+		infoLogger.LogKV("msg", "runner group progress",
+			"pods", len(statuses),
+			"running", phases[corev1.PodRunning],
+			"pending", phases[corev1.PodPending],
+			"failed", phases[corev1.PodFailed],
+			"completed", completed,
+			"errors", errs,
+			"details", pods,
+		)
+	}
+}
+
+// diagnoseRunnerGroup returns the pod phases and last log lines of runner
+// group in readable format.
+func diagnoseRunnerGroup(ctx context.Context, cli kubernetes.Interface) string {
+	statuses, err := fetchRunnerPodStatuses(ctx, cli)
+	if err != nil {
+		return err.Error()
+	}
+	if len(statuses) == 0 {
+		return fmt.Sprintf("no pod in %s", runnerGroupNamespace)
+	}
+
+	var b strings.Builder
+	for _, st := range statuses {
+		fmt.Fprintf(&b, "\npod %s: phase=%s completed=%d errors=%d", st.Name, st.Phase, st.Completed, st.Errors)
+		for _, line := range st.LastLogs {
+			fmt.Fprintf(&b, "\n  %s", line)
+		}
+	}
+	return b.String()
+}
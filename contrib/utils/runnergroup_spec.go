@@ -0,0 +1,92 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package utils
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Azure/kperf/api/types"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The defaults of `kperf runner run` flags, which are used by runners if
+// the load profile doesn't set them.
+const (
+	runnerDefaultConns  = 1
+	runnerDefaultClient = 1
+	runnerDefaultTotal  = 1000
+)
+
+// ValidateRunnerGroupSpecFile verifies the load profile in runner group spec
+// file in the same way as runners, so that invalid profile fails before
+// deploying runner group instead of in each runner's log.
+func ValidateRunnerGroupSpecFile(rgCfgFile string) error {
+	data, err := os.ReadFile(rgCfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", rgCfgFile, err)
+	}
+
+	if err := ValidateRunnerGroupSpec(data); err != nil {
+		return fmt.Errorf("invalid runner group spec %s: %w", rgCfgFile, err)
+	}
+	return nil
+}
+
+// ValidateRunnerGroupSpec verifies the load profile in runner group spec.
+// The error is prefixed with the path of offending field, like
+// loadProfile.spec.modeConfig.
+//
+// NOTE: The runner group server stores the load profile into ConfigMap in
+// YAML and runners load it back. The load profile round-trips through the
+// same marshalers and gets runners' defaults before validation so that it's
+// checked as runners see it.
+func ValidateRunnerGroupSpec(data []byte) error {
+	var spec types.RunnerGroupSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return fmt.Errorf("failed to unmarshal into RunnerGroupSpec: %w", err)
+	}
+
+	if spec.Profile == nil {
+		return fmt.Errorf("loadProfile is required")
+	}
+
+	raw, err := yaml.Marshal(spec.Profile)
+	if err != nil {
+		return fmt.Errorf("loadProfile: failed to marshal: %w", err)
+	}
+
+	profile := &types.LoadProfile{}
+	if err := yaml.Unmarshal(raw, profile); err != nil {
+		return fmt.Errorf("loadProfile.spec: %w", err)
+	}
+
+	profile = types.ExpandVariables(profile, nil)
+	if profile.Spec.Conns == 0 {
+		profile.Spec.Conns = runnerDefaultConns
+	}
+	if profile.Spec.Client == 0 {
+		profile.Spec.Client = runnerDefaultClient
+	}
+	if profile.Spec.ContentType == "" {
+		profile.Spec.ContentType = types.ContentTypeJSON
+	}
+
+	if profile.Version != 1 {
+		return fmt.Errorf("loadProfile.version should be 1: %v", profile.Version)
+	}
+
+	if profile.Spec.ModeConfig != nil {
+		defaultOverrides := map[string]interface{}{"total": runnerDefaultTotal}
+		if err := profile.Spec.ModeConfig.Validate(defaultOverrides); err != nil {
+			return fmt.Errorf("loadProfile.spec.modeConfig: %w", err)
+		}
+	}
+
+	if err := profile.Validate(); err != nil {
+		return fmt.Errorf("loadProfile.spec: %w", err)
+	}
+	return nil
+}
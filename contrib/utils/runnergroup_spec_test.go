@@ -0,0 +1,167 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package utils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/kperf/contrib/internal/manifests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRunnerGroupSpecEmbed(t *testing.T) {
+	files, err := fs.Glob(manifests.FS, "loadprofile/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, f := range files {
+		data, err := manifests.FS.ReadFile(f)
+		require.NoError(t, err)
+		assert.NoError(t, ValidateRunnerGroupSpec(data), f)
+	}
+}
+
+func TestValidateRunnerGroupSpec(t *testing.T) {
+	for name, tc := range map[string]struct {
+		data   string
+		errMsg string
+	}{
+		"valid with runner's defaults": {
+			data: `
+count: 1
+loadProfile:
+  version: 1
+  spec:
+    mode: weighted-random
+    modeConfig:
+      distribution: deterministic
+      requests:
+      - shares: 1
+        list:
+          version: v1
+          resource: pods
+`,
+		},
+		"no load profile": {
+			data:   "count: 1\n",
+			errMsg: "loadProfile is required",
+		},
+		"invalid version": {
+			data: `
+count: 1
+loadProfile:
+  version: 2
+  spec:
+    mode: weighted-random
+    modeConfig:
+      requests:
+      - shares: 1
+        list:
+          version: v1
+          resource: pods
+`,
+			errMsg: "loadProfile.version should be 1",
+		},
+		"invalid conns": {
+			data: `
+count: 1
+loadProfile:
+  version: 1
+  spec:
+    conns: -1
+    mode: weighted-random
+    modeConfig:
+      requests:
+      - shares: 1
+        list:
+          version: v1
+          resource: pods
+`,
+			errMsg: "loadProfile.spec: conns requires > 0",
+		},
+		"unknown mode config field": {
+			data: `
+count: 1
+loadProfile:
+  version: 1
+  spec:
+    mode: weighted-random
+    modeConfig:
+      rat: 10
+      requests:
+      - shares: 1
+        list:
+          version: v1
+          resource: pods
+`,
+			errMsg: "unknown keys [rat] in modeConfig",
+		},
+		"invalid mode config": {
+			data: `
+count: 1
+loadProfile:
+  version: 1
+  spec:
+    mode: weighted-random
+    modeConfig:
+      burst: -1
+      requests:
+      - shares: 1
+        list:
+          version: v1
+          resource: pods
+`,
+			errMsg: "loadProfile.spec.modeConfig: burst requires >= 0",
+		},
+		"no mode config": {
+			data: `
+count: 1
+loadProfile:
+  version: 1
+  spec:
+    conns: 1
+`,
+			errMsg: "loadProfile.spec: ",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateRunnerGroupSpec([]byte(tc.data))
+			if tc.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+}
+
+func TestValidateRunnerGroupSpecFile(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "rg.yaml")
+	require.NoError(t, os.WriteFile(target, []byte(`
+count: 1
+loadProfile:
+  version: 1
+  spec:
+    mode: weighted-random
+    modeConfig:
+      maxInFlight: -1
+      requests:
+      - shares: 1
+        list:
+          version: v1
+          resource: pods
+`), 0600))
+
+	err := ValidateRunnerGroupSpecFile(target)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), target)
+	assert.Contains(t, err.Error(), "loadProfile.spec.modeConfig")
+
+	assert.Error(t, ValidateRunnerGroupSpecFile(filepath.Join(t.TempDir(), "not-found.yaml")))
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunnerPodStatusParseLogs(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 12; i++ {
+		lines = append(lines, fmt.Sprintf(`I1017 02:14:35.359184 1 schedule.go:456] "Schedule progress" completed=%d errors=%d expectedTotal=0 elapsed="%ds"`, i*100, i, i*10))
+	}
+	lines = append(lines, `I1017 02:14:36.000000 1 other.go:1] "Unrelated" completed=1`)
+
+	st := &runnerPodStatus{}
+	st.parseLogs([]byte(strings.Join(lines, "\n")))
+	assert.Equal(t, int64(1200), st.Completed)
+	assert.Equal(t, int64(12), st.Errors)
+	assert.Len(t, st.LastLogs, runnerDiagnosticLogLines)
+	assert.Equal(t, lines[len(lines)-1], st.LastLogs[runnerDiagnosticLogLines-1])
+}
+
+func TestDiagnoseRunnerGroup(t *testing.T) {
+	ctx := context.Background()
+
+	cli := fake.NewSimpleClientset()
+	assert.Contains(t, diagnoseRunnerGroup(ctx, cli), "no pod")
+
+	for name, phase := range map[string]corev1.PodPhase{
+		"runnergroup-server": corev1.PodRunning,
+		"runner-0":           corev1.PodPending,
+	} {
+		_, err := cli.CoreV1().Pods(runnerGroupNamespace).Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: runnerGroupNamespace},
+			Status:     corev1.PodStatus{Phase: phase},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	statuses, err := fetchRunnerPodStatuses(ctx, cli)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "runner-0", statuses[0].Name)
+	assert.Equal(t, corev1.PodPending, statuses[0].Phase)
+	assert.Empty(t, statuses[0].LastLogs)
+	// NOTE: The fake clientset always replies "fake logs".
+	assert.Equal(t, []string{"fake logs"}, statuses[1].LastLogs)
+
+	diag := diagnoseRunnerGroup(ctx, cli)
+	assert.Contains(t, diag, "pod runner-0: phase=Pending")
+	assert.Contains(t, diag, "pod runnergroup-server: phase=Running")
+	assert.Contains(t, diag, "fake logs")
+}
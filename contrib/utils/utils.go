@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -22,9 +23,12 @@ import (
 	"github.com/Azure/kperf/contrib/log"
 	"github.com/Azure/kperf/helmcli"
 
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/yaml"
 )
 
@@ -41,9 +45,27 @@ var (
 	EKSIdleNodepoolInstanceType = "m4.large"
 )
 
-// RepeatJobWithPod repeats to deploy 3k pods.
+// JobChurnStats is the summary of the jobs deployed by RepeatJobWithPod, so
+// that the churn pressure is known rather than assumed.
+type JobChurnStats struct {
+	// PodsPerIteration is the number of pods in each job.
+	PodsPerIteration int32 `json:"podsPerIteration"`
+	// Iterations is the number of jobs which have been waited for. The
+	// one interrupted by ctx isn't counted.
+	Iterations int `json:"iterations"`
+	// CompletedIterations is the number of jobs whose pods all succeeded.
+	CompletedIterations int `json:"completedIterations"`
+	// NotStartedPods is the total number of pods which never started in
+	// incomplete jobs.
+	NotStartedPods int `json:"notStartedPods"`
+}
+
+// RepeatJobWithPod repeats to deploy the job with pods until ctx is done. The
+// job is loaded from target in embedded memory, or WithJobTemplateOpt, and
+// then patched by WithJobPodCountOpt and WithJobParallelismOpt. Each
+// iteration is verified by job's status after waiting for it.
 func RepeatJobWithPod(ctx context.Context, kubeCfgPath string, namespace string,
-	target string, timeoutOpts ...JobTimeoutOpt) {
+	target string, timeoutOpts ...JobTimeoutOpt) JobChurnStats {
 	infoLogger := log.GetLogger(ctx).WithKeyValues("level", "info")
 	warnLogger := log.GetLogger(ctx).WithKeyValues("level", "warn")
 
@@ -57,14 +79,25 @@ func RepeatJobWithPod(ctx context.Context, kubeCfgPath string, namespace string,
 		opt(jobsTimeout)
 	}
 
-	infoLogger.LogKV("msg", "repeat to create job with 3k pods")
+	data := jobsTimeout.template
+	if data == nil {
+		var err error
+		data, err = manifests.FS.ReadFile(target)
+		if err != nil {
+			panic(fmt.Errorf("unexpected error when read %s from embed memory: %v",
+				target, err))
+		}
+	}
 
-	data, err := manifests.FS.ReadFile(target)
+	data, job, err := patchJobManifest(data, jobsTimeout.podCount, jobsTimeout.parallelism)
 	if err != nil {
-		panic(fmt.Errorf("unexpected error when read %s from embed memory: %v",
-			target, err))
+		panic(fmt.Errorf("unexpected error when patch job %s: %v", target, err))
 	}
 
+	stats := JobChurnStats{PodsPerIteration: jobCompletions(job)}
+	infoLogger.LogKV("msg", "repeat to create job", "job", job.Name,
+		"pods", stats.PodsPerIteration, "parallelism", ptr.Deref(job.Spec.Parallelism, 1))
+
 	jobFile, cleanup, err := CreateTempFileWithContent(data)
 	if err != nil {
 		panic(fmt.Errorf("unexpected error when create job yaml: %v", err))
@@ -91,8 +124,8 @@ func RepeatJobWithPod(ctx context.Context, kubeCfgPath string, namespace string,
 	for {
 		select {
 		case <-ctx.Done():
-			infoLogger.LogKV("msg", "stop creating job")
-			return
+			infoLogger.LogKV("msg", "stop creating job", "stats", stats)
+			return stats
 		default:
 		}
 
@@ -105,11 +138,28 @@ func RepeatJobWithPod(ctx context.Context, kubeCfgPath string, namespace string,
 		}
 
 		timoutString := fmt.Sprintf("%ds", int(jobsTimeout.waitTimeout.Seconds()))
-		werr := kr.Wait(ctx, jobsTimeout.waitTimeout, "condition=complete", timoutString, "job/batchjobs")
+		werr := kr.Wait(ctx, jobsTimeout.waitTimeout, "condition=complete", timoutString, "job/"+job.Name)
 		if werr != nil {
 			warnLogger.LogKV("msg", "failed to wait job finish", "job", target, "error", werr)
 		}
 
+		// NOTE: The iteration interrupted by ctx isn't verified.
+		if ctx.Err() == nil {
+			stats.Iterations++
+
+			status, gerr := kr.GetJob(ctx, time.Minute, job.Name)
+			if gerr != nil {
+				warnLogger.LogKV("msg", "failed to get job status", "job", target, "error", gerr)
+			} else if completed, notStarted := verifyJobIteration(status); completed {
+				stats.CompletedIterations++
+			} else {
+				stats.NotStartedPods += notStarted
+				warnLogger.LogKV("msg", "job didn't complete", "job", target,
+					"succeeded", status.Status.Succeeded, "failed", status.Status.Failed,
+					"notStarted", notStarted)
+			}
+		}
+
 		derr := kr.Delete(ctx, jobsTimeout.deleteTimeout, jobFile)
 		if derr != nil {
 			warnLogger.LogKV("msg", "failed to delete job", "job", target, "error", derr)
@@ -118,6 +168,64 @@ func RepeatJobWithPod(ctx context.Context, kubeCfgPath string, namespace string,
 	}
 }
 
+// patchJobManifest overrides job's completions and parallelism if they're
+// not zero, and returns the patched manifest.
+func patchJobManifest(data []byte, podCount, parallelism int32) ([]byte, *batchv1.Job, error) {
+	if podCount < 0 || parallelism < 0 {
+		return nil, nil, fmt.Errorf("pod count and parallelism require >= 0")
+	}
+
+	job := &batchv1.Job{}
+	if err := yaml.Unmarshal(data, job); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal job: %w", err)
+	}
+	if job.Kind != "Job" || job.Name == "" {
+		return nil, nil, fmt.Errorf("expected named Job, but got %s %q", job.Kind, job.Name)
+	}
+
+	if podCount == 0 && parallelism == 0 {
+		return data, job, nil
+	}
+	if podCount > 0 {
+		job.Spec.Completions = ptr.To(podCount)
+	}
+	if parallelism > 0 {
+		job.Spec.Parallelism = ptr.To(parallelism)
+	}
+
+	data, err := yaml.Marshal(job)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal job: %w", err)
+	}
+	return data, job, nil
+}
+
+// jobCompletions returns the number of pods to complete the job.
+func jobCompletions(job *batchv1.Job) int32 {
+	return ptr.Deref(job.Spec.Completions, 1)
+}
+
+// verifyJobIteration returns true if all the pods of job succeeded.
+// Otherwise, it returns the number of pods which never started, which are
+// neither finished nor ready.
+func verifyJobIteration(job *batchv1.Job) (bool, int) {
+	completions := jobCompletions(job)
+
+	status := job.Status
+	for _, cond := range status.Conditions {
+		if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue &&
+			status.Succeeded >= completions {
+			return true, 0
+		}
+	}
+
+	notStarted := completions - status.Succeeded - status.Failed - ptr.Deref(status.Ready, 0)
+	if notStarted < 0 {
+		notStarted = 0
+	}
+	return false, int(notStarted)
+}
+
 // RenderTemplate renders a resource template to JSON for K8s API requests
 func RenderTemplate(resource string, values map[string]interface{}) ([]byte, error) {
 	// Resource template
@@ -295,7 +403,23 @@ func NewRunnerGroupSpecFileFromEmbed(target string, tweakFn func(*types.RunnerGr
 	if err != nil {
 		return "", nil, fmt.Errorf("unexpected error when read %s from embed memory: %v", target, err)
 	}
+	return newRunnerGroupSpecFile(data, tweakFn)
+}
 
+// NewRunnerGroupSpecFileFromPath is like NewRunnerGroupSpecFileFromEmbed but
+// reads load profile (RunnerGroupSpec) from local file.
+func NewRunnerGroupSpecFileFromPath(path string, tweakFn func(*types.RunnerGroupSpec) error) (_name string, _cleanup func() error, _ error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return newRunnerGroupSpecFile(data, tweakFn)
+}
+
+// newRunnerGroupSpecFile tweaks load profile (RunnerGroupSpec) and marshals
+// it into temporary file.
+func newRunnerGroupSpecFile(data []byte, tweakFn func(*types.RunnerGroupSpec) error) (_name string, _cleanup func() error, _ error) {
+	var err error
 	if tweakFn != nil {
 		var spec types.RunnerGroupSpec
 		if err = yaml.Unmarshal(data, &spec); err != nil {
@@ -315,14 +439,87 @@ func NewRunnerGroupSpecFileFromEmbed(target string, tweakFn func(*types.RunnerGr
 	return CreateTempFileWithContent(data)
 }
 
-// DeployRunnerGroup deploys runner group for benchmark.
+// TweakWarmupRunnerGroupSpec turns the runner group spec into the warm-up
+// one, which sends the same requests for duration at rate multiplied by
+// rateRatio. The rate stays unlimited if it's zero. Only weighted-random mode
+// is supported.
+func TweakWarmupRunnerGroupSpec(spec *types.RunnerGroupSpec, duration time.Duration, rateRatio float64) error {
+	if duration < time.Second {
+		return fmt.Errorf("warm-up duration requires >= 1s: %v", duration)
+	}
+	if rateRatio <= 0 || rateRatio > 1 {
+		return fmt.Errorf("warm-up rate ratio requires (0, 1]: %v", rateRatio)
+	}
+	if spec.Profile == nil {
+		return fmt.Errorf("load profile is required")
+	}
+
+	cfg, ok := spec.Profile.Spec.ModeConfig.(*types.WeightedRandomConfig)
+	if !ok {
+		return fmt.Errorf("warm-up only supports %s mode, got %s",
+			types.ModeWeightedRandom, spec.Profile.Spec.Mode)
+	}
+
+	// NOTE: Deterministic distribution requires total, while warm-up is
+	// bounded by duration.
+	cfg.Total = 0
+	cfg.Duration = types.Seconds(duration / time.Second)
+	cfg.Distribution = types.DistributionRandom
+	cfg.Rate *= rateRatio
+	spec.Profile.Description = fmt.Sprintf("warm-up for %v at %v of rate: %s",
+		duration, rateRatio, spec.Profile.Description)
+	return nil
+}
+
+// DeployRunnerGroup deploys one runner group for benchmark and waits for its
+// result. It logs the progress of runner pods on the interval, one minute by
+// default. If it times out, the pod phases and last log lines are returned
+// in error and runner group is left for inspection. If warm-up is enabled,
+// the warm-up runner group is deployed in the same way before the measured
+// one.
 func DeployRunnerGroup(ctx context.Context,
 	kubeCfgPath, runnerImage, rgCfgFile string,
-	runnerFlowControl, runnerGroupAffinity string) (*types.RunnerGroupsReport, error) {
+	runnerFlowControl, runnerGroupAffinity string,
+	opts ...DeployRunnerGroupOpt) (*types.RunnerGroupsReport, error) {
+
+	opt := &deployRunnerGroupOption{
+		progressInterval: 1 * time.Minute,
+	}
+	for _, o := range opts {
+		o(opt)
+	}
 
 	infoLogger := log.GetLogger(ctx).WithKeyValues("level", "info")
 	warnLogger := log.GetLogger(ctx).WithKeyValues("level", "warn")
 
+	if err := ValidateRunnerGroupSpecFile(rgCfgFile); err != nil {
+		return nil, err
+	}
+
+	if opt.warmupDuration > 0 {
+		warmupCfgFile, cleanup, err := NewRunnerGroupSpecFileFromPath(rgCfgFile, func(spec *types.RunnerGroupSpec) error {
+			return TweakWarmupRunnerGroupSpec(spec, opt.warmupDuration, opt.warmupRateRatio)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate warm-up runner group spec: %w", err)
+		}
+		defer func() { _ = cleanup() }()
+
+		infoLogger.LogKV("msg", "warming up", "duration", opt.warmupDuration, "rateRatio", opt.warmupRateRatio)
+		// NOTE: The warm-up's result is discarded.
+		_, err = DeployRunnerGroup(ctx, kubeCfgPath, runnerImage, warmupCfgFile,
+			runnerFlowControl, runnerGroupAffinity,
+			append(opts, WithDeployRunnerGroupWarmupOpt(0, 0))...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to warm up: %w", err)
+		}
+	}
+
+	clientset, err := BuildClientset(kubeCfgPath)
+	if err != nil {
+		return nil, err
+	}
+
 	kr := NewKperfRunner(kubeCfgPath, runnerImage)
 
 	infoLogger.LogKV("msg", "deleting existing runner group")
@@ -332,16 +529,45 @@ func DeployRunnerGroup(ctx context.Context,
 	}
 
 	infoLogger.LogKV("msg", "deploying runner group")
-	rerr := kr.RGRun(ctx, 0, rgCfgFile, runnerFlowControl, runnerGroupAffinity)
+	rerr := kr.RGRun(ctx, 0, rgCfgFile, runnerFlowControl, runnerGroupAffinity, opt.placement)
 	if rerr != nil {
 		return nil, fmt.Errorf("failed to deploy runner group: %w", rerr)
 	}
 
-	infoLogger.LogKV("msg", "start to wait runner group")
+	waitCtx, waitCancel := ctx, context.CancelFunc(func() {})
+	if opt.timeout > 0 {
+		waitCtx, waitCancel = context.WithTimeout(ctx, opt.timeout)
+	}
+	defer waitCancel()
+
+	if opt.progressInterval > 0 {
+		var wg sync.WaitGroup
+		progressCtx, progressCancel := context.WithCancel(waitCtx)
+		defer func() {
+			progressCancel()
+			wg.Wait()
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logRunnerGroupProgress(progressCtx, clientset, opt.progressInterval)
+		}()
+	}
+
+	infoLogger.LogKV("msg", "start to wait runner group", "timeout", opt.timeout)
 	for {
 		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			// NOTE: waitCtx is done so that diagnostics needs its own.
+			diagCtx, diagCancel := context.WithTimeout(context.Background(), 1*time.Minute)
+			defer diagCancel()
+			return nil, fmt.Errorf("runner group didn't finish in %v: %s",
+				opt.timeout, diagnoseRunnerGroup(diagCtx, clientset))
 		default:
 		}
 
@@ -351,7 +577,7 @@ func DeployRunnerGroup(ctx context.Context,
 		// has been restarted, the proxy tunnel will be broken and
 		// the client won't be notified. So, the client will hang forever.
 		// Using 1 min as timeout is to ensure we can get result in time.
-		data, err := kr.RGResult(ctx, 1*time.Minute)
+		data, err := kr.RGResult(waitCtx, 1*time.Minute)
 		if err != nil {
 			// FIXME(weifu): If the pod is not found, we should fast
 			// return. However, it's hard to maintain error string
@@ -374,7 +600,7 @@ func DeployRunnerGroup(ctx context.Context,
 
 		infoLogger.LogKV("msg", "deleting runner group")
 		if derr := kr.RGDelete(ctx, 0); derr != nil {
-			warnLogger.LogKV("msg", "failed to delete runner group", "err", err)
+			warnLogger.LogKV("msg", "failed to delete runner group", "err", derr)
 		}
 		return &rgResult, nil
 	}
@@ -386,25 +612,14 @@ func FetchAPIServerCores(ctx context.Context, kubeCfgPath string) (map[string]in
 
 	logger.WithKeyValues("level", "info").LogKV("msg", "fetching apiserver's cores")
 
-	kr := NewKubectlRunner(kubeCfgPath, "")
-	fqdn, err := kr.FQDN(ctx, 0)
+	metrics, err := FetchAPIServerMetrics(ctx, kubeCfgPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cluster fqdn: %w", err)
-	}
-
-	ips, nerr := NSLookup(fqdn)
-	if nerr != nil {
-		return nil, fmt.Errorf("failed get dns records of fqdn %s: %w", fqdn, nerr)
+		return nil, err
 	}
 
 	res := map[string]int{}
-	for _, ip := range ips {
+	for ip, data := range metrics {
 		cores, err := func() (int, error) {
-			data, err := kr.Metrics(ctx, 0, fqdn, ip)
-			if err != nil {
-				return 0, fmt.Errorf("failed to get metrics for ip %s: %w", ip, err)
-			}
-
 			lines := strings.Split(string(data), "\n")
 			for _, line := range lines {
 				if strings.HasPrefix(line, "go_sched_gomaxprocs_threads") {
@@ -428,6 +643,35 @@ func FetchAPIServerCores(ctx context.Context, kubeCfgPath string) (map[string]in
 	return res, nil
 }
 
+// FetchAPIServerMetrics fetchs the raw /metrics data for each kube-apiserver
+// behind the cluster's FQDN. The key is the apiserver's IP. The apiserver
+// which fails to respond is skipped with warning.
+func FetchAPIServerMetrics(ctx context.Context, kubeCfgPath string) (map[string][]byte, error) {
+	logger := log.GetLogger(ctx)
+
+	kr := NewKubectlRunner(kubeCfgPath, "")
+	fqdn, err := kr.FQDN(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster fqdn: %w", err)
+	}
+
+	ips, nerr := NSLookup(fqdn)
+	if nerr != nil {
+		return nil, fmt.Errorf("failed get dns records of fqdn %s: %w", fqdn, nerr)
+	}
+
+	res := map[string][]byte{}
+	for _, ip := range ips {
+		data, err := kr.Metrics(ctx, 0, fqdn, ip)
+		if err != nil {
+			logger.WithKeyValues("level", "warn").LogKV("msg", "failed to get metrics", "ip", ip, "error", err)
+			continue
+		}
+		res[ip] = data
+	}
+	return res, nil
+}
+
 // FetchNodeProviderIDByType is used to get one node's provider id with a given
 // instance type.
 func FetchNodeProviderIDByType(ctx context.Context, kubeCfgPath string, instanceType string) (string, error) {
@@ -17,6 +17,13 @@ type jobsTimeoutOption struct {
 	applyTimeout  time.Duration
 	waitTimeout   time.Duration
 	deleteTimeout time.Duration
+
+	// podCount overrides job's completions if it's not zero.
+	podCount int32
+	// parallelism overrides job's parallelism if it's not zero.
+	parallelism int32
+	// template is the job manifest used instead of the embedded one.
+	template []byte
 }
 
 type RollingUpdateTimeoutOpt func(*rollingUpdateTimeoutOption)
@@ -63,3 +70,86 @@ func WithJobDeleteTimeoutOpt(to time.Duration) JobTimeoutOpt {
 		jto.deleteTimeout = to
 	}
 }
+
+// WithJobPodCountOpt sets the number of pods, which is job's completions,
+// in each iteration.
+func WithJobPodCountOpt(count int32) JobTimeoutOpt {
+	return func(jto *jobsTimeoutOption) {
+		jto.podCount = count
+	}
+}
+
+// WithJobParallelismOpt sets the maximum number of pods running at the same
+// time in each iteration.
+func WithJobParallelismOpt(parallelism int32) JobTimeoutOpt {
+	return func(jto *jobsTimeoutOption) {
+		jto.parallelism = parallelism
+	}
+}
+
+// WithJobTemplateOpt uses the given job manifest in YAML instead of the
+// embedded one.
+func WithJobTemplateOpt(data []byte) JobTimeoutOpt {
+	return func(jto *jobsTimeoutOption) {
+		jto.template = data
+	}
+}
+
+type deployRunnerGroupOption struct {
+	timeout          time.Duration
+	progressInterval time.Duration
+	placement        RunnerPlacement
+	warmupDuration   time.Duration
+	warmupRateRatio  float64
+}
+
+// RunnerPlacement places runners into nodes in addition to affinity. The
+// fields are in the format of `kperf rg run` flags.
+type RunnerPlacement struct {
+	// CPU is the cpu request of each runner, like 500m or 2.
+	CPU string
+	// Memory is the memory request of each runner, like 4Gi.
+	Memory string
+	// NodeSelector is the list of node labels in KEY=VALUE format.
+	NodeSelector []string
+	// Tolerations is the list of tolerations in KEY[=VALUE][:EFFECT] or
+	// JSON format.
+	Tolerations []string
+}
+
+// DeployRunnerGroupOpt is used to configure DeployRunnerGroup.
+type DeployRunnerGroupOpt func(*deployRunnerGroupOption)
+
+// WithDeployRunnerGroupTimeoutOpt fails DeployRunnerGroup with diagnostics if
+// runner group doesn't finish in time. Zero means no timeout.
+func WithDeployRunnerGroupTimeoutOpt(to time.Duration) DeployRunnerGroupOpt {
+	return func(dro *deployRunnerGroupOption) {
+		dro.timeout = to
+	}
+}
+
+// WithDeployRunnerGroupProgressIntervalOpt sets the interval to log runner
+// group's progress. Zero means disabled.
+func WithDeployRunnerGroupProgressIntervalOpt(interval time.Duration) DeployRunnerGroupOpt {
+	return func(dro *deployRunnerGroupOption) {
+		dro.progressInterval = interval
+	}
+}
+
+// WithDeployRunnerGroupWarmupOpt runs the same load profile for duration at
+// rate multiplied by rateRatio before the measured run, and discards its
+// result. Zero duration means disabled.
+func WithDeployRunnerGroupWarmupOpt(duration time.Duration, rateRatio float64) DeployRunnerGroupOpt {
+	return func(dro *deployRunnerGroupOption) {
+		dro.warmupDuration = duration
+		dro.warmupRateRatio = rateRatio
+	}
+}
+
+// WithDeployRunnerGroupRunnerPlacementOpt places runners into nodes by the
+// resource requests, node selector and tolerations.
+func WithDeployRunnerGroupRunnerPlacementOpt(placement RunnerPlacement) DeployRunnerGroupOpt {
+	return func(dro *deployRunnerGroupOption) {
+		dro.placement = placement
+	}
+}
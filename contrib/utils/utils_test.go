@@ -0,0 +1,146 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/contrib/internal/manifests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPatchJobManifest(t *testing.T) {
+	template, err := manifests.FS.ReadFile("workload/100pod.job.yaml")
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		data        []byte
+		podCount    int32
+		parallelism int32
+		completions int32
+		parallel    int32
+		err         string
+	}{
+		"unchanged": {
+			data:        template,
+			completions: 100,
+			parallel:    10,
+		},
+		"pod count": {
+			data:        template,
+			podCount:    2000,
+			completions: 2000,
+			parallel:    10,
+		},
+		"pod count and parallelism": {
+			data:        template,
+			podCount:    500,
+			parallelism: 50,
+			completions: 500,
+			parallel:    50,
+		},
+		"negative": {
+			data:     template,
+			podCount: -1,
+			err:      "require >= 0",
+		},
+		"not job": {
+			data: []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: a\n"),
+			err:  "expected named Job",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, job, err := patchJobManifest(tc.data, tc.podCount, tc.parallelism)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "batchjobs", job.Name)
+
+			// The manifest applied is the patched one.
+			patched := &batchv1.Job{}
+			require.NoError(t, yaml.Unmarshal(data, patched))
+			assert.Equal(t, tc.completions, ptr.Deref(patched.Spec.Completions, 0))
+			assert.Equal(t, tc.parallel, ptr.Deref(patched.Spec.Parallelism, 0))
+			assert.Equal(t, tc.completions, jobCompletions(job))
+			require.Len(t, patched.Spec.Template.Spec.Containers, 1)
+			assert.Equal(t, "fake-image", patched.Spec.Template.Spec.Containers[0].Image)
+		})
+	}
+}
+
+func TestVerifyJobIteration(t *testing.T) {
+	complete := []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+
+	for name, tc := range map[string]struct {
+		status     batchv1.JobStatus
+		completed  bool
+		notStarted int
+	}{
+		"complete": {
+			status:    batchv1.JobStatus{Conditions: complete, Succeeded: 100},
+			completed: true,
+		},
+		"timeout": {
+			status:     batchv1.JobStatus{Succeeded: 60, Failed: 5, Active: 35, Ready: ptr.To[int32](15)},
+			notStarted: 20,
+		},
+		"nothing started": {
+			status:     batchv1.JobStatus{},
+			notStarted: 100,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			job := &batchv1.Job{
+				Spec:   batchv1.JobSpec{Completions: ptr.To[int32](100)},
+				Status: tc.status,
+			}
+			completed, notStarted := verifyJobIteration(job)
+			assert.Equal(t, tc.completed, completed)
+			assert.Equal(t, tc.notStarted, notStarted)
+		})
+	}
+}
+
+func TestTweakWarmupRunnerGroupSpec(t *testing.T) {
+	newSpec := func(modeCfg types.ModeConfig, mode types.ExecutionMode) *types.RunnerGroupSpec {
+		return &types.RunnerGroupSpec{
+			Count: 2,
+			Profile: &types.LoadProfile{
+				Version:     1,
+				Description: "bench",
+				Spec:        types.LoadProfileSpec{Mode: mode, ModeConfig: modeCfg},
+			},
+		}
+	}
+
+	cfg := &types.WeightedRandomConfig{
+		Rate:         100,
+		Total:        1000,
+		Distribution: types.DistributionDeterministic,
+	}
+	spec := newSpec(cfg, types.ModeWeightedRandom)
+	require.NoError(t, TweakWarmupRunnerGroupSpec(spec, 90*time.Second, 0.5))
+	assert.Equal(t, int32(2), spec.Count)
+	assert.Equal(t, float64(50), cfg.Rate)
+	assert.Equal(t, 0, cfg.Total)
+	assert.Equal(t, types.Seconds(90), cfg.Duration)
+	assert.Equal(t, types.DistributionRandom, cfg.Distribution)
+	assert.Contains(t, spec.Profile.Description, "warm-up")
+
+	err := TweakWarmupRunnerGroupSpec(newSpec(&types.WeightedRandomConfig{}, types.ModeWeightedRandom), time.Minute, 0)
+	assert.ErrorContains(t, err, "rate ratio")
+
+	err = TweakWarmupRunnerGroupSpec(newSpec(&types.TimeSeriesConfig{}, types.ModeTimeSeries), time.Minute, 0.5)
+	assert.ErrorContains(t, err, "only supports weighted-random mode")
+}
@@ -3,6 +3,7 @@
 {{- $memory := .Values.memory }}
 {{- $maxPods := .Values.maxPods }}
 {{- $labels := .Values.nodeLabels }}
+{{- $taints := .Values.nodeTaints }}
 {{- $sharedProviderID := .Values.sharedProviderID }}
 {{- range $index := (untilStep 0 (int .Values.replicas) 1) }}
 apiVersion: v1
@@ -33,6 +34,13 @@ spec:
   - effect: NoSchedule
     key: kperf.io/nodepool
     value: fake
+{{- range $taint := $taints }}
+  - effect: {{ $taint.effect }}
+    key: {{ $taint.key }}
+{{- if $taint.value }}
+    value: {{ $taint.value }}
+{{- end }}
+{{- end }}
 {{- if $sharedProviderID }}
   providerID: {{ $sharedProviderID }}
 {{- end}}
@@ -0,0 +1,106 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Azure/kperf/api/types"
+)
+
+// BuildLatencyHistogram builds latency histogram with the given upper bounds.
+func BuildLatencyHistogram(latencies []float64, buckets []float64) types.LatencyHistogram {
+	h := types.LatencyHistogram{
+		Buckets: append([]float64(nil), buckets...),
+		Counts:  make([]int64, len(buckets)+1),
+	}
+
+	for _, l := range latencies {
+		idx := sort.SearchFloat64s(buckets, l)
+		h.Counts[idx]++
+		h.Count++
+		h.Sum += l
+	}
+	return h
+}
+
+// MergeLatencyHistogram adds src into dst. Both histograms should have the
+// same bucket boundaries.
+func MergeLatencyHistogram(dst *types.LatencyHistogram, src types.LatencyHistogram) error {
+	if dst.Buckets == nil {
+		dst.Buckets = append([]float64(nil), src.Buckets...)
+		dst.Counts = make([]int64, len(src.Counts))
+	}
+
+	if !equalBuckets(dst.Buckets, src.Buckets) || len(dst.Counts) != len(src.Counts) {
+		return fmt.Errorf("mismatched histogram buckets: %v != %v", dst.Buckets, src.Buckets)
+	}
+
+	for i := range src.Counts {
+		dst.Counts[i] += src.Counts[i]
+	}
+	dst.Count += src.Count
+	dst.Sum += src.Sum
+	return nil
+}
+
+// BuildPercentileLatenciesFromHistogram builds approximate percentile
+// latencies from histogram. It interpolates linearly in the bucket, like
+// prometheus's histogram_quantile.
+func BuildPercentileLatenciesFromHistogram(h types.LatencyHistogram) [][2]float64 {
+	if h.Count == 0 || len(h.Buckets) == 0 {
+		return nil
+	}
+
+	var percentiles = []float64{0, 0.5, 0.90, 0.95, 0.99, 1}
+
+	res := make([][2]float64, len(percentiles))
+	for pi, pv := range percentiles {
+		res[pi] = [2]float64{pv, histogramQuantile(h, pv)}
+	}
+	return res
+}
+
+// histogramQuantile returns the approximate q-quantile from histogram.
+func histogramQuantile(h types.LatencyHistogram, q float64) float64 {
+	rank := math.Max(1, math.Ceil(q*float64(h.Count)))
+
+	cumulative := int64(0)
+	for i, c := range h.Counts {
+		if c == 0 {
+			continue
+		}
+		if float64(cumulative+c) < rank {
+			cumulative += c
+			continue
+		}
+
+		// The observations greater than the last bound.
+		if i == len(h.Buckets) {
+			return h.Buckets[len(h.Buckets)-1]
+		}
+
+		lower := 0.0
+		if i > 0 {
+			lower = h.Buckets[i-1]
+		}
+		upper := h.Buckets[i]
+		return lower + (upper-lower)*(rank-float64(cumulative))/float64(c)
+	}
+	return h.Buckets[len(h.Buckets)-1]
+}
+
+func equalBuckets(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLatencyHistogram(t *testing.T) {
+	h := BuildLatencyHistogram([]float64{0.05, 0.1, 0.3, 1, 2}, []float64{0.1, 0.5, 1})
+	assert.Equal(t, []float64{0.1, 0.5, 1}, h.Buckets)
+	assert.Equal(t, []int64{2, 1, 1, 1}, h.Counts)
+	assert.Equal(t, int64(5), h.Count)
+	assert.InDelta(t, 3.45, h.Sum, 1e-9)
+}
+
+func TestMergeLatencyHistogram(t *testing.T) {
+	buckets := []float64{0.1, 0.5, 1}
+
+	var merged types.LatencyHistogram
+	require.NoError(t, MergeLatencyHistogram(&merged, BuildLatencyHistogram([]float64{0.05, 0.3}, buckets)))
+	require.NoError(t, MergeLatencyHistogram(&merged, BuildLatencyHistogram([]float64{0.06, 2}, buckets)))
+
+	assert.Equal(t, BuildLatencyHistogram([]float64{0.05, 0.3, 0.06, 2}, buckets).Counts, merged.Counts)
+	assert.Equal(t, int64(4), merged.Count)
+
+	err := MergeLatencyHistogram(&merged, BuildLatencyHistogram([]float64{0.05}, []float64{0.1, 1}))
+	assert.Error(t, err)
+}
+
+func TestBuildPercentileLatenciesFromHistogram(t *testing.T) {
+	assert.Nil(t, BuildPercentileLatenciesFromHistogram(types.LatencyHistogram{}))
+
+	latencies := make([]float64, 0, 100)
+	for i := 0; i < 90; i++ {
+		latencies = append(latencies, 0.05)
+	}
+	for i := 0; i < 10; i++ {
+		latencies = append(latencies, 0.8)
+	}
+	latencies[99] = 5
+
+	res := BuildPercentileLatenciesFromHistogram(BuildLatencyHistogram(latencies, []float64{0.1, 0.5, 1}))
+	assert.Equal(t, [2]float64{0, 0.1 / 90}, res[0])
+	assert.Equal(t, [2]float64{0.5, 0.1 * 50 / 90}, res[1])
+	assert.Equal(t, [2]float64{0.9, 0.1}, res[2])
+	assert.InDelta(t, 0.5+0.5*5/9, res[3][1], 1e-9)
+	// observations beyond the last bound are reported as the last bound
+	assert.Equal(t, [2]float64{1, 1}, res[5])
+}
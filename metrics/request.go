@@ -4,8 +4,7 @@
 package metrics
 
 import (
-	"container/list"
-	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,47 +20,267 @@ type ResponseMetric interface {
 	ObserveFailure(method string, url string, now time.Time, seconds float64, err error)
 	// ObserveReceivedBytes observes the bytes read from apiserver.
 	ObserveReceivedBytes(bytes int64)
+	// ObserveResponseSize observes the bytes of one response.
+	ObserveResponseSize(method string, url string, bytes int64)
+	// ObserveWatchSetupLatency observes the time to first event or bookmark
+	// of watch.
+	ObserveWatchSetupLatency(method string, url string, seconds float64)
+	// ObserveWatchEvents observes the events and bookmarks received by watch.
+	ObserveWatchEvents(events int64, bookmarks int64)
+	// ObserveWatchEventLag observes the time from object's creation to
+	// receiving its ADDED event by watch.
+	ObserveWatchEventLag(method string, url string, seconds float64)
+	// ObserveTTFB observes the time to first byte, which is from sending
+	// request to receiving response headers.
+	ObserveTTFB(method string, url string, seconds float64)
+	// ObserveBodyReadLatency observes the time to read the whole response
+	// body after headers are received.
+	ObserveBodyReadLatency(method string, url string, seconds float64)
+	// ObserveLogLines observes the number of log lines received by one
+	// request.
+	ObserveLogLines(url string, count int64)
+	// ObserveLogTimeSpan observes the time span between the first and last
+	// log lines received by one request.
+	ObserveLogTimeSpan(url string, seconds float64)
 	// Gather returns the summary.
 	Gather() types.ResponseStats
 }
 
+// responseMetricImpl merges the observations of its shards. It observes
+// into its own shard, and the workers can observe into their own shards
+// created by NewShard so that they don't contend on one lock.
 type responseMetricImpl struct {
-	mu              sync.Mutex
-	errors          *list.List
-	receivedBytes   int64
-	latenciesByURLs map[string]*list.List
+	*responseShard
+
+	mu sync.Mutex
+	// shards are created by NewShard.
+	shards []*responseShard
 }
 
 func NewResponseMetric() ResponseMetric {
-	return &responseMetricImpl{
-		errors:          list.New(),
-		latenciesByURLs: map[string]*list.List{},
+	return &responseMetricImpl{responseShard: newResponseShard()}
+}
+
+// NewShard returns the ResponseMetric which observes into its own shard of
+// m. The shards are merged by m.Gather, which is also returned by the
+// shard's Gather. It's used by each worker so that workers don't contend on
+// one lock at high QPS. If m isn't created by NewResponseMetric, m itself is
+// returned.
+func NewShard(m ResponseMetric) ResponseMetric {
+	impl, ok := m.(*responseMetricImpl)
+	if !ok {
+		return m
+	}
+
+	shard := newResponseShard()
+	impl.mu.Lock()
+	impl.shards = append(impl.shards, shard)
+	impl.mu.Unlock()
+	return &shardMetric{responseShard: shard, parent: impl}
+}
+
+// shardMetric is the ResponseMetric of one shard.
+type shardMetric struct {
+	*responseShard
+	parent *responseMetricImpl
+}
+
+// Gather implements ResponseMetric.
+func (m *shardMetric) Gather() types.ResponseStats {
+	return m.parent.Gather()
+}
+
+// Gather implements ResponseMetric. The shards are locked one by one, so
+// the result gathered while observing isn't a snapshot of one instant.
+func (m *responseMetricImpl) Gather() types.ResponseStats {
+	m.mu.Lock()
+	shards := append([]*responseShard{m.responseShard}, m.shards...)
+	m.mu.Unlock()
+
+	stats := types.ResponseStats{
+		Errors:                   []types.ResponseError{},
+		LatenciesByURL:           map[string][]float64{},
+		AttemptsByURL:            map[string]int64{},
+		FailuresByURL:            map[string]int64{},
+		AttemptsByMethod:         map[string]int64{},
+		FailuresByMethod:         map[string]int64{},
+		WatchSetupLatenciesByURL: map[string][]float64{},
+		WatchEventLagsByURL:      map[string][]float64{},
+		TTFBByURL:                map[string][]float64{},
+		BodyReadLatenciesByURL:   map[string][]float64{},
+	}
+	for _, shard := range shards {
+		shard.mergeInto(&stats)
+	}
+
+	// NOTE: The errors in one shard are in order. It keeps the order of
+	// errors observed at the same time.
+	sort.SliceStable(stats.Errors, func(i, j int) bool {
+		return stats.Errors[i].Timestamp.Before(stats.Errors[j].Timestamp)
+	})
+	return stats
+}
+
+// responseShard accumulates observations. It's safe to use concurrently but
+// it's expected to be used by one worker mostly.
+type responseShard struct {
+	mu                   sync.Mutex
+	errors               []types.ResponseError
+	receivedBytes        int64
+	latenciesByURLs      map[string][]float64
+	watchSetupLatsByURLs map[string][]float64
+	watchEventLagsByURLs map[string][]float64
+	respSizesByURLs      map[string][]int64
+	ttfbByURLs           map[string][]float64
+	bodyReadLatsByURLs   map[string][]float64
+	watchEvents          int64
+	watchBookmarks       int64
+	attemptsByURLs       map[string]int64
+	failuresByURLs       map[string]int64
+	attemptsByMethods    map[string]int64
+	failuresByMethods    map[string]int64
+	logLinesByURLs       map[string]int64
+	logTimeSpanByURLs    map[string]float64
+}
+
+func newResponseShard() *responseShard {
+	return &responseShard{
+		latenciesByURLs:      map[string][]float64{},
+		watchSetupLatsByURLs: map[string][]float64{},
+		watchEventLagsByURLs: map[string][]float64{},
+		respSizesByURLs:      map[string][]int64{},
+		ttfbByURLs:           map[string][]float64{},
+		bodyReadLatsByURLs:   map[string][]float64{},
+		attemptsByURLs:       map[string]int64{},
+		failuresByURLs:       map[string]int64{},
+		attemptsByMethods:    map[string]int64{},
+		failuresByMethods:    map[string]int64{},
+		logLinesByURLs:       map[string]int64{},
+		logTimeSpanByURLs:    map[string]float64{},
 	}
 }
 
 // ObserveLatency implements ResponseMetric.
-func (m *responseMetricImpl) ObserveLatency(method string, url string, seconds float64) {
+func (m *responseShard) ObserveLatency(method string, url string, seconds float64) {
+	key := urlKey(method, url)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	key := fmt.Sprintf("%s %s", method, url)
-	l, ok := m.latenciesByURLs[key]
-	if !ok {
-		m.latenciesByURLs[key] = list.New()
-		l = m.latenciesByURLs[key]
+	m.latenciesByURLs[key] = append(m.latenciesByURLs[key], seconds)
+	m.observeAttempt(method, key, false)
+}
+
+// ObserveWatchSetupLatency implements ResponseMetric.
+func (m *responseShard) ObserveWatchSetupLatency(method string, url string, seconds float64) {
+	key := urlKey(method, url)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.watchSetupLatsByURLs[key] = append(m.watchSetupLatsByURLs[key], seconds)
+}
+
+// ObserveWatchEventLag implements ResponseMetric.
+func (m *responseShard) ObserveWatchEventLag(method string, url string, seconds float64) {
+	key := urlKey(method, url)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.watchEventLagsByURLs[key] = append(m.watchEventLagsByURLs[key], seconds)
+}
+
+// ObserveTTFB implements ResponseMetric.
+func (m *responseShard) ObserveTTFB(method string, url string, seconds float64) {
+	key := urlKey(method, url)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ttfbByURLs[key] = append(m.ttfbByURLs[key], seconds)
+}
+
+// ObserveBodyReadLatency implements ResponseMetric.
+func (m *responseShard) ObserveBodyReadLatency(method string, url string, seconds float64) {
+	key := urlKey(method, url)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bodyReadLatsByURLs[key] = append(m.bodyReadLatsByURLs[key], seconds)
+}
+
+// ObserveWatchEvents implements ResponseMetric.
+func (m *responseShard) ObserveWatchEvents(events int64, bookmarks int64) {
+	atomic.AddInt64(&m.watchEvents, events)
+	atomic.AddInt64(&m.watchBookmarks, bookmarks)
+}
+
+// ObserveLogLines implements ResponseMetric.
+func (m *responseShard) ObserveLogLines(url string, count int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.logLinesByURLs[url] += count
+}
+
+// ObserveLogTimeSpan implements ResponseMetric. The longest one is kept.
+func (m *responseShard) ObserveLogTimeSpan(url string, seconds float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cur, ok := m.logTimeSpanByURLs[url]; !ok || seconds > cur {
+		m.logTimeSpanByURLs[url] = seconds
+	}
+}
+
+// observeAttempt counts the attempt by url key and method.
+//
+// NOTE: The caller should hold the lock.
+func (m *responseShard) observeAttempt(method string, key string, failed bool) {
+	m.attemptsByURLs[key]++
+	m.attemptsByMethods[method]++
+	if failed {
+		m.failuresByURLs[key]++
+		m.failuresByMethods[method]++
 	}
-	l.PushBack(seconds)
+}
+
+// ObserveResponseSize implements ResponseMetric.
+func (m *responseShard) ObserveResponseSize(method string, url string, bytes int64) {
+	key := urlKey(method, url)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.respSizesByURLs[key] = append(m.respSizesByURLs[key], bytes)
+}
+
+// urlKey returns the key of observation by method and url.
+func urlKey(method string, url string) string {
+	return method + " " + url
 }
 
 // ObserveFailure implements ResponseMetric.
-func (m *responseMetricImpl) ObserveFailure(method string, url string, now time.Time, seconds float64, err error) {
+func (m *responseShard) ObserveFailure(method string, url string, now time.Time, seconds float64, err error) {
 	if err == nil {
 		return
 	}
 
+	oerr := BuildResponseError(method, url, now, seconds, err)
+
+	key := urlKey(method, url)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.observeAttempt(method, key, true)
+	m.errors = append(m.errors, oerr)
+}
+
+// BuildResponseError classifies err of the request into types.ResponseError.
+func BuildResponseError(method string, url string, now time.Time, seconds float64, err error) types.ResponseError {
 	oerr := types.ResponseError{
 		Method:    method,
 		URL:       url,
@@ -87,45 +306,67 @@ func (m *responseMetricImpl) ObserveFailure(method string, url string, now time.
 		oerr.Type = types.ResponseErrorTypeUnknown
 		oerr.Message = err.Error()
 	}
-	m.errors.PushBack(oerr)
+	return oerr
 }
 
 // ObserveReceivedBytes implements ResponseMetric.
-func (m *responseMetricImpl) ObserveReceivedBytes(bytes int64) {
+func (m *responseShard) ObserveReceivedBytes(bytes int64) {
 	atomic.AddInt64(&m.receivedBytes, bytes)
 }
 
-// Gather implements ResponseMetric.
-func (m *responseMetricImpl) Gather() types.ResponseStats {
-	return types.ResponseStats{
-		Errors:             m.dumpErrors(),
-		LatenciesByURL:     m.dumpLatencies(),
-		TotalReceivedBytes: atomic.LoadInt64(&m.receivedBytes),
-	}
-}
-
-func (m *responseMetricImpl) dumpLatencies() map[string][]float64 {
+// mergeInto adds the observations into stats. The slices of stats are
+// copied so that later observations don't change stats.
+func (m *responseShard) mergeInto(stats *types.ResponseStats) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	res := make(map[string][]float64)
-	for u, latencies := range m.latenciesByURLs {
-		res[u] = make([]float64, 0, latencies.Len())
+	stats.Errors = append(stats.Errors, m.errors...)
+	stats.TotalReceivedBytes += atomic.LoadInt64(&m.receivedBytes)
+	stats.TotalWatchEvents += atomic.LoadInt64(&m.watchEvents)
+	stats.TotalWatchBookmarks += atomic.LoadInt64(&m.watchBookmarks)
+
+	mergeLists(stats.LatenciesByURL, m.latenciesByURLs)
+	mergeLists(stats.WatchSetupLatenciesByURL, m.watchSetupLatsByURLs)
+	mergeLists(stats.WatchEventLagsByURL, m.watchEventLagsByURLs)
+	mergeLists(stats.TTFBByURL, m.ttfbByURLs)
+	mergeLists(stats.BodyReadLatenciesByURL, m.bodyReadLatsByURLs)
+	mergeCounts(stats.AttemptsByURL, m.attemptsByURLs)
+	mergeCounts(stats.FailuresByURL, m.failuresByURLs)
+	mergeCounts(stats.AttemptsByMethod, m.attemptsByMethods)
+	mergeCounts(stats.FailuresByMethod, m.failuresByMethods)
 
-		for e := latencies.Front(); e != nil; e = e.Next() {
-			res[u] = append(res[u], e.Value.(float64))
+	if len(m.respSizesByURLs) > 0 {
+		if stats.ResponseSizesByURL == nil {
+			stats.ResponseSizesByURL = make(map[string][]int64, len(m.respSizesByURLs))
+		}
+		mergeLists(stats.ResponseSizesByURL, m.respSizesByURLs)
+	}
+	if len(m.logLinesByURLs) > 0 {
+		if stats.LogLinesByURL == nil {
+			stats.LogLinesByURL = make(map[string]int64, len(m.logLinesByURLs))
+		}
+		mergeCounts(stats.LogLinesByURL, m.logLinesByURLs)
+	}
+	for u, span := range m.logTimeSpanByURLs {
+		if stats.LogTimeSpanByURL == nil {
+			stats.LogTimeSpanByURL = make(map[string]float64, len(m.logTimeSpanByURLs))
+		}
+		if cur, ok := stats.LogTimeSpanByURL[u]; !ok || span > cur {
+			stats.LogTimeSpanByURL[u] = span
 		}
 	}
-	return res
 }
 
-func (m *responseMetricImpl) dumpErrors() []types.ResponseError {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// mergeLists appends the values of src into dst by key.
+func mergeLists[T float64 | int64](dst, src map[string][]T) {
+	for k, values := range src {
+		dst[k] = append(dst[k], values...)
+	}
+}
 
-	res := make([]types.ResponseError, 0, m.errors.Len())
-	for e := m.errors.Front(); e != nil; e = e.Next() {
-		res = append(res, e.Value.(types.ResponseError))
+// mergeCounts adds the counts of src into dst by key.
+func mergeCounts(dst, src map[string]int64) {
+	for k, v := range src {
+		dst[k] += v
 	}
-	return res
 }
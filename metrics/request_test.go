@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"runtime"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
@@ -186,3 +188,183 @@ func TestResponseMetric_ObserveFailure(t *testing.T) {
 	errors := m.Gather().Errors
 	assert.Equal(t, expectedErrors, errors)
 }
+
+func TestResponseMetric_ObserveWatch(t *testing.T) {
+	m := NewResponseMetric()
+
+	m.ObserveLatency("WATCHCHURN", "/api/v1/pods", 30)
+	m.ObserveWatchSetupLatency("WATCHCHURN", "/api/v1/pods", 0.1)
+	m.ObserveWatchSetupLatency("WATCHCHURN", "/api/v1/pods", 0.2)
+	m.ObserveWatchEvents(10, 1)
+	m.ObserveWatchEvents(5, 2)
+
+	stats := m.Gather()
+	assert.Equal(t, map[string][]float64{"WATCHCHURN /api/v1/pods": {30}}, stats.LatenciesByURL)
+	assert.Equal(t, map[string][]float64{"WATCHCHURN /api/v1/pods": {0.1, 0.2}}, stats.WatchSetupLatenciesByURL)
+	assert.Equal(t, int64(15), stats.TotalWatchEvents)
+	assert.Equal(t, int64(3), stats.TotalWatchBookmarks)
+}
+
+func TestResponseMetric_ObserveTieredLatency(t *testing.T) {
+	m := NewResponseMetric()
+
+	m.ObserveTTFB("GET", "/api/v1/pods", 0.1)
+	m.ObserveTTFB("GET", "/api/v1/pods", 0.2)
+	m.ObserveBodyReadLatency("GET", "/api/v1/pods", 1.5)
+
+	stats := m.Gather()
+	assert.Equal(t, map[string][]float64{"GET /api/v1/pods": {0.1, 0.2}}, stats.TTFBByURL)
+	assert.Equal(t, map[string][]float64{"GET /api/v1/pods": {1.5}}, stats.BodyReadLatenciesByURL)
+}
+
+func TestResponseMetric_ObserveLogs(t *testing.T) {
+	m := NewResponseMetric()
+	stats := m.Gather()
+	assert.Nil(t, stats.LogLinesByURL)
+	assert.Nil(t, stats.LogTimeSpanByURL)
+
+	m.ObserveLogLines("/api/v1/namespaces/default/pods/a/log", 10)
+	m.ObserveLogLines("/api/v1/namespaces/default/pods/a/log", 5)
+	m.ObserveLogTimeSpan("/api/v1/namespaces/default/pods/a/log", 2)
+	m.ObserveLogTimeSpan("/api/v1/namespaces/default/pods/a/log", 1)
+
+	stats = m.Gather()
+	assert.Equal(t, map[string]int64{"/api/v1/namespaces/default/pods/a/log": 15}, stats.LogLinesByURL)
+	assert.Equal(t, map[string]float64{"/api/v1/namespaces/default/pods/a/log": 2}, stats.LogTimeSpanByURL)
+}
+
+func TestResponseMetric_ObserveResponseSize(t *testing.T) {
+	m := NewResponseMetric()
+	assert.Nil(t, m.Gather().ResponseSizesByURL)
+
+	for _, size := range []int64{100, 200, 300, 400, 500} {
+		m.ObserveResponseSize("LIST", "/api/v1/pods", size)
+	}
+	m.ObserveResponseSize("GET", "/api/v1/pods/a", 10)
+
+	stats := m.Gather()
+	assert.Equal(t, map[string][]int64{
+		"LIST /api/v1/pods":  {100, 200, 300, 400, 500},
+		"GET /api/v1/pods/a": {10},
+	}, stats.ResponseSizesByURL)
+	assert.Equal(t, [2]float64{0.5, 300}, BuildResponseSizeHistogram(stats.ResponseSizesByURL["LIST /api/v1/pods"])[1])
+}
+
+func TestResponseMetric_ErrorRates(t *testing.T) {
+	m := NewResponseMetric()
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		m.ObserveLatency("LIST", "/api/v1/pods", 0.1)
+	}
+	for i := 0; i < 6; i++ {
+		m.ObserveLatency("GET", "/api/v1/pods/a", 0.1)
+	}
+	for i := 0; i < 4; i++ {
+		m.ObserveFailure("GET", "/api/v1/pods/a", now, 0.1, apierrors.NewTooManyRequestsError("retry"))
+	}
+
+	stats := m.Gather()
+	assert.Equal(t, map[string]types.ErrorRate{
+		"LIST /api/v1/pods":  {Attempts: 10, Failures: 0, Rate: 0},
+		"GET /api/v1/pods/a": {Attempts: 10, Failures: 4, Rate: 0.4},
+	}, BuildErrorRates(stats.AttemptsByURL, stats.FailuresByURL))
+	assert.Equal(t, map[string]types.ErrorRate{
+		"LIST": {Attempts: 10, Failures: 0, Rate: 0},
+		"GET":  {Attempts: 10, Failures: 4, Rate: 0.4},
+	}, BuildErrorRates(stats.AttemptsByMethod, stats.FailuresByMethod))
+}
+
+func TestResponseMetric_Shards(t *testing.T) {
+	m := NewResponseMetric()
+	m.ObserveLatency("GET", "/api/v1/pods/a", 0.1)
+
+	now := time.Now()
+	shards := []ResponseMetric{NewShard(m), NewShard(m)}
+	shards[0].ObserveLatency("GET", "/api/v1/pods/a", 0.2)
+	shards[0].ObserveFailure("GET", "/api/v1/pods/a", now.Add(time.Second), 0.1, apierrors.NewTooManyRequestsError("retry"))
+	shards[1].ObserveLatency("LIST", "/api/v1/pods", 0.3)
+	shards[1].ObserveFailure("GET", "/api/v1/pods/a", now, 0.1, apierrors.NewTooManyRequestsError("retry"))
+	shards[1].ObserveReceivedBytes(10)
+	shards[1].ObserveLogTimeSpan("/log", 2)
+	m.ObserveReceivedBytes(5)
+	m.ObserveLogTimeSpan("/log", 1)
+
+	stats := shards[1].Gather()
+	assert.Equal(t, m.Gather(), stats)
+	assert.Equal(t, map[string][]float64{
+		"GET /api/v1/pods/a": {0.1, 0.2},
+		"LIST /api/v1/pods":  {0.3},
+	}, stats.LatenciesByURL)
+	assert.Equal(t, map[string]int64{"GET /api/v1/pods/a": 4, "LIST /api/v1/pods": 1}, stats.AttemptsByURL)
+	assert.Equal(t, map[string]int64{"GET": 2}, stats.FailuresByMethod)
+	assert.Equal(t, int64(15), stats.TotalReceivedBytes)
+	assert.Equal(t, map[string]float64{"/log": 2}, stats.LogTimeSpanByURL)
+	assert.Len(t, stats.Errors, 2)
+	assert.Equal(t, now, stats.Errors[0].Timestamp, "errors are sorted by time")
+
+	// The gathered stats don't change by later observations.
+	shards[0].ObserveLatency("GET", "/api/v1/pods/a", 0.4)
+	assert.Equal(t, []float64{0.1, 0.2}, stats.LatenciesByURL["GET /api/v1/pods/a"])
+}
+
+func TestResponseMetric_GatherDuringObserve(t *testing.T) {
+	m := NewResponseMetric()
+
+	const workers, requests = 8, 1000
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shard := NewShard(m)
+			for j := 0; j < requests; j++ {
+				shard.ObserveLatency("GET", "/api/v1/pods", 0.1)
+				shard.ObserveReceivedBytes(1)
+				shard.ObserveResponseSize("GET", "/api/v1/pods", 1)
+			}
+		}()
+	}
+
+	// NOTE: Run with -race to verify the snapshot used by progress.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		prev := 0
+		for i := 0; i < 100; i++ {
+			n := len(m.Gather().LatenciesByURL["GET /api/v1/pods"])
+			assert.GreaterOrEqual(t, n, prev)
+			prev = n
+		}
+	}()
+
+	wg.Wait()
+	<-done
+
+	stats := m.Gather()
+	assert.Len(t, stats.LatenciesByURL["GET /api/v1/pods"], workers*requests)
+	assert.Equal(t, int64(workers*requests), stats.TotalReceivedBytes)
+}
+
+// benchmarkObserve observes latencies from 256 goroutines.
+func benchmarkObserve(b *testing.B, newMetric func(ResponseMetric) ResponseMetric) {
+	m := NewResponseMetric()
+
+	b.ReportAllocs()
+	b.SetParallelism(256 / max(runtime.GOMAXPROCS(0), 1))
+	b.RunParallel(func(pb *testing.PB) {
+		worker := newMetric(m)
+		for pb.Next() {
+			worker.ObserveReceivedBytes(100)
+			worker.ObserveLatency("GET", "/api/v1/namespaces/default/pods/a", 0.01)
+		}
+	})
+}
+
+func BenchmarkResponseMetricShared(b *testing.B) {
+	benchmarkObserve(b, func(m ResponseMetric) ResponseMetric { return m })
+}
+
+func BenchmarkResponseMetricSharded(b *testing.B) {
+	benchmarkObserve(b, NewShard)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// streamingEntry is one line written by StreamingResponseMetric.
+type streamingEntry struct {
+	// Timestamp is the unix time in seconds, with microsecond precision,
+	// when request is sent.
+	Timestamp float64 `json:"ts"`
+	Method    string  `json:"method"`
+	URL       string  `json:"url"`
+	LatencyMS float64 `json:"latency_ms"`
+	Bytes     int64   `json:"bytes"`
+	// Error is null for successful request.
+	Error *string `json:"error"`
+}
+
+// StreamingResponseMetric writes each completed request as one line of
+// compact JSON, instead of aggregating them, so that the results can be
+// consumed in real time. It's safe to use concurrently.
+type StreamingResponseMetric struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+	// err is the first error of writing. The rest of lines are dropped
+	// after that.
+	err error
+}
+
+// NewStreamingResponseMetric returns StreamingResponseMetric writing to w.
+func NewStreamingResponseMetric(w io.Writer) *StreamingResponseMetric {
+	return &StreamingResponseMetric{w: bufio.NewWriter(w)}
+}
+
+// ObserveRequest writes the completed request sent at start. The line is
+// flushed immediately.
+func (m *StreamingResponseMetric) ObserveRequest(start time.Time, method string, url string, seconds float64, bytes int64, err error) {
+	entry := streamingEntry{
+		Timestamp: float64(start.UnixMicro()) / 1e6,
+		Method:    method,
+		URL:       url,
+		LatencyMS: seconds * 1000,
+		Bytes:     bytes,
+	}
+	if err != nil {
+		msg := err.Error()
+		entry.Error = &msg
+	}
+	m.writeLine(entry)
+}
+
+// WriteSummary writes v as the final line, like the summary of all the
+// requests after schedule returns. It returns the first error of writing.
+func (m *StreamingResponseMetric) WriteSummary(v interface{}) error {
+	m.writeLine(v)
+	return m.Flush()
+}
+
+// Flush flushes the buffered data and returns the first error of writing.
+func (m *StreamingResponseMetric) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err == nil {
+		m.err = m.w.Flush()
+	}
+	return m.err
+}
+
+// writeLine writes v as one line of compact JSON and flushes it.
+func (m *StreamingResponseMetric) writeLine(v interface{}) {
+	data, err := json.Marshal(v)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return
+	}
+	if err != nil {
+		m.err = fmt.Errorf("failed to marshal streaming line: %w", err)
+		return
+	}
+
+	data = append(data, '\n')
+	if _, err := m.w.Write(data); err != nil {
+		m.err = fmt.Errorf("failed to write streaming line: %w", err)
+		return
+	}
+	if err := m.w.Flush(); err != nil {
+		m.err = fmt.Errorf("failed to flush streaming line: %w", err)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamingResponseMetric(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewStreamingResponseMetric(&buf)
+
+	start := time.Unix(1234567890, 123000000)
+	m.ObserveRequest(start, "GET", "/api/v1/pods", 0.0052, 1024, nil)
+	assert.Equal(t,
+		`{"ts":1234567890.123,"method":"GET","url":"/api/v1/pods","latency_ms":5.2,"bytes":1024,"error":null}`+"\n",
+		buf.String(), "line should be flushed immediately")
+
+	m.ObserveRequest(start, "GET", "/api/v1/nodes", 1, 0, errors.New("boom"))
+	require.NoError(t, m.WriteSummary(map[string]int{"total": 2}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t,
+		`{"ts":1234567890.123,"method":"GET","url":"/api/v1/nodes","latency_ms":1000,"bytes":0,"error":"boom"}`,
+		lines[1])
+	assert.Equal(t, `{"total":2}`, lines[2])
+}
+
+func TestStreamingResponseMetricConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewStreamingResponseMetric(&buf)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.ObserveRequest(time.Now(), "GET", fmt.Sprintf("/%d/%d", i, j), 0.001, 1, nil)
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, m.Flush())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 1000)
+	for _, line := range lines {
+		var entry streamingEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+	}
+}
+
+type failedWriter struct{}
+
+func (failedWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestStreamingResponseMetricWriteError(t *testing.T) {
+	m := NewStreamingResponseMetric(failedWriter{})
+	m.ObserveRequest(time.Now(), "GET", "/", 0.001, 1, nil)
+	m.ObserveRequest(time.Now(), "GET", "/", 0.001, 1, nil)
+
+	err := m.WriteSummary(struct{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disk full")
+}
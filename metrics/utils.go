@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/Azure/kperf/api/types"
 	"golang.org/x/net/http2"
@@ -41,6 +42,31 @@ func BuildPercentileLatencies(latencies []float64) [][2]float64 {
 	return res
 }
 
+// BuildResponseSizeHistogram builds percentile response sizes in bytes. The
+// percentiles are the same as BuildPercentileLatencies.
+func BuildResponseSizeHistogram(sizes []int64) [][2]float64 {
+	values := make([]float64, 0, len(sizes))
+	for _, s := range sizes {
+		values = append(values, float64(s))
+	}
+	return BuildPercentileLatencies(values)
+}
+
+// BuildSkew returns the ratio of max to min counts. The min is treated as 1
+// if it's zero.
+func BuildSkew(counts []int64) float64 {
+	if len(counts) == 0 {
+		return 0
+	}
+
+	minN, maxN := counts[0], counts[0]
+	for _, n := range counts[1:] {
+		minN = min(minN, n)
+		maxN = max(maxN, n)
+	}
+	return float64(maxN) / float64(max(minN, 1))
+}
+
 // BuildErrorStatsGroupByType summaries total count for each type of errors.
 func BuildErrorStatsGroupByType(errors []types.ResponseError) map[string]int32 {
 	res := map[string]int32{}
@@ -58,6 +84,61 @@ func BuildErrorStatsGroupByType(errors []types.ResponseError) map[string]int32 {
 	return res
 }
 
+// BuildErrorRates builds error rates from the number of attempts and failures.
+func BuildErrorRates(attempts, failures map[string]int64) map[string]types.ErrorRate {
+	res := make(map[string]types.ErrorRate, len(attempts))
+	for key, n := range attempts {
+		res[key] = newErrorRate(n, failures[key])
+	}
+	return res
+}
+
+// MergeBenchmarkWindow widens the benchmark window from start to end to
+// cover the one from srcStart to srcEnd, which is the earliest start time and
+// the latest end time. The times are in RFC3339 and the invalid ones are
+// ignored.
+func MergeBenchmarkWindow(start, end *string, srcStart, srcEnd string) {
+	if t, ok := parseRFC3339(srcStart); ok {
+		if cur, ok := parseRFC3339(*start); !ok || t.Before(cur) {
+			*start = srcStart
+		}
+	}
+	if t, ok := parseRFC3339(srcEnd); ok {
+		if cur, ok := parseRFC3339(*end); !ok || t.After(cur) {
+			*end = srcEnd
+		}
+	}
+}
+
+// parseRFC3339 parses non-empty time in RFC3339.
+func parseRFC3339(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
+
+// MergeErrorRates merges error rates from src into dst. The attempts and
+// failures are summed up and then the rate is recomputed.
+func MergeErrorRates(dst, src map[string]types.ErrorRate) {
+	for key, r := range src {
+		d := dst[key]
+		dst[key] = newErrorRate(d.Attempts+r.Attempts, d.Failures+r.Failures)
+	}
+}
+
+func newErrorRate(attempts, failures int64) types.ErrorRate {
+	r := types.ErrorRate{
+		Attempts: attempts,
+		Failures: failures,
+	}
+	if attempts > 0 {
+		r.Rate = float64(failures) / float64(attempts)
+	}
+	return r
+}
+
 var (
 	// errHTTP2ClientConnectionLost is used to track unexported http2 error.
 	errHTTP2ClientConnectionLost = errors.New("http2: client connection lost")
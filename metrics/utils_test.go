@@ -6,6 +6,8 @@ package metrics
 import (
 	"testing"
 
+	"github.com/Azure/kperf/api/types"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -34,3 +36,60 @@ func TestBuildPercentileLatencies(t *testing.T) {
 	assert.Equal(t, [2]float64{0.99, 0}, res[4])
 	assert.Equal(t, [2]float64{1, 50}, res[5])
 }
+
+func TestBuildResponseSizeHistogram(t *testing.T) {
+	assert.Nil(t, BuildResponseSizeHistogram(nil))
+
+	res := BuildResponseSizeHistogram([]int64{500, 100, 400, 200, 300})
+	assert.Equal(t, [][2]float64{
+		{0, 100},
+		{0.5, 300},
+		{0.9, 500},
+		{0.95, 500},
+		{0.99, 500},
+		{1, 500},
+	}, res)
+}
+
+func TestBuildSkew(t *testing.T) {
+	assert.Equal(t, float64(0), BuildSkew(nil))
+	assert.Equal(t, float64(1), BuildSkew([]int64{5, 5, 5}))
+	assert.Equal(t, float64(4), BuildSkew([]int64{10, 40, 20}))
+	assert.Equal(t, float64(7), BuildSkew([]int64{0, 7}))
+}
+
+func TestMergeErrorRates(t *testing.T) {
+	dst := map[string]types.ErrorRate{
+		"GET /api/v1/pods/a": {Attempts: 10, Failures: 9, Rate: 0.9},
+	}
+	MergeErrorRates(dst, map[string]types.ErrorRate{
+		"GET /api/v1/pods/a": {Attempts: 90, Failures: 1, Rate: 1.0 / 90},
+		"LIST /api/v1/pods":  {Attempts: 5, Failures: 0, Rate: 0},
+	})
+
+	// Not the average of rates (0.9 + 0.011) / 2
+	assert.Equal(t, map[string]types.ErrorRate{
+		"GET /api/v1/pods/a": {Attempts: 100, Failures: 10, Rate: 0.1},
+		"LIST /api/v1/pods":  {Attempts: 5, Failures: 0, Rate: 0},
+	}, dst)
+}
+
+func TestMergeBenchmarkWindow(t *testing.T) {
+	var start, end string
+	MergeBenchmarkWindow(&start, &end, "2024-01-01T00:00:10Z", "2024-01-01T00:01:00Z")
+	assert.Equal(t, "2024-01-01T00:00:10Z", start)
+	assert.Equal(t, "2024-01-01T00:01:00Z", end)
+
+	MergeBenchmarkWindow(&start, &end, "2024-01-01T00:00:05.5Z", "2024-01-01T00:00:50Z")
+	assert.Equal(t, "2024-01-01T00:00:05.5Z", start)
+	assert.Equal(t, "2024-01-01T00:01:00Z", end)
+
+	MergeBenchmarkWindow(&start, &end, "", "invalid")
+	assert.Equal(t, "2024-01-01T00:00:05.5Z", start)
+	assert.Equal(t, "2024-01-01T00:01:00Z", end)
+
+	// Time zones are compared by instant.
+	MergeBenchmarkWindow(&start, &end, "2024-01-01T08:00:00+08:00", "2024-01-01T08:02:00+08:00")
+	assert.Equal(t, "2024-01-01T08:00:00+08:00", start)
+	assert.Equal(t, "2024-01-01T08:02:00+08:00", end)
+}
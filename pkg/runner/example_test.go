@@ -0,0 +1,60 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/pkg/runner"
+
+	"k8s.io/client-go/rest"
+)
+
+// This example runs weighted-random benchmark against fake apiserver. The
+// rest.Config can be the one from envtest or any cluster.
+func ExampleRunner_Run() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","items":[]}`)
+	}))
+	defer srv.Close()
+
+	profile := types.LoadProfile{
+		Version: 1,
+		Spec: types.LoadProfileSpec{
+			Conns:       2,
+			Client:      4,
+			ContentType: types.ContentTypeJSON,
+			Mode:        types.ModeWeightedRandom,
+			ModeConfig: &types.WeightedRandomConfig{
+				Total: 100,
+				Requests: []*types.WeightedRequest{
+					{
+						Shares: 100,
+						StaleList: &types.RequestList{
+							KubeGroupVersionResource: types.KubeGroupVersionResource{
+								Version:  "v1",
+								Resource: "pods",
+							},
+							Namespace: "default",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	report, err := (&runner.Runner{}).Run(context.Background(), profile, runner.Options{
+		RestConfig: &rest.Config{Host: srv.URL},
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("total: %d, errors: %d\n", report.Metrics.Total, report.Metrics.ErrorCount)
+	// Output: total: 100, errors: 0
+}
@@ -0,0 +1,154 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/metrics"
+	"github.com/Azure/kperf/request"
+)
+
+// BuildMetricReport builds report from schedule's result. The histogram
+// uses types.DefaultHistogramBuckets if histogramBuckets is empty.
+func BuildMetricReport(includeRawData bool, histogramBuckets []float64, stats *request.Result) *types.RunnerMetricReport {
+	if len(histogramBuckets) == 0 {
+		histogramBuckets = types.DefaultHistogramBuckets
+	}
+
+	output := types.RunnerMetricReport{
+		SchemaVersion:      types.RunnerMetricReportSchemaVersion,
+		Total:              stats.Total,
+		SuccessCount:       stats.SuccessCount(),
+		ErrorCount:         stats.ErrorCount(),
+		ErrorRate:          stats.ErrorRate(),
+		SuccessRate:        stats.SuccessRate(),
+		ErrorStats:         metrics.BuildErrorStatsGroupByType(stats.Errors),
+		ErrorRateByURL:     metrics.BuildErrorRates(stats.AttemptsByURL, stats.FailuresByURL),
+		ErrorRateByMethod:  metrics.BuildErrorRates(stats.AttemptsByMethod, stats.FailuresByMethod),
+		Duration:           stats.Duration.String(),
+		TotalReceivedBytes: stats.TotalReceivedBytes,
+		ExecutorReport:     stats.ExecutorReport,
+
+		PercentileLatenciesByURL: map[string][][2]float64{},
+		LatencyHistograms:        map[string]types.LatencyHistogram{},
+	}
+
+	total := 0
+	for _, latencies := range stats.LatenciesByURL {
+		total += len(latencies)
+	}
+	latencies := make([]float64, 0, total)
+	for _, l := range stats.LatenciesByURL {
+		latencies = append(latencies, l...)
+	}
+	output.PercentileLatencies = metrics.BuildPercentileLatencies(latencies)
+
+	if stats.DispatchBlockedTime > 0 {
+		output.DispatchBlockedTime = stats.DispatchBlockedTime.String()
+		output.MaxDispatchBlockedTime = stats.MaxDispatchBlockedTime.String()
+	}
+
+	if stats.ConnectionRampInterval > 0 {
+		output.ConnectionRampInterval = stats.ConnectionRampInterval.String()
+	}
+	output.ClientRotationCount = stats.ClientRotationCount
+
+	if !stats.StartTime.IsZero() {
+		output.BenchmarkStartTime = stats.StartTime.UTC().Format(time.RFC3339Nano)
+	}
+	if !stats.EndTime.IsZero() {
+		output.BenchmarkEndTime = stats.EndTime.UTC().Format(time.RFC3339Nano)
+	}
+
+	if stats.TerminationCause != nil {
+		output.TerminatedEarly = true
+		output.TerminationCause = stats.TerminationCause.Error()
+	}
+	output.FailFastError = stats.FailFastError
+
+	for u, l := range stats.LatenciesByURL {
+		output.PercentileLatenciesByURL[u] = metrics.BuildPercentileLatencies(l)
+		output.LatencyHistograms[u] = metrics.BuildLatencyHistogram(l, histogramBuckets)
+	}
+
+	if len(stats.WatchSetupLatenciesByURL) > 0 {
+		output.PercentileWatchSetupLatenciesByURL = map[string][][2]float64{}
+		for u, l := range stats.WatchSetupLatenciesByURL {
+			output.PercentileWatchSetupLatenciesByURL[u] = metrics.BuildPercentileLatencies(l)
+		}
+	}
+	if len(stats.WatchEventLagsByURL) > 0 {
+		output.PercentileWatchEventLagsByURL = map[string][][2]float64{}
+		for u, l := range stats.WatchEventLagsByURL {
+			output.PercentileWatchEventLagsByURL[u] = metrics.BuildPercentileLatencies(l)
+		}
+	}
+	if len(stats.TTFBByURL) > 0 {
+		output.PercentileTTFBByURL = map[string][][2]float64{}
+		for u, l := range stats.TTFBByURL {
+			output.PercentileTTFBByURL[u] = metrics.BuildPercentileLatencies(l)
+		}
+	}
+	if len(stats.BodyReadLatenciesByURL) > 0 {
+		output.PercentileBodyReadLatencyByURL = map[string][][2]float64{}
+		for u, l := range stats.BodyReadLatenciesByURL {
+			output.PercentileBodyReadLatencyByURL[u] = metrics.BuildPercentileLatencies(l)
+		}
+	}
+	if len(stats.LatenciesByConnection) > 0 {
+		output.PercentileLatenciesByConnection = map[string][][2]float64{}
+		for idx, l := range stats.LatenciesByConnection {
+			output.PercentileLatenciesByConnection[fmt.Sprintf("conn-%d", idx)] = metrics.BuildPercentileLatencies(l)
+		}
+	}
+	if len(stats.RequestsByWorker) > 0 {
+		output.WorkerStats = &types.WorkerStats{
+			Requests: stats.RequestsByWorker,
+			Skew:     metrics.BuildSkew(stats.RequestsByWorker),
+		}
+	}
+	if len(stats.RequestsByConnection) > 0 {
+		avgLatencies := make([]float64, len(stats.RequestsByConnection))
+		for idx, n := range stats.RequestsByConnection {
+			if succeeded := n - stats.FailuresByConnection[idx]; succeeded > 0 {
+				avgLatencies[idx] = stats.LatencySumByConnection[idx] / float64(succeeded)
+			}
+		}
+		output.ConnectionStats = &types.ConnectionStats{
+			Requests:         stats.RequestsByConnection,
+			Failures:         stats.FailuresByConnection,
+			AverageLatencies: avgLatencies,
+			Skew:             metrics.BuildSkew(stats.RequestsByConnection),
+		}
+	}
+	if len(stats.ResponseSizesByURL) > 0 {
+		output.PercentileResponseSizeByURL = map[string][][2]float64{}
+		for u, s := range stats.ResponseSizesByURL {
+			output.PercentileResponseSizeByURL[u] = metrics.BuildResponseSizeHistogram(s)
+		}
+	}
+	output.TotalWatchEvents = stats.TotalWatchEvents
+	output.TotalWatchBookmarks = stats.TotalWatchBookmarks
+	output.LogLinesByURL = stats.LogLinesByURL
+	output.LogTimeSpanByURL = stats.LogTimeSpanByURL
+
+	if includeRawData {
+		output.LatenciesByURL = stats.LatenciesByURL
+		output.WatchSetupLatenciesByURL = stats.WatchSetupLatenciesByURL
+		if len(stats.WatchEventLagsByURL) > 0 {
+			output.WatchEventLagsByURL = stats.WatchEventLagsByURL
+		}
+		if len(stats.TTFBByURL) > 0 {
+			output.TTFBByURL = stats.TTFBByURL
+		}
+		if len(stats.BodyReadLatenciesByURL) > 0 {
+			output.BodyReadLatenciesByURL = stats.BodyReadLatenciesByURL
+		}
+		output.Errors = stats.Errors
+	}
+	return &output
+}
@@ -0,0 +1,294 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+// Package runner runs benchmark against kube-apiserver programmatically. It's
+// the same code path as `kperf runner run`, so that kperf can be embedded in
+// other test harnesses.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/request"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+// defaultProgressInterval is the default interval to call Options.OnProgress.
+const defaultProgressInterval = time.Second
+
+// cleanupPostDelTimeout is the timeout to delete the objects created by
+// postDel requests after benchmark.
+const cleanupPostDelTimeout = 5 * time.Minute
+
+// Options is the options of Runner.Run.
+type Options struct {
+	// KubeconfigPath is the path to kubeconfig. It's ignored if RestConfig
+	// is set.
+	KubeconfigPath string
+	// RestConfig is used to create clients instead of kubeconfig, like the
+	// one from envtest. It isn't modified.
+	RestConfig *rest.Config
+	// ClientOpts applies to clients after the ones derived from load
+	// profile, like user agent and proxy.
+	ClientOpts []request.ClientCfgOpt
+	// ScheduleOpts applies to benchmark's Schedule, like concurrency limit
+	// and request interceptor. It doesn't apply to warmup.
+	ScheduleOpts []request.ScheduleOpt
+	// ClientRotationInterval is the interval to recreate clients during
+	// benchmark. Zero means disabled.
+	ClientRotationInterval time.Duration
+
+	// Warmup is the load profile spec to send before benchmark with the
+	// same clients, so that the connections are established. Its result is
+	// discarded. Nil means no warmup.
+	Warmup *types.LoadProfileSpec
+	// WarmupScheduleOpts applies to warmup's Schedule.
+	WarmupScheduleOpts []request.ScheduleOpt
+
+	// OnProgress is called with the status of benchmark on ProgressInterval
+	// and once more after benchmark finishes.
+	OnProgress func(types.RunnerStatus)
+	// ProgressInterval is the interval to call OnProgress. Default is one
+	// second.
+	ProgressInterval time.Duration
+
+	// CleanupPostDel deletes the resources created by postDel requests
+	// after benchmark.
+	CleanupPostDel bool
+	// RawData includes raw latencies and errors into report.
+	RawData bool
+
+	// Metadata is copied into report with StartTime, EndTime and Proxy
+	// filled. Nil means no metadata.
+	Metadata *types.RunMetadata
+	// Annotations is copied into report.
+	Annotations map[string]string
+	// ProfileChecksum is copied into report.
+	ProfileChecksum string
+}
+
+// Report is the outcome of Runner.Run.
+type Report struct {
+	// Metrics is the report as `kperf runner run` outputs in JSON.
+	Metrics *types.RunnerMetricReport
+	// Result is the raw result of Schedule.
+	Result *request.Result
+}
+
+// Runner runs one benchmark. The zero value is ready to use. It's safe to
+// query progress and control benchmark from other goroutines while Run is
+// in progress.
+type Runner struct {
+	progress request.Progress
+
+	mu      sync.Mutex
+	started bool
+	spec    *types.LoadProfileSpec
+	opts    Options
+	tracer  *request.TransportTracer
+}
+
+// Progress returns the progress of running benchmark. It also implements
+// request.RunnerController to stop, pause and update benchmark.
+func (r *Runner) Progress() *request.Progress {
+	return &r.progress
+}
+
+// Run sends the requests described by profile until it finishes or ctx is
+// canceled, and returns the report. It can be called only once.
+//
+// NOTE: If benchmark fails midway or the cleanup fails, the report is
+// returned along with the error.
+func (r *Runner) Run(ctx context.Context, profile types.LoadProfile, opts Options) (*Report, error) {
+	spec := profile.Spec
+	if spec.ModeConfig == nil {
+		return nil, fmt.Errorf("modeConfig is required")
+	}
+
+	// NOTE: The objects which can't be cleaned up fail before benchmark
+	// instead of being left behind.
+	if opts.CleanupPostDel {
+		if err := request.ValidatePostDelCleanup(&spec); err != nil {
+			return nil, err
+		}
+	}
+
+	tracer := &request.TransportTracer{}
+	r.mu.Lock()
+	if r.started {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("runner has been started")
+	}
+	r.started = true
+	r.spec, r.opts, r.tracer = &spec, opts, tracer
+	r.mu.Unlock()
+
+	clientOpts := append([]request.ClientCfgOpt{
+		request.WithClientOptionsOpt(spec.ModeConfig.ConfigureClientOptions()),
+		request.WithClientContentTypeOpt(spec.ContentType),
+		request.WithClientDisableHTTP2Opt(spec.DisableHTTP2),
+		request.WithClientConnectTimeoutOpt(spec.ConnectTimeoutSeconds.Duration()),
+		request.WithClientReadTimeoutOpt(spec.ReadTimeoutSeconds.Duration()),
+		request.WithClientTransportOpt(spec.Transport),
+		request.WithClientDNSCacheOpt(spec.DNSCacheTTLSeconds.Duration()),
+		request.WithClientDNSServersOpt(spec.DNSServers),
+		request.WithClientTransportTracerOpt(tracer),
+	}, opts.ClientOpts...)
+
+	newClients := func() ([]rest.Interface, error) {
+		if opts.RestConfig != nil {
+			return request.NewClientsForConfig(opts.RestConfig, spec.Conns, clientOpts...)
+		}
+		return request.NewClients(opts.KubeconfigPath, spec.Conns, clientOpts...)
+	}
+	restClis, err := newClients()
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: Warmup shares the clients with benchmark so that the
+	// connections are established before benchmark.
+	if opts.Warmup != nil {
+		if err := runWarmup(ctx, opts.Warmup, restClis, opts.WarmupScheduleOpts...); err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("failed to warmup: %w", err)
+		}
+	}
+
+	scheduleOpts := append([]request.ScheduleOpt{
+		request.WithScheduleProgressOpt(&r.progress),
+		request.WithScheduleClientRotationOpt(opts.ClientRotationInterval, newClients),
+	}, opts.ScheduleOpts...)
+
+	if opts.OnProgress != nil {
+		stop := r.reportProgress(opts.OnProgress, opts.ProgressInterval)
+		defer stop()
+	}
+
+	startTime := time.Now().UTC()
+	r.mu.Lock()
+	if r.opts.Metadata != nil {
+		metadata := *r.opts.Metadata
+		metadata.StartTime = startTime
+		r.opts.Metadata = &metadata
+	}
+	r.mu.Unlock()
+
+	stats, err := request.Schedule(ctx, &spec, restClis, scheduleOpts...)
+	if err != nil {
+		return nil, err
+	}
+	endTime := time.Now().UTC()
+
+	report := &Report{
+		Metrics: r.buildReport(stats, &endTime),
+		Result:  stats,
+	}
+
+	var errs []error
+	if stats.ExecutionError != nil {
+		errs = append(errs, fmt.Errorf("benchmark failed: %w", stats.ExecutionError))
+	}
+
+	// NOTE: The cleanup doesn't use ctx, which can be canceled to stop
+	// benchmark.
+	if opts.CleanupPostDel {
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), cleanupPostDelTimeout)
+		defer cancel()
+
+		if err := request.CleanupPostDelResources(cleanupCtx, restClis[0], &spec); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// PartialReport returns the report of running benchmark. It returns nil if
+// benchmark isn't started yet.
+func (r *Runner) PartialReport() *Report {
+	res := r.progress.Result()
+	if res == nil {
+		return nil
+	}
+	return &Report{
+		Metrics: r.buildReport(res, nil),
+		Result:  res,
+	}
+}
+
+// buildReport builds report from schedule's result with Run's options.
+// endTime is nil if benchmark is still running.
+func (r *Runner) buildReport(res *request.Result, endTime *time.Time) *types.RunnerMetricReport {
+	r.mu.Lock()
+	spec, opts, tracer := r.spec, r.opts, r.tracer
+	r.mu.Unlock()
+
+	report := BuildMetricReport(opts.RawData, spec.HistogramBuckets, res)
+	if opts.Metadata != nil {
+		metadata := *opts.Metadata
+		metadata.EndTime = endTime
+		metadata.Proxy = tracer.Proxy()
+		report.Metadata = &metadata
+	}
+	report.Annotations = opts.Annotations
+	report.ProfileChecksum = opts.ProfileChecksum
+	report.TransportStats = tracer.Stats()
+	return report
+}
+
+// reportProgress calls fn with benchmark's status on interval until the
+// returned function is called, which calls fn with the last status.
+func (r *Runner) reportProgress(fn func(types.RunnerStatus), interval time.Duration) func() {
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				fn(r.progress.Status())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+		fn(r.progress.Status())
+	}
+}
+
+// runWarmup sends warmup requests by the given clients and discards the
+// result.
+func runWarmup(ctx context.Context, spec *types.LoadProfileSpec, restClis []rest.Interface, opts ...request.ScheduleOpt) error {
+	res, err := request.Schedule(ctx, spec, restClis, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to warmup: %w", err)
+	}
+	if res.ExecutionError != nil {
+		return fmt.Errorf("failed to warmup: %w", res.ExecutionError)
+	}
+	klog.InfoS("Warmup complete, starting main benchmark",
+		"duration", res.Duration, "errors", res.ErrorCount())
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestRunnerRun(t *testing.T) {
+	srv := newFakeServer(t, 0)
+	restCfg := &rest.Config{Host: srv.URL}
+
+	var mu sync.Mutex
+	var statuses []types.RunnerStatus
+
+	r := &Runner{}
+	assert.Nil(t, r.PartialReport())
+
+	report, err := r.Run(context.TODO(), newTestProfile(20, 0), Options{
+		RestConfig: restCfg,
+		OnProgress: func(status types.RunnerStatus) {
+			mu.Lock()
+			defer mu.Unlock()
+			statuses = append(statuses, status)
+		},
+		Metadata:        &types.RunMetadata{RunID: "test"},
+		Annotations:     map[string]string{"k": "v"},
+		ProfileChecksum: "checksum",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 20, report.Result.Total)
+	assert.Equal(t, 20, report.Metrics.Total)
+	assert.Equal(t, 0, report.Metrics.ErrorCount)
+	assert.Equal(t, "test", report.Metrics.Metadata.RunID)
+	assert.False(t, report.Metrics.Metadata.StartTime.IsZero())
+	require.NotNil(t, report.Metrics.Metadata.EndTime)
+	assert.Equal(t, map[string]string{"k": "v"}, report.Metrics.Annotations)
+	assert.Equal(t, "checksum", report.Metrics.ProfileChecksum)
+
+	// The last status is reported after benchmark finishes.
+	mu.Lock()
+	require.NotEmpty(t, statuses)
+	assert.Equal(t, types.RunnerStateFinished, statuses[len(statuses)-1].State)
+	assert.Equal(t, int64(20), statuses[len(statuses)-1].Completed)
+	mu.Unlock()
+
+	partial := r.PartialReport()
+	require.NotNil(t, partial)
+	assert.Equal(t, 20, partial.Metrics.Total)
+	assert.Nil(t, partial.Metrics.Metadata.EndTime)
+
+	// The given rest.Config isn't modified.
+	assert.Nil(t, restCfg.NegotiatedSerializer)
+	assert.Empty(t, restCfg.UserAgent)
+
+	_, err = r.Run(context.TODO(), newTestProfile(20, 0), Options{RestConfig: restCfg})
+	assert.Error(t, err)
+}
+
+func TestRunnerRunCanceled(t *testing.T) {
+	srv := newFakeServer(t, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	report, err := (&Runner{}).Run(ctx, newTestProfile(0, 10), Options{
+		RestConfig: &rest.Config{Host: srv.URL},
+	})
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.True(t, report.Metrics.TerminatedEarly)
+	assert.Greater(t, report.Metrics.Total, 0)
+}
+
+func TestRunnerRunCleanupPostDel(t *testing.T) {
+	for name, tc := range map[string]struct {
+		failList    bool
+		expectedErr string
+	}{
+		"canceled": {},
+		"cleanup failed": {
+			failList:    true,
+			expectedErr: "failed to cleanup pods created by postDel",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var listed int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == http.MethodGet && r.URL.Path == "/api/v1/namespaces/default/pods" {
+					atomic.AddInt32(&listed, 1)
+					if tc.failList {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[]}`)
+					return
+				}
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Success"}`)
+			}))
+			t.Cleanup(srv.Close)
+
+			profile := newTestProfile(0, 10)
+			profile.Spec.ModeConfig.(*types.WeightedRandomConfig).Requests = []*types.WeightedRequest{
+				{
+					Shares: 1,
+					PostDel: &types.RequestPostDel{
+						KubeGroupVersionResource: types.KubeGroupVersionResource{
+							Version:  "v1",
+							Resource: "pods",
+						},
+						Namespace:    "default",
+						NameTemplate: "kperf-{{.Index}}",
+					},
+				},
+			}
+
+			// NOTE: The cleanup still runs after ctx is canceled.
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			report, err := (&Runner{}).Run(ctx, profile, Options{
+				RestConfig:     &rest.Config{Host: srv.URL},
+				CleanupPostDel: true,
+			})
+			require.NotNil(t, report)
+			assert.True(t, report.Metrics.TerminatedEarly)
+			assert.Greater(t, atomic.LoadInt32(&listed), int32(0))
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// newFakeServer returns apiserver which lists empty pods after delay.
+func newFakeServer(t *testing.T, delay time.Duration) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","items":[]}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestProfile returns weighted-random profile listing pods.
+func newTestProfile(total int, duration types.Seconds) types.LoadProfile {
+	return types.LoadProfile{
+		Version: 1,
+		Spec: types.LoadProfileSpec{
+			Conns:       1,
+			Client:      2,
+			ContentType: types.ContentTypeJSON,
+			Mode:        types.ModeWeightedRandom,
+			ModeConfig: &types.WeightedRandomConfig{
+				Total:    total,
+				Duration: duration,
+				Requests: []*types.WeightedRequest{
+					{
+						Shares: 1,
+						StaleList: &types.RequestList{
+							KubeGroupVersionResource: types.KubeGroupVersionResource{
+								Version:  "v1",
+								Resource: "pods",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+// Package constantconcurrency is a reference implementation of executor
+// plugin. It keeps N requests always in-flight.
+package constantconcurrency
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/request/executor"
+
+	"k8s.io/client-go/rest"
+)
+
+// Mode is the execution mode provided by this plugin.
+const Mode types.ExecutionMode = "constant-concurrency"
+
+// Config defines configuration for constant-concurrency mode.
+type Config struct {
+	types.PluginModeConfig `json:"-" yaml:"-"`
+
+	// Concurrency defines the number of in-flight requests.
+	Concurrency int `json:"concurrency" yaml:"concurrency"`
+	// Total defines the total number of requests (zero is no limit).
+	Total int `json:"total" yaml:"total"`
+	// Duration defines the running time in seconds (zero is no limit).
+	Duration int `json:"duration" yaml:"duration"`
+	// Requests defines the different kinds of requests with weights.
+	Requests []*types.WeightedRequest `json:"requests" yaml:"requests"`
+}
+
+// GetOverridableFields implements types.ModeConfig.
+func (c *Config) GetOverridableFields() []types.OverridableField {
+	return []types.OverridableField{
+		{
+			Name:        "concurrency",
+			Type:        types.FieldTypeInt,
+			Description: "Number of in-flight requests",
+		},
+	}
+}
+
+// ApplyOverrides implements types.ModeConfig.
+func (c *Config) ApplyOverrides(overrides map[string]interface{}) error {
+	for key, value := range overrides {
+		switch key {
+		case "concurrency":
+			v, ok := value.(int)
+			if !ok {
+				return fmt.Errorf("concurrency must be int, got %T", value)
+			}
+			c.Concurrency = v
+		default:
+			return fmt.Errorf("unknown override key for %s mode: %s", Mode, key)
+		}
+	}
+	return nil
+}
+
+// Validate implements types.ModeConfig.
+func (c *Config) Validate(_ map[string]interface{}) error {
+	if c.Concurrency <= 0 {
+		return fmt.Errorf("concurrency requires > 0: %v", c.Concurrency)
+	}
+	if c.Total < 0 {
+		return fmt.Errorf("total requires >= 0: %v", c.Total)
+	}
+	if c.Duration < 0 {
+		return fmt.Errorf("duration requires >= 0: %v", c.Duration)
+	}
+	if len(c.Requests) == 0 {
+		return fmt.Errorf("requests is required")
+	}
+	for idx, r := range c.Requests {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("idx: %v request: %v", idx, err)
+		}
+	}
+	return nil
+}
+
+// ConfigureClientOptions implements types.ModeConfig.
+func (c *Config) ConfigureClientOptions() types.ClientOptions {
+	// Concurrency limits the traffic instead of QPS.
+	return types.ClientOptions{QPS: 0}
+}
+
+// Plugin implements executor.Plugin for constant-concurrency mode.
+type Plugin struct{}
+
+// Mode implements executor.Plugin.
+func (Plugin) Mode() types.ExecutionMode {
+	return Mode
+}
+
+// Constructor implements executor.Plugin.
+func (Plugin) Constructor() executor.ExecutorConstructor {
+	return NewExecutor
+}
+
+// NewModeConfig implements executor.ModeConfigPlugin.
+func (Plugin) NewModeConfig() types.ModeConfig {
+	return &Config{}
+}
+
+// Executor implements executor.Executor for constant-concurrency mode.
+type Executor struct {
+	config       *Config
+	reqBuilderCh chan executor.RESTRequestBuilder
+	tokens       chan struct{}
+	shares       []int
+	reqBuilders  []executor.RESTRequestBuilder
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	once         sync.Once
+}
+
+// NewExecutor creates a new constant-concurrency executor from spec.
+func NewExecutor(spec *types.LoadProfileSpec) (executor.Executor, error) {
+	if spec.Mode != Mode {
+		return nil, fmt.Errorf("expected mode %s, got %s", Mode, spec.Mode)
+	}
+
+	config, ok := spec.ModeConfig.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for %s mode", Mode)
+	}
+
+	if err := config.Validate(nil); err != nil {
+		return nil, err
+	}
+
+	shares := make([]int, 0, len(config.Requests))
+	reqBuilders := make([]executor.RESTRequestBuilder, 0, len(config.Requests))
+	for _, r := range config.Requests {
+		builder, err := executor.NewRequestBuilder(r.WithDefaultObjectMeta(spec.ObjectMeta), spec.MaxRetries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request builder: %v", err)
+		}
+		shares = append(shares, r.Shares)
+		reqBuilders = append(reqBuilders, builder)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Executor{
+		config:       config,
+		reqBuilderCh: make(chan executor.RESTRequestBuilder),
+		tokens:       make(chan struct{}, config.Concurrency),
+		shares:       shares,
+		reqBuilders:  reqBuilders,
+		ctx:          ctx,
+		cancel:       cancel,
+	}, nil
+}
+
+// Chan implements executor.Executor.
+func (e *Executor) Chan() <-chan executor.RESTRequestBuilder {
+	return e.reqBuilderCh
+}
+
+// Run implements executor.Executor. It dispatches new request only if the
+// number of in-flight requests is less than concurrency.
+func (e *Executor) Run(ctx context.Context) error {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	for sum := 0; e.config.Total == 0 || sum < e.config.Total; sum++ {
+		// Acquire one token which is released after request is done.
+		select {
+		case e.tokens <- struct{}{}:
+		case <-e.ctx.Done():
+			return e.ctx.Err()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		builder := &releaseBuilder{
+			RESTRequestBuilder: e.randomPick(),
+			release:            func() { <-e.tokens },
+		}
+		select {
+		case e.reqBuilderCh <- builder:
+		case <-e.ctx.Done():
+			return e.ctx.Err()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Stop implements executor.Executor.
+func (e *Executor) Stop() {
+	e.once.Do(func() {
+		e.cancel()
+		e.wg.Wait()
+		close(e.reqBuilderCh)
+	})
+}
+
+// Metadata implements executor.Executor.
+func (e *Executor) Metadata() executor.ExecutorMetadata {
+	return executor.ExecutorMetadata{
+		ExpectedTotal:    e.config.Total,
+		ExpectedDuration: time.Duration(e.config.Duration) * time.Second,
+		Custom: map[string]interface{}{
+			"mode":          string(Mode),
+			"concurrency":   e.config.Concurrency,
+			"request_types": len(e.config.Requests),
+		},
+	}
+}
+
+// GetRateLimiter implements executor.Executor. It returns nil because the
+// concurrency limits the traffic.
+func (e *Executor) GetRateLimiter() executor.RateLimiter {
+	return nil
+}
+
+// GetExecutionContext implements executor.Executor.
+func (e *Executor) GetExecutionContext(baseCtx context.Context) (context.Context, context.CancelFunc) {
+	if e.config.Duration > 0 {
+		return context.WithTimeout(baseCtx, time.Duration(e.config.Duration)*time.Second)
+	}
+	return context.WithCancel(baseCtx)
+}
+
+// randomPick randomly selects a request builder based on weights.
+func (e *Executor) randomPick() executor.RESTRequestBuilder {
+	sum := 0
+	for _, s := range e.shares {
+		sum += s
+	}
+
+	rndInt, err := rand.Int(rand.Reader, big.NewInt(int64(sum)))
+	if err != nil {
+		panic(err)
+	}
+
+	rnd := rndInt.Int64()
+	for i := range e.shares {
+		s := int64(e.shares[i])
+		if rnd < s {
+			return e.reqBuilders[i]
+		}
+		rnd -= s
+	}
+	panic("unreachable")
+}
+
+// releaseBuilder builds requester which calls release after it's done.
+type releaseBuilder struct {
+	executor.RESTRequestBuilder
+	release func()
+}
+
+// Build implements executor.RESTRequestBuilder.
+func (b *releaseBuilder) Build(cli rest.Interface) executor.Requester {
+	return &releaseRequester{
+		Requester: b.RESTRequestBuilder.Build(cli),
+		release:   b.release,
+	}
+}
+
+// releaseRequester calls release once Do is done.
+type releaseRequester struct {
+	executor.Requester
+	release func()
+	once    sync.Once
+}
+
+// Do implements executor.Requester.
+func (r *releaseRequester) Do(ctx context.Context) (int64, error) {
+	defer r.once.Do(r.release)
+	return r.Requester.Do(ctx)
+}
+
+// Unwrap implements executor.WrappedRequester.
+func (r *releaseRequester) Unwrap() executor.Requester {
+	return r.Requester
+}
+
+// Rewrap implements executor.WrappedRequester.
+func (r *releaseRequester) Rewrap(req executor.Requester) {
+	r.Requester = req
+}
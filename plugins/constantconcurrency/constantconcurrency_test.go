@@ -0,0 +1,110 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package constantconcurrency
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/request/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+type fakeRequester struct {
+	inflight    *int64
+	maxInflight *int64
+}
+
+func (r *fakeRequester) Method() string          { return "GET" }
+func (r *fakeRequester) URL() *url.URL           { return &url.URL{Path: "/"} }
+func (r *fakeRequester) MaskedURL() *url.URL     { return r.URL() }
+func (r *fakeRequester) Timeout(_ time.Duration) {}
+
+func (r *fakeRequester) Do(_ context.Context) (int64, error) {
+	n := atomic.AddInt64(r.inflight, 1)
+	defer atomic.AddInt64(r.inflight, -1)
+
+	for {
+		max := atomic.LoadInt64(r.maxInflight)
+		if n <= max || atomic.CompareAndSwapInt64(r.maxInflight, max, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return 0, nil
+}
+
+type fakeBuilder struct {
+	inflight    int64
+	maxInflight int64
+}
+
+func (b *fakeBuilder) Build(_ rest.Interface) executor.Requester {
+	return &fakeRequester{inflight: &b.inflight, maxInflight: &b.maxInflight}
+}
+
+func TestRegisterPlugin(t *testing.T) {
+	builder := &fakeBuilder{}
+	executor.SetRequestBuilderFactory(func(*types.WeightedRequest, int) (executor.RESTRequestBuilder, error) {
+		return builder, nil
+	})
+
+	factory := executor.NewExecutorFactory()
+	factory.RegisterAll([]executor.Plugin{Plugin{}})
+	assert.Contains(t, factory.AvailableModes(), string(Mode))
+
+	spec := &types.LoadProfileSpec{
+		Mode: Mode,
+		ModeConfig: &Config{
+			Concurrency: 3,
+			Total:       30,
+			Requests: []*types.WeightedRequest{
+				{
+					Shares: 1,
+					StaleGet: &types.RequestGet{
+						KubeGroupVersionResource: types.KubeGroupVersionResource{
+							Version:  "v1",
+							Resource: "pods",
+						},
+						Namespace: "default",
+						Name:      "x",
+					},
+				},
+			},
+		},
+	}
+
+	exec, err := factory.Create(spec)
+	require.NoError(t, err)
+	assert.Equal(t, 30, exec.Metadata().ExpectedTotal)
+
+	total := int64(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range exec.Chan() {
+				req := b.Build(nil)
+				_, _ = req.Do(context.TODO())
+				atomic.AddInt64(&total, 1)
+			}
+		}()
+	}
+
+	require.NoError(t, exec.Run(context.TODO()))
+	exec.Stop()
+	wg.Wait()
+
+	assert.Equal(t, int64(30), total)
+	assert.Equal(t, int64(3), builder.maxInflight)
+}
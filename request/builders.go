@@ -18,25 +18,70 @@ func init() {
 
 // CreateRequestBuilder creates a RESTRequestBuilder from a WeightedRequest.
 // This function is used by weighted-random mode executors.
+//
+// The request's MaxRetries, if it's set, takes precedence over maxRetries.
 func CreateRequestBuilder(r *types.WeightedRequest, maxRetries int) (executor.RESTRequestBuilder, error) {
+	if r.MaxRetries != nil {
+		maxRetries = *r.MaxRetries
+	}
+
+	r, err := r.WithoutDeprecated()
+	if err != nil {
+		return nil, err
+	}
+
 	var builder executor.RESTRequestBuilder
 	switch {
-	case r.StaleList != nil:
-		builder = newRequestListBuilder(r.StaleList, "0", maxRetries)
-	case r.QuorumList != nil:
-		builder = newRequestListBuilder(r.QuorumList, "", maxRetries)
+	case r.List != nil:
+		listBuilder, err := newRequestListBuilder(r.List,
+			r.List.Consistency.ReadResourceVersion(r.List.ResourceVersion), maxRetries)
+		if err != nil {
+			return nil, err
+		}
+		builder = listBuilder
+	case r.Get != nil:
+		getBuilder, err := newRequestGetBuilder(r.Get,
+			r.Get.Consistency.ReadResourceVersion(""), maxRetries)
+		if err != nil {
+			return nil, err
+		}
+		builder = getBuilder
 	case r.WatchList != nil:
-		builder = newRequestWatchListBuilder(r.WatchList, maxRetries)
-	case r.StaleGet != nil:
-		builder = newRequestGetBuilder(r.StaleGet, "0", maxRetries)
-	case r.QuorumGet != nil:
-		builder = newRequestGetBuilder(r.QuorumGet, "", maxRetries)
+		wlBuilder, err := newRequestWatchListBuilder(r.WatchList, maxRetries)
+		if err != nil {
+			return nil, err
+		}
+		builder = wlBuilder
+	case r.WatchChurn != nil:
+		wcBuilder, err := newRequestWatchChurnBuilder(r.WatchChurn, maxRetries)
+		if err != nil {
+			return nil, err
+		}
+		builder = wcBuilder
 	case r.GetPodLog != nil:
-		builder = newRequestGetPodLogBuilder(r.GetPodLog, maxRetries)
+		logBuilder, err := newRequestGetPodLogBuilder(r.GetPodLog, maxRetries)
+		if err != nil {
+			return nil, err
+		}
+		builder = logBuilder
+	case r.Put != nil:
+		putBuilder, err := newRequestPutBuilder(r.Put, maxRetries)
+		if err != nil {
+			return nil, err
+		}
+		builder = putBuilder
 	case r.Patch != nil:
-		builder = newRequestPatchBuilder(r.Patch, "", maxRetries)
+		patchBuilder, err := newRequestPatchBuilder(r.Patch, "", maxRetries)
+		if err != nil {
+			return nil, err
+		}
+		builder = patchBuilder
 	case r.PostDel != nil:
-		builder = newRequestPostDelBuilder(r.PostDel, "", maxRetries)
+		pdBuilder, err := newRequestPostDelBuilder(r.PostDel, "", maxRetries)
+		if err != nil {
+			return nil, err
+		}
+		builder = pdBuilder
 	default:
 		return nil, fmt.Errorf("unsupported request type")
 	}
@@ -45,7 +90,13 @@ func CreateRequestBuilder(r *types.WeightedRequest, maxRetries int) (executor.RE
 
 // CreateRequestBuilderFromExact creates a RESTRequestBuilder from an ExactRequest.
 // This function is used by time-series and other exact-replay mode executors.
+//
+// The request's MaxRetries, if it's set, takes precedence over maxRetries.
 func CreateRequestBuilderFromExact(req *types.ExactRequest, maxRetries int) (executor.RESTRequestBuilder, error) {
+	if req.MaxRetries != nil {
+		maxRetries = *req.MaxRetries
+	}
+
 	resourceVersion := req.ResourceVersion
 
 	switch req.Method {
@@ -58,7 +109,7 @@ func CreateRequestBuilderFromExact(req *types.ExactRequest, maxRetries int) (exe
 			},
 			Namespace: req.Namespace,
 			Name:      req.Name,
-		}, resourceVersion, maxRetries), nil
+		}, resourceVersion, maxRetries)
 
 	case "LIST":
 		return newRequestListBuilder(&types.RequestList{
@@ -71,7 +122,7 @@ func CreateRequestBuilderFromExact(req *types.ExactRequest, maxRetries int) (exe
 			Limit:         req.Limit,
 			Selector:      req.LabelSelector,
 			FieldSelector: req.FieldSelector,
-		}, resourceVersion, maxRetries), nil
+		}, resourceVersion, maxRetries)
 
 	case "PATCH":
 		patchType, ok := types.GetPatchType(req.PatchType)
@@ -88,7 +139,7 @@ func CreateRequestBuilderFromExact(req *types.ExactRequest, maxRetries int) (exe
 			Name:      req.Name,
 			Body:      req.Body,
 			PatchType: string(patchType),
-		}, resourceVersion, maxRetries), nil
+		}, resourceVersion, maxRetries)
 
 	case "POST":
 		return newRequestPostDelBuilder(&types.RequestPostDel{
@@ -98,7 +149,7 @@ func CreateRequestBuilderFromExact(req *types.ExactRequest, maxRetries int) (exe
 				Resource: req.Resource,
 			},
 			Namespace: req.Namespace,
-		}, resourceVersion, maxRetries), nil
+		}, resourceVersion, maxRetries)
 
 	case "DELETE":
 		return newRequestPostDelBuilder(&types.RequestPostDel{
@@ -109,7 +160,7 @@ func CreateRequestBuilderFromExact(req *types.ExactRequest, maxRetries int) (exe
 			},
 			Namespace:   req.Namespace,
 			DeleteRatio: 1.0,
-		}, resourceVersion, maxRetries), nil
+		}, resourceVersion, maxRetries)
 
 	default:
 		return nil, fmt.Errorf("unsupported method: %s", req.Method)
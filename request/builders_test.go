@@ -0,0 +1,203 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateRequestBuilderMaxRetries(t *testing.T) {
+	zero, three := 0, 3
+	gvr := types.KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+
+	for name, tc := range map[string]struct {
+		maxRetries *int
+		expected   int
+	}{
+		"spec's":          {maxRetries: nil, expected: 5},
+		"override":        {maxRetries: &three, expected: 3},
+		"disable retries": {maxRetries: &zero, expected: 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			builder, err := CreateRequestBuilder(&types.WeightedRequest{
+				Shares:     1,
+				StaleGet:   &types.RequestGet{KubeGroupVersionResource: gvr, Name: "a"},
+				MaxRetries: tc.maxRetries,
+			}, 5)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, builder.(*requestGetBuilder).maxRetries)
+
+			builder, err = CreateRequestBuilderFromExact(&types.ExactRequest{
+				Method:     "LIST",
+				Version:    "v1",
+				Resource:   "pods",
+				MaxRetries: tc.maxRetries,
+			}, 5)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, builder.(*requestListBuilder).maxRetries)
+		})
+	}
+}
+
+func TestCreateRequestBuilderConsistency(t *testing.T) {
+	gvr := types.KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+
+	clis, err := NewClients(newTestKubeconfig(t, "http://127.0.0.1:1"), 1)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		req      *types.WeightedRequest
+		expected string
+	}{
+		"deprecated staleList": {
+			req:      &types.WeightedRequest{StaleList: &types.RequestList{KubeGroupVersionResource: gvr}},
+			expected: "resourceVersion=0",
+		},
+		"stale list": {
+			req:      &types.WeightedRequest{List: &types.RequestList{KubeGroupVersionResource: gvr, Consistency: types.ConsistencyStale}},
+			expected: "resourceVersion=0",
+		},
+		"quorum list": {
+			req:      &types.WeightedRequest{List: &types.RequestList{KubeGroupVersionResource: gvr, Limit: 10}},
+			expected: "limit=10",
+		},
+		"exact list": {
+			req: &types.WeightedRequest{List: &types.RequestList{
+				KubeGroupVersionResource: gvr,
+				Consistency:              types.ConsistencyExact,
+				ResourceVersion:          "42",
+			}},
+			expected: "resourceVersion=42&resourceVersionMatch=Exact",
+		},
+		"deprecated staleGet": {
+			req:      &types.WeightedRequest{StaleGet: &types.RequestGet{KubeGroupVersionResource: gvr, Name: "a"}},
+			expected: "resourceVersion=0",
+		},
+		"quorum get": {
+			req:      &types.WeightedRequest{Get: &types.RequestGet{KubeGroupVersionResource: gvr, Name: "a", Consistency: types.ConsistencyQuorum}},
+			expected: "",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			builder, err := CreateRequestBuilder(tc.req, 0)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, builder.Build(clis[0]).URL().RawQuery)
+		})
+	}
+}
+
+func TestBuilderURL(t *testing.T) {
+	clis, err := NewClients(newTestKubeconfig(t, "http://127.0.0.1:1"), 1)
+	require.NoError(t, err)
+
+	pods := types.KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+	deploys := types.KubeGroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	limitBytes := int64(1024)
+
+	for name, tc := range map[string]struct {
+		req       *types.WeightedRequest
+		url       string
+		maskedURL string
+	}{
+		"namespaced get": {
+			req: &types.WeightedRequest{Get: &types.RequestGet{KubeGroupVersionResource: pods, Namespace: "default", Name: "a", Consistency: types.ConsistencyStale}},
+			url: "http://127.0.0.1:1/api/v1/namespaces/default/pods/a?resourceVersion=0",
+		},
+		"group get": {
+			req: &types.WeightedRequest{Get: &types.RequestGet{KubeGroupVersionResource: deploys, Name: "a"}},
+			url: "http://127.0.0.1:1/apis/apps/v1/deployments/a",
+		},
+		"list": {
+			req: &types.WeightedRequest{List: &types.RequestList{KubeGroupVersionResource: pods, Namespace: "default", Limit: 10, Selector: "app=x"}},
+			url: "http://127.0.0.1:1/api/v1/namespaces/default/pods?labelSelector=app%3Dx&limit=10",
+		},
+		"paginated list": {
+			req: &types.WeightedRequest{List: &types.RequestList{KubeGroupVersionResource: deploys, Limit: 10, Paginate: true}},
+			url: "http://127.0.0.1:1/apis/apps/v1/deployments?limit=10",
+		},
+		"watch list": {
+			req: &types.WeightedRequest{WatchList: &types.RequestWatchList{KubeGroupVersionResource: pods, FieldSelector: "spec.nodeName=n"}},
+			url: "http://127.0.0.1:1/api/v1/pods?allowWatchBookmarks=true&fieldSelector=spec.nodeName%3Dn&resourceVersionMatch=NotOlderThan&sendInitialEvents=true&watch=true",
+		},
+		"watch churn": {
+			req: &types.WeightedRequest{WatchChurn: &types.RequestWatchChurn{KubeGroupVersionResource: pods, Namespace: "default", HoldTime: "10s"}},
+			url: "http://127.0.0.1:1/api/v1/namespaces/default/pods?allowWatchBookmarks=true&timeoutSeconds=40&watch=true",
+		},
+		"pod log": {
+			req: &types.WeightedRequest{GetPodLog: &types.RequestGetPodLog{Namespace: "default", Name: "a", Container: "c", LimitBytes: &limitBytes}},
+			url: "http://127.0.0.1:1/api/v1/namespaces/default/pods/a/log?container=c&limitBytes=1024",
+		},
+		"patch": {
+			req: &types.WeightedRequest{Patch: &types.RequestPatch{
+				KubeGroupVersionResource: pods, Namespace: "default", Name: "a",
+				KeySpaceSize: 1, PatchType: "merge", Body: `{}`,
+			}},
+			url:       "http://127.0.0.1:1/api/v1/namespaces/default/pods/a-0",
+			maskedURL: "http://127.0.0.1:1/api/v1/namespaces/default/pods/:name",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			builder, err := CreateRequestBuilder(tc.req, 0)
+			require.NoError(t, err)
+
+			req := builder.Build(clis[0])
+			assert.Equal(t, tc.url, req.URL().String())
+			if tc.maskedURL == "" {
+				tc.maskedURL = tc.url
+			}
+			assert.Equal(t, tc.maskedURL, req.MaskedURL().String())
+			assert.Equal(t, tc.maskedURL, req.MaskedURL().String(), "masked URL is stable")
+
+			// The builder is reused for the next request.
+			assert.Equal(t, tc.url, builder.Build(clis[0]).URL().String())
+		})
+	}
+}
+
+func BenchmarkRequestGetBuilder(b *testing.B) {
+	clis, err := NewClients(newTestKubeconfig(b, "http://127.0.0.1:1"), 1)
+	require.NoError(b, err)
+
+	builder, err := newRequestGetBuilder(&types.RequestGet{
+		KubeGroupVersionResource: types.KubeGroupVersionResource{Version: "v1", Resource: "pods"},
+		Namespace:                "default",
+		Name:                     "a",
+	}, "0", 0)
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := builder.Build(clis[0])
+		_ = req.MaskedURL()
+	}
+}
+
+func BenchmarkRequestPatchBuilder(b *testing.B) {
+	clis, err := NewClients(newTestKubeconfig(b, "http://127.0.0.1:1"), 1)
+	require.NoError(b, err)
+
+	builder, err := newRequestPatchBuilder(&types.RequestPatch{
+		KubeGroupVersionResource: types.KubeGroupVersionResource{Version: "v1", Resource: "configmaps"},
+		Namespace:                "default",
+		Name:                     "a",
+		KeySpaceSize:             100,
+		PatchType:                "merge",
+		Body:                     `{"data":{"k":"v"}}`,
+	}, "", 0)
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := builder.Build(clis[0])
+		_ = req.MaskedURL()
+		_ = req.MaskedURL()
+	}
+}
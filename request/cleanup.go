@@ -0,0 +1,193 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+// cleanupListPageSize is the number of objects listed in one page while
+// cleaning up.
+const cleanupListPageSize = 500
+
+// ValidatePostDelCleanup returns error if the objects created by any postDel
+// request in spec can't be selected by CleanupPostDelResources, like the
+// nameTemplate formatting index in hex. It's used to fail before benchmark
+// instead of leaving the objects behind after benchmark.
+func ValidatePostDelCleanup(spec *types.LoadProfileSpec) error {
+	config, ok := spec.ModeConfig.(*types.WeightedRandomConfig)
+	if !ok {
+		return nil
+	}
+
+	for _, r := range config.Requests {
+		if r.PostDel == nil {
+			continue
+		}
+		if _, err := postDelNamePattern(r.PostDel); err != nil {
+			return fmt.Errorf("failed to cleanup %s created by postDel: %w", r.PostDel.Resource, err)
+		}
+	}
+	return nil
+}
+
+// CleanupPostDelResources deletes all the objects created by postDel
+// requests defined in spec. The objects are selected by the run ID label
+// in spec's ObjectMeta, if any, and names matching the NameTemplate.
+func CleanupPostDelResources(ctx context.Context, cli rest.Interface, spec *types.LoadProfileSpec) error {
+	config, ok := spec.ModeConfig.(*types.WeightedRandomConfig)
+	if !ok {
+		return nil
+	}
+
+	var labelSelector string
+	if spec.ObjectMeta != nil && spec.ObjectMeta.Labels[types.LabelRunID] != "" {
+		labelSelector = types.LabelRunID + "=" + spec.ObjectMeta.Labels[types.LabelRunID]
+	}
+
+	for _, r := range config.Requests {
+		if r.PostDel == nil || (r.PostDel.NameTemplate == "" && labelSelector == "") {
+			continue
+		}
+
+		if err := cleanupPostDelResources(ctx, cli, r.PostDel, labelSelector); err != nil {
+			return fmt.Errorf("failed to cleanup %s created by postDel: %w", r.PostDel.Resource, err)
+		}
+	}
+	return nil
+}
+
+func cleanupPostDelResources(ctx context.Context, cli rest.Interface, src *types.RequestPostDel, labelSelector string) error {
+	pattern, err := postDelNamePattern(src)
+	if err != nil {
+		return err
+	}
+
+	// https://kubernetes.io/docs/reference/using-api/#api-groups
+	comps := make([]string, 0, 5)
+	if src.Group == "" {
+		comps = append(comps, "api", src.Version)
+	} else {
+		comps = append(comps, "apis", src.Group, src.Version)
+	}
+	if src.Namespace != "" {
+		comps = append(comps, "namespaces", src.Namespace)
+	}
+	comps = append(comps, src.Resource)
+
+	deleted := 0
+	opts := metav1.ListOptions{LabelSelector: labelSelector, Limit: cleanupListPageSize}
+	for {
+		raw, err := cli.Get().AbsPath(comps...).
+			SpecificallyVersionedParams(
+				&opts,
+				scheme.ParameterCodec,
+				schema.GroupVersion{Version: "v1"},
+			).
+			SetHeader("Accept", "application/json").
+			Do(ctx).Raw()
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", src.Resource, err)
+		}
+
+		var objs metav1.PartialObjectMetadataList
+		if err := json.Unmarshal(raw, &objs); err != nil {
+			return fmt.Errorf("failed to decode list of %s: %w", src.Resource, err)
+		}
+
+		for _, obj := range objs.Items {
+			if pattern != nil && !pattern.MatchString(obj.Name) {
+				continue
+			}
+
+			err := cli.Delete().AbsPath(append(comps, obj.Name)...).Do(ctx).Error()
+			if err != nil {
+				return fmt.Errorf("failed to delete %s %s: %w", src.Resource, obj.Name, err)
+			}
+			deleted++
+		}
+
+		// NOTE: The next pages are listed from the same snapshot as the
+		// first one, so deleting objects doesn't skip any.
+		if objs.Continue == "" {
+			break
+		}
+		opts.Continue = objs.Continue
+	}
+	klog.V(2).InfoS("Cleanup postDel resources", "resource", src.Resource,
+		"namespace", src.Namespace, "nameTemplate", src.NameTemplate, "labelSelector", labelSelector,
+		"deleted", deleted)
+	return nil
+}
+
+// The sentinels to render NameTemplate, whose digits are replaced by \d+ in
+// the pattern. They're long enough not to collide with other parts of name.
+const (
+	postDelIndexSentinel     int64 = 918273645546372819
+	postDelTimestampSentinel int64 = 192837465564738291
+)
+
+// postDelNamePattern converts NameTemplate into regular expression which
+// matches all the names rendered by that template. It returns nil if
+// NameTemplate is empty.
+//
+// NOTE: The template is rendered with sentinel numbers so that it's executed
+// with the same types as creation, like {{printf "%05d" .Index}}. If index or
+// timestamp isn't rendered in decimal, like in hex, the names can't be
+// matched and it returns error.
+func postDelNamePattern(src *types.RequestPostDel) (*regexp.Regexp, error) {
+	tmpl, err := src.ParseNameTemplate()
+	if err != nil || tmpl == nil {
+		return nil, err
+	}
+
+	name, err := types.RenderPostDelName(tmpl, types.PostDelNameTemplateData{
+		Index:     postDelIndexSentinel,
+		Namespace: src.Namespace,
+		Resource:  src.Resource,
+		Timestamp: postDelTimestampSentinel,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build name pattern from nameTemplate %q: %w", src.NameTemplate, err)
+	}
+
+	expr := regexp.QuoteMeta(name)
+	expr = strings.ReplaceAll(expr, strconv.FormatInt(postDelIndexSentinel, 10), `\d+`)
+	expr = strings.ReplaceAll(expr, strconv.FormatInt(postDelTimestampSentinel, 10), `\d+`)
+	pattern, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build name pattern from nameTemplate %q: %w", src.NameTemplate, err)
+	}
+
+	// Verify that the names rendered as creation are matched.
+	for _, index := range []int64{1, 42} {
+		name, err := types.RenderPostDelName(tmpl, types.PostDelNameTemplateData{
+			Index:     index,
+			Namespace: src.Namespace,
+			Resource:  src.Resource,
+			Timestamp: time.Now().UnixNano(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !pattern.MatchString(name) {
+			return nil, fmt.Errorf("nameTemplate %q can't be matched for cleanup, like %q: index and timestamp should be rendered in decimal", src.NameTemplate, name)
+		}
+	}
+	return pattern, nil
+}
@@ -0,0 +1,150 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostDelNamePattern(t *testing.T) {
+	src := &types.RequestPostDel{
+		KubeGroupVersionResource: types.KubeGroupVersionResource{
+			Version:  "v1",
+			Resource: "pods",
+		},
+		Namespace:    "default",
+		NameTemplate: "kperf-{{.Resource}}-{{.Index}}",
+	}
+
+	pattern, err := postDelNamePattern(src)
+	require.NoError(t, err)
+
+	tmpl, err := src.ParseNameTemplate()
+	require.NoError(t, err)
+	name, err := types.RenderPostDelName(tmpl, types.PostDelNameTemplateData{Index: 42, Resource: "pods"})
+	require.NoError(t, err)
+	assert.Equal(t, "kperf-pods-42", name)
+
+	assert.True(t, pattern.MatchString(name))
+	assert.False(t, pattern.MatchString("kperf-pods-"))
+	assert.False(t, pattern.MatchString("kperf-pods-42-x"))
+	assert.False(t, pattern.MatchString("other-pods-42"))
+}
+
+func TestPostDelNamePatternFormatted(t *testing.T) {
+	for name, tc := range map[string]struct {
+		nameTemplate string
+		matched      []string
+		unmatched    []string
+		expectedErr  string
+	}{
+		"padded index": {
+			nameTemplate: `kperf-{{printf "%05d" .Index}}`,
+			matched:      []string{"kperf-00001", "kperf-00042", "kperf-123456"},
+			unmatched:    []string{"kperf-", "kperf-1a"},
+		},
+		"timestamp and index": {
+			nameTemplate: `kperf-{{.Timestamp}}-{{.Index}}`,
+			matched:      []string{"kperf-1700000000000000000-1"},
+			unmatched:    []string{"kperf-1700000000000000000"},
+		},
+		"hex index": {
+			nameTemplate: `kperf-{{printf "%x" .Index}}`,
+			expectedErr:  "can't be matched for cleanup",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			src := &types.RequestPostDel{
+				KubeGroupVersionResource: types.KubeGroupVersionResource{
+					Version:  "v1",
+					Resource: "pods",
+				},
+				Namespace:    "default",
+				NameTemplate: tc.nameTemplate,
+			}
+
+			pattern, err := postDelNamePattern(src)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			for _, name := range tc.matched {
+				assert.True(t, pattern.MatchString(name), name)
+			}
+			for _, name := range tc.unmatched {
+				assert.False(t, pattern.MatchString(name), name)
+			}
+		})
+	}
+}
+
+func TestCleanupPostDelResourcesPaginated(t *testing.T) {
+	var mu sync.Mutex
+	var deleted, continues []string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, r.URL.Path)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Success"}`)
+			return
+		}
+
+		assert.Equal(t, "500", r.URL.Query().Get("limit"))
+		token := r.URL.Query().Get("continue")
+		continues = append(continues, token)
+		if token == "" {
+			fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{"continue":"next"},"items":[{"metadata":{"name":"kperf-1"}},{"metadata":{"name":"other"}}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"kperf-2"}}]}`)
+	})
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), 1)
+	require.NoError(t, err)
+
+	spec := &types.LoadProfileSpec{
+		ModeConfig: &types.WeightedRandomConfig{
+			Requests: []*types.WeightedRequest{
+				{
+					Shares: 1,
+					PostDel: &types.RequestPostDel{
+						KubeGroupVersionResource: types.KubeGroupVersionResource{
+							Version:  "v1",
+							Resource: "pods",
+						},
+						Namespace:    "default",
+						NameTemplate: "kperf-{{.Index}}",
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, ValidatePostDelCleanup(spec))
+	require.NoError(t, CleanupPostDelResources(context.TODO(), clis[0], spec))
+
+	assert.Equal(t, []string{"", "next"}, continues)
+	assert.Equal(t, []string{
+		"/api/v1/namespaces/default/pods/kperf-1",
+		"/api/v1/namespaces/default/pods/kperf-2",
+	}, deleted)
+}
+
+func TestPostDelNamePatternWithoutTemplate(t *testing.T) {
+	pattern, err := postDelNamePattern(&types.RequestPostDel{})
+	require.NoError(t, err)
+	assert.Nil(t, pattern)
+}
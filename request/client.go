@@ -6,13 +6,22 @@ package request
 import (
 	"fmt"
 	"math"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Azure/kperf/api/types"
 	"github.com/Azure/kperf/request/unstructuredscheme"
 
+	"github.com/google/uuid"
+	"golang.org/x/net/http2"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2"
 )
 
 // NewClients creates N rest.Interface.
@@ -27,10 +36,34 @@ func NewClients(kubeCfgPath string, connsNum int, opts ...ClientCfgOpt) ([]rest.
 		opt(&cfg)
 	}
 
-	restCfg, err := clientcmd.BuildConfigFromFlags("", kubeCfgPath)
+	if cfg.runID == "" && strings.Contains(cfg.userAgent, UserAgentRunIDPlaceholder) {
+		cfg.runID = uuid.New().String()
+	}
+
+	restCfg, err := cfg.buildRestConfig(kubeCfgPath)
 	if err != nil {
 		return nil, err
 	}
+	return newClientsFromRestConfig(restCfg, connsNum, &cfg)
+}
+
+// NewClientsForConfig creates N rest.Interface from the given rest.Config,
+// like the one from envtest, instead of kubeconfig. The context, cluster
+// and user overrides are ignored. The given rest.Config isn't modified.
+func NewClientsForConfig(restCfg *rest.Config, connsNum int, opts ...ClientCfgOpt) ([]rest.Interface, error) {
+	var cfg = defaultClientCfg
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.runID == "" && strings.Contains(cfg.userAgent, UserAgentRunIDPlaceholder) {
+		cfg.runID = uuid.New().String()
+	}
+	return newClientsFromRestConfig(rest.CopyConfig(restCfg), connsNum, &cfg)
+}
+
+// newClientsFromRestConfig creates N rest.Interface from restCfg with cfg.
+func newClientsFromRestConfig(restCfg *rest.Config, connsNum int, cfg *clientCfg) ([]rest.Interface, error) {
 	restCfg.NegotiatedSerializer = unstructuredscheme.NewNegotiatedSerializer()
 
 	// NOTE:
@@ -40,10 +73,16 @@ func NewClients(kubeCfgPath string, connsNum int, opts ...ClientCfgOpt) ([]rest.
 	// configuration. If not, all the clients will share one transport.
 	// If protocol is HTTP2, there will be only one connection.
 	//
+	// The proxy-url in kubeconfig, if any, is kept. Otherwise, standard
+	// proxy environment variables, like HTTPS_PROXY and NO_PROXY, are
+	// honored.
+	//
 	// REF: https://github.com/kubernetes/client-go/blob/c5938c6876a62f53c1f4ee55b879ca5c74253ae8/transport/cache.go#L154
-	restCfg.Proxy = http.ProxyFromEnvironment
+	if restCfg.Proxy == nil {
+		restCfg.Proxy = http.ProxyFromEnvironment
+	}
 
-	err = cfg.apply(restCfg)
+	err := cfg.apply(restCfg)
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +90,13 @@ func NewClients(kubeCfgPath string, connsNum int, opts ...ClientCfgOpt) ([]rest.
 	restClients := make([]rest.Interface, 0, connsNum)
 	for i := 0; i < connsNum; i++ {
 		cfgShallowCopy := *restCfg
+		cfgShallowCopy.UserAgent = strings.ReplaceAll(restCfg.UserAgent, UserAgentIndexPlaceholder, strconv.Itoa(i))
+		if cfg.transportFactory != nil {
+			cfgShallowCopy.Wrap(cfg.transportFactoryWrap(cfgShallowCopy, i))
+		}
+		if cfg.transportTracer != nil {
+			cfgShallowCopy.Wrap(cfg.transportTracer.wrap(i))
+		}
 
 		restCli, err := rest.UnversionedRESTClientFor(&cfgShallowCopy)
 		if err != nil {
@@ -65,25 +111,135 @@ func NewClients(kubeCfgPath string, connsNum int, opts ...ClientCfgOpt) ([]rest.
 var defaultClientCfg = clientCfg{
 	qps:         float64(math.MaxInt32),
 	contentType: types.ContentTypeJSON,
+
+	serviceAccountTokenExpiration: defaultServiceAccountTokenExpiration,
 }
 
 type clientCfg struct {
 	userAgent    string
+	runID        string
 	qps          float64
+	burst        int
 	contentType  types.ContentType
 	disableHTTP2 bool
+	contextName  string
+	clusterName  string
+	userName     string
+
+	connectTimeout time.Duration
+	readTimeout    time.Duration
+
+	idleConnTimeout     time.Duration
+	disableKeepAlives   bool
+	maxIdleConnsPerHost int
+	tlsHandshakeTimeout time.Duration
+
+	proxyURL string
+
+	dnsCacheTTL time.Duration
+	dnsServers  []string
+
+	http2Settings map[http2.SettingID]uint32
+
+	transportTracer  *TransportTracer
+	transportFactory TransportFactory
+
+	serviceAccountTokenExpiration time.Duration
+}
+
+// BuildRestConfig loads k8s.io/client-go/rest.Config from kubeconfig in the
+// same way as NewClients, for other clients talking to the same apiserver.
+// Only the context, cluster and user overrides are respected.
+func BuildRestConfig(kubeCfgPath string, opts ...ClientCfgOpt) (*rest.Config, error) {
+	var cfg = defaultClientCfg
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg.buildRestConfig(kubeCfgPath)
+}
+
+// buildRestConfig loads k8s.io/client-go/rest.Config from kubeconfig with
+// context overrides.
+func (cfg *clientCfg) buildRestConfig(kubeCfgPath string) (*rest.Config, error) {
+	return cfg.clientConfig(kubeCfgPath).ClientConfig()
+}
+
+// clientConfig returns the kubeconfig loader with context overrides.
+func (cfg *clientCfg) clientConfig(kubeCfgPath string) clientcmd.ClientConfig {
+	loadingRules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeCfgPath}
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: cfg.contextName,
+		Context: clientcmdapi.Context{
+			Cluster:  cfg.clusterName,
+			AuthInfo: cfg.userName,
+		},
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+}
+
+// KubeconfigTarget is where the clients created from kubeconfig connect to.
+type KubeconfigTarget struct {
+	// Context is the name of kubeconfig context. It's empty for in-cluster
+	// config.
+	Context string
+	// Cluster is the name of kubeconfig cluster.
+	Cluster string
+	// User is the name of kubeconfig user.
+	User string
+	// Server is the address of apiserver.
+	Server string
+}
+
+// ResolveKubeconfigTarget returns the target of clients created by NewClients
+// with the same kubeconfig and options. Only the context, cluster and user
+// overrides are respected. It fails if any of them doesn't exist.
+func ResolveKubeconfigTarget(kubeCfgPath string, opts ...ClientCfgOpt) (*KubeconfigTarget, error) {
+	var cfg = defaultClientCfg
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	clientCfg := cfg.clientConfig(kubeCfgPath)
+	restCfg, err := clientCfg.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	rawCfg, err := clientCfg.RawConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	target := &KubeconfigTarget{
+		Context: rawCfg.CurrentContext,
+		Server:  restCfg.Host,
+	}
+	if cfg.contextName != "" {
+		target.Context = cfg.contextName
+	}
+	if kubeCtx, ok := rawCfg.Contexts[target.Context]; ok {
+		target.Cluster = kubeCtx.Cluster
+		target.User = kubeCtx.AuthInfo
+	}
+	if cfg.clusterName != "" {
+		target.Cluster = cfg.clusterName
+	}
+	if cfg.userName != "" {
+		target.User = cfg.userName
+	}
+	return target, nil
 }
 
 // apply sets value to k8s.io/client-go/rest.Config.
 func (cfg *clientCfg) apply(restCfg *rest.Config) error {
-	// set qps
+	// set qps and burst
 	restCfg.QPS = float32(cfg.qps)
+	if cfg.burst > 0 {
+		restCfg.Burst = cfg.burst
+	}
 
 	// set user agent
-	restCfg.UserAgent = cfg.userAgent
-	if restCfg.UserAgent == "" {
-		restCfg.UserAgent = rest.DefaultKubernetesUserAgent()
-	}
+	restCfg.UserAgent = ResolveUserAgent(cfg.userAgent, cfg.runID)
 
 	// set the content type
 	switch cfg.contentType {
@@ -96,12 +252,162 @@ func (cfg *clientCfg) apply(restCfg *rest.Config) error {
 	}
 
 	// disable HTTP2
+	//
+	// NOTE: There is no need to handle HTTP2 server push, which can be sent
+	// by load balancers in front of apiserver. Go's HTTP2 transport sends
+	// SETTINGS_ENABLE_PUSH=0 and treats PUSH_PROMISE as connection error,
+	// so the in-flight requests fail and the connection is re-established.
 	if cfg.disableHTTP2 {
 		restCfg.NextProtos = []string{"http/1.1"}
 	}
+
+	// force all the requests to go through the proxy
+	if cfg.proxyURL != "" {
+		u, err := parseProxyURL(cfg.proxyURL)
+		if err != nil {
+			return err
+		}
+		restCfg.Proxy = http.ProxyURL(u)
+	}
+
+	// set timeout for establishing connection and DNS resolution
+	//
+	// NOTE: The dialer is the same as client-go's default one except
+	// timeout.
+	if cfg.connectTimeout > 0 || cfg.dnsCacheTTL > 0 || len(cfg.dnsServers) > 0 {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}
+		if cfg.connectTimeout > 0 {
+			dialer.Timeout = cfg.connectTimeout
+		}
+
+		restCfg.Dial = dialer.DialContext
+		if cfg.dnsCacheTTL > 0 || len(cfg.dnsServers) > 0 {
+			restCfg.Dial = newDNSResolver(cfg.dnsServers, cfg.dnsCacheTTL).dialContext(dialer)
+		}
+	}
+
+	if cfg.disableKeepAlives && !cfg.disableHTTP2 {
+		klog.Warningf("Keep-alives is disabled with HTTP2, each request will establish new connection")
+	}
+
+	// tune the transport, including timeout for waiting response's headers
+	//
+	// NOTE: The transport is uncacheable since Proxy is set. These fields
+	// are also respected by HTTP2 transport because it's configured from
+	// http.Transport.
+	if cfg.readTimeout > 0 || cfg.idleConnTimeout > 0 || cfg.disableKeepAlives ||
+		cfg.maxIdleConnsPerHost > 0 || cfg.tlsHandshakeTimeout > 0 {
+
+		tuned := *cfg
+		restCfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			if t, ok := rt.(*http.Transport); ok {
+				tuned.tuneTransport(t)
+			}
+			return rt
+		})
+	}
+
+	// set HTTP2 settings sent in the initial SETTINGS frame
+	if len(cfg.http2Settings) > 0 {
+		if err := validateHTTP2Settings(cfg.http2Settings); err != nil {
+			return err
+		}
+
+		if cfg.disableHTTP2 {
+			klog.Warningf("HTTP2 is disabled, HTTP2 settings are ignored")
+		} else {
+			settings := cfg.http2Settings
+			restCfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+				t, ok := rt.(*http.Transport)
+				if !ok {
+					return rt
+				}
+
+				configured, err := configureHTTP2Settings(t, settings)
+				if err != nil {
+					klog.Warningf("Failed to apply HTTP2 settings: %v", err)
+					return rt
+				}
+				return configured
+			})
+		}
+	}
 	return nil
 }
 
+// transportFactoryWrap returns a wrapper which replaces the base transport
+// built by client-go with the one from transportFactory. The authentication
+// and user agent wrappers are still applied on top of it by client-go.
+func (cfg *clientCfg) transportFactoryWrap(restCfg rest.Config, index int) func(http.RoundTripper) http.RoundTripper {
+	factory := cfg.transportFactory
+	return func(rt http.RoundTripper) http.RoundTripper {
+		if custom := factory(&restCfg, index); custom != nil {
+			return custom
+		}
+		return rt
+	}
+}
+
+// parseProxyURL parses the proxy URL. Only http, https and socks5 schemes
+// are supported by http.Transport.
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url %q: %w", proxyURL, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy url %q: unsupported scheme %q", proxyURL, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url %q: host is required", proxyURL)
+	}
+	return u, nil
+}
+
+// tuneTransport overrides the non-zero settings into http.Transport.
+func (cfg *clientCfg) tuneTransport(t *http.Transport) {
+	if cfg.readTimeout > 0 {
+		t.ResponseHeaderTimeout = cfg.readTimeout
+	}
+	if cfg.idleConnTimeout > 0 {
+		t.IdleConnTimeout = cfg.idleConnTimeout
+	}
+	if cfg.disableKeepAlives {
+		t.DisableKeepAlives = true
+	}
+	if cfg.maxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = cfg.maxIdleConnsPerHost
+	}
+	if cfg.tlsHandshakeTimeout > 0 {
+		t.TLSHandshakeTimeout = cfg.tlsHandshakeTimeout
+	}
+}
+
+const (
+	// UserAgentRunIDPlaceholder in user agent is replaced with run ID.
+	UserAgentRunIDPlaceholder = "{run-id}"
+	// UserAgentIndexPlaceholder in user agent is replaced with the index of
+	// client.
+	UserAgentIndexPlaceholder = "{index}"
+)
+
+// ResolveUserAgent returns the user agent pattern shared by all the clients.
+// The run ID placeholder is expanded while the index placeholder is kept
+// since it's different for each client. Empty template means client-go's
+// default user agent.
+func ResolveUserAgent(template, runID string) string {
+	if template == "" {
+		return rest.DefaultKubernetesUserAgent()
+	}
+	return strings.ReplaceAll(template, UserAgentRunIDPlaceholder, runID)
+}
+
 // ClientCfgOpt is used to update default client setting.
 type ClientCfgOpt func(*clientCfg)
 
@@ -114,13 +420,40 @@ func WithClientQPSOpt(qps float64) ClientCfgOpt {
 	}
 }
 
-// WithClientUserAgentOpt updates user agent.
+// WithClientQPSBurstOpt updates QPS and burst of client-side token bucket
+// rate limiter. Zero value keeps the current setting.
+func WithClientQPSBurstOpt(qps float64, burst int) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		WithClientQPSOpt(qps)(cfg)
+		if burst > 0 {
+			cfg.burst = burst
+		}
+	}
+}
+
+// WithClientOptionsOpt applies mode-specific client options returned by
+// ModeConfig.ConfigureClientOptions. Zero QPS means no limit.
+func WithClientOptionsOpt(opts types.ClientOptions) ClientCfgOpt {
+	return WithClientQPSBurstOpt(opts.QPS, opts.Burst)
+}
+
+// WithClientUserAgentOpt updates user agent. It can be a template with
+// {run-id} and {index} placeholders, like kperf-runner/{run-id}/client-{index},
+// so that each client is distinguishable in apiserver's metrics.
 func WithClientUserAgentOpt(ua string) ClientCfgOpt {
 	return func(cfg *clientCfg) {
 		cfg.userAgent = ua
 	}
 }
 
+// WithClientRunIDOpt sets run ID expanded into user agent template. A random
+// one is generated if it's empty and the template requires it.
+func WithClientRunIDOpt(runID string) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		cfg.runID = runID
+	}
+}
+
 // WithClientContentTypeOpt updates content type of response.
 func WithClientContentTypeOpt(ct types.ContentType) ClientCfgOpt {
 	return func(cfg *clientCfg) {
@@ -134,3 +467,122 @@ func WithClientDisableHTTP2Opt(b bool) ClientCfgOpt {
 		cfg.disableHTTP2 = b
 	}
 }
+
+// WithClientHTTP2SettingsOpt sets the HTTP2 settings sent to server in the
+// initial SETTINGS frame. Supported settings are SETTINGS_HEADER_TABLE_SIZE,
+// SETTINGS_INITIAL_WINDOW_SIZE and SETTINGS_MAX_FRAME_SIZE. The
+// SETTINGS_INITIAL_WINDOW_SIZE requires kperf built with go1.24+.
+func WithClientHTTP2SettingsOpt(settings map[http2.SettingID]uint32) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		cfg.http2Settings = settings
+	}
+}
+
+// WithClientContextOpt uses the given kubeconfig context instead of the
+// current context.
+func WithClientContextOpt(contextName string) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		cfg.contextName = contextName
+	}
+}
+
+// WithClientClusterOpt overrides the cluster used by the kubeconfig context.
+func WithClientClusterOpt(clusterName string) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		cfg.clusterName = clusterName
+	}
+}
+
+// WithClientUserOpt overrides the user used by the kubeconfig context.
+func WithClientUserOpt(userName string) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		cfg.userName = userName
+	}
+}
+
+// WithClientConnectTimeoutOpt updates timeout for establishing connection.
+func WithClientConnectTimeoutOpt(timeout time.Duration) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		cfg.connectTimeout = timeout
+	}
+}
+
+// WithClientReadTimeoutOpt updates timeout for waiting response's headers.
+func WithClientReadTimeoutOpt(timeout time.Duration) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		cfg.readTimeout = timeout
+	}
+}
+
+// WithClientTransportOpt tunes HTTP transport's connection behavior by the
+// given spec. Nil spec means no change.
+func WithClientTransportOpt(spec *types.TransportSpec) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		if spec == nil {
+			return
+		}
+		cfg.idleConnTimeout = time.Duration(spec.IdleConnTimeoutSeconds) * time.Second
+		cfg.disableKeepAlives = spec.DisableKeepAlives
+		cfg.maxIdleConnsPerHost = spec.MaxIdleConnsPerHost
+		cfg.tlsHandshakeTimeout = time.Duration(spec.TLSHandshakeTimeoutSeconds) * time.Second
+	}
+}
+
+// WithClientProxyURLOpt sends all the requests through the given proxy,
+// instead of the one from kubeconfig or environment variables.
+func WithClientProxyURLOpt(proxyURL string) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		cfg.proxyURL = proxyURL
+	}
+}
+
+// WithClientDNSCacheOpt caches the resolved addresses of apiserver's host
+// for ttl, so that DNS resolution isn't on the critical path of every new
+// connection. Zero means no cache.
+func WithClientDNSCacheOpt(ttl time.Duration) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		cfg.dnsCacheTTL = ttl
+	}
+}
+
+// WithClientDNSServersOpt resolves apiserver's host by the given nameservers,
+// in ip[:port] format, instead of system's. The port is 53 by default.
+func WithClientDNSServersOpt(servers []string) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		cfg.dnsServers = servers
+	}
+}
+
+// WithClientTransportTracerOpt traces HTTP transports by the given tracer.
+func WithClientTransportTracerOpt(t *TransportTracer) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		cfg.transportTracer = t
+	}
+}
+
+// TransportFactory creates the base HTTP transport for the index-th client.
+// The given config is a copy of the client's rest.Config and must not be
+// modified.
+type TransportFactory func(cfg *rest.Config, index int) http.RoundTripper
+
+// WithClientTransportFactoryOpt builds each client's base transport by the
+// given factory, for example, to use a different TLS configuration or dialer
+// per client. The returned transport replaces the one from rest.TransportFor,
+// so that connect timeout, proxy and transport tunings aren't applied to it.
+// The authentication from kubeconfig is still applied on top of it. If the
+// factory returns nil, the default transport is used.
+func WithClientTransportFactoryOpt(f TransportFactory) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		cfg.transportFactory = f
+	}
+}
+
+// WithClientServiceAccountTokenExpirationSecondsOpt updates the requested
+// lifetime of token created by NewClientsWithServiceAccount.
+func WithClientServiceAccountTokenExpirationSecondsOpt(seconds int) ClientCfgOpt {
+	return func(cfg *clientCfg) {
+		if seconds > 0 {
+			cfg.serviceAccountTokenExpiration = time.Duration(seconds) * time.Second
+		}
+	}
+}
@@ -4,10 +4,25 @@
 package request
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/metrics"
 )
 
@@ -36,3 +51,357 @@ func TestNewClientShouldNotPanic(t *testing.T) {
 	_, err := NewClients("testdata/dummy_nonexistent_kubeconfig.yaml", 10)
 	assert.NoError(t, err)
 }
+
+func TestNewClientWithContext(t *testing.T) {
+	_, err := NewClients("testdata/dummy_nonexistent_kubeconfig.yaml", 1,
+		WithClientContextOpt("testing@unit-test.kperf.io"))
+	assert.NoError(t, err)
+
+	_, err = NewClients("testdata/dummy_nonexistent_kubeconfig.yaml", 1,
+		WithClientContextOpt("unknown"))
+	assert.Error(t, err)
+}
+
+func TestResolveKubeconfigTarget(t *testing.T) {
+	kubeCfgPath := "testdata/dummy_nonexistent_kubeconfig.yaml"
+
+	target, err := ResolveKubeconfigTarget(kubeCfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, &KubeconfigTarget{
+		Context: "testing@unit-test.kperf.io",
+		Cluster: "unit-test.kperf.io",
+		User:    "testing@unit-test.kperf.io",
+		Server:  "https://unit-test.kperf.io",
+	}, target)
+
+	for name, opt := range map[string]ClientCfgOpt{
+		"unknown context": WithClientContextOpt("unknown"),
+		"unknown cluster": WithClientClusterOpt("unknown"),
+		"unknown user":    WithClientUserOpt("unknown"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ResolveKubeconfigTarget(kubeCfgPath, opt)
+			assert.ErrorContains(t, err, `"unknown" does not exist`)
+		})
+	}
+}
+
+func TestNewClientWithReadTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	kubeCfgPath := newTestKubeconfig(t, srv.URL)
+
+	clis, err := NewClients(kubeCfgPath, 1,
+		WithClientConnectTimeoutOpt(time.Second),
+		WithClientReadTimeoutOpt(200*time.Millisecond))
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = clis[0].Get().AbsPath("/api/v1/pods").Timeout(5 * time.Second).Do(context.TODO()).Error()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout awaiting response headers")
+	assert.Less(t, time.Since(start), 3*time.Second)
+}
+
+func TestNewClientWithTransportTracer(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	kubeCfgPath := newTestKubeconfig(t, srv.URL)
+
+	for _, tc := range []struct {
+		disableHTTP2 bool
+		protocol     string
+	}{
+		{disableHTTP2: false, protocol: "h2"},
+		{disableHTTP2: true, protocol: "http/1.1"},
+	} {
+		t.Run(tc.protocol, func(t *testing.T) {
+			tracer := &TransportTracer{}
+			clis, err := NewClients(kubeCfgPath, 2,
+				WithClientDisableHTTP2Opt(tc.disableHTTP2),
+				WithClientTransportTracerOpt(tracer))
+			require.NoError(t, err)
+
+			for _, cli := range clis {
+				for i := 0; i < 3; i++ {
+					require.NoError(t, cli.Get().AbsPath("/api/v1/pods").Do(context.TODO()).Error())
+				}
+			}
+
+			stats := tracer.Stats()
+			assert.Equal(t, int64(2), stats.ConnectionsOpened)
+			assert.Equal(t, int64(2), stats.TLSHandshakes)
+			assert.Equal(t, int64(4), stats.ConnectionsReused)
+			assert.Equal(t, map[string]int{tc.protocol: 2}, stats.Protocols)
+			require.Len(t, stats.Connections, 2)
+			for idx, conn := range stats.Connections {
+				assert.Equal(t, idx, conn.Client)
+				assert.Equal(t, tc.protocol, conn.Protocol)
+				assert.Equal(t, int64(3), conn.Requests)
+			}
+		})
+	}
+}
+
+func TestNewClientWithUserAgentTemplate(t *testing.T) {
+	uaCh := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uaCh <- r.UserAgent()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	kubeCfgPath := newTestKubeconfig(t, srv.URL)
+
+	userAgents := func(opts ...ClientCfgOpt) []string {
+		clis, err := NewClients(kubeCfgPath, 2, opts...)
+		require.NoError(t, err)
+
+		res := make([]string, 0, len(clis))
+		for _, cli := range clis {
+			require.NoError(t, cli.Get().AbsPath("/api/v1/pods").Do(context.TODO()).Error())
+			res = append(res, <-uaCh)
+		}
+		return res
+	}
+
+	template := "kperf-runner/{run-id}/client-{index}"
+	assert.Equal(t, []string{"kperf-runner/abc/client-0", "kperf-runner/abc/client-1"},
+		userAgents(WithClientUserAgentOpt(template), WithClientRunIDOpt("abc")))
+	assert.Equal(t, "kperf-runner/abc/client-{index}", ResolveUserAgent(template, "abc"))
+
+	// Random run ID is shared by all the clients.
+	uas := userAgents(WithClientUserAgentOpt(template))
+	assert.NotContains(t, uas[0], UserAgentRunIDPlaceholder)
+	assert.Equal(t, strings.TrimSuffix(uas[0], "0"), strings.TrimSuffix(uas[1], "1"))
+
+	assert.Equal(t, []string{"static", "static"}, userAgents(WithClientUserAgentOpt("static")))
+	assert.Equal(t, rest.DefaultKubernetesUserAgent(), userAgents()[0])
+}
+
+func TestNewClientWithProxyURL(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var connects int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		atomic.AddInt32(&connects, 1)
+
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+		go func() {
+			defer conn.Close()
+			_, _ = io.Copy(conn, upstream)
+		}()
+		go func() {
+			defer upstream.Close()
+			_, _ = io.Copy(upstream, conn)
+		}()
+	}))
+	defer proxy.Close()
+
+	kubeCfgPath := newTestKubeconfig(t, srv.URL)
+
+	_, err := NewClients(kubeCfgPath, 1, WithClientProxyURLOpt("ftp://proxy"))
+	assert.Error(t, err)
+
+	tracer := &TransportTracer{}
+	clis, err := NewClients(kubeCfgPath, 2,
+		WithClientProxyURLOpt(proxy.URL),
+		WithClientTransportTracerOpt(tracer))
+	require.NoError(t, err)
+
+	for _, cli := range clis {
+		for i := 0; i < 3; i++ {
+			require.NoError(t, cli.Get().AbsPath("/api/v1/pods").Do(context.TODO()).Error())
+		}
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&connects))
+	assert.Equal(t, proxy.URL, tracer.Proxy())
+
+	stats := tracer.Stats()
+	assert.Equal(t, int64(2), stats.ProxyConnects)
+	assert.Greater(t, stats.ProxyConnectSeconds, float64(0))
+	assert.Equal(t, int64(2), stats.TLSHandshakes)
+}
+
+func TestNewClientWithTransportFactory(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	kubeCfgPath := newTestKubeconfig(t, srv.URL)
+
+	counts := make([]atomic.Int32, 3)
+	clis, err := NewClients(kubeCfgPath, len(counts),
+		WithClientTransportFactoryOpt(func(cfg *rest.Config, index int) http.RoundTripper {
+			assert.Equal(t, srv.URL, cfg.Host)
+			if index == len(counts)-1 {
+				return nil
+			}
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				counts[index].Add(1)
+				return http.DefaultTransport.RoundTrip(req)
+			})
+		}))
+	require.NoError(t, err)
+
+	for i, cli := range clis {
+		for j := 0; j <= i; j++ {
+			require.NoError(t, cli.Get().AbsPath("/api/v1/pods").Do(context.TODO()).Error())
+		}
+	}
+	assert.Equal(t, int32(1), counts[0].Load())
+	assert.Equal(t, int32(2), counts[1].Load())
+	assert.Equal(t, int32(0), counts[2].Load())
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClientCfgTuneTransport(t *testing.T) {
+	cfg := defaultClientCfg
+	WithClientReadTimeoutOpt(2 * time.Second)(&cfg)
+	WithClientTransportOpt(&types.TransportSpec{
+		IdleConnTimeoutSeconds:     5,
+		DisableKeepAlives:          true,
+		MaxIdleConnsPerHost:        7,
+		TLSHandshakeTimeoutSeconds: 3,
+	})(&cfg)
+
+	restCfg := &rest.Config{}
+	require.NoError(t, cfg.apply(restCfg))
+	require.NotNil(t, restCfg.WrapTransport)
+
+	tr := &http.Transport{
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConnsPerHost: 25,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	restCfg.WrapTransport(tr)
+	assert.Equal(t, 2*time.Second, tr.ResponseHeaderTimeout)
+	assert.Equal(t, 5*time.Second, tr.IdleConnTimeout)
+	assert.True(t, tr.DisableKeepAlives)
+	assert.Equal(t, 7, tr.MaxIdleConnsPerHost)
+	assert.Equal(t, 3*time.Second, tr.TLSHandshakeTimeout)
+
+	// Zero value keeps client-go's default.
+	cfg = defaultClientCfg
+	WithClientTransportOpt(&types.TransportSpec{IdleConnTimeoutSeconds: 5})(&cfg)
+
+	restCfg = &rest.Config{}
+	require.NoError(t, cfg.apply(restCfg))
+
+	tr = &http.Transport{MaxIdleConnsPerHost: 25, TLSHandshakeTimeout: 10 * time.Second}
+	restCfg.WrapTransport(tr)
+	assert.Equal(t, 5*time.Second, tr.IdleConnTimeout)
+	assert.Equal(t, 25, tr.MaxIdleConnsPerHost)
+	assert.Equal(t, 10*time.Second, tr.TLSHandshakeTimeout)
+	assert.False(t, tr.DisableKeepAlives)
+}
+
+func TestClientCfgQPSBurst(t *testing.T) {
+	cfg := defaultClientCfg
+	WithClientQPSBurstOpt(50, 100)(&cfg)
+
+	restCfg := &rest.Config{}
+	require.NoError(t, cfg.apply(restCfg))
+	assert.Equal(t, float32(50), restCfg.QPS)
+	assert.Equal(t, 100, restCfg.Burst)
+
+	// Zero value keeps the default.
+	cfg = defaultClientCfg
+	WithClientQPSOpt(50)(&cfg)
+
+	restCfg = &rest.Config{}
+	require.NoError(t, cfg.apply(restCfg))
+	assert.Equal(t, float32(50), restCfg.QPS)
+	assert.Equal(t, 0, restCfg.Burst)
+}
+
+func TestClientCfgModeClientOptions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		config      types.ModeConfig
+		expectedQPS float32
+	}{
+		"time-series is unthrottled": {
+			config:      &types.TimeSeriesConfig{Interval: "1s"},
+			expectedQPS: float32(math.MaxInt32),
+		},
+		"weighted-random with rate": {
+			config:      &types.WeightedRandomConfig{Rate: 100},
+			expectedQPS: 100,
+		},
+		"weighted-random without rate": {
+			config:      &types.WeightedRandomConfig{},
+			expectedQPS: float32(math.MaxInt32),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := defaultClientCfg
+			WithClientOptionsOpt(tc.config.ConfigureClientOptions())(&cfg)
+
+			restCfg := &rest.Config{}
+			require.NoError(t, cfg.apply(restCfg))
+			assert.Equal(t, tc.expectedQPS, restCfg.QPS)
+		})
+	}
+}
+
+// newTestKubeconfig creates kubeconfig file which points to the given server.
+func newTestKubeconfig(t testing.TB, serverURL string) string {
+	kubeCfgPath := filepath.Join(t.TempDir(), "kubeconfig")
+	kubeCfg := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: %t
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user: {}
+`, serverURL, strings.HasPrefix(serverURL, "https://"))
+	require.NoError(t, os.WriteFile(kubeCfgPath, []byte(kubeCfg), 0600))
+	return kubeCfgPath
+}
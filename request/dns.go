@@ -0,0 +1,147 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultDNSPort is the port of nameserver if it isn't specified.
+const defaultDNSPort = "53"
+
+// dnsResolver resolves host names by the given nameservers, or system's if
+// there is none, and caches the addresses for ttl. It's shared by all the
+// clients created by NewClients.
+type dnsResolver struct {
+	resolver *net.Resolver
+	ttl      time.Duration
+	cache    sync.Map // host -> *dnsCacheEntry
+}
+
+// dnsCacheEntry is the cached addresses of host.
+type dnsCacheEntry struct {
+	addrs   []net.IPAddr
+	expires time.Time
+}
+
+// newDNSResolver returns dnsResolver. The nameservers are used in turn and
+// the port is 53 if it isn't specified. Zero ttl disables cache.
+func newDNSResolver(servers []string, ttl time.Duration) *dnsResolver {
+	r := &dnsResolver{
+		resolver: net.DefaultResolver,
+		ttl:      ttl,
+	}
+	if len(servers) == 0 {
+		return r
+	}
+
+	addrs := make([]string, 0, len(servers))
+	for _, s := range servers {
+		addrs = append(addrs, withDefaultPort(s, defaultDNSPort))
+	}
+
+	var next uint32
+	r.resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			addr := addrs[int(atomic.AddUint32(&next, 1)-1)%len(addrs)]
+
+			// NOTE: The connection to nameserver isn't the one to
+			// apiserver, so it's hidden from httptrace.ClientTrace in ctx.
+			dialCtx, cancel := withoutValues(ctx)
+			defer cancel()
+			return (&net.Dialer{}).DialContext(dialCtx, network, addr)
+		},
+	}
+	return r
+}
+
+// lookup returns the addresses of host from cache if it's not expired.
+//
+// NOTE: LookupIPAddr reports DNSStart and DNSDone to httptrace.ClientTrace
+// in ctx, which is how TransportTracer measures resolution. Cache hits
+// aren't reported because there is no resolution.
+func (r *dnsResolver) lookup(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if r.ttl > 0 {
+		if v, ok := r.cache.Load(host); ok {
+			if entry := v.(*dnsCacheEntry); time.Now().Before(entry.expires) {
+				return entry.addrs, nil
+			}
+		}
+	}
+
+	addrs, err := r.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if r.ttl > 0 {
+		r.cache.Store(host, &dnsCacheEntry{addrs: addrs, expires: time.Now().Add(r.ttl)})
+	}
+	return addrs, nil
+}
+
+// dialContext returns the dial function which resolves host by r and dials
+// the addresses in order until one succeeds.
+func (r *dnsResolver) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		addrs, err := r.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var errs []error
+		for _, ip := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+		if len(errs) == 0 {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+// withoutValues returns a context which is canceled with ctx and has the
+// same deadline, but none of its values.
+func withoutValues(ctx context.Context) (context.Context, context.CancelFunc) {
+	res, cancel := context.WithCancel(context.Background())
+	stop := context.AfterFunc(ctx, cancel)
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancelDeadline context.CancelFunc
+		res, cancelDeadline = context.WithDeadline(res, deadline)
+		return res, func() {
+			stop()
+			cancelDeadline()
+			cancel()
+		}
+	}
+	return res, func() {
+		stop()
+		cancel()
+	}
+}
+
+// withDefaultPort appends port to addr if addr doesn't have one.
+func withDefaultPort(addr, port string) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+	return net.JoinHostPort(addr, port)
+}
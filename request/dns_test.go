@@ -0,0 +1,123 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// newTestNameserver serves A record of 127.0.0.1 for any name over UDP. It
+// returns the address and the number of served queries.
+func newTestNameserver(t *testing.T) (string, *atomic.Int32) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	var queries atomic.Int32
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			var msg dnsmessage.Message
+			if err := msg.Unpack(buf[:n]); err != nil || len(msg.Questions) == 0 {
+				continue
+			}
+			queries.Add(1)
+
+			q := msg.Questions[0]
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: msg.ID, Response: true, Authoritative: true},
+				Questions: msg.Questions,
+			}
+			if q.Type == dnsmessage.TypeA {
+				resp.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class, TTL: 60},
+					Body:   &dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}},
+				}}
+			}
+			data, err := resp.Pack()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(data, addr)
+		}
+	}()
+	return conn.LocalAddr().String(), &queries
+}
+
+func TestNewClientWithDNS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// NOTE: The host is only known by the test nameserver.
+	serverURL := strings.Replace(srv.URL, "127.0.0.1", "apiserver.kperf.test", 1)
+	kubeCfgPath := newTestKubeconfig(t, serverURL)
+
+	for name, tc := range map[string]struct {
+		ttl         time.Duration
+		resolutions int64
+	}{
+		"without cache": {resolutions: 3},
+		"with cache":    {ttl: time.Minute, resolutions: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			nameserver, queries := newTestNameserver(t)
+
+			tracer := &TransportTracer{}
+			clis, err := NewClients(kubeCfgPath, 1,
+				WithClientDNSServersOpt([]string{nameserver}),
+				WithClientDNSCacheOpt(tc.ttl),
+				// Each request dials new connection.
+				WithClientTransportOpt(&types.TransportSpec{DisableKeepAlives: true}),
+				WithClientDisableHTTP2Opt(true),
+				WithClientTransportTracerOpt(tracer))
+			require.NoError(t, err)
+
+			for i := 0; i < 3; i++ {
+				require.NoError(t, clis[0].Get().AbsPath("/api/v1/pods").Do(context.TODO()).Error())
+			}
+
+			stats := tracer.Stats()
+			assert.Equal(t, int64(3), stats.ConnectionsOpened)
+			assert.Equal(t, tc.resolutions, stats.DNSResolutions)
+			require.NotNil(t, stats.DNSResolutionDurationSeconds)
+			total := int64(0)
+			for _, c := range stats.DNSResolutionDurationSeconds.Counts {
+				total += c
+			}
+			assert.Equal(t, tc.resolutions, total)
+			assert.NotZero(t, queries.Load())
+		})
+	}
+}
+
+func TestWithDefaultPort(t *testing.T) {
+	for addr, expected := range map[string]string{
+		"10.0.0.10":      "10.0.0.10:53",
+		"10.0.0.10:5353": "10.0.0.10:5353",
+		"fd00::10":       "[fd00::10]:53",
+		"[fd00::10]:54":  "[fd00::10]:54",
+	} {
+		assert.Equal(t, expected, withDefaultPort(addr, defaultDNSPort), addr)
+	}
+}
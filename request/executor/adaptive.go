@@ -0,0 +1,234 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/metrics"
+
+	"golang.org/x/time/rate"
+	"k8s.io/klog/v2"
+)
+
+// adaptiveMaxExceededRatio is the maximum ratio of requests which fail or
+// exceed the target latency, which means P99.
+const adaptiveMaxExceededRatio = 0.01
+
+// AdaptiveExecutor implements Executor for adaptive mode. It binary-searches
+// the maximum rate which keeps P99 latency under the target. Each step sends
+// requests at the probed rate for StepDuration, with the same distribution
+// as weighted-random mode.
+type AdaptiveExecutor struct {
+	config *types.AdaptiveConfig
+	inner  *WeightedRandomExecutor
+
+	mu sync.Mutex
+	// latencies are the latencies in seconds of successful requests
+	// during current step.
+	latencies []float64
+	// errors is the number of failed requests during current step.
+	errors int
+	report types.AdaptiveReport
+
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// NewAdaptiveExecutor creates a new adaptive executor from spec.
+func NewAdaptiveExecutor(spec *types.LoadProfileSpec) (Executor, error) {
+	if spec.Mode != types.ModeAdaptive {
+		return nil, fmt.Errorf("expected mode %s, got %s", types.ModeAdaptive, spec.Mode)
+	}
+
+	if spec.ModeConfig == nil {
+		return nil, fmt.Errorf("modeConfig is required")
+	}
+
+	config, ok := spec.ModeConfig.(*types.AdaptiveConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for adaptive mode")
+	}
+
+	// NOTE: The requests are generated by weighted-random executor without
+	// total and duration. The rate is updated for each step.
+	innerSpec := *spec
+	innerSpec.Mode = types.ModeWeightedRandom
+	innerSpec.ModeConfig = &types.WeightedRandomConfig{
+		Rate:          config.MinRate,
+		Requests:      config.Requests,
+		MaxRetryPicks: config.MaxRetryPicks,
+	}
+	inner, err := NewWeightedRandomExecutor(&innerSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AdaptiveExecutor{
+		config: config,
+		inner:  inner.(*WeightedRandomExecutor),
+		report: types.AdaptiveReport{
+			TargetP99Seconds: config.TargetP99Seconds,
+			Steps:            []types.AdaptiveStep{},
+		},
+	}, nil
+}
+
+// Chan returns the channel that produces request builders.
+func (e *AdaptiveExecutor) Chan() <-chan RESTRequestBuilder {
+	return e.inner.Chan()
+}
+
+// Run starts the executor and probes the rates one by one.
+func (e *AdaptiveExecutor) Run(ctx context.Context) error {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	innerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	innerErrCh := make(chan error, 1)
+	go func() {
+		innerErrCh <- e.inner.Run(innerCtx)
+	}()
+
+	stepDuration := time.Duration(e.config.StepDuration) * time.Second
+	low, high := e.config.MinRate, e.config.MaxRate
+	for i := 0; i < e.config.Steps; i++ {
+		r := low + (high-low)/2
+
+		e.startStep(r)
+		select {
+		case <-time.After(stepDuration):
+		case err := <-innerErrCh:
+			return err
+		}
+
+		step := e.finishStep(r)
+		klog.V(2).InfoS("Adaptive step finished",
+			"step", i, "rate", step.Rate, "requests", step.Requests,
+			"errors", step.Errors, "p99", step.P99Seconds, "passed", step.Passed)
+
+		if step.Passed {
+			low = r
+		} else {
+			high = r
+		}
+	}
+
+	cancel()
+	<-innerErrCh
+
+	klog.InfoS("Adaptive search finished",
+		"sustainableRate", e.Report().Adaptive.SustainableRate,
+		"targetP99", e.config.TargetP99Seconds)
+	return nil
+}
+
+// startStep resets the observed responses and updates the rate.
+//
+// NOTE: The responses of requests sent in previous step, which are still
+// in-flight, are counted into this step.
+func (e *AdaptiveExecutor) startStep(r float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.latencies = nil
+	e.errors = 0
+	e.inner.limiter.SetLimit(rate.Limit(r))
+}
+
+// finishStep evaluates the observed responses at the given rate.
+func (e *AdaptiveExecutor) finishStep(r float64) types.AdaptiveStep {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	step := types.AdaptiveStep{
+		Rate:     r,
+		Requests: len(e.latencies) + e.errors,
+		Errors:   e.errors,
+	}
+
+	exceeded := e.errors
+	for _, l := range e.latencies {
+		if l > e.config.TargetP99Seconds {
+			exceeded++
+		}
+	}
+	if percentiles := metrics.BuildPercentileLatencies(e.latencies); percentiles != nil {
+		for _, p := range percentiles {
+			if p[0] == 0.99 {
+				step.P99Seconds = p[1]
+			}
+		}
+	}
+	step.Passed = step.Requests > 0 &&
+		float64(exceeded) <= adaptiveMaxExceededRatio*float64(step.Requests)
+
+	if step.Passed && r > e.report.SustainableRate {
+		e.report.SustainableRate = r
+	}
+	e.report.Steps = append(e.report.Steps, step)
+	return step
+}
+
+// OnResponse implements ResponseObserver.
+func (e *AdaptiveExecutor) OnResponse(_ string, latency time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err != nil {
+		e.errors++
+		return
+	}
+	e.latencies = append(e.latencies, latency.Seconds())
+}
+
+// Report implements Reporter.
+func (e *AdaptiveExecutor) Report() *types.ExecutorReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	report := e.report
+	report.Steps = append([]types.AdaptiveStep{}, e.report.Steps...)
+	return &types.ExecutorReport{Adaptive: &report}
+}
+
+// Stop gracefully stops the executor.
+func (e *AdaptiveExecutor) Stop() {
+	e.once.Do(func() {
+		e.inner.Stop()
+		e.wg.Wait()
+	})
+}
+
+// Metadata returns executor metadata.
+func (e *AdaptiveExecutor) Metadata() ExecutorMetadata {
+	return ExecutorMetadata{
+		ExpectedDuration: time.Duration(e.config.Steps) * e.config.StepDuration.Duration(),
+		Custom: map[string]interface{}{
+			"mode":          string(types.ModeAdaptive),
+			"target_p99":    e.config.TargetP99Seconds,
+			"min_rate":      e.config.MinRate,
+			"max_rate":      e.config.MaxRate,
+			"steps":         e.config.Steps,
+			"request_types": len(e.config.Requests),
+		},
+	}
+}
+
+// GetRateLimiter returns the rate limiter updated for each step.
+func (e *AdaptiveExecutor) GetRateLimiter() RateLimiter {
+	return e.inner.limiter
+}
+
+// GetExecutionContext returns a cancelable context. The duration is
+// controlled by steps.
+func (e *AdaptiveExecutor) GetExecutionContext(baseCtx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithCancel(baseCtx)
+}
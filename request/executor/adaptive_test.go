@@ -0,0 +1,84 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveExecutor(t *testing.T) {
+	origin := createRequestBuilderFunc
+	defer func() { createRequestBuilderFunc = origin }()
+
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	config := &types.AdaptiveConfig{
+		TargetP99Seconds: 0.1,
+		MaxRate:          400,
+		Steps:            2,
+		StepDuration:     1,
+		Requests: []*types.WeightedRequest{
+			{
+				Shares: 1,
+				StaleList: &types.RequestList{
+					KubeGroupVersionResource: types.KubeGroupVersionResource{
+						Version:  "v1",
+						Resource: "pods",
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, config.Validate(nil))
+
+	exec, err := NewAdaptiveExecutor(&types.LoadProfileSpec{
+		Mode:       types.ModeAdaptive,
+		ModeConfig: config,
+	})
+	require.NoError(t, err)
+	defer exec.Stop()
+
+	observer, ok := exec.(ResponseObserver)
+	require.True(t, ok)
+
+	// The fake apiserver is overloaded if rate is higher than 250.
+	limiter := exec.GetRateLimiter()
+	go func() {
+		for range exec.Chan() {
+			if limiter.Wait(context.TODO()) != nil {
+				return
+			}
+			latency := 10 * time.Millisecond
+			if exec.(*AdaptiveExecutor).inner.limiter.Limit() > 250 {
+				latency = time.Second
+			}
+			observer.OnResponse("GET", latency, nil)
+		}
+	}()
+
+	require.NoError(t, exec.Run(context.TODO()))
+
+	report := exec.(Reporter).Report().Adaptive
+	require.NotNil(t, report)
+	require.Len(t, report.Steps, 2)
+
+	// The first step probes the middle of [1, 400] and the second step
+	// probes the middle of [200.5, 400].
+	assert.Equal(t, 200.5, report.Steps[0].Rate)
+	assert.True(t, report.Steps[0].Passed)
+	assert.Greater(t, report.Steps[0].Requests, 0)
+	assert.Equal(t, 300.25, report.Steps[1].Rate)
+	assert.False(t, report.Steps[1].Passed)
+	assert.Equal(t, 200.5, report.SustainableRate)
+	assert.Equal(t, 0.1, report.TargetP99Seconds)
+}
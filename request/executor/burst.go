@@ -0,0 +1,241 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/metrics"
+
+	"k8s.io/klog/v2"
+)
+
+// BurstExecutor implements Executor for burst mode. It alternates bursts,
+// which send requests at BurstRate for BurstDuration, with idle periods
+// without any request. The requests have the same distribution as
+// weighted-random mode.
+type BurstExecutor struct {
+	config *types.BurstConfig
+	inner  *WeightedRandomExecutor
+
+	mu sync.Mutex
+	// bursting is true if it's in burst.
+	bursting bool
+	// resumeCh is closed when next burst starts.
+	resumeCh chan struct{}
+	// current is the index of current or last burst.
+	current int
+	// latencies are the latencies in seconds of successful requests
+	// per burst.
+	latencies [][]float64
+	// errors are the number of failed requests per burst.
+	errors []int
+
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// NewBurstExecutor creates a new burst executor from spec.
+func NewBurstExecutor(spec *types.LoadProfileSpec) (Executor, error) {
+	if spec.Mode != types.ModeBurst {
+		return nil, fmt.Errorf("expected mode %s, got %s", types.ModeBurst, spec.Mode)
+	}
+
+	if spec.ModeConfig == nil {
+		return nil, fmt.Errorf("modeConfig is required")
+	}
+
+	config, ok := spec.ModeConfig.(*types.BurstConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for burst mode")
+	}
+
+	// NOTE: The requests are generated by weighted-random executor without
+	// total and duration. The idle periods are controlled by rate limiter.
+	innerSpec := *spec
+	innerSpec.Mode = types.ModeWeightedRandom
+	innerSpec.ModeConfig = &types.WeightedRandomConfig{
+		Rate:          config.BurstRate,
+		Requests:      config.Requests,
+		MaxRetryPicks: config.MaxRetryPicks,
+	}
+	inner, err := NewWeightedRandomExecutor(&innerSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BurstExecutor{
+		config:    config,
+		inner:     inner.(*WeightedRandomExecutor),
+		resumeCh:  make(chan struct{}),
+		latencies: make([][]float64, config.Cycles),
+		errors:    make([]int, config.Cycles),
+	}, nil
+}
+
+// Chan returns the channel that produces request builders.
+func (e *BurstExecutor) Chan() <-chan RESTRequestBuilder {
+	return e.inner.Chan()
+}
+
+// Run starts the executor and runs the bursts one by one.
+func (e *BurstExecutor) Run(ctx context.Context) error {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	innerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	innerErrCh := make(chan error, 1)
+	go func() {
+		innerErrCh <- e.inner.Run(innerCtx)
+	}()
+
+	burstDuration := time.Duration(e.config.BurstDuration) * time.Second
+	idleDuration := time.Duration(e.config.IdleDuration) * time.Second
+	for i := 0; i < e.config.Cycles; i++ {
+		e.startBurst(i)
+		klog.V(2).InfoS("Burst started", "burst", i, "rate", e.config.BurstRate)
+
+		select {
+		case <-time.After(burstDuration):
+		case err := <-innerErrCh:
+			return err
+		}
+
+		e.stopBurst()
+		klog.V(2).InfoS("Burst finished", "burst", i)
+
+		if i == e.config.Cycles-1 || idleDuration == 0 {
+			continue
+		}
+
+		select {
+		case <-time.After(idleDuration):
+		case err := <-innerErrCh:
+			return err
+		}
+	}
+
+	cancel()
+	<-innerErrCh
+	return nil
+}
+
+// startBurst resumes the requests of the i-th burst.
+func (e *BurstExecutor) startBurst(i int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.current = i
+	e.bursting = true
+	close(e.resumeCh)
+}
+
+// stopBurst pauses the requests until next burst.
+//
+// NOTE: The responses of requests sent in a burst, which are still in-flight,
+// are still counted into that burst.
+func (e *BurstExecutor) stopBurst() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.bursting = false
+	e.resumeCh = make(chan struct{})
+}
+
+// OnResponse implements ResponseObserver.
+func (e *BurstExecutor) OnResponse(_ string, latency time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err != nil {
+		e.errors[e.current]++
+		return
+	}
+	e.latencies[e.current] = append(e.latencies[e.current], latency.Seconds())
+}
+
+// Report implements Reporter.
+func (e *BurstExecutor) Report() *types.ExecutorReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	report := &types.BurstReport{
+		Bursts: make([]types.BurstStats, 0, len(e.latencies)),
+	}
+	for i, latencies := range e.latencies {
+		report.Bursts = append(report.Bursts, types.BurstStats{
+			Index:               i,
+			Requests:            len(latencies) + e.errors[i],
+			Errors:              e.errors[i],
+			PercentileLatencies: metrics.BuildPercentileLatencies(append([]float64{}, latencies...)),
+		})
+	}
+	return &types.ExecutorReport{Burst: report}
+}
+
+// Stop gracefully stops the executor.
+func (e *BurstExecutor) Stop() {
+	e.once.Do(func() {
+		e.inner.Stop()
+		e.wg.Wait()
+	})
+}
+
+// Metadata returns executor metadata.
+func (e *BurstExecutor) Metadata() ExecutorMetadata {
+	cycles := e.config.Cycles
+	return ExecutorMetadata{
+		ExpectedDuration: time.Duration(cycles)*e.config.BurstDuration.Duration() + time.Duration(cycles-1)*e.config.IdleDuration.Duration(),
+		Custom: map[string]interface{}{
+			"mode":           string(types.ModeBurst),
+			"rate":           e.config.BurstRate,
+			"burst_duration": e.config.BurstDuration,
+			"idle_duration":  e.config.IdleDuration,
+			"cycles":         cycles,
+			"request_types":  len(e.config.Requests),
+		},
+	}
+}
+
+// GetRateLimiter returns the rate limiter which blocks during idle periods.
+func (e *BurstExecutor) GetRateLimiter() RateLimiter {
+	return burstLimiter{e}
+}
+
+// GetExecutionContext returns a cancelable context. The duration is
+// controlled by cycles.
+func (e *BurstExecutor) GetExecutionContext(baseCtx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithCancel(baseCtx)
+}
+
+// burstLimiter waits for next burst during idle periods and then for the
+// burst's rate limiter.
+type burstLimiter struct {
+	e *BurstExecutor
+}
+
+// Wait implements RateLimiter.
+func (l burstLimiter) Wait(ctx context.Context) error {
+	for {
+		l.e.mu.Lock()
+		bursting, resumeCh := l.e.bursting, l.e.resumeCh
+		l.e.mu.Unlock()
+
+		if bursting {
+			return l.e.inner.limiter.Wait(ctx)
+		}
+
+		select {
+		case <-resumeCh:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
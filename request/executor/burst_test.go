@@ -0,0 +1,108 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBurstExecutor(t *testing.T) {
+	origin := createRequestBuilderFunc
+	defer func() { createRequestBuilderFunc = origin }()
+
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	config := &types.BurstConfig{
+		BurstRate:     100,
+		BurstDuration: 1,
+		IdleDuration:  1,
+		Cycles:        2,
+		Requests: []*types.WeightedRequest{
+			{
+				Shares: 1,
+				StaleList: &types.RequestList{
+					KubeGroupVersionResource: types.KubeGroupVersionResource{
+						Version:  "v1",
+						Resource: "pods",
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, config.Validate(nil))
+
+	exec, err := NewBurstExecutor(&types.LoadProfileSpec{
+		Mode:       types.ModeBurst,
+		ModeConfig: config,
+	})
+	require.NoError(t, err)
+	defer exec.Stop()
+	assert.Equal(t, 3*time.Second, exec.Metadata().ExpectedDuration)
+
+	observer, ok := exec.(ResponseObserver)
+	require.True(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The fake apiserver degrades in the second burst.
+	var mu sync.Mutex
+	var sentAt []time.Duration
+	start := time.Now()
+
+	limiter := exec.GetRateLimiter()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range exec.Chan() {
+			if limiter.Wait(ctx) != nil {
+				return
+			}
+			elapsed := time.Since(start)
+
+			mu.Lock()
+			sentAt = append(sentAt, elapsed)
+			mu.Unlock()
+
+			latency := 10 * time.Millisecond
+			if elapsed > 2*time.Second {
+				latency = 100 * time.Millisecond
+			}
+			observer.OnResponse("GET", latency, nil)
+		}
+	}()
+
+	require.NoError(t, exec.Run(ctx))
+	cancel()
+	<-done
+
+	// No request is sent during idle period.
+	mu.Lock()
+	for _, at := range sentAt {
+		assert.False(t, at > 1100*time.Millisecond && at < 1900*time.Millisecond, "request sent at %v", at)
+	}
+	mu.Unlock()
+
+	report := exec.(Reporter).Report().Burst
+	require.NotNil(t, report)
+	require.Len(t, report.Bursts, 2)
+	for i, b := range report.Bursts {
+		assert.Equal(t, i, b.Index)
+		assert.Greater(t, b.Requests, 0)
+		assert.Equal(t, 0, b.Errors)
+		require.NotEmpty(t, b.PercentileLatencies)
+	}
+	assert.Equal(t, 0.01, report.Bursts[0].PercentileLatencies[4][1])
+	assert.Equal(t, 0.1, report.Bursts[1].PercentileLatencies[4][1])
+}
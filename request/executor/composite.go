@@ -0,0 +1,217 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Azure/kperf/api/types"
+	"k8s.io/klog/v2"
+)
+
+// CompositeExecutor implements Executor for composite mode. It runs child
+// executors concurrently and multiplexes their channels into one. Each
+// child keeps its own rate limiter, which is applied before its requests
+// are multiplexed, so there is no global rate limiter.
+type CompositeExecutor struct {
+	config       *types.CompositeConfig
+	children     []Executor
+	reqBuilderCh chan RESTRequestBuilder
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	// forwardWg waits for the goroutines which forward children's requests.
+	forwardWg sync.WaitGroup
+	once      sync.Once
+}
+
+// newCompositeExecutor creates a new composite executor from spec. The
+// children are created by the factory so that plugin modes can be children.
+func (f *ExecutorFactory) newCompositeExecutor(spec *types.LoadProfileSpec) (Executor, error) {
+	if spec.Mode != types.ModeComposite {
+		return nil, fmt.Errorf("expected mode %s, got %s", types.ModeComposite, spec.Mode)
+	}
+
+	if spec.ModeConfig == nil {
+		return nil, fmt.Errorf("modeConfig is required")
+	}
+
+	config, ok := spec.ModeConfig.(*types.CompositeConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for composite mode")
+	}
+
+	children := make([]Executor, 0, len(config.Children))
+	for _, child := range config.Children {
+		childSpec := *spec
+		childSpec.Mode = child.Mode
+		childSpec.ModeConfig = child.ModeConfig
+
+		exec, err := f.Create(&childSpec)
+		if err != nil {
+			for _, c := range children {
+				c.Stop()
+			}
+			return nil, fmt.Errorf("failed to create child %s: %w", child.Name, err)
+		}
+		children = append(children, exec)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &CompositeExecutor{
+		config:       config,
+		children:     children,
+		reqBuilderCh: make(chan RESTRequestBuilder),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+	for _, child := range children {
+		e.forwardWg.Add(1)
+		go e.forward(child)
+	}
+	return e, nil
+}
+
+// forward sends the child's requests to the multiplexed channel after the
+// child's rate limiter permits.
+func (e *CompositeExecutor) forward(child Executor) {
+	defer e.forwardWg.Done()
+
+	limiter := child.GetRateLimiter()
+	for {
+		var builder RESTRequestBuilder
+		select {
+		case b, ok := <-child.Chan():
+			if !ok {
+				return
+			}
+			builder = b
+		case <-e.ctx.Done():
+			return
+		}
+
+		if limiter != nil {
+			if err := limiter.Wait(e.ctx); err != nil {
+				return
+			}
+		}
+
+		select {
+		case e.reqBuilderCh <- builder:
+		case <-e.ctx.Done():
+			return
+		}
+	}
+}
+
+// Chan returns the channel that produces request builders.
+func (e *CompositeExecutor) Chan() <-chan RESTRequestBuilder {
+	return e.reqBuilderCh
+}
+
+// Run runs all the children concurrently with their own execution context
+// and returns after all of them finish.
+func (e *CompositeExecutor) Run(ctx context.Context) error {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	errCh := make(chan error, len(e.children))
+	for idx, child := range e.children {
+		go func() {
+			childCtx, cancel := child.GetExecutionContext(ctx)
+			defer cancel()
+
+			err := child.Run(childCtx)
+			if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+				err = fmt.Errorf("child %s: %w", e.config.Children[idx].Name, err)
+			} else {
+				err = nil
+			}
+			klog.V(2).InfoS("Composite child finished", "child", e.config.Children[idx].Name, "err", err)
+			errCh <- err
+		}()
+	}
+
+	var firstErr error
+	for range e.children {
+		if err := <-errCh; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Stop gracefully stops all the children and closes the channel.
+func (e *CompositeExecutor) Stop() {
+	e.once.Do(func() {
+		e.cancel()
+		for _, child := range e.children {
+			child.Stop()
+		}
+		e.forwardWg.Wait()
+		e.wg.Wait()
+		close(e.reqBuilderCh)
+	})
+}
+
+// Report implements Reporter with children's reports by name.
+func (e *CompositeExecutor) Report() *types.ExecutorReport {
+	report := &types.ExecutorReport{}
+	for idx, child := range e.children {
+		reporter, ok := child.(Reporter)
+		if !ok {
+			continue
+		}
+		if report.Children == nil {
+			report.Children = make(map[string]*types.ExecutorReport)
+		}
+		report.Children[e.config.Children[idx].Name] = reporter.Report()
+	}
+	return report
+}
+
+// Metadata returns the aggregated metadata of children. The expected total
+// is the sum and the expected duration is the longest one of children. So
+// are the dispatch blocked times.
+func (e *CompositeExecutor) Metadata() ExecutorMetadata {
+	res := ExecutorMetadata{}
+
+	children := make([]map[string]interface{}, 0, len(e.children))
+	for idx, child := range e.children {
+		md := child.Metadata()
+
+		res.ExpectedTotal += md.ExpectedTotal
+		res.ExpectedDuration = max(res.ExpectedDuration, md.ExpectedDuration)
+		res.DispatchBlockedTime += md.DispatchBlockedTime
+		res.MaxDispatchBlockedTime = max(res.MaxDispatchBlockedTime, md.MaxDispatchBlockedTime)
+
+		children = append(children, map[string]interface{}{
+			"name":              e.config.Children[idx].Name,
+			"expected_total":    md.ExpectedTotal,
+			"expected_duration": md.ExpectedDuration.String(),
+			"custom":            md.Custom,
+		})
+	}
+
+	res.Custom = map[string]interface{}{
+		"mode":     string(types.ModeComposite),
+		"children": children,
+	}
+	return res
+}
+
+// GetRateLimiter returns nil because each child's rate limiter is applied
+// before multiplexing.
+func (e *CompositeExecutor) GetRateLimiter() RateLimiter {
+	return nil
+}
+
+// GetExecutionContext returns a simple cancellable context. The duration is
+// controlled by children.
+func (e *CompositeExecutor) GetExecutionContext(baseCtx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithCancel(baseCtx)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompositeExecutor(t *testing.T) {
+	originRandom, originExact := createRequestBuilderFunc, createExactRequestBuilderFunc
+	defer func() {
+		createRequestBuilderFunc, createExactRequestBuilderFunc = originRandom, originExact
+	}()
+
+	// The size tells which child the request comes from.
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{size: 1}, nil
+	}
+	createExactRequestBuilderFunc = func(*types.ExactRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{size: 2}, nil
+	}
+
+	exactReqs := []types.ExactRequest{
+		{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "a"},
+		{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "b"},
+		{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "c"},
+	}
+	config := &types.CompositeConfig{
+		Children: []types.CompositeChild{
+			{
+				Name: "background",
+				Mode: types.ModeWeightedRandom,
+				ModeConfig: &types.WeightedRandomConfig{
+					Rate:  100,
+					Total: 20,
+					Requests: []*types.WeightedRequest{
+						{
+							Shares: 1,
+							StaleList: &types.RequestList{
+								KubeGroupVersionResource: types.KubeGroupVersionResource{
+									Version:  "v1",
+									Resource: "pods",
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				Mode: types.ModeTimeSeries,
+				ModeConfig: &types.TimeSeriesConfig{
+					Interval: "100ms",
+					Buckets: []types.RequestBucket{
+						{StartTime: 0, Requests: exactReqs},
+						{StartTime: 0.1, Requests: exactReqs},
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, config.Validate(nil))
+	assert.Equal(t, "child-1", config.Children[1].Name)
+
+	exec, err := CreateExecutor(&types.LoadProfileSpec{
+		Mode:       types.ModeComposite,
+		ModeConfig: config,
+	})
+	require.NoError(t, err)
+	defer exec.Stop()
+
+	assert.Nil(t, exec.GetRateLimiter())
+
+	md := exec.Metadata()
+	assert.Equal(t, 26, md.ExpectedTotal)
+	assert.Equal(t, 100*time.Millisecond, md.ExpectedDuration)
+	assert.Len(t, md.Custom["children"], 2)
+
+	var fromRandom, fromExact int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for builder := range exec.Chan() {
+			if builder.(*fakeCacheBuilder).size == 1 {
+				atomic.AddInt64(&fromRandom, 1)
+			} else {
+				atomic.AddInt64(&fromExact, 1)
+			}
+		}
+	}()
+
+	start := time.Now()
+	require.NoError(t, exec.Run(context.TODO()))
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&fromRandom) == 20 && atomic.LoadInt64(&fromExact) == 6
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The background child's rate limiter is applied.
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	exec.Stop()
+	exec.Stop()
+	<-done
+}
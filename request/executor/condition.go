@@ -0,0 +1,94 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/Azure/kperf/api/types"
+	"k8s.io/client-go/rest"
+)
+
+// CacheSizer is implemented by request builders which cache created objects,
+// like postDel.
+type CacheSizer interface {
+	// CacheSize returns the number of cached objects.
+	CacheSize() int
+}
+
+// conditionalBuilder wraps RESTRequestBuilder with types.RequestCondition.
+type conditionalBuilder struct {
+	RESTRequestBuilder
+	condition *types.RequestCondition
+	// maxConcurrency is WeightedRequest.MaxConcurrency.
+	maxConcurrency int
+
+	// inflight is the number of picked requests which are not done yet.
+	inflight int64
+	// capped counts the picks skipped because of maxConcurrency.
+	capped int64
+}
+
+// met returns true if all the conditions are met.
+func (b *conditionalBuilder) met() bool {
+	c := b.condition
+	if c.IfCacheNonEmpty || c.IfCacheSize != nil {
+		size := 0
+		if cs, ok := b.RESTRequestBuilder.(CacheSizer); ok {
+			size = cs.CacheSize()
+		}
+		if c.IfCacheNonEmpty && size == 0 {
+			return false
+		}
+		if c.IfCacheSize != nil && size < *c.IfCacheSize {
+			return false
+		}
+	}
+
+	inflight := atomic.LoadInt64(&b.inflight)
+	if c.MaxConcurrent > 0 && inflight >= int64(c.MaxConcurrent) {
+		return false
+	}
+	if b.maxConcurrency > 0 && inflight >= int64(b.maxConcurrency) {
+		atomic.AddInt64(&b.capped, 1)
+		return false
+	}
+	return true
+}
+
+// pick marks that request is picked and will be executed.
+func (b *conditionalBuilder) pick() {
+	atomic.AddInt64(&b.inflight, 1)
+}
+
+// Build implements RESTRequestBuilder.
+func (b *conditionalBuilder) Build(cli rest.Interface) Requester {
+	return &conditionalRequester{
+		Requester: b.RESTRequestBuilder.Build(cli),
+		builder:   b,
+	}
+}
+
+// conditionalRequester decreases builder's in-flight counter once it's done.
+type conditionalRequester struct {
+	Requester
+	builder *conditionalBuilder
+}
+
+// Do implements Requester.
+func (r *conditionalRequester) Do(ctx context.Context) (int64, error) {
+	defer atomic.AddInt64(&r.builder.inflight, -1)
+	return r.Requester.Do(ctx)
+}
+
+// Unwrap implements WrappedRequester.
+func (r *conditionalRequester) Unwrap() Requester {
+	return r.Requester
+}
+
+// Rewrap implements WrappedRequester.
+func (r *conditionalRequester) Rewrap(req Requester) {
+	r.Requester = req
+}
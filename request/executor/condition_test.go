@@ -0,0 +1,129 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+type fakeRequester struct{}
+
+func (fakeRequester) Method() string                    { return "GET" }
+func (fakeRequester) URL() *url.URL                     { return &url.URL{} }
+func (fakeRequester) MaskedURL() *url.URL               { return &url.URL{} }
+func (fakeRequester) Timeout(time.Duration)             {}
+func (fakeRequester) Do(context.Context) (int64, error) { return 0, nil }
+
+type fakeCacheBuilder struct {
+	size int
+}
+
+func (b *fakeCacheBuilder) Build(rest.Interface) Requester { return fakeRequester{} }
+func (b *fakeCacheBuilder) CacheSize() int                 { return b.size }
+
+func TestConditionalBuilder(t *testing.T) {
+	inner := &fakeCacheBuilder{}
+	cb := &conditionalBuilder{
+		RESTRequestBuilder: inner,
+		condition: &types.RequestCondition{
+			IfCacheNonEmpty: true,
+			MaxConcurrent:   1,
+		},
+	}
+	assert.False(t, cb.met(), "cache is empty")
+
+	inner.size = 1
+	assert.True(t, cb.met())
+
+	cb.pick()
+	assert.False(t, cb.met(), "reach max concurrent")
+
+	req := cb.Build(nil)
+	_, err := req.Do(context.TODO())
+	require.NoError(t, err)
+	assert.True(t, cb.met(), "request is done")
+
+	minSize := 2
+	cb.condition = &types.RequestCondition{IfCacheSize: &minSize}
+	assert.False(t, cb.met())
+	inner.size = 2
+	assert.True(t, cb.met())
+}
+
+func TestWeightedRandomExecutorConditionNotMet(t *testing.T) {
+	inner := &fakeCacheBuilder{}
+	exec := &WeightedRandomExecutor{
+		config: &types.WeightedRandomConfig{MaxRetryPicks: 3},
+		shares: []int{1},
+		reqBuilders: []RESTRequestBuilder{
+			&conditionalBuilder{
+				RESTRequestBuilder: inner,
+				condition:          &types.RequestCondition{IfCacheNonEmpty: true},
+			},
+		},
+	}
+
+	_, builder := exec.randomPick()
+	assert.Nil(t, builder)
+	assert.Equal(t, int64(4), exec.Metadata().Custom["condition_not_met_count"])
+
+	inner.size = 1
+	_, builder = exec.randomPick()
+	assert.NotNil(t, builder)
+	assert.Equal(t, int64(4), exec.Metadata().Custom["condition_not_met_count"])
+}
+
+func TestWeightedRandomExecutorMaxConcurrency(t *testing.T) {
+	capped := &conditionalBuilder{
+		RESTRequestBuilder: &fakeCacheBuilder{},
+		condition:          &types.RequestCondition{},
+		maxConcurrency:     1,
+	}
+	cheap := &fakeCacheBuilder{}
+
+	requests := []*types.WeightedRequest{
+		{Shares: 1, QuorumList: &types.RequestList{}},
+		{Shares: 1, StaleGet: &types.RequestGet{}},
+	}
+	exec := &WeightedRandomExecutor{
+		config:      &types.WeightedRandomConfig{Requests: requests},
+		requests:    requests,
+		shares:      []int{1, 1},
+		reqBuilders: []RESTRequestBuilder{capped, cheap},
+		counts:      make([]int64, 2),
+		schedule:    []int{0, 0, 1},
+	}
+
+	idx, builder := exec.scheduledPick(0)
+	assert.Equal(t, 0, idx)
+	req := builder.Build(nil)
+
+	// The capped type is skipped and swapped with the cheap one.
+	idx, builder = exec.scheduledPick(1)
+	assert.Equal(t, 1, idx)
+	assert.Equal(t, cheap, builder)
+	assert.Equal(t, []int{0, 1, 0}, exec.schedule)
+
+	_, builder = exec.scheduledPick(2)
+	assert.Nil(t, builder)
+
+	_, err := req.Do(context.TODO())
+	require.NoError(t, err)
+	idx, builder = exec.scheduledPick(2)
+	assert.Equal(t, 0, idx)
+	assert.NotNil(t, builder)
+
+	report := exec.Report().WeightedRandom
+	assert.Equal(t, int64(2), report.Requests[0].CapSkipped)
+	assert.Equal(t, int64(0), report.Requests[1].CapSkipped)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// dispatchTracker measures the time executor is blocked on sending request
+// builders to workers. The send blocks if all the workers are busy, which
+// delays the following requests. It's safe for concurrent use.
+type dispatchTracker struct {
+	total atomic.Int64
+	max   atomic.Int64
+}
+
+// observe records one send which is blocked for d.
+func (t *dispatchTracker) observe(d time.Duration) {
+	t.total.Add(int64(d))
+	for {
+		cur := t.max.Load()
+		if int64(d) <= cur || t.max.CompareAndSwap(cur, int64(d)) {
+			return
+		}
+	}
+}
+
+// since records one send which is started at start.
+func (t *dispatchTracker) since(start time.Time) {
+	t.observe(time.Since(start))
+}
+
+// apply sets the cumulative and max blocked time to metadata.
+func (t *dispatchTracker) apply(md *ExecutorMetadata) {
+	md.DispatchBlockedTime = time.Duration(t.total.Load())
+	md.MaxDispatchBlockedTime = time.Duration(t.max.Load())
+}
+
+// runWindow records when executor starts and finishes sending requests. It's
+// safe for concurrent use.
+type runWindow struct {
+	// start and end are in unix nanoseconds. Zero means unset.
+	start atomic.Int64
+	end   atomic.Int64
+}
+
+// begin records now as start time if it isn't set yet.
+func (w *runWindow) begin(now time.Time) {
+	w.start.CompareAndSwap(0, now.UnixNano())
+}
+
+// finish records now as end time.
+func (w *runWindow) finish(now time.Time) {
+	w.end.Store(now.UnixNano())
+}
+
+// apply sets the start and end time to metadata.
+func (w *runWindow) apply(md *ExecutorMetadata) {
+	if start := w.start.Load(); start != 0 {
+		md.StartTime = time.Unix(0, start)
+	}
+	if end := w.end.Load(); end != 0 {
+		md.EndTime = time.Unix(0, end)
+	}
+}
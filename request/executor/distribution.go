@@ -0,0 +1,101 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// distributionStatsInterval is the interval to emit DistributionSnapshot.
+var distributionStatsInterval = time.Second
+
+// DistributionSnapshot is the distribution of picked requests by type, which
+// should converge to the theoretical weights for a large number of picks.
+type DistributionSnapshot struct {
+	// RequestTypeCounts is the number of picks of each request type since
+	// last snapshot.
+	RequestTypeCounts map[string]int64
+	// TheoreticalWeights is the ratio of each request type's shares to the
+	// total shares.
+	TheoreticalWeights map[string]float64
+}
+
+// distributionStats accumulates the picks of each request type and emits
+// them as DistributionSnapshot.
+type distributionStats struct {
+	ch        chan DistributionSnapshot
+	closeOnce sync.Once
+
+	mu sync.Mutex
+	// pending is the number of picks of each request type which aren't
+	// emitted yet.
+	pending map[string]int64
+}
+
+func newDistributionStats() *distributionStats {
+	return &distributionStats{
+		ch:      make(chan DistributionSnapshot, 1),
+		pending: map[string]int64{},
+	}
+}
+
+// add records one pick of the request type.
+func (s *distributionStats) add(typ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending[typ]++
+}
+
+// emit sends the pending picks with weights without blocking. If the last
+// snapshot isn't received yet, it's merged into the new one so that no pick
+// is lost.
+func (s *distributionStats) emit(weights map[string]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int64, len(weights))
+	for typ := range weights {
+		counts[typ] = 0
+	}
+	select {
+	case last := <-s.ch:
+		for typ, c := range last.RequestTypeCounts {
+			counts[typ] += c
+		}
+	default:
+	}
+	for typ, c := range s.pending {
+		counts[typ] += c
+	}
+	s.pending = map[string]int64{}
+
+	// NOTE: It doesn't block because emit is the only sender and the
+	// channel has been drained above.
+	s.ch <- DistributionSnapshot{
+		RequestTypeCounts:  counts,
+		TheoreticalWeights: weights,
+	}
+}
+
+// run emits snapshot on the interval until ctx is done. Then it emits the
+// last snapshot and closes the channel.
+func (s *distributionStats) run(ctx context.Context, weightsFn func() map[string]float64) {
+	defer s.closeOnce.Do(func() { close(s.ch) })
+
+	ticker := time.NewTicker(distributionStatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.emit(weightsFn())
+		case <-ctx.Done():
+			s.emit(weightsFn())
+			return
+		}
+	}
+}
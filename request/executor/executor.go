@@ -5,6 +5,7 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"net/url"
 	"time"
 
@@ -27,6 +28,19 @@ type Requester interface {
 	Do(context.Context) (bytes int64, err error)
 }
 
+// WrappedRequester is Requester wrapping another one, like the one releasing
+// in-flight token once it's done. Schedule follows Unwrap to find the optional
+// interfaces of the wrapped requester, like the watch stats, so that the
+// wrappers don't have to forward them.
+type WrappedRequester interface {
+	Requester
+	// Unwrap returns the wrapped requester.
+	Unwrap() Requester
+	// Rewrap replaces the wrapped requester before Do, like converting
+	// it into the one measuring tiered latency.
+	Rewrap(Requester)
+}
+
 // Executor generates requests according to a specific execution mode.
 // This interface abstracts different request generation strategies,
 // allowing the scheduler to be mode-agnostic.
@@ -57,6 +71,58 @@ type Executor interface {
 	GetExecutionContext(baseCtx context.Context) (context.Context, context.CancelFunc)
 }
 
+// ResponseObserver is implemented by executors which need feedback of
+// requests, like adaptive mode. The scheduler calls OnResponse after each
+// request. It's called by workers concurrently so it must be cheap and
+// non-blocking.
+type ResponseObserver interface {
+	OnResponse(method string, latency time.Duration, err error)
+}
+
+// Reporter is implemented by executors which produce mode-specific report,
+// like the sustainable rate found by adaptive mode.
+type Reporter interface {
+	// Report returns the report. It's called after Run returns.
+	Report() *types.ExecutorReport
+}
+
+// ErrUpdateRequestsNotSupported is returned if executor can't update
+// requests while running.
+var ErrUpdateRequestsNotSupported = errors.New("updating requests isn't supported by executor")
+
+// RequestUpdater is implemented by executors whose requests can be replaced
+// while running, like weighted-random mode.
+type RequestUpdater interface {
+	// UpdateRequests replaces the requests to send. It's safe to call
+	// concurrently with Run.
+	UpdateRequests(requests []*types.WeightedRequest) error
+}
+
+// ErrUpdateRateNotSupported is returned if executor can't update rate while
+// running.
+var ErrUpdateRateNotSupported = errors.New("updating rate isn't supported by executor")
+
+// RateUpdater is implemented by executors whose rate can be changed while
+// running, like weighted-random mode.
+type RateUpdater interface {
+	// UpdateRate changes the requests per second. Zero means unlimited.
+	// It's safe to call concurrently with Run.
+	UpdateRate(qps float64) error
+}
+
+// ErrDistributionStatsNotSupported is returned if executor doesn't report
+// the distribution of picked requests.
+var ErrDistributionStatsNotSupported = errors.New("distribution stats isn't supported by executor")
+
+// DistributionStatsProvider is implemented by executors which report the
+// distribution of picked requests while running, like weighted-random mode.
+type DistributionStatsProvider interface {
+	// DistributionStats returns the channel which emits snapshot every
+	// second while running. It's closed after Run returns. The picks are
+	// accumulated into next snapshot if the receiver falls behind.
+	DistributionStats() <-chan DistributionSnapshot
+}
+
 // RateLimiter is an interface for rate limiting.
 // This allows executors to provide custom rate limiting strategies.
 type RateLimiter interface {
@@ -72,6 +138,23 @@ type ExecutorMetadata struct {
 	// ExpectedDuration is the expected duration of execution (0 if unbounded).
 	ExpectedDuration time.Duration
 
+	// DispatchBlockedTime is the cumulative time executor is blocked on
+	// sending requests to workers so far. Nonzero means workers can't keep
+	// up and the requests are delayed.
+	DispatchBlockedTime time.Duration
+
+	// MaxDispatchBlockedTime is the longest time of one blocked send so far.
+	MaxDispatchBlockedTime time.Duration
+
+	// StartTime is the wall-clock time when executor starts to send the
+	// first request. It's zero if executor isn't running yet or doesn't
+	// track it.
+	StartTime time.Time
+
+	// EndTime is the wall-clock time when executor finishes sending the
+	// last request. It's zero if executor is still running.
+	EndTime time.Time
+
 	// Custom contains mode-specific metadata.
 	// This allows modes to provide additional information without changing the interface.
 	// Examples:
@@ -27,6 +27,10 @@ func NewExecutorFactory() *ExecutorFactory {
 
 	f.Register(string(types.ModeWeightedRandom), NewWeightedRandomExecutor)
 	f.Register(string(types.ModeTimeSeries), NewTimeSeriesExecutor)
+	f.Register(string(types.ModeAdaptive), NewAdaptiveExecutor)
+	f.Register(string(types.ModeBurst), NewBurstExecutor)
+	f.Register(string(types.ModeTrace), NewTraceExecutor)
+	f.Register(string(types.ModeComposite), f.newCompositeExecutor)
 
 	return f
 }
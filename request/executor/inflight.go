@@ -0,0 +1,96 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"k8s.io/client-go/rest"
+)
+
+// inFlightLimiter caps the number of dispatched requests which are not done
+// yet.
+type inFlightLimiter struct {
+	tokens chan struct{}
+	count  int64
+}
+
+// newInFlightLimiter returns nil if max is zero, which means no limit.
+func newInFlightLimiter(max int) *inFlightLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &inFlightLimiter{tokens: make(chan struct{}, max)}
+}
+
+// acquire blocks until a token is available or ctx is done.
+func (l *inFlightLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.tokens <- struct{}{}:
+		atomic.AddInt64(&l.count, 1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release returns one token.
+func (l *inFlightLimiter) release() {
+	atomic.AddInt64(&l.count, -1)
+	<-l.tokens
+}
+
+// inFlight returns the number of acquired tokens.
+func (l *inFlightLimiter) inFlight() int64 {
+	return atomic.LoadInt64(&l.count)
+}
+
+// wrap returns the builder which releases the acquired token once the
+// request is done.
+func (l *inFlightLimiter) wrap(builder RESTRequestBuilder) RESTRequestBuilder {
+	return &inFlightBuilder{
+		RESTRequestBuilder: builder,
+		limiter:            l,
+	}
+}
+
+// inFlightBuilder wraps RESTRequestBuilder holding one token of
+// inFlightLimiter.
+type inFlightBuilder struct {
+	RESTRequestBuilder
+	limiter *inFlightLimiter
+}
+
+// Build implements RESTRequestBuilder.
+func (b *inFlightBuilder) Build(cli rest.Interface) Requester {
+	return &inFlightRequester{
+		Requester: b.RESTRequestBuilder.Build(cli),
+		limiter:   b.limiter,
+	}
+}
+
+// inFlightRequester releases the token once it's done.
+type inFlightRequester struct {
+	Requester
+	limiter *inFlightLimiter
+	once    sync.Once
+}
+
+// Do implements Requester.
+func (r *inFlightRequester) Do(ctx context.Context) (int64, error) {
+	defer r.once.Do(r.limiter.release)
+	return r.Requester.Do(ctx)
+}
+
+// Unwrap implements WrappedRequester.
+func (r *inFlightRequester) Unwrap() Requester {
+	return r.Requester
+}
+
+// Rewrap implements WrappedRequester.
+func (r *inFlightRequester) Rewrap(req Requester) {
+	r.Requester = req
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"fmt"
+
+	"github.com/Azure/kperf/api/types"
+)
+
+// Plugin provides a custom execution mode.
+//
+// The plugin's constructor receives LoadProfileSpec whose Mode is the plugin's
+// Mode. The ModeConfig is the plugin's own configuration type, which embeds
+// types.PluginModeConfig. The returned Executor must follow the contract of
+// Executor interface, especially that Stop closes the channel returned by
+// Chan and it's idempotent.
+type Plugin interface {
+	// Mode returns the execution mode handled by this plugin.
+	Mode() types.ExecutionMode
+	// Constructor returns the function to create executor for the mode.
+	Constructor() ExecutorConstructor
+}
+
+// ModeConfigPlugin is implemented by plugins whose mode config can be
+// loaded from load profile.
+type ModeConfigPlugin interface {
+	Plugin
+	// NewModeConfig returns pointer to the empty mode config.
+	NewModeConfig() types.ModeConfig
+}
+
+// RegisterPlugin registers a plugin's mode constructor. If the plugin
+// implements ModeConfigPlugin, its mode config is registered by
+// types.RegisterModeConfig as well, which is global.
+func (f *ExecutorFactory) RegisterPlugin(plugin Plugin) {
+	f.RegisterMode(plugin.Mode(), plugin.Constructor())
+	if p, ok := plugin.(ModeConfigPlugin); ok {
+		types.RegisterModeConfig(p.Mode(), p.NewModeConfig)
+	}
+}
+
+// RegisterAll registers all the plugins' mode constructors.
+func (f *ExecutorFactory) RegisterAll(plugins []Plugin) {
+	for _, plugin := range plugins {
+		f.RegisterPlugin(plugin)
+	}
+}
+
+// RegisterPlugin allows external packages to register plugins into the
+// default factory.
+func RegisterPlugin(plugin Plugin) {
+	defaultFactory.RegisterPlugin(plugin)
+}
+
+// NewRequestBuilder creates a request builder from WeightedRequest. It's used
+// by plugins to reuse built-in request types.
+func NewRequestBuilder(r *types.WeightedRequest, maxRetries int) (RESTRequestBuilder, error) {
+	if createRequestBuilderFunc == nil {
+		return nil, fmt.Errorf("request builder factory not initialized")
+	}
+	return createRequestBuilderFunc(r, maxRetries)
+}
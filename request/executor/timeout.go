@@ -0,0 +1,115 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+)
+
+// ErrMaxDurationExceeded is the cause of execution context's deadline set by
+// NewTimeoutExecutor. It tells the hard deadline apart from mode's own
+// duration, which is the expected end of execution.
+var ErrMaxDurationExceeded = errors.New("max duration exceeded")
+
+// timeoutExecutor caps the execution of inner executor by timeout.
+type timeoutExecutor struct {
+	inner   Executor
+	timeout time.Duration
+}
+
+// NewTimeoutExecutor wraps inner with a hard deadline. The execution context
+// is always canceled after timeout, even if inner has no duration or longer
+// one. For instance, NewTimeoutExecutor(exec, time.Minute) runs exec for at
+// most one minute.
+func NewTimeoutExecutor(inner Executor, timeout time.Duration) Executor {
+	return &timeoutExecutor{
+		inner:   inner,
+		timeout: timeout,
+	}
+}
+
+// Chan implements Executor.
+func (e *timeoutExecutor) Chan() <-chan RESTRequestBuilder {
+	return e.inner.Chan()
+}
+
+// Run implements Executor.
+func (e *timeoutExecutor) Run(ctx context.Context) error {
+	return e.inner.Run(ctx)
+}
+
+// Stop implements Executor.
+func (e *timeoutExecutor) Stop() {
+	e.inner.Stop()
+}
+
+// Metadata returns inner's metadata with ExpectedDuration capped by timeout.
+func (e *timeoutExecutor) Metadata() ExecutorMetadata {
+	md := e.inner.Metadata()
+	if md.ExpectedDuration == 0 || md.ExpectedDuration > e.timeout {
+		md.ExpectedDuration = e.timeout
+	}
+	return md
+}
+
+// GetRateLimiter implements Executor.
+func (e *timeoutExecutor) GetRateLimiter() RateLimiter {
+	return e.inner.GetRateLimiter()
+}
+
+// GetExecutionContext returns inner's execution context with the deadline,
+// whose cause is ErrMaxDurationExceeded.
+func (e *timeoutExecutor) GetExecutionContext(baseCtx context.Context) (context.Context, context.CancelFunc) {
+	innerCtx, innerCancel := e.inner.GetExecutionContext(baseCtx)
+	ctx, cancel := context.WithTimeoutCause(innerCtx, e.timeout, ErrMaxDurationExceeded)
+	return ctx, func() {
+		cancel()
+		innerCancel()
+	}
+}
+
+// OnResponse implements ResponseObserver if inner does.
+func (e *timeoutExecutor) OnResponse(method string, latency time.Duration, err error) {
+	if observer, ok := e.inner.(ResponseObserver); ok {
+		observer.OnResponse(method, latency, err)
+	}
+}
+
+// UpdateRequests implements RequestUpdater. It fails if inner isn't
+// RequestUpdater.
+func (e *timeoutExecutor) UpdateRequests(requests []*types.WeightedRequest) error {
+	if updater, ok := e.inner.(RequestUpdater); ok {
+		return updater.UpdateRequests(requests)
+	}
+	return ErrUpdateRequestsNotSupported
+}
+
+// UpdateRate implements RateUpdater. It fails if inner isn't RateUpdater.
+func (e *timeoutExecutor) UpdateRate(qps float64) error {
+	if updater, ok := e.inner.(RateUpdater); ok {
+		return updater.UpdateRate(qps)
+	}
+	return ErrUpdateRateNotSupported
+}
+
+// DistributionStats implements DistributionStatsProvider. It returns nil if
+// inner isn't DistributionStatsProvider.
+func (e *timeoutExecutor) DistributionStats() <-chan DistributionSnapshot {
+	if provider, ok := e.inner.(DistributionStatsProvider); ok {
+		return provider.DistributionStats()
+	}
+	return nil
+}
+
+// Report implements Reporter. It returns nil if inner isn't Reporter.
+func (e *timeoutExecutor) Report() *types.ExecutorReport {
+	if reporter, ok := e.inner.(Reporter); ok {
+		return reporter.Report()
+	}
+	return nil
+}
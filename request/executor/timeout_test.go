@@ -0,0 +1,76 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutExecutor(t *testing.T) {
+	origin := createRequestBuilderFunc
+	defer func() { createRequestBuilderFunc = origin }()
+
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	gvr := types.KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+	for name, tc := range map[string]struct {
+		duration         types.Seconds
+		expectedDuration time.Duration
+	}{
+		"unbounded inner": {
+			duration:         0,
+			expectedDuration: 100 * time.Millisecond,
+		},
+		"longer inner": {
+			duration:         60,
+			expectedDuration: 100 * time.Millisecond,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			inner, err := NewWeightedRandomExecutor(&types.LoadProfileSpec{
+				Mode: types.ModeWeightedRandom,
+				ModeConfig: &types.WeightedRandomConfig{
+					Rate:     100,
+					Total:    1 << 30,
+					Duration: tc.duration,
+					Requests: []*types.WeightedRequest{
+						{Shares: 1, StaleList: &types.RequestList{KubeGroupVersionResource: gvr}},
+					},
+				},
+			})
+			require.NoError(t, err)
+
+			exec := NewTimeoutExecutor(inner, 100*time.Millisecond)
+			defer exec.Stop()
+			assert.Equal(t, tc.expectedDuration, exec.Metadata().ExpectedDuration)
+			assert.Equal(t, inner.GetRateLimiter(), exec.GetRateLimiter())
+
+			ctx, cancel := exec.GetExecutionContext(context.Background())
+			defer cancel()
+			deadline, ok := ctx.Deadline()
+			require.True(t, ok)
+			assert.LessOrEqual(t, time.Until(deadline), 100*time.Millisecond)
+
+			go func() {
+				for range exec.Chan() {
+				}
+			}()
+
+			start := time.Now()
+			err = exec.Run(ctx)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.ErrorIs(t, context.Cause(ctx), ErrMaxDurationExceeded)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+}
@@ -6,10 +6,15 @@ package executor
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Azure/kperf/api/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 )
 
 // TimeSeriesExecutor implements Executor for time-series replay mode.
@@ -20,10 +25,22 @@ type TimeSeriesExecutor struct {
 	interval     time.Duration
 	buckets      []types.RequestBucket
 	reqBuilderCh chan RESTRequestBuilder
-	ctx          context.Context
-	cancel       context.CancelFunc
-	wg           sync.WaitGroup
-	once         sync.Once
+	// dispatching is the number of buckets being dispatched.
+	dispatching int64
+	// blocked measures the time blocked on sending requests.
+	blocked dispatchTracker
+	// window records when Run starts and finishes.
+	window runWindow
+	// lags are the dispatch lags of dispatched buckets.
+	lags   []types.BucketDispatchLag
+	lagsMu sync.Mutex
+	// clock is used to wait for target time. It's replaced in tests.
+	clock clock.Clock
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
 }
 
 // NewTimeSeriesExecutor creates a new time series executor from spec.
@@ -47,18 +64,48 @@ func NewTimeSeriesExecutor(spec *types.LoadProfileSpec) (Executor, error) {
 		return nil, fmt.Errorf("invalid interval: %v", err)
 	}
 
+	config.PrepareBuckets()
+	buckets := config.WindowBuckets()
+	if config.BucketFilter != nil {
+		selected, err := config.BucketFilter.Compile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucketFilter: %w", err)
+		}
+		buckets = filterBuckets(buckets, selected)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	return &TimeSeriesExecutor{
 		config:       config,
 		spec:         spec,
 		interval:     interval,
-		buckets:      config.Buckets,
-		reqBuilderCh: make(chan RESTRequestBuilder),
+		buckets:      buckets,
+		clock:        clock.RealClock{},
+		reqBuilderCh: make(chan RESTRequestBuilder, spec.DispatchBufferSize),
 		ctx:          ctx,
 		cancel:       cancel,
 	}, nil
 }
 
+// filterBuckets returns the buckets with the selected requests only. The
+// buckets without selected request are dropped.
+func filterBuckets(buckets []types.RequestBucket, selected func(*types.ExactRequest) bool) []types.RequestBucket {
+	filtered := make([]types.RequestBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		reqs := make([]types.ExactRequest, 0, len(bucket.Requests))
+		for i := range bucket.Requests {
+			if selected(&bucket.Requests[i]) {
+				reqs = append(reqs, bucket.Requests[i])
+			}
+		}
+		if len(reqs) == 0 {
+			continue
+		}
+		filtered = append(filtered, types.RequestBucket{StartTime: bucket.StartTime, Requests: reqs})
+	}
+	return filtered
+}
+
 // Chan returns the channel that produces request builders.
 func (e *TimeSeriesExecutor) Chan() <-chan RESTRequestBuilder {
 	return e.reqBuilderCh
@@ -69,39 +116,272 @@ func (e *TimeSeriesExecutor) Run(ctx context.Context) error {
 	e.wg.Add(1)
 	defer e.wg.Done()
 
-	startTime := time.Now()
+	startTime := e.clock.Now()
+	e.window.begin(startTime)
+	defer func() { e.window.finish(e.clock.Now()) }()
 
-	for _, bucket := range e.buckets {
-		targetTime := startTime.Add(time.Duration(bucket.StartTime * float64(time.Second)))
+	for round := 0; round <= e.config.Repeat; round++ {
+		if err := e.replay(ctx, round, startTime.Add(time.Duration(round)*e.replayDuration())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replay dispatches all the buckets once. The bucket's start time is relative
+// to the given startTime.
+func (e *TimeSeriesExecutor) replay(ctx context.Context, round int, startTime time.Time) error {
+	if e.config.BucketOverlapMode == types.BucketOverlapModeConcurrent {
+		return e.replayConcurrently(ctx, round, startTime)
+	}
+
+	prefetched := e.prefetch(0)
+	for idx := range e.buckets {
+		bucket := &e.buckets[idx]
+		targetTime := startTime.Add(e.scale(time.Duration(bucket.StartTime * float64(time.Second))))
+
+		builders, err := e.waitPrefetched(ctx, prefetched)
+		if err != nil {
+			return err
+		}
+		prefetched = e.prefetch(idx + 1)
+
+		if late := e.clock.Since(targetTime); late > 0 &&
+			e.config.BucketOverlapMode == types.BucketOverlapModeBestEffort {
+			klog.Warningf("Bucket %d (startTime=%v) is late by %v, dispatching immediately",
+				idx, bucket.StartTime, late)
+		}
 
 		// Wait until target time
-		select {
-		case <-time.After(time.Until(targetTime)):
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-e.ctx.Done():
-			return e.ctx.Err()
+		if err := e.waitUntil(ctx, targetTime); err != nil {
+			return err
 		}
 
-		// Dispatch requests in this bucket
-		for _, req := range bucket.Requests {
-			builder := e.createBuilderForExactRequest(&req)
-			if builder == nil {
-				continue
-			}
+		if err := e.dispatch(ctx, round, idx, targetTime, builders); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replayConcurrently dispatches each bucket in its own goroutine at its
+// target time so that slow bucket doesn't delay the following buckets. The
+// number of buckets being dispatched is capped by BucketConcurrency.
+func (e *TimeSeriesExecutor) replayConcurrently(ctx context.Context, round int, startTime time.Time) error {
+	var sem chan struct{}
+	if e.config.BucketConcurrency > 0 {
+		sem = make(chan struct{}, e.config.BucketConcurrency)
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	prefetched := e.prefetch(0)
+	for idx := range e.buckets {
+		bucket := &e.buckets[idx]
+		targetTime := startTime.Add(e.scale(time.Duration(bucket.StartTime * float64(time.Second))))
+
+		builders, err := e.waitPrefetched(ctx, prefetched)
+		if err != nil {
+			return err
+		}
+		prefetched = e.prefetch(idx + 1)
+
+		// Wait until target time
+		if err := e.waitUntil(ctx, targetTime); err != nil {
+			return err
+		}
+
+		if sem != nil {
 			select {
-			case e.reqBuilderCh <- builder:
+			case sem <- struct{}{}:
 			case <-ctx.Done():
 				return ctx.Err()
 			case <-e.ctx.Done():
 				return e.ctx.Err()
 			}
 		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+
+			if err := e.dispatch(ctx, round, idx, targetTime, builders); err != nil {
+				klog.V(5).Infof("Bucket %d (startTime=%v) is interrupted: %v", idx, bucket.StartTime, err)
+			}
+		}()
 	}
+	return nil
+}
 
+// waitUntil blocks until the given time or either ctx or executor is done.
+func (e *TimeSeriesExecutor) waitUntil(ctx context.Context, t time.Time) error {
+	if d := t.Sub(e.clock.Now()); d > 0 {
+		select {
+		case <-e.clock.After(d):
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.ctx.Done():
+			return e.ctx.Err()
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return e.ctx.Err()
+}
+
+// prefetch creates the request builders of the idx-th bucket in background,
+// so that it doesn't eat into the dispatch window of a bucket with lots of
+// requests. It returns nil if there is no such bucket.
+func (e *TimeSeriesExecutor) prefetch(idx int) <-chan []RESTRequestBuilder {
+	if idx >= len(e.buckets) {
+		return nil
+	}
+
+	ch := make(chan []RESTRequestBuilder, 1)
+	go func() {
+		bucket := &e.buckets[idx]
+		builders := make([]RESTRequestBuilder, 0, len(bucket.Requests))
+		for i := range bucket.Requests {
+			if builder := e.createBuilderForExactRequest(&bucket.Requests[i]); builder != nil {
+				builders = append(builders, builder)
+			}
+		}
+		ch <- builders
+	}()
+	return ch
+}
+
+// waitPrefetched waits for the builders created by prefetch.
+func (e *TimeSeriesExecutor) waitPrefetched(ctx context.Context, ch <-chan []RESTRequestBuilder) ([]RESTRequestBuilder, error) {
+	select {
+	case builders := <-ch:
+		return builders, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-e.ctx.Done():
+		return nil, e.ctx.Err()
+	}
+}
+
+// dispatch sends the builders of the idx-th bucket and records how late
+// they are sent compared to their target time. The first one's target time
+// is the given targetTime. The others' are the same, or spread evenly over
+// spreadWindow if Spread is uniform.
+func (e *TimeSeriesExecutor) dispatch(ctx context.Context, round, idx int, targetTime time.Time, builders []RESTRequestBuilder) error {
+	atomic.AddInt64(&e.dispatching, 1)
+	defer atomic.AddInt64(&e.dispatching, -1)
+
+	bucket := &e.buckets[idx]
+
+	var step time.Duration
+	if e.config.Spread == types.SpreadModeUniform && len(builders) > 0 {
+		step = e.spreadWindow(idx) / time.Duration(len(builders))
+	}
+
+	lag := types.BucketDispatchLag{Round: round, StartTime: bucket.StartTime}
+	defer func() {
+		if lag.Requests > 0 {
+			e.recordLag(lag)
+		}
+	}()
+
+	for i, builder := range builders {
+		reqTargetTime := targetTime.Add(time.Duration(i) * step)
+		if i > 0 && step > 0 {
+			if err := e.waitUntil(ctx, reqTargetTime); err != nil {
+				return err
+			}
+		}
+
+		start := time.Now()
+		select {
+		case e.reqBuilderCh <- builder:
+			e.blocked.since(start)
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.ctx.Done():
+			return e.ctx.Err()
+		}
+
+		late := e.clock.Since(reqTargetTime).Seconds()
+		if lag.Requests == 0 {
+			lag.FirstLagSeconds = late
+		}
+		lag.LastLagSeconds = late
+		lag.Requests++
+	}
 	return nil
 }
 
+// spreadWindow returns the scaled duration which the idx-th bucket's
+// requests are spread over. It's the interval, or until the next bucket's
+// start time if it's sooner.
+func (e *TimeSeriesExecutor) spreadWindow(idx int) time.Duration {
+	window := e.interval
+	if idx+1 < len(e.buckets) {
+		gap := time.Duration((e.buckets[idx+1].StartTime - e.buckets[idx].StartTime) * float64(time.Second))
+		window = min(window, gap)
+	}
+	return e.scale(window)
+}
+
+// recordLag appends the dispatch lag of one bucket.
+func (e *TimeSeriesExecutor) recordLag(lag types.BucketDispatchLag) {
+	e.lagsMu.Lock()
+	defer e.lagsMu.Unlock()
+
+	e.lags = append(e.lags, lag)
+}
+
+// Report implements Reporter with the dispatch lag of each bucket.
+func (e *TimeSeriesExecutor) Report() *types.ExecutorReport {
+	e.lagsMu.Lock()
+	lags := slices.Clone(e.lags)
+	e.lagsMu.Unlock()
+
+	// NOTE: The buckets dispatched concurrently may be recorded out of
+	// order.
+	sort.SliceStable(lags, func(i, j int) bool {
+		if lags[i].Round != lags[j].Round {
+			return lags[i].Round < lags[j].Round
+		}
+		return lags[i].StartTime < lags[j].StartTime
+	})
+
+	report := &types.TimeSeriesReport{Buckets: lags}
+	for _, lag := range lags {
+		report.MaxLagSeconds = max(report.MaxLagSeconds, lag.LastLagSeconds)
+	}
+	return &types.ExecutorReport{TimeSeries: report}
+}
+
+// replayDuration returns the scaled duration of replaying buckets in window
+// once. The next replay starts one interval after the last bucket. The
+// buckets dropped by BucketFilter are still counted so that the replay keeps
+// the cadence of recording.
+func (e *TimeSeriesExecutor) replayDuration() time.Duration {
+	buckets := e.config.WindowBuckets()
+	if len(buckets) == 0 {
+		return 0
+	}
+	lastStartTime := time.Duration(buckets[len(buckets)-1].StartTime * float64(time.Second))
+	return e.scale(lastStartTime + e.interval)
+}
+
+// scale applies TimeScale to the given duration.
+func (e *TimeSeriesExecutor) scale(d time.Duration) time.Duration {
+	if e.config.TimeScale <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * e.config.TimeScale)
+}
+
 // Stop gracefully stops the executor.
 func (e *TimeSeriesExecutor) Stop() {
 	e.once.Do(func() {
@@ -122,16 +402,33 @@ func (e *TimeSeriesExecutor) Metadata() ExecutorMetadata {
 	if len(e.buckets) > 0 {
 		maxDuration = e.buckets[len(e.buckets)-1].StartTime
 	}
+	expectedDuration := time.Duration(e.config.Repeat)*e.replayDuration() +
+		e.scale(time.Duration(maxDuration*float64(time.Second)))
 
-	return ExecutorMetadata{
-		ExpectedTotal:    totalRequests,
-		ExpectedDuration: time.Duration(maxDuration * float64(time.Second)),
+	md := ExecutorMetadata{
+		ExpectedTotal:    totalRequests * (e.config.Repeat + 1),
+		ExpectedDuration: expectedDuration,
 		Custom: map[string]interface{}{
 			"mode":         string(types.ModeTimeSeries),
 			"bucket_count": len(e.buckets),
 			"interval":     e.interval.String(),
+			"repeat":       e.config.Repeat,
+			"time_scale":   e.config.TimeScale,
+			"overlap_mode": string(e.config.BucketOverlapMode),
+			"spread":       string(e.spread()),
 		},
 	}
+	e.blocked.apply(&md)
+	e.window.apply(&md)
+	return md
+}
+
+// spread returns the configured spread mode. Empty means none.
+func (e *TimeSeriesExecutor) spread() types.SpreadMode {
+	if e.config.Spread == "" {
+		return types.SpreadModeNone
+	}
+	return e.config.Spread
 }
 
 // createBuilderForExactRequest creates a request builder from an ExactRequest.
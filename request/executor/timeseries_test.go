@@ -0,0 +1,453 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestTimeSeriesBucketOverlapMode(t *testing.T) {
+	origin := createExactRequestBuilderFunc
+	defer func() { createExactRequestBuilderFunc = origin }()
+
+	createExactRequestBuilderFunc = func(*types.ExactRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	for _, tc := range []struct {
+		mode        types.BucketOverlapMode
+		concurrency int
+		expected    int64
+	}{
+		{mode: types.BucketOverlapModeSequential, expected: 1},
+		{mode: types.BucketOverlapModeBestEffort, expected: 1},
+		{mode: types.BucketOverlapModeConcurrent, expected: 10},
+		{mode: types.BucketOverlapModeConcurrent, concurrency: 4, expected: 4},
+	} {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			buckets := make([]types.RequestBucket, 10)
+			for i := range buckets {
+				buckets[i] = types.RequestBucket{
+					StartTime: 0,
+					Requests: []types.ExactRequest{
+						{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "a"},
+					},
+				}
+			}
+
+			exec, err := NewTimeSeriesExecutor(&types.LoadProfileSpec{
+				Mode: types.ModeTimeSeries,
+				ModeConfig: &types.TimeSeriesConfig{
+					Interval:          "1s",
+					Buckets:           buckets,
+					BucketOverlapMode: tc.mode,
+					BucketConcurrency: tc.concurrency,
+				},
+			})
+			require.NoError(t, err)
+			e := exec.(*TimeSeriesExecutor)
+
+			runErrCh := make(chan error, 1)
+			go func() { runErrCh <- e.Run(context.TODO()) }()
+
+			// Nobody receives requests yet, so dispatching buckets are
+			// all blocked.
+			require.Eventually(t, func() bool {
+				return atomic.LoadInt64(&e.dispatching) == tc.expected
+			}, 5*time.Second, 10*time.Millisecond)
+			time.Sleep(50 * time.Millisecond)
+			assert.Equal(t, tc.expected, atomic.LoadInt64(&e.dispatching))
+
+			received := 0
+			for received < len(buckets) {
+				<-e.Chan()
+				received++
+			}
+			require.NoError(t, <-runErrCh)
+			e.Stop()
+		})
+	}
+}
+
+func TestTimeSeriesBucketFilter(t *testing.T) {
+	origin := createExactRequestBuilderFunc
+	defer func() { createExactRequestBuilderFunc = origin }()
+
+	methods := make(chan string, 10)
+	createExactRequestBuilderFunc = func(req *types.ExactRequest, _ int) (RESTRequestBuilder, error) {
+		methods <- req.Method
+		return &fakeCacheBuilder{}, nil
+	}
+
+	exec, err := NewTimeSeriesExecutor(&types.LoadProfileSpec{
+		Mode: types.ModeTimeSeries,
+		ModeConfig: &types.TimeSeriesConfig{
+			Interval: "1s",
+			Buckets: []types.RequestBucket{
+				{
+					StartTime: 0,
+					Requests: []types.ExactRequest{
+						{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "a"},
+						{Method: "LIST", Version: "v1", Resource: "pods", Namespace: "default"},
+						{Method: "POST", Version: "v1", Resource: "configmaps", Namespace: "default"},
+						{Method: "GET", Version: "v1", Resource: "pods", Namespace: "kube-system", Name: "b"},
+					},
+				},
+				{
+					StartTime: 0,
+					Requests: []types.ExactRequest{
+						{Method: "LIST", Version: "v1", Resource: "pods"},
+					},
+				},
+			},
+			BucketFilter: &types.BucketFilterConfig{MethodRegexp: "GET"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, exec.Metadata().ExpectedTotal)
+	assert.Equal(t, 1, exec.Metadata().Custom["bucket_count"])
+
+	go func() {
+		for range exec.Chan() {
+		}
+	}()
+	require.NoError(t, exec.Run(context.TODO()))
+	exec.Stop()
+
+	close(methods)
+	for method := range methods {
+		assert.Equal(t, "GET", method)
+	}
+}
+
+func TestTimeSeriesWindow(t *testing.T) {
+	origin := createExactRequestBuilderFunc
+	defer func() { createExactRequestBuilderFunc = origin }()
+
+	names := make(chan string, 10)
+	createExactRequestBuilderFunc = func(req *types.ExactRequest, _ int) (RESTRequestBuilder, error) {
+		names <- req.Name
+		return &fakeCacheBuilder{}, nil
+	}
+
+	bucket := func(startTime float64, name string) types.RequestBucket {
+		return types.RequestBucket{
+			StartTime: startTime,
+			Requests: []types.ExactRequest{
+				{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: name},
+			},
+		}
+	}
+	exec, err := NewTimeSeriesExecutor(&types.LoadProfileSpec{
+		Mode: types.ModeTimeSeries,
+		ModeConfig: &types.TimeSeriesConfig{
+			Interval:    "100ms",
+			Buckets:     []types.RequestBucket{bucket(0, "a"), bucket(3600, "b"), bucket(3600.2, "c"), bucket(7200, "d")},
+			Repeat:      1,
+			StartOffset: 3600,
+			EndOffset:   7200,
+		},
+	})
+	require.NoError(t, err)
+
+	// The window is replayed twice, each for 300ms.
+	md := exec.Metadata()
+	assert.Equal(t, 4, md.ExpectedTotal)
+	assert.InDelta(t, 500*time.Millisecond, md.ExpectedDuration, float64(time.Millisecond))
+	assert.Equal(t, 2, md.Custom["bucket_count"])
+
+	go func() {
+		for range exec.Chan() {
+		}
+	}()
+	start := time.Now()
+	require.NoError(t, exec.Run(context.TODO()))
+	assert.Less(t, time.Since(start), 5*time.Second)
+	exec.Stop()
+
+	close(names)
+	got := []string{}
+	for name := range names {
+		got = append(got, name)
+	}
+	assert.Equal(t, []string{"b", "c", "b", "c"}, got)
+}
+
+func TestTimeSeriesSortBuckets(t *testing.T) {
+	origin := createExactRequestBuilderFunc
+	defer func() { createExactRequestBuilderFunc = origin }()
+
+	names := make(chan string, 10)
+	createExactRequestBuilderFunc = func(req *types.ExactRequest, _ int) (RESTRequestBuilder, error) {
+		names <- req.Name
+		return &fakeCacheBuilder{}, nil
+	}
+
+	bucket := func(startTime float64, names ...string) types.RequestBucket {
+		b := types.RequestBucket{StartTime: startTime}
+		for _, name := range names {
+			b.Requests = append(b.Requests, types.ExactRequest{
+				Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: name,
+			})
+		}
+		return b
+	}
+	exec, err := NewTimeSeriesExecutor(&types.LoadProfileSpec{
+		Mode: types.ModeTimeSeries,
+		ModeConfig: &types.TimeSeriesConfig{
+			Interval:               "100ms",
+			Buckets:                []types.RequestBucket{bucket(0.2, "c"), bucket(0, "a"), bucket(0.2, "d", "e"), bucket(0.1, "b")},
+			SortBuckets:            true,
+			MergeBucketsAtSameTime: true,
+		},
+	})
+	require.NoError(t, err)
+
+	md := exec.Metadata()
+	assert.Equal(t, 5, md.ExpectedTotal)
+	assert.Equal(t, 3, md.Custom["bucket_count"])
+	assert.True(t, md.StartTime.IsZero())
+	assert.True(t, md.EndTime.IsZero())
+
+	go func() {
+		for range exec.Chan() {
+		}
+	}()
+	before := time.Now()
+	require.NoError(t, exec.Run(context.TODO()))
+	exec.Stop()
+
+	md = exec.Metadata()
+	assert.False(t, md.StartTime.Before(before.Truncate(time.Microsecond)))
+	assert.GreaterOrEqual(t, md.EndTime.Sub(md.StartTime), 200*time.Millisecond)
+
+	close(names)
+	got := []string{}
+	for name := range names {
+		got = append(got, name)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, got)
+}
+
+func TestTimeSeriesDispatchBlocked(t *testing.T) {
+	origin := createExactRequestBuilderFunc
+	defer func() { createExactRequestBuilderFunc = origin }()
+
+	createExactRequestBuilderFunc = func(*types.ExactRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	const delay = 200 * time.Millisecond
+
+	for name, tc := range map[string]struct {
+		bufferSize int
+		blocked    bool
+	}{
+		"unbuffered": {blocked: true},
+		"buffered":   {bufferSize: 3, blocked: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			exec, err := NewTimeSeriesExecutor(&types.LoadProfileSpec{
+				DispatchBufferSize: tc.bufferSize,
+				Mode:               types.ModeTimeSeries,
+				ModeConfig: &types.TimeSeriesConfig{
+					Interval: "1s",
+					Buckets: []types.RequestBucket{
+						{
+							StartTime: 0,
+							Requests: []types.ExactRequest{
+								{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "a"},
+								{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "b"},
+								{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "c"},
+							},
+						},
+					},
+				},
+			})
+			require.NoError(t, err)
+			defer exec.Stop()
+
+			runErrCh := make(chan error, 1)
+			go func() { runErrCh <- exec.Run(context.TODO()) }()
+
+			// Workers are busy so that nobody receives for a while.
+			time.Sleep(delay)
+			for i := 0; i < 3; i++ {
+				<-exec.Chan()
+			}
+			require.NoError(t, <-runErrCh)
+
+			md := exec.Metadata()
+			if tc.blocked {
+				assert.GreaterOrEqual(t, md.DispatchBlockedTime, delay)
+				assert.GreaterOrEqual(t, md.MaxDispatchBlockedTime, delay)
+			} else {
+				assert.Less(t, md.DispatchBlockedTime, delay/2)
+			}
+			assert.LessOrEqual(t, md.MaxDispatchBlockedTime, md.DispatchBlockedTime)
+		})
+	}
+}
+
+func TestTimeSeriesDispatchLag(t *testing.T) {
+	origin := createExactRequestBuilderFunc
+	defer func() { createExactRequestBuilderFunc = origin }()
+
+	// Creating builder is slow, which is done before the bucket's target
+	// time by prefetch.
+	createExactRequestBuilderFunc = func(*types.ExactRequest, int) (RESTRequestBuilder, error) {
+		time.Sleep(20 * time.Millisecond)
+		return &fakeCacheBuilder{}, nil
+	}
+
+	req := types.ExactRequest{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "a"}
+	spike := make([]types.ExactRequest, 10)
+	for i := range spike {
+		spike[i] = req
+	}
+
+	for _, mode := range []types.BucketOverlapMode{
+		types.BucketOverlapModeSequential,
+		types.BucketOverlapModeConcurrent,
+	} {
+		t.Run(string(mode), func(t *testing.T) {
+			exec, err := NewTimeSeriesExecutor(&types.LoadProfileSpec{
+				Mode: types.ModeTimeSeries,
+				ModeConfig: &types.TimeSeriesConfig{
+					Interval:          "1s",
+					Repeat:            1,
+					TimeScale:         0.5,
+					BucketOverlapMode: mode,
+					Buckets: []types.RequestBucket{
+						{StartTime: 0, Requests: []types.ExactRequest{req}},
+						{StartTime: 1, Requests: spike},
+					},
+				},
+			})
+			require.NoError(t, err)
+			defer exec.Stop()
+
+			runErrCh := make(chan error, 1)
+			go func() { runErrCh <- exec.Run(context.TODO()) }()
+
+			for i := 0; i < 2*(1+len(spike)); i++ {
+				<-exec.Chan()
+			}
+			require.NoError(t, <-runErrCh)
+
+			report := exec.(Reporter).Report().TimeSeries
+			require.NotNil(t, report)
+			require.Len(t, report.Buckets, 4)
+			for i, lag := range report.Buckets {
+				assert.Equal(t, i/2, lag.Round)
+				if i%2 == 0 {
+					assert.Equal(t, 0.0, lag.StartTime)
+					assert.Equal(t, 1, lag.Requests)
+					continue
+				}
+				assert.Equal(t, 1.0, lag.StartTime)
+				assert.Equal(t, len(spike), lag.Requests)
+				assert.GreaterOrEqual(t, lag.FirstLagSeconds, 0.0)
+				assert.GreaterOrEqual(t, lag.LastLagSeconds, lag.FirstLagSeconds)
+				// Building 10 requests takes 200ms, which would be
+				// counted as lag without prefetch.
+				assert.Less(t, lag.LastLagSeconds, 0.1)
+				assert.GreaterOrEqual(t, report.MaxLagSeconds, lag.LastLagSeconds)
+			}
+		})
+	}
+}
+
+func TestTimeSeriesSpread(t *testing.T) {
+	origin := createExactRequestBuilderFunc
+	defer func() { createExactRequestBuilderFunc = origin }()
+
+	createExactRequestBuilderFunc = func(*types.ExactRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	requests := func(n int) []types.ExactRequest {
+		reqs := make([]types.ExactRequest, n)
+		for i := range reqs {
+			reqs[i] = types.ExactRequest{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "a"}
+		}
+		return reqs
+	}
+
+	for name, tc := range map[string]struct {
+		spread    types.SpreadMode
+		timeScale float64
+		// expected are the offsets of received requests in seconds.
+		expected []float64
+	}{
+		"none": {
+			spread:   types.SpreadModeNone,
+			expected: []float64{0, 0, 0, 0, 10, 10},
+		},
+		// The first bucket is spread until the next bucket, which is
+		// sooner than interval. The last one is spread over interval.
+		"uniform": {
+			spread:   types.SpreadModeUniform,
+			expected: []float64{0, 2.5, 5, 7.5, 10, 40},
+		},
+		"uniform with time scale": {
+			spread:    types.SpreadModeUniform,
+			timeScale: 2,
+			expected:  []float64{0, 5, 10, 15, 20, 80},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			exec, err := NewTimeSeriesExecutor(&types.LoadProfileSpec{
+				Mode: types.ModeTimeSeries,
+				ModeConfig: &types.TimeSeriesConfig{
+					Interval:  "60s",
+					Spread:    tc.spread,
+					TimeScale: tc.timeScale,
+					Buckets: []types.RequestBucket{
+						{StartTime: 0, Requests: requests(4)},
+						{StartTime: 10, Requests: requests(2)},
+					},
+				},
+			})
+			require.NoError(t, err)
+			defer exec.Stop()
+
+			e := exec.(*TimeSeriesExecutor)
+			start := time.Now()
+			fakeClock := clocktesting.NewFakeClock(start)
+			e.clock = fakeClock
+			assert.Equal(t, string(tc.spread), e.Metadata().Custom["spread"])
+
+			runErrCh := make(chan error, 1)
+			go func() { runErrCh <- e.Run(context.TODO()) }()
+
+			// Step the clock only if executor waits for it, so that the
+			// requests are received at their target time.
+			offsets := make([]float64, 0, len(tc.expected))
+			for len(offsets) < len(tc.expected) {
+				select {
+				case <-e.Chan():
+					offsets = append(offsets, fakeClock.Since(start).Seconds())
+				default:
+					if fakeClock.HasWaiters() {
+						fakeClock.Step(500 * time.Millisecond)
+					} else {
+						time.Sleep(time.Millisecond)
+					}
+				}
+			}
+			require.NoError(t, <-runErrCh)
+			assert.Equal(t, tc.expected, offsets)
+		})
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"k8s.io/klog/v2"
+)
+
+// traceLateTolerance is how long the request can fall behind its scheduled
+// time before it's counted as late.
+const traceLateTolerance = 10 * time.Millisecond
+
+// TraceExecutor implements Executor for trace mode. It streams the trace
+// file and dispatches each request at its offset. The requests which are
+// behind schedule are dispatched immediately.
+type TraceExecutor struct {
+	config       *types.TraceConfig
+	spec         *types.LoadProfileSpec
+	format       types.TraceFormat
+	reqBuilderCh chan RESTRequestBuilder
+	// requests is the number of dispatched requests.
+	requests int64
+	// late is the number of requests dispatched after scheduled time.
+	late int64
+	// skipped is the number of unsupported requests.
+	skipped int64
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// NewTraceExecutor creates a new trace executor from spec.
+func NewTraceExecutor(spec *types.LoadProfileSpec) (Executor, error) {
+	if spec.Mode != types.ModeTrace {
+		return nil, fmt.Errorf("expected mode %s, got %s", types.ModeTrace, spec.Mode)
+	}
+
+	if spec.ModeConfig == nil {
+		return nil, fmt.Errorf("modeConfig is required")
+	}
+
+	config, ok := spec.ModeConfig.(*types.TraceConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for trace mode")
+	}
+
+	if _, err := os.Stat(config.File); err != nil {
+		return nil, fmt.Errorf("invalid trace file: %w", err)
+	}
+
+	format := config.Format
+	if format == "" {
+		format = types.TraceFormatCSV
+		switch strings.ToLower(filepath.Ext(config.File)) {
+		case ".jsonl", ".ndjson":
+			format = types.TraceFormatJSONL
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TraceExecutor{
+		config:       config,
+		spec:         spec,
+		format:       format,
+		reqBuilderCh: make(chan RESTRequestBuilder),
+		ctx:          ctx,
+		cancel:       cancel,
+	}, nil
+}
+
+// Chan returns the channel that produces request builders.
+func (e *TraceExecutor) Chan() <-chan RESTRequestBuilder {
+	return e.reqBuilderCh
+}
+
+// Run starts the executor and replays the trace file.
+func (e *TraceExecutor) Run(ctx context.Context) error {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	f, err := os.Open(e.config.File)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+	defer f.Close()
+
+	var next func() (*types.TraceRecord, error)
+	switch e.format {
+	case types.TraceFormatJSONL:
+		next = newJSONLTraceReader(f)
+	default:
+		next = newCSVTraceReader(f)
+	}
+
+	startTime := time.Now()
+	for line := 1; ; line++ {
+		record, err := next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read trace record #%d: %w", line, err)
+		}
+
+		builder, err := e.createBuilder(record)
+		if err != nil {
+			atomic.AddInt64(&e.skipped, 1)
+			klog.V(2).Infof("Skip trace record #%d: %v", line, err)
+			continue
+		}
+
+		// Wait until target time. It's no-op if it's already passed.
+		targetTime := startTime.Add(e.scale(time.Duration(record.Offset * float64(time.Second))))
+		select {
+		case <-time.After(time.Until(targetTime)):
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.ctx.Done():
+			return e.ctx.Err()
+		}
+
+		select {
+		case e.reqBuilderCh <- builder:
+			atomic.AddInt64(&e.requests, 1)
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.ctx.Done():
+			return e.ctx.Err()
+		}
+
+		// NOTE: The request can be behind schedule because of previous
+		// records or busy workers.
+		if time.Since(targetTime) > traceLateTolerance {
+			atomic.AddInt64(&e.late, 1)
+		}
+	}
+}
+
+// createBuilder creates a request builder from the trace record.
+func (e *TraceExecutor) createBuilder(record *types.TraceRecord) (RESTRequestBuilder, error) {
+	if createExactRequestBuilderFunc == nil {
+		return nil, fmt.Errorf("request builder factory not initialized")
+	}
+
+	req, err := traceRecordToExactRequest(record)
+	if err != nil {
+		return nil, err
+	}
+	return createExactRequestBuilderFunc(req, e.spec.MaxRetries)
+}
+
+// scale applies TimeScale to the given duration.
+func (e *TraceExecutor) scale(d time.Duration) time.Duration {
+	if e.config.TimeScale <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * e.config.TimeScale)
+}
+
+// Report implements Reporter.
+func (e *TraceExecutor) Report() *types.ExecutorReport {
+	return &types.ExecutorReport{
+		Trace: &types.TraceReport{
+			Requests: atomic.LoadInt64(&e.requests),
+			Late:     atomic.LoadInt64(&e.late),
+			Skipped:  atomic.LoadInt64(&e.skipped),
+		},
+	}
+}
+
+// Stop gracefully stops the executor.
+func (e *TraceExecutor) Stop() {
+	e.once.Do(func() {
+		e.cancel()
+		e.wg.Wait()
+		close(e.reqBuilderCh)
+	})
+}
+
+// Metadata returns executor metadata. The total and duration are unknown
+// because the trace file is streamed.
+func (e *TraceExecutor) Metadata() ExecutorMetadata {
+	return ExecutorMetadata{
+		Custom: map[string]interface{}{
+			"mode":          string(types.ModeTrace),
+			"file":          e.config.File,
+			"format":        string(e.format),
+			"time_scale":    e.config.TimeScale,
+			"late_count":    atomic.LoadInt64(&e.late),
+			"skipped_count": atomic.LoadInt64(&e.skipped),
+		},
+	}
+}
+
+// GetRateLimiter returns nil because trace mode handles timing internally.
+func (e *TraceExecutor) GetRateLimiter() RateLimiter {
+	return nil
+}
+
+// GetExecutionContext returns a simple cancellable context (no duration timeout).
+func (e *TraceExecutor) GetExecutionContext(baseCtx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithCancel(baseCtx)
+}
+
+// newJSONLTraceReader returns the function to read TraceRecord line by line.
+func newJSONLTraceReader(r io.Reader) func() (*types.TraceRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	return func() (*types.TraceRecord, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			record := &types.TraceRecord{}
+			if err := json.Unmarshal([]byte(line), record); err != nil {
+				return nil, err
+			}
+			return record, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
+
+// defaultTraceCSVColumns are the columns of CSV without header.
+var defaultTraceCSVColumns = []string{"offset", "verb", "path"}
+
+// newCSVTraceReader returns the function to read TraceRecord row by row. If
+// the first row's offset isn't a number, it's a header.
+func newCSVTraceReader(r io.Reader) func() (*types.TraceRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var columns []string
+	return func() (*types.TraceRecord, error) {
+		row, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		if columns == nil {
+			columns = defaultTraceCSVColumns
+			if _, err := strconv.ParseFloat(row[0], 64); err != nil {
+				columns = make([]string, 0, len(row))
+				for _, c := range row {
+					columns = append(columns, strings.ToLower(strings.TrimSpace(c)))
+				}
+
+				row, err = reader.Read()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		return csvRowToTraceRecord(columns, row)
+	}
+}
+
+// csvRowToTraceRecord converts CSV row into TraceRecord by column names.
+func csvRowToTraceRecord(columns, row []string) (*types.TraceRecord, error) {
+	if len(row) != len(columns) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(columns), len(row))
+	}
+
+	record := &types.TraceRecord{}
+	for i, c := range columns {
+		v := row[i]
+
+		var err error
+		switch c {
+		case "offset", "offset_seconds":
+			record.Offset, err = strconv.ParseFloat(v, 64)
+		case "verb", "method":
+			record.Method = v
+		case "path", "uri":
+			record.Path = v
+		case "group":
+			record.Group = v
+		case "version":
+			record.Version = v
+		case "resource":
+			record.Resource = v
+		case "namespace":
+			record.Namespace = v
+		case "name":
+			record.Name = v
+		case "labelselector":
+			record.LabelSelector = v
+		case "fieldselector":
+			record.FieldSelector = v
+		case "limit":
+			if v != "" {
+				record.Limit, err = strconv.Atoi(v)
+			}
+		case "resourceversion":
+			record.ResourceVersion = v
+		case "body":
+			record.Body = v
+		case "patchtype":
+			record.PatchType = v
+		default:
+			return nil, fmt.Errorf("unknown column %q", c)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", c, v, err)
+		}
+	}
+	return record, nil
+}
+
+// traceRecordToExactRequest converts TraceRecord into ExactRequest. The
+// path, if any, is parsed into GVR fields and query parameters. The verb
+// can be either HTTP method or kubernetes verb, and GET without name is
+// LIST.
+func traceRecordToExactRequest(record *types.TraceRecord) (*types.ExactRequest, error) {
+	req := record.ExactRequest
+	if err := req.ValidateMaxRetries(); err != nil {
+		return nil, err
+	}
+
+	if record.Path != "" {
+		if err := parseTracePath(record.Path, &req); err != nil {
+			return nil, err
+		}
+	}
+
+	switch strings.ToUpper(req.Method) {
+	case "GET", "LIST":
+		req.Method = "GET"
+		if req.Name == "" {
+			req.Method = "LIST"
+		}
+	case "POST", "CREATE":
+		req.Method = "POST"
+	case "PATCH":
+		req.Method = "PATCH"
+	case "DELETE":
+		req.Method = "DELETE"
+	default:
+		return nil, fmt.Errorf("unsupported verb: %s", req.Method)
+	}
+	return &req, nil
+}
+
+// parseTracePath parses request URI, like /api/v1/namespaces/default/pods/a
+// or /apis/apps/v1/deployments?limit=10, into req.
+func parseTracePath(path string, req *types.ExactRequest) error {
+	u, err := url.Parse(path)
+	if err != nil {
+		return fmt.Errorf("invalid path %q: %w", path, err)
+	}
+
+	segs := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case len(segs) >= 3 && segs[0] == "api":
+		req.Group, req.Version, segs = "", segs[1], segs[2:]
+	case len(segs) >= 4 && segs[0] == "apis":
+		req.Group, req.Version, segs = segs[1], segs[2], segs[3:]
+	default:
+		return fmt.Errorf("invalid path %q: not a resource path", path)
+	}
+
+	// NOTE: /api/v1/namespaces/{name} is the namespace object itself.
+	req.Namespace = ""
+	if len(segs) >= 3 && segs[0] == "namespaces" {
+		req.Namespace, segs = segs[1], segs[2:]
+	}
+
+	switch len(segs) {
+	case 1:
+		req.Resource, req.Name = segs[0], ""
+	case 2:
+		req.Resource, req.Name = segs[0], segs[1]
+	default:
+		return fmt.Errorf("invalid path %q: subresource is not supported", path)
+	}
+
+	query := u.Query()
+	if v := query.Get("labelSelector"); v != "" {
+		req.LabelSelector = v
+	}
+	if v := query.Get("fieldSelector"); v != "" {
+		req.FieldSelector = v
+	}
+	if v := query.Get("resourceVersion"); v != "" {
+		req.ResourceVersion = v
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid path %q: invalid limit: %w", path, err)
+		}
+		req.Limit = limit
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceRecordToExactRequest(t *testing.T) {
+	for name, tc := range map[string]struct {
+		record   types.TraceRecord
+		expected *types.ExactRequest
+	}{
+		"list pods in namespace": {
+			record: types.TraceRecord{
+				Path:         "/api/v1/namespaces/default/pods?limit=10&labelSelector=app%3Dx&resourceVersion=0",
+				ExactRequest: types.ExactRequest{Method: "GET"},
+			},
+			expected: &types.ExactRequest{
+				Method: "LIST", Version: "v1", Resource: "pods", Namespace: "default",
+				Limit: 10, LabelSelector: "app=x", ResourceVersion: "0",
+			},
+		},
+		"get namespace": {
+			record: types.TraceRecord{
+				Path:         "/api/v1/namespaces/kube-system",
+				ExactRequest: types.ExactRequest{Method: "get"},
+			},
+			expected: &types.ExactRequest{
+				Method: "GET", Version: "v1", Resource: "namespaces", Name: "kube-system",
+			},
+		},
+		"create deployment": {
+			record: types.TraceRecord{
+				Path:         "/apis/apps/v1/namespaces/default/deployments",
+				ExactRequest: types.ExactRequest{Method: "create"},
+			},
+			expected: &types.ExactRequest{
+				Method: "POST", Group: "apps", Version: "v1", Resource: "deployments", Namespace: "default",
+			},
+		},
+		"gvr fields": {
+			record: types.TraceRecord{
+				ExactRequest: types.ExactRequest{Method: "list", Version: "v1", Resource: "configmaps"},
+			},
+			expected: &types.ExactRequest{
+				Method: "LIST", Version: "v1", Resource: "configmaps",
+			},
+		},
+		"subresource": {
+			record: types.TraceRecord{
+				Path:         "/api/v1/namespaces/default/pods/a/log",
+				ExactRequest: types.ExactRequest{Method: "GET"},
+			},
+		},
+		"non-resource path": {
+			record: types.TraceRecord{
+				Path:         "/healthz",
+				ExactRequest: types.ExactRequest{Method: "GET"},
+			},
+		},
+		"unsupported verb": {
+			record: types.TraceRecord{
+				Path:         "/api/v1/pods",
+				ExactRequest: types.ExactRequest{Method: "WATCH"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, err := traceRecordToExactRequest(&tc.record)
+			if tc.expected == nil {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, req)
+		})
+	}
+}
+
+func TestTraceExecutor(t *testing.T) {
+	origin := createExactRequestBuilderFunc
+	defer func() { createExactRequestBuilderFunc = origin }()
+
+	var mu sync.Mutex
+	var methods []string
+	createExactRequestBuilderFunc = func(req *types.ExactRequest, _ int) (RESTRequestBuilder, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		methods = append(methods, req.Method)
+		return &fakeCacheBuilder{}, nil
+	}
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"trace.csv": strings.Join([]string{
+			"0,GET,/api/v1/pods",
+			"0.4,WATCH,/api/v1/pods",
+			"0.4,GET,/api/v1/namespaces/default/pods/a",
+			"0.4,DELETE,/api/v1/namespaces/default/pods",
+			"",
+		}, "\n"),
+		"trace.jsonl": strings.Join([]string{
+			`{"offset":0,"method":"LIST","version":"v1","resource":"pods"}`,
+			`{"offset":0.4,"method":"WATCH","path":"/api/v1/pods"}`,
+			`{"offset":0.4,"method":"GET","path":"/api/v1/namespaces/default/pods/a"}`,
+			`{"offset":0.4,"method":"DELETE","version":"v1","resource":"pods","namespace":"default"}`,
+			"",
+		}, "\n"),
+		"header.csv": strings.Join([]string{
+			"offset,verb,version,resource,namespace,name",
+			"0,list,v1,pods,,",
+			"0.4,watch,v1,pods,,",
+			"0.4,get,v1,pods,default,a",
+			"0.4,delete,v1,pods,default,",
+			"",
+		}, "\n"),
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	for _, name := range []string{"trace.csv", "trace.jsonl", "header.csv"} {
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			methods = nil
+			mu.Unlock()
+
+			exec, err := NewTraceExecutor(&types.LoadProfileSpec{
+				Mode: types.ModeTrace,
+				ModeConfig: &types.TraceConfig{
+					File: filepath.Join(dir, name),
+					// The offset 0.4 is replayed at 0.2s.
+					TimeScale: 0.5,
+				},
+			})
+			require.NoError(t, err)
+			defer exec.Stop()
+
+			// The first request blocks the consumer so that the
+			// following requests fall behind.
+			var sentAt []time.Duration
+			start := time.Now()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for range exec.Chan() {
+					sentAt = append(sentAt, time.Since(start))
+					if len(sentAt) == 1 {
+						time.Sleep(400 * time.Millisecond)
+					}
+				}
+			}()
+
+			require.NoError(t, exec.Run(context.TODO()))
+			exec.Stop()
+			<-done
+
+			mu.Lock()
+			assert.Equal(t, []string{"LIST", "GET", "DELETE"}, methods)
+			mu.Unlock()
+
+			require.Len(t, sentAt, 3)
+			assert.Less(t, sentAt[0], 100*time.Millisecond)
+			assert.GreaterOrEqual(t, sentAt[1], 400*time.Millisecond)
+
+			report := exec.(Reporter).Report().Trace
+			require.NotNil(t, report)
+			assert.Equal(t, int64(3), report.Requests)
+			assert.Equal(t, int64(2), report.Late)
+			assert.Equal(t, int64(1), report.Skipped)
+		})
+	}
+
+	_, err := NewTraceExecutor(&types.LoadProfileSpec{
+		Mode:       types.ModeTrace,
+		ModeConfig: &types.TraceConfig{File: filepath.Join(dir, "not-found.csv")},
+	})
+	assert.Error(t, err)
+}
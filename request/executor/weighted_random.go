@@ -9,7 +9,10 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	mathrand "math/rand/v2"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Azure/kperf/api/types"
@@ -23,12 +26,43 @@ type WeightedRandomExecutor struct {
 	spec         *types.LoadProfileSpec
 	limiter      *rate.Limiter
 	reqBuilderCh chan RESTRequestBuilder
-	shares       []int
-	reqBuilders  []RESTRequestBuilder
-	ctx          context.Context
-	cancel       context.CancelFunc
-	wg           sync.WaitGroup
-	once         sync.Once
+
+	// mu protects requests, shares, reqBuilders and counts, which are
+	// replaced by UpdateRequests.
+	mu          sync.RWMutex
+	requests    []*types.WeightedRequest
+	shares      []int
+	reqBuilders []RESTRequestBuilder
+	// counts are the realized counts of each request.
+	counts []int64
+
+	// conditionNotMet counts the picks whose condition is not met.
+	conditionNotMet int64
+	// schedule is the precomputed order of request indexes in
+	// deterministic distribution.
+	schedule []int
+	// seed is the seed to shuffle schedule or pick requests randomly.
+	seed int64
+	// rnd is the pseudo-random generator seeded by Seed in random
+	// distribution. It's nil if Seed isn't set, which means crypto/rand.
+	//
+	// NOTE: It's not cryptographically secure, which doesn't matter for
+	// picking requests. It's only used by Run's goroutine.
+	rnd *mathrand.Rand
+	// inflight caps the number of in-flight requests. It's nil if there is
+	// no limit.
+	inflight *inFlightLimiter
+	// dispatch measures the time blocked on sending requests.
+	dispatch dispatchTracker
+	// window records when Run starts and finishes.
+	window runWindow
+	// stats emits the distribution of picked requests.
+	stats *distributionStats
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
 }
 
 // NewWeightedRandomExecutor creates a new weighted random executor from spec.
@@ -47,19 +81,9 @@ func NewWeightedRandomExecutor(spec *types.LoadProfileSpec) (Executor, error) {
 		return nil, fmt.Errorf("invalid config type for weighted-random mode")
 	}
 
-	// Build request builders
-	shares := make([]int, 0, len(config.Requests))
-	reqBuilders := make([]RESTRequestBuilder, 0, len(config.Requests))
-	for _, r := range config.Requests {
-		shares = append(shares, r.Shares)
-		if createRequestBuilderFunc == nil {
-			return nil, fmt.Errorf("request builder factory not initialized")
-		}
-		builder, err := createRequestBuilderFunc(r, spec.MaxRetries)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request builder: %v", err)
-		}
-		reqBuilders = append(reqBuilders, builder)
+	shares, reqBuilders, err := newWeightedRequestBuilders(config.Requests, spec)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create rate limiter
@@ -69,19 +93,168 @@ func NewWeightedRandomExecutor(spec *types.LoadProfileSpec) (Executor, error) {
 	}
 	limiter := rate.NewLimiter(rate.Limit(qps), 1)
 
+	var schedule []int
+	seed := config.Seed
+	if config.Distribution == types.DistributionDeterministic {
+		if config.Total <= 0 {
+			return nil, fmt.Errorf("deterministic distribution requires total > 0")
+		}
+		for seed == 0 {
+			seed = mathrand.Int64()
+		}
+		schedule = deterministicSchedule(config.Total, shares, seed)
+	}
+
+	var rnd *mathrand.Rand
+	if schedule == nil && seed != 0 {
+		rnd = mathrand.New(mathrand.NewPCG(uint64(seed), 0))
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	return &WeightedRandomExecutor{
 		config:       config,
 		spec:         spec,
 		limiter:      limiter,
-		reqBuilderCh: make(chan RESTRequestBuilder),
+		reqBuilderCh: make(chan RESTRequestBuilder, spec.DispatchBufferSize),
+		requests:     config.Requests,
 		shares:       shares,
 		reqBuilders:  reqBuilders,
+		counts:       make([]int64, len(reqBuilders)),
+		schedule:     schedule,
+		seed:         seed,
+		rnd:          rnd,
+		inflight:     newInFlightLimiter(config.MaxInFlight),
+		stats:        newDistributionStats(),
 		ctx:          ctx,
 		cancel:       cancel,
 	}, nil
 }
 
+// newWeightedRequestBuilders returns the shares and request builders of the
+// given requests.
+func newWeightedRequestBuilders(requests []*types.WeightedRequest, spec *types.LoadProfileSpec) ([]int, []RESTRequestBuilder, error) {
+	if createRequestBuilderFunc == nil {
+		return nil, nil, fmt.Errorf("request builder factory not initialized")
+	}
+
+	shares := make([]int, 0, len(requests))
+	reqBuilders := make([]RESTRequestBuilder, 0, len(requests))
+	for _, r := range requests {
+		shares = append(shares, r.Shares)
+		builder, err := createRequestBuilderFunc(r.WithDefaultObjectMeta(spec.ObjectMeta), spec.MaxRetries)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create request builder: %v", err)
+		}
+		if r.Condition != nil || r.MaxConcurrency > 0 {
+			condition := r.Condition
+			if condition == nil {
+				condition = &types.RequestCondition{}
+			}
+			builder = &conditionalBuilder{
+				RESTRequestBuilder: builder,
+				condition:          condition,
+				maxConcurrency:     r.MaxConcurrency,
+			}
+		}
+		reqBuilders = append(reqBuilders, builder)
+	}
+	return shares, reqBuilders, nil
+}
+
+// UpdateRequests implements RequestUpdater. It replaces the requests to pick
+// while running, like shifting weight from reads to writes once data is
+// populated. The realized counts in report start over for the new requests.
+// It isn't supported by deterministic distribution, because the schedule is
+// precomputed.
+func (e *WeightedRandomExecutor) UpdateRequests(requests []*types.WeightedRequest) error {
+	if e.schedule != nil {
+		return fmt.Errorf("updating requests isn't supported by %s distribution", types.DistributionDeterministic)
+	}
+
+	sum := 0
+	for i, r := range requests {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("requests[%d]: %w", i, err)
+		}
+		sum += r.Shares
+	}
+	if sum <= 0 {
+		return fmt.Errorf("total shares of requests requires > 0: %v", sum)
+	}
+
+	shares, reqBuilders, err := newWeightedRequestBuilders(requests, e.spec)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.requests = requests
+	e.shares = shares
+	e.reqBuilders = reqBuilders
+	e.counts = make([]int64, len(reqBuilders))
+	return nil
+}
+
+// UpdateRate implements RateUpdater. Like config's rate, zero means
+// unlimited.
+func (e *WeightedRandomExecutor) UpdateRate(qps float64) error {
+	if qps < 0 {
+		return fmt.Errorf("rate requires >= 0: %v", qps)
+	}
+	if qps == 0 {
+		qps = float64(math.MaxInt32)
+	}
+	e.limiter.SetLimit(rate.Limit(qps))
+	return nil
+}
+
+// deterministicSchedule apportions total by shares with largest remainder
+// method, so that the realized counts match shares for any total, and
+// shuffles them by the seed.
+func deterministicSchedule(total int, shares []int, seed int64) []int {
+	sum := 0
+	for _, s := range shares {
+		sum += s
+	}
+
+	counts := make([]int, len(shares))
+	remainders := make([]int, len(shares))
+	assigned := 0
+	for i, s := range shares {
+		counts[i] = total * s / sum
+		remainders[i] = total * s % sum
+		assigned += counts[i]
+	}
+
+	// Give the rest to the largest remainders. The tie goes to the former.
+	order := make([]int, len(shares))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+	for i := 0; assigned < total; i++ {
+		counts[order[i]]++
+		assigned++
+	}
+
+	schedule := make([]int, 0, total)
+	for i, c := range counts {
+		for j := 0; j < c; j++ {
+			schedule = append(schedule, i)
+		}
+	}
+
+	rnd := mathrand.New(mathrand.NewPCG(uint64(seed), uint64(seed)))
+	rnd.Shuffle(len(schedule), func(i, j int) {
+		schedule[i], schedule[j] = schedule[j], schedule[i]
+	})
+	return schedule
+}
+
 // Chan returns the channel that produces request builders.
 func (e *WeightedRandomExecutor) Chan() <-chan RESTRequestBuilder {
 	return e.reqBuilderCh
@@ -92,6 +265,20 @@ func (e *WeightedRandomExecutor) Run(ctx context.Context) error {
 	e.wg.Add(1)
 	defer e.wg.Done()
 
+	e.window.begin(time.Now())
+	defer func() { e.window.finish(time.Now()) }()
+
+	statsCtx, statsCancel := context.WithCancel(context.Background())
+	statsDone := make(chan struct{})
+	go func() {
+		defer close(statsDone)
+		e.stats.run(statsCtx, e.theoreticalWeights)
+	}()
+	defer func() {
+		statsCancel()
+		<-statsDone
+	}()
+
 	total := e.config.Total
 	sum := 0
 
@@ -100,19 +287,78 @@ func (e *WeightedRandomExecutor) Run(ctx context.Context) error {
 			break
 		}
 
-		builder := e.randomPick()
+		var idx int
+		var builder RESTRequestBuilder
+		e.mu.RLock()
+		if e.schedule != nil {
+			idx, builder = e.scheduledPick(sum)
+		} else {
+			idx, builder = e.randomPick()
+		}
+		// NOTE: The counts are replaced by UpdateRequests, so the picked
+		// one is counted in the counts it's picked from.
+		counts := e.counts
+		var typ string
+		if builder != nil {
+			typ = e.requests[idx].Type()
+		}
+		e.mu.RUnlock()
+		if builder == nil {
+			// None of picked requests meet the condition. Wait for
+			// runtime state changes, like in-flight requests are done.
+			select {
+			case <-time.After(conditionRetryInterval):
+				continue
+			case <-e.ctx.Done():
+				return e.ctx.Err()
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if e.inflight != nil {
+			if err := e.acquireInFlight(ctx); err != nil {
+				return err
+			}
+			builder = e.inflight.wrap(builder)
+		}
+
+		start := time.Now()
 		select {
 		case e.reqBuilderCh <- builder:
+			e.dispatch.since(start)
+			atomic.AddInt64(&counts[idx], 1)
+			e.stats.add(typ)
 			sum++
 		case <-e.ctx.Done():
+			e.releaseInFlight()
 			return e.ctx.Err()
 		case <-ctx.Done():
+			e.releaseInFlight()
 			return ctx.Err()
 		}
 	}
 	return nil
 }
 
+// acquireInFlight blocks until the number of in-flight requests is under
+// MaxInFlight or either ctx or executor is done.
+func (e *WeightedRandomExecutor) acquireInFlight(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(e.ctx, cancel)
+	defer stop()
+
+	return e.inflight.acquire(ctx)
+}
+
+// releaseInFlight releases the token acquired for the undelivered request.
+func (e *WeightedRandomExecutor) releaseInFlight() {
+	if e.inflight != nil {
+		e.inflight.release()
+	}
+}
+
 // Stop gracefully stops the executor.
 func (e *WeightedRandomExecutor) Stop() {
 	e.once.Do(func() {
@@ -124,40 +370,198 @@ func (e *WeightedRandomExecutor) Stop() {
 
 // Metadata returns executor metadata.
 func (e *WeightedRandomExecutor) Metadata() ExecutorMetadata {
-	return ExecutorMetadata{
+	e.mu.RLock()
+	requestTypes := len(e.requests)
+	e.mu.RUnlock()
+
+	md := ExecutorMetadata{
 		ExpectedTotal:    e.config.Total,
 		ExpectedDuration: time.Duration(e.config.Duration) * time.Second,
 		Custom: map[string]interface{}{
-			"mode":          string(types.ModeWeightedRandom),
-			"rate":          e.config.Rate,
-			"request_types": len(e.config.Requests),
+			"mode":                    string(types.ModeWeightedRandom),
+			"rate":                    e.config.Rate,
+			"request_types":           requestTypes,
+			"condition_not_met_count": atomic.LoadInt64(&e.conditionNotMet),
+			"distribution":            string(e.distribution()),
+			"in_flight_count":         e.inFlightCount(),
 		},
 	}
+	e.dispatch.apply(&md)
+	e.window.apply(&md)
+	return md
+}
+
+// DistributionStats implements DistributionStatsProvider. The snapshot
+// counts the requests sent to workers by type. If requests are updated, the
+// weights are about the current ones.
+func (e *WeightedRandomExecutor) DistributionStats() <-chan DistributionSnapshot {
+	return e.stats.ch
+}
+
+// theoreticalWeights returns the ratio of each request type's shares to the
+// total shares.
+func (e *WeightedRandomExecutor) theoreticalWeights() map[string]float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	total := 0
+	shares := map[string]int{}
+	for _, r := range e.requests {
+		shares[r.Type()] += r.Shares
+		total += r.Shares
+	}
+
+	weights := make(map[string]float64, len(shares))
+	for typ, s := range shares {
+		if total > 0 {
+			weights[typ] = float64(s) / float64(total)
+		}
+	}
+	return weights
+}
+
+// inFlightCount returns the number of in-flight requests. It's always zero
+// if MaxInFlight isn't set.
+func (e *WeightedRandomExecutor) inFlightCount() int64 {
+	if e.inflight == nil {
+		return 0
+	}
+	return e.inflight.inFlight()
 }
 
-// randomPick randomly selects a request builder based on weights.
-func (e *WeightedRandomExecutor) randomPick() RESTRequestBuilder {
+// conditionRetryInterval is the interval to pick again if none of picked
+// requests meet the condition.
+const conditionRetryInterval = 10 * time.Millisecond
+
+// randomPick randomly selects a request builder based on weights. If the
+// selected builder's condition is not met, it picks again up to MaxRetryPicks
+// times. It returns nil if none of picked builders meet the condition. The
+// caller must hold mu's read lock.
+func (e *WeightedRandomExecutor) randomPick() (int, RESTRequestBuilder) {
+	maxRetryPicks := e.config.MaxRetryPicks
+	if maxRetryPicks == 0 {
+		maxRetryPicks = types.DefaultMaxRetryPicks
+	}
+
+	for i := 0; i <= maxRetryPicks; i++ {
+		idx := e.weightedPick()
+		if builder := e.tryPick(idx); builder != nil {
+			return idx, builder
+		}
+	}
+	return 0, nil
+}
+
+// scheduledPick selects the n-th request builder in deterministic schedule.
+// If its condition is not met, it looks ahead up to MaxRetryPicks requests
+// of other types and swaps the first one meeting condition into the n-th,
+// so that the realized counts still match shares. It returns nil if none of
+// them meet the condition.
+func (e *WeightedRandomExecutor) scheduledPick(n int) (int, RESTRequestBuilder) {
+	maxRetryPicks := e.config.MaxRetryPicks
+	if maxRetryPicks == 0 {
+		maxRetryPicks = types.DefaultMaxRetryPicks
+	}
+
+	idx := e.schedule[n]
+	if builder := e.tryPick(idx); builder != nil {
+		return idx, builder
+	}
+
+	for i, retries := n+1, 0; i < len(e.schedule) && retries < maxRetryPicks; i++ {
+		retries++
+		next := e.schedule[i]
+		if next == idx {
+			continue
+		}
+
+		if builder := e.tryPick(next); builder != nil {
+			e.schedule[n], e.schedule[i] = next, idx
+			return next, builder
+		}
+	}
+	return 0, nil
+}
+
+// tryPick returns the idx-th request builder if its condition is met.
+func (e *WeightedRandomExecutor) tryPick(idx int) RESTRequestBuilder {
+	builder := e.reqBuilders[idx]
+
+	cb, ok := builder.(*conditionalBuilder)
+	if !ok {
+		return builder
+	}
+	if cb.met() {
+		cb.pick()
+		return cb
+	}
+	atomic.AddInt64(&e.conditionNotMet, 1)
+	return nil
+}
+
+// weightedPick randomly selects the index of request builder based on weights.
+// The picks are reproducible if Seed is set.
+func (e *WeightedRandomExecutor) weightedPick() int {
 	sum := 0
 	for _, s := range e.shares {
 		sum += s
 	}
 
-	rndInt, err := rand.Int(rand.Reader, big.NewInt(int64(sum)))
-	if err != nil {
-		panic(err)
+	var rnd int64
+	if e.rnd != nil {
+		rnd = e.rnd.Int64N(int64(sum))
+	} else {
+		rndInt, err := rand.Int(rand.Reader, big.NewInt(int64(sum)))
+		if err != nil {
+			panic(err)
+		}
+		rnd = rndInt.Int64()
 	}
 
-	rnd := rndInt.Int64()
 	for i := range e.shares {
 		s := int64(e.shares[i])
 		if rnd < s {
-			return e.reqBuilders[i]
+			return i
 		}
 		rnd -= s
 	}
 	panic("unreachable")
 }
 
+// distribution returns the configured distribution. Empty means random.
+func (e *WeightedRandomExecutor) distribution() types.Distribution {
+	if e.config.Distribution == "" {
+		return types.DistributionRandom
+	}
+	return e.config.Distribution
+}
+
+// Report implements Reporter with the realized counts of each request. If
+// requests are updated, it's about the current ones.
+func (e *WeightedRandomExecutor) Report() *types.ExecutorReport {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	report := &types.WeightedRandomReport{
+		Distribution: e.distribution(),
+		Requests:     make([]types.WeightedRequestCount, 0, len(e.requests)),
+	}
+	report.Seed = e.seed
+	for i, r := range e.requests {
+		count := types.WeightedRequestCount{
+			Type:          r.Type(),
+			Shares:        r.Shares,
+			Count:         atomic.LoadInt64(&e.counts[i]),
+			KeySpaceShard: r.KeySpaceShard(),
+		}
+		if cb, ok := e.reqBuilders[i].(*conditionalBuilder); ok {
+			count.CapSkipped = atomic.LoadInt64(&cb.capped)
+		}
+		report.Requests = append(report.Requests, count)
+	}
+	return &types.ExecutorReport{WeightedRandom: report}
+}
+
 // GetRateLimiter returns the rate limiter for worker-level rate limiting.
 func (e *WeightedRandomExecutor) GetRateLimiter() RateLimiter {
 	return e.limiter
@@ -0,0 +1,498 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package executor
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestDeterministicSchedule(t *testing.T) {
+	countOf := func(schedule []int, n int) []int {
+		counts := make([]int, n)
+		for _, idx := range schedule {
+			counts[idx]++
+		}
+		return counts
+	}
+
+	for name, tc := range map[string]struct {
+		total    int
+		shares   []int
+		expected []int
+	}{
+		"exact": {
+			total:    100,
+			shares:   []int{99, 1},
+			expected: []int{99, 1},
+		},
+		"largest remainder": {
+			total:    10,
+			shares:   []int{1, 1, 1},
+			expected: []int{4, 3, 3},
+		},
+		"small total": {
+			total:    3,
+			shares:   []int{50, 30, 20},
+			expected: []int{1, 1, 1},
+		},
+		"tie goes to the former": {
+			total:    1,
+			shares:   []int{1, 1},
+			expected: []int{1, 0},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			schedule := deterministicSchedule(tc.total, tc.shares, 1)
+			assert.Len(t, schedule, tc.total)
+			assert.Equal(t, tc.expected, countOf(schedule, len(tc.shares)))
+		})
+	}
+
+	// The same seed produces the same order.
+	a := deterministicSchedule(1000, []int{3, 2, 1}, 42)
+	assert.Equal(t, a, deterministicSchedule(1000, []int{3, 2, 1}, 42))
+	assert.NotEqual(t, a, deterministicSchedule(1000, []int{3, 2, 1}, 43))
+}
+
+func TestWeightedRandomExecutorDeterministicReport(t *testing.T) {
+	origin := createRequestBuilderFunc
+	defer func() { createRequestBuilderFunc = origin }()
+
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	gvr := types.KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+	config := &types.WeightedRandomConfig{
+		Total:        100,
+		Distribution: types.DistributionDeterministic,
+		Requests: []*types.WeightedRequest{
+			{Shares: 99, StaleList: &types.RequestList{KubeGroupVersionResource: gvr}},
+			{Shares: 1, QuorumGet: &types.RequestGet{KubeGroupVersionResource: gvr, Name: "x"}},
+		},
+	}
+	require.NoError(t, config.Validate(nil))
+
+	exec, err := NewWeightedRandomExecutor(&types.LoadProfileSpec{
+		Mode:       types.ModeWeightedRandom,
+		ModeConfig: config,
+	})
+	require.NoError(t, err)
+	defer exec.Stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- exec.Run(context.Background())
+	}()
+
+	for i := 0; i < 100; i++ {
+		<-exec.Chan()
+	}
+	require.NoError(t, <-errCh)
+
+	report := exec.(Reporter).Report().WeightedRandom
+	require.NotNil(t, report)
+	assert.Equal(t, types.DistributionDeterministic, report.Distribution)
+	assert.NotZero(t, report.Seed)
+	assert.Equal(t, []types.WeightedRequestCount{
+		{Type: "staleList", Shares: 99, Count: 99},
+		{Type: "quorumGet", Shares: 1, Count: 1},
+	}, report.Requests)
+}
+
+func TestWeightedRandomExecutorMaxInFlight(t *testing.T) {
+	origin := createRequestBuilderFunc
+	defer func() { createRequestBuilderFunc = origin }()
+
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	config := &types.WeightedRandomConfig{
+		Total:       3,
+		MaxInFlight: 2,
+		Requests: []*types.WeightedRequest{
+			{
+				Shares: 1,
+				StaleList: &types.RequestList{
+					KubeGroupVersionResource: types.KubeGroupVersionResource{Version: "v1", Resource: "pods"},
+				},
+			},
+		},
+	}
+	require.NoError(t, config.Validate(nil))
+
+	exec, err := NewWeightedRandomExecutor(&types.LoadProfileSpec{
+		Mode:       types.ModeWeightedRandom,
+		ModeConfig: config,
+	})
+	require.NoError(t, err)
+	defer exec.Stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- exec.Run(context.Background())
+	}()
+
+	first, second := <-exec.Chan(), <-exec.Chan()
+	assert.Equal(t, int64(2), exec.Metadata().Custom["in_flight_count"])
+
+	select {
+	case <-exec.Chan():
+		t.Fatal("expected to wait for in-flight requests")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	_, err = first.Build(nil).Do(context.TODO())
+	require.NoError(t, err)
+	third := <-exec.Chan()
+	require.NoError(t, <-errCh)
+
+	for _, b := range []RESTRequestBuilder{second, third} {
+		_, err = b.Build(nil).Do(context.TODO())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(0), exec.Metadata().Custom["in_flight_count"])
+}
+
+func TestWeightedRandomExecutorSeed(t *testing.T) {
+	origin := createRequestBuilderFunc
+	defer func() { createRequestBuilderFunc = origin }()
+
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	gvr := types.KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+	picks := func(seed int64) []int {
+		exec, err := NewWeightedRandomExecutor(&types.LoadProfileSpec{
+			Mode: types.ModeWeightedRandom,
+			ModeConfig: &types.WeightedRandomConfig{
+				Total: 100,
+				Seed:  seed,
+				Requests: []*types.WeightedRequest{
+					{Shares: 5, StaleList: &types.RequestList{KubeGroupVersionResource: gvr}},
+					{Shares: 3, QuorumList: &types.RequestList{KubeGroupVersionResource: gvr}},
+					{Shares: 2, StaleGet: &types.RequestGet{KubeGroupVersionResource: gvr, Name: "x"}},
+				},
+			},
+		})
+		require.NoError(t, err)
+		defer exec.Stop()
+
+		res := make([]int, 0, 100)
+		for i := 0; i < 100; i++ {
+			idx, builder := exec.(*WeightedRandomExecutor).randomPick()
+			require.NotNil(t, builder)
+			res = append(res, idx)
+		}
+		return res
+	}
+
+	assert.Equal(t, picks(42), picks(42))
+	assert.NotEqual(t, picks(42), picks(43))
+}
+
+func TestWeightedRandomExecutorRunWindow(t *testing.T) {
+	origin := createRequestBuilderFunc
+	defer func() { createRequestBuilderFunc = origin }()
+
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	gvr := types.KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+	exec, err := NewWeightedRandomExecutor(&types.LoadProfileSpec{
+		Mode: types.ModeWeightedRandom,
+		ModeConfig: &types.WeightedRandomConfig{
+			Total: 10,
+			Requests: []*types.WeightedRequest{
+				{Shares: 1, StaleList: &types.RequestList{KubeGroupVersionResource: gvr}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	md := exec.Metadata()
+	assert.True(t, md.StartTime.IsZero())
+	assert.True(t, md.EndTime.IsZero())
+
+	go func() {
+		for range exec.Chan() {
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	before := time.Now()
+	require.NoError(t, exec.Run(context.TODO()))
+	after := time.Now()
+	exec.Stop()
+
+	md = exec.Metadata()
+	assert.False(t, md.StartTime.Before(before.Truncate(time.Microsecond)))
+	assert.False(t, md.EndTime.After(after))
+	assert.False(t, md.EndTime.Before(md.StartTime))
+}
+
+func TestWeightedRandomExecutorUpdateRequests(t *testing.T) {
+	origin := createRequestBuilderFunc
+	defer func() { createRequestBuilderFunc = origin }()
+
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	gvr := types.KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+	list := func(shares int) *types.WeightedRequest {
+		return &types.WeightedRequest{Shares: shares, List: &types.RequestList{KubeGroupVersionResource: gvr}}
+	}
+	get := func(shares int) *types.WeightedRequest {
+		return &types.WeightedRequest{Shares: shares, Get: &types.RequestGet{KubeGroupVersionResource: gvr, Name: "x"}}
+	}
+
+	exec, err := NewWeightedRandomExecutor(&types.LoadProfileSpec{
+		Mode: types.ModeWeightedRandom,
+		ModeConfig: &types.WeightedRandomConfig{
+			Seed:     42,
+			Requests: []*types.WeightedRequest{list(1), get(0)},
+		},
+	})
+	require.NoError(t, err)
+	defer exec.Stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- exec.Run(context.Background())
+	}()
+
+	for i := 0; i < 100; i++ {
+		<-exec.Chan()
+	}
+	counts := func() []int64 {
+		res := []int64{}
+		for _, r := range exec.(Reporter).Report().WeightedRandom.Requests {
+			res = append(res, r.Count)
+		}
+		return res
+	}
+	// NOTE: The count is increased after the request is received.
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]int64{100, 0}, counts())
+	}, time.Second, 10*time.Millisecond)
+
+	updater := exec.(RequestUpdater)
+	for name, reqs := range map[string][]*types.WeightedRequest{
+		"empty":           nil,
+		"zero shares":     {list(0), get(0)},
+		"negative shares": {list(-1), get(2)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, updater.UpdateRequests(reqs))
+		})
+	}
+
+	// Shift all the weight from list to gets.
+	require.NoError(t, updater.UpdateRequests([]*types.WeightedRequest{list(0), get(9), get(1)}))
+	assert.Equal(t, 3, exec.Metadata().Custom["request_types"])
+
+	for i := 0; i < 1000; i++ {
+		<-exec.Chan()
+	}
+	// NOTE: The builder picked before update may be blocked on channel,
+	// which is counted as list.
+	var c []int64
+	require.Eventually(t, func() bool {
+		c = counts()
+		return c[1]+c[2] >= 999
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(0), c[0])
+	assert.InDelta(t, 900, c[1], 60)
+
+	exec.Stop()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+}
+
+func TestWeightedRandomExecutorUpdateRequestsDeterministic(t *testing.T) {
+	origin := createRequestBuilderFunc
+	defer func() { createRequestBuilderFunc = origin }()
+
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	gvr := types.KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+	reqs := []*types.WeightedRequest{
+		{Shares: 1, List: &types.RequestList{KubeGroupVersionResource: gvr}},
+	}
+	exec, err := NewWeightedRandomExecutor(&types.LoadProfileSpec{
+		Mode: types.ModeWeightedRandom,
+		ModeConfig: &types.WeightedRandomConfig{
+			Total:        10,
+			Distribution: types.DistributionDeterministic,
+			Requests:     reqs,
+		},
+	})
+	require.NoError(t, err)
+	defer exec.Stop()
+
+	assert.ErrorContains(t, exec.(RequestUpdater).UpdateRequests(reqs), "deterministic")
+}
+
+func TestWeightedRandomExecutorUpdateRate(t *testing.T) {
+	origin := createRequestBuilderFunc
+	defer func() { createRequestBuilderFunc = origin }()
+
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	exec, err := NewWeightedRandomExecutor(&types.LoadProfileSpec{
+		Mode: types.ModeWeightedRandom,
+		ModeConfig: &types.WeightedRandomConfig{
+			Rate: 10,
+			Requests: []*types.WeightedRequest{
+				{Shares: 1, StaleList: &types.RequestList{KubeGroupVersionResource: types.KubeGroupVersionResource{Version: "v1", Resource: "pods"}}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	defer exec.Stop()
+
+	limiter := exec.GetRateLimiter().(*rate.Limiter)
+	updater := NewTimeoutExecutor(exec, time.Minute).(RateUpdater)
+
+	require.NoError(t, updater.UpdateRate(100))
+	assert.Equal(t, rate.Limit(100), limiter.Limit())
+
+	// Zero means unlimited.
+	require.NoError(t, updater.UpdateRate(0))
+	assert.Equal(t, rate.Limit(math.MaxInt32), limiter.Limit())
+
+	assert.Error(t, updater.UpdateRate(-1))
+}
+
+func TestWeightedRandomExecutorDistributionStats(t *testing.T) {
+	origin := createRequestBuilderFunc
+	defer func() { createRequestBuilderFunc = origin }()
+
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+
+	gvr := types.KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+	config := &types.WeightedRandomConfig{
+		Total: 10000,
+		Requests: []*types.WeightedRequest{
+			{Shares: 60, StaleList: &types.RequestList{KubeGroupVersionResource: gvr}},
+			{Shares: 25, QuorumList: &types.RequestList{KubeGroupVersionResource: gvr}},
+			// The same type is counted together.
+			{Shares: 5, StaleGet: &types.RequestGet{KubeGroupVersionResource: gvr, Name: "x"}},
+			{Shares: 10, StaleGet: &types.RequestGet{KubeGroupVersionResource: gvr, Name: "y"}},
+		},
+	}
+	require.NoError(t, config.Validate(nil))
+
+	exec, err := NewWeightedRandomExecutor(&types.LoadProfileSpec{
+		Mode:       types.ModeWeightedRandom,
+		ModeConfig: config,
+	})
+	require.NoError(t, err)
+	defer exec.Stop()
+
+	statsCh := exec.(DistributionStatsProvider).DistributionStats()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- exec.Run(context.Background())
+	}()
+	for i := 0; i < config.Total; i++ {
+		<-exec.Chan()
+	}
+	require.NoError(t, <-errCh)
+
+	counts := map[string]int64{}
+	var weights map[string]float64
+	for snapshot := range statsCh {
+		for typ, c := range snapshot.RequestTypeCounts {
+			counts[typ] += c
+		}
+		weights = snapshot.TheoreticalWeights
+	}
+
+	assert.Equal(t, map[string]float64{
+		"staleList":  0.6,
+		"quorumList": 0.25,
+		"staleGet":   0.15,
+	}, weights)
+
+	total := int64(0)
+	for _, c := range counts {
+		total += c
+	}
+	require.Equal(t, int64(config.Total), total)
+	for typ, w := range weights {
+		actual := float64(counts[typ]) / float64(total)
+		assert.InDelta(t, w, actual, 0.05, "request type %s", typ)
+	}
+}
+
+func TestWeightedRandomExecutorDistributionStatsInterval(t *testing.T) {
+	origin, originInterval := createRequestBuilderFunc, distributionStatsInterval
+	defer func() { createRequestBuilderFunc, distributionStatsInterval = origin, originInterval }()
+
+	createRequestBuilderFunc = func(*types.WeightedRequest, int) (RESTRequestBuilder, error) {
+		return &fakeCacheBuilder{}, nil
+	}
+	distributionStatsInterval = 10 * time.Millisecond
+
+	exec, err := NewWeightedRandomExecutor(&types.LoadProfileSpec{
+		Mode: types.ModeWeightedRandom,
+		ModeConfig: &types.WeightedRandomConfig{
+			Rate: 100,
+			Requests: []*types.WeightedRequest{
+				{
+					Shares: 1,
+					StaleList: &types.RequestList{
+						KubeGroupVersionResource: types.KubeGroupVersionResource{Version: "v1", Resource: "pods"},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = exec.Run(ctx)
+	}()
+
+	// NOTE: The executor is blocked on sending the second request so that
+	// the snapshots are emitted without new picks.
+	<-exec.Chan()
+	statsCh := exec.(DistributionStatsProvider).DistributionStats()
+	var snapshots []DistributionSnapshot
+	for len(snapshots) < 3 {
+		snapshots = append(snapshots, <-statsCh)
+	}
+
+	total := int64(0)
+	for _, s := range snapshots {
+		assert.Equal(t, map[string]float64{"staleList": 1}, s.TheoreticalWeights)
+		total += s.RequestTypeCounts["staleList"]
+	}
+	assert.Equal(t, int64(1), total, "each pick is counted once")
+
+	exec.Stop()
+	for range statsCh {
+	}
+}
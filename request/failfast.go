@@ -0,0 +1,54 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Azure/kperf/api/types"
+)
+
+// ErrFailFast is the termination cause if ErrorCallback terminates Schedule.
+var ErrFailFast = errors.New("fail fast on request error")
+
+// ErrorCallback is called with every failed request by workers concurrently.
+// Schedule is terminated if it returns true.
+type ErrorCallback func(respErr types.ResponseError) bool
+
+// FailFast terminates Schedule once MaxErrors errors of Type are observed.
+// Its OnError is used as ErrorCallback.
+type FailFast struct {
+	// MaxErrors is the number of errors to terminate Schedule. Zero means
+	// one error.
+	MaxErrors int
+	// Type is the type of errors to count. Empty means any type.
+	Type types.ResponseErrorType
+
+	count atomic.Int64
+}
+
+// OnError implements ErrorCallback.
+func (f *FailFast) OnError(respErr types.ResponseError) bool {
+	if f.Type != "" && respErr.Type != f.Type {
+		return false
+	}
+	return f.count.Add(1) >= int64(max(f.MaxErrors, 1))
+}
+
+// failFastError is the cancellation cause if ErrorCallback terminates
+// Schedule.
+type failFastError struct {
+	respErr types.ResponseError
+}
+
+func (e *failFastError) Error() string {
+	return fmt.Sprintf("%v: %s %s failed with %s error", ErrFailFast,
+		e.respErr.Method, e.respErr.URL, e.respErr.Type)
+}
+
+func (e *failFastError) Unwrap() error {
+	return ErrFailFast
+}
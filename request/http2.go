@@ -0,0 +1,87 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+const (
+	// http2MinFrameSize and http2MaxFrameSize are the bounds of
+	// SETTINGS_MAX_FRAME_SIZE defined by RFC 9113.
+	http2MinFrameSize = 1 << 14
+	http2MaxFrameSize = 1<<24 - 1
+
+	// http2ReadIdleTimeout and http2PingTimeout are client-go's defaults
+	// of HTTP2 connection health check.
+	//
+	// REF: https://github.com/kubernetes/apimachinery/blob/v0.31.1/pkg/util/net/http.go#L174
+	http2ReadIdleTimeout = 30 * time.Second
+	http2PingTimeout     = 15 * time.Second
+)
+
+// validateHTTP2Settings returns error if any setting isn't supported or its
+// value is out of range.
+func validateHTTP2Settings(settings map[http2.SettingID]uint32) error {
+	for id, v := range settings {
+		switch id {
+		case http2.SettingHeaderTableSize:
+		case http2.SettingInitialWindowSize:
+			if v == 0 || v > math.MaxInt32 {
+				return fmt.Errorf("invalid HTTP2 setting %s=%d: requires (0, %d]", id, v, math.MaxInt32)
+			}
+			if !supportsHTTP2InitialWindowSize {
+				return fmt.Errorf("HTTP2 setting %s requires kperf built with go1.24+", id)
+			}
+		case http2.SettingMaxFrameSize:
+			if v < http2MinFrameSize || v > http2MaxFrameSize {
+				return fmt.Errorf("invalid HTTP2 setting %s=%d: requires [%d, %d]",
+					id, v, http2MinFrameSize, http2MaxFrameSize)
+			}
+		default:
+			return fmt.Errorf("unsupported HTTP2 setting %s", id)
+		}
+	}
+	return nil
+}
+
+// configureHTTP2Settings returns a clone of t whose HTTP2 transport sends
+// the settings in the initial SETTINGS frame. It returns t if t doesn't use
+// HTTP2.
+//
+// NOTE: golang.org/x/net/http2.Transport doesn't accept raw settings. Each
+// setting is mapped to the transport field which is advertised by it.
+// client-go has configured the HTTP2 transport without exposing it and it
+// can't be registered again into the same transport. So the transport is
+// cloned and configured with client-go's health check defaults.
+func configureHTTP2Settings(t *http.Transport, settings map[http2.SettingID]uint32) (*http.Transport, error) {
+	if _, ok := t.TLSNextProto[http2.NextProtoTLS]; !ok {
+		return t, nil
+	}
+
+	t1 := t.Clone()
+	t2, err := http2.ConfigureTransports(t1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure HTTP2 transport: %w", err)
+	}
+	t2.ReadIdleTimeout = http2ReadIdleTimeout
+	t2.PingTimeout = http2PingTimeout
+
+	for id, v := range settings {
+		switch id {
+		case http2.SettingHeaderTableSize:
+			t2.MaxDecoderHeaderTableSize = v
+		case http2.SettingInitialWindowSize:
+			setHTTP2InitialWindowSize(t1, v)
+		case http2.SettingMaxFrameSize:
+			t2.MaxReadFrameSize = v
+		}
+	}
+	return t1, nil
+}
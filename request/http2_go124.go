@@ -0,0 +1,21 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+//go:build go1.24
+
+package request
+
+import "net/http"
+
+// supportsHTTP2InitialWindowSize is true since http.Transport.HTTP2 is
+// respected by golang.org/x/net/http2 for go1.24+.
+const supportsHTTP2InitialWindowSize = true
+
+// setHTTP2InitialWindowSize sets the SETTINGS_INITIAL_WINDOW_SIZE sent by
+// HTTP2 transport.
+func setHTTP2InitialWindowSize(t *http.Transport, size uint32) {
+	if t.HTTP2 == nil {
+		t.HTTP2 = &http.HTTP2Config{}
+	}
+	t.HTTP2.MaxReceiveBufferPerStream = int(size)
+}
@@ -0,0 +1,15 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+//go:build !go1.24
+
+package request
+
+import "net/http"
+
+// supportsHTTP2InitialWindowSize is false since golang.org/x/net/http2
+// always uses the default stream window size before go1.24.
+const supportsHTTP2InitialWindowSize = false
+
+// setHTTP2InitialWindowSize is no-op before go1.24.
+func setHTTP2InitialWindowSize(*http.Transport, uint32) {}
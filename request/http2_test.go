@@ -0,0 +1,158 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+// recordingConn records the bytes read from client.
+type recordingConn struct {
+	net.Conn
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.buf.Write(p[:n])
+	return n, err
+}
+
+// newHTTP2SettingsCaptureServer returns HTTP2 server which captures the
+// initial SETTINGS frame of each connection.
+func newHTTP2SettingsCaptureServer(t *testing.T) (*httptest.Server, func() map[http2.SettingID]uint32) {
+	var (
+		mu    sync.Mutex
+		conns []*recordingConn
+	)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.EnableHTTP2 = true
+	srv.Config.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){
+		http2.NextProtoTLS: func(hs *http.Server, conn *tls.Conn, h http.Handler) {
+			rc := &recordingConn{Conn: conn}
+
+			mu.Lock()
+			conns = append(conns, rc)
+			mu.Unlock()
+
+			(&http2.Server{}).ServeConn(rc, &http2.ServeConnOpts{BaseConfig: hs, Handler: h})
+		},
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	return srv, func() map[http2.SettingID]uint32 {
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, conns, 1)
+
+		rc := conns[0]
+		rc.mu.Lock()
+		data := bytes.Clone(rc.buf.Bytes())
+		rc.mu.Unlock()
+
+		require.True(t, bytes.HasPrefix(data, []byte(http2.ClientPreface)))
+		framer := http2.NewFramer(nil, bytes.NewReader(data[len(http2.ClientPreface):]))
+		f, err := framer.ReadFrame()
+		require.NoError(t, err)
+		sf, ok := f.(*http2.SettingsFrame)
+		require.True(t, ok, "the first frame is %T", f)
+
+		settings := map[http2.SettingID]uint32{}
+		require.NoError(t, sf.ForeachSetting(func(s http2.Setting) error {
+			settings[s.ID] = s.Val
+			return nil
+		}))
+		return settings
+	}
+}
+
+func TestNewClientWithHTTP2Settings(t *testing.T) {
+	srv, capturedSettings := newHTTP2SettingsCaptureServer(t)
+	kubeCfgPath := newTestKubeconfig(t, srv.URL)
+
+	settings := map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize: 8192,
+		http2.SettingMaxFrameSize:    1 << 15,
+	}
+	if supportsHTTP2InitialWindowSize {
+		settings[http2.SettingInitialWindowSize] = 1 << 20
+	}
+
+	clis, err := NewClients(kubeCfgPath, 1, WithClientHTTP2SettingsOpt(settings))
+	require.NoError(t, err)
+	require.NoError(t, clis[0].Get().AbsPath("/api/v1/pods").Do(context.TODO()).Error())
+
+	captured := capturedSettings()
+	for id, v := range settings {
+		assert.Equal(t, v, captured[id], "setting %s", id)
+	}
+	// The other settings are kept.
+	assert.Equal(t, uint32(0), captured[http2.SettingEnablePush])
+}
+
+func TestValidateHTTP2Settings(t *testing.T) {
+	for name, tc := range map[string]struct {
+		settings map[http2.SettingID]uint32
+		hasError bool
+	}{
+		"empty": {
+			settings: map[http2.SettingID]uint32{},
+		},
+		"header table size": {
+			settings: map[http2.SettingID]uint32{http2.SettingHeaderTableSize: 0},
+		},
+		"max frame size": {
+			settings: map[http2.SettingID]uint32{http2.SettingMaxFrameSize: 1<<24 - 1},
+		},
+		"too small max frame size": {
+			settings: map[http2.SettingID]uint32{http2.SettingMaxFrameSize: 1024},
+			hasError: true,
+		},
+		"too large max frame size": {
+			settings: map[http2.SettingID]uint32{http2.SettingMaxFrameSize: 1 << 24},
+			hasError: true,
+		},
+		"zero initial window size": {
+			settings: map[http2.SettingID]uint32{http2.SettingInitialWindowSize: 0},
+			hasError: true,
+		},
+		"too large initial window size": {
+			settings: map[http2.SettingID]uint32{http2.SettingInitialWindowSize: 1 << 31},
+			hasError: true,
+		},
+		"unsupported": {
+			settings: map[http2.SettingID]uint32{http2.SettingEnablePush: 1},
+			hasError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := validateHTTP2Settings(tc.settings)
+			if tc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/kperf/api/types"
+
+	"k8s.io/client-go/rest"
+)
+
+// ScheduleMultiMode executes requests of multiple modes simultaneously. It's
+// Schedule in composite mode whose children are the given specs' modes, so
+// that their requests compete for the shared worker pool and each mode keeps
+// its own rate limiter.
+//
+// The worker pool's size is the sum of each spec's clients and the other
+// settings, like content type, are from the first spec. The child is named
+// after its mode, suffixed with the spec's index if there are multiple specs
+// with the same mode.
+func ScheduleMultiMode(ctx context.Context, specs []types.LoadProfileSpec, restClis []rest.Interface, opts ...ScheduleOpt) (*Result, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("required at least one spec")
+	}
+
+	tags := multiModeTags(specs)
+	children := make([]types.CompositeChild, 0, len(specs))
+
+	composite := specs[0]
+	composite.Client = 0
+	for idx, spec := range specs {
+		children = append(children, types.CompositeChild{
+			Name:       tags[idx],
+			Mode:       spec.Mode,
+			ModeConfig: spec.ModeConfig,
+		})
+		if spec.Client > 0 {
+			composite.Client += spec.Client
+		} else {
+			composite.Client += spec.Conns
+		}
+	}
+	composite.Mode = types.ModeComposite
+	composite.ModeConfig = &types.CompositeConfig{Children: children}
+
+	return Schedule(ctx, &composite, restClis, opts...)
+}
+
+// multiModeTags returns the child name of each spec.
+func multiModeTags(specs []types.LoadProfileSpec) []string {
+	counts := make(map[types.ExecutionMode]int, len(specs))
+	for _, spec := range specs {
+		counts[spec.Mode]++
+	}
+
+	tags := make([]string, 0, len(specs))
+	for idx, spec := range specs {
+		tag := string(spec.Mode)
+		if counts[spec.Mode] > 1 {
+			tag = fmt.Sprintf("%s-%d", spec.Mode, idx)
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
@@ -0,0 +1,124 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleMultiMode(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		first = map[string]time.Time{}
+		last  = map[string]time.Time{}
+	)
+
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		kind := "list"
+		if strings.HasSuffix(r.URL.Path, "/pods/a") {
+			kind = "get"
+		}
+
+		mu.Lock()
+		now := time.Now()
+		if _, ok := first[kind]; !ok {
+			first[kind] = now
+		}
+		last[kind] = now
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+		writePodList(w, r)
+	})
+
+	buckets := make([]types.RequestBucket, 2)
+	for i := range buckets {
+		buckets[i] = types.RequestBucket{
+			StartTime: 0,
+			Requests: []types.ExactRequest{
+				{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "a"},
+				{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "a"},
+				{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "a"},
+				{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "a"},
+				{Method: "GET", Version: "v1", Resource: "pods", Namespace: "default", Name: "a"},
+			},
+		}
+	}
+
+	listSpec, listCfg := newStaleListSpec()
+	listSpec.Conns, listSpec.Client = 2, 2
+	listCfg.Total = 20
+
+	specs := []types.LoadProfileSpec{
+		*listSpec,
+		{
+			Conns:       2,
+			Client:      2,
+			ContentType: types.ContentTypeJSON,
+			Mode:        types.ModeTimeSeries,
+			ModeConfig: &types.TimeSeriesConfig{
+				Interval: "1s",
+				Buckets:  buckets,
+			},
+		},
+	}
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), 2)
+	require.NoError(t, err)
+
+	res, err := ScheduleMultiMode(context.TODO(), specs, clis)
+	require.NoError(t, err)
+	assert.NoError(t, res.TerminationCause)
+	assert.Empty(t, res.Errors)
+
+	assert.Equal(t, 30, res.Total)
+
+	// Each mode's requests are told apart by URL.
+	counts := map[string]int{}
+	for key, latencies := range res.LatenciesByURL {
+		kind := "list"
+		if strings.Contains(key, "/pods/a") {
+			kind = "get"
+		}
+		counts[kind] += len(latencies)
+	}
+	assert.Equal(t, map[string]int{"list": 20, "get": 10}, counts)
+
+	// Both modes run as children of composite mode, named after the mode.
+	require.NotNil(t, res.ExecutorReport)
+	assert.Contains(t, res.ExecutorReport.Children, string(types.ModeWeightedRandom))
+	assert.Contains(t, res.ExecutorReport.Children, string(types.ModeTimeSeries))
+
+	// Requests from both modes share the 4 workers.
+	require.Len(t, res.RequestsByWorker, 4)
+	total := int64(0)
+	for _, n := range res.RequestsByWorker {
+		total += n
+	}
+	assert.Equal(t, int64(30), total)
+
+	// Both modes produce requests concurrently so that their requests
+	// interleave.
+	assert.True(t, first["get"].Before(last["list"]))
+	assert.True(t, first["list"].Before(last["get"]))
+}
+
+func TestMultiModeTags(t *testing.T) {
+	tags := multiModeTags([]types.LoadProfileSpec{
+		{Mode: types.ModeWeightedRandom},
+		{Mode: types.ModeTimeSeries},
+		{Mode: types.ModeWeightedRandom},
+	})
+	assert.Equal(t, []string{"weighted-random-0", "time-series", "weighted-random-2"}, tags)
+}
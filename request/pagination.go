@@ -0,0 +1,69 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// protobufMagic is the prefix of kubernetes protobuf-encoded objects.
+//
+// REF: https://kubernetes.io/docs/reference/using-api/api-concepts/#protobuf-encoding
+var protobufMagic = []byte{0x6b, 0x38, 0x73, 0x00}
+
+// listContinueToken returns the continue token from list response in either
+// json or protobuf format.
+func listContinueToken(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, protobufMagic) {
+		var list struct {
+			Metadata metav1.ListMeta `json:"metadata"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return "", fmt.Errorf("failed to decode list in json: %w", err)
+		}
+		return list.Metadata.Continue, nil
+	}
+
+	var unknown runtime.Unknown
+	if err := unknown.Unmarshal(data[len(protobufMagic):]); err != nil {
+		return "", fmt.Errorf("failed to decode list in protobuf: %w", err)
+	}
+
+	// All the built-in list types use field 1 for ListMeta.
+	raw := unknown.Raw
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return "", fmt.Errorf("failed to decode list in protobuf: %w", protowire.ParseError(n))
+		}
+		raw = raw[n:]
+
+		if num != 1 || typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, raw)
+			if n < 0 {
+				return "", fmt.Errorf("failed to decode list in protobuf: %w", protowire.ParseError(n))
+			}
+			raw = raw[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(raw)
+		if n < 0 {
+			return "", fmt.Errorf("failed to decode list in protobuf: %w", protowire.ParseError(n))
+		}
+
+		var listMeta metav1.ListMeta
+		if err := listMeta.Unmarshal(value); err != nil {
+			return "", fmt.Errorf("failed to decode list's metadata in protobuf: %w", err)
+		}
+		return listMeta.Continue, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,121 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestListContinueToken(t *testing.T) {
+	token, err := listContinueToken([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{"continue":"abc"},"items":[]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", token)
+
+	token, err = listContinueToken([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "", token)
+
+	list := &corev1.PodList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "10", Continue: "def"},
+		Items:    []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "a"}}},
+	}
+	raw, err := list.Marshal()
+	require.NoError(t, err)
+
+	unknown := runtime.Unknown{
+		TypeMeta: runtime.TypeMeta{APIVersion: "v1", Kind: "PodList"},
+		Raw:      raw,
+	}
+	data, err := unknown.Marshal()
+	require.NoError(t, err)
+
+	token, err = listContinueToken(append(append([]byte{}, protobufMagic...), data...))
+	require.NoError(t, err)
+	assert.Equal(t, "def", token)
+
+	_, err = listContinueToken([]byte("not a list"))
+	assert.Error(t, err)
+}
+
+func TestPaginatedListRequester(t *testing.T) {
+	const totalPages = 4
+
+	var requests int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+
+		page := 1
+		if c := r.URL.Query().Get("continue"); c != "" {
+			assert.Empty(t, r.URL.Query().Get("resourceVersion"))
+			page, _ = strconv.Atoi(c)
+		}
+
+		next := ""
+		if page < totalPages {
+			next = strconv.Itoa(page + 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"kind":"PodList","apiVersion":"v1","metadata":{"continue":%q},"items":[]}`, next)
+	}))
+	defer srv.Close()
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), 1)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		src      types.RequestList
+		expected int64
+	}{
+		{
+			name:     "first page only",
+			src:      types.RequestList{Limit: 10},
+			expected: 1,
+		},
+		{
+			name:     "until exhausted",
+			src:      types.RequestList{Limit: 10, Paginate: true},
+			expected: totalPages,
+		},
+		{
+			name:     "limit by page count",
+			src:      types.RequestList{Limit: 10, Paginate: true, LimitByPageCount: 2},
+			expected: 2,
+		},
+		{
+			name:     "page count larger than total",
+			src:      types.RequestList{Limit: 10, Paginate: true, LimitByPageCount: 10},
+			expected: totalPages,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			atomic.StoreInt64(&requests, 0)
+
+			tc.src.KubeGroupVersionResource = types.KubeGroupVersionResource{Version: "v1", Resource: "pods"}
+			builder, err := newRequestListBuilder(&tc.src, "", 0)
+			require.NoError(t, err)
+			reqr := builder.Build(clis[0])
+			reqr.Timeout(defaultTimeout)
+
+			bytes, err := reqr.Do(context.TODO())
+			require.NoError(t, err)
+			assert.Greater(t, bytes, int64(0))
+			assert.Equal(t, tc.expected, atomic.LoadInt64(&requests))
+		})
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/metrics"
+	"github.com/Azure/kperf/request/executor"
+)
+
+// RunnerController controls running Schedule. It's implemented by Progress.
+type RunnerController interface {
+	// Status returns the snapshot of Schedule's state.
+	Status() types.RunnerStatus
+	// Stop cancels Schedule gracefully.
+	Stop()
+	// UpdateRequests replaces the requests of running Schedule.
+	UpdateRequests(requests []*types.WeightedRequest) error
+	// UpdateRate changes the requests per second of running Schedule.
+	UpdateRate(qps float64) error
+	// Pause holds running Schedule from sending new requests.
+	Pause() error
+	// Resume continues paused Schedule.
+	Resume() error
+	// DistributionStats returns the live distribution of picked requests
+	// of running Schedule.
+	DistributionStats() (<-chan executor.DistributionSnapshot, error)
+}
+
+var _ RunnerController = &Progress{}
+
+// errScheduleNotStarted is returned if Schedule isn't started yet.
+var errScheduleNotStarted = errors.New("schedule is not started")
+
+// rateSampleInterval is the minimum interval to sample current rate.
+const rateSampleInterval = time.Second
+
+// Progress exposes the state of running Schedule. It's safe for concurrent
+// use. The zero value is ready to use and it's attached by Schedule through
+// WithScheduleProgressOpt.
+type Progress struct {
+	completed int64
+	failed    int64
+	// paused is the fast path for workers to skip waitResumed.
+	paused atomic.Bool
+
+	mu            sync.Mutex
+	start         time.Time
+	end           time.Time
+	expectedTotal int
+	respMetric    metrics.ResponseMetric
+	exec          executor.Executor
+	cancel        context.CancelCauseFunc
+	stopped       bool
+	// resumeCh is closed by Resume to release the paused workers.
+	resumeCh chan struct{}
+
+	// last sample for current rate
+	sampledAt   time.Time
+	sampled     int64
+	currentRate float64
+}
+
+// attach binds Progress with running Schedule. If Stop has been called, the
+// Schedule is canceled immediately.
+func (p *Progress) attach(start time.Time, expectedTotal int, respMetric metrics.ResponseMetric, exec executor.Executor, cancel context.CancelCauseFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.start = start
+	p.sampledAt = start
+	p.expectedTotal = expectedTotal
+	p.respMetric = respMetric
+	p.exec = exec
+	p.cancel = cancel
+	if p.stopped {
+		cancel(ErrScheduleStopped)
+	}
+}
+
+// observe records one finished request.
+func (p *Progress) observe(err error) {
+	atomic.AddInt64(&p.completed, 1)
+	if err != nil {
+		atomic.AddInt64(&p.failed, 1)
+	}
+}
+
+// finish marks Schedule is done.
+func (p *Progress) finish(end time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.end = end
+}
+
+// Completed returns the number of finished requests.
+func (p *Progress) Completed() int64 {
+	return atomic.LoadInt64(&p.completed)
+}
+
+// Failed returns the number of failed requests.
+func (p *Progress) Failed() int64 {
+	return atomic.LoadInt64(&p.failed)
+}
+
+// Status returns the snapshot of Schedule's state.
+func (p *Progress) Status() types.RunnerStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	completed := atomic.LoadInt64(&p.completed)
+
+	status := types.RunnerStatus{
+		Completed:     completed,
+		Errors:        atomic.LoadInt64(&p.failed),
+		ExpectedTotal: p.expectedTotal,
+		OpenWatches:   OpenWatches(),
+		Stopped:       p.stopped,
+		Paused:        p.resumeCh != nil,
+	}
+
+	if p.start.IsZero() {
+		status.State = types.RunnerStatePending
+		return status
+	}
+
+	now := time.Now()
+	status.State = types.RunnerStateRunning
+	if !p.end.IsZero() {
+		status.State = types.RunnerStateFinished
+		now = p.end
+	}
+
+	elapsed := now.Sub(p.start)
+	status.Elapsed = elapsed.Round(time.Millisecond).String()
+
+	if p.end.IsZero() {
+		if d := now.Sub(p.sampledAt); d >= rateSampleInterval {
+			p.currentRate = float64(completed-p.sampled) / d.Seconds()
+			p.sampledAt, p.sampled = now, completed
+		}
+		status.CurrentRate = p.currentRate
+	}
+	if elapsed > 0 {
+		status.AverageRate = float64(completed) / elapsed.Seconds()
+	}
+	if p.exec != nil && p.end.IsZero() {
+		status.InFlight, _ = p.exec.Metadata().Custom["in_flight_count"].(int64)
+	}
+	return status
+}
+
+// Result returns the partial result of Schedule. It returns nil if Schedule
+// isn't started yet.
+func (p *Progress) Result() *Result {
+	p.mu.Lock()
+	start, end, respMetric := p.start, p.end, p.respMetric
+	p.mu.Unlock()
+
+	if respMetric == nil {
+		return nil
+	}
+
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return &Result{
+		ResponseStats: respMetric.Gather(),
+		Duration:      end.Sub(start),
+		Total:         int(p.Completed()),
+	}
+}
+
+// Stop cancels Schedule gracefully. The in-flight requests are finished and
+// Schedule returns result as usual, with ErrScheduleStopped as termination
+// cause.
+func (p *Progress) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopped = true
+	if p.cancel != nil {
+		p.cancel(ErrScheduleStopped)
+	}
+	// NOTE: Paused workers hold the builders, which must be released
+	// so that Schedule returns.
+	p.resumeLocked()
+}
+
+// UpdateRequests replaces the requests of running Schedule, like shifting
+// weight from reads to writes. It fails if Schedule isn't running or its
+// executor doesn't support it, which is only weighted-random mode for now.
+func (p *Progress) UpdateRequests(requests []*types.WeightedRequest) error {
+	exec, err := p.runningExecutor()
+	if err != nil {
+		return err
+	}
+
+	updater, ok := exec.(executor.RequestUpdater)
+	if !ok {
+		return executor.ErrUpdateRequestsNotSupported
+	}
+	return updater.UpdateRequests(requests)
+}
+
+// UpdateRate changes the requests per second of running Schedule. Zero
+// means unlimited. It fails if Schedule isn't running or its executor
+// doesn't support it, which is only weighted-random mode for now.
+func (p *Progress) UpdateRate(qps float64) error {
+	exec, err := p.runningExecutor()
+	if err != nil {
+		return err
+	}
+
+	updater, ok := exec.(executor.RateUpdater)
+	if !ok {
+		return executor.ErrUpdateRateNotSupported
+	}
+	return updater.UpdateRate(qps)
+}
+
+// DistributionStats returns the channel which emits the distribution of
+// picked requests by type every second, so that it can be compared with the
+// theoretical weights. The channel is closed after executor finishes. It
+// fails if Schedule isn't running or its executor doesn't support it, which
+// is only weighted-random mode for now.
+func (p *Progress) DistributionStats() (<-chan executor.DistributionSnapshot, error) {
+	exec, err := p.runningExecutor()
+	if err != nil {
+		return nil, err
+	}
+
+	provider, ok := exec.(executor.DistributionStatsProvider)
+	if !ok {
+		return nil, executor.ErrDistributionStatsNotSupported
+	}
+	ch := provider.DistributionStats()
+	if ch == nil {
+		return nil, executor.ErrDistributionStatsNotSupported
+	}
+	return ch, nil
+}
+
+// Pause holds running Schedule from sending new requests until Resume. The
+// in-flight requests are finished as usual. Pausing doesn't extend the
+// duration of executor, like time-series mode's buckets.
+func (p *Progress) Pause() error {
+	if _, err := p.runningExecutor(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.resumeCh == nil {
+		p.resumeCh = make(chan struct{})
+		p.paused.Store(true)
+	}
+	return nil
+}
+
+// Resume continues Schedule paused by Pause. It's no-op if Schedule isn't
+// paused.
+func (p *Progress) Resume() error {
+	if _, err := p.runningExecutor(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.resumeLocked()
+	return nil
+}
+
+// resumeLocked releases the paused workers. p.mu must be held.
+func (p *Progress) resumeLocked() {
+	if p.resumeCh != nil {
+		close(p.resumeCh)
+		p.resumeCh = nil
+		p.paused.Store(false)
+	}
+}
+
+// waitResumed blocks the worker while Schedule is paused. It returns the
+// cancellation cause if Schedule is canceled, except errScheduleDone,
+// because the builders produced before executor is done are still sent
+// after resuming.
+func (p *Progress) waitResumed(ctx context.Context) error {
+	if !p.paused.Load() {
+		return nil
+	}
+
+	p.mu.Lock()
+	resumeCh := p.resumeCh
+	p.mu.Unlock()
+
+	if resumeCh == nil {
+		return nil
+	}
+
+	select {
+	case <-resumeCh:
+		return nil
+	case <-ctx.Done():
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, errScheduleDone) {
+		return cause
+	}
+	<-resumeCh
+	return nil
+}
+
+// runningExecutor returns the executor of running Schedule.
+func (p *Progress) runningExecutor() (executor.Executor, error) {
+	p.mu.Lock()
+	exec, end := p.exec, p.end
+	p.mu.Unlock()
+
+	if exec == nil {
+		return nil, errScheduleNotStarted
+	}
+	if !end.IsZero() {
+		return nil, errScheduleDone
+	}
+	return exec, nil
+}
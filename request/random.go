@@ -6,9 +6,18 @@ package request
 import (
 	"context"
 	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"math/big"
+	"net/url"
+	"path"
+	"strconv"
 	"sync/atomic"
+	"text/template"
 	"time"
 
 	"github.com/Azure/kperf/api/types"
@@ -17,10 +26,12 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	apitypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
 )
 
 // RESTRequestBuilder is used to build rest.Request.
@@ -33,47 +44,79 @@ type requestGetBuilder struct {
 	name            string
 	resourceVersion string
 	maxRetries      int
+
+	// absPath and params are immutable so that they're computed once.
+	absPath string
+	params  url.Values
 }
 
-func newRequestGetBuilder(src *types.RequestGet, resourceVersion string, maxRetries int) *requestGetBuilder {
+func newRequestGetBuilder(src *types.RequestGet, resourceVersion string, maxRetries int) (*requestGetBuilder, error) {
+	version := schema.GroupVersion{
+		Group:   src.Group,
+		Version: src.Version,
+	}
+	params, err := encodeV1Params(&metav1.GetOptions{ResourceVersion: resourceVersion})
+	if err != nil {
+		return nil, err
+	}
 	return &requestGetBuilder{
-		version: schema.GroupVersion{
-			Group:   src.Group,
-			Version: src.Version,
-		},
+		version:         version,
 		resource:        src.Resource,
 		namespace:       src.Namespace,
 		name:            src.Name,
 		resourceVersion: resourceVersion,
 		maxRetries:      maxRetries,
-	}
+
+		absPath: resourcePath(version, src.Namespace, src.Resource, src.Name),
+		params:  params,
+	}, nil
 }
 
 // Build implements RequestBuilder.Build.
 func (b *requestGetBuilder) Build(cli rest.Interface) Requester {
+	return &DiscardRequester{
+		BaseRequester: BaseRequester{
+			method: "GET",
+			req:    withParams(cli.Get().AbsPath(b.absPath), b.params).MaxRetries(b.maxRetries),
+		},
+	}
+}
+
+// resourcePath returns the absolute path of resource. The name is optional.
+func resourcePath(version schema.GroupVersion, namespace, resource, name string) string {
 	// https://kubernetes.io/docs/reference/using-api/#api-groups
-	comps := make([]string, 0, 5)
-	if b.version.Group == "" {
-		comps = append(comps, "api", b.version.Version)
+	comps := make([]string, 0, 7)
+	if version.Group == "" {
+		comps = append(comps, "api", version.Version)
 	} else {
-		comps = append(comps, "apis", b.version.Group, b.version.Version)
+		comps = append(comps, "apis", version.Group, version.Version)
 	}
-	if b.namespace != "" {
-		comps = append(comps, "namespaces", b.namespace)
+	if namespace != "" {
+		comps = append(comps, "namespaces", namespace)
 	}
-	comps = append(comps, b.resource, b.name)
+	comps = append(comps, resource, name)
+	return path.Join(comps...)
+}
 
-	return &DiscardRequester{
-		BaseRequester: BaseRequester{
-			method: "GET",
-			req: cli.Get().AbsPath(comps...).
-				SpecificallyVersionedParams(
-					&metav1.GetOptions{ResourceVersion: b.resourceVersion},
-					scheme.ParameterCodec,
-					schema.GroupVersion{Version: "v1"},
-				).MaxRetries(b.maxRetries),
-		},
+// encodeV1Params encodes options into query parameters in the same way as
+// rest.Request.SpecificallyVersionedParams. The builders encode immutable
+// options once instead of for each request.
+func encodeV1Params(opts runtime.Object) (url.Values, error) {
+	params, err := scheme.ParameterCodec.EncodeParameters(opts, schema.GroupVersion{Version: "v1"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %T as query parameters: %w", opts, err)
+	}
+	return params, nil
+}
+
+// withParams adds the query parameters to request.
+func withParams(req *rest.Request, params url.Values) *rest.Request {
+	for k, vs := range params {
+		for _, v := range vs {
+			req = req.Param(k, v)
+		}
 	}
+	return req
 }
 
 type requestListBuilder struct {
@@ -81,14 +124,29 @@ type requestListBuilder struct {
 	resource        string
 	namespace       string
 	limit           int64
+	paginate        bool
+	maxPages        int
 	labelSelector   string
 	fieldSelector   string
 	resourceVersion string
-	maxRetries      int
+	// resourceVersionMatch is Exact for exact consistency. Otherwise,
+	// it's empty so that apiserver uses the default semantics.
+	resourceVersionMatch metav1.ResourceVersionMatch
+	maxRetries           int
+
+	// absPath and params of the first page are immutable so that they're
+	// computed once.
+	absPath string
+	params  url.Values
 }
 
-func newRequestListBuilder(src *types.RequestList, resourceVersion string, maxRetries int) *requestListBuilder {
-	return &requestListBuilder{
+func newRequestListBuilder(src *types.RequestList, resourceVersion string, maxRetries int) (*requestListBuilder, error) {
+	var resourceVersionMatch metav1.ResourceVersionMatch
+	if src.Consistency == types.ConsistencyExact {
+		resourceVersionMatch = metav1.ResourceVersionMatchExact
+	}
+
+	b := &requestListBuilder{
 		version: schema.GroupVersion{
 			Group:   src.Group,
 			Version: src.Version,
@@ -96,41 +154,58 @@ func newRequestListBuilder(src *types.RequestList, resourceVersion string, maxRe
 		resource:        src.Resource,
 		namespace:       src.Namespace,
 		limit:           int64(src.Limit),
+		paginate:        src.Paginate,
+		maxPages:        src.LimitByPageCount,
 		labelSelector:   src.Selector,
 		fieldSelector:   src.FieldSelector,
 		resourceVersion: resourceVersion,
 		maxRetries:      maxRetries,
+
+		resourceVersionMatch: resourceVersionMatch,
+	}
+	b.absPath = resourcePath(b.version, b.namespace, b.resource, "")
+
+	var err error
+	b.params, err = encodeV1Params(b.listOptions(resourceVersion, resourceVersionMatch, ""))
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// listOptions returns the options of the given page.
+func (b *requestListBuilder) listOptions(resourceVersion string, resourceVersionMatch metav1.ResourceVersionMatch, continueToken string) *metav1.ListOptions {
+	return &metav1.ListOptions{
+		LabelSelector:        b.labelSelector,
+		FieldSelector:        b.fieldSelector,
+		ResourceVersion:      resourceVersion,
+		ResourceVersionMatch: resourceVersionMatch,
+		Limit:                b.limit,
+		Continue:             continueToken,
 	}
 }
 
 // Build implements RequestBuilder.Build.
 func (b *requestListBuilder) Build(cli rest.Interface) Requester {
-	// https://kubernetes.io/docs/reference/using-api/#api-groups
-	comps := make([]string, 0, 5)
-	if b.version.Group == "" {
-		comps = append(comps, "api", b.version.Version)
-	} else {
-		comps = append(comps, "apis", b.version.Group, b.version.Version)
-	}
-	if b.namespace != "" {
-		comps = append(comps, "namespaces", b.namespace)
+	baseReqr := BaseRequester{
+		method: "LIST",
+		req:    withParams(cli.Get().AbsPath(b.absPath), b.params).MaxRetries(b.maxRetries),
 	}
-	comps = append(comps, b.resource)
 
-	return &DiscardRequester{
-		BaseRequester: BaseRequester{
-			method: "LIST",
-			req: cli.Get().AbsPath(comps...).
+	if !b.paginate {
+		return &DiscardRequester{BaseRequester: baseReqr}
+	}
+	return &PaginatedListRequester{
+		BaseRequester: baseReqr,
+		maxPages:      b.maxPages,
+		nextPage: func(continueToken string) *rest.Request {
+			// The resourceVersion is encoded in continue token.
+			return cli.Get().AbsPath(b.absPath).
 				SpecificallyVersionedParams(
-					&metav1.ListOptions{
-						LabelSelector:   b.labelSelector,
-						FieldSelector:   b.fieldSelector,
-						ResourceVersion: b.resourceVersion,
-						Limit:           b.limit,
-					},
+					b.listOptions("", "", continueToken),
 					scheme.ParameterCodec,
 					schema.GroupVersion{Version: "v1"},
-				).MaxRetries(b.maxRetries),
+				).MaxRetries(b.maxRetries)
 		},
 	}
 }
@@ -142,72 +217,138 @@ type requestWatchListBuilder struct {
 	labelSelector string
 	fieldSelector string
 	maxRetries    int
+
+	// absPath and params are immutable so that they're computed once.
+	absPath string
+	params  url.Values
 }
 
-func newRequestWatchListBuilder(src *types.RequestWatchList, maxRetries int) *requestWatchListBuilder {
+func newRequestWatchListBuilder(src *types.RequestWatchList, maxRetries int) (*requestWatchListBuilder, error) {
+	version := schema.GroupVersion{
+		Group:   src.Group,
+		Version: src.Version,
+	}
+	params, err := encodeV1Params(&metav1.ListOptions{
+		LabelSelector:        src.Selector,
+		FieldSelector:        src.FieldSelector,
+		ResourceVersion:      "",
+		Watch:                true,
+		SendInitialEvents:    toPtr(true),
+		ResourceVersionMatch: metav1.ResourceVersionMatchNotOlderThan,
+		AllowWatchBookmarks:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
 	return &requestWatchListBuilder{
-		version: schema.GroupVersion{
-			Group:   src.Group,
-			Version: src.Version,
-		},
+		version:       version,
 		resource:      src.Resource,
 		namespace:     src.Namespace,
 		labelSelector: src.Selector,
 		fieldSelector: src.FieldSelector,
 		maxRetries:    maxRetries,
-	}
+
+		absPath: resourcePath(version, src.Namespace, src.Resource, ""),
+		params:  params,
+	}, nil
 }
 
 // Build implements RequestBuilder.Build.
 func (b *requestWatchListBuilder) Build(cli rest.Interface) Requester {
-	// https://kubernetes.io/docs/reference/using-api/#api-groups
-	comps := make([]string, 0, 5)
-	if b.version.Group == "" {
-		comps = append(comps, "api", b.version.Version)
-	} else {
-		comps = append(comps, "apis", b.version.Group, b.version.Version)
+	return &WatchListRequester{
+		BaseRequester: BaseRequester{
+			method: "WATCHLIST",
+			req:    withParams(cli.Get().AbsPath(b.absPath), b.params).MaxRetries(b.maxRetries),
+		},
 	}
-	if b.namespace != "" {
-		comps = append(comps, "namespaces", b.namespace)
+}
+
+type requestWatchChurnBuilder struct {
+	version       schema.GroupVersion
+	resource      string
+	namespace     string
+	labelSelector string
+	fieldSelector string
+	holdTime      time.Duration
+	maxRetries    int
+
+	// absPath and params are immutable so that they're computed once.
+	absPath string
+	params  url.Values
+}
+
+func newRequestWatchChurnBuilder(src *types.RequestWatchChurn, maxRetries int) (*requestWatchChurnBuilder, error) {
+	holdTime, err := time.ParseDuration(src.HoldTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid holdTime %q: %w", src.HoldTime, err)
 	}
-	comps = append(comps, b.resource)
 
-	return &WatchListRequester{
+	// NOTE: Leave enough room so that apiserver won't close the watch
+	// before holdTime.
+	timeoutSeconds := int64(math.Ceil(holdTime.Seconds())) + 30
+
+	version := schema.GroupVersion{
+		Group:   src.Group,
+		Version: src.Version,
+	}
+	params, err := encodeV1Params(&metav1.ListOptions{
+		LabelSelector:       src.Selector,
+		FieldSelector:       src.FieldSelector,
+		Watch:               true,
+		AllowWatchBookmarks: true,
+		TimeoutSeconds:      &timeoutSeconds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &requestWatchChurnBuilder{
+		version:       version,
+		resource:      src.Resource,
+		namespace:     src.Namespace,
+		labelSelector: src.Selector,
+		fieldSelector: src.FieldSelector,
+		holdTime:      holdTime,
+		maxRetries:    maxRetries,
+
+		absPath: resourcePath(version, src.Namespace, src.Resource, ""),
+		params:  params,
+	}, nil
+}
+
+// Build implements RequestBuilder.Build.
+func (b *requestWatchChurnBuilder) Build(cli rest.Interface) Requester {
+	return &WatchChurnRequester{
+		holdTime: b.holdTime,
 		BaseRequester: BaseRequester{
-			method: "WATCHLIST",
-			req: cli.Get().AbsPath(comps...).
-				SpecificallyVersionedParams(
-					&metav1.ListOptions{
-						LabelSelector:        b.labelSelector,
-						FieldSelector:        b.fieldSelector,
-						ResourceVersion:      "",
-						Watch:                true,
-						SendInitialEvents:    toPtr(true),
-						ResourceVersionMatch: metav1.ResourceVersionMatchNotOlderThan,
-						AllowWatchBookmarks:  true,
-					},
-					scheme.ParameterCodec,
-					schema.GroupVersion{Version: "v1"},
-				).MaxRetries(b.maxRetries),
+			method: "WATCHCHURN",
+			req:    withParams(cli.Get().AbsPath(b.absPath), b.params).MaxRetries(b.maxRetries),
 		},
 	}
 }
 
 type requestGetPodLogBuilder struct {
-	namespace  string
-	name       string
-	container  string
-	tailLines  *int64
-	limitBytes *int64
-	maxRetries int
+	namespace       string
+	name            string
+	container       string
+	tailLines       *int64
+	limitBytes      *int64
+	parseTimestamps bool
+	countLines      bool
+	maxRetries      int
+
+	// absPath and params are immutable so that they're computed once.
+	absPath string
+	params  url.Values
 }
 
-func newRequestGetPodLogBuilder(src *types.RequestGetPodLog, maxRetries int) *requestGetPodLogBuilder {
+func newRequestGetPodLogBuilder(src *types.RequestGetPodLog, maxRetries int) (*requestGetPodLogBuilder, error) {
 	b := &requestGetPodLogBuilder{
-		namespace:  src.Namespace,
-		name:       src.Name,
-		container:  src.Container,
-		maxRetries: maxRetries,
+		namespace:       src.Namespace,
+		name:            src.Name,
+		container:       src.Container,
+		parseTimestamps: src.ParseTimestamps,
+		countLines:      src.CountLines,
+		maxRetries:      maxRetries,
 	}
 	if src.TailLines != nil {
 		b.tailLines = toPtr(*src.TailLines)
@@ -215,34 +356,36 @@ func newRequestGetPodLogBuilder(src *types.RequestGetPodLog, maxRetries int) *re
 	if src.LimitBytes != nil {
 		b.limitBytes = toPtr(*src.LimitBytes)
 	}
-	return b
+
+	b.absPath = path.Join(resourcePath(schema.GroupVersion{Version: "v1"}, b.namespace, "pods", b.name), "log")
+
+	var err error
+	b.params, err = encodeV1Params(&corev1.PodLogOptions{
+		Container:  b.container,
+		TailLines:  b.tailLines,
+		LimitBytes: b.limitBytes,
+		Timestamps: b.parseTimestamps,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
 }
 
 // Build implements RequestBuilder.Build.
 func (b *requestGetPodLogBuilder) Build(cli rest.Interface) Requester {
-	// https://kubernetes.io/docs/reference/using-api/#api-groups
-	apiPath, version := "api", "v1"
-
-	comps := make([]string, 2, 7)
-	comps[0], comps[1] = apiPath, version
-	comps = append(comps, "namespaces", b.namespace)
-	comps = append(comps, "pods", b.name, "log")
-
-	return &DiscardRequester{
-		BaseRequester: BaseRequester{
-			method: "POD_LOG",
-			req: cli.Get().AbsPath(comps...).
-				SpecificallyVersionedParams(
-					&corev1.PodLogOptions{
-						Container:  b.container,
-						TailLines:  b.tailLines,
-						LimitBytes: b.limitBytes,
-					},
-					scheme.ParameterCodec,
-					schema.GroupVersion{Version: "v1"},
-				).MaxRetries(b.maxRetries),
-		},
+	base := BaseRequester{
+		method: "POD_LOG",
+		req:    withParams(cli.Get().AbsPath(b.absPath), b.params).MaxRetries(b.maxRetries),
+	}
+	if b.parseTimestamps || b.countLines {
+		return &LogParsingRequester{
+			BaseRequester:   base,
+			countLines:      b.countLines,
+			parseTimestamps: b.parseTimestamps,
+		}
 	}
+	return &DiscardRequester{BaseRequester: base}
 }
 
 type requestPatchBuilder struct {
@@ -251,61 +394,214 @@ type requestPatchBuilder struct {
 	resourceVersion string
 	namespace       string
 	name            string
-	keySpaceSize    int
+	keySpaceLow     int64
+	keySpaceHigh    int64
 	patchType       apitypes.PatchType
 	body            interface{}
+	bodyTemplate    *template.Template
 	maxRetries      int
+
+	// collectionPath is the immutable path of resource without name.
+	collectionPath string
 }
 
-func newRequestPatchBuilder(src *types.RequestPatch, resourceVersion string, maxRetries int) *requestPatchBuilder {
+func newRequestPatchBuilder(src *types.RequestPatch, resourceVersion string, maxRetries int) (*requestPatchBuilder, error) {
 	patchType, _ := types.GetPatchType(src.PatchType)
 
+	bodyTemplate, err := src.ParseBodyTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	keySpaceLow, keySpaceHigh := src.KeySpaceRange(src.KeySpaceSize)
+
+	version := schema.GroupVersion{
+		Group:   src.Group,
+		Version: src.Version,
+	}
 	return &requestPatchBuilder{
-		version: schema.GroupVersion{
-			Group:   src.Group,
-			Version: src.Version,
-		},
+		version:         version,
 		resource:        src.Resource,
 		resourceVersion: resourceVersion,
 		namespace:       src.Namespace,
 		name:            src.Name,
-		keySpaceSize:    src.KeySpaceSize,
+		keySpaceLow:     keySpaceLow,
+		keySpaceHigh:    keySpaceHigh,
 		patchType:       patchType,
 		body:            []byte(src.Body),
+		bodyTemplate:    bodyTemplate,
 		maxRetries:      maxRetries,
-	}
+
+		collectionPath: resourcePath(version, src.Namespace, src.Resource, ""),
+	}, nil
 }
 
 // Build implements RequestBuilder.Build.
 func (b *requestPatchBuilder) Build(cli rest.Interface) Requester {
-	// https://kubernetes.io/docs/reference/using-api/#api-groups
-	comps := make([]string, 0, 5)
-	if b.version.Group == "" {
-		comps = append(comps, "api", b.version.Version)
-	} else {
-		comps = append(comps, "apis", b.version.Group, b.version.Version)
-	}
-	if b.namespace != "" {
-		comps = append(comps, "namespaces", b.namespace)
-	}
-	// Generate random suffix based on keySpaceSize
-	randomInt, _ := rand.Int(rand.Reader, big.NewInt(int64(b.keySpaceSize)))
-	suffix := randomInt.Int64()
+	// Generate random suffix in the shard of keySpaceSize
+	randomInt, _ := rand.Int(rand.Reader, big.NewInt(b.keySpaceHigh-b.keySpaceLow))
+	suffix := b.keySpaceLow + randomInt.Int64()
 
 	// Create final resource name: name-{suffix}
-	finalName := fmt.Sprintf("%s-%d", b.name, suffix)
-	comps = append(comps, b.resource, finalName)
+	finalName := b.name + "-" + strconv.FormatInt(suffix, 10)
 
 	return &DiscardRequester{
 		BaseRequester: BaseRequester{
 			method: "PATCH",
-			req: cli.Patch(b.patchType).AbsPath(comps...).
-				Body(b.body).
+			req: cli.Patch(b.patchType).AbsPath(b.collectionPath, finalName).
+				Body(b.newBody(finalName, suffix)).
 				MaxRetries(b.maxRetries),
 		},
 	}
 }
 
+// newBody returns the body for the given object. The template is safe
+// for concurrent use after parsed.
+func (b *requestPatchBuilder) newBody(name string, index int64) interface{} {
+	if b.bodyTemplate == nil {
+		return b.body
+	}
+
+	body, err := types.RenderPatchBody(b.bodyTemplate, types.PatchBodyTemplateData{
+		Name:      name,
+		Index:     index,
+		Namespace: b.namespace,
+	})
+	if err != nil {
+		// NOTE: The template has been verified by RequestPatch.Validate
+		// so it's unlikely to happen. The body is empty so that the
+		// request fails and is counted as error.
+		klog.V(5).ErrorS(err, "failed to render patch body")
+		return b.body
+	}
+	return []byte(body)
+}
+
+type requestPutBuilder struct {
+	resource       string
+	name           string
+	keySpaceLow    int64
+	keySpaceHigh   int64
+	valueSize      int
+	optimisticLock bool
+	maxRetries     int
+
+	// collectionPath is the immutable path of resource without name.
+	collectionPath string
+}
+
+func newRequestPutBuilder(src *types.RequestPut, maxRetries int) (*requestPutBuilder, error) {
+	if !src.UseExistingTemplate {
+		return nil, fmt.Errorf("put requires useExistingTemplate since creating objects by put isn't supported")
+	}
+
+	keySpaceLow, keySpaceHigh := src.KeySpaceRange(src.KeySpaceSize)
+	version := schema.GroupVersion{Group: src.Group, Version: src.Version}
+	return &requestPutBuilder{
+		resource:       src.Resource,
+		name:           src.Name,
+		keySpaceLow:    keySpaceLow,
+		keySpaceHigh:   keySpaceHigh,
+		valueSize:      src.ValueSize,
+		optimisticLock: src.OptimisticLock,
+		maxRetries:     maxRetries,
+		collectionPath: resourcePath(version, src.Namespace, src.Resource, ""),
+	}, nil
+}
+
+// Build implements RequestBuilder.Build.
+func (b *requestPutBuilder) Build(cli rest.Interface) Requester {
+	// Generate random suffix in the shard of keySpaceSize
+	randomInt, _ := rand.Int(rand.Reader, big.NewInt(b.keySpaceHigh-b.keySpaceLow))
+	name := b.name + "-" + strconv.FormatInt(b.keySpaceLow+randomInt.Int64(), 10)
+
+	return &UpdateRequester{
+		BaseRequester: BaseRequester{
+			method: "PUT",
+			req: cli.Put().AbsPath(b.collectionPath, name).
+				SetHeader("Content-Type", "application/json").
+				MaxRetries(b.maxRetries),
+		},
+		get: cli.Get().AbsPath(b.collectionPath, name).
+			SetHeader("Accept", "application/json").
+			MaxRetries(b.maxRetries),
+		secret:         b.resource == "secrets",
+		valueSize:      b.valueSize,
+		optimisticLock: b.optimisticLock,
+	}
+}
+
+// UpdateRequester GETs the existing object and PUTs it back with new data.
+// The GET and PUT are measured as one request.
+type UpdateRequester struct {
+	BaseRequester
+	get *rest.Request
+	// secret means the object is secret whose data is base64 encoded.
+	// Otherwise, it's configmap.
+	secret         bool
+	valueSize      int
+	optimisticLock bool
+}
+
+// Timeout applies the timeout to both GET and PUT.
+func (reqr *UpdateRequester) Timeout(timeout time.Duration) {
+	reqr.BaseRequester.Timeout(timeout)
+	reqr.get.Timeout(timeout)
+}
+
+func (reqr *UpdateRequester) Do(ctx context.Context) (bytes int64, err error) {
+	raw, err := reqr.get.Do(ctx).Raw()
+	if err != nil {
+		return int64(len(raw)), fmt.Errorf("failed to get existing object: %w", err)
+	}
+
+	body, err := reqr.newBody(raw)
+	if err != nil {
+		return int64(len(raw)), err
+	}
+
+	respBody, err := reqr.req.Body(body).Stream(ctx)
+	if err != nil {
+		return int64(len(raw)), err
+	}
+	defer respBody.Close()
+
+	bytes, err = io.Copy(io.Discard, respBody)
+	return int64(len(raw)) + bytes, err
+}
+
+// newBody replaces data of the existing object with random value. The
+// resourceVersion is kept only for optimistic lock.
+func (reqr *UpdateRequester) newBody(existing []byte) ([]byte, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(existing, &obj); err != nil {
+		return nil, fmt.Errorf("failed to decode existing object: %w", err)
+	}
+
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if metadata == nil {
+		return nil, fmt.Errorf("existing object has no metadata")
+	}
+	if !reqr.optimisticLock {
+		delete(metadata, "resourceVersion")
+	}
+
+	value := randomValue(reqr.valueSize)
+	if reqr.secret {
+		value = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	obj["data"] = map[string]interface{}{"kperf": value}
+
+	return json.Marshal(obj)
+}
+
+// randomValue returns random hex string of the given size.
+func randomValue(size int) string {
+	buf := make([]byte, (size+1)/2)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)[:size]
+}
+
 type requestPostDelBuilder struct {
 	version         schema.GroupVersion
 	resource        string
@@ -313,6 +609,12 @@ type requestPostDelBuilder struct {
 	namespace       string
 	deleteRatio     float64
 	maxRetries      int
+	nameTemplate    *template.Template
+	keySpaceShard   types.KeySpaceShard
+	objectMeta      *types.ObjectMeta
+
+	// collectionPath is the immutable path of resource without name.
+	collectionPath string
 
 	// Per-builder cache for created resources
 	cache *Cache
@@ -321,29 +623,30 @@ type requestPostDelBuilder struct {
 	resourceCounter int64
 }
 
-func newRequestPostDelBuilder(src *types.RequestPostDel, resourceVersion string, maxRetries int) *requestPostDelBuilder {
+func newRequestPostDelBuilder(src *types.RequestPostDel, resourceVersion string, maxRetries int) (*requestPostDelBuilder, error) {
+	nameTemplate, err := src.ParseNameTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	version := schema.GroupVersion{Group: src.Group, Version: src.Version}
 	return &requestPostDelBuilder{
-		version:         schema.GroupVersion{Group: src.Group, Version: src.Version},
+		version:         version,
 		resource:        src.Resource,
 		resourceVersion: resourceVersion,
 		namespace:       src.Namespace,
 		deleteRatio:     src.DeleteRatio,
 		maxRetries:      maxRetries,
+		nameTemplate:    nameTemplate,
+		keySpaceShard:   src.KeySpaceShard,
+		objectMeta:      src.ObjectMeta,
+		collectionPath:  resourcePath(version, src.Namespace, src.Resource, ""),
 		cache:           InitCache(), // Initialize the cache
-	}
+	}, nil
 }
 
 // Build implements RequestBuilder.Build.
 func (b *requestPostDelBuilder) Build(cli rest.Interface) Requester {
-	comps := make([]string, 0, 5)
-	if b.version.Group == "" {
-		comps = append(comps, "api", b.version.Version)
-	} else {
-		comps = append(comps, "apis", b.version.Group, b.version.Version)
-	}
-	if b.namespace != "" {
-		comps = append(comps, "namespaces", b.namespace)
-	}
 
 	// Random pick operation DELETE or CREATE based on deleteRatio weight probability
 	randomInt, _ := rand.Int(rand.Reader, big.NewInt(1000))
@@ -352,7 +655,6 @@ func (b *requestPostDelBuilder) Build(cli rest.Interface) Requester {
 	if shouldDelete {
 		// Try to get a name from cache
 		if name, ok := b.cache.Pop(); ok {
-			comps = append(comps, b.resource, name)
 
 			return &PostDelDiscardRequester{
 				builder:   b,
@@ -361,7 +663,7 @@ func (b *requestPostDelBuilder) Build(cli rest.Interface) Requester {
 				DiscardRequester: DiscardRequester{
 					BaseRequester: BaseRequester{
 						method: "DELETE",
-						req: cli.Delete().AbsPath(comps...).
+						req: cli.Delete().AbsPath(b.collectionPath, name).
 							MaxRetries(b.maxRetries),
 					},
 				},
@@ -371,17 +673,17 @@ func (b *requestPostDelBuilder) Build(cli rest.Interface) Requester {
 	}
 
 	// POST logic - create resource and add to cache if successful
-	comps = append(comps, b.resource)
 
 	// Use builder's atomic counter for synchronized unique ID generation
-	counter := atomic.AddInt64(&b.resourceCounter, 1)
+	counter := b.keySpaceShard.KeySpaceIndex(atomic.AddInt64(&b.resourceCounter, 1))
 	timestamp := time.Now().UnixNano()
-	name := fmt.Sprintf("%d-%d", timestamp, counter)
+	name := b.newName(counter, timestamp)
 
 	body, _ := utils.RenderTemplate(b.resource, map[string]interface{}{
 		"namePattern": name,
 		"namespace":   b.namespace,
 	})
+	body = withObjectMeta(body, b.objectMeta)
 
 	return &PostDelDiscardRequester{
 		builder:   b,
@@ -390,12 +692,79 @@ func (b *requestPostDelBuilder) Build(cli rest.Interface) Requester {
 		DiscardRequester: DiscardRequester{
 			BaseRequester: BaseRequester{
 				method: "POST",
-				req:    cli.Post().AbsPath(comps...).Body(body).MaxRetries(b.maxRetries),
+				req:    cli.Post().AbsPath(b.collectionPath).Body(body).MaxRetries(b.maxRetries),
 			},
 		},
 	}
 }
 
+// CacheSize implements executor.CacheSizer.
+func (b *requestPostDelBuilder) CacheSize() int {
+	return b.cache.Len()
+}
+
+// newName generates the name of object which is going to be created.
+func (b *requestPostDelBuilder) newName(counter, timestamp int64) string {
+	if b.nameTemplate != nil {
+		name, err := types.RenderPostDelName(b.nameTemplate, types.PostDelNameTemplateData{
+			Index:     counter,
+			Namespace: b.namespace,
+			Resource:  b.resource,
+			Timestamp: timestamp,
+		})
+		if err == nil {
+			return name
+		}
+		klog.V(5).ErrorS(err, "failed to render name, fallback to default name")
+	}
+	return fmt.Sprintf("%d-%d", timestamp, counter)
+}
+
+// withObjectMeta merges labels and annotations into object's metadata in
+// JSON body. The body is returned as it is if it's not a JSON object.
+func withObjectMeta(body []byte, meta *types.ObjectMeta) []byte {
+	if meta == nil || len(body) == 0 {
+		return body
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		klog.V(5).ErrorS(err, "failed to decode body to inject labels and annotations")
+		return body
+	}
+
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		obj["metadata"] = metadata
+	}
+	mergeStringMap(metadata, "labels", meta.Labels)
+	mergeStringMap(metadata, "annotations", meta.Annotations)
+
+	merged, err := json.Marshal(obj)
+	if err != nil {
+		klog.V(5).ErrorS(err, "failed to encode body with labels and annotations")
+		return body
+	}
+	return merged
+}
+
+// mergeStringMap merges values into the map of the given key in obj.
+func mergeStringMap(obj map[string]interface{}, key string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+
+	target, _ := obj[key].(map[string]interface{})
+	if target == nil {
+		target = make(map[string]interface{}, len(values))
+		obj[key] = target
+	}
+	for k, v := range values {
+		target[k] = v
+	}
+}
+
 // PostDelDiscardRequester handles both POST and DELETE requests with cache management
 type PostDelDiscardRequester struct {
 	builder   *requestPostDelBuilder
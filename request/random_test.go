@@ -0,0 +1,252 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestEncodeV1Params(t *testing.T) {
+	params, err := encodeV1Params(&metav1.GetOptions{ResourceVersion: "0"})
+	require.NoError(t, err)
+	assert.Equal(t, "0", params.Get("resourceVersion"))
+
+	// The object which isn't options returns error instead of panic.
+	_, err = encodeV1Params(&unstructured.Unstructured{})
+	assert.ErrorContains(t, err, "failed to encode *unstructured.Unstructured as query parameters")
+}
+
+func TestRequestPatchBuilderBodyTemplate(t *testing.T) {
+	type patched struct {
+		path string
+		body []byte
+	}
+
+	patchedCh := make(chan patched, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		patchedCh <- patched{path: r.URL.Path, body: body}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), 1)
+	require.NoError(t, err)
+
+	src := &types.RequestPatch{
+		KubeGroupVersionResource: types.KubeGroupVersionResource{
+			Version:  "v1",
+			Resource: "configmaps",
+		},
+		Namespace:    "default",
+		Name:         "kperf",
+		KeySpaceSize: 10,
+		PatchType:    "merge",
+		BodyTemplate: `{"metadata":{"labels":{"version":"{{.Index}}","name":"{{.Name}}","ns":"{{.Namespace}}"}}}`,
+	}
+	require.NoError(t, src.Validate())
+
+	builder, err := newRequestPatchBuilder(src, "", 0)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err := builder.Build(clis[0]).Do(context.TODO())
+		require.NoError(t, err)
+
+		p := <-patchedCh
+		name := path.Base(p.path)
+		require.True(t, strings.HasPrefix(name, "kperf-"))
+
+		var obj struct {
+			Metadata struct {
+				Labels map[string]string `json:"labels"`
+			} `json:"metadata"`
+		}
+		require.NoError(t, json.Unmarshal(p.body, &obj))
+		assert.Equal(t, map[string]string{
+			"version": strings.TrimPrefix(name, "kperf-"),
+			"name":    name,
+			"ns":      "default",
+		}, obj.Metadata.Labels)
+	}
+
+	_, err = newRequestPatchBuilder(&types.RequestPatch{BodyTemplate: "{{.Index"}, "", 0)
+	assert.Error(t, err)
+}
+
+func TestRequestPatchBuilderKeySpaceShard(t *testing.T) {
+	offset := 2
+	src := &types.RequestPatch{
+		KubeGroupVersionResource: types.KubeGroupVersionResource{
+			Version:  "v1",
+			Resource: "configmaps",
+		},
+		Name:          "kperf",
+		KeySpaceSize:  12,
+		KeySpaceShard: types.KeySpaceShard{KeySpacePartitions: 4, KeySpaceOffset: &offset},
+		PatchType:     "merge",
+		BodyTemplate:  `{"metadata":{"labels":{"version":"{{.Index}}"}}}`,
+	}
+	require.NoError(t, src.Validate())
+
+	builder, err := newRequestPatchBuilder(src, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), builder.keySpaceLow)
+	assert.Equal(t, int64(9), builder.keySpaceHigh)
+
+	clis, err := NewClients(newTestKubeconfig(t, "http://127.0.0.1:1"), 1)
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		name := path.Base(builder.Build(clis[0]).URL().Path)
+		assert.Contains(t, []string{"kperf-6", "kperf-7", "kperf-8"}, name)
+	}
+}
+
+func TestWithObjectMeta(t *testing.T) {
+	meta := &types.ObjectMeta{
+		Labels:      map[string]string{types.LabelRunID: "abc", "team": "perf"},
+		Annotations: map[string]string{"owner": "alice"},
+	}
+
+	for name, tc := range map[string]struct {
+		body     string
+		expected string
+	}{
+		"without metadata": {
+			body:     `{"kind":"ConfigMap"}`,
+			expected: `{"kind":"ConfigMap","metadata":{"annotations":{"owner":"alice"},"labels":{"kperf.io/run-id":"abc","team":"perf"}}}`,
+		},
+		"merge with existing": {
+			body:     `{"metadata":{"name":"a","labels":{"app":"x","team":"dev"}}}`,
+			expected: `{"metadata":{"annotations":{"owner":"alice"},"labels":{"app":"x","kperf.io/run-id":"abc","team":"perf"},"name":"a"}}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.JSONEq(t, tc.expected, string(withObjectMeta([]byte(tc.body), meta)))
+		})
+	}
+
+	assert.Nil(t, withObjectMeta(nil, meta))
+	assert.Equal(t, `{}`, string(withObjectMeta([]byte(`{}`), nil)))
+	assert.Equal(t, `not-json`, string(withObjectMeta([]byte(`not-json`), meta)))
+}
+
+func TestRequestPostDelBuilderObjectMeta(t *testing.T) {
+	spec := &types.ObjectMeta{Labels: map[string]string{types.LabelRunID: "abc", "team": "perf"}}
+	r := (&types.WeightedRequest{
+		Shares: 1,
+		PostDel: &types.RequestPostDel{
+			KubeGroupVersionResource: types.KubeGroupVersionResource{Version: "v1", Resource: "pods"},
+			Namespace:                "default",
+			ObjectMeta:               &types.ObjectMeta{Labels: map[string]string{"team": "churn"}},
+		},
+	}).WithDefaultObjectMeta(spec)
+	require.NoError(t, r.Validate())
+
+	builder, err := CreateRequestBuilder(r, 0)
+	require.NoError(t, err)
+	assert.Equal(t, &types.ObjectMeta{
+		Labels: map[string]string{types.LabelRunID: "abc", "team": "churn"},
+	}, builder.(*requestPostDelBuilder).objectMeta)
+}
+
+func TestRequestPutBuilderUseExistingTemplate(t *testing.T) {
+	type put struct {
+		path string
+		body []byte
+	}
+
+	putCh := make(chan put, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"` +
+				path.Base(r.URL.Path) + `","namespace":"default","resourceVersion":"42","labels":{"app":"x"}},"data":{"old":"v"}}`))
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			putCh <- put{path: r.URL.Path, body: body}
+			_, _ = w.Write(body)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer srv.Close()
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), 1)
+	require.NoError(t, err)
+
+	for name, optimisticLock := range map[string]bool{
+		"unconditional":   false,
+		"optimistic lock": true,
+	} {
+		t.Run(name, func(t *testing.T) {
+			src := &types.RequestPut{
+				KubeGroupVersionResource: types.KubeGroupVersionResource{
+					Version:  "v1",
+					Resource: "configmaps",
+				},
+				Namespace:           "default",
+				Name:                "kperf",
+				KeySpaceSize:        10,
+				ValueSize:           33,
+				UseExistingTemplate: true,
+				OptimisticLock:      optimisticLock,
+			}
+			require.NoError(t, src.Validate())
+
+			builder, err := newRequestPutBuilder(src, 0)
+			require.NoError(t, err)
+
+			req := builder.Build(clis[0])
+			assert.Equal(t, "PUT", req.Method())
+			assert.Equal(t, "/api/v1/namespaces/default/configmaps/:name", req.MaskedURL().Path)
+
+			bytes, err := req.Do(context.TODO())
+			require.NoError(t, err)
+			assert.Greater(t, bytes, int64(33))
+
+			p := <-putCh
+			assert.True(t, strings.HasPrefix(path.Base(p.path), "kperf-"))
+
+			var obj struct {
+				Metadata struct {
+					Name            string            `json:"name"`
+					ResourceVersion string            `json:"resourceVersion"`
+					Labels          map[string]string `json:"labels"`
+				} `json:"metadata"`
+				Data map[string]string `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(p.body, &obj))
+			assert.Equal(t, path.Base(p.path), obj.Metadata.Name)
+			assert.Equal(t, map[string]string{"app": "x"}, obj.Metadata.Labels, "existing object is the template")
+			require.Len(t, obj.Data, 1)
+			assert.Len(t, obj.Data["kperf"], 33)
+			if optimisticLock {
+				assert.Equal(t, "42", obj.Metadata.ResourceVersion)
+			} else {
+				assert.Empty(t, obj.Metadata.ResourceVersion)
+			}
+		})
+	}
+
+	_, err = newRequestPutBuilder(&types.RequestPut{}, 0)
+	assert.ErrorContains(t, err, "useExistingTemplate")
+}
@@ -1,17 +1,24 @@
 package request
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"time"
 	_ "unsafe" // unsafe to use internal function from client-go
 
 	"github.com/Azure/kperf/request/executor"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/rest"
@@ -25,6 +32,10 @@ type Requester = executor.Requester
 type BaseRequester struct {
 	method string
 	req    *rest.Request
+
+	// maskedURL is computed on first use since it's called for each
+	// metric. It's reset by Timeout which changes the URL.
+	maskedURL *url.URL
 }
 
 func (reqr *BaseRequester) Method() string {
@@ -35,24 +46,31 @@ func (reqr *BaseRequester) URL() *url.URL {
 	return reqr.req.URL()
 }
 
-// MaskedURL returns a masked URL for DELETE and PATCH methods to enable aggregation in metrics
+// MaskedURL returns a masked URL for DELETE, PATCH and PUT methods to enable aggregation in metrics
+//
+// NOTE: The returned URL is shared by the following calls so that it must
+// not be modified.
 func (reqr *BaseRequester) MaskedURL() *url.URL {
-	originalURL := reqr.req.URL()
-
-	// Aggregates for DELETE and PATCH methods, replaces the last path segment
-	// for DELETE and PATCH requests so they can be aggregated (e.g. in metrics)
-	if reqr.method == http.MethodDelete || reqr.method == http.MethodPatch {
-		if u, err := url.Parse(originalURL.String()); err == nil {
-			u.Path = path.Join(path.Dir(u.Path), ":name")
-			return u // String() will keep ":name" as-is
-		}
+	if reqr.maskedURL != nil {
+		return reqr.maskedURL
 	}
 
-	return originalURL
+	u := reqr.req.URL()
+
+	// Aggregates for DELETE, PATCH and PUT methods, replaces the last path
+	// segment for these requests so they can be aggregated (e.g. in metrics)
+	if reqr.method == http.MethodDelete || reqr.method == http.MethodPatch || reqr.method == http.MethodPut {
+		u.Path = path.Join(path.Dir(u.Path), ":name")
+		u.RawPath = "" // String() will keep ":name" as-is
+	}
+
+	reqr.maskedURL = u
+	return u
 }
 
 func (reqr *BaseRequester) Timeout(timeout time.Duration) {
 	reqr.req.Timeout(timeout)
+	reqr.maskedURL = nil
 }
 
 type DiscardRequester struct {
@@ -69,6 +87,113 @@ func (reqr *DiscardRequester) Do(ctx context.Context) (bytes int64, err error) {
 	return io.Copy(io.Discard, respBody)
 }
 
+// TieredLatencyRequester is implemented by requesters which measure the time
+// to first byte and the time to read body separately.
+type TieredLatencyRequester interface {
+	// TieredLatency returns the time until response headers are received
+	// and the time to read the whole body after that.
+	//
+	// NOTE: It's only valid after Do returns.
+	TieredLatency() (ttfb time.Duration, bodyRead time.Duration)
+}
+
+// TieredRequester is DiscardRequester which measures the time to first byte
+// and the time to read body separately. It tells whether slow requests are
+// caused by server processing or large response.
+type TieredRequester struct {
+	BaseRequester
+
+	ttfb     time.Duration
+	bodyRead time.Duration
+}
+
+func (reqr *TieredRequester) Do(ctx context.Context) (bytes int64, err error) {
+	start := time.Now()
+	respBody, err := reqr.req.Stream(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer respBody.Close()
+
+	headerReceived := time.Now()
+	reqr.ttfb = headerReceived.Sub(start)
+
+	bytes, err = io.Copy(io.Discard, respBody)
+	reqr.bodyRead = time.Since(headerReceived)
+	return bytes, err
+}
+
+// TieredLatency implements TieredLatencyRequester.
+func (reqr *TieredRequester) TieredLatency() (time.Duration, time.Duration) {
+	return reqr.ttfb, reqr.bodyRead
+}
+
+// toTieredRequester converts DiscardRequester into TieredRequester. If it's
+// wrapped, like by maxInFlight, the wrapped one is converted in place. The
+// other requesters are returned as they are.
+func toTieredRequester(req Requester) Requester {
+	switch r := req.(type) {
+	case *DiscardRequester:
+		return &TieredRequester{BaseRequester: r.BaseRequester}
+	case executor.WrappedRequester:
+		r.Rewrap(toTieredRequester(r.Unwrap()))
+		return r
+	default:
+		return req
+	}
+}
+
+// PaginatedListRequester lists objects page by page by following continue
+// token.
+type PaginatedListRequester struct {
+	BaseRequester
+
+	// nextPage returns the request for the page of continue token.
+	nextPage func(continueToken string) *rest.Request
+	// maxPages is the maximum number of pages. Zero means no limit.
+	maxPages int
+	timeout  time.Duration
+}
+
+func (reqr *PaginatedListRequester) Timeout(timeout time.Duration) {
+	reqr.timeout = timeout
+	reqr.BaseRequester.Timeout(timeout)
+}
+
+func (reqr *PaginatedListRequester) Do(ctx context.Context) (bytes int64, _ error) {
+	req := reqr.req
+	for page := 1; ; page++ {
+		respBody, err := req.Stream(ctx)
+		if err != nil {
+			return bytes, err
+		}
+
+		data, err := io.ReadAll(respBody)
+		respBody.Close()
+		bytes += int64(len(data))
+		if err != nil {
+			return bytes, err
+		}
+
+		if reqr.maxPages > 0 && page >= reqr.maxPages {
+			return bytes, nil
+		}
+
+		continueToken, err := listContinueToken(data)
+		if err != nil {
+			return bytes, fmt.Errorf("failed to get continue token from page %d: %w", page, err)
+		}
+		if continueToken == "" {
+			return bytes, nil
+		}
+
+		req = reqr.nextPage(continueToken)
+		if reqr.timeout > 0 {
+			req.Timeout(reqr.timeout)
+		}
+	}
+}
+
 type WatchListRequester struct {
 	BaseRequester
 }
@@ -95,6 +220,194 @@ func (reqr *WatchListRequester) Do(ctx context.Context) (zero int64, _ error) {
 	return zero, fmt.Errorf("don't receive bookmark")
 }
 
+// openWatches is the number of watches held by WatchChurnRequester.
+var openWatches int64
+
+// OpenWatches returns the number of watches which are currently held open by
+// watch-churn requests.
+func OpenWatches() int64 {
+	return atomic.LoadInt64(&openWatches)
+}
+
+// WatchStatsRequester is implemented by requesters which hold a watch open.
+type WatchStatsRequester interface {
+	// WatchStats returns the time to first event or bookmark (zero if
+	// nothing was received) and the number of events and bookmarks.
+	//
+	// NOTE: It's only valid after Do returns.
+	WatchStats() (setup time.Duration, events int64, bookmarks int64)
+}
+
+// WatchEventLagRequester is implemented by requesters which measure the
+// delivery lag of watch events.
+type WatchEventLagRequester interface {
+	// WatchEventLags returns the seconds from object's creation to
+	// receiving its ADDED event for the objects created after the watch
+	// is established.
+	//
+	// NOTE: It's only valid after Do returns.
+	WatchEventLags() []float64
+}
+
+// WatchChurnRequester establishes a watch, keeps it for holdTime and closes it.
+type WatchChurnRequester struct {
+	BaseRequester
+	holdTime time.Duration
+
+	setup     time.Duration
+	events    int64
+	bookmarks int64
+	lags      []float64
+}
+
+func (reqr *WatchChurnRequester) Do(ctx context.Context) (zero int64, _ error) {
+	start := time.Now()
+
+	w, err := reqr.req.Watch(ctx)
+	if err != nil {
+		return zero, err
+	}
+	defer w.Stop()
+
+	atomic.AddInt64(&openWatches, 1)
+	defer atomic.AddInt64(&openWatches, -1)
+
+	timer := time.NewTimer(reqr.holdTime)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return zero, nil
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return zero, fmt.Errorf("watch closed before holdTime %v", reqr.holdTime)
+			}
+
+			if reqr.setup == 0 {
+				reqr.setup = time.Since(start)
+			}
+
+			switch event.Type {
+			case watch.Error:
+				return zero, apierrors.FromObject(event.Object)
+			case watch.Bookmark:
+				reqr.bookmarks++
+			case watch.Added:
+				reqr.events++
+				reqr.observeLag(start, event.Object)
+			default:
+				reqr.events++
+			}
+		}
+	}
+}
+
+// observeLag records the lag of ADDED event since object's creation.
+//
+// NOTE: The watch without resourceVersion starts with ADDED events of the
+// existing objects, which are skipped. The creationTimestamp is in seconds,
+// so the objects created in the same second as the watch are skipped as
+// well and the lag is accurate up to one second.
+func (reqr *WatchChurnRequester) observeLag(start time.Time, obj runtime.Object) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+
+	created := accessor.GetCreationTimestamp().Time
+	if !created.After(start) {
+		return
+	}
+	reqr.lags = append(reqr.lags, max(time.Since(created), 0).Seconds())
+}
+
+// WatchStats implements WatchStatsRequester.
+func (reqr *WatchChurnRequester) WatchStats() (time.Duration, int64, int64) {
+	return reqr.setup, reqr.events, reqr.bookmarks
+}
+
+// WatchEventLags implements WatchEventLagRequester.
+func (reqr *WatchChurnRequester) WatchEventLags() []float64 {
+	return reqr.lags
+}
+
+// LogStatsRequester is implemented by requesters which parse pod logs.
+type LogStatsRequester interface {
+	// LogStats returns the number of log lines and the time span in
+	// seconds between the first and last lines. Either is nil if it's not
+	// tracked.
+	//
+	// NOTE: It's only valid after Do returns.
+	LogStats() (lines *int64, timeSpanSeconds *float64)
+}
+
+// logTimestampLen is the length of RFC3339Nano timestamp added by kubelet,
+// like 2024-01-02T15:04:05.123456789Z.
+const logTimestampLen = 30
+
+// LogParsingRequester reads pod logs line by line. It counts the lines
+// and parses the timestamp prefix of each line if they are enabled.
+type LogParsingRequester struct {
+	BaseRequester
+	countLines      bool
+	parseTimestamps bool
+
+	lines     int64
+	firstTime time.Time
+	lastTime  time.Time
+}
+
+func (reqr *LogParsingRequester) Do(ctx context.Context) (bytes int64, err error) {
+	respBody, err := reqr.req.Stream(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer respBody.Close()
+
+	r := bufio.NewReader(respBody)
+	for {
+		line, err := r.ReadString('\n')
+		bytes += int64(len(line))
+
+		if strings.HasSuffix(line, "\n") {
+			reqr.lines++
+		}
+		if reqr.parseTimestamps && len(line) >= logTimestampLen {
+			if t, perr := time.Parse(time.RFC3339Nano, strings.TrimSpace(line[:logTimestampLen])); perr == nil {
+				if reqr.firstTime.IsZero() {
+					reqr.firstTime = t
+				}
+				reqr.lastTime = t
+			}
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return bytes, nil
+			}
+			return bytes, err
+		}
+	}
+}
+
+// LogStats implements LogStatsRequester.
+func (reqr *LogParsingRequester) LogStats() (*int64, *float64) {
+	var (
+		lines    *int64
+		timeSpan *float64
+	)
+	if reqr.countLines {
+		lines = toPtr(reqr.lines)
+	}
+	if reqr.parseTimestamps && !reqr.firstTime.IsZero() {
+		timeSpan = toPtr(reqr.lastTime.Sub(reqr.firstTime).Seconds())
+	}
+	return lines, timeSpan
+}
+
 //go:linkname handleAnyWatch k8s.io/client-go/tools/cache.handleAnyWatch
 func handleAnyWatch(start time.Time,
 	w watch.Interface,
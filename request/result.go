@@ -0,0 +1,146 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"github.com/Azure/kperf/api/types"
+)
+
+// MergeParallel combines the results of two Schedule calls running at the
+// same time. The duration is the longer one.
+//
+// The observations are copied so that both r and other are unchanged.
+// ExecutorReport, ConnectionRampInterval, TerminationCause, ExecutionError
+// and FailFastError can't be combined and are taken from r, or other if r's
+// is empty. StartTime and EndTime are the earliest and the latest of both.
+func (r *Result) MergeParallel(other *Result) *Result {
+	res := r.merge(other)
+	res.Duration = max(r.Duration, other.Duration)
+	return res
+}
+
+// MergeSequential combines the results of two Schedule calls running one
+// after another. The duration is the sum of both.
+//
+// The other fields are combined in the same way as MergeParallel.
+func (r *Result) MergeSequential(other *Result) *Result {
+	res := r.merge(other)
+	res.Duration = r.Duration + other.Duration
+	return res
+}
+
+// merge combines all the fields except Duration.
+func (r *Result) merge(other *Result) *Result {
+	res := &Result{}
+	for _, src := range []*Result{r, other} {
+		mergeResponseStats(&res.ResponseStats, &src.ResponseStats)
+
+		res.Total += src.Total
+		res.ClientRotationCount += src.ClientRotationCount
+		res.DispatchBlockedTime += src.DispatchBlockedTime
+		res.MaxDispatchBlockedTime = max(res.MaxDispatchBlockedTime, src.MaxDispatchBlockedTime)
+
+		if !src.StartTime.IsZero() && (res.StartTime.IsZero() || src.StartTime.Before(res.StartTime)) {
+			res.StartTime = src.StartTime
+		}
+		if src.EndTime.After(res.EndTime) {
+			res.EndTime = src.EndTime
+		}
+
+		if res.ExecutorReport == nil {
+			res.ExecutorReport = src.ExecutorReport
+		}
+		if res.ConnectionRampInterval == 0 {
+			res.ConnectionRampInterval = src.ConnectionRampInterval
+		}
+		if res.TerminationCause == nil {
+			res.TerminationCause = src.TerminationCause
+		}
+		if res.ExecutionError == nil {
+			res.ExecutionError = src.ExecutionError
+		}
+		if res.FailFastError == nil {
+			res.FailFastError = src.FailFastError
+		}
+	}
+	return res
+}
+
+// mergeResponseStats adds the observations of src into dst. The per-index
+// counters, like RequestsByWorker, are added by index.
+func mergeResponseStats(dst, src *types.ResponseStats) {
+	dst.Errors = append(dst.Errors, src.Errors...)
+	dst.TotalReceivedBytes += src.TotalReceivedBytes
+	dst.TotalWatchEvents += src.TotalWatchEvents
+	dst.TotalWatchBookmarks += src.TotalWatchBookmarks
+
+	dst.LatenciesByURL = appendByKey(dst.LatenciesByURL, src.LatenciesByURL)
+	dst.ResponseSizesByURL = appendByKey(dst.ResponseSizesByURL, src.ResponseSizesByURL)
+	dst.WatchSetupLatenciesByURL = appendByKey(dst.WatchSetupLatenciesByURL, src.WatchSetupLatenciesByURL)
+	dst.WatchEventLagsByURL = appendByKey(dst.WatchEventLagsByURL, src.WatchEventLagsByURL)
+	dst.TTFBByURL = appendByKey(dst.TTFBByURL, src.TTFBByURL)
+	dst.BodyReadLatenciesByURL = appendByKey(dst.BodyReadLatenciesByURL, src.BodyReadLatenciesByURL)
+	dst.LatenciesByConnection = appendByKey(dst.LatenciesByConnection, src.LatenciesByConnection)
+
+	dst.AttemptsByURL = addByKey(dst.AttemptsByURL, src.AttemptsByURL)
+	dst.FailuresByURL = addByKey(dst.FailuresByURL, src.FailuresByURL)
+	dst.AttemptsByMethod = addByKey(dst.AttemptsByMethod, src.AttemptsByMethod)
+	dst.FailuresByMethod = addByKey(dst.FailuresByMethod, src.FailuresByMethod)
+	dst.LogLinesByURL = addByKey(dst.LogLinesByURL, src.LogLinesByURL)
+
+	for u, span := range src.LogTimeSpanByURL {
+		if dst.LogTimeSpanByURL == nil {
+			dst.LogTimeSpanByURL = make(map[string]float64, len(src.LogTimeSpanByURL))
+		}
+		if cur, ok := dst.LogTimeSpanByURL[u]; !ok || span > cur {
+			dst.LogTimeSpanByURL[u] = span
+		}
+	}
+
+	dst.RequestsByWorker = addByIndex(dst.RequestsByWorker, src.RequestsByWorker)
+	dst.RequestsByConnection = addByIndex(dst.RequestsByConnection, src.RequestsByConnection)
+	dst.FailuresByConnection = addByIndex(dst.FailuresByConnection, src.FailuresByConnection)
+	dst.LatencySumByConnection = addByIndex(dst.LatencySumByConnection, src.LatencySumByConnection)
+}
+
+// appendByKey appends the values of src into dst by key. dst is allocated if
+// it's nil and src isn't empty.
+func appendByKey[K comparable, T any](dst, src map[K][]T) map[K][]T {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[K][]T, len(src))
+	}
+	for k, values := range src {
+		dst[k] = append(dst[k], values...)
+	}
+	return dst
+}
+
+// addByKey adds the counts of src into dst by key. dst is allocated if it's
+// nil and src isn't empty.
+func addByKey[K comparable](dst, src map[K]int64) map[K]int64 {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[K]int64, len(src))
+	}
+	for k, v := range src {
+		dst[k] += v
+	}
+	return dst
+}
+
+// addByIndex adds src into dst by index. dst is extended if src is longer.
+func addByIndex[T int64 | float64](dst, src []T) []T {
+	if len(src) > len(dst) {
+		dst = append(dst, make([]T, len(src)-len(dst))...)
+	}
+	for i, v := range src {
+		dst[i] += v
+	}
+	return dst
+}
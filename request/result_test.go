@@ -0,0 +1,97 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mergeTestStartTime is the start time of results by newMergeTestResults.
+var mergeTestStartTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newMergeTestResults() (*Result, *Result) {
+	r := &Result{
+		ResponseStats: types.ResponseStats{
+			Errors:             []types.ResponseError{{URL: "/a", Type: types.ResponseErrorTypeHTTP, Code: 500}},
+			LatenciesByURL:     map[string][]float64{"/a": {0.1, 0.2}},
+			TotalReceivedBytes: 100,
+			AttemptsByURL:      map[string]int64{"/a": 3},
+			FailuresByURL:      map[string]int64{"/a": 1},
+			RequestsByWorker:   []int64{1, 2},
+		},
+		Duration:               2 * time.Second,
+		Total:                  3,
+		ClientRotationCount:    1,
+		StartTime:              mergeTestStartTime.Add(time.Second),
+		EndTime:                mergeTestStartTime.Add(3 * time.Second),
+		MaxDispatchBlockedTime: time.Second,
+		TerminationCause:       ErrScheduleStopped,
+	}
+	other := &Result{
+		ResponseStats: types.ResponseStats{
+			LatenciesByURL:     map[string][]float64{"/a": {0.3}, "/b": {0.4}},
+			TotalReceivedBytes: 50,
+			AttemptsByURL:      map[string]int64{"/a": 1, "/b": 1},
+			RequestsByWorker:   []int64{1, 0, 1},
+		},
+		Duration:               3 * time.Second,
+		Total:                  2,
+		ClientRotationCount:    2,
+		StartTime:              mergeTestStartTime,
+		EndTime:                mergeTestStartTime.Add(2 * time.Second),
+		MaxDispatchBlockedTime: 2 * time.Second,
+		ExecutionError:         errors.New("boom"),
+		FailFastError:          &types.ResponseError{URL: "/a", Type: types.ResponseErrorTypeHTTP, Code: 500},
+	}
+	return r, other
+}
+
+func TestResultMerge(t *testing.T) {
+	for name, tc := range map[string]struct {
+		merge    func(r, other *Result) *Result
+		duration time.Duration
+	}{
+		"parallel": {
+			merge:    (*Result).MergeParallel,
+			duration: 3 * time.Second,
+		},
+		"sequential": {
+			merge:    (*Result).MergeSequential,
+			duration: 5 * time.Second,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, other := newMergeTestResults()
+
+			res := tc.merge(r, other)
+			assert.Equal(t, tc.duration, res.Duration)
+			assert.Equal(t, 5, res.Total)
+			assert.Equal(t, 3, res.ClientRotationCount)
+			assert.Equal(t, mergeTestStartTime, res.StartTime)
+			assert.Equal(t, mergeTestStartTime.Add(3*time.Second), res.EndTime)
+			assert.Equal(t, 1, res.ErrorCount())
+			assert.Equal(t, int64(150), res.TotalReceivedBytes)
+			assert.Equal(t, map[string][]float64{"/a": {0.1, 0.2, 0.3}, "/b": {0.4}}, res.LatenciesByURL)
+			assert.Equal(t, map[string]int64{"/a": 4, "/b": 1}, res.AttemptsByURL)
+			assert.Equal(t, map[string]int64{"/a": 1}, res.FailuresByURL)
+			assert.Equal(t, []int64{2, 2, 1}, res.RequestsByWorker)
+			assert.Equal(t, 2*time.Second, res.MaxDispatchBlockedTime)
+			assert.ErrorIs(t, res.TerminationCause, ErrScheduleStopped)
+			assert.EqualError(t, res.ExecutionError, "boom")
+			assert.Equal(t, other.FailFastError, res.FailFastError)
+
+			// The inputs are unchanged.
+			assert.Equal(t, []float64{0.1, 0.2}, r.LatenciesByURL["/a"])
+			assert.Equal(t, int64(3), r.AttemptsByURL["/a"])
+			assert.Equal(t, []int64{1, 2}, r.RequestsByWorker)
+			assert.Equal(t, []float64{0.3}, other.LatenciesByURL["/a"])
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Azure/kperf/api/types"
@@ -21,27 +22,274 @@ import (
 
 const defaultTimeout = 60 * time.Second
 
+// progressInterval is the interval to log the progress of schedule.
+const progressInterval = 10 * time.Second
+
+// dispatchBlockedWarningRatio is the ratio of dispatch blocked time to the
+// duration of schedule above which a warning is logged.
+const dispatchBlockedWarningRatio = 0.1
+
+var (
+	// ErrScheduleStopped is the termination cause if Schedule is stopped
+	// by Progress.Stop.
+	ErrScheduleStopped = errors.New("schedule is stopped")
+
+	// errScheduleDone is the cancellation cause if executor finishes
+	// as expected.
+	errScheduleDone = errors.New("schedule is done")
+)
+
+// executorError is the cancellation cause if executor fails.
+type executorError struct {
+	err error
+}
+
+func (e *executorError) Error() string {
+	return fmt.Sprintf("executor failed: %v", e.err)
+}
+
+func (e *executorError) Unwrap() error {
+	return e.err
+}
+
 // Result contains responseStats vlaues from Gather() and adds Duration and Total values separately
 type Result struct {
 	types.ResponseStats
 	// Duration means the time of benchmark.
 	Duration time.Duration
-	// Total means the total number of requests.
+	// Total means the number of attempted requests, which is successes
+	// plus errors. It can be less than executor's expected total if
+	// Schedule is terminated early.
 	Total int
+	// ExecutorReport is the mode-specific report if executor produces it.
+	ExecutorReport *types.ExecutorReport
+	// DispatchBlockedTime is the cumulative time executor is blocked on
+	// sending requests because all the workers are busy.
+	DispatchBlockedTime time.Duration
+	// MaxDispatchBlockedTime is the longest time of one blocked send.
+	MaxDispatchBlockedTime time.Duration
+	// ConnectionRampInterval is the interval between connections put into
+	// use if connection ramp-up is enabled.
+	ConnectionRampInterval time.Duration
+	// ClientRotationCount is the number of times the clients are replaced
+	// if client rotation is enabled.
+	ClientRotationCount int
+	// StartTime and EndTime are the wall-clock time when executor starts
+	// and finishes sending requests. They're zero if executor doesn't
+	// track them.
+	StartTime time.Time
+	EndTime   time.Time
+	// TerminationCause is the reason why Schedule is terminated before
+	// executor finishes, like the cause of canceled context,
+	// ErrScheduleStopped or executor.ErrMaxDurationExceeded. It's nil if Schedule finishes as expected.
+	TerminationCause error
+	// ExecutionError is the error returned by executor. The result only
+	// contains the requests completed before the failure if it's not nil.
+	ExecutionError error
+	// FailFastError is the error which makes ErrorCallback terminate
+	// Schedule. It's nil if ErrorCallback doesn't terminate Schedule.
+	FailFastError *types.ResponseError
+}
+
+// ErrorCount returns the number of failed requests.
+func (r *Result) ErrorCount() int {
+	return len(r.ResponseStats.Errors)
+}
+
+// SuccessCount returns the number of requests without error.
+func (r *Result) SuccessCount() int {
+	return max(r.Total-r.ErrorCount(), 0)
+}
+
+// ErrorRate returns the ratio of failed requests to total. It's 0 if there
+// is no request.
+func (r *Result) ErrorRate() float64 {
+	if r.Total <= 0 {
+		return 0
+	}
+	return float64(r.ErrorCount()) / float64(r.Total)
+}
+
+// SuccessRate returns the ratio of successful requests to total.
+func (r *Result) SuccessRate() float64 {
+	return 1 - r.ErrorRate()
+}
+
+// scheduleCfg is the setting for Schedule.
+type scheduleCfg struct {
+	trackPerConnection bool
+	trackResponseSize  bool
+	progress           *Progress
+	concurrencyLimit   int
+	maxDuration        time.Duration
+	tieredLatency      bool
+	interceptor        RequestInterceptor
+	connectionRampUp   int
+	executor           executor.Executor
+	rotationInterval   time.Duration
+	newClients         func() ([]rest.Interface, error)
+	errorCallback      ErrorCallback
+}
+
+// RequestInterceptor intercepts Do of every requester sent by Schedule. It
+// should call do and return its result. It's called by workers concurrently.
+type RequestInterceptor func(ctx context.Context, req Requester, do func(context.Context) (int64, error)) (int64, error)
+
+// ScheduleOpt is used to update default schedule setting.
+type ScheduleOpt func(*scheduleCfg)
+
+// WithScheduleTrackPerConnectionOpt tracks latencies for each connection.
+func WithScheduleTrackPerConnectionOpt(b bool) ScheduleOpt {
+	return func(cfg *scheduleCfg) {
+		cfg.trackPerConnection = b
+	}
+}
+
+// WithScheduleTrackResponseSizeOpt enables response size tracking per request.
+func WithScheduleTrackResponseSizeOpt(b bool) ScheduleOpt {
+	return func(cfg *scheduleCfg) {
+		cfg.trackResponseSize = b
+	}
+}
+
+// WithScheduleProgressOpt exposes the state of Schedule by the given progress.
+func WithScheduleProgressOpt(p *Progress) ScheduleOpt {
+	return func(cfg *scheduleCfg) {
+		cfg.progress = p
+	}
+}
+
+// WithScheduleConcurrencyLimitOpt caps the number of workers, which is
+// spec's Client, without changing the number of connections. Each worker
+// sends requests over its share of the connections in turn. Zero means no
+// limit.
+func WithScheduleConcurrencyLimitOpt(n int) ScheduleOpt {
+	return func(cfg *scheduleCfg) {
+		cfg.concurrencyLimit = n
+	}
+}
+
+// WithScheduleMaxDurationOpt caps the execution by the duration, regardless
+// of the mode. Zero means no limit.
+func WithScheduleMaxDurationOpt(d time.Duration) ScheduleOpt {
+	return func(cfg *scheduleCfg) {
+		cfg.maxDuration = d
+	}
+}
+
+// WithScheduleTieredLatencyOpt measures the time to first byte and the time
+// to read body separately for requests which discard response.
+func WithScheduleTieredLatencyOpt(b bool) ScheduleOpt {
+	return func(cfg *scheduleCfg) {
+		cfg.tieredLatency = b
+	}
+}
+
+// WithScheduleConnectionRampUpOpt puts the connections into use one by one
+// instead of all at once, up to n connections. The workers of the next
+// connection start every expected duration / connections, so that it
+// requires the mode's expected duration. Zero means no ramp-up.
+//
+// NOTE: The connection is established by its first request.
+func WithScheduleConnectionRampUpOpt(n int) ScheduleOpt {
+	return func(cfg *scheduleCfg) {
+		cfg.connectionRampUp = n
+	}
+}
+
+// WithScheduleClientRotationOpt replaces the clients by the ones from
+// newClients on every interval, like rebuilding them from the kubeconfig
+// with rotated credentials. The workers pick up the new clients on their
+// next request. newClients must return as many clients as the initial ones.
+// If it fails, the clients in use are kept. Zero interval means no rotation.
+func WithScheduleClientRotationOpt(interval time.Duration, newClients func() ([]rest.Interface, error)) ScheduleOpt {
+	return func(cfg *scheduleCfg) {
+		cfg.rotationInterval = interval
+		cfg.newClients = newClients
+	}
+}
+
+// WithScheduleRequestInterceptorOpt intercepts every request, like logging
+// each request. If it's used more than once, the interceptors are chained
+// and the first one is the outermost.
+func WithScheduleRequestInterceptorOpt(interceptor RequestInterceptor) ScheduleOpt {
+	return func(cfg *scheduleCfg) {
+		outer := cfg.interceptor
+		if outer == nil || interceptor == nil {
+			if interceptor != nil {
+				cfg.interceptor = interceptor
+			}
+			return
+		}
+		cfg.interceptor = func(ctx context.Context, req Requester, do func(context.Context) (int64, error)) (int64, error) {
+			return outer(ctx, req, func(ctx context.Context) (int64, error) {
+				return interceptor(ctx, req, do)
+			})
+		}
+	}
+}
+
+// WithScheduleErrorCallbackOpt calls cb with every failed request. Once cb
+// returns true, Schedule is terminated with ErrFailFast as termination cause
+// and the builders not sent yet are dropped. The in-flight requests are
+// still finished.
+func WithScheduleErrorCallbackOpt(cb ErrorCallback) ScheduleOpt {
+	return func(cfg *scheduleCfg) {
+		cfg.errorCallback = cb
+	}
+}
+
+// WithScheduleExecutorOpt uses the given executor instead of creating one
+// from spec, like an executor built outside the factory or a mock in tests.
+// Its mode, which is Metadata().Custom["mode"], must match spec's Mode if
+// it's reported. Schedule stops the executor when it returns.
+func WithScheduleExecutorOpt(exec executor.Executor) ScheduleOpt {
+	return func(cfg *scheduleCfg) {
+		cfg.executor = exec
+	}
 }
 
 // Schedule executes requests to apiserver based on LoadProfileSpec using the executor pattern.
-func Schedule(ctx context.Context, spec *types.LoadProfileSpec, restCli []rest.Interface) (*Result, error) {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+func Schedule(ctx context.Context, spec *types.LoadProfileSpec, restCli []rest.Interface, opts ...ScheduleOpt) (*Result, error) {
+	var cfg scheduleCfg
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
-	// Create executor for the specified mode
-	exec, err := executor.CreateExecutor(spec)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create executor: %v", err)
+	exec := cfg.executor
+	if exec == nil {
+		// Create executor for the specified mode
+		var err error
+		exec, err = executor.CreateExecutor(spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create executor: %v", err)
+		}
+	} else if mode, ok := exec.Metadata().Custom["mode"]; ok && mode != string(spec.Mode) {
+		exec.Stop()
+		return nil, fmt.Errorf("executor's mode %v doesn't match spec's mode %s", mode, spec.Mode)
+	}
+	if cfg.maxDuration > 0 {
+		exec = executor.NewTimeoutExecutor(exec, cfg.maxDuration)
 	}
 	defer exec.Stop()
 
+	return schedule(ctx, spec, exec, restCli, &cfg)
+}
+
+// schedule dispatches the executor's requests to the worker pool until the
+// executor finishes or ctx is canceled. The spec provides the settings of
+// worker pool, like the number of clients.
+func schedule(ctx context.Context, spec *types.LoadProfileSpec, exec executor.Executor, restCli []rest.Interface, cfg *scheduleCfg) (*Result, error) {
+	progress := cfg.progress
+	if progress == nil {
+		progress = &Progress{}
+	}
+
+	// NOTE: The parent context can be canceled with cause, like SLO
+	// violation, which is preserved as termination cause.
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
 	// Get metadata for logging
 	metadata := exec.Metadata()
 
@@ -49,40 +297,151 @@ func Schedule(ctx context.Context, spec *types.LoadProfileSpec, restCli []rest.I
 	execCtx, execCancel := exec.GetExecutionContext(ctx)
 	defer execCancel()
 
+	// Connections in use. With ramp-up, the i-th connection is put into
+	// use after i * rampInterval.
+	conns := len(restCli)
+	var rampInterval time.Duration
+	if cfg.connectionRampUp > 0 {
+		if metadata.ExpectedDuration <= 0 {
+			return nil, fmt.Errorf("connection ramp-up requires the mode's expected duration")
+		}
+		conns = min(cfg.connectionRampUp, conns)
+		rampInterval = metadata.ExpectedDuration / time.Duration(len(restCli))
+	}
+
+	if cfg.rotationInterval > 0 && cfg.newClients == nil {
+		return nil, fmt.Errorf("client rotation requires the function to create clients")
+	}
+
+	// Workers load the clients on each request so that the rotation
+	// takes effect without restarting them.
+	var pool atomic.Pointer[[]rest.Interface]
+	pool.Store(&restCli)
+
 	// Get rate limiter (nil if mode doesn't need it)
 	limiter := exec.GetRateLimiter()
 
+	// Get response observer (nil if mode doesn't need feedback)
+	observer, _ := exec.(executor.ResponseObserver)
+
 	// Worker pool - start workers BEFORE executor to avoid unbuffered channel deadlock
 	clients := spec.Client
 	if clients == 0 {
 		clients = spec.Conns
 	}
+	if cfg.concurrencyLimit > 0 {
+		clients = min(clients, cfg.concurrencyLimit)
+	}
 
 	respMetric := metrics.NewResponseMetric()
+
+	// connMetrics[i] records latencies of requests sent by restCli[i].
+	var connMetrics []metrics.ResponseMetric
+	if cfg.trackPerConnection {
+		connMetrics = make([]metrics.ResponseMetric, len(restCli))
+		for i := range connMetrics {
+			connMetrics[i] = metrics.NewResponseMetric()
+		}
+	}
+
 	var wg sync.WaitGroup
 
+	// failedFast is set once errorCallback terminates Schedule.
+	var failedFast atomic.Bool
+
+	// Each worker owns its stats of each connection so that it doesn't
+	// need lock. They are merged after all the workers exit.
+	workerStats := make([][]workerStat, clients)
+
+	rampStart := time.Now()
 	reqBuilderCh := exec.Chan()
 	for i := 0; i < clients; i++ {
-		cli := restCli[i%len(restCli)]
+		workerStats[i] = make([]workerStat, len(restCli))
+
+		// Each worker sticks to one connection. If there are fewer
+		// workers than connections, like by concurrency limit, each
+		// worker sends requests over its connections in turn so that
+		// all the connections are used.
+		//
+		// NOTE: With ramp-up, the worker starts with the ramp-up delay
+		// of its first connection, and the other connections join the
+		// turns once they are put into use.
+		workerConns := []int{i % conns}
+		for connIdx := i + clients; connIdx < conns; connIdx += clients {
+			workerConns = append(workerConns, connIdx)
+		}
+		delay := time.Duration(workerConns[0]) * rampInterval
+
+		// Each worker observes into its own shard so that workers don't
+		// contend on one lock at high QPS.
+		respMetric := metrics.NewShard(respMetric)
+
+		var connMetricShards map[int]metrics.ResponseMetric
+		if cfg.trackPerConnection {
+			connMetricShards = make(map[int]metrics.ResponseMetric, len(workerConns))
+			for _, connIdx := range workerConns {
+				connMetricShards[connIdx] = metrics.NewShard(connMetrics[connIdx])
+			}
+		}
+
 		wg.Add(1)
-		go func(workerID int, cli rest.Interface) {
+		go func(workerID int) {
 			defer wg.Done()
 
+			stats := workerStats[workerID]
+
+			if delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+
 			klog.V(5).Infof("Worker %d started, waiting for requests", workerID)
 			requestCount := 0
 
 			for builder := range reqBuilderCh {
+				// NOTE: The builders are drained without sending
+				// so that executor isn't blocked.
+				if failedFast.Load() {
+					continue
+				}
+
+				if err := progress.waitResumed(ctx); err != nil {
+					klog.V(5).Infof("Worker %d: paused schedule canceled: %v", workerID, err)
+					return
+				}
+
 				// Apply rate limiting (if configured)
 				if limiter != nil {
-					if err := limiter.Wait(ctx); err != nil {
+					// NOTE: The builders received after executor is done
+					// are the last ones it produced. They are still sent
+					// so that the total matches.
+					if err := limiter.Wait(ctx); err != nil && !errors.Is(context.Cause(ctx), errScheduleDone) {
 						klog.V(5).Infof("Worker %d: Rate limiter wait failed: %v", workerID, err)
 						return
 					}
 				}
 
+				inUse := len(workerConns)
+				if rampInterval > 0 {
+					elapsed := time.Since(rampStart)
+					for inUse > 1 && time.Duration(workerConns[inUse-1])*rampInterval > elapsed {
+						inUse--
+					}
+				}
+				connIdx := workerConns[requestCount%inUse]
+				stat, connMetric := &stats[connIdx], connMetricShards[connIdx]
+
 				requestCount++
 				klog.V(8).Infof("Worker %d received request #%d", workerID, requestCount)
-				req := builder.Build(cli)
+				req := builder.Build((*pool.Load())[connIdx])
+				if cfg.tieredLatency {
+					req = toTieredRequester(req)
+				}
 
 				klog.V(5).Infof("Request URL: %s", req.URL())
 
@@ -91,7 +450,7 @@ func Schedule(ctx context.Context, spec *types.LoadProfileSpec, restCli []rest.I
 					start := time.Now()
 
 					var bytes int64
-					bytes, err := req.Do(context.Background())
+					bytes, err := doRequest(context.Background(), req, cfg.interceptor)
 					// Based on HTTP2 Spec Section 8.1 [1],
 					//
 					// A server can send a complete response prior to the client
@@ -114,19 +473,71 @@ func Schedule(ctx context.Context, spec *types.LoadProfileSpec, restCli []rest.I
 
 					end := time.Now()
 					latency := end.Sub(start).Seconds()
+					progress.observe(err)
+					stat.observe(latency, err)
+					if observer != nil {
+						observer.OnResponse(req.Method(), end.Sub(start), err)
+					}
+
+					if wr, ok := unwrapAs[WatchStatsRequester](req); ok {
+						setup, events, bookmarks := wr.WatchStats()
+						if setup > 0 {
+							respMetric.ObserveWatchSetupLatency(req.Method(), req.MaskedURL().String(), setup.Seconds())
+						}
+						respMetric.ObserveWatchEvents(events, bookmarks)
+					}
+
+					if lr, ok := unwrapAs[WatchEventLagRequester](req); ok {
+						for _, lag := range lr.WatchEventLags() {
+							respMetric.ObserveWatchEventLag(req.Method(), req.MaskedURL().String(), lag)
+						}
+					}
+
+					if tr, ok := unwrapAs[TieredLatencyRequester](req); ok && err == nil {
+						ttfb, bodyRead := tr.TieredLatency()
+						if ttfb > 0 {
+							respMetric.ObserveTTFB(req.Method(), req.MaskedURL().String(), ttfb.Seconds())
+							respMetric.ObserveBodyReadLatency(req.Method(), req.MaskedURL().String(), bodyRead.Seconds())
+						}
+					}
+
+					if lr, ok := unwrapAs[LogStatsRequester](req); ok && err == nil {
+						lines, timeSpan := lr.LogStats()
+						if lines != nil {
+							respMetric.ObserveLogLines(req.MaskedURL().String(), *lines)
+						}
+						if timeSpan != nil {
+							respMetric.ObserveLogTimeSpan(req.MaskedURL().String(), *timeSpan)
+						}
+					}
 
 					respMetric.ObserveReceivedBytes(bytes)
 					if err != nil {
 						respMetric.ObserveFailure(req.Method(), req.MaskedURL().String(), end, latency, err)
 						klog.V(5).Infof("Request stream failed: %v", err)
+						if cfg.errorCallback != nil {
+							respErr := metrics.BuildResponseError(req.Method(), req.MaskedURL().String(), end, latency, err)
+							if cfg.errorCallback(respErr) {
+								// NOTE: The first cause wins if
+								// several workers fail at once.
+								cancel(&failFastError{respErr: respErr})
+								failedFast.Store(true)
+							}
+						}
 						return
 					}
 					respMetric.ObserveLatency(req.Method(), req.MaskedURL().String(), latency)
+					if cfg.trackResponseSize {
+						respMetric.ObserveResponseSize(req.Method(), req.MaskedURL().String(), bytes)
+					}
+					if connMetric != nil {
+						connMetric.ObserveLatency(req.Method(), req.MaskedURL().String(), latency)
+					}
 				}()
 			}
 
 			klog.V(5).Infof("Worker %d finished: processed %d requests", workerID, requestCount)
-		}(i, cli)
+		}(i)
 	}
 
 	// Extract rate from metadata for logging (mode-specific)
@@ -136,6 +547,7 @@ func Schedule(ctx context.Context, spec *types.LoadProfileSpec, restCli []rest.I
 		"mode", spec.Mode,
 		"clients", clients,
 		"connections", len(restCli),
+		"connectionRampInterval", rampInterval,
 		"rate", rate,
 		"expectedTotal", metadata.ExpectedTotal,
 		"expectedDuration", metadata.ExpectedDuration,
@@ -144,14 +556,53 @@ func Schedule(ctx context.Context, spec *types.LoadProfileSpec, restCli []rest.I
 	)
 
 	start := time.Now()
+	progress.attach(start, metadata.ExpectedTotal, respMetric, exec, cancel)
+
+	go func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				klog.V(2).InfoS("Schedule progress",
+					"completed", progress.Completed(),
+					"errors", progress.Failed(),
+					"expectedTotal", metadata.ExpectedTotal,
+					"openWatches", OpenWatches(),
+					"elapsed", time.Since(start).Round(time.Second),
+				)
+			}
+		}
+	}()
+
+	rotationCh := make(chan int, 1)
+	if cfg.rotationInterval > 0 {
+		go func() {
+			rotationCh <- rotateClients(ctx, &pool, cfg.rotationInterval, cfg.newClients)
+		}()
+	} else {
+		rotationCh <- 0
+	}
 
 	// Start executor AFTER workers are ready to receive
 	go func() {
-		if err := exec.Run(execCtx); err != nil && err != context.Canceled {
+		err := exec.Run(execCtx)
+		// NOTE: The deadline of mode's duration means executor is done,
+		// but the one of --max-duration cuts the execution short.
+		if cause := context.Cause(execCtx); errors.Is(cause, executor.ErrMaxDurationExceeded) {
+			cancel(cause)
+			return
+		}
+		if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 			klog.Errorf("Executor error: %v", err)
+			cancel(&executorError{err: err})
+			return
 		}
-		// Signal completion (success or failure)
-		cancel()
+		// Signal completion
+		cancel(errScheduleDone)
 	}()
 
 	// Wait for completion
@@ -159,16 +610,187 @@ func Schedule(ctx context.Context, spec *types.LoadProfileSpec, restCli []rest.I
 
 	exec.Stop()
 	wg.Wait()
+	rotations := <-rotationCh
 
-	totalDuration := time.Since(start)
+	end := time.Now()
+	progress.finish(end)
+	totalDuration := end.Sub(start)
 	responseStats := respMetric.Gather()
+	if cfg.trackPerConnection {
+		responseStats.LatenciesByConnection = make(map[int][]float64, len(connMetrics))
+		for idx, m := range connMetrics {
+			latencies := []float64{}
+			for _, l := range m.Gather().LatenciesByURL {
+				latencies = append(latencies, l...)
+			}
+			responseStats.LatenciesByConnection[idx] = latencies
+		}
+	}
+	responseStats.RequestsByWorker = make([]int64, clients)
+	responseStats.RequestsByConnection = make([]int64, len(restCli))
+	responseStats.FailuresByConnection = make([]int64, len(restCli))
+	responseStats.LatencySumByConnection = make([]float64, len(restCli))
+	for i, stats := range workerStats {
+		for connIdx, stat := range stats {
+			responseStats.RequestsByWorker[i] += stat.requests
+			responseStats.RequestsByConnection[connIdx] += stat.requests
+			responseStats.FailuresByConnection[connIdx] += stat.failures
+			responseStats.LatencySumByConnection[connIdx] += stat.latencySum
+		}
+	}
+
+	finalMetadata := exec.Metadata()
+	warnDispatchBlocked(finalMetadata, limiter, totalDuration)
+
+	var executorReport *types.ExecutorReport
+	if reporter, ok := exec.(executor.Reporter); ok {
+		executorReport = reporter.Report()
+	}
+
+	var terminationCause error
+	if cause := context.Cause(ctx); !errors.Is(cause, errScheduleDone) {
+		terminationCause = cause
+		klog.V(2).InfoS("Schedule terminated early", "cause", cause)
+	}
+
+	var execErr *executorError
+	var executionError error
+	if errors.As(terminationCause, &execErr) {
+		executionError = execErr.err
+	}
+
+	var ffErr *failFastError
+	var failFastRespErr *types.ResponseError
+	if errors.As(terminationCause, &ffErr) {
+		failFastRespErr = &ffErr.respErr
+	}
+
 	return &Result{
-		ResponseStats: responseStats,
-		Duration:      totalDuration,
-		Total:         metadata.ExpectedTotal,
+		ResponseStats:    responseStats,
+		Duration:         totalDuration,
+		Total:            attemptedTotal(responseStats),
+		ExecutorReport:   executorReport,
+		TerminationCause: terminationCause,
+		ExecutionError:   executionError,
+		FailFastError:    failFastRespErr,
+
+		DispatchBlockedTime:    finalMetadata.DispatchBlockedTime,
+		MaxDispatchBlockedTime: finalMetadata.MaxDispatchBlockedTime,
+		ConnectionRampInterval: rampInterval,
+		ClientRotationCount:    rotations,
+		StartTime:              finalMetadata.StartTime,
+		EndTime:                finalMetadata.EndTime,
 	}, nil
 }
 
+// rotateClients replaces the clients in pool by newClients on every interval
+// until ctx is done, and returns the number of rotations. The replaced
+// clients' idle connections are closed, while the in-flight requests on them
+// still finish.
+func rotateClients(ctx context.Context, pool *atomic.Pointer[[]rest.Interface], interval time.Duration, newClients func() ([]rest.Interface, error)) int {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	rotations := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return rotations
+		case <-ticker.C:
+		}
+
+		clis, err := newClients()
+		if err == nil && len(clis) != len(*pool.Load()) {
+			err = fmt.Errorf("expected %d clients, got %d", len(*pool.Load()), len(clis))
+		}
+		if err != nil {
+			klog.Warningf("Failed to rotate clients, keep the ones in use: %v", err)
+			continue
+		}
+
+		old := pool.Swap(&clis)
+		rotations++
+		klog.V(2).InfoS("Rotated clients", "rotations", rotations)
+
+		for _, cli := range *old {
+			if rc, ok := cli.(*rest.RESTClient); ok && rc.Client != nil {
+				rc.Client.CloseIdleConnections()
+			}
+		}
+	}
+}
+
+// warnDispatchBlocked logs a warning if executor is blocked on dispatching
+// requests for a significant part of schedule. It means workers can't keep
+// up and the requests aren't sent on time, so that the configured rate or
+// timeline isn't honored.
+//
+// NOTE: It's skipped if workers are rate limited, because executor is
+// expected to be blocked while workers wait for the limiter.
+func warnDispatchBlocked(md executor.ExecutorMetadata, limiter executor.RateLimiter, duration time.Duration) {
+	if limiter != nil || duration <= 0 {
+		return
+	}
+	if float64(md.DispatchBlockedTime) <= float64(duration)*dispatchBlockedWarningRatio {
+		return
+	}
+	klog.Warningf("Executor was blocked on dispatching requests for %v (max %v) in %v, "+
+		"workers can't keep up and requests are delayed; consider more clients or dispatchBufferSize",
+		md.DispatchBlockedTime, md.MaxDispatchBlockedTime, duration)
+}
+
+// attemptedTotal returns the number of observed requests, including failed
+// ones.
+func attemptedTotal(stats types.ResponseStats) int {
+	total := int64(0)
+	for _, n := range stats.AttemptsByMethod {
+		total += n
+	}
+	return int(total)
+}
+
+// doRequest sends the request through interceptor if any.
+func doRequest(ctx context.Context, req Requester, interceptor RequestInterceptor) (int64, error) {
+	if interceptor == nil {
+		return req.Do(ctx)
+	}
+	return interceptor(ctx, req, req.Do)
+}
+
+// workerStat accumulates the requests handled by one worker over one
+// connection.
+type workerStat struct {
+	requests   int64
+	failures   int64
+	latencySum float64
+}
+
+// observe records one finished request.
+func (s *workerStat) observe(latency float64, err error) {
+	s.requests++
+	if err != nil {
+		s.failures++
+		return
+	}
+	s.latencySum += latency
+}
+
+// unwrapAs returns the first requester implementing T in the chain of
+// executor.WrappedRequester, starting from req itself.
+func unwrapAs[T any](req Requester) (T, bool) {
+	for {
+		if t, ok := req.(T); ok {
+			return t, true
+		}
+		wr, ok := req.(executor.WrappedRequester)
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		req = wr.Unwrap()
+	}
+}
+
 // isHTTP2StreamNoError returns true if it's NO_ERROR.
 func isHTTP2StreamNoError(err error) bool {
 	if err == nil {
@@ -0,0 +1,781 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/request/executor"
+	"github.com/Azure/kperf/request/testdata/repeatmode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/rest"
+)
+
+func TestScheduleTrackPerConnection(t *testing.T) {
+	srv := newTestServer(t, writePodList)
+
+	spec, cfg := newStaleListSpec()
+	spec.Conns, spec.Client = 2, 4
+	cfg.Total = 20
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	res, err := Schedule(context.TODO(), spec, clis)
+	require.NoError(t, err)
+	assert.Nil(t, res.LatenciesByConnection)
+	assert.NoError(t, res.TerminationCause)
+
+	res, err = Schedule(context.TODO(), spec, clis, WithScheduleTrackPerConnectionOpt(true))
+	require.NoError(t, err)
+	require.Len(t, res.LatenciesByConnection, 2)
+
+	expected := 0
+	for _, l := range res.LatenciesByURL {
+		expected += len(l)
+	}
+
+	total := 0
+	for idx := range res.LatenciesByConnection {
+		assert.Contains(t, []int{0, 1}, idx)
+		total += len(res.LatenciesByConnection[idx])
+	}
+	assert.Equal(t, expected, total)
+
+	// Workers 0 and 2 share connection 0, while 1 and 3 share connection 1.
+	require.Len(t, res.RequestsByWorker, 4)
+	require.Len(t, res.RequestsByConnection, 2)
+	assert.Equal(t, res.RequestsByWorker[0]+res.RequestsByWorker[2], res.RequestsByConnection[0])
+	assert.Equal(t, res.RequestsByWorker[1]+res.RequestsByWorker[3], res.RequestsByConnection[1])
+	assert.Equal(t, int64(expected), res.RequestsByConnection[0]+res.RequestsByConnection[1])
+	assert.Equal(t, []int64{0, 0}, res.FailuresByConnection)
+	for idx, n := range res.RequestsByConnection {
+		assert.Equal(t, len(res.LatenciesByConnection[idx]), int(n))
+		if n > 0 {
+			assert.Greater(t, res.LatencySumByConnection[idx], float64(0))
+		}
+	}
+}
+
+func TestScheduleConcurrencyLimit(t *testing.T) {
+	srv := newTestServer(t, writePodList)
+
+	spec, cfg := newStaleListSpec()
+	spec.Conns, spec.Client = 4, 0
+	cfg.Total = 20
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	var intercepted int64
+	interceptor := func(ctx context.Context, _ Requester, do func(context.Context) (int64, error)) (int64, error) {
+		atomic.AddInt64(&intercepted, 1)
+		return do(ctx)
+	}
+
+	res, err := Schedule(context.TODO(), spec, clis, WithScheduleConcurrencyLimitOpt(1),
+		WithScheduleRequestInterceptorOpt(interceptor))
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), atomic.LoadInt64(&intercepted))
+
+	// Only 1 worker is spawned and it sends requests over all the 4
+	// connections in turn.
+	assert.Equal(t, []int64{20}, res.RequestsByWorker)
+	assert.Equal(t, []int64{5, 5, 5, 5}, res.RequestsByConnection)
+}
+
+func TestScheduleRequestInterceptorChain(t *testing.T) {
+	srv := newTestServer(t, writePodList)
+
+	spec, cfg := newStaleListSpec()
+	spec.Conns, spec.Client = 1, 1
+	cfg.Total = 3
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	var calls []string
+	newInterceptor := func(name string) RequestInterceptor {
+		return func(ctx context.Context, _ Requester, do func(context.Context) (int64, error)) (int64, error) {
+			calls = append(calls, name+":before")
+			n, err := do(ctx)
+			calls = append(calls, name+":after")
+			return n, err
+		}
+	}
+
+	_, err = Schedule(context.TODO(), spec, clis,
+		WithScheduleRequestInterceptorOpt(newInterceptor("a")),
+		WithScheduleRequestInterceptorOpt(nil),
+		WithScheduleRequestInterceptorOpt(newInterceptor("b")))
+	require.NoError(t, err)
+
+	expected := []string{}
+	for i := 0; i < 3; i++ {
+		expected = append(expected, "a:before", "b:before", "b:after", "a:after")
+	}
+	assert.Equal(t, expected, calls)
+}
+
+func TestScheduleConnectionRampUp(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		first = map[string]time.Time{}
+	)
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if _, ok := first[r.UserAgent()]; !ok {
+			first[r.UserAgent()] = time.Now()
+		}
+		mu.Unlock()
+		writePodList(w, r)
+	})
+
+	spec, cfg := newStaleListSpec()
+	spec.Conns, spec.Client = 4, 4
+	cfg.Rate, cfg.Duration = 100, 1
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns,
+		WithClientUserAgentOpt("test/{index}"))
+	require.NoError(t, err)
+
+	res, err := Schedule(context.TODO(), spec, clis, WithScheduleConnectionRampUpOpt(2))
+	require.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, res.ConnectionRampInterval)
+
+	// Only the first 2 connections are put into use, one by one.
+	require.Len(t, res.RequestsByConnection, 4)
+	assert.Positive(t, res.RequestsByConnection[0])
+	assert.Positive(t, res.RequestsByConnection[1])
+	assert.Equal(t, []int64{0, 0}, res.RequestsByConnection[2:])
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, first, 2)
+	assert.GreaterOrEqual(t, first["test/1"].Sub(first["test/0"]), 200*time.Millisecond)
+
+	// The interval can't be derived without expected duration.
+	cfg.Duration, cfg.Total = 0, 10
+	_, err = Schedule(context.TODO(), spec, clis, WithScheduleConnectionRampUpOpt(2))
+	assert.ErrorContains(t, err, "expected duration")
+}
+
+func TestScheduleMaxDuration(t *testing.T) {
+	srv := newTestServer(t, writePodList)
+
+	spec, cfg := newStaleListSpec()
+	cfg.Rate, cfg.Duration = 100, 60
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	res, err := Schedule(context.TODO(), spec, clis, WithScheduleMaxDurationOpt(500*time.Millisecond))
+	require.NoError(t, err)
+	assert.ErrorIs(t, res.TerminationCause, executor.ErrMaxDurationExceeded)
+	assert.NoError(t, res.ExecutionError)
+	assert.Less(t, res.Duration, 10*time.Second)
+	assert.NotEmpty(t, res.LatenciesByURL)
+}
+
+func TestScheduleProgress(t *testing.T) {
+	srv := newTestServer(t, writePodList)
+
+	spec, cfg := newStaleListSpec()
+	cfg.Rate = 100
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	progress := &Progress{}
+	assert.Equal(t, types.RunnerStatePending, progress.Status().State)
+	assert.Nil(t, progress.Result())
+	_, err = progress.DistributionStats()
+	assert.ErrorIs(t, err, errScheduleNotStarted)
+
+	go func() {
+		for progress.Completed() < 5 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(t, types.RunnerStateRunning, progress.Status().State)
+		assert.NotNil(t, progress.Result())
+
+		statsCh, err := progress.DistributionStats()
+		assert.NoError(t, err)
+		assert.NotNil(t, statsCh)
+		progress.Stop()
+	}()
+
+	// Without total and duration, it runs until stop.
+	res, err := Schedule(context.TODO(), spec, clis, WithScheduleProgressOpt(progress))
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.LatenciesByURL)
+
+	assert.ErrorIs(t, res.TerminationCause, ErrScheduleStopped)
+
+	status := progress.Status()
+	assert.Equal(t, types.RunnerStateFinished, status.State)
+	assert.True(t, status.Stopped)
+	assert.GreaterOrEqual(t, status.Completed, int64(5))
+}
+
+func TestScheduleTerminationCause(t *testing.T) {
+	srv := newTestServer(t, writePodList)
+
+	spec, cfg := newStaleListSpec()
+	cfg.Rate = 100
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	// Simulate that SLO checker cancels the benchmark with cause.
+	errSLOViolated := errors.New("p99 latency exceeds 1s")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	progress := &Progress{}
+	go func() {
+		for progress.Completed() < 3 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		cancel(errSLOViolated)
+	}()
+
+	res, err := Schedule(ctx, spec, clis, WithScheduleProgressOpt(progress))
+	require.NoError(t, err)
+	assert.ErrorIs(t, res.TerminationCause, errSLOViolated)
+	assert.NotEmpty(t, res.LatenciesByURL)
+}
+
+// faultExecutor runs the inner executor for a while and then fails.
+type faultExecutor struct {
+	executor.Executor
+	err error
+}
+
+func (e *faultExecutor) Run(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	_ = e.Executor.Run(ctx)
+	return e.err
+}
+
+func TestScheduleExecutionError(t *testing.T) {
+	srv := newTestServer(t, writePodList)
+
+	errInjected := errors.New("injected fault")
+	faultMode := types.ExecutionMode("test-fault-injection")
+	executor.RegisterMode(faultMode, func(spec *types.LoadProfileSpec) (executor.Executor, error) {
+		innerSpec := *spec
+		innerSpec.Mode = types.ModeWeightedRandom
+		inner, err := executor.NewWeightedRandomExecutor(&innerSpec)
+		if err != nil {
+			return nil, err
+		}
+		return &faultExecutor{Executor: inner, err: errInjected}, nil
+	})
+
+	spec, cfg := newStaleListSpec()
+	spec.Mode = faultMode
+	cfg.Rate = 100
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	res, err := Schedule(context.TODO(), spec, clis)
+	require.NoError(t, err)
+	assert.ErrorIs(t, res.ExecutionError, errInjected)
+	assert.ErrorIs(t, res.TerminationCause, errInjected)
+	assert.NotEmpty(t, res.LatenciesByURL, "partial result is kept")
+
+	// The executor finishing as expected has no error.
+	spec.Mode = types.ModeWeightedRandom
+	cfg.Total = 3
+	res, err = Schedule(context.TODO(), spec, clis)
+	require.NoError(t, err)
+	assert.NoError(t, res.ExecutionError)
+}
+
+func TestScheduleFailFast(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), 1)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		failFast       *FailFast
+		expectedErrors int
+		terminated     bool
+	}{
+		"any error": {
+			failFast:       &FailFast{MaxErrors: 1},
+			expectedErrors: 1,
+			terminated:     true,
+		},
+		"max errors": {
+			failFast:       &FailFast{MaxErrors: 3},
+			expectedErrors: 3,
+			terminated:     true,
+		},
+		"matched type": {
+			failFast:       &FailFast{MaxErrors: 1, Type: types.ResponseErrorTypeHTTP},
+			expectedErrors: 1,
+			terminated:     true,
+		},
+		"unmatched type": {
+			failFast:       &FailFast{MaxErrors: 1, Type: types.ResponseErrorTypeConnection},
+			expectedErrors: 20,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			spec, cfg := newStaleListSpec()
+			cfg.Total = 20
+
+			res, err := Schedule(context.TODO(), spec, clis, WithScheduleErrorCallbackOpt(tc.failFast.OnError))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedErrors, res.ErrorCount())
+			assert.Equal(t, tc.expectedErrors, res.Total)
+
+			if !tc.terminated {
+				assert.NoError(t, res.TerminationCause)
+				assert.Nil(t, res.FailFastError)
+				return
+			}
+			assert.ErrorIs(t, res.TerminationCause, ErrFailFast)
+			require.NotNil(t, res.FailFastError)
+			assert.Equal(t, types.ResponseErrorTypeHTTP, res.FailFastError.Type)
+			assert.Equal(t, http.StatusInternalServerError, res.FailFastError.Code)
+			assert.Contains(t, res.FailFastError.URL, "/api/v1/pods")
+			assert.False(t, res.FailFastError.Timestamp.IsZero())
+		})
+	}
+}
+
+func TestScheduleGetPodLogStats(t *testing.T) {
+	var timestamps atomic.Bool
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		timestamps.Store(r.URL.Query().Get("timestamps") == "true")
+		_, _ = w.Write([]byte(
+			"2024-01-02T15:04:05.000000000Z first\n" +
+				"2024-01-02T15:04:06.500000000Z second\n" +
+				"not a timestamped line\n" +
+				"2024-01-02T15:04:07.000000000Z last\n"))
+	})
+
+	spec, cfg := newStaleListSpec()
+	cfg.Total = 3
+	cfg.Requests[0] = &types.WeightedRequest{
+		Shares: 1,
+		GetPodLog: &types.RequestGetPodLog{
+			Namespace:       "default",
+			Name:            "a",
+			ParseTimestamps: true,
+			CountLines:      true,
+		},
+	}
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	u := srv.URL + "/api/v1/namespaces/default/pods/a/log?timeout=1m0s&timestamps=true"
+
+	// NOTE: maxInFlight wraps the requester, whose stats are still
+	// reported by following Unwrap.
+	for name, maxInFlight := range map[string]int{"bare": 0, "wrapped": 1} {
+		t.Run(name, func(t *testing.T) {
+			cfg.MaxInFlight = maxInFlight
+
+			res, err := Schedule(context.TODO(), spec, clis)
+			require.NoError(t, err)
+			assert.True(t, timestamps.Load())
+			assert.Equal(t, map[string]int64{u: 12}, res.LogLinesByURL)
+			assert.Equal(t, map[string]float64{u: 2}, res.LogTimeSpanByURL)
+		})
+	}
+}
+
+func TestScheduleTieredLatency(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+
+		time.Sleep(100 * time.Millisecond)
+		writePodList(w, r)
+	})
+
+	spec, cfg := newStaleListSpec()
+	cfg.Total = 2
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	res, err := Schedule(context.TODO(), spec, clis)
+	require.NoError(t, err)
+	assert.Empty(t, res.TTFBByURL)
+	assert.Empty(t, res.BodyReadLatenciesByURL)
+
+	// NOTE: maxInFlight wraps the requester, which is converted in
+	// place.
+	for name, maxInFlight := range map[string]int{"bare": 0, "wrapped": 1} {
+		t.Run(name, func(t *testing.T) {
+			cfg.MaxInFlight = maxInFlight
+
+			res, err := Schedule(context.TODO(), spec, clis, WithScheduleTieredLatencyOpt(true))
+			require.NoError(t, err)
+			require.Len(t, res.TTFBByURL, 1)
+			require.Len(t, res.BodyReadLatenciesByURL, 1)
+			for key, latencies := range res.TTFBByURL {
+				require.Len(t, latencies, 2)
+				require.Len(t, res.BodyReadLatenciesByURL[key], 2)
+				require.Len(t, res.LatenciesByURL[key], 2)
+
+				for idx, ttfb := range latencies {
+					bodyRead := res.BodyReadLatenciesByURL[key][idx]
+					assert.GreaterOrEqual(t, ttfb, 0.05)
+					assert.GreaterOrEqual(t, bodyRead, 0.1)
+				}
+			}
+		})
+	}
+}
+
+func TestResultCountsAndRates(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		total       int
+		errors      int
+		success     int
+		errorRate   float64
+		successRate float64
+	}{
+		{name: "no request", total: 0, errors: 0, success: 0, errorRate: 0, successRate: 1},
+		{name: "no error", total: 10, errors: 0, success: 10, errorRate: 0, successRate: 1},
+		{name: "partial errors", total: 10, errors: 3, success: 7, errorRate: 0.3, successRate: 0.7},
+		{name: "all errors", total: 4, errors: 4, success: 0, errorRate: 1, successRate: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := &Result{
+				ResponseStats: types.ResponseStats{Errors: make([]types.ResponseError, tc.errors)},
+				Total:         tc.total,
+			}
+			assert.Equal(t, tc.errors, res.ErrorCount())
+			assert.Equal(t, tc.success, res.SuccessCount())
+			assert.InDelta(t, tc.errorRate, res.ErrorRate(), 1e-9)
+			assert.InDelta(t, tc.successRate, res.SuccessRate(), 1e-9)
+		})
+	}
+}
+
+func TestScheduleTotalWithoutExpectedTotal(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("resourceVersion") == "0" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writePodList(w, r)
+	})
+
+	spec, cfg := newStaleListSpec()
+	spec.Client = 2
+	cfg.Rate, cfg.Duration = 50, 1
+	cfg.Requests = append(cfg.Requests, &types.WeightedRequest{
+		Shares:     1,
+		QuorumList: cfg.Requests[0].StaleList,
+	})
+	require.Zero(t, cfg.Total)
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	res, err := Schedule(context.TODO(), spec, clis)
+	require.NoError(t, err)
+
+	var finished int64
+	for _, n := range res.RequestsByWorker {
+		finished += n
+	}
+	// The end of mode's duration isn't early termination.
+	assert.NoError(t, res.TerminationCause)
+	require.Positive(t, res.Total)
+	assert.Equal(t, int(finished), res.Total)
+	assert.Positive(t, res.ErrorCount())
+	assert.Less(t, res.ErrorCount(), res.Total)
+	assert.Equal(t, res.Total, res.SuccessCount()+res.ErrorCount())
+	assert.InDelta(t, float64(res.ErrorCount())/float64(res.Total), res.ErrorRate(), 1e-9)
+}
+
+func TestScheduleCustomModeFromProfile(t *testing.T) {
+	var count atomic.Int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+		writePodList(w, r)
+	})
+
+	executor.RegisterPlugin(repeatmode.Plugin{})
+
+	in := []byte(`
+version: 1
+spec:
+  conns: 1
+  client: 2
+  contentType: json
+  mode: repeat
+  modeConfig:
+    count: 5
+    request:
+      shares: 1
+      staleList:
+        version: v1
+        resource: pods
+`)
+
+	var profile types.LoadProfile
+	require.NoError(t, yaml.Unmarshal(in, &profile))
+	require.NoError(t, profile.Validate())
+	require.IsType(t, &repeatmode.Config{}, profile.Spec.ModeConfig)
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), profile.Spec.Conns)
+	require.NoError(t, err)
+
+	res, err := Schedule(context.TODO(), &profile.Spec, clis)
+	require.NoError(t, err)
+	assert.Equal(t, 5, res.Total)
+	assert.Equal(t, int32(5), count.Load())
+}
+
+// writePodList responds empty PodList.
+func writePodList(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[]}`))
+}
+
+// newTestServer starts the server with handler. It's closed when the test
+// finishes.
+func newTestServer(t testing.TB, handler http.HandlerFunc) *httptest.Server {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newStaleListSpec returns the weighted-random spec, which lists pods from
+// cache with one connection and one client, and its mode config. The
+// callers set the fields they test.
+func newStaleListSpec() (*types.LoadProfileSpec, *types.WeightedRandomConfig) {
+	cfg := &types.WeightedRandomConfig{
+		Requests: []*types.WeightedRequest{
+			{
+				Shares: 1,
+				StaleList: &types.RequestList{
+					KubeGroupVersionResource: types.KubeGroupVersionResource{
+						Version:  "v1",
+						Resource: "pods",
+					},
+				},
+			},
+		},
+	}
+	return &types.LoadProfileSpec{
+		Conns:       1,
+		Client:      1,
+		ContentType: types.ContentTypeJSON,
+		Mode:        types.ModeWeightedRandom,
+		ModeConfig:  cfg,
+	}, cfg
+}
+
+func TestScheduleWithExecutor(t *testing.T) {
+	srv := newTestServer(t, writePodList)
+
+	spec, cfg := newStaleListSpec()
+	cfg.Total = 20
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	// The injected executor sends 5 requests instead of spec's 20.
+	injectedSpec, injectedCfg := newStaleListSpec()
+	injectedCfg.Total = 5
+	exec, err := executor.NewWeightedRandomExecutor(injectedSpec)
+	require.NoError(t, err)
+
+	res, err := Schedule(context.TODO(), spec, clis, WithScheduleExecutorOpt(exec))
+	require.NoError(t, err)
+	assert.Equal(t, 5, res.Total)
+
+	// The executor's mode must match spec's mode.
+	spec.Mode = types.ModeTimeSeries
+	exec, err = executor.NewWeightedRandomExecutor(injectedSpec)
+	require.NoError(t, err)
+
+	_, err = Schedule(context.TODO(), spec, clis, WithScheduleExecutorOpt(exec))
+	assert.ErrorContains(t, err, "doesn't match spec's mode")
+}
+
+func TestSchedulePause(t *testing.T) {
+	srv := newTestServer(t, writePodList)
+
+	spec, cfg := newStaleListSpec()
+	cfg.Rate = 100
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	progress := &Progress{}
+	assert.Error(t, progress.Pause())
+
+	go func() {
+		for progress.Completed() < 5 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.NoError(t, progress.Pause())
+		assert.True(t, progress.Status().Paused)
+
+		// At most the in-flight requests are finished after pausing.
+		time.Sleep(100 * time.Millisecond)
+		paused := progress.Completed()
+		time.Sleep(200 * time.Millisecond)
+		assert.Equal(t, paused, progress.Completed())
+
+		assert.NoError(t, progress.Resume())
+		assert.False(t, progress.Status().Paused)
+		for progress.Completed() < paused+5 {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		// Stop releases the paused workers.
+		assert.NoError(t, progress.Pause())
+		progress.Stop()
+	}()
+
+	res, err := Schedule(context.TODO(), spec, clis, WithScheduleProgressOpt(progress))
+	require.NoError(t, err)
+	assert.ErrorIs(t, res.TerminationCause, ErrScheduleStopped)
+	assert.False(t, progress.Status().Paused)
+	assert.ErrorIs(t, progress.Resume(), errScheduleDone)
+}
+
+func TestScheduleClientRotation(t *testing.T) {
+	var oldHits, newHits atomic.Int64
+	oldSrv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		oldHits.Add(1)
+		writePodList(w, r)
+	})
+	newSrv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		newHits.Add(1)
+		writePodList(w, r)
+	})
+
+	spec, cfg := newStaleListSpec()
+	spec.Conns, spec.Client = 2, 2
+	cfg.Rate, cfg.Duration = 100, 1
+
+	kubeCfgPath := newTestKubeconfig(t, oldSrv.URL)
+	newClients := func() ([]rest.Interface, error) {
+		return NewClients(kubeCfgPath, spec.Conns)
+	}
+	clis, err := newClients()
+	require.NoError(t, err)
+
+	// The credentials are rotated by replacing the kubeconfig in place.
+	rotatedCfg, err := os.ReadFile(newTestKubeconfig(t, newSrv.URL))
+	require.NoError(t, err)
+	go func() {
+		for oldHits.Load() < 5 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.NoError(t, os.WriteFile(kubeCfgPath, rotatedCfg, 0600))
+	}()
+
+	res, err := Schedule(context.TODO(), spec, clis,
+		WithScheduleClientRotationOpt(200*time.Millisecond, newClients))
+	require.NoError(t, err)
+	assert.NoError(t, res.TerminationCause)
+	assert.GreaterOrEqual(t, res.ClientRotationCount, 2)
+	assert.Positive(t, oldHits.Load())
+	assert.Positive(t, newHits.Load())
+	assert.Equal(t, int(oldHits.Load()+newHits.Load()), res.Total)
+
+	// The clients in use are kept if rotation fails.
+	badClients := func() ([]rest.Interface, error) {
+		return NewClients(kubeCfgPath, 1)
+	}
+	cfg.Duration = 0
+	cfg.Total = 10
+	res, err = Schedule(context.TODO(), spec, clis,
+		WithScheduleClientRotationOpt(time.Millisecond, badClients))
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.ClientRotationCount)
+	assert.Equal(t, 10, res.SuccessCount())
+
+	_, err = Schedule(context.TODO(), spec, clis, WithScheduleClientRotationOpt(time.Second, nil))
+	assert.ErrorContains(t, err, "client rotation")
+}
+
+func TestScheduleWatchEventLags(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		writeEvent := func(name string, created time.Time) {
+			_, _ = fmt.Fprintf(w, `{"type":"ADDED","object":{"apiVersion":"v1","kind":"Pod","metadata":{"name":%q,"creationTimestamp":%q}}}`+"\n",
+				name, created.UTC().Format(time.RFC3339))
+			w.(http.Flusher).Flush()
+		}
+
+		// The existing pod is skipped.
+		writeEvent("existing", time.Now().Add(-time.Hour))
+
+		// creationTimestamp is in seconds, so the pod is created in
+		// the next second after the watch starts.
+		time.Sleep(1100 * time.Millisecond)
+		writeEvent("created", time.Now())
+		<-r.Context().Done()
+	})
+
+	spec := &types.LoadProfileSpec{
+		Conns:  1,
+		Client: 1,
+		Mode:   types.ModeWeightedRandom,
+		ModeConfig: &types.WeightedRandomConfig{
+			Total: 1,
+			Requests: []*types.WeightedRequest{
+				{
+					Shares: 1,
+					WatchChurn: &types.RequestWatchChurn{
+						KubeGroupVersionResource: types.KubeGroupVersionResource{Version: "v1", Resource: "pods"},
+						HoldTime:                 "2s",
+					},
+				},
+			},
+		},
+	}
+
+	clis, err := NewClients(newTestKubeconfig(t, srv.URL), spec.Conns)
+	require.NoError(t, err)
+
+	res, err := Schedule(context.TODO(), spec, clis)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.TotalWatchEvents)
+	require.Len(t, res.WatchEventLagsByURL, 1)
+	for _, lags := range res.WatchEventLagsByURL {
+		require.Len(t, lags, 1)
+		assert.GreaterOrEqual(t, lags[0], float64(0))
+		assert.Less(t, lags[0], float64(1))
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultServiceAccountTokenExpiration is the default lifetime of token
+	// requested by NewClientsWithServiceAccount.
+	defaultServiceAccountTokenExpiration = time.Hour
+
+	// serviceAccountTokenRefreshRatio is the ratio of token's lifetime
+	// after which the token is refreshed.
+	serviceAccountTokenRefreshRatio = 0.8
+
+	// serviceAccountTokenRetryInterval is the interval to refresh again if
+	// TokenRequest fails.
+	serviceAccountTokenRetryInterval = 10 * time.Second
+
+	// serviceAccountTokenMinRefreshInterval is the minimum interval to
+	// refresh token, in case that the token is about to expire when it's
+	// issued.
+	serviceAccountTokenMinRefreshInterval = time.Second
+)
+
+// NewClientsWithServiceAccount creates N rest.Interface authenticated as
+// the given ServiceAccount, instead of the user in kubeconfig. The kubeconfig,
+// which can be empty for in-cluster config, is only used to request
+// time-limited tokens by TokenRequest API. The token is refreshed in the
+// background before it expires, until ctx is done. The clients should be
+// released with ctx, like at the end of benchmark, because the token isn't
+// refreshed after that.
+func NewClientsWithServiceAccount(ctx context.Context, namespace, saName, kubeCfgPath string, connsNum int, opts ...ClientCfgOpt) ([]rest.Interface, error) {
+	var cfg = defaultClientCfg
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.runID == "" && strings.Contains(cfg.userAgent, UserAgentRunIDPlaceholder) {
+		cfg.runID = uuid.New().String()
+	}
+
+	restCfg, err := cfg.buildRestConfig(kubeCfgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: It's the same as NewClients to make transport uncacheable and
+	// honor proxy environment variables.
+	if restCfg.Proxy == nil {
+		restCfg.Proxy = http.ProxyFromEnvironment
+	}
+
+	cs, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	ts := &serviceAccountTokenSource{
+		cli:        cs,
+		namespace:  namespace,
+		name:       saName,
+		expiration: cfg.serviceAccountTokenExpiration,
+	}
+	expiresAt, err := ts.refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+	go ts.refreshLoop(ctx, expiresAt)
+
+	// NOTE: The anonymous config keeps server and TLS settings, except
+	// client certificates, and drops all the credentials of kubeconfig.
+	saCfg := rest.AnonymousClientConfig(restCfg)
+	saCfg.Wrap(ts.wrap)
+	return newClientsFromRestConfig(saCfg, connsNum, &cfg)
+}
+
+// serviceAccountTokenSource provides the token of ServiceAccount.
+type serviceAccountTokenSource struct {
+	cli        kubernetes.Interface
+	namespace  string
+	name       string
+	expiration time.Duration
+
+	mu    sync.RWMutex
+	token string
+}
+
+// refresh requests a new token and returns its expiration time.
+func (ts *serviceAccountTokenSource) refresh(ctx context.Context) (time.Time, error) {
+	expirationSeconds := int64(ts.expiration.Seconds())
+	tr, err := ts.cli.CoreV1().ServiceAccounts(ts.namespace).CreateToken(ctx, ts.name,
+		&authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{
+				ExpirationSeconds: &expirationSeconds,
+			},
+		}, metav1.CreateOptions{})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to request token for serviceaccount %s/%s: %w",
+			ts.namespace, ts.name, err)
+	}
+
+	ts.mu.Lock()
+	ts.token = tr.Status.Token
+	ts.mu.Unlock()
+	return tr.Status.ExpirationTimestamp.Time, nil
+}
+
+// refreshLoop refreshes token before it expires until ctx is done.
+func (ts *serviceAccountTokenSource) refreshLoop(ctx context.Context, expiresAt time.Time) {
+	timer := time.NewTimer(serviceAccountTokenRefreshInterval(time.Until(expiresAt)))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		var wait time.Duration
+		expiresAt, err := ts.refresh(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			klog.Warningf("Failed to refresh token, retry in %v: %v", serviceAccountTokenRetryInterval, err)
+			wait = serviceAccountTokenRetryInterval
+		default:
+			wait = serviceAccountTokenRefreshInterval(time.Until(expiresAt))
+		}
+		timer.Reset(wait)
+	}
+}
+
+// serviceAccountTokenRefreshInterval returns how long to wait before
+// refreshing the token which expires in ttl.
+func serviceAccountTokenRefreshInterval(ttl time.Duration) time.Duration {
+	return max(time.Duration(float64(ttl)*serviceAccountTokenRefreshRatio), serviceAccountTokenMinRefreshInterval)
+}
+
+// Token returns the current token.
+func (ts *serviceAccountTokenSource) Token() string {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.token
+}
+
+// wrap returns http.RoundTripper which sets the current token as bearer
+// token for each request.
+func (ts *serviceAccountTokenSource) wrap(rt http.RoundTripper) http.RoundTripper {
+	return &bearerTokenRoundTripper{delegate: rt, source: ts}
+}
+
+// bearerTokenRoundTripper sets bearer token for each request.
+type bearerTokenRoundTripper struct {
+	delegate http.RoundTripper
+	source   *serviceAccountTokenSource
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *bearerTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+rt.source.Token())
+	return rt.delegate.RoundTrip(req)
+}
+
+// WrappedRoundTripper returns the delegate so that client-go can find the
+// underlying transport, like closing idle connections.
+func (rt *bearerTokenRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return rt.delegate
+}
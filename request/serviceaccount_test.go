@@ -0,0 +1,118 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTestJWT returns a synthetic JWT whose subject is the ServiceAccount.
+func newTestJWT(namespace, name string, idx int64) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	header := enc([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	payload := enc([]byte(fmt.Sprintf(`{"sub":"system:serviceaccount:%s:%s","jti":"%d"}`, namespace, name, idx)))
+	return header + "." + payload + "." + enc([]byte("signature"))
+}
+
+func TestNewClientsWithServiceAccount(t *testing.T) {
+	var issued int64
+	var mu sync.Mutex
+	var requestedExpiration int64
+	var authorizations []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/api/v1/namespaces/perf/serviceaccounts/bench/token" {
+			var tr authenticationv1.TokenRequest
+			if err := json.NewDecoder(r.Body).Decode(&tr); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			atomic.StoreInt64(&requestedExpiration, *tr.Spec.ExpirationSeconds)
+
+			// NOTE: The token expires earlier than requested so that it's
+			// refreshed during test.
+			tr.Status = authenticationv1.TokenRequestStatus{
+				Token:               newTestJWT("perf", "bench", atomic.AddInt64(&issued, 1)),
+				ExpirationTimestamp: metav1.NewTime(time.Now().Add(2 * time.Second)),
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(&tr)
+			return
+		}
+
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	kubeCfgPath := newTestKubeconfig(t, srv.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clis, err := NewClientsWithServiceAccount(ctx, "perf", "bench", kubeCfgPath, 2,
+		WithClientServiceAccountTokenExpirationSecondsOpt(600))
+	require.NoError(t, err)
+	require.Len(t, clis, 2)
+	assert.Equal(t, int64(600), atomic.LoadInt64(&requestedExpiration))
+
+	for _, cli := range clis {
+		require.NoError(t, cli.Get().AbsPath("/api/v1/pods").Do(context.TODO()).Error())
+	}
+
+	first := "Bearer " + newTestJWT("perf", "bench", 1)
+	mu.Lock()
+	assert.Equal(t, []string{first, first}, authorizations)
+	mu.Unlock()
+
+	// The token is refreshed before it expires.
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&issued) >= 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, clis[0].Get().AbsPath("/api/v1/pods").Do(context.TODO()).Error())
+	mu.Lock()
+	assert.NotEqual(t, first, authorizations[2])
+	mu.Unlock()
+
+	// The token isn't refreshed after ctx is done.
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+	stopped := atomic.LoadInt64(&issued)
+	time.Sleep(2 * time.Second)
+	assert.Equal(t, stopped, atomic.LoadInt64(&issued))
+}
+
+func TestServiceAccountTokenRefreshInterval(t *testing.T) {
+	assert.Equal(t, 48*time.Minute, serviceAccountTokenRefreshInterval(time.Hour))
+	assert.Equal(t, serviceAccountTokenMinRefreshInterval, serviceAccountTokenRefreshInterval(0))
+	assert.Equal(t, serviceAccountTokenMinRefreshInterval, serviceAccountTokenRefreshInterval(-time.Minute))
+}
+
+func TestNewClientsWithServiceAccountTokenRequestFailed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, err := NewClientsWithServiceAccount(context.TODO(), "perf", "bench", newTestKubeconfig(t, srv.URL), 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to request token for serviceaccount perf/bench")
+}
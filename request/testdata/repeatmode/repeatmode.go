@@ -0,0 +1,146 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+// Package repeatmode is an example custom mode which sends one request for
+// the given times. It's used to test loading custom mode from load profile.
+package repeatmode
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/request/executor"
+)
+
+// Mode is the execution mode provided by this plugin.
+const Mode types.ExecutionMode = "repeat"
+
+// Config defines configuration for repeat mode.
+type Config struct {
+	types.PluginModeConfig `json:"-" yaml:"-"`
+
+	// Count is the number of times to send request.
+	Count int `json:"count" yaml:"count"`
+	// Request is the request to send.
+	Request *types.WeightedRequest `json:"request" yaml:"request"`
+}
+
+// GetOverridableFields implements types.ModeConfig.
+func (c *Config) GetOverridableFields() []types.OverridableField {
+	return nil
+}
+
+// ApplyOverrides implements types.ModeConfig.
+func (c *Config) ApplyOverrides(overrides map[string]interface{}) error {
+	for key := range overrides {
+		return fmt.Errorf("unknown override key for %s mode: %s", Mode, key)
+	}
+	return nil
+}
+
+// Validate implements types.ModeConfig.
+func (c *Config) Validate(_ map[string]interface{}) error {
+	if c.Count <= 0 {
+		return fmt.Errorf("count requires > 0: %v", c.Count)
+	}
+	if c.Request == nil {
+		return fmt.Errorf("request is required")
+	}
+	return c.Request.Validate()
+}
+
+// ConfigureClientOptions implements types.ModeConfig.
+func (c *Config) ConfigureClientOptions() types.ClientOptions {
+	return types.ClientOptions{}
+}
+
+// Plugin implements executor.ModeConfigPlugin for repeat mode.
+type Plugin struct{}
+
+// Mode implements executor.Plugin.
+func (Plugin) Mode() types.ExecutionMode {
+	return Mode
+}
+
+// Constructor implements executor.Plugin.
+func (Plugin) Constructor() executor.ExecutorConstructor {
+	return newExecutor
+}
+
+// NewModeConfig implements executor.ModeConfigPlugin.
+func (Plugin) NewModeConfig() types.ModeConfig {
+	return &Config{}
+}
+
+type repeatExecutor struct {
+	count        int
+	builder      executor.RESTRequestBuilder
+	reqBuilderCh chan executor.RESTRequestBuilder
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	once         sync.Once
+}
+
+func newExecutor(spec *types.LoadProfileSpec) (executor.Executor, error) {
+	config, ok := spec.ModeConfig.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for %s mode", Mode)
+	}
+
+	builder, err := executor.NewRequestBuilder(config.Request, spec.MaxRetries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request builder: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &repeatExecutor{
+		count:        config.Count,
+		builder:      builder,
+		reqBuilderCh: make(chan executor.RESTRequestBuilder),
+		ctx:          ctx,
+		cancel:       cancel,
+	}, nil
+}
+
+func (e *repeatExecutor) Chan() <-chan executor.RESTRequestBuilder {
+	return e.reqBuilderCh
+}
+
+func (e *repeatExecutor) Run(ctx context.Context) error {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	for i := 0; i < e.count; i++ {
+		select {
+		case e.reqBuilderCh <- e.builder:
+		case <-e.ctx.Done():
+			return e.ctx.Err()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (e *repeatExecutor) Stop() {
+	e.once.Do(func() {
+		e.cancel()
+		e.wg.Wait()
+		close(e.reqBuilderCh)
+	})
+}
+
+func (e *repeatExecutor) Metadata() executor.ExecutorMetadata {
+	return executor.ExecutorMetadata{ExpectedTotal: e.count}
+}
+
+func (e *repeatExecutor) GetRateLimiter() executor.RateLimiter {
+	return nil
+}
+
+func (e *repeatExecutor) GetExecutionContext(baseCtx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithCancel(baseCtx)
+}
@@ -0,0 +1,241 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package request
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Azure/kperf/api/types"
+	"github.com/Azure/kperf/metrics"
+)
+
+// TransportTracer traces HTTP transports of clients created by NewClients.
+// It's safe for concurrent use. The zero value is ready to use and it's
+// attached by NewClients through WithClientTransportTracerOpt.
+type TransportTracer struct {
+	connsOpened   int64
+	connsReused   int64
+	tlsHandshakes int64
+
+	proxyConnects     int64
+	proxyConnectNanos int64
+
+	mu    sync.Mutex
+	conns map[transportConnKey]*types.TransportConnectionStats
+	proxy string
+	// dnsDurations are the durations in seconds of DNS resolutions.
+	dnsDurations []float64
+}
+
+// transportConnKey identifies the connection.
+type transportConnKey struct {
+	client    int
+	localAddr string
+}
+
+// wrap returns the function to wrap the transport of the client-th client.
+func (t *TransportTracer) wrap(client int) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		if tr, ok := rt.(*http.Transport); ok {
+			t.traceProxy(tr)
+		}
+		return &tracedRoundTripper{
+			delegate: rt,
+			trace:    t.clientTrace(client),
+			tracer:   t,
+		}
+	}
+}
+
+// traceProxy records the proxy selected by the transport and the CONNECT
+// tunnels set up through it.
+func (t *TransportTracer) traceProxy(tr *http.Transport) {
+	if proxy := tr.Proxy; proxy != nil {
+		tr.Proxy = func(req *http.Request) (*url.URL, error) {
+			u, err := proxy(req)
+			if err == nil && u != nil {
+				t.mu.Lock()
+				t.proxy = u.Redacted()
+				t.mu.Unlock()
+			}
+			return u, err
+		}
+	}
+
+	onConnectResponse := tr.OnProxyConnectResponse
+	tr.OnProxyConnectResponse = func(ctx context.Context, proxyURL *url.URL, connectReq *http.Request, connectRes *http.Response) error {
+		if connectRes.StatusCode == http.StatusOK {
+			atomic.AddInt64(&t.proxyConnects, 1)
+			if timer, ok := ctx.Value(proxyConnectTimerKey{}).(*proxyConnectTimer); ok && !timer.connectDone.IsZero() {
+				atomic.AddInt64(&t.proxyConnectNanos, int64(time.Since(timer.connectDone)))
+			}
+		}
+		if onConnectResponse != nil {
+			return onConnectResponse(ctx, proxyURL, connectReq, connectRes)
+		}
+		return nil
+	}
+}
+
+// clientTrace returns httptrace.ClientTrace for the client-th client.
+func (t *TransportTracer) clientTrace(client int) *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				atomic.AddInt64(&t.connsOpened, 1)
+			}
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				atomic.AddInt64(&t.tlsHandshakes, 1)
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				atomic.AddInt64(&t.connsReused, 1)
+			}
+			if info.Conn == nil {
+				return
+			}
+
+			key := transportConnKey{
+				client:    client,
+				localAddr: info.Conn.LocalAddr().String(),
+			}
+
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			if t.conns == nil {
+				t.conns = make(map[transportConnKey]*types.TransportConnectionStats)
+			}
+			stat, ok := t.conns[key]
+			if !ok {
+				// NOTE: It's HTTP/1.1 if ALPN isn't negotiated.
+				protocol := "http/1.1"
+				if tc, ok := info.Conn.(*tls.Conn); ok {
+					if p := tc.ConnectionState().NegotiatedProtocol; p != "" {
+						protocol = p
+					}
+				}
+
+				stat = &types.TransportConnectionStats{
+					Client:    client,
+					LocalAddr: key.localAddr,
+					Protocol:  protocol,
+				}
+				t.conns[key] = stat
+			}
+			stat.Requests++
+		},
+	}
+}
+
+// Stats returns the snapshot of transport statistics.
+func (t *TransportTracer) Stats() *types.TransportStats {
+	res := &types.TransportStats{
+		ConnectionsOpened: atomic.LoadInt64(&t.connsOpened),
+		ConnectionsReused: atomic.LoadInt64(&t.connsReused),
+		TLSHandshakes:     atomic.LoadInt64(&t.tlsHandshakes),
+		Protocols:         map[string]int{},
+
+		ProxyConnects:       atomic.LoadInt64(&t.proxyConnects),
+		ProxyConnectSeconds: time.Duration(atomic.LoadInt64(&t.proxyConnectNanos)).Seconds(),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.dnsDurations) > 0 {
+		res.DNSResolutions = int64(len(t.dnsDurations))
+		histogram := metrics.BuildLatencyHistogram(t.dnsDurations, types.DefaultHistogramBuckets)
+		res.DNSResolutionDurationSeconds = &histogram
+	}
+
+	res.Connections = make([]types.TransportConnectionStats, 0, len(t.conns))
+	for _, stat := range t.conns {
+		res.Connections = append(res.Connections, *stat)
+		res.Protocols[stat.Protocol]++
+	}
+	sort.Slice(res.Connections, func(i, j int) bool {
+		ci, cj := res.Connections[i], res.Connections[j]
+		if ci.Client != cj.Client {
+			return ci.Client < cj.Client
+		}
+		return ci.LocalAddr < cj.LocalAddr
+	})
+	return res
+}
+
+// observeDNS records one DNS resolution.
+func (t *TransportTracer) observeDNS(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dnsDurations = append(t.dnsDurations, d.Seconds())
+}
+
+// Proxy returns the proxy, without password, which the requests were sent
+// through. It's empty if there is no proxy.
+func (t *TransportTracer) Proxy() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.proxy
+}
+
+// proxyConnectTimerKey is the context key of proxyConnectTimer.
+type proxyConnectTimerKey struct{}
+
+// proxyConnectTimer records when the connection to proxy is established so
+// that the time spent on CONNECT tunnel can be measured.
+type proxyConnectTimer struct {
+	connectDone time.Time
+}
+
+// tracedRoundTripper injects httptrace.ClientTrace into each request.
+type tracedRoundTripper struct {
+	delegate http.RoundTripper
+	trace    *httptrace.ClientTrace
+	tracer   *TransportTracer
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *tracedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := httptrace.WithClientTrace(req.Context(), rt.trace)
+
+	// NOTE: The request's context is used to dial connection, including
+	// CONNECT tunnel to proxy.
+	timer := &proxyConnectTimer{}
+	var dnsStart time.Time
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				timer.connectDone = time.Now()
+			}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			if !dnsStart.IsZero() {
+				rt.tracer.observeDNS(time.Since(dnsStart))
+			}
+		},
+	})
+	ctx = context.WithValue(ctx, proxyConnectTimerKey{}, timer)
+	return rt.delegate.RoundTrip(req.WithContext(ctx))
+}
+
+// WrappedRoundTripper returns the delegate so that client-go can find the
+// underlying transport, like closing idle connections.
+func (rt *tracedRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return rt.delegate
+}
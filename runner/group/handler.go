@@ -18,6 +18,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	apitypes "k8s.io/apimachinery/pkg/types"
@@ -63,6 +64,11 @@ func NewHandler(
 		return nil, err
 	}
 
+	// NOTE: The resources are parsed when building job object.
+	if err := spec.ValidatePlacement(); err != nil {
+		return nil, err
+	}
+
 	return &Handler{
 		name:            name,
 		namespace:       namespace,
@@ -410,6 +416,16 @@ func (h *Handler) buildBatchJobObject(uploadURL string) *batchv1.Job {
 							},
 						},
 					},
+					{
+						// NOTE: The job is indexed so that each runner
+						// can use disjoint key space shard.
+						Name: types.RunnerIndexEnv,
+						ValueFrom: &corev1.EnvVarSource{
+							FieldRef: &corev1.ObjectFieldSelector{
+								FieldPath: "metadata.annotations['" + batchv1.JobCompletionIndexAnnotation + "']",
+							},
+						},
+					},
 					{
 						Name:  "TARGET_URL",
 						Value: uploadURL,
@@ -495,6 +511,27 @@ func (h *Handler) buildBatchJobObject(uploadURL string) *batchv1.Job {
 		job.Spec.Template.Spec.ServiceAccountName = *sa
 	}
 
+	if res := h.spec.Resources; res != nil {
+		requests := corev1.ResourceList{}
+		if res.CPU != "" {
+			requests[corev1.ResourceCPU] = resource.MustParse(res.CPU)
+		}
+		if res.Memory != "" {
+			requests[corev1.ResourceMemory] = resource.MustParse(res.Memory)
+		}
+		job.Spec.Template.Spec.Containers[0].Resources.Requests = requests
+	}
+
+	job.Spec.Template.Spec.NodeSelector = h.spec.NodeSelector
+	for _, t := range h.spec.Tolerations {
+		job.Spec.Template.Spec.Tolerations = append(job.Spec.Template.Spec.Tolerations, corev1.Toleration{
+			Key:      t.Key,
+			Operator: corev1.TolerationOperator(t.Operator),
+			Value:    t.Value,
+			Effect:   corev1.TaintEffect(t.Effect),
+		})
+	}
+
 	return job
 }
 
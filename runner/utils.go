@@ -63,8 +63,20 @@ func buildRunnerGroupSummary(s *localstore.Store, groups []*group.Handler) *type
 	totalBytes := int64(0)
 	totalResp := 0
 	latenciesByURL := map[string]*list.List{}
+	watchSetupLatenciesByURL := map[string]*list.List{}
+	watchEventLagsByURL := map[string]*list.List{}
+	ttfbByURL := map[string]*list.List{}
+	bodyReadLatenciesByURL := map[string]*list.List{}
+	latencyHistograms := map[string]types.LatencyHistogram{}
+	var skippedLatencyHistograms []types.SkippedLatencyHistogram
+	totalWatchEvents, totalWatchBookmarks := int64(0), int64(0)
+	var benchmarkStartTime, benchmarkEndTime string
+	var logLinesByURL map[string]int64
+	var logTimeSpanByURL map[string]float64
 	errs := []types.ResponseError{}
 	errStats := map[string]int32{}
+	errRateByURL := map[string]types.ErrorRate{}
+	errRateByMethod := map[string]types.ErrorRate{}
 	maxDuration := 0 * time.Second
 
 	for idx := range groups {
@@ -107,8 +119,57 @@ func buildRunnerGroupSummary(s *localstore.Store, groups []*group.Handler) *type
 				}
 			}
 
+			// update latency histograms
+			//
+			// NOTE: The histograms which can't be merged, like the ones
+			// with different bucket boundaries, are recorded in summary
+			// so that the merged histograms aren't taken as complete.
+			for u, h := range report.LatencyHistograms {
+				merged := latencyHistograms[u]
+				if err := metrics.MergeLatencyHistogram(&merged, h); err != nil {
+					klog.ErrorS(err, "failed to merge histogram", "runner", pod.Name, "url", u)
+					skippedLatencyHistograms = append(skippedLatencyHistograms, types.SkippedLatencyHistogram{
+						Source: pod.Name,
+						URL:    u,
+						Reason: err.Error(),
+					})
+					continue
+				}
+				latencyHistograms[u] = merged
+			}
+
+			// update tiered latencies
+			appendLatenciesByURL(ttfbByURL, report.TTFBByURL)
+			appendLatenciesByURL(bodyReadLatenciesByURL, report.BodyReadLatenciesByURL)
+
+			// update watch stats
+			appendLatenciesByURL(watchSetupLatenciesByURL, report.WatchSetupLatenciesByURL)
+			appendLatenciesByURL(watchEventLagsByURL, report.WatchEventLagsByURL)
+			totalWatchEvents += report.TotalWatchEvents
+			totalWatchBookmarks += report.TotalWatchBookmarks
+
+			// update benchmark window
+			metrics.MergeBenchmarkWindow(&benchmarkStartTime, &benchmarkEndTime,
+				report.BenchmarkStartTime, report.BenchmarkEndTime)
+
+			// update log stats
+			for u, n := range report.LogLinesByURL {
+				if logLinesByURL == nil {
+					logLinesByURL = map[string]int64{}
+				}
+				logLinesByURL[u] += n
+			}
+			for u, span := range report.LogTimeSpanByURL {
+				if logTimeSpanByURL == nil {
+					logTimeSpanByURL = map[string]float64{}
+				}
+				logTimeSpanByURL[u] = max(logTimeSpanByURL[u], span)
+			}
+
 			// update error stats
 			mergeErrorStat(errStats, report.ErrorStats)
+			metrics.MergeErrorRates(errRateByURL, report.ErrorRateByURL)
+			metrics.MergeErrorRates(errRateByMethod, report.ErrorRateByMethod)
 			errs = append(errs, report.Errors...)
 			report.Errors = nil
 
@@ -135,16 +196,59 @@ func buildRunnerGroupSummary(s *localstore.Store, groups []*group.Handler) *type
 	}
 
 	return &types.RunnerMetricReport{
-		Total:                    totalResp,
-		Errors:                   errs,
-		ErrorStats:               errStats,
-		Duration:                 maxDuration.String(),
-		TotalReceivedBytes:       totalBytes,
-		PercentileLatencies:      metrics.BuildPercentileLatencies(latencies),
-		PercentileLatenciesByURL: percentileLatenciesByURL,
+		SchemaVersion:                      types.RunnerMetricReportSchemaVersion,
+		Total:                              totalResp,
+		Errors:                             errs,
+		ErrorStats:                         errStats,
+		ErrorRateByURL:                     errRateByURL,
+		ErrorRateByMethod:                  errRateByMethod,
+		Duration:                           maxDuration.String(),
+		TotalReceivedBytes:                 totalBytes,
+		PercentileLatencies:                metrics.BuildPercentileLatencies(latencies),
+		PercentileLatenciesByURL:           percentileLatenciesByURL,
+		LatencyHistograms:                  latencyHistograms,
+		SkippedLatencyHistograms:           skippedLatencyHistograms,
+		PercentileWatchSetupLatenciesByURL: buildPercentileLatenciesByURL(watchSetupLatenciesByURL),
+		PercentileWatchEventLagsByURL:      buildPercentileLatenciesByURL(watchEventLagsByURL),
+		PercentileTTFBByURL:                buildPercentileLatenciesByURL(ttfbByURL),
+		PercentileBodyReadLatencyByURL:     buildPercentileLatenciesByURL(bodyReadLatenciesByURL),
+		TotalWatchEvents:                   totalWatchEvents,
+		TotalWatchBookmarks:                totalWatchBookmarks,
+		LogLinesByURL:                      logLinesByURL,
+		LogTimeSpanByURL:                   logTimeSpanByURL,
+		BenchmarkStartTime:                 benchmarkStartTime,
+		BenchmarkEndTime:                   benchmarkEndTime,
 	}
 }
 
+// appendLatenciesByURL appends latencies in src into dst.
+func appendLatenciesByURL(dst map[string]*list.List, src map[string][]float64) {
+	for u, l := range src {
+		latencies, ok := dst[u]
+		if !ok {
+			dst[u] = list.New()
+			latencies = dst[u]
+		}
+		for _, v := range l {
+			latencies.PushBack(v)
+		}
+	}
+}
+
+// buildPercentileLatenciesByURL returns percentile latencies for each url.
+// It returns nil if there is no latency.
+func buildPercentileLatenciesByURL(latenciesByURL map[string]*list.List) map[string][][2]float64 {
+	if len(latenciesByURL) == 0 {
+		return nil
+	}
+
+	res := make(map[string][][2]float64, len(latenciesByURL))
+	for u, l := range latenciesByURL {
+		res[u] = metrics.BuildPercentileLatencies(listToSliceFloat64(l))
+	}
+	return res
+}
+
 // listToSliceFloat64 converts list.List into []float64.
 func listToSliceFloat64(l *list.List) []float64 {
 	res := make([]float64, 0, l.Len())
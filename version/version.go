@@ -0,0 +1,34 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package version
+
+import "runtime/debug"
+
+var (
+	// Version holds the complete version number. Filled in at linking time.
+	Version = "unknown"
+
+	// Revision is filled with the VCS (e.g. git) revision being used to
+	// build the program at linking time.
+	Revision = ""
+)
+
+func init() {
+	if Revision != "" {
+		return
+	}
+
+	// Fallback to the revision stamped by go build if it's not filled in
+	// at linking time.
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			Revision = s.Value
+			return
+		}
+	}
+}
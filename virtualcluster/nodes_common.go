@@ -9,6 +9,7 @@ import (
 
 	"github.com/Azure/kperf/helmcli"
 
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/yaml"
 )
 
@@ -76,6 +77,9 @@ type nodepoolConfig struct {
 	maxPods int
 	// labels is to be applied to each virtual node.
 	labels map[string]string
+	// taints is to be applied to each virtual node in addition to the
+	// default one which avoids scheduling actual running pods.
+	taints []corev1.Taint
 	// sharedProviderID is to force all the virtual nodes sharing one providerID.
 	//
 	// FIXME(weifu):
@@ -155,6 +159,13 @@ func WithNodepoolLabelsOpt(labels map[string]string) NodepoolOpt {
 	}
 }
 
+// WithNodepoolTaintsOpt updates node's taints.
+func WithNodepoolTaintsOpt(taints []corev1.Taint) NodepoolOpt {
+	return func(cfg *nodepoolConfig) {
+		cfg.taints = taints
+	}
+}
+
 // WithNodepoolNodeControllerAffinity forces virtual node's controller to
 // nodes with that specific labels.
 func WithNodepoolNodeControllerAffinity(nodeSelectors map[string][]string) NodepoolOpt {
@@ -193,9 +204,20 @@ func (cfg *nodepoolConfig) toNodeHelmValuesAppliers() ([]helmcli.ValuesApplier,
 		return nil, err
 	}
 
+	nodeTaintsYaml, err := cfg.renderNodeTaints()
+	if err != nil {
+		return nil, err
+	}
+
+	nodeTaintsApplier, err := helmcli.YAMLValuesApplier(nodeTaintsYaml)
+	if err != nil {
+		return nil, err
+	}
+
 	return []helmcli.ValuesApplier{
 		helmcli.StringPathValuesApplier(res...),
 		nodeLabelsApplier,
+		nodeTaintsApplier,
 	}, nil
 }
 
@@ -214,6 +236,25 @@ func (cfg *nodepoolConfig) renderNodeLabels() (string, error) {
 	return string(rawData), nil
 }
 
+// renderNodeTaints renders virtual node's taints into YAML string
+//
+// NOTE: Please align with ../manifests/virtualcluster/nodes/values.yaml
+func (cfg *nodepoolConfig) renderNodeTaints() (string, error) {
+	taints := cfg.taints
+	if taints == nil {
+		taints = []corev1.Taint{}
+	}
+	target := map[string]interface{}{
+		"nodeTaints": taints,
+	}
+
+	rawData, err := yaml.Marshal(target)
+	if err != nil {
+		return "", fmt.Errorf("failed to render nodeTaints: %w", err)
+	}
+	return string(rawData), nil
+}
+
 // toNodeControllerHelmValuesAppliers creates ValuesAppliers.
 //
 // NOTE: Please align with ../manifests/virtualcluster/nodecontrollers/values.yaml